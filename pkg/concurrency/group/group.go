@@ -0,0 +1,165 @@
+package group
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Group runs functions in goroutines with a shared context, and is created with WithContext. The context is canceled
+// when a function fails, so the other functions can stop early. Unlike the first error of an errgroup, Wait returns
+// the errors of all the functions.
+//
+// The Group implements the lifecycle.Service interface, so its functions can run until the process shuts down.
+type Group struct {
+	ctx       context.Context
+	cancel    context.CancelCauseFunc
+	running   sync.WaitGroup
+	limit     chan struct{}
+	errsLock  sync.Mutex
+	errs      []error
+	shutdown  chan struct{}
+	closeOnce sync.Once
+}
+
+// WithContext creates a Group and the context shared by its functions. The context is derived from the parent,
+// and is canceled when a function fails or when Wait returns.
+func WithContext(parent context.Context) (*Group, context.Context) {
+	ctx, cancel := context.WithCancelCause(parent)
+	return &Group{
+		ctx:      ctx,
+		cancel:   cancel,
+		running:  sync.WaitGroup{},
+		limit:    nil,
+		errsLock: sync.Mutex{},
+		errs:     nil,
+		shutdown: make(chan struct{}),
+	}, ctx
+}
+
+// SetLimit bounds the number of functions that run at the same time. Go waits for a function to return when the
+// limit is reached. A negative limit removes the bound. The limit cannot be changed while functions are running,
+// or this function panics.
+func (g *Group) SetLimit(limit int) {
+	if limit < 0 {
+		g.limit = nil
+		return
+	}
+	if g.limit != nil && len(g.limit) != 0 {
+		panic(fmt.Sprintf("the limit cannot be changed while %d functions are running", len(g.limit)))
+	}
+	g.limit = make(chan struct{}, limit)
+}
+
+// Go runs the function in a goroutine with the context of the Group. If the limit is reached, it waits for a running
+// function to return first. An error or a panic of the function cancels the context of the Group.
+func (g *Group) Go(fn func(ctx context.Context) error) {
+	if fn == nil {
+		panic("the function cannot be nil")
+	}
+	if g.limit != nil {
+		g.limit <- struct{}{}
+	}
+	g.start(fn)
+}
+
+// TryGo runs the function like Go if the limit is not reached, and returns false otherwise.
+func (g *Group) TryGo(fn func(ctx context.Context) error) bool {
+	if fn == nil {
+		panic("the function cannot be nil")
+	}
+	if g.limit != nil {
+		select {
+		case g.limit <- struct{}{}:
+		default:
+			return false
+		}
+	}
+	g.start(fn)
+	return true
+}
+
+// start runs the function in a goroutine. The slot of the limit must be taken.
+func (g *Group) start(fn func(ctx context.Context) error) {
+	g.running.Add(1)
+	go func() {
+		defer g.running.Done()
+		defer func() {
+			if g.limit != nil {
+				<-g.limit
+			}
+		}()
+		if err := g.call(fn); err != nil {
+			g.errsLock.Lock()
+			g.errs = append(g.errs, err)
+			g.errsLock.Unlock()
+			g.cancel(err)
+		}
+	}()
+}
+
+// call invokes the function, returning its panic as an error.
+func (g *Group) call(fn func(ctx context.Context) error) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("the function panicked (%v)", recovered)
+		}
+	}()
+	return fn(g.ctx)
+}
+
+// Wait waits for all the functions to return, cancels the context of the Group, and returns the errors of the
+// functions joined together. It returns nil if none of them failed.
+func (g *Group) Wait() error {
+	return g.wait(false)
+}
+
+// Run waits for the functions like Wait. It is the implementation of the lifecycle.Service interface.
+// Once Shutdown is called, the errors that are the cancellation of the context are ignored,
+// since the functions stopped as requested.
+func (g *Group) Run() error {
+	g.running.Wait()
+	select {
+	case <-g.shutdown:
+		return g.wait(true)
+	default:
+		return g.wait(false)
+	}
+}
+
+// wait waits for all the functions to return and joins their errors.
+func (g *Group) wait(ignoreCanceled bool) error {
+	g.running.Wait()
+	g.cancel(context.Canceled)
+	g.errsLock.Lock()
+	defer g.errsLock.Unlock()
+	errs := make([]error, 0, len(g.errs))
+	for _, err := range g.errs {
+		if !ignoreCanceled || !errors.Is(err, context.Canceled) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Shutdown cancels the context of the Group and waits for the functions to return, or for the context to be done.
+// It is safe to call Shutdown many times.
+func (g *Group) Shutdown(ctx context.Context) error {
+	g.closeOnce.Do(func() {
+		close(g.shutdown)
+	})
+	g.cancel(context.Canceled)
+
+	done := make(chan struct{})
+	go func() {
+		g.running.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("the functions of the group did not stop in time (%w)", ctx.Err())
+	}
+}
@@ -0,0 +1,196 @@
+package group_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/concurrency/group"
+	"github.com/TriangleSide/GoBase/pkg/lifecycle"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestGroup(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when all the functions succeed it should return no error", func(t *testing.T) {
+		t.Parallel()
+		g, _ := group.WithContext(context.Background())
+		var calls atomic.Int32
+		for i := 0; i < 10; i++ {
+			g.Go(func(context.Context) error {
+				calls.Add(1)
+				return nil
+			})
+		}
+		assert.NoError(t, g.Wait())
+		assert.Equals(t, calls.Load(), int32(10))
+	})
+
+	t.Run("when the function is nil it should panic", func(t *testing.T) {
+		t.Parallel()
+		g, _ := group.WithContext(context.Background())
+		assert.PanicPart(t, func() {
+			g.Go(nil)
+		}, "the function cannot be nil")
+		assert.PanicPart(t, func() {
+			g.TryGo(nil)
+		}, "the function cannot be nil")
+	})
+
+	t.Run("when functions fail it should cancel the context and join all the errors", func(t *testing.T) {
+		t.Parallel()
+		g, ctx := group.WithContext(context.Background())
+		firstErr := errors.New("first error")
+		secondErr := errors.New("second error")
+		g.Go(func(context.Context) error {
+			return firstErr
+		})
+		g.Go(func(ctx context.Context) error {
+			<-ctx.Done()
+			return secondErr
+		})
+		err := g.Wait()
+		assert.True(t, errors.Is(err, firstErr))
+		assert.True(t, errors.Is(err, secondErr))
+		assert.True(t, errors.Is(context.Cause(ctx), firstErr))
+	})
+
+	t.Run("when a function panics it should return the panic as an error", func(t *testing.T) {
+		t.Parallel()
+		g, ctx := group.WithContext(context.Background())
+		g.Go(func(context.Context) error {
+			panic("function panic")
+		})
+		assert.ErrorExact(t, g.Wait(), "the function panicked (function panic)")
+		assert.NotNil(t, ctx.Err())
+	})
+
+	t.Run("when the functions are done it should cancel the context", func(t *testing.T) {
+		t.Parallel()
+		g, ctx := group.WithContext(context.Background())
+		g.Go(func(context.Context) error {
+			return nil
+		})
+		assert.NoError(t, g.Wait())
+		assert.True(t, errors.Is(ctx.Err(), context.Canceled))
+	})
+
+	t.Run("when the parent context is canceled it should cancel the context of the functions", func(t *testing.T) {
+		t.Parallel()
+		parent, cancel := context.WithCancel(context.Background())
+		g, _ := group.WithContext(parent)
+		g.Go(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		cancel()
+		assert.True(t, errors.Is(g.Wait(), context.Canceled))
+	})
+
+	t.Run("when there is a limit it should bound the functions running at the same time", func(t *testing.T) {
+		t.Parallel()
+		const limit = 2
+		g, _ := group.WithContext(context.Background())
+		g.SetLimit(limit)
+		var running, maxRunning atomic.Int32
+		for i := 0; i < 20; i++ {
+			g.Go(func(context.Context) error {
+				current := running.Add(1)
+				for {
+					previous := maxRunning.Load()
+					if current <= previous || maxRunning.CompareAndSwap(previous, current) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				running.Add(-1)
+				return nil
+			})
+		}
+		assert.NoError(t, g.Wait())
+		assert.True(t, maxRunning.Load() <= limit)
+	})
+
+	t.Run("when the limit is reached it should not start a function with try go", func(t *testing.T) {
+		t.Parallel()
+		g, _ := group.WithContext(context.Background())
+		g.SetLimit(1)
+		release := make(chan struct{})
+		assert.True(t, g.TryGo(func(context.Context) error {
+			<-release
+			return nil
+		}))
+		assert.False(t, g.TryGo(func(context.Context) error {
+			return nil
+		}))
+		assert.PanicPart(t, func() {
+			g.SetLimit(2)
+		}, "the limit cannot be changed while 1 functions are running")
+		close(release)
+		assert.NoError(t, g.Wait())
+		g.SetLimit(-1)
+		assert.True(t, g.TryGo(func(context.Context) error {
+			return nil
+		}))
+		assert.NoError(t, g.Wait())
+	})
+
+	t.Run("when the group is shut down it should cancel the functions and ignore the cancellations", func(t *testing.T) {
+		t.Parallel()
+		g, _ := group.WithContext(context.Background())
+		g.Go(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		assert.NoError(t, g.Shutdown(context.Background()))
+		assert.NoError(t, g.Shutdown(context.Background()))
+		assert.NoError(t, g.Run())
+	})
+
+	t.Run("when the functions do not stop before the shutdown deadline it should return an error", func(t *testing.T) {
+		t.Parallel()
+		g, _ := group.WithContext(context.Background())
+		release := make(chan struct{})
+		g.Go(func(context.Context) error {
+			<-release
+			return errors.New("function error")
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		assert.ErrorPart(t, g.Shutdown(ctx), "the functions of the group did not stop in time")
+		close(release)
+		assert.ErrorExact(t, g.Run(), "function error")
+	})
+
+	t.Run("when the group is managed by the lifecycle it should be stopped on shutdown", func(t *testing.T) {
+		t.Parallel()
+		g, _ := group.WithContext(context.Background())
+		var stopped atomic.Bool
+		g.Go(func(ctx context.Context) error {
+			<-ctx.Done()
+			stopped.Store(true)
+			return ctx.Err()
+		})
+		manager := lifecycle.New(lifecycle.WithSignals())
+		manager.MustAdd("group", g)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.NoError(t, manager.Run(ctx))
+		assert.True(t, stopped.Load())
+	})
+
+	t.Run("when a function of a group managed by the lifecycle fails it should stop the services", func(t *testing.T) {
+		t.Parallel()
+		g, _ := group.WithContext(context.Background())
+		g.Go(func(context.Context) error {
+			return errors.New("function error")
+		})
+		manager := lifecycle.New(lifecycle.WithSignals())
+		manager.MustAdd("group", g)
+		err := manager.Run(context.Background())
+		assert.ErrorPart(t, err, "the service 'group' failed (function error)")
+	})
+}
@@ -0,0 +1,43 @@
+package workerpool
+
+import (
+	"context"
+)
+
+// Future is the result of a task submitted to a Pool. It is available once the task is done.
+type Future[T any] struct {
+	done  chan struct{}
+	value T
+	err   error
+}
+
+// newFuture creates a Future that is not done.
+func newFuture[T any]() *Future[T] {
+	return &Future[T]{
+		done: make(chan struct{}),
+	}
+}
+
+// complete sets the result of the Future and wakes its waiters. It must be called once.
+func (f *Future[T]) complete(value T, err error) {
+	f.value = value
+	f.err = err
+	close(f.done)
+}
+
+// Done returns a channel that is closed once the task is done.
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait waits for the task to be done and returns its result. If the context is done first, its error is returned,
+// but the task keeps running.
+func (f *Future[T]) Wait(ctx context.Context) (T, error) {
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		var zeroValue T
+		return zeroValue, ctx.Err()
+	}
+}
@@ -0,0 +1,84 @@
+package workerpool
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// Hooks are called as the tasks move through the Pool, to record metrics. Any of them can be nil.
+// They are called from the goroutines of the submitters and the workers, so they must be safe for concurrent use.
+type Hooks struct {
+	// TaskSubmitted is called when a task is added to the queue of the Pool.
+	TaskSubmitted func()
+
+	// TaskStarted is called when a worker starts a task, with the time the task waited in the queue.
+	TaskStarted func(queued time.Duration)
+
+	// TaskFinished is called when a task is done, with the time it ran and its error.
+	TaskFinished func(duration time.Duration, err error)
+
+	// TaskPanicked is called when a task panics, with the recovered value and the stack of the task.
+	TaskPanicked func(recovered any, stack []byte)
+}
+
+// config is configured by the Option functions.
+type config struct {
+	workers     int
+	queueSize   int
+	taskTimeout time.Duration
+	hooks       Hooks
+}
+
+// Option is used to configure the Pool.
+type Option func(cfg *config)
+
+// WithWorkers sets the number of tasks that run at the same time. The default is GOMAXPROCS.
+// If the number of workers is not positive, this function panics.
+func WithWorkers(workers int) Option {
+	if workers <= 0 {
+		panic(fmt.Sprintf("the number of workers %d must be greater than zero", workers))
+	}
+	return func(cfg *config) {
+		cfg.workers = workers
+	}
+}
+
+// WithQueueSize sets the number of tasks that wait for a worker. When the queue is full, Submit waits for room.
+// The default is the number of workers. If the queue size is not positive, this function panics.
+func WithQueueSize(queueSize int) Option {
+	if queueSize <= 0 {
+		panic(fmt.Sprintf("the queue size %d must be greater than zero", queueSize))
+	}
+	return func(cfg *config) {
+		cfg.queueSize = queueSize
+	}
+}
+
+// WithTaskTimeout sets the time each task has to run. The context of the task is canceled once it expires.
+// By default, the tasks have no timeout. If the timeout is not positive, this function panics.
+func WithTaskTimeout(timeout time.Duration) Option {
+	if timeout <= 0 {
+		panic(fmt.Sprintf("the task timeout %s must be greater than zero", timeout))
+	}
+	return func(cfg *config) {
+		cfg.taskTimeout = timeout
+	}
+}
+
+// WithHooks sets the functions that are called as the tasks move through the Pool.
+func WithHooks(hooks Hooks) Option {
+	return func(cfg *config) {
+		cfg.hooks = hooks
+	}
+}
+
+// defaultConfig returns the configuration of a Pool without options.
+func defaultConfig() *config {
+	return &config{
+		workers:     runtime.GOMAXPROCS(0),
+		queueSize:   0,
+		taskTimeout: 0,
+		hooks:       Hooks{},
+	}
+}
@@ -0,0 +1,192 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/datastructures/queue"
+)
+
+var (
+	// ErrClosed is returned when submitting to a Pool that is shut down, and by the tasks that were still queued
+	// when the shutdown deadline passed.
+	ErrClosed = errors.New("the worker pool is closed")
+)
+
+// PanicError is the error of a task that panicked.
+type PanicError struct {
+	// Value is the value the task panicked with.
+	Value any
+
+	// Stack is the stack of the task when it panicked.
+	Stack []byte
+}
+
+// Error is the implementation of the error interface.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("the task panicked (%v)", e.Value)
+}
+
+// task is a function queued in the Pool.
+type task struct {
+	ctx      context.Context
+	queuedAt time.Time
+	run      func(ctx context.Context) error
+	abandon  func(err error)
+}
+
+// Pool runs the submitted tasks on a fixed number of workers. The tasks wait in a bounded queue until a worker
+// is free. A panic in a task is recovered and returned as its error, so it does not stop the other tasks.
+//
+// The Pool implements the lifecycle.Service interface, so it can be drained when the process shuts down.
+type Pool struct {
+	cfg     *config
+	tasks   *queue.Queue[*task]
+	workers sync.WaitGroup
+	ctx     context.Context
+	cancel  context.CancelFunc
+	stopped chan struct{}
+}
+
+// New allocates a Pool and starts its workers.
+func New(opts ...Option) *Pool {
+	cfg := defaultConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.queueSize == 0 {
+		cfg.queueSize = cfg.workers
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pool := &Pool{
+		cfg:     cfg,
+		tasks:   queue.New[*task](cfg.queueSize),
+		workers: sync.WaitGroup{},
+		ctx:     ctx,
+		cancel:  cancel,
+		stopped: make(chan struct{}),
+	}
+	for i := 0; i < cfg.workers; i++ {
+		pool.workers.Add(1)
+		go pool.work()
+	}
+	go func() {
+		pool.workers.Wait()
+		pool.cancel()
+		close(pool.stopped)
+	}()
+	return pool
+}
+
+// Submit queues the function in the Pool and returns the Future of its result. If the queue is full, it waits
+// until there is room, or until the context is done. It returns ErrClosed if the Pool is shut down.
+//
+// The function receives a context that is canceled when the context of Submit is canceled, when the task timeout
+// expires, or when the Pool fails to drain before its shutdown deadline. It should return once its context is done.
+func Submit[T any](ctx context.Context, pool *Pool, fn func(ctx context.Context) (T, error)) (*Future[T], error) {
+	if fn == nil {
+		panic("the task function cannot be nil")
+	}
+	future := newFuture[T]()
+	queuedTask := &task{
+		ctx:      ctx,
+		queuedAt: time.Now(),
+		run: func(ctx context.Context) error {
+			value, err := fn(ctx)
+			future.complete(value, err)
+			return err
+		},
+		abandon: func(err error) {
+			var zeroValue T
+			future.complete(zeroValue, err)
+		},
+	}
+	if err := pool.tasks.Push(ctx, queuedTask); err != nil {
+		if errors.Is(err, queue.ErrClosed) {
+			return nil, ErrClosed
+		}
+		return nil, fmt.Errorf("failed to queue the task (%w)", err)
+	}
+	if pool.cfg.hooks.TaskSubmitted != nil {
+		pool.cfg.hooks.TaskSubmitted()
+	}
+	return future, nil
+}
+
+// work runs the queued tasks until the Pool is shut down and its queue is empty.
+func (p *Pool) work() {
+	defer p.workers.Done()
+	for {
+		queuedTask, err := p.tasks.Pop(context.Background())
+		if err != nil {
+			return
+		}
+		if p.ctx.Err() != nil {
+			queuedTask.abandon(ErrClosed)
+			continue
+		}
+		p.runTask(queuedTask)
+	}
+}
+
+// runTask runs the task with its timeout, recovering its panic.
+func (p *Pool) runTask(queuedTask *task) {
+	if p.cfg.hooks.TaskStarted != nil {
+		p.cfg.hooks.TaskStarted(time.Since(queuedTask.queuedAt))
+	}
+
+	ctx, cancel := context.WithCancel(queuedTask.ctx)
+	defer cancel()
+	stopCancelOnClose := context.AfterFunc(p.ctx, cancel)
+	defer stopCancelOnClose()
+	if p.cfg.taskTimeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, p.cfg.taskTimeout)
+		defer cancelTimeout()
+	}
+
+	startedAt := time.Now()
+	err := func() (err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				panicErr := &PanicError{Value: recovered, Stack: debug.Stack()}
+				if p.cfg.hooks.TaskPanicked != nil {
+					p.cfg.hooks.TaskPanicked(panicErr.Value, panicErr.Stack)
+				}
+				queuedTask.abandon(panicErr)
+				err = panicErr
+			}
+		}()
+		return queuedTask.run(ctx)
+	}()
+
+	if p.cfg.hooks.TaskFinished != nil {
+		p.cfg.hooks.TaskFinished(time.Since(startedAt), err)
+	}
+}
+
+// Run blocks until the Pool is shut down and its workers have stopped. It is the implementation of the
+// lifecycle.Service interface, since the workers are started by New.
+func (p *Pool) Run() error {
+	<-p.stopped
+	return nil
+}
+
+// Shutdown stops the Pool from accepting tasks and waits for the queued and running tasks to be done.
+// If the context is done first, the contexts of the running tasks are canceled, the queued tasks fail with
+// ErrClosed, and the error of the context is returned. It is safe to call Shutdown many times.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	p.tasks.Close()
+	select {
+	case <-p.stopped:
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		return fmt.Errorf("the worker pool did not drain in time (%w)", ctx.Err())
+	}
+}
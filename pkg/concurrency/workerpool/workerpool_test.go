@@ -0,0 +1,305 @@
+package workerpool_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/concurrency/workerpool"
+	"github.com/TriangleSide/GoBase/pkg/lifecycle"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestPool(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when the options are invalid it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			workerpool.WithWorkers(0)
+		}, "the number of workers 0 must be greater than zero")
+		assert.PanicPart(t, func() {
+			workerpool.WithQueueSize(0)
+		}, "the queue size 0 must be greater than zero")
+		assert.PanicPart(t, func() {
+			workerpool.WithTaskTimeout(0)
+		}, "the task timeout 0s must be greater than zero")
+	})
+
+	t.Run("when the task function is nil it should panic", func(t *testing.T) {
+		t.Parallel()
+		pool := workerpool.New()
+		defer func() { _ = pool.Shutdown(context.Background()) }()
+		assert.PanicPart(t, func() {
+			_, _ = workerpool.Submit[int](context.Background(), pool, nil)
+		}, "the task function cannot be nil")
+	})
+
+	t.Run("when tasks are submitted it should return their results in futures", func(t *testing.T) {
+		t.Parallel()
+		pool := workerpool.New(workerpool.WithWorkers(2))
+		defer func() { _ = pool.Shutdown(context.Background()) }()
+		futures := make([]*workerpool.Future[int], 10)
+		for i := range futures {
+			future, err := workerpool.Submit(context.Background(), pool, func(context.Context) (int, error) {
+				return i * 2, nil
+			})
+			assert.NoError(t, err)
+			futures[i] = future
+		}
+		for i, future := range futures {
+			value, err := future.Wait(context.Background())
+			assert.NoError(t, err, assert.Continue())
+			assert.Equals(t, value, i*2, assert.Continue())
+		}
+	})
+
+	t.Run("when a task fails it should return its error in the future", func(t *testing.T) {
+		t.Parallel()
+		pool := workerpool.New()
+		defer func() { _ = pool.Shutdown(context.Background()) }()
+		future, err := workerpool.Submit(context.Background(), pool, func(context.Context) (string, error) {
+			return "", errors.New("task error")
+		})
+		assert.NoError(t, err)
+		<-future.Done()
+		_, err = future.Wait(context.Background())
+		assert.ErrorExact(t, err, "task error")
+	})
+
+	t.Run("when a task panics it should return a panic error and keep running the other tasks", func(t *testing.T) {
+		t.Parallel()
+		var hookValue atomic.Value
+		pool := workerpool.New(workerpool.WithWorkers(1), workerpool.WithHooks(workerpool.Hooks{
+			TaskPanicked: func(recovered any, stack []byte) {
+				assert.True(t, len(stack) > 0, assert.Continue())
+				hookValue.Store(recovered)
+			},
+		}))
+		defer func() { _ = pool.Shutdown(context.Background()) }()
+		panicking, err := workerpool.Submit(context.Background(), pool, func(context.Context) (int, error) {
+			panic("task panic")
+		})
+		assert.NoError(t, err)
+		_, err = panicking.Wait(context.Background())
+		assert.ErrorExact(t, err, "the task panicked (task panic)")
+		var panicErr *workerpool.PanicError
+		assert.True(t, errors.As(err, &panicErr))
+		assert.Equals(t, panicErr.Value, any("task panic"))
+		assert.Equals(t, hookValue.Load(), any("task panic"))
+
+		next, err := workerpool.Submit(context.Background(), pool, func(context.Context) (int, error) {
+			return 1, nil
+		})
+		assert.NoError(t, err)
+		value, err := next.Wait(context.Background())
+		assert.NoError(t, err)
+		assert.Equals(t, value, 1)
+	})
+
+	t.Run("when there are more tasks than workers it should bound the concurrency", func(t *testing.T) {
+		t.Parallel()
+		const workers = 3
+		pool := workerpool.New(workerpool.WithWorkers(workers), workerpool.WithQueueSize(1))
+		defer func() { _ = pool.Shutdown(context.Background()) }()
+		var running, maxRunning atomic.Int32
+		futures := make([]*workerpool.Future[struct{}], 20)
+		for i := range futures {
+			future, err := workerpool.Submit(context.Background(), pool, func(context.Context) (struct{}, error) {
+				current := running.Add(1)
+				for {
+					previous := maxRunning.Load()
+					if current <= previous || maxRunning.CompareAndSwap(previous, current) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				running.Add(-1)
+				return struct{}{}, nil
+			})
+			assert.NoError(t, err)
+			futures[i] = future
+		}
+		for _, future := range futures {
+			_, err := future.Wait(context.Background())
+			assert.NoError(t, err, assert.Continue())
+		}
+		assert.True(t, maxRunning.Load() <= workers)
+	})
+
+	t.Run("when a task runs longer than the timeout it should cancel its context", func(t *testing.T) {
+		t.Parallel()
+		pool := workerpool.New(workerpool.WithTaskTimeout(10 * time.Millisecond))
+		defer func() { _ = pool.Shutdown(context.Background()) }()
+		future, err := workerpool.Submit(context.Background(), pool, func(ctx context.Context) (int, error) {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		})
+		assert.NoError(t, err)
+		_, err = future.Wait(context.Background())
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("when the submit context is canceled it should cancel the task", func(t *testing.T) {
+		t.Parallel()
+		pool := workerpool.New()
+		defer func() { _ = pool.Shutdown(context.Background()) }()
+		ctx, cancel := context.WithCancel(context.Background())
+		started := make(chan struct{})
+		future, err := workerpool.Submit(ctx, pool, func(ctx context.Context) (int, error) {
+			close(started)
+			<-ctx.Done()
+			return 0, ctx.Err()
+		})
+		assert.NoError(t, err)
+		<-started
+		cancel()
+		_, err = future.Wait(context.Background())
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+
+	t.Run("when the queue is full it should wait for room until the context is done", func(t *testing.T) {
+		t.Parallel()
+		pool := workerpool.New(workerpool.WithWorkers(1), workerpool.WithQueueSize(1))
+		release := make(chan struct{})
+		defer func() {
+			close(release)
+			_ = pool.Shutdown(context.Background())
+		}()
+		blocking := func(context.Context) (int, error) {
+			<-release
+			return 0, nil
+		}
+		started := make(chan struct{})
+		_, err := workerpool.Submit(context.Background(), pool, func(ctx context.Context) (int, error) {
+			close(started)
+			return blocking(ctx)
+		})
+		assert.NoError(t, err)
+		<-started
+		_, err = workerpool.Submit(context.Background(), pool, blocking)
+		assert.NoError(t, err)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err = workerpool.Submit(ctx, pool, blocking)
+		assert.ErrorPart(t, err, "failed to queue the task")
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("when the future is waited with a done context it should return the error of the context", func(t *testing.T) {
+		t.Parallel()
+		pool := workerpool.New()
+		release := make(chan struct{})
+		defer func() {
+			close(release)
+			_ = pool.Shutdown(context.Background())
+		}()
+		future, err := workerpool.Submit(context.Background(), pool, func(context.Context) (int, error) {
+			<-release
+			return 1, nil
+		})
+		assert.NoError(t, err)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = future.Wait(ctx)
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+
+	t.Run("when the pool is shut down it should drain the queued tasks and reject new ones", func(t *testing.T) {
+		t.Parallel()
+		pool := workerpool.New(workerpool.WithWorkers(1), workerpool.WithQueueSize(10))
+		var completed atomic.Int32
+		for i := 0; i < 10; i++ {
+			_, err := workerpool.Submit(context.Background(), pool, func(context.Context) (int, error) {
+				time.Sleep(time.Millisecond)
+				completed.Add(1)
+				return 0, nil
+			})
+			assert.NoError(t, err)
+		}
+		assert.NoError(t, pool.Shutdown(context.Background()))
+		assert.NoError(t, pool.Shutdown(context.Background()))
+		assert.NoError(t, pool.Run())
+		assert.Equals(t, completed.Load(), int32(10))
+		_, err := workerpool.Submit(context.Background(), pool, func(context.Context) (int, error) {
+			return 0, nil
+		})
+		assert.True(t, errors.Is(err, workerpool.ErrClosed))
+	})
+
+	t.Run("when the pool does not drain before the deadline it should cancel the running and queued tasks", func(t *testing.T) {
+		t.Parallel()
+		pool := workerpool.New(workerpool.WithWorkers(1), workerpool.WithQueueSize(1))
+		started := make(chan struct{})
+		running, err := workerpool.Submit(context.Background(), pool, func(ctx context.Context) (int, error) {
+			close(started)
+			<-ctx.Done()
+			return 0, ctx.Err()
+		})
+		assert.NoError(t, err)
+		<-started
+		queued, err := workerpool.Submit(context.Background(), pool, func(context.Context) (int, error) {
+			return 1, nil
+		})
+		assert.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		err = pool.Shutdown(ctx)
+		assert.ErrorPart(t, err, "the worker pool did not drain in time")
+		_, err = running.Wait(context.Background())
+		assert.True(t, errors.Is(err, context.Canceled))
+		_, err = queued.Wait(context.Background())
+		assert.True(t, errors.Is(err, workerpool.ErrClosed))
+		assert.NoError(t, pool.Run())
+	})
+
+	t.Run("when hooks are set it should call them for each task", func(t *testing.T) {
+		t.Parallel()
+		var submitted, started, finished atomic.Int32
+		var finishedErrors sync.Map
+		pool := workerpool.New(workerpool.WithHooks(workerpool.Hooks{
+			TaskSubmitted: func() { submitted.Add(1) },
+			TaskStarted: func(queued time.Duration) {
+				assert.True(t, queued >= 0, assert.Continue())
+				started.Add(1)
+			},
+			TaskFinished: func(duration time.Duration, err error) {
+				assert.True(t, duration >= 0, assert.Continue())
+				finishedErrors.Store(finished.Add(1), err)
+			},
+		}))
+		future, err := workerpool.Submit(context.Background(), pool, func(context.Context) (int, error) {
+			return 0, errors.New("task error")
+		})
+		assert.NoError(t, err)
+		_, _ = future.Wait(context.Background())
+		assert.NoError(t, pool.Shutdown(context.Background()))
+		assert.Equals(t, submitted.Load(), int32(1))
+		assert.Equals(t, started.Load(), int32(1))
+		assert.Equals(t, finished.Load(), int32(1))
+		finishedErr, _ := finishedErrors.Load(int32(1))
+		assert.ErrorExact(t, finishedErr.(error), "task error")
+	})
+
+	t.Run("when the pool is managed by the lifecycle it should be drained on shutdown", func(t *testing.T) {
+		t.Parallel()
+		pool := workerpool.New()
+		var completed atomic.Bool
+		_, err := workerpool.Submit(context.Background(), pool, func(context.Context) (int, error) {
+			time.Sleep(10 * time.Millisecond)
+			completed.Store(true)
+			return 0, nil
+		})
+		assert.NoError(t, err)
+		manager := lifecycle.New(lifecycle.WithSignals())
+		manager.MustAdd("workers", pool)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.NoError(t, manager.Run(ctx))
+		assert.True(t, completed.Load())
+	})
+}
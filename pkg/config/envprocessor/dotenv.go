@@ -0,0 +1,155 @@
+package envprocessor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	// escapedDollar temporarily replaces escaped dollar signs so they are not expanded.
+	escapedDollar = "\x00"
+)
+
+var (
+	// dotEnvKeyRegex matches the valid variable names of a dotenv file.
+	dotEnvKeyRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_.-]*$`)
+)
+
+// WithDotEnv loads the variables of dotenv files, such as .env, for the processor without changing the environment
+// of the process. The variables of later files take precedence over the variables of earlier files, and the
+// variables of the files take precedence over the environment variables.
+//
+// Each line of a file has the form KEY=VALUE, optionally preceded by export. Lines starting with a hash are comments.
+// Values can be unquoted, single quoted, or double quoted. Unquoted values end at a comment, which is a hash that
+// follows whitespace. Double quoted values can use the \n, \r, \t, \", \\ and \$ escapes. Unquoted and double quoted
+// values expand ${NAME} and $NAME with the variables defined before them in the files, then with the
+// environment variables. Single quoted values are used as they are.
+func WithDotEnv(paths ...string) Option {
+	return func(p *config) {
+		p.dotEnvPaths = append(p.dotEnvPaths, paths...)
+	}
+}
+
+// loadDotEnv reads the dotenv files in order and returns their variables.
+func loadDotEnv(paths []string) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the dotenv file %s (%w)", path, err)
+		}
+		if err := parseDotEnv(string(data), values); err != nil {
+			return nil, fmt.Errorf("failed to parse the dotenv file %s (%w)", path, err)
+		}
+	}
+	return values, nil
+}
+
+// parseDotEnv parses the lines of a dotenv file and sets the variables in the values.
+func parseDotEnv(data string, values map[string]string) error {
+	for index, rawLine := range strings.Split(data, "\n") {
+		lineNumber := index + 1
+		line := strings.TrimSpace(strings.TrimRight(rawLine, "\r"))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, rawValue, found := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !found || !isDotEnvKey(key) {
+			return fmt.Errorf("expecting KEY=VALUE on line %d", lineNumber)
+		}
+
+		value, err := parseDotEnvValue(strings.TrimSpace(rawValue), values)
+		if err != nil {
+			return fmt.Errorf("invalid value on line %d (%w)", lineNumber, err)
+		}
+		values[key] = value
+	}
+	return nil
+}
+
+// isDotEnvKey returns true if the key is a valid variable name. It starts with a letter or an underscore,
+// followed by letters, digits, underscores, dots or dashes.
+func isDotEnvKey(key string) bool {
+	return dotEnvKeyRegex.MatchString(key)
+}
+
+// parseDotEnvValue parses an unquoted, single quoted, or double quoted value.
+func parseDotEnvValue(text string, values map[string]string) (string, error) {
+	if strings.HasPrefix(text, "'") {
+		end := strings.Index(text[1:], "'")
+		if end < 0 {
+			return "", errors.New("unterminated single quoted value")
+		}
+		if err := checkDotEnvTrailing(text[end+2:]); err != nil {
+			return "", err
+		}
+		return text[1 : end+1], nil
+	}
+
+	if strings.HasPrefix(text, `"`) {
+		var sb strings.Builder
+		for i := 1; i < len(text); i++ {
+			char := text[i]
+			switch {
+			case char == '"':
+				if err := checkDotEnvTrailing(text[i+1:]); err != nil {
+					return "", err
+				}
+				return expandDotEnv(sb.String(), values), nil
+			case char == '\\' && i+1 < len(text):
+				i++
+				switch text[i] {
+				case 'n':
+					sb.WriteByte('\n')
+				case 'r':
+					sb.WriteByte('\r')
+				case 't':
+					sb.WriteByte('\t')
+				case '$':
+					sb.WriteString(escapedDollar)
+				default:
+					sb.WriteByte(text[i])
+				}
+			default:
+				sb.WriteByte(char)
+			}
+		}
+		return "", errors.New("unterminated double quoted value")
+	}
+
+	for i := range text {
+		if text[i] == '#' && i > 0 && (text[i-1] == ' ' || text[i-1] == '\t') {
+			text = strings.TrimSpace(text[:i])
+			break
+		}
+	}
+	text = strings.ReplaceAll(text, `\$`, escapedDollar)
+	return expandDotEnv(text, values), nil
+}
+
+// checkDotEnvTrailing returns an error if the text after a quoted value is not empty or a comment.
+func checkDotEnvTrailing(text string) error {
+	text = strings.TrimSpace(text)
+	if text != "" && !strings.HasPrefix(text, "#") {
+		return fmt.Errorf("unexpected characters after the quoted value '%s'", text)
+	}
+	return nil
+}
+
+// expandDotEnv expands ${NAME} and $NAME with the variables defined so far, then with the environment variables.
+// Undefined variables expand to an empty string.
+func expandDotEnv(text string, values map[string]string) string {
+	expanded := os.Expand(text, func(name string) string {
+		if value, found := values[name]; found {
+			return value
+		}
+		return os.Getenv(name)
+	})
+	return strings.ReplaceAll(expanded, escapedDollar, "$")
+}
@@ -0,0 +1,123 @@
+package envprocessor_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/config/envprocessor"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestDotEnv(t *testing.T) {
+	type testStruct struct {
+		Host     string `config_format:"snake"`
+		Port     int    `config_format:"snake"`
+		URL      string `config_format:"snake"`
+		Greeting string `config_format:"snake"`
+		Literal  string `config_format:"snake"`
+		Price    string `config_format:"snake"`
+		Comment  string `config_format:"snake"`
+		Hash     string `config_format:"snake"`
+		Exported string `config_format:"snake"`
+		Empty    string `config_format:"snake"`
+		FromEnv  string `config_format:"snake"`
+		Missing  string `config_format:"snake"`
+	}
+
+	t.Run("when a dotenv file is loaded it should parse the quoting, comments, and expansions", func(t *testing.T) {
+		t.Setenv("DOTENV_TEST_USER", "admin")
+		path := writeConfigFile(t, ".env", strings.Join([]string{
+			"# The address of the service.",
+			"HOST=localhost",
+			"PORT = 8080 # inline comment",
+			"URL=http://${DOTENV_TEST_USER}@$HOST:${PORT}/path",
+			`GREETING="hello\n\"world\" # not a comment" # comment`,
+			`LITERAL='$HOST \n' # comment`,
+			`PRICE=\$5`,
+			"COMMENT=a#b",
+			"HASH=\"\\$HOST\"",
+			"export EXPORTED=yes",
+			"EMPTY=",
+			"FROM_ENV=${DOTENV_TEST_USER}",
+			"MISSING=${DOTENV_TEST_UNDEFINED}",
+			"",
+		}, "\r\n"))
+		conf, err := envprocessor.ProcessAndValidate[testStruct](envprocessor.WithDotEnv(path))
+		assert.NoError(t, err)
+		assert.Equals(t, *conf, testStruct{
+			Host:     "localhost",
+			Port:     8080,
+			URL:      "http://admin@localhost:8080/path",
+			Greeting: "hello\n\"world\" # not a comment",
+			Literal:  `$HOST \n`,
+			Price:    "$5",
+			Comment:  "a#b",
+			Hash:     "$HOST",
+			Exported: "yes",
+			Empty:    "",
+			FromEnv:  "admin",
+			Missing:  "",
+		})
+		_, isSet := os.LookupEnv("HOST")
+		assert.False(t, isSet)
+	})
+
+	t.Run("when many dotenv files are loaded the later files should take precedence", func(t *testing.T) {
+		first := writeConfigFile(t, ".env", "HOST=first\nPORT=1")
+		second := writeConfigFile(t, ".env.local", "HOST=second\nURL=$PORT")
+		conf, err := envprocessor.ProcessAndValidate[testStruct](envprocessor.WithDotEnv(first), envprocessor.WithDotEnv(second))
+		assert.NoError(t, err)
+		assert.Equals(t, conf.Host, "second")
+		assert.Equals(t, conf.Port, 1)
+		assert.Equals(t, conf.URL, "1")
+	})
+
+	t.Run("when a variable is in a dotenv file and the environment the dotenv file should take precedence", func(t *testing.T) {
+		path := writeConfigFile(t, ".env", "HOST=dotenv")
+		t.Setenv("HOST", "env")
+		t.Setenv("PORT", "2")
+		conf, err := envprocessor.ProcessAndValidate[testStruct](envprocessor.WithDotEnv(path))
+		assert.NoError(t, err)
+		assert.Equals(t, conf.Host, "dotenv")
+		assert.Equals(t, conf.Port, 2)
+	})
+
+	t.Run("when a dotenv file has the path of the configuration file it should load it", func(t *testing.T) {
+		configPath := writeConfigFile(t, "config.json", `{"host": "file", "port": 3}`)
+		dotEnvPath := writeConfigFile(t, ".env", "CONFIG_FILE="+configPath+"\nHOST=dotenv")
+		conf, err := envprocessor.ProcessAndValidate[testStruct](envprocessor.WithDotEnv(dotEnvPath), envprocessor.WithConfigFileEnv("CONFIG_FILE"))
+		assert.NoError(t, err)
+		assert.Equals(t, conf.Host, "dotenv")
+		assert.Equals(t, conf.Port, 3)
+	})
+
+	t.Run("when the dotenv file does not exist it should return an error", func(t *testing.T) {
+		conf, err := envprocessor.ProcessAndValidate[testStruct](envprocessor.WithDotEnv(filepath.Join(t.TempDir(), ".env")))
+		assert.ErrorPart(t, err, "failed to read the dotenv file")
+		assert.True(t, errors.Is(err, os.ErrNotExist))
+		assert.Nil(t, conf)
+	})
+
+	for contents, expectedErr := range map[string]string{
+		"HOST":                 "expecting KEY=VALUE on line 1",
+		"\n1HOST=a":            "expecting KEY=VALUE on line 2",
+		"=a":                   "expecting KEY=VALUE on line 1",
+		"HOST='a":              "unterminated single quoted value",
+		`HOST="a`:              "unterminated double quoted value",
+		`HOST="a" b`:           "unexpected characters after the quoted value 'b'",
+		"HOST='a' b":           "unexpected characters after the quoted value 'b'",
+		"MY HOST=a":            "expecting KEY=VALUE on line 1",
+		"HOST=a\nPORT=\"b\\\"": "invalid value on line 2 (unterminated double quoted value)",
+	} {
+		t.Run("when the dotenv file contains "+strings.ReplaceAll(contents, "\n", "\\n")+" it should fail to parse", func(t *testing.T) {
+			path := writeConfigFile(t, ".env", contents)
+			conf, err := envprocessor.ProcessAndValidate[testStruct](envprocessor.WithDotEnv(path))
+			assert.ErrorPart(t, err, "failed to parse the dotenv file")
+			assert.ErrorPart(t, err, expectedErr)
+			assert.Nil(t, conf)
+		})
+	}
+}
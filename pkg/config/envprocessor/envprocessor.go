@@ -1,8 +1,13 @@
 package envprocessor
 
 import (
+	"encoding"
 	"fmt"
 	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
 
 	"github.com/TriangleSide/GoBase/pkg/utils/assign"
 	"github.com/TriangleSide/GoBase/pkg/utils/fields"
@@ -18,16 +23,66 @@ const (
 	// it is transformed into STRUCT_FIELD.
 	FormatTag = "config_format"
 
+	// PrefixTag overrides the prefix of a nested struct field, which is its field name in snake-case by default.
+	// An empty prefix places the fields of the nested struct at the same level as the fields of its parent.
+	PrefixTag = "config_prefix"
+
 	// DefaultTag is the default to use in case there is no environment variable that matches the formatted field name.
 	DefaultTag = "config_default"
 
+	// EnvTag sets the exact name of the environment variable of a field, for names that don't follow a format.
+	// The prefixes are not applied to the name, and the FormatTag is not needed.
+	EnvTag = "config_env"
+
 	// FormatTypeSnake tells the processor to transform the field name into snake-case. StructField becomes STRUCT_FIELD.
 	FormatTypeSnake = "snake"
+
+	// FormatTypeScreaming tells the processor to transform the field name into screaming snake-case.
+	// StructField becomes STRUCT_FIELD. It is the same as FormatTypeSnake, for readers that expect snake-case to
+	// be lower-case.
+	FormatTypeScreaming = "screaming"
+
+	// FormatTypeKebab tells the processor to transform the field name into kebab-case. StructField becomes struct-field.
+	FormatTypeKebab = "kebab"
+
+	// FormatTypeCamel tells the processor to transform the field name into camel-case. StructField becomes structField.
+	FormatTypeCamel = "camel"
+
+	// FormatTypeAsIs tells the processor to use the field name as it is. StructField stays StructField.
+	FormatTypeAsIs = "as-is"
 )
 
 // config is the configuration for the ProcessAndValidate function.
 type config struct {
-	prefix string
+	prefix          string
+	filePath        string
+	filePathEnv     string
+	dotEnvPaths     []string
+	secretResolvers map[string]SecretResolver
+	requireValues   bool
+}
+
+// lookupFunc returns the value of a configuration variable and whether it is set.
+type lookupFunc func(name string) (string, bool)
+
+// mapLookup returns a lookupFunc that finds the values in the map.
+func mapLookup(values map[string]string) lookupFunc {
+	return func(name string) (string, bool) {
+		value, found := values[name]
+		return value, found
+	}
+}
+
+// chainLookups returns a lookupFunc that returns the value of the first lookup that has it.
+func chainLookups(lookups []lookupFunc) lookupFunc {
+	return func(name string) (string, bool) {
+		for _, lookup := range lookups {
+			if value, found := lookup(name); found {
+				return value, true
+			}
+		}
+		return "", false
+	}
 }
 
 // Option is used to set parameters for the environment variable processor.
@@ -41,54 +96,254 @@ func WithPrefix(prefix string) Option {
 	}
 }
 
+// WithRequiredValues makes the processor fail when a field with a FormatTag or an EnvTag has neither a value nor
+// a default. The error lists all the fields without a value.
+func WithRequiredValues() Option {
+	return func(p *config) {
+		p.requireValues = true
+	}
+}
+
 // ProcessAndValidate fills out the fields of a struct from the environment variables.
+//
+// Nested struct fields, and pointers to structs, group configuration under a prefix. The prefix of a nested struct
+// is its field name in snake-case, or the value of its PrefixTag, and is joined with the prefix of its parent.
+// Given a field named Database with a nested field named Host, the processor looks for DATABASE_HOST.
+// A nested struct pointer is only allocated if one of its fields is assigned. Struct fields with a FormatTag
+// are not nested, and their value is decoded from a single environment variable instead.
+//
+// The values can also come from dotenv files with WithDotEnv, and from a configuration file with WithConfigFile
+// or WithConfigFileEnv. The values of the dotenv files take precedence over the environment variables,
+// which take precedence over the values of the configuration file. Values that reference secrets are resolved
+// before they are assigned with WithSecretReferences and WithSecretResolver.
 func ProcessAndValidate[T any](opts ...Option) (*T, error) {
 	cfg := &config{
-		prefix: "",
+		prefix:          "",
+		filePath:        "",
+		filePathEnv:     "",
+		dotEnvPaths:     nil,
+		secretResolvers: make(map[string]SecretResolver),
+		requireValues:   false,
 	}
 
 	for _, opt := range opts {
 		opt(cfg)
 	}
 
-	fieldsMetadata := fields.StructMetadata[T]()
+	lookups := make([]lookupFunc, 0, 3)
+	if len(cfg.dotEnvPaths) > 0 {
+		dotEnvValues, err := loadDotEnv(cfg.dotEnvPaths)
+		if err != nil {
+			return nil, err
+		}
+		lookups = append(lookups, mapLookup(dotEnvValues))
+	}
+	lookups = append(lookups, os.LookupEnv)
+	if path := configFilePath(cfg, chainLookups(lookups)); path != "" {
+		fileValues, err := loadConfigFile(path, cfg.prefix)
+		if err != nil {
+			return nil, err
+		}
+		lookups = append(lookups, mapLookup(fileValues))
+	}
+	proc := &processor{
+		lookup:          chainLookups(lookups),
+		secretResolvers: cfg.secretResolvers,
+		requireValues:   cfg.requireValues,
+		missing:         make([]string, 0),
+	}
+
 	conf := new(T)
+	if _, err := proc.processStruct(reflect.ValueOf(conf), cfg.prefix, ""); err != nil {
+		return nil, err
+	}
+	if len(proc.missing) > 0 {
+		slices.Sort(proc.missing)
+		return nil, fmt.Errorf("no value or default for the fields %s", strings.Join(proc.missing, ", "))
+	}
+
+	if err := validation.Struct(conf); err != nil {
+		return nil, fmt.Errorf("failed while validating the configuration (%s)", err.Error())
+	}
+
+	return conf, nil
+}
+
+// processor fills out configuration structs.
+type processor struct {
+	lookup          lookupFunc
+	secretResolvers map[string]SecretResolver
+	requireValues   bool
+	missing         []string
+}
+
+// processStruct fills out the fields of the struct that structPtr points to with the values of the lookup.
+// The prefix is prepended to the environment variable names, and the path is prepended to the field names in errors.
+// It returns true if any field of the struct, or of its nested structs, was assigned.
+func (p *processor) processStruct(structPtr reflect.Value, prefix string, path string) (bool, error) {
+	fieldsMetadata := fields.StructMetadataFromType(structPtr.Type().Elem())
+	assigned := false
 
 	for fieldName, fieldMetadata := range fieldsMetadata.Iterator() {
+		fieldPath := fieldName
+		if path != "" {
+			fieldPath = path + "." + fieldName
+		}
+
 		formatValue, hasFormatTag := fieldMetadata.Tags[FormatTag]
-		if !hasFormatTag {
+		exactEnvName, hasEnvTag := fieldMetadata.Tags[EnvTag]
+		if !hasFormatTag && !hasEnvTag {
+			nestedAssigned, err := p.processNestedStruct(structPtr, fieldName, fieldMetadata, prefix, fieldPath)
+			if err != nil {
+				return false, err
+			}
+			assigned = assigned || nestedAssigned
 			continue
 		}
 
 		var formattedEnvName string
-		switch formatValue {
-		case FormatTypeSnake:
-			formattedEnvName = stringcase.CamelToSnake(fieldName)
-			if cfg.prefix != "" {
-				formattedEnvName = fmt.Sprintf("%s_%s", cfg.prefix, formattedEnvName)
+		if hasEnvTag {
+			if exactEnvName == "" {
+				panic(fmt.Sprintf("empty env name for field %s", fieldPath))
 			}
-		default:
-			panic(fmt.Sprintf("invalid config format (%s)", formatValue))
+			formattedEnvName = exactEnvName
+		} else {
+			formattedEnvName = joinPrefix(prefix, formatFieldName(formatValue, fieldName))
 		}
 
-		envValue, hasEnvValue := os.LookupEnv(formattedEnvName)
+		envValue, hasEnvValue := p.lookup(formattedEnvName)
 		if hasEnvValue {
-			if err := assign.StructField(conf, fieldName, envValue); err != nil {
-				return nil, fmt.Errorf("failed to assign env var %s to field %s (%s)", envValue, fieldName, err.Error())
+			if err := p.assign(structPtr, fieldName, fieldPath, envValue, "env var"); err != nil {
+				return false, err
 			}
+			assigned = true
 		} else {
 			defaultValue, hasDefaultTag := fieldMetadata.Tags[DefaultTag]
 			if hasDefaultTag {
-				if err := assign.StructField(conf, fieldName, defaultValue); err != nil {
-					return nil, fmt.Errorf("failed to assign default value %s to field %s (%s)", defaultValue, fieldName, err.Error())
+				if err := p.assign(structPtr, fieldName, fieldPath, defaultValue, "default value"); err != nil {
+					return false, err
 				}
+				assigned = true
+			} else if p.requireValues {
+				p.missing = append(p.missing, fmt.Sprintf("%s (%s)", fieldPath, formattedEnvName))
 			}
 		}
 	}
 
-	if err := validation.Struct(conf); err != nil {
-		return nil, fmt.Errorf("failed while validating the configuration (%s)", err.Error())
+	return assigned, nil
+}
+
+// processNestedStruct fills out a nested struct field with the values of the lookup if the field is a struct,
+// or a pointer to a struct, that can be nested. See isNestable. A nil pointer is only set if a field is assigned.
+func (p *processor) processNestedStruct(structPtr reflect.Value, fieldName string, fieldMetadata *fields.FieldMetadata, prefix string, fieldPath string) (bool, error) {
+	fieldType := fieldMetadata.Type
+	isPtr := fieldType.Kind() == reflect.Ptr
+	if isPtr {
+		fieldType = fieldType.Elem()
+	}
+	if !isNestable(fieldType) {
+		return false, nil
 	}
 
-	return conf, nil
+	fieldValue := structPtr.Elem()
+	for _, anonymousName := range fieldMetadata.Anonymous {
+		fieldValue = fieldValue.FieldByName(anonymousName)
+	}
+	fieldValue = fieldValue.FieldByName(fieldName)
+	if !fieldValue.CanSet() {
+		return false, nil
+	}
+
+	nestedPrefix, hasPrefixTag := fieldMetadata.Tags[PrefixTag]
+	if !hasPrefixTag {
+		nestedPrefix = stringcase.CamelToSnake(fieldName)
+	}
+	nestedPrefix = joinPrefix(prefix, nestedPrefix)
+
+	nestedPtr := reflect.New(fieldType)
+	if isPtr && !fieldValue.IsNil() {
+		nestedPtr = fieldValue
+	} else if !isPtr {
+		nestedPtr = fieldValue.Addr()
+	}
+
+	assigned, err := p.processStruct(nestedPtr, nestedPrefix, fieldPath)
+	if err != nil {
+		return false, err
+	}
+	if isPtr && assigned && fieldValue.IsNil() {
+		fieldValue.Set(nestedPtr)
+	}
+	return assigned, nil
+}
+
+// formatFieldName transforms the field name with the format of the FormatTag.
+// If the format is unknown, a panic occurs.
+func formatFieldName(format string, fieldName string) string {
+	switch format {
+	case FormatTypeSnake, FormatTypeScreaming:
+		return stringcase.CamelToSnake(fieldName)
+	case FormatTypeKebab:
+		return strings.ReplaceAll(strings.ToLower(stringcase.CamelToSnake(fieldName)), "_", "-")
+	case FormatTypeCamel:
+		return lowerCamel(fieldName)
+	case FormatTypeAsIs:
+		return fieldName
+	default:
+		panic(fmt.Sprintf("invalid config format (%s)", format))
+	}
+}
+
+// lowerCamel lowers the leading upper-case letters of a name. The last letter of an upper-case run that is followed
+// by a lower-case letter starts the next word, so HTTPServer becomes httpServer.
+func lowerCamel(name string) string {
+	runes := []rune(name)
+	for i := range runes {
+		if !unicode.IsUpper(runes[i]) {
+			break
+		}
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
+
+// assign resolves the secret reference of the value, if it is one, and assigns the value to the field.
+// The source describes where the value comes from in errors. The values of secrets are not included in errors.
+func (p *processor) assign(structPtr reflect.Value, fieldName string, fieldPath string, value string, source string) error {
+	resolved, isSecret, err := resolveSecret(value, p.secretResolvers)
+	if err != nil {
+		return fmt.Errorf("failed to resolve the secret of the %s of field %s (%w)", source, fieldPath, err)
+	}
+	if err := assign.ReflectedStructField(structPtr, fieldName, resolved); err != nil {
+		if isSecret {
+			// The parsing error is not included because it can contain the value of the secret.
+			return fmt.Errorf("failed to assign the secret of the %s to field %s", source, fieldPath)
+		}
+		return fmt.Errorf("failed to assign %s %s to field %s (%s)", source, value, fieldPath, err.Error())
+	}
+	return nil
+}
+
+// isNestable returns true if the type is a struct that groups configuration fields. Structs that are decoded
+// from text, like time.Time, are not nested.
+func isNestable(fieldType reflect.Type) bool {
+	if fieldType.Kind() != reflect.Struct {
+		return false
+	}
+	textUnmarshalerType := reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	return !fieldType.Implements(textUnmarshalerType) && !reflect.PointerTo(fieldType).Implements(textUnmarshalerType)
+}
+
+// joinPrefix joins a prefix and a name with an underscore. An empty prefix returns the name as is.
+func joinPrefix(prefix string, name string) string {
+	if prefix == "" {
+		return name
+	}
+	if name == "" {
+		return prefix
+	}
+	return prefix + "_" + name
 }
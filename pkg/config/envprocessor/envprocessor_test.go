@@ -1,10 +1,15 @@
 package envprocessor_test
 
 import (
+	"errors"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/TriangleSide/GoBase/pkg/config/envprocessor"
 	"github.com/TriangleSide/GoBase/pkg/test/assert"
+	"github.com/TriangleSide/GoBase/pkg/test/envtest"
+	"github.com/TriangleSide/GoBase/pkg/validation"
 )
 
 func TestEnvProcessor(t *testing.T) {
@@ -130,4 +135,238 @@ func TestEnvProcessor(t *testing.T) {
 		assert.Equals(t, conf.EmbeddedField, EmbeddedValue)
 		assert.Equals(t, conf.Field, FieldValue)
 	})
+
+	t.Run("when a field uses a registered validator it should report the validator error", func(t *testing.T) {
+		validation.RegisterValidator("envprocessor_port_test", func(value any, _ string) error {
+			if port, ok := value.(int); ok && port >= 1024 {
+				return nil
+			}
+			return errors.New("port must not be privileged")
+		})
+		type testStruct struct {
+			Port int `config_format:"snake" validate:"envprocessor_port_test"`
+		}
+
+		t.Setenv("PORT", "8080")
+		conf, err := envprocessor.ProcessAndValidate[testStruct]()
+		assert.NoError(t, err)
+		assert.Equals(t, conf.Port, 8080)
+
+		t.Setenv("PORT", "80")
+		conf, err = envprocessor.ProcessAndValidate[testStruct]()
+		assert.ErrorPart(t, err, "validation failed on field 'Port' with validator 'envprocessor_port_test': port must not be privileged")
+		assert.Nil(t, conf)
+	})
+
+	t.Run("when a struct has nested struct fields it should use their field names as prefixes", func(t *testing.T) {
+		type credentials struct {
+			User     string `config_format:"snake" validate:"required"`
+			Password string `config_format:"snake"`
+		}
+		type database struct {
+			Host        string      `config_format:"snake" validate:"required"`
+			Port        int         `config_format:"snake" config_default:"5432"`
+			Credentials credentials `config_prefix:"AUTH"`
+		}
+		type cache struct {
+			Host string `config_format:"snake"`
+		}
+		type testStruct struct {
+			Database      database
+			ReplicaDB     *database `config_prefix:"REPLICA"`
+			Cache         *cache
+			Flattened     cache `config_prefix:""`
+			JSONEncoded   cache `config_format:"snake"`
+			StartedAt     time.Time
+			Name          string `config_format:"snake"`
+			unexported    cache
+			NotConfigured int
+		}
+
+		envtest.Set(t, map[string]string{
+			"DATABASE_HOST":           "db.local",
+			"DATABASE_AUTH_USER":      "admin",
+			"APP_DATABASE_AUTH_USER":  "prefixed",
+			"REPLICA_HOST":            "replica.local",
+			"REPLICA_AUTH_USER":       "reader",
+			"HOST":                    "flattened.local",
+			"JSON_ENCODED":            `{"Host":"json.local"}`,
+			"NAME":                    "app",
+			"UNEXPORTED_HOST":         "ignored",
+			"DATABASE_PORT":           "5433",
+			"DATABASE_AUTH_PASSWORD":  "secret",
+			"DATABASE_PASSWORD":       "ignored",
+			"CACHE_HOST_NOT_ASSIGNED": "ignored",
+		})
+
+		conf, err := envprocessor.ProcessAndValidate[testStruct]()
+		assert.NoError(t, err)
+		assert.Equals(t, conf.Database, database{
+			Host:        "db.local",
+			Port:        5433,
+			Credentials: credentials{User: "admin", Password: "secret"},
+		})
+		assert.Equals(t, *conf.ReplicaDB, database{
+			Host:        "replica.local",
+			Port:        5432,
+			Credentials: credentials{User: "reader"},
+		})
+		assert.Nil(t, conf.Cache)
+		assert.Equals(t, conf.Flattened.Host, "flattened.local")
+		assert.Equals(t, conf.JSONEncoded.Host, "json.local")
+		assert.True(t, conf.StartedAt.IsZero())
+		assert.Equals(t, conf.Name, "app")
+		assert.Equals(t, conf.unexported, cache{})
+	})
+
+	t.Run("when a nested struct is used with a prefix option it should join the prefixes", func(t *testing.T) {
+		type database struct {
+			Host string `config_format:"snake"`
+		}
+		type testStruct struct {
+			Database database
+		}
+		t.Setenv("APP_DATABASE_HOST", "db.local")
+		conf, err := envprocessor.ProcessAndValidate[testStruct](envprocessor.WithPrefix("APP"))
+		assert.NoError(t, err)
+		assert.Equals(t, conf.Database.Host, "db.local")
+	})
+
+	t.Run("when a nested struct field fails to be assigned it should reference the path of the field", func(t *testing.T) {
+		type database struct {
+			Port int `config_format:"snake"`
+		}
+		type testStruct struct {
+			Database *database
+		}
+		t.Setenv("DATABASE_PORT", "NOT_AN_INT")
+		conf, err := envprocessor.ProcessAndValidate[testStruct]()
+		assert.ErrorPart(t, err, "failed to assign env var NOT_AN_INT to field Database.Port")
+		assert.Nil(t, conf)
+	})
+
+	t.Run("when a nested struct field violates its validation rules it should fail to process", func(t *testing.T) {
+		type database struct {
+			Host string `config_format:"snake" validate:"required"`
+		}
+		type testStruct struct {
+			Database database
+		}
+		conf, err := envprocessor.ProcessAndValidate[testStruct]()
+		assert.ErrorPart(t, err, "validation failed on field 'Host'")
+		assert.Nil(t, conf)
+	})
+
+	t.Run("when fields use the naming formats it should look for the formatted names", func(t *testing.T) {
+		type testStruct struct {
+			SnakeValue     string `config_format:"snake"`
+			ScreamingValue string `config_format:"screaming"`
+			KebabValue     string `config_format:"kebab"`
+			CamelValue     string `config_format:"camel"`
+			HTTPServerName string `config_format:"camel"`
+			AsIsValue      string `config_format:"as-is"`
+		}
+		envtest.Set(t, map[string]string{
+			"SNAKE_VALUE":     "snake",
+			"SCREAMING_VALUE": "screaming",
+			"kebab-value":     "kebab",
+			"camelValue":      "camel",
+			"httpServerName":  "http",
+			"AsIsValue":       "as-is",
+		})
+		conf, err := envprocessor.ProcessAndValidate[testStruct]()
+		assert.NoError(t, err)
+		assert.Equals(t, *conf, testStruct{
+			SnakeValue:     "snake",
+			ScreamingValue: "screaming",
+			KebabValue:     "kebab",
+			CamelValue:     "camel",
+			HTTPServerName: "http",
+			AsIsValue:      "as-is",
+		})
+	})
+
+	t.Run("when a field has an exact env name it should look for it without the prefixes", func(t *testing.T) {
+		type nested struct {
+			Token string `config_env:"legacy_TOKEN"`
+		}
+		type testStruct struct {
+			Port   int `config_env:"PORT_NUMBER" config_format:"snake" config_default:"80"`
+			Nested nested
+		}
+		envtest.Set(t, map[string]string{
+			"PORT_NUMBER":  "8080",
+			"APP_PORT":     "1",
+			"legacy_TOKEN": "token",
+		})
+		conf, err := envprocessor.ProcessAndValidate[testStruct](envprocessor.WithPrefix("APP"))
+		assert.NoError(t, err)
+		assert.Equals(t, conf.Port, 8080)
+		assert.Equals(t, conf.Nested.Token, "token")
+	})
+
+	t.Run("when a field has an empty exact env name it should panic", func(t *testing.T) {
+		type testStruct struct {
+			Value string `config_env:""`
+		}
+		assert.PanicExact(t, func() {
+			_, _ = envprocessor.ProcessAndValidate[testStruct]()
+		}, "empty env name for field Value")
+	})
+
+	t.Run("when values are required it should fail for the fields without a value or a default", func(t *testing.T) {
+		type nested struct {
+			Host string `config_format:"snake"`
+		}
+		type testStruct struct {
+			Name     string `config_format:"snake"`
+			Port     int    `config_format:"snake" config_default:"80"`
+			Token    string `config_env:"API_TOKEN"`
+			Database nested
+			Untagged string
+		}
+		conf, err := envprocessor.ProcessAndValidate[testStruct](envprocessor.WithRequiredValues())
+		assert.ErrorExact(t, err, "no value or default for the fields Database.Host (DATABASE_HOST), Name (NAME), Token (API_TOKEN)")
+		assert.Nil(t, conf)
+
+		envtest.Set(t, map[string]string{
+			"NAME":          "name",
+			"API_TOKEN":     "token",
+			"DATABASE_HOST": "host",
+		})
+		conf, err = envprocessor.ProcessAndValidate[testStruct](envprocessor.WithRequiredValues())
+		assert.NoError(t, err)
+		assert.Equals(t, conf.Port, 80)
+	})
+
+	t.Run("when values are not required it should leave the fields without a value unset", func(t *testing.T) {
+		type testStruct struct {
+			Name string `config_format:"snake"`
+		}
+		conf, err := envprocessor.ProcessAndValidate[testStruct]()
+		assert.NoError(t, err)
+		assert.Equals(t, conf.Name, "")
+	})
+
+	t.Run("when fields are durations, byte sizes and urls it should parse them", func(t *testing.T) {
+		type testStruct struct {
+			Timeout     time.Duration `config_format:"snake" config_default:"30s"`
+			MaxBodySize int64         `config_format:"snake" unit:"bytes"`
+			Endpoint    *url.URL      `config_format:"snake" validate:"required"`
+		}
+		envtest.Set(t, map[string]string{
+			"MAX_BODY_SIZE": "512MiB",
+			"ENDPOINT":      "https://example.com/api",
+		})
+		conf, err := envprocessor.ProcessAndValidate[testStruct]()
+		assert.NoError(t, err)
+		assert.Equals(t, conf.Timeout, 30*time.Second)
+		assert.Equals(t, conf.MaxBodySize, int64(512<<20))
+		assert.Equals(t, conf.Endpoint.String(), "https://example.com/api")
+
+		t.Setenv("MAX_BODY_SIZE", "512 apples")
+		conf, err = envprocessor.ProcessAndValidate[testStruct]()
+		assert.ErrorPart(t, err, "failed to assign env var 512 apples to field MaxBodySize")
+		assert.Nil(t, conf)
+	})
 }
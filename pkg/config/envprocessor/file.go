@@ -0,0 +1,166 @@
+package envprocessor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/TriangleSide/GoBase/pkg/utils/stringcase"
+)
+
+// FileParser parses the contents of a configuration file into a tree of values. The values of the tree
+// can be maps with string keys, slices, strings, booleans, numbers, or nil.
+type FileParser func(data []byte) (map[string]any, error)
+
+var (
+	// fileParsers maps a lower-case file extension, like .json, to the parser of the format.
+	fileParsers = map[string]FileParser{
+		".json": parseJSONFile,
+		".yaml": parseYAMLFile,
+		".yml":  parseYAMLFile,
+		".toml": parseTOMLFile,
+	}
+
+	// fileParsersMutex guards the fileParsers.
+	fileParsersMutex sync.RWMutex
+)
+
+// RegisterFileParser registers the parser of the configuration files with an extension, such as .ini.
+// Registering an extension that already has a parser replaces it. The built-in YAML and TOML parsers only support
+// a subset of the formats, so they can be replaced with complete implementations.
+func RegisterFileParser(extension string, parser FileParser) {
+	if !strings.HasPrefix(extension, ".") {
+		panic(fmt.Sprintf("The file extension '%s' must start with a dot.", extension))
+	}
+	if parser == nil {
+		panic(fmt.Sprintf("The file extension '%s' has a nil parser.", extension))
+	}
+	fileParsersMutex.Lock()
+	defer fileParsersMutex.Unlock()
+	fileParsers[strings.ToLower(extension)] = parser
+}
+
+// WithConfigFile loads the configuration file at the path. The format of the file is chosen from its extension.
+// See WithConfigFileEnv for how the values of the file are matched to the fields.
+func WithConfigFile(path string) Option {
+	return func(p *config) {
+		p.filePath = path
+	}
+}
+
+// WithConfigFileEnv loads the configuration file at the path in the environment variable, when it is set.
+// It takes precedence over the path of WithConfigFile, so a deployment can replace the file of the service.
+//
+// The keys of the file are matched to the fields with the same names as the environment variables. The keys are
+// transformed into snake-case, and the keys of nested objects are joined with an underscore. For example, the key
+// host in the object database becomes DATABASE_HOST. Arrays and objects are also available as JSON for the fields
+// that decode JSON. Environment variables take precedence over the values of the file.
+func WithConfigFileEnv(envName string) Option {
+	return func(p *config) {
+		p.filePathEnv = envName
+	}
+}
+
+// configFilePath returns the path of the configuration file, or an empty string if there is none.
+// The environment variable with the path is found with the lookup.
+func configFilePath(cfg *config, lookup lookupFunc) string {
+	if cfg.filePathEnv != "" {
+		if path, hasPath := lookup(cfg.filePathEnv); hasPath && path != "" {
+			return path
+		}
+	}
+	return cfg.filePath
+}
+
+// loadConfigFile reads and parses the configuration file, then flattens its values into a map keyed by
+// environment variable names. The prefix is prepended to the names.
+func loadConfigFile(path string, prefix string) (map[string]string, error) {
+	extension := strings.ToLower(filepath.Ext(path))
+	fileParsersMutex.RLock()
+	parser, hasParser := fileParsers[extension]
+	fileParsersMutex.RUnlock()
+	if !hasParser {
+		return nil, fmt.Errorf("no parser for the configuration file extension '%s'", extension)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the configuration file %s (%w)", path, err)
+	}
+	values, err := parser(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the configuration file %s (%w)", path, err)
+	}
+
+	flattened := make(map[string]string)
+	if err := flattenFileValues(prefix, values, flattened); err != nil {
+		return nil, fmt.Errorf("failed to flatten the configuration file %s (%w)", path, err)
+	}
+	return flattened, nil
+}
+
+// flattenFileValues adds the values of the tree into the flattened map, keyed by their environment variable names.
+// Objects are also added as JSON, and their keys are joined to the name of the object.
+func flattenFileValues(prefix string, values map[string]any, flattened map[string]string) error {
+	for key, value := range values {
+		name := joinPrefix(prefix, fileKeyToEnvName(key))
+		if nested, isMap := value.(map[string]any); isMap {
+			if err := flattenFileValues(name, nested, flattened); err != nil {
+				return err
+			}
+		}
+		if value == nil {
+			continue
+		}
+		encoded, err := fileValueToString(value)
+		if err != nil {
+			return fmt.Errorf("failed to encode the value of %s (%w)", key, err)
+		}
+		flattened[name] = encoded
+	}
+	return nil
+}
+
+// fileKeyToEnvName transforms a key of a configuration file into snake-case. Dashes, dots and spaces
+// become underscores.
+func fileKeyToEnvName(key string) string {
+	return strings.NewReplacer("-", "_", ".", "_", " ", "_").Replace(stringcase.CamelToSnake(key))
+}
+
+// fileValueToString encodes a value of a configuration file into the string an environment variable would have.
+func fileValueToString(value any) (string, error) {
+	switch typed := value.(type) {
+	case string:
+		return typed, nil
+	case bool:
+		return strconv.FormatBool(typed), nil
+	case int64:
+		return strconv.FormatInt(typed, 10), nil
+	case float64:
+		return strconv.FormatFloat(typed, 'f', -1, 64), nil
+	case json.Number:
+		return typed.String(), nil
+	default:
+		encoded, err := json.Marshal(typed)
+		if err != nil {
+			return "", err
+		}
+		return string(encoded), nil
+	}
+}
+
+// parseJSONFile parses a JSON configuration file. Numbers are kept as they are written.
+func parseJSONFile(data []byte) (map[string]any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	values := make(map[string]any)
+	if err := decoder.Decode(&values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
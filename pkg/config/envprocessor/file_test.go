@@ -0,0 +1,336 @@
+package envprocessor_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/config/envprocessor"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+type fileTestDatabase struct {
+	Host    string        `config_format:"snake" validate:"required"`
+	Port    int           `config_format:"snake" config_default:"5432"`
+	Timeout time.Duration `config_format:"snake"`
+}
+
+type fileTestConfig struct {
+	Name     string            `config_format:"snake" validate:"required"`
+	Debug    bool              `config_format:"snake"`
+	Ratio    float64           `config_format:"snake"`
+	Tags     []string          `config_format:"snake"`
+	Ports    []int             `config_format:"snake"`
+	Labels   map[string]string `config_format:"snake"`
+	Database fileTestDatabase
+}
+
+func writeConfigFile(t *testing.T, name string, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	return path
+}
+
+func TestConfigFile(t *testing.T) {
+	expected := fileTestConfig{
+		Name:   "service",
+		Debug:  true,
+		Ratio:  0.5,
+		Tags:   []string{"a", "b c"},
+		Ports:  []int{80, 443},
+		Labels: map[string]string{"team": "core", "tier": "1"},
+		Database: fileTestDatabase{
+			Host:    "db.local",
+			Port:    5432,
+			Timeout: 0,
+		},
+	}
+
+	for fileName, contents := range map[string]string{
+		"config.json": `{
+			"name": "service",
+			"debug": true,
+			"ratio": 0.5,
+			"tags": ["a", "b c"],
+			"ports": [80, 443],
+			"labels": {"team": "core", "tier": "1"},
+			"database": {"host": "db.local"}
+		}`,
+		"config.yaml": strings.Join([]string{
+			"---",
+			"# The name of the service.",
+			"name: service # inline comment",
+			"debug: true",
+			"ratio: 0.5",
+			"tags: [a, 'b c']",
+			"ports:",
+			"- 80",
+			"- 443",
+			"labels:",
+			"  team: \"core\"",
+			"  tier: '1'",
+			"database:",
+			"  host: db.local",
+			"  port: ~",
+		}, "\n"),
+		"config.toml": strings.Join([]string{
+			"# The name of the service.",
+			`name = "service" # inline comment`,
+			"debug = true",
+			"ratio = 0.5",
+			`tags = ["a", 'b c']`,
+			"ports = [80, 4_43]",
+			`labels = { team = "core", "tier" = "1" }`,
+			"",
+			"[database]",
+			"host = 'db.local'",
+		}, "\n"),
+	} {
+		t.Run("when the configuration is in a "+filepath.Ext(fileName)+" file it should assign its values to the fields", func(t *testing.T) {
+			path := writeConfigFile(t, fileName, contents)
+			conf, err := envprocessor.ProcessAndValidate[fileTestConfig](envprocessor.WithConfigFile(path))
+			assert.NoError(t, err)
+			assert.Equals(t, *conf, expected)
+		})
+	}
+
+	t.Run("when an environment variable is set it should take precedence over the file", func(t *testing.T) {
+		path := writeConfigFile(t, "config.yaml", "name: file\ndatabase:\n  host: file.local\n  port: 1\n")
+		t.Setenv("DATABASE_HOST", "env.local")
+		conf, err := envprocessor.ProcessAndValidate[fileTestConfig](envprocessor.WithConfigFile(path))
+		assert.NoError(t, err)
+		assert.Equals(t, conf.Name, "file")
+		assert.Equals(t, conf.Database.Host, "env.local")
+		assert.Equals(t, conf.Database.Port, 1)
+	})
+
+	t.Run("when a prefix is used the file keys should be relative to the prefix", func(t *testing.T) {
+		path := writeConfigFile(t, "config.toml", "name = \"file\"\n[database]\nhost = \"file.local\"\n")
+		t.Setenv("APP_NAME", "env")
+		conf, err := envprocessor.ProcessAndValidate[fileTestConfig](envprocessor.WithConfigFile(path), envprocessor.WithPrefix("APP"))
+		assert.NoError(t, err)
+		assert.Equals(t, conf.Name, "env")
+		assert.Equals(t, conf.Database.Host, "file.local")
+	})
+
+	t.Run("when the keys are in camel-case or kebab-case they should match the snake-case names", func(t *testing.T) {
+		type testStruct struct {
+			MaxConns    int    `config_format:"snake"`
+			LogLevel    string `config_format:"snake"`
+			ServiceName string `config_format:"snake"`
+		}
+		path := writeConfigFile(t, "config.yaml", "maxConns: 3\nlog-level: debug\nSERVICE_NAME: svc\n")
+		conf, err := envprocessor.ProcessAndValidate[testStruct](envprocessor.WithConfigFile(path))
+		assert.NoError(t, err)
+		assert.Equals(t, *conf, testStruct{MaxConns: 3, LogLevel: "debug", ServiceName: "svc"})
+	})
+
+	t.Run("when an object is assigned to a field with a format tag it should be decoded as JSON", func(t *testing.T) {
+		type testStruct struct {
+			Database fileTestDatabase `config_format:"snake"`
+		}
+		path := writeConfigFile(t, "config.yaml", "database:\n  Host: db.local\n  Port: 1\n")
+		conf, err := envprocessor.ProcessAndValidate[testStruct](envprocessor.WithConfigFile(path))
+		assert.NoError(t, err)
+		assert.Equals(t, conf.Database, fileTestDatabase{Host: "db.local", Port: 1})
+	})
+
+	t.Run("when the path of the file is in an environment variable it should take precedence over the option", func(t *testing.T) {
+		optionPath := writeConfigFile(t, "option.json", `{"name": "option", "database": {"host": "h"}}`)
+		envPath := writeConfigFile(t, "env.json", `{"name": "env", "database": {"host": "h"}}`)
+		opts := []envprocessor.Option{envprocessor.WithConfigFile(optionPath), envprocessor.WithConfigFileEnv("CONFIG_FILE")}
+
+		conf, err := envprocessor.ProcessAndValidate[fileTestConfig](opts...)
+		assert.NoError(t, err)
+		assert.Equals(t, conf.Name, "option")
+
+		t.Setenv("CONFIG_FILE", envPath)
+		conf, err = envprocessor.ProcessAndValidate[fileTestConfig](opts...)
+		assert.NoError(t, err)
+		assert.Equals(t, conf.Name, "env")
+	})
+
+	t.Run("when the file does not exist it should return an error", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "missing.json")
+		conf, err := envprocessor.ProcessAndValidate[fileTestConfig](envprocessor.WithConfigFile(path))
+		assert.ErrorPart(t, err, "failed to read the configuration file")
+		assert.True(t, errors.Is(err, os.ErrNotExist))
+		assert.Nil(t, conf)
+	})
+
+	t.Run("when the file extension is unknown it should return an error", func(t *testing.T) {
+		path := writeConfigFile(t, "config.ini", "name=service")
+		conf, err := envprocessor.ProcessAndValidate[fileTestConfig](envprocessor.WithConfigFile(path))
+		assert.ErrorPart(t, err, "no parser for the configuration file extension '.ini'")
+		assert.Nil(t, conf)
+	})
+
+	t.Run("when the file has an invalid value it should fail to assign it", func(t *testing.T) {
+		path := writeConfigFile(t, "config.json", `{"name": "service", "database": {"host": "h", "port": "abc"}}`)
+		conf, err := envprocessor.ProcessAndValidate[fileTestConfig](envprocessor.WithConfigFile(path))
+		assert.ErrorPart(t, err, "failed to assign env var abc to field Database.Port")
+		assert.Nil(t, conf)
+	})
+
+	for fileName, testCase := range map[string]struct {
+		contents string
+		err      string
+	}{
+		"invalid.json":        {contents: `{"name": `, err: "unexpected EOF"},
+		"array.json":          {contents: `[1]`, err: "cannot unmarshal array"},
+		"indent.yaml":         {contents: "name: a\n  other: b", err: "unexpected indentation on line 2"},
+		"first_indent.yaml":   {contents: "  name: a", err: "unexpected indentation on line 1"},
+		"tabs.yaml":           {contents: "name:\n\tother: b", err: "tabs cannot be used for indentation on line 2"},
+		"no_key.yaml":         {contents: "name", err: "expecting a key on line 1"},
+		"duplicate.yaml":      {contents: "name: a\nname: b", err: "duplicate key 'name' on line 2"},
+		"sequence.yaml":       {contents: "- a", err: "the document must be a mapping"},
+		"flow_map.yaml":       {contents: "name: {a: b}", err: "flow mappings are not supported"},
+		"block_scalar.yaml":   {contents: "name: |\n  text", err: "block scalars are not supported"},
+		"unterminated.yaml":   {contents: "name: [a, b", err: "unterminated flow sequence"},
+		"nested_flow.yaml":    {contents: "name: [[a]]", err: "nested flow collections are not supported"},
+		"double_quote.yaml":   {contents: `name: "abc`, err: "unterminated double quoted string"},
+		"single_quote.yaml":   {contents: `name: 'abc`, err: "unterminated single quoted string"},
+		"array_table.toml":    {contents: "[[servers]]", err: "arrays of tables are not supported on line 1"},
+		"table_header.toml":   {contents: "[database", err: "unterminated table header on line 1"},
+		"empty_key.toml":      {contents: "[a..b]", err: "empty key"},
+		"no_equals.toml":      {contents: "name", err: "expecting a key and a value separated by an equal sign"},
+		"bare_key.toml":       {contents: "my name = 1", err: "invalid bare key 'my name'"},
+		"duplicate.toml":      {contents: "name = 1\nname = 2", err: "duplicate key 'name'"},
+		"not_table.toml":      {contents: "name = 1\n[name]", err: "the key 'name' is not a table"},
+		"missing_value.toml":  {contents: "name =", err: "missing value"},
+		"multiline.toml":      {contents: `name = """a`, err: "multi-line strings are not supported"},
+		"string.toml":         {contents: `name = "a`, err: "unterminated string"},
+		"array.toml":          {contents: "name = [1, 2", err: "unterminated array"},
+		"inline_table.toml":   {contents: "name = {a = 1", err: "unterminated inline table"},
+		"invalid_value.toml":  {contents: "name = abc", err: "invalid value 'abc'"},
+		"invalid_nested.toml": {contents: "name = [abc]", err: "invalid value 'abc'"},
+	} {
+		t.Run("when the file "+fileName+" cannot be parsed it should return an error", func(t *testing.T) {
+			path := writeConfigFile(t, fileName, testCase.contents)
+			conf, err := envprocessor.ProcessAndValidate[fileTestConfig](envprocessor.WithConfigFile(path))
+			assert.ErrorPart(t, err, "failed to parse the configuration file")
+			assert.ErrorPart(t, err, testCase.err)
+			assert.Nil(t, conf)
+		})
+	}
+
+	t.Run("when the YAML file has nested sequences of mappings they should be available as JSON", func(t *testing.T) {
+		type server struct {
+			Host string
+			Port int
+		}
+		type testStruct struct {
+			Servers []server `config_format:"snake"`
+			Matrix  [][]int  `config_format:"snake"`
+			Empty   []string `config_format:"snake"`
+			Hex     int      `config_format:"snake"`
+			Octal   int      `config_format:"snake"`
+			Decimal int      `config_format:"snake"`
+			Text    string   `config_format:"snake"`
+			Quote   string   `config_format:"snake"`
+			Escape  string   `config_format:"snake"`
+			Hash    string   `config_format:"snake"`
+			Nothing *string  `config_format:"snake"`
+		}
+		contents := strings.Join([]string{
+			"servers:",
+			"  - Host: a.local",
+			"    Port: 1",
+			"  - Host: b.local",
+			"    Port: 2",
+			"matrix:",
+			"  -",
+			"    - 1",
+			"    - 2",
+			"  - - 3",
+			"empty: []",
+			"hex: 0x10",
+			"octal: 0o10",
+			"decimal: 010",
+			"text: it's a url http://host:80/path",
+			"quote: 'it''s'",
+			`escape: "a\"b # not a comment"`,
+			"hash: a#b",
+			"nothing:",
+		}, "\n")
+		path := writeConfigFile(t, "config.yml", contents)
+		conf, err := envprocessor.ProcessAndValidate[testStruct](envprocessor.WithConfigFile(path))
+		assert.NoError(t, err)
+		assert.Equals(t, conf.Servers, []server{{Host: "a.local", Port: 1}, {Host: "b.local", Port: 2}})
+		assert.Equals(t, conf.Matrix, [][]int{{1, 2}, {3}})
+		assert.Equals(t, conf.Empty, []string{})
+		assert.Equals(t, conf.Hex, 16)
+		assert.Equals(t, conf.Octal, 8)
+		assert.Equals(t, conf.Decimal, 10)
+		assert.Equals(t, conf.Text, "it's a url http://host:80/path")
+		assert.Equals(t, conf.Quote, "it's")
+		assert.Equals(t, conf.Escape, `a"b # not a comment`)
+		assert.Equals(t, conf.Hash, "a#b")
+		assert.Nil(t, conf.Nothing)
+	})
+
+	t.Run("when the TOML file has dotted keys and nested tables they should be flattened", func(t *testing.T) {
+		type testStruct struct {
+			Database fileTestDatabase
+			Owner    struct {
+				Name string `config_format:"snake"`
+				Born string `config_format:"snake"`
+			}
+			Limits []any `config_format:"snake"`
+			Bits   int   `config_format:"snake"`
+		}
+		contents := strings.Join([]string{
+			`database.host = "db.local"`,
+			`"database".port = 6543`,
+			"bits = 0b101",
+			"limits = [1.5, inf, true, { a = 1 }, [2]]",
+			"[owner]",
+			`name = "Tom \"T\" # Preston"`,
+			"born = 1979-05-27T07:32:00Z",
+		}, "\n")
+		path := writeConfigFile(t, "config.toml", contents)
+		conf, err := envprocessor.ProcessAndValidate[testStruct](envprocessor.WithConfigFile(path))
+		assert.Error(t, err)
+		assert.ErrorPart(t, err, "failed to flatten the configuration file")
+
+		contents = strings.ReplaceAll(contents, "inf, ", "")
+		path = writeConfigFile(t, "config.toml", contents)
+		conf, err = envprocessor.ProcessAndValidate[testStruct](envprocessor.WithConfigFile(path))
+		assert.NoError(t, err)
+		assert.Equals(t, conf.Database, fileTestDatabase{Host: "db.local", Port: 6543})
+		assert.Equals(t, conf.Owner.Name, `Tom "T" # Preston`)
+		assert.Equals(t, conf.Owner.Born, "1979-05-27T07:32:00Z")
+		assert.Equals(t, conf.Limits, []any{1.5, true, map[string]any{"a": float64(1)}, []any{float64(2)}})
+		assert.Equals(t, conf.Bits, 5)
+	})
+
+	t.Run("when a file parser is registered it should be used for its extension", func(t *testing.T) {
+		envprocessor.RegisterFileParser(".Properties", func(data []byte) (map[string]any, error) {
+			values := make(map[string]any)
+			for _, line := range strings.Split(string(data), "\n") {
+				if key, value, found := strings.Cut(line, "="); found {
+					values[key] = value
+				}
+			}
+			return values, nil
+		})
+		path := writeConfigFile(t, "config.properties", "name=service\ndatabase.host=db.local")
+		conf, err := envprocessor.ProcessAndValidate[fileTestConfig](envprocessor.WithConfigFile(path))
+		assert.NoError(t, err)
+		assert.Equals(t, conf.Name, "service")
+		assert.Equals(t, conf.Database.Host, "db.local")
+	})
+
+	t.Run("when a file parser is registered with an invalid extension or a nil parser it should panic", func(t *testing.T) {
+		assert.PanicExact(t, func() {
+			envprocessor.RegisterFileParser("ini", func([]byte) (map[string]any, error) { return nil, nil })
+		}, "The file extension 'ini' must start with a dot.")
+		assert.PanicExact(t, func() {
+			envprocessor.RegisterFileParser(".ini", nil)
+		}, "The file extension '.ini' has a nil parser.")
+	})
+}
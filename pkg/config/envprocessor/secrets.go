@@ -0,0 +1,92 @@
+package envprocessor
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// FileSecretPrefix marks a value as the path of a file that contains the secret, such as
+	// file:///run/secrets/db_password. Trailing line breaks of the file are removed.
+	FileSecretPrefix = "file://"
+
+	// Base64SecretPrefix marks a value as a base64 encoded secret, such as base64:c2VjcmV0.
+	Base64SecretPrefix = "base64:"
+)
+
+// SecretResolver resolves secret references into their values. It allows secrets to be fetched from stores
+// like Vault or SSM instead of being passed as plaintext values.
+type SecretResolver interface {
+	// Resolve receives the reference without its prefix and returns the value of the secret.
+	Resolve(reference string) (string, error)
+}
+
+// SecretResolverFunc is a function that implements the SecretResolver interface.
+type SecretResolverFunc func(reference string) (string, error)
+
+// Resolve calls the function.
+func (f SecretResolverFunc) Resolve(reference string) (string, error) {
+	return f(reference)
+}
+
+// WithSecretReferences resolves the values that start with the FileSecretPrefix or the Base64SecretPrefix
+// before they are assigned. This applies to the values of every source, including the defaults.
+func WithSecretReferences() Option {
+	return func(p *config) {
+		p.secretResolvers[FileSecretPrefix] = SecretResolverFunc(resolveFileSecret)
+		p.secretResolvers[Base64SecretPrefix] = SecretResolverFunc(resolveBase64Secret)
+	}
+}
+
+// WithSecretResolver resolves the values that start with the prefix, such as vault:, with the resolver before they
+// are assigned. When many prefixes match a value, the longest one is used.
+func WithSecretResolver(prefix string, resolver SecretResolver) Option {
+	if prefix == "" {
+		panic("The secret prefix cannot be empty.")
+	}
+	if resolver == nil {
+		panic(fmt.Sprintf("The secret prefix '%s' has a nil resolver.", prefix))
+	}
+	return func(p *config) {
+		p.secretResolvers[prefix] = resolver
+	}
+}
+
+// resolveSecret resolves the value with the resolver of the longest prefix that matches it. It returns true
+// if the value is a secret reference. Values that are not secret references are returned as is.
+func resolveSecret(value string, resolvers map[string]SecretResolver) (string, bool, error) {
+	matchedPrefix := ""
+	for prefix := range resolvers {
+		if strings.HasPrefix(value, prefix) && len(prefix) > len(matchedPrefix) {
+			matchedPrefix = prefix
+		}
+	}
+	if matchedPrefix == "" {
+		return value, false, nil
+	}
+	resolved, err := resolvers[matchedPrefix].Resolve(strings.TrimPrefix(value, matchedPrefix))
+	if err != nil {
+		return "", true, err
+	}
+	return resolved, true, nil
+}
+
+// resolveFileSecret reads the secret from the file at the path.
+func resolveFileSecret(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the secret file (%w)", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// resolveBase64Secret decodes the standard base64 encoded secret.
+func resolveBase64Secret(encoded string) (string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode the base64 secret (%w)", err)
+	}
+	return string(decoded), nil
+}
@@ -0,0 +1,106 @@
+package envprocessor_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/config/envprocessor"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestSecrets(t *testing.T) {
+	type testStruct struct {
+		Password string `config_format:"snake"`
+		APIKey   string `config_format:"snake" config_default:"base64:ZGVmYXVsdA=="`
+		Token    string `config_format:"snake"`
+		Plain    string `config_format:"snake"`
+	}
+
+	t.Run("when secret references are enabled it should resolve file and base64 secrets", func(t *testing.T) {
+		secretPath := writeConfigFile(t, "db_password", "s3cret\n")
+		t.Setenv("PASSWORD", "file://"+secretPath)
+		t.Setenv("TOKEN", "base64:dG9rZW4=")
+		t.Setenv("PLAIN", "plain")
+		conf, err := envprocessor.ProcessAndValidate[testStruct](envprocessor.WithSecretReferences())
+		assert.NoError(t, err)
+		assert.Equals(t, *conf, testStruct{Password: "s3cret", APIKey: "default", Token: "token", Plain: "plain"})
+	})
+
+	t.Run("when secret references are not enabled it should assign the values as is", func(t *testing.T) {
+		t.Setenv("TOKEN", "base64:dG9rZW4=")
+		conf, err := envprocessor.ProcessAndValidate[testStruct]()
+		assert.NoError(t, err)
+		assert.Equals(t, conf.Token, "base64:dG9rZW4=")
+		assert.Equals(t, conf.APIKey, "base64:ZGVmYXVsdA==")
+	})
+
+	t.Run("when a custom resolver is registered it should resolve the values with the longest matching prefix", func(t *testing.T) {
+		references := make([]string, 0)
+		vault := envprocessor.SecretResolverFunc(func(reference string) (string, error) {
+			references = append(references, reference)
+			return "vault-" + reference, nil
+		})
+		kv := envprocessor.SecretResolverFunc(func(reference string) (string, error) {
+			return "kv-" + reference, nil
+		})
+		t.Setenv("PASSWORD", "vault:db/password")
+		t.Setenv("TOKEN", "vault:kv:token")
+		conf, err := envprocessor.ProcessAndValidate[testStruct](
+			envprocessor.WithSecretResolver("vault:", vault),
+			envprocessor.WithSecretResolver("vault:kv:", kv),
+		)
+		assert.NoError(t, err)
+		assert.Equals(t, conf.Password, "vault-db/password")
+		assert.Equals(t, conf.Token, "kv-token")
+		assert.Equals(t, references, []string{"db/password"})
+	})
+
+	t.Run("when a secret file does not exist it should return an error", func(t *testing.T) {
+		t.Setenv("PASSWORD", "file://"+filepath.Join(t.TempDir(), "missing"))
+		conf, err := envprocessor.ProcessAndValidate[testStruct](envprocessor.WithSecretReferences())
+		assert.ErrorPart(t, err, "failed to resolve the secret of the env var of field Password (failed to read the secret file")
+		assert.True(t, errors.Is(err, os.ErrNotExist))
+		assert.Nil(t, conf)
+	})
+
+	t.Run("when a base64 secret is invalid it should return an error", func(t *testing.T) {
+		t.Setenv("TOKEN", "base64:not base64")
+		conf, err := envprocessor.ProcessAndValidate[testStruct](envprocessor.WithSecretReferences())
+		assert.ErrorPart(t, err, "failed to resolve the secret of the env var of field Token (failed to decode the base64 secret")
+		assert.Nil(t, conf)
+	})
+
+	t.Run("when a resolved secret cannot be assigned it should not include the secret in the error", func(t *testing.T) {
+		type intStruct struct {
+			Port int `config_format:"snake"`
+		}
+		t.Setenv("PORT", "base64:c2VjcmV0")
+		conf, err := envprocessor.ProcessAndValidate[intStruct](envprocessor.WithSecretReferences())
+		assert.ErrorPart(t, err, "failed to assign the secret of the env var to field Port")
+		assert.False(t, strings.Contains(err.Error(), `"secret"`))
+		assert.False(t, strings.Contains(err.Error(), "c2VjcmV0"))
+		assert.Nil(t, conf)
+	})
+
+	t.Run("when a resolver fails it should return its error", func(t *testing.T) {
+		resolverErr := errors.New("access denied")
+		t.Setenv("TOKEN", "ssm:/token")
+		conf, err := envprocessor.ProcessAndValidate[testStruct](envprocessor.WithSecretResolver("ssm:", envprocessor.SecretResolverFunc(func(string) (string, error) {
+			return "", resolverErr
+		})))
+		assert.True(t, errors.Is(err, resolverErr))
+		assert.Nil(t, conf)
+	})
+
+	t.Run("when a resolver is registered with an empty prefix or a nil resolver it should panic", func(t *testing.T) {
+		assert.PanicExact(t, func() {
+			envprocessor.WithSecretResolver("", envprocessor.SecretResolverFunc(func(string) (string, error) { return "", nil }))
+		}, "The secret prefix cannot be empty.")
+		assert.PanicExact(t, func() {
+			envprocessor.WithSecretResolver("vault:", nil)
+		}, "The secret prefix 'vault:' has a nil resolver.")
+	})
+}
@@ -0,0 +1,209 @@
+package envprocessor
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseTOMLFile parses a TOML configuration file. It supports a subset of TOML that is common in configuration files:
+// tables, dotted keys, single-line arrays and inline tables, strings, integers, floats, booleans, and comments.
+// Dates and times are kept as strings. Multi-line strings and arrays of tables are not supported.
+func parseTOMLFile(data []byte) (map[string]any, error) {
+	root := make(map[string]any)
+	table := root
+	for index, rawLine := range strings.Split(string(data), "\n") {
+		lineNumber := index + 1
+		line := strings.TrimSpace(stripTOMLComment(strings.TrimRight(rawLine, "\r")))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if strings.HasPrefix(line, "[[") {
+				return nil, fmt.Errorf("arrays of tables are not supported on line %d", lineNumber)
+			}
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("unterminated table header on line %d", lineNumber)
+			}
+			keys, err := splitTOMLKey(line[1 : len(line)-1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid table header on line %d (%w)", lineNumber, err)
+			}
+			table, err = tomlTable(root, keys)
+			if err != nil {
+				return nil, fmt.Errorf("invalid table header on line %d (%w)", lineNumber, err)
+			}
+			continue
+		}
+
+		if err := setTOMLKeyValue(table, line); err != nil {
+			return nil, fmt.Errorf("invalid key value pair on line %d (%w)", lineNumber, err)
+		}
+	}
+	return root, nil
+}
+
+// setTOMLKeyValue parses a key value pair, like a.b = 1, and sets it in the table.
+func setTOMLKeyValue(table map[string]any, text string) error {
+	parts := splitTopLevel(text, '=')
+	if len(parts) < 2 {
+		return errors.New("expecting a key and a value separated by an equal sign")
+	}
+	keys, err := splitTOMLKey(parts[0])
+	if err != nil {
+		return err
+	}
+	value, err := parseTOMLValue(strings.TrimSpace(strings.Join(parts[1:], "=")))
+	if err != nil {
+		return err
+	}
+	parent, err := tomlTable(table, keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+	lastKey := keys[len(keys)-1]
+	if _, duplicate := parent[lastKey]; duplicate {
+		return fmt.Errorf("duplicate key '%s'", lastKey)
+	}
+	parent[lastKey] = value
+	return nil
+}
+
+// tomlTable returns the table at the path of keys, creating the tables that do not exist.
+func tomlTable(root map[string]any, keys []string) (map[string]any, error) {
+	table := root
+	for _, key := range keys {
+		existing, found := table[key]
+		if !found {
+			created := make(map[string]any)
+			table[key] = created
+			table = created
+			continue
+		}
+		existingTable, isTable := existing.(map[string]any)
+		if !isTable {
+			return nil, fmt.Errorf("the key '%s' is not a table", key)
+		}
+		table = existingTable
+	}
+	return table, nil
+}
+
+// splitTOMLKey splits a dotted key into its parts. Each part can be bare or quoted.
+func splitTOMLKey(text string) ([]string, error) {
+	keys := make([]string, 0)
+	for _, part := range splitTopLevel(text, '.') {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return nil, errors.New("empty key")
+		}
+		if strings.HasPrefix(part, `"`) || strings.HasPrefix(part, "'") {
+			unquoted, err := parseTOMLString(part)
+			if err != nil {
+				return nil, err
+			}
+			part = unquoted
+		} else if strings.ContainsAny(part, " \t\"'=[]{}#") {
+			return nil, fmt.Errorf("invalid bare key '%s'", part)
+		}
+		keys = append(keys, part)
+	}
+	return keys, nil
+}
+
+// parseTOMLValue parses a string, number, boolean, array, inline table, or date.
+func parseTOMLValue(text string) (any, error) {
+	switch {
+	case text == "":
+		return nil, errors.New("missing value")
+	case strings.HasPrefix(text, `"""`) || strings.HasPrefix(text, "'''"):
+		return nil, errors.New("multi-line strings are not supported")
+	case strings.HasPrefix(text, `"`) || strings.HasPrefix(text, "'"):
+		return parseTOMLString(text)
+	case strings.HasPrefix(text, "["):
+		if !strings.HasSuffix(text, "]") {
+			return nil, errors.New("unterminated array")
+		}
+		items := make([]any, 0)
+		inner := strings.TrimSpace(text[1 : len(text)-1])
+		for _, item := range splitTopLevel(inner, ',') {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			value, err := parseTOMLValue(item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		}
+		return items, nil
+	case strings.HasPrefix(text, "{"):
+		if !strings.HasSuffix(text, "}") {
+			return nil, errors.New("unterminated inline table")
+		}
+		table := make(map[string]any)
+		inner := strings.TrimSpace(text[1 : len(text)-1])
+		if inner == "" {
+			return table, nil
+		}
+		for _, pair := range splitTopLevel(inner, ',') {
+			if err := setTOMLKeyValue(table, strings.TrimSpace(pair)); err != nil {
+				return nil, err
+			}
+		}
+		return table, nil
+	case text == "true":
+		return true, nil
+	case text == "false":
+		return false, nil
+	}
+
+	number := strings.ReplaceAll(text, "_", "")
+	base := 10
+	if strings.HasPrefix(number, "0x") || strings.HasPrefix(number, "0o") || strings.HasPrefix(number, "0b") {
+		base = 0
+	}
+	if parsed, err := strconv.ParseInt(number, base, 64); err == nil {
+		return parsed, nil
+	}
+	switch number {
+	case "inf", "+inf", "-inf", "nan", "+nan", "-nan":
+		parsed, _ := strconv.ParseFloat(number, 64)
+		return parsed, nil
+	}
+	if parsed, err := strconv.ParseFloat(number, 64); err == nil {
+		return parsed, nil
+	}
+	if len(text) > 0 && text[0] >= '0' && text[0] <= '9' && !strings.ContainsAny(text, " \t,") {
+		return text, nil
+	}
+	return nil, fmt.Errorf("invalid value '%s'", text)
+}
+
+// parseTOMLString parses a basic string with escapes, or a literal string without escapes.
+func parseTOMLString(text string) (string, error) {
+	if len(text) < 2 || text[0] != text[len(text)-1] {
+		return "", errors.New("unterminated string")
+	}
+	if text[0] == '\'' {
+		return text[1 : len(text)-1], nil
+	}
+	return strconv.Unquote(text)
+}
+
+// stripTOMLComment removes the comment of a line. A comment starts with a hash that is outside of a string.
+func stripTOMLComment(line string) string {
+	scanner := quoteScanner{}
+	for i, char := range line {
+		if scanner.inQuotes(line, i, char) {
+			continue
+		}
+		if char == '#' {
+			return line[:i]
+		}
+	}
+	return line
+}
@@ -0,0 +1,320 @@
+package envprocessor
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// yamlLine is a significant line of a YAML document.
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+}
+
+// parseYAMLFile parses a YAML configuration file. It supports a subset of YAML that is common in configuration files:
+// nested block mappings, block sequences, flow sequences of scalars, quoted and plain scalars, and comments.
+// Multi-line scalars, anchors, flow mappings, and multiple documents are not supported.
+func parseYAMLFile(data []byte) (map[string]any, error) {
+	lines := make([]yamlLine, 0)
+	for index, rawLine := range strings.Split(string(data), "\n") {
+		text := strings.TrimRight(stripComment(strings.TrimRight(rawLine, "\r")), " \t")
+		trimmed := strings.TrimLeft(text, " ")
+		if trimmed == "" || (len(lines) == 0 && trimmed == "---") {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf("tabs cannot be used for indentation on line %d", index+1)
+		}
+		lines = append(lines, yamlLine{number: index + 1, indent: len(text) - len(trimmed), text: trimmed})
+	}
+	if len(lines) == 0 {
+		return make(map[string]any), nil
+	}
+	if lines[0].indent != 0 {
+		return nil, fmt.Errorf("unexpected indentation on line %d", lines[0].number)
+	}
+
+	value, next, err := parseYAMLBlock(lines, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	if next < len(lines) {
+		return nil, fmt.Errorf("unexpected indentation on line %d", lines[next].number)
+	}
+	values, isMap := value.(map[string]any)
+	if !isMap {
+		return nil, errors.New("the document must be a mapping")
+	}
+	return values, nil
+}
+
+// isYAMLSequenceItem returns true if the text of the line is an item of a block sequence.
+func isYAMLSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// parseYAMLBlock parses the block mapping or block sequence that starts at the line index with the indent.
+// It returns the value and the index of the first line after the block.
+func parseYAMLBlock(lines []yamlLine, index int, indent int) (any, int, error) {
+	if isYAMLSequenceItem(lines[index].text) {
+		return parseYAMLSequence(lines, index, indent)
+	}
+	return parseYAMLMapping(lines, index, indent)
+}
+
+// parseYAMLMapping parses the entries of a block mapping with the indent.
+func parseYAMLMapping(lines []yamlLine, index int, indent int) (map[string]any, int, error) {
+	mapping := make(map[string]any)
+	for index < len(lines) && lines[index].indent == indent && !isYAMLSequenceItem(lines[index].text) {
+		line := lines[index]
+		key, rawValue, found := cutYAMLKey(line.text)
+		if !found {
+			return nil, 0, fmt.Errorf("expecting a key on line %d", line.number)
+		}
+		if _, duplicate := mapping[key]; duplicate {
+			return nil, 0, fmt.Errorf("duplicate key '%s' on line %d", key, line.number)
+		}
+		index++
+
+		if rawValue != "" {
+			value, err := parseYAMLScalarOrFlow(rawValue)
+			if err != nil {
+				return nil, 0, fmt.Errorf("invalid value on line %d (%w)", line.number, err)
+			}
+			mapping[key] = value
+			continue
+		}
+
+		switch {
+		case index < len(lines) && lines[index].indent > indent:
+			value, next, err := parseYAMLBlock(lines, index, lines[index].indent)
+			if err != nil {
+				return nil, 0, err
+			}
+			mapping[key] = value
+			index = next
+		case index < len(lines) && lines[index].indent == indent && isYAMLSequenceItem(lines[index].text):
+			value, next, err := parseYAMLSequence(lines, index, indent)
+			if err != nil {
+				return nil, 0, err
+			}
+			mapping[key] = value
+			index = next
+		default:
+			mapping[key] = nil
+		}
+	}
+	if index < len(lines) && lines[index].indent > indent {
+		return nil, 0, fmt.Errorf("unexpected indentation on line %d", lines[index].number)
+	}
+	return mapping, index, nil
+}
+
+// parseYAMLSequence parses the items of a block sequence with the indent. An item that starts with a key
+// is a mapping whose entries are indented past the dash.
+func parseYAMLSequence(lines []yamlLine, index int, indent int) ([]any, int, error) {
+	sequence := make([]any, 0)
+	for index < len(lines) && lines[index].indent == indent && isYAMLSequenceItem(lines[index].text) {
+		line := lines[index]
+		itemText := strings.TrimLeft(strings.TrimPrefix(line.text, "-"), " ")
+
+		if itemText == "" {
+			index++
+			if index < len(lines) && lines[index].indent > indent {
+				value, next, err := parseYAMLBlock(lines, index, lines[index].indent)
+				if err != nil {
+					return nil, 0, err
+				}
+				sequence = append(sequence, value)
+				index = next
+			} else {
+				sequence = append(sequence, nil)
+			}
+			continue
+		}
+
+		if _, _, isMapping := cutYAMLKey(itemText); isMapping || isYAMLSequenceItem(itemText) {
+			lines[index] = yamlLine{
+				number: line.number,
+				indent: line.indent + len(line.text) - len(itemText),
+				text:   itemText,
+			}
+			value, next, err := parseYAMLBlock(lines, index, lines[index].indent)
+			if err != nil {
+				return nil, 0, err
+			}
+			sequence = append(sequence, value)
+			index = next
+			continue
+		}
+
+		value, err := parseYAMLScalarOrFlow(itemText)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid value on line %d (%w)", line.number, err)
+		}
+		sequence = append(sequence, value)
+		index++
+	}
+	return sequence, index, nil
+}
+
+// cutYAMLKey splits the text of a mapping entry into its key and value. The key ends at the first colon
+// outside of quotes that is followed by a space or the end of the line.
+func cutYAMLKey(text string) (string, string, bool) {
+	scanner := quoteScanner{}
+	for i, char := range text {
+		if scanner.inQuotes(text, i, char) {
+			continue
+		}
+		if char == ':' && (i+1 == len(text) || text[i+1] == ' ') {
+			key := strings.TrimSpace(text[:i])
+			if unquoted, err := parseYAMLScalar(key); err == nil {
+				if unquotedKey, isString := unquoted.(string); isString {
+					key = unquotedKey
+				}
+			}
+			return key, strings.TrimSpace(text[i+1:]), key != ""
+		}
+	}
+	return "", "", false
+}
+
+// parseYAMLScalarOrFlow parses a scalar, or a flow sequence of scalars like [a, b].
+func parseYAMLScalarOrFlow(text string) (any, error) {
+	switch {
+	case strings.HasPrefix(text, "["):
+		if !strings.HasSuffix(text, "]") {
+			return nil, errors.New("unterminated flow sequence")
+		}
+		items := make([]any, 0)
+		inner := strings.TrimSpace(text[1 : len(text)-1])
+		if inner == "" {
+			return items, nil
+		}
+		for _, item := range splitTopLevel(inner, ',') {
+			item = strings.TrimSpace(item)
+			if strings.HasPrefix(item, "[") || strings.HasPrefix(item, "{") {
+				return nil, errors.New("nested flow collections are not supported")
+			}
+			value, err := parseYAMLScalar(item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		}
+		return items, nil
+	case text == "{}":
+		return make(map[string]any), nil
+	case strings.HasPrefix(text, "{"):
+		return nil, errors.New("flow mappings are not supported")
+	case strings.HasPrefix(text, "|") || strings.HasPrefix(text, ">"):
+		return nil, errors.New("block scalars are not supported")
+	default:
+		return parseYAMLScalar(text)
+	}
+}
+
+// parseYAMLScalar parses a quoted or plain scalar. Plain scalars are resolved to nil, booleans, integers,
+// and floats like the YAML 1.2 core schema, otherwise they are strings.
+func parseYAMLScalar(text string) (any, error) {
+	switch {
+	case strings.HasPrefix(text, `"`):
+		if len(text) < 2 || !strings.HasSuffix(text, `"`) {
+			return nil, errors.New("unterminated double quoted string")
+		}
+		return strconv.Unquote(text)
+	case strings.HasPrefix(text, "'"):
+		if len(text) < 2 || !strings.HasSuffix(text, "'") {
+			return nil, errors.New("unterminated single quoted string")
+		}
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	}
+	switch text {
+	case "", "~", "null", "Null", "NULL":
+		return nil, nil
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	}
+	base := 10
+	if strings.HasPrefix(text, "0x") || strings.HasPrefix(text, "0o") {
+		base = 0
+	}
+	if parsed, err := strconv.ParseInt(text, base, 64); err == nil {
+		return parsed, nil
+	}
+	if parsed, err := strconv.ParseFloat(text, 64); err == nil {
+		return parsed, nil
+	}
+	return text, nil
+}
+
+// stripComment removes the comment of a line. A comment starts with a hash that is outside of quotes and is
+// at the start of the line or after whitespace.
+func stripComment(line string) string {
+	scanner := quoteScanner{}
+	for i, char := range line {
+		if scanner.inQuotes(line, i, char) {
+			continue
+		}
+		if char == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t') {
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// splitTopLevel splits the text on the separator when it is outside of quotes, brackets, and braces.
+func splitTopLevel(text string, separator rune) []string {
+	parts := make([]string, 0)
+	scanner := quoteScanner{}
+	depth := 0
+	start := 0
+	for i, char := range text {
+		if scanner.inQuotes(text, i, char) {
+			continue
+		}
+		switch {
+		case char == '[' || char == '{':
+			depth++
+		case char == ']' || char == '}':
+			depth--
+		case char == separator && depth == 0:
+			parts = append(parts, text[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, text[start:])
+}
+
+// quoteScanner tracks whether the characters of a line are inside a quoted string. A quote only opens a string
+// at the start of a token, so apostrophes inside plain words are not quotes. Double quoted strings can escape quotes.
+type quoteScanner struct {
+	quote   rune
+	escaped bool
+}
+
+// inQuotes returns true if the character at index i of the text is part of a quoted string, including its quotes.
+func (s *quoteScanner) inQuotes(text string, i int, char rune) bool {
+	switch {
+	case s.escaped:
+		s.escaped = false
+		return true
+	case s.quote != 0:
+		if char == '\\' && s.quote == '"' {
+			s.escaped = true
+		} else if char == s.quote {
+			s.quote = 0
+		}
+		return true
+	case (char == '"' || char == '\'') && (i == 0 || strings.ContainsRune(" \t[{,:=.", rune(text[i-1]))):
+		s.quote = char
+		return true
+	default:
+		return false
+	}
+}
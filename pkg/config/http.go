@@ -10,6 +10,15 @@ const (
 	HTTPServerCertEnvName          envprocessor.EnvName = "HTTP_SERVER_CERT"
 	HTTPServerKeyEnvName           envprocessor.EnvName = "HTTP_SERVER_KEY"
 	HTTPServerClientCACertsEnvName envprocessor.EnvName = "HTTP_SERVER_CLIENT_CA_CERTS"
+	HTTPServerHTTP2EnabledEnvName  envprocessor.EnvName = "HTTP_SERVER_HTTP2_ENABLED"
+	HTTPServerH2CEnabledEnvName    envprocessor.EnvName = "HTTP_SERVER_H2C_ENABLED"
+	HTTPServerACMEDomainsEnvName   envprocessor.EnvName = "HTTP_SERVER_ACME_DOMAINS"
+	HTTPServerACMECacheDirEnvName  envprocessor.EnvName = "HTTP_SERVER_ACME_CACHE_DIR"
+	HTTPServerACMEStagingEnvName   envprocessor.EnvName = "HTTP_SERVER_ACME_STAGING"
+	HTTPClientTLSModeEnvName       envprocessor.EnvName = "HTTP_CLIENT_TLS_MODE"
+	HTTPClientCertEnvName          envprocessor.EnvName = "HTTP_CLIENT_CERT"
+	HTTPClientKeyEnvName           envprocessor.EnvName = "HTTP_CLIENT_KEY"
+	HTTPClientRootCACertsEnvName   envprocessor.EnvName = "HTTP_CLIENT_ROOT_CA_CERTS"
 )
 
 // HTTPServerTLSMode represents the TLS mode of the HTTP server.
@@ -24,6 +33,9 @@ const (
 
 	// HTTPServerTLSModeMutualTLS represents HTTP over mutual TLS.
 	HTTPServerTLSModeMutualTLS HTTPServerTLSMode = "mutual_tls"
+
+	// HTTPServerTLSModeACME represents HTTP over TLS with certificates obtained from an ACME certificate authority.
+	HTTPServerTLSModeACME HTTPServerTLSMode = "acme"
 )
 
 // HTTPServer holds configuration parameters for an HTTP server.
@@ -50,8 +62,12 @@ type HTTPServer struct {
 	// If zero, ReadTimeout is used. If both are zero, it means no timeout.
 	HTTPServerHeaderReadTimeoutSeconds int `config_format:"snake" config_default:"0" validate:"gte=0"`
 
-	// HTTPServerTLSMode specifies the TLS mode of the server: off, tls, or mutual_tls.
-	HTTPServerTLSMode HTTPServerTLSMode `config_format:"snake" config_default:"tls" validate:"oneof=off tls mutual_tls"`
+	// HTTPServerShutdownGracePeriodSeconds is the maximum time (in seconds) to wait for in-flight requests when shutting down.
+	// The connections that are still active after the grace period are closed. Zero means no grace period limit.
+	HTTPServerShutdownGracePeriodSeconds int `config_format:"snake" config_default:"0" validate:"gte=0"`
+
+	// HTTPServerTLSMode specifies the TLS mode of the server: off, tls, mutual_tls, or acme.
+	HTTPServerTLSMode HTTPServerTLSMode `config_format:"snake" config_default:"tls" validate:"oneof=off tls mutual_tls acme"`
 
 	// HTTPServerCert is the path to the TLS certificate file.
 	HTTPServerCert string `config_format:"snake" config_default:"" validate:"required_if=HTTPServerTLSMode tls HTTPServerTLSMode mutual_tls,omitempty,filepath"`
@@ -62,6 +78,68 @@ type HTTPServer struct {
 	// HTTPServerClientCACerts is a list of paths to client CA certificate files (used in mutual TLS).
 	HTTPServerClientCACerts []string `config_format:"snake" config_default:"[]" validate:"required_if=HTTPServerTLSMode mutual_tls,dive,required,filepath"`
 
+	// HTTPServerCertReloadIntervalSeconds is how often (in seconds) the certificate, key, and client CA files are
+	// checked for changes. Changed certificates are used for new connections without restarting the server.
+	// Zero means the certificates are only loaded when the server is created.
+	HTTPServerCertReloadIntervalSeconds int `config_format:"snake" config_default:"0" validate:"gte=0"`
+
+	// HTTPServerACMEDomains is the list of domains that certificates are obtained for in acme mode.
+	// Handshakes with any other server name are rejected.
+	HTTPServerACMEDomains []string `config_format:"snake" config_default:"[]" validate:"required_if=HTTPServerTLSMode acme,dive,required,fqdn"`
+
+	// HTTPServerACMEEmail is the contact email of the ACME account. The certificate authority uses it for expiry notices.
+	HTTPServerACMEEmail string `config_format:"snake" config_default:"" validate:"omitempty,email"`
+
+	// HTTPServerACMECacheDir is the directory where the ACME account key and the certificates are stored.
+	HTTPServerACMECacheDir string `config_format:"snake" config_default:"" validate:"required_if=HTTPServerTLSMode acme"`
+
+	// HTTPServerACMEStaging uses the staging environment of Let's Encrypt, whose certificates are not trusted.
+	HTTPServerACMEStaging bool `config_format:"snake" config_default:"false"`
+
+	// HTTPServerACMEDirectoryURL is the directory URL of another ACME certificate authority than Let's Encrypt.
+	// If it is set, HTTPServerACMEStaging is ignored.
+	HTTPServerACMEDirectoryURL string `config_format:"snake" config_default:"" validate:"omitempty,url"`
+
+	// HTTPServerACMEHTTPBindPort is the port of a plain HTTP listener that answers http-01 challenges and
+	// redirects other requests to HTTPS. It is usually 80. Zero disables the listener, in which case only
+	// tls-alpn-01 challenges are solved, and the server must be reachable on port 443.
+	HTTPServerACMEHTTPBindPort uint16 `config_format:"snake" config_default:"0" validate:"gte=0"`
+
 	// HTTPServerMaxHeaderBytes sets the maximum size in bytes of request headers. It doesn't limit the request body size.
 	HTTPServerMaxHeaderBytes int `config_format:"snake" config_default:"1048576" validate:"gte=4096,lte=1073741824"`
+
+	// HTTPServerHTTP2Enabled allows clients to use HTTP/2. With TLS, the protocol is negotiated with ALPN.
+	// Without TLS, HTTP/2 is only served if HTTPServerH2CEnabled is set.
+	// The idle timeout of HTTP/2 connections is HTTPServerIdleTimeoutSeconds.
+	HTTPServerHTTP2Enabled bool `config_format:"snake" config_default:"true"`
+
+	// HTTPServerH2CEnabled serves HTTP/2 cleartext (h2c) with prior knowledge when the TLS mode is off.
+	// This is used behind a load balancer that terminates TLS and forwards requests over HTTP/2.
+	HTTPServerH2CEnabled bool `config_format:"snake" config_default:"false"`
+
+	// HTTPServerHTTP2MaxConcurrentStreams is the maximum number of concurrent streams of an HTTP/2 connection.
+	// Zero uses the default of the http package.
+	HTTPServerHTTP2MaxConcurrentStreams int `config_format:"snake" config_default:"250" validate:"gte=0"`
+}
+
+// HTTPClient holds configuration parameters for an HTTP client.
+// It uses the same TLS modes as the HTTPServer, so a client can be configured to match the server it calls.
+type HTTPClient struct {
+	// HTTPClientTimeoutSeconds is the maximum time (in seconds) of a request, including reading the response body.
+	// Zero means no timeout.
+	HTTPClientTimeoutSeconds int `config_format:"snake" config_default:"30" validate:"gte=0"`
+
+	// HTTPClientTLSMode specifies the TLS mode of the client: off, tls, or mutual_tls.
+	// When the mode is off, the TLS settings of the transport are left unchanged.
+	HTTPClientTLSMode HTTPServerTLSMode `config_format:"snake" config_default:"tls" validate:"oneof=off tls mutual_tls"`
+
+	// HTTPClientCert is the path to the client certificate file (used in mutual TLS).
+	HTTPClientCert string `config_format:"snake" config_default:"" validate:"required_if=HTTPClientTLSMode mutual_tls,omitempty,filepath"`
+
+	// HTTPClientKey is the path to the client private key file (used in mutual TLS).
+	HTTPClientKey string `config_format:"snake" config_default:"" validate:"required_if=HTTPClientTLSMode mutual_tls,omitempty,filepath"`
+
+	// HTTPClientRootCACerts is a list of paths to the CA certificate files used to verify the server.
+	// If it is empty, the CAs of the host are used.
+	HTTPClientRootCACerts []string `config_format:"snake" config_default:"[]" validate:"dive,required,filepath"`
 }
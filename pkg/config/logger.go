@@ -3,4 +3,35 @@ package config
 // Logger contains the values needed to configure the logger.
 type Logger struct {
 	LogLevel string `config_format:"snake" config_default:"INFO" validate:"required,oneof=ERROR WARN INFO DEBUG TRACE"`
+
+	// LogSlowOperationThresholdMilliseconds is how long an instrumented operation, like decoding or validating
+	// request parameters, can take before it is logged as slow. Zero disables the slow operation logs.
+	LogSlowOperationThresholdMilliseconds int `config_format:"snake" config_default:"0" validate:"gte=0"`
+
+	// LogModuleLevels overrides the log level of modules, as comma separated module=level pairs like
+	// "http=debug,cache=warn". The logs of a module are the ones whose context was named with logger.WithModule.
+	LogModuleLevels string `config_format:"snake" config_default:""`
+
+	// LogFilePath is the file the logs are written to. The logs are written to stdout if it is empty.
+	LogFilePath string `config_format:"snake" config_default:"" validate:"omitempty,filepath"`
+
+	// LogFileMaxSizeMegabytes is the size at which the log file is rotated. Zero disables the rotation by size.
+	LogFileMaxSizeMegabytes int `config_format:"snake" config_default:"100" validate:"gte=0"`
+
+	// LogFileRotationIntervalMinutes is how long the log file is written to before it is rotated.
+	// Zero disables the rotation by time.
+	LogFileRotationIntervalMinutes int `config_format:"snake" config_default:"0" validate:"gte=0"`
+
+	// LogFileMaxAgeDays is how long the rotated log files are kept. Zero keeps them regardless of their age.
+	LogFileMaxAgeDays int `config_format:"snake" config_default:"0" validate:"gte=0"`
+
+	// LogFileMaxBackups is how many rotated log files are kept. Zero keeps them regardless of their count.
+	LogFileMaxBackups int `config_format:"snake" config_default:"0" validate:"gte=0"`
+
+	// LogFileCompress compresses the rotated log files with gzip.
+	LogFileCompress bool `config_format:"snake" config_default:"false"`
+
+	// LogFileReopenOnSighup reopens the log file when the process receives a SIGHUP, so tools like logrotate
+	// can move the file.
+	LogFileReopenOnSighup bool `config_format:"snake" config_default:"false"`
 }
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// BuildTLSConfig creates the tls.Config that matches the TLS mode of the HTTPServer configuration.
+// If the TLS mode is off, the returned tls.Config is nil.
+func BuildTLSConfig(cfg *HTTPServer) (*tls.Config, error) {
+	switch cfg.HTTPServerTLSMode {
+	case HTTPServerTLSModeOff:
+		return nil, nil
+	case HTTPServerTLSModeTLS:
+		serverCert, err := tls.LoadX509KeyPair(cfg.HTTPServerCert, cfg.HTTPServerKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the server certificates (%w)", err)
+		}
+		return &tls.Config{
+			MinVersion:   tls.VersionTLS13,
+			Certificates: []tls.Certificate{serverCert},
+		}, nil
+	case HTTPServerTLSModeMutualTLS:
+		if len(cfg.HTTPServerClientCACerts) == 0 {
+			return nil, errors.New("no client CAs provided")
+		}
+		serverCert, err := tls.LoadX509KeyPair(cfg.HTTPServerCert, cfg.HTTPServerKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the server certificates (%w)", err)
+		}
+		clientCAs, err := loadCertPool(cfg.HTTPServerClientCACerts, "client CA")
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client CA certificates (%w)", err)
+		}
+		return &tls.Config{
+			MinVersion:   tls.VersionTLS13,
+			Certificates: []tls.Certificate{serverCert},
+			ClientAuth:   tls.RequireAndVerifyClientCert,
+			ClientCAs:    clientCAs,
+		}, nil
+	case HTTPServerTLSModeACME:
+		return nil, errors.New("the TLS config of the acme mode is built by the ACME manager")
+	default:
+		return nil, fmt.Errorf("invalid TLS mode: %s", cfg.HTTPServerTLSMode)
+	}
+}
+
+// BuildClientTLSConfig creates the tls.Config that matches the TLS mode of the HTTPClient configuration.
+// If the TLS mode is off, the returned tls.Config is nil.
+func BuildClientTLSConfig(cfg *HTTPClient) (*tls.Config, error) {
+	var tlsConfig *tls.Config
+	switch cfg.HTTPClientTLSMode {
+	case HTTPServerTLSModeOff:
+		return nil, nil
+	case HTTPServerTLSModeTLS:
+		tlsConfig = &tls.Config{
+			MinVersion: tls.VersionTLS13,
+		}
+	case HTTPServerTLSModeMutualTLS:
+		clientCert, err := tls.LoadX509KeyPair(cfg.HTTPClientCert, cfg.HTTPClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the client certificates (%w)", err)
+		}
+		tlsConfig = &tls.Config{
+			MinVersion:   tls.VersionTLS13,
+			Certificates: []tls.Certificate{clientCert},
+		}
+	default:
+		return nil, fmt.Errorf("invalid TLS mode: %s", cfg.HTTPClientTLSMode)
+	}
+	if len(cfg.HTTPClientRootCACerts) != 0 {
+		rootCAs, err := loadCertPool(cfg.HTTPClientRootCACerts, "root CA")
+		if err != nil {
+			return nil, fmt.Errorf("failed to load root CA certificates (%w)", err)
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+	return tlsConfig, nil
+}
+
+// loadCertPool loads the CA certificates on the paths into a pool. The kind names the certificates in errors.
+func loadCertPool(caCertPaths []string, kind string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	for _, caCertPath := range caCertPaths {
+		caCert, err := os.ReadFile(caCertPath)
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s certificate on path %s (%w)", kind, caCertPath, err)
+		}
+		if ok := pool.AppendCertsFromPEM(caCert); !ok {
+			return nil, fmt.Errorf("failed to append %s certificate (%s)", kind, caCertPath)
+		}
+	}
+	return pool, nil
+}
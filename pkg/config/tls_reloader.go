@@ -0,0 +1,124 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// TLSReloader holds the TLS configuration of an HTTPServer and reloads it when the certificate files change.
+//
+// The tls.Config returned by TLSConfig gets the server certificate with GetCertificate, and in mutual TLS mode,
+// gets the client CAs with GetConfigForClient. New connections use the certificates of the last successful
+// Reload, so renewed certificates are served without restarting the server. Existing connections are not affected.
+type TLSReloader struct {
+	cfg       *HTTPServer
+	base      *tls.Config
+	current   atomic.Pointer[tls.Config]
+	reloadMtx sync.Mutex
+	digest    []byte
+}
+
+// NewTLSReloader loads the certificates of the HTTPServer configuration. The TLS mode must be tls or mutual_tls.
+func NewTLSReloader(cfg *HTTPServer) (*TLSReloader, error) {
+	if cfg.HTTPServerTLSMode != HTTPServerTLSModeTLS && cfg.HTTPServerTLSMode != HTTPServerTLSModeMutualTLS {
+		return nil, errors.New("the TLS mode must be tls or mutual_tls to reload the certificates")
+	}
+	digest, err := certificateFilesDigest(cfg)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := BuildTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	reloader := &TLSReloader{
+		cfg:    cfg,
+		digest: digest,
+	}
+	reloader.current.Store(tlsConfig)
+	reloader.base = &tls.Config{
+		MinVersion:     tlsConfig.MinVersion,
+		ClientAuth:     tlsConfig.ClientAuth,
+		ClientCAs:      tlsConfig.ClientCAs,
+		GetCertificate: reloader.getCertificate,
+	}
+	if cfg.HTTPServerTLSMode == HTTPServerTLSModeMutualTLS {
+		reloader.base.GetConfigForClient = reloader.getConfigForClient
+	}
+	return reloader, nil
+}
+
+// TLSConfig returns the tls.Config that serves the reloaded certificates.
+//
+// The config is used for every handshake, so its NextProtos must list the application protocols the server
+// supports. An http.Server does not set them on this config, only on its own copy.
+func (r *TLSReloader) TLSConfig() *tls.Config {
+	return r.base
+}
+
+// Certificates returns the server certificates that are currently served.
+func (r *TLSReloader) Certificates() []tls.Certificate {
+	return r.current.Load().Certificates
+}
+
+// Reload loads the certificates again if the content of the certificate, key, or client CA files changed.
+// It returns true if the certificates were replaced. If the new certificates cannot be loaded, the
+// previous ones continue to be served and the error is returned.
+func (r *TLSReloader) Reload() (bool, error) {
+	r.reloadMtx.Lock()
+	defer r.reloadMtx.Unlock()
+
+	digest, err := certificateFilesDigest(r.cfg)
+	if err != nil {
+		return false, err
+	}
+	if bytes.Equal(digest, r.digest) {
+		return false, nil
+	}
+	tlsConfig, err := BuildTLSConfig(r.cfg)
+	if err != nil {
+		return false, err
+	}
+	r.current.Store(tlsConfig)
+	r.digest = digest
+	return true, nil
+}
+
+// getCertificate returns the server certificate that is currently served.
+func (r *TLSReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return &r.current.Load().Certificates[0], nil
+}
+
+// getConfigForClient returns a copy of the base config with the client CAs that are currently trusted.
+func (r *TLSReloader) getConfigForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	tlsConfig := r.base.Clone()
+	tlsConfig.GetConfigForClient = nil
+	tlsConfig.ClientCAs = r.current.Load().ClientCAs
+	return tlsConfig, nil
+}
+
+// certificateFilesDigest returns a digest of the content of the certificate, key, and client CA files.
+// The content is used rather than the modification time because renewals often replace symbolic links.
+func certificateFilesDigest(cfg *HTTPServer) ([]byte, error) {
+	paths := []string{cfg.HTTPServerCert, cfg.HTTPServerKey}
+	if cfg.HTTPServerTLSMode == HTTPServerTLSModeMutualTLS {
+		paths = append(paths, cfg.HTTPServerClientCACerts...)
+	}
+	hash := sha256.New()
+	for _, path := range paths {
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the certificate file %s (%w)", path, err)
+		}
+		fileDigest := sha256.Sum256(contents)
+		hash.Write(fileDigest[:])
+	}
+	return hash.Sum(nil), nil
+}
@@ -0,0 +1,158 @@
+package config_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/config"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+// writeSelfSignedCertificate writes a self-signed certificate with the common name to the certificate and key paths.
+func writeSelfSignedCertificate(t *testing.T, certPath string, keyPath string, commonName string) {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	certTemplate := x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, &certTemplate, &certTemplate, &privateKey.PublicKey, privateKey)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certBytes}), 0644))
+	assert.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)}), 0600))
+}
+
+// servedCommonName returns the common name of the certificate that the config serves.
+func servedCommonName(t *testing.T, tlsConfig *tls.Config) string {
+	t.Helper()
+	certificate, err := tlsConfig.GetCertificate(&tls.ClientHelloInfo{})
+	assert.NoError(t, err)
+	leaf, err := x509.ParseCertificate(certificate.Certificate[0])
+	assert.NoError(t, err)
+	return leaf.Subject.CommonName
+}
+
+func TestTLSReloader(t *testing.T) {
+	t.Parallel()
+
+	newConfig := func(t *testing.T, mode config.HTTPServerTLSMode) *config.HTTPServer {
+		t.Helper()
+		tempDir := t.TempDir()
+		cfg := &config.HTTPServer{
+			HTTPServerTLSMode:       mode,
+			HTTPServerCert:          filepath.Join(tempDir, "cert.pem"),
+			HTTPServerKey:           filepath.Join(tempDir, "key.pem"),
+			HTTPServerClientCACerts: []string{filepath.Join(tempDir, "ca.pem")},
+		}
+		writeSelfSignedCertificate(t, cfg.HTTPServerCert, cfg.HTTPServerKey, "first")
+		writeSelfSignedCertificate(t, cfg.HTTPServerClientCACerts[0], filepath.Join(tempDir, "ca_key.pem"), "first-ca")
+		return cfg
+	}
+
+	t.Run("when the TLS mode is off it should return an error", func(t *testing.T) {
+		t.Parallel()
+		reloader, err := config.NewTLSReloader(&config.HTTPServer{HTTPServerTLSMode: config.HTTPServerTLSModeOff})
+		assert.ErrorExact(t, err, "the TLS mode must be tls or mutual_tls to reload the certificates")
+		assert.Nil(t, reloader)
+	})
+
+	t.Run("when a certificate file is missing it should return an error", func(t *testing.T) {
+		t.Parallel()
+		cfg := newConfig(t, config.HTTPServerTLSModeTLS)
+		cfg.HTTPServerCert = "does_not_exist.pem"
+		reloader, err := config.NewTLSReloader(cfg)
+		assert.ErrorPart(t, err, "failed to read the certificate file does_not_exist.pem")
+		assert.Nil(t, reloader)
+	})
+
+	t.Run("when the certificate files are invalid it should return an error", func(t *testing.T) {
+		t.Parallel()
+		cfg := newConfig(t, config.HTTPServerTLSModeTLS)
+		assert.NoError(t, os.WriteFile(cfg.HTTPServerKey, []byte("invalid"), 0600))
+		reloader, err := config.NewTLSReloader(cfg)
+		assert.ErrorPart(t, err, "failed to load the server certificates")
+		assert.Nil(t, reloader)
+	})
+
+	t.Run("when the files did not change it should not reload", func(t *testing.T) {
+		t.Parallel()
+		reloader, err := config.NewTLSReloader(newConfig(t, config.HTTPServerTLSModeTLS))
+		assert.NoError(t, err)
+		reloaded, err := reloader.Reload()
+		assert.NoError(t, err)
+		assert.False(t, reloaded)
+		assert.Equals(t, servedCommonName(t, reloader.TLSConfig()), "first")
+		assert.Nil(t, reloader.TLSConfig().GetConfigForClient)
+		assert.Equals(t, reloader.TLSConfig().MinVersion, uint16(tls.VersionTLS13))
+	})
+
+	t.Run("when the certificate is renewed it should serve the new certificate", func(t *testing.T) {
+		t.Parallel()
+		cfg := newConfig(t, config.HTTPServerTLSModeTLS)
+		reloader, err := config.NewTLSReloader(cfg)
+		assert.NoError(t, err)
+		writeSelfSignedCertificate(t, cfg.HTTPServerCert, cfg.HTTPServerKey, "second")
+		reloaded, err := reloader.Reload()
+		assert.NoError(t, err)
+		assert.True(t, reloaded)
+		assert.Equals(t, servedCommonName(t, reloader.TLSConfig()), "second")
+		assert.Equals(t, len(reloader.Certificates()), 1)
+	})
+
+	t.Run("when the new certificate cannot be loaded it should keep serving the previous one", func(t *testing.T) {
+		t.Parallel()
+		cfg := newConfig(t, config.HTTPServerTLSModeTLS)
+		reloader, err := config.NewTLSReloader(cfg)
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(cfg.HTTPServerCert, []byte("partially written"), 0644))
+		reloaded, err := reloader.Reload()
+		assert.ErrorPart(t, err, "failed to load the server certificates")
+		assert.False(t, reloaded)
+		assert.Equals(t, servedCommonName(t, reloader.TLSConfig()), "first")
+
+		writeSelfSignedCertificate(t, cfg.HTTPServerCert, cfg.HTTPServerKey, "second")
+		reloaded, err = reloader.Reload()
+		assert.NoError(t, err)
+		assert.True(t, reloaded)
+		assert.Equals(t, servedCommonName(t, reloader.TLSConfig()), "second")
+	})
+
+	t.Run("when the client CAs change in mutual TLS mode it should trust the new CAs", func(t *testing.T) {
+		t.Parallel()
+		cfg := newConfig(t, config.HTTPServerTLSModeMutualTLS)
+		reloader, err := config.NewTLSReloader(cfg)
+		assert.NoError(t, err)
+		reloader.TLSConfig().NextProtos = []string{"h2", "http/1.1"}
+
+		before, err := reloader.TLSConfig().GetConfigForClient(&tls.ClientHelloInfo{})
+		assert.NoError(t, err)
+		assert.Equals(t, before.ClientAuth, tls.RequireAndVerifyClientCert)
+		assert.Equals(t, before.NextProtos, []string{"h2", "http/1.1"})
+		assert.Nil(t, before.GetConfigForClient)
+
+		writeSelfSignedCertificate(t, cfg.HTTPServerClientCACerts[0], filepath.Join(filepath.Dir(cfg.HTTPServerCert), "ca_key.pem"), "second-ca")
+		reloaded, err := reloader.Reload()
+		assert.NoError(t, err)
+		assert.True(t, reloaded)
+		after, err := reloader.TLSConfig().GetConfigForClient(&tls.ClientHelloInfo{})
+		assert.NoError(t, err)
+		assert.False(t, before.ClientCAs.Equal(after.ClientCAs))
+		assert.Equals(t, servedCommonName(t, after), "first")
+	})
+}
@@ -0,0 +1,245 @@
+package config_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/config"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestBuildTLSConfig(t *testing.T) {
+	t.Parallel()
+
+	tempDir := t.TempDir()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	certTemplate := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{Organization: []string{"TLS Config Tests Inc."}},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, &certTemplate, &certTemplate, &privateKey.PublicKey, privateKey)
+	assert.NoError(t, err)
+
+	certPath := filepath.Join(tempDir, "cert.pem")
+	assert.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certBytes}), 0644))
+	keyPath := filepath.Join(tempDir, "key.pem")
+	assert.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)}), 0600))
+	invalidPath := filepath.Join(tempDir, "invalid.pem")
+	assert.NoError(t, os.WriteFile(invalidPath, []byte("invalid data"), 0644))
+
+	newConfig := func(mode config.HTTPServerTLSMode) *config.HTTPServer {
+		return &config.HTTPServer{
+			HTTPServerTLSMode:       mode,
+			HTTPServerCert:          certPath,
+			HTTPServerKey:           keyPath,
+			HTTPServerClientCACerts: []string{certPath},
+		}
+	}
+
+	t.Run("when the TLS mode is off it should return a nil config", func(t *testing.T) {
+		t.Parallel()
+		tlsConfig, err := config.BuildTLSConfig(newConfig(config.HTTPServerTLSModeOff))
+		assert.NoError(t, err)
+		assert.Nil(t, tlsConfig)
+	})
+
+	t.Run("when the TLS mode is acme it should fail since the ACME manager builds the config", func(t *testing.T) {
+		t.Parallel()
+		tlsConfig, err := config.BuildTLSConfig(newConfig(config.HTTPServerTLSModeACME))
+		assert.ErrorExact(t, err, "the TLS config of the acme mode is built by the ACME manager")
+		assert.Nil(t, tlsConfig)
+	})
+
+	t.Run("when the TLS mode is invalid it should fail", func(t *testing.T) {
+		t.Parallel()
+		tlsConfig, err := config.BuildTLSConfig(newConfig("invalid_mode"))
+		assert.ErrorExact(t, err, "invalid TLS mode: invalid_mode")
+		assert.Nil(t, tlsConfig)
+	})
+
+	t.Run("when the TLS mode is TLS it should load the server certificate", func(t *testing.T) {
+		t.Parallel()
+		tlsConfig, err := config.BuildTLSConfig(newConfig(config.HTTPServerTLSModeTLS))
+		assert.NoError(t, err)
+		assert.NotNil(t, tlsConfig)
+		assert.Equals(t, len(tlsConfig.Certificates), 1)
+		assert.Equals(t, tlsConfig.MinVersion, uint16(tls.VersionTLS13))
+		assert.Equals(t, tlsConfig.ClientAuth, tls.NoClientCert)
+	})
+
+	t.Run("when the TLS mode is mutual TLS it should require and verify client certificates", func(t *testing.T) {
+		t.Parallel()
+		tlsConfig, err := config.BuildTLSConfig(newConfig(config.HTTPServerTLSModeMutualTLS))
+		assert.NoError(t, err)
+		assert.NotNil(t, tlsConfig)
+		assert.Equals(t, len(tlsConfig.Certificates), 1)
+		assert.Equals(t, tlsConfig.ClientAuth, tls.RequireAndVerifyClientCert)
+		assert.NotNil(t, tlsConfig.ClientCAs)
+	})
+
+	t.Run("when the server certificate or key is missing or invalid it should fail", func(t *testing.T) {
+		t.Parallel()
+		for _, mode := range []config.HTTPServerTLSMode{config.HTTPServerTLSModeTLS, config.HTTPServerTLSModeMutualTLS} {
+			for _, modify := range []func(cfg *config.HTTPServer){
+				func(cfg *config.HTTPServer) { cfg.HTTPServerCert = "" },
+				func(cfg *config.HTTPServer) { cfg.HTTPServerKey = "" },
+				func(cfg *config.HTTPServer) { cfg.HTTPServerCert = invalidPath },
+				func(cfg *config.HTTPServer) { cfg.HTTPServerKey = invalidPath },
+			} {
+				cfg := newConfig(mode)
+				modify(cfg)
+				tlsConfig, err := config.BuildTLSConfig(cfg)
+				assert.ErrorPart(t, err, "failed to load the server certificates")
+				assert.Nil(t, tlsConfig)
+			}
+		}
+	})
+
+	t.Run("when the client CAs are missing in mutual TLS mode it should fail", func(t *testing.T) {
+		t.Parallel()
+		cfg := newConfig(config.HTTPServerTLSModeMutualTLS)
+		cfg.HTTPServerClientCACerts = []string{}
+		tlsConfig, err := config.BuildTLSConfig(cfg)
+		assert.ErrorExact(t, err, "no client CAs provided")
+		assert.Nil(t, tlsConfig)
+	})
+
+	t.Run("when a client CA does not exist it should fail", func(t *testing.T) {
+		t.Parallel()
+		cfg := newConfig(config.HTTPServerTLSModeMutualTLS)
+		cfg.HTTPServerClientCACerts = []string{"does_not_exist.pem"}
+		tlsConfig, err := config.BuildTLSConfig(cfg)
+		assert.ErrorPart(t, err, "could not read client CA certificate")
+		assert.Nil(t, tlsConfig)
+	})
+
+	t.Run("when a client CA is invalid it should fail", func(t *testing.T) {
+		t.Parallel()
+		cfg := newConfig(config.HTTPServerTLSModeMutualTLS)
+		cfg.HTTPServerClientCACerts = []string{invalidPath}
+		tlsConfig, err := config.BuildTLSConfig(cfg)
+		assert.ErrorPart(t, err, "failed to load client CA certificates")
+		assert.Nil(t, tlsConfig)
+	})
+}
+
+func TestBuildClientTLSConfig(t *testing.T) {
+	t.Parallel()
+
+	tempDir := t.TempDir()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	certTemplate := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{Organization: []string{"TLS Config Tests Inc."}},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, &certTemplate, &certTemplate, &privateKey.PublicKey, privateKey)
+	assert.NoError(t, err)
+
+	certPath := filepath.Join(tempDir, "cert.pem")
+	assert.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certBytes}), 0644))
+	keyPath := filepath.Join(tempDir, "key.pem")
+	assert.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)}), 0600))
+	invalidPath := filepath.Join(tempDir, "invalid.pem")
+	assert.NoError(t, os.WriteFile(invalidPath, []byte("invalid data"), 0644))
+
+	newConfig := func(mode config.HTTPServerTLSMode) *config.HTTPClient {
+		return &config.HTTPClient{
+			HTTPClientTLSMode:     mode,
+			HTTPClientCert:        certPath,
+			HTTPClientKey:         keyPath,
+			HTTPClientRootCACerts: []string{certPath},
+		}
+	}
+
+	t.Run("when the TLS mode is off it should return a nil config", func(t *testing.T) {
+		t.Parallel()
+		tlsConfig, err := config.BuildClientTLSConfig(newConfig(config.HTTPServerTLSModeOff))
+		assert.NoError(t, err)
+		assert.Nil(t, tlsConfig)
+	})
+
+	t.Run("when the TLS mode is invalid it should fail", func(t *testing.T) {
+		t.Parallel()
+		tlsConfig, err := config.BuildClientTLSConfig(newConfig("invalid_mode"))
+		assert.ErrorExact(t, err, "invalid TLS mode: invalid_mode")
+		assert.Nil(t, tlsConfig)
+	})
+
+	t.Run("when the TLS mode is TLS it should only load the root CAs", func(t *testing.T) {
+		t.Parallel()
+		tlsConfig, err := config.BuildClientTLSConfig(newConfig(config.HTTPServerTLSModeTLS))
+		assert.NoError(t, err)
+		assert.NotNil(t, tlsConfig)
+		assert.Equals(t, len(tlsConfig.Certificates), 0)
+		assert.Equals(t, tlsConfig.MinVersion, uint16(tls.VersionTLS13))
+		assert.NotNil(t, tlsConfig.RootCAs)
+	})
+
+	t.Run("when there are no root CAs it should use the CAs of the host", func(t *testing.T) {
+		t.Parallel()
+		cfg := newConfig(config.HTTPServerTLSModeTLS)
+		cfg.HTTPClientRootCACerts = nil
+		tlsConfig, err := config.BuildClientTLSConfig(cfg)
+		assert.NoError(t, err)
+		assert.Nil(t, tlsConfig.RootCAs)
+	})
+
+	t.Run("when the TLS mode is mutual TLS it should load the client certificate", func(t *testing.T) {
+		t.Parallel()
+		tlsConfig, err := config.BuildClientTLSConfig(newConfig(config.HTTPServerTLSModeMutualTLS))
+		assert.NoError(t, err)
+		assert.NotNil(t, tlsConfig)
+		assert.Equals(t, len(tlsConfig.Certificates), 1)
+		assert.NotNil(t, tlsConfig.RootCAs)
+	})
+
+	t.Run("when the client certificate or key is missing or invalid it should fail", func(t *testing.T) {
+		t.Parallel()
+		for _, modify := range []func(cfg *config.HTTPClient){
+			func(cfg *config.HTTPClient) { cfg.HTTPClientCert = "" },
+			func(cfg *config.HTTPClient) { cfg.HTTPClientKey = invalidPath },
+		} {
+			cfg := newConfig(config.HTTPServerTLSModeMutualTLS)
+			modify(cfg)
+			tlsConfig, err := config.BuildClientTLSConfig(cfg)
+			assert.ErrorPart(t, err, "failed to load the client certificates")
+			assert.Nil(t, tlsConfig)
+		}
+	})
+
+	t.Run("when a root CA does not exist or is invalid it should fail", func(t *testing.T) {
+		t.Parallel()
+		for _, path := range []string{"does_not_exist.pem", invalidPath} {
+			cfg := newConfig(config.HTTPServerTLSModeTLS)
+			cfg.HTTPClientRootCACerts = []string{path}
+			tlsConfig, err := config.BuildClientTLSConfig(cfg)
+			assert.ErrorPart(t, err, "failed to load root CA certificates")
+			assert.Nil(t, tlsConfig)
+		}
+	})
+}
@@ -1,7 +1,9 @@
 package cache
 
 import (
+	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -9,137 +11,359 @@ import (
 // If the value is not present, or if it's expired, the function gets called.
 type GetOrSetFn[Key comparable, Value any] func(Key) (Value, *time.Duration, error)
 
-// getOrSetKeyLock is used by the GetOrSet function to make sure the function is not executed in parallel.
+// getOrSetKeyLock is used by the GetOrSet and LoadOrRefresh functions to make sure the function of a key is not
+// executed in parallel.
 type getOrSetKeyLock[Value any] struct {
 	WaitChan chan struct{}
 	FnValue  Value
 	FnError  error
 }
 
+// Stats are the counters of a Cache.
+type Stats struct {
+	// Hits is the number of times a key was found.
+	Hits uint64
+
+	// Misses is the number of times a key was not found, or was expired.
+	Misses uint64
+
+	// Evictions is the number of entries evicted because of the capacity of the Cache.
+	Evictions uint64
+
+	// Expirations is the number of expired entries that were removed.
+	Expirations uint64
+
+	// Entries is the number of entries in the Cache, including the expired entries that were not removed yet.
+	Entries int
+}
+
+// evictedEntry is an entry that was evicted. It is used to call the eviction callback after the locks are released.
+type evictedEntry[Key comparable, Value any] struct {
+	key    Key
+	value  Value
+	reason EvictionReason
+}
+
 // Cache is an implementation of the Cache interface.
 type Cache[Key comparable, Value any] struct {
 	rwMutex          sync.RWMutex
 	getOrSetLock     sync.Mutex
 	getOrSetKeyLocks map[Key]*getOrSetKeyLock[Value]
 	keyToItem        map[Key]*item[Value]
+	maxEntries       int
+	tracker          policyTracker[Key]
+	evictionCallback func(key Key, value Value, reason EvictionReason)
+	hits             atomic.Uint64
+	misses           atomic.Uint64
+	evictions        atomic.Uint64
+	expirations      atomic.Uint64
+	stopJanitor      chan struct{}
+	closeOnce        sync.Once
 }
 
 // New creates a new instance of the Cache interface.
-func New[Key comparable, Value any]() *Cache[Key, Value] {
-	return &Cache[Key, Value]{
+// If the options start a janitor, the Cache must be closed to stop it.
+func New[Key comparable, Value any](opts ...Option) *Cache[Key, Value] {
+	cfg := &config{
+		maxEntries:       0,
+		evictionPolicy:   EvictionLRU,
+		janitorInterval:  0,
+		evictionCallback: nil,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return newFromConfig[Key, Value](cfg)
+}
+
+// newFromConfig creates a Cache with the resolved options.
+func newFromConfig[Key comparable, Value any](cfg *config) *Cache[Key, Value] {
+	c := &Cache[Key, Value]{
 		rwMutex:          sync.RWMutex{},
 		getOrSetLock:     sync.Mutex{},
 		getOrSetKeyLocks: make(map[Key]*getOrSetKeyLock[Value]),
 		keyToItem:        make(map[Key]*item[Value]),
+		maxEntries:       cfg.maxEntries,
+		tracker:          nil,
+		evictionCallback: nil,
+		stopJanitor:      make(chan struct{}),
+	}
+	if cfg.maxEntries > 0 {
+		c.tracker = newPolicyTracker[Key](cfg.evictionPolicy)
+	}
+	if cfg.evictionCallback != nil {
+		callback, typeMatches := cfg.evictionCallback.(func(key Key, value Value, reason EvictionReason))
+		if !typeMatches {
+			panic(fmt.Sprintf("the eviction callback must be a func(%T, %T, EvictionReason)", *new(Key), *new(Value)))
+		}
+		c.evictionCallback = callback
+	}
+	if cfg.janitorInterval > 0 {
+		go c.runJanitor(cfg.janitorInterval)
 	}
+	return c
 }
 
 // item are the values that are held in the Cache's map.
 type item[Value any] struct {
-	value  Value
-	expiry *time.Time
+	value     Value
+	expiry    *time.Time
+	refreshAt *time.Time
+}
+
+// newItem creates an item that expires after the TTL. If the stale window is positive, the item is kept for the stale
+// window after the TTL, and it needs to be refreshed once the TTL has passed.
+func newItem[Value any](value Value, ttl *time.Duration, staleWindow time.Duration) *item[Value] {
+	if ttl == nil {
+		return &item[Value]{
+			value:     value,
+			expiry:    nil,
+			refreshAt: nil,
+		}
+	}
+	now := time.Now()
+	expireTime := now.Add(*ttl + staleWindow)
+	created := &item[Value]{
+		value:     value,
+		expiry:    &expireTime,
+		refreshAt: nil,
+	}
+	if staleWindow > 0 {
+		refreshTime := now.Add(*ttl)
+		created.refreshAt = &refreshTime
+	}
+	return created
+}
+
+// isStale returns true if the item is past its TTL but within its stale window.
+func (i *item[Value]) isStale(now time.Time) bool {
+	return i.refreshAt != nil && now.After(*i.refreshAt)
+}
+
+// isExpired returns true if the item has an expiry before the time.
+func (i *item[Value]) isExpired(now time.Time) bool {
+	return i.expiry != nil && now.After(*i.expiry)
 }
 
 // Set is the implementation of the Cache interface.
 func (c *Cache[Key, Value]) Set(key Key, value Value, ttl *time.Duration) {
-	var itemToAdd *item[Value]
-	if ttl != nil {
-		expireTime := time.Now().Add(*ttl)
-		itemToAdd = &item[Value]{
-			value:  value,
-			expiry: &expireTime,
-		}
-	} else {
-		itemToAdd = &item[Value]{
-			value:  value,
-			expiry: nil,
+	c.rwMutex.Lock()
+	evicted := c.setLocked(key, value, ttl, 0)
+	c.rwMutex.Unlock()
+	c.notifyEvicted(evicted)
+}
+
+// setLocked stores the value of the key and returns the entries evicted to make room for it.
+// See newItem for the stale window. The write lock must be held.
+func (c *Cache[Key, Value]) setLocked(key Key, value Value, ttl *time.Duration, staleWindow time.Duration) []evictedEntry[Key, Value] {
+	itemToAdd := newItem(value, ttl, staleWindow)
+
+	var evicted []evictedEntry[Key, Value]
+	_, alreadySet := c.keyToItem[key]
+	if c.tracker != nil {
+		if alreadySet {
+			c.tracker.accessed(key)
+		} else {
+			// Room is made before the key is tracked, so the new entry is never its own victim.
+			evicted = c.evictForNewEntry()
+			c.tracker.added(key)
 		}
 	}
-	c.rwMutex.Lock()
 	c.keyToItem[key] = itemToAdd
+	return evicted
+}
+
+// UpdateFn is used in the Update function of the Cache. It receives the current value of the key and whether it
+// was found, and returns the value to store with its TTL.
+type UpdateFn[Value any] func(current Value, found bool) (Value, *time.Duration)
+
+// Update atomically replaces the value of the key with the result of the function, and returns the new value.
+// Expired values are passed to the function as not found. The function is called while the Cache is locked,
+// so it must not use the Cache.
+func (c *Cache[Key, Value]) Update(key Key, fn UpdateFn[Value]) Value {
+	var evicted []evictedEntry[Key, Value]
+	c.rwMutex.Lock()
+	var current Value
+	itemValue, found := c.keyToItem[key]
+	if found && itemValue.isExpired(time.Now()) {
+		evicted = append(evicted, evictedEntry[Key, Value]{key: key, value: itemValue.value, reason: EvictionReasonExpired})
+		c.deleteLocked(key)
+		c.expirations.Add(1)
+		found = false
+	}
+	if found {
+		current = itemValue.value
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+	updated, ttl := fn(current, found)
+	evicted = append(evicted, c.setLocked(key, updated, ttl, 0)...)
 	c.rwMutex.Unlock()
+	c.notifyEvicted(evicted)
+	return updated
+}
+
+// evictForNewEntry evicts entries until a new entry can be added without exceeding the maximum number of entries.
+// The write lock must be held.
+func (c *Cache[Key, Value]) evictForNewEntry() []evictedEntry[Key, Value] {
+	var evicted []evictedEntry[Key, Value]
+	for len(c.keyToItem) >= c.maxEntries {
+		victim, hasVictim := c.tracker.victim()
+		if !hasVictim {
+			break
+		}
+		evicted = append(evicted, evictedEntry[Key, Value]{key: victim, value: c.keyToItem[victim].value, reason: EvictionReasonCapacity})
+		c.deleteLocked(victim)
+		c.evictions.Add(1)
+	}
+	return evicted
 }
 
 // Get is the implementation of the Cache interface.
 func (c *Cache[Key, Value]) Get(key Key) (Value, bool) {
-	c.rwMutex.RLock()
-	itemValue, loaded := c.keyToItem[key]
-	c.rwMutex.RUnlock()
+	itemValue, found := c.getItem(key)
+	if !found {
+		var zeroValue Value
+		return zeroValue, false
+	}
+	return itemValue.value, true
+}
+
+// getItem fetches the item of the key if it is not expired, and records the hit or miss.
+func (c *Cache[Key, Value]) getItem(key Key) (*item[Value], bool) {
+	var itemValue *item[Value]
+	var loaded bool
+	if c.tracker != nil {
+		// The policy tracker records the access, so the write lock is needed.
+		c.rwMutex.Lock()
+		itemValue, loaded = c.keyToItem[key]
+		if loaded && !itemValue.isExpired(time.Now()) {
+			c.tracker.accessed(key)
+		}
+		c.rwMutex.Unlock()
+	} else {
+		c.rwMutex.RLock()
+		itemValue, loaded = c.keyToItem[key]
+		c.rwMutex.RUnlock()
+	}
 
 	if loaded {
-		if itemValue.expiry != nil && time.Now().After(*itemValue.expiry) {
+		if itemValue.isExpired(time.Now()) {
 			c.clearIfExpired(key)
-			var zeroValue Value
-			return zeroValue, false
+			c.misses.Add(1)
+			return nil, false
 		}
-		return itemValue.value, true
+		c.hits.Add(1)
+		return itemValue, true
 	} else {
-		var zeroValue Value
-		return zeroValue, false
+		c.misses.Add(1)
+		return nil, false
 	}
 }
 
 // clearIfExpired removes the key from the Cache if it is expired.
 func (c *Cache[Key, Value]) clearIfExpired(key Key) {
+	var evicted []evictedEntry[Key, Value]
 	c.rwMutex.Lock()
 	itemValue, loaded := c.keyToItem[key]
-	if loaded && itemValue.expiry != nil && time.Now().After(*itemValue.expiry) {
-		delete(c.keyToItem, key)
+	if loaded && itemValue.isExpired(time.Now()) {
+		evicted = append(evicted, evictedEntry[Key, Value]{key: key, value: itemValue.value, reason: EvictionReasonExpired})
+		c.deleteLocked(key)
+		c.expirations.Add(1)
 	}
 	c.rwMutex.Unlock()
+	c.notifyEvicted(evicted)
 }
 
-// GetOrSet is the implementation of the Cache interface.
-func (c *Cache[Key, Value]) GetOrSet(key Key, fn GetOrSetFn[Key, Value]) (Value, error) {
-	c.getOrSetLock.Lock()
-	keyLock, keyLockFound := c.getOrSetKeyLocks[key]
-	if !keyLockFound {
-		keyLock = &getOrSetKeyLock[Value]{
-			WaitChan: make(chan struct{}),
-		}
-		c.getOrSetKeyLocks[key] = keyLock
+// deleteLocked removes the key from the Cache and its policy tracker. The write lock must be held.
+func (c *Cache[Key, Value]) deleteLocked(key Key) {
+	delete(c.keyToItem, key)
+	if c.tracker != nil {
+		c.tracker.removed(key)
 	}
-	c.getOrSetLock.Unlock()
+}
 
-	if keyLockFound {
-		<-keyLock.WaitChan
-		return keyLock.FnValue, keyLock.FnError
-	} else {
-		defer func() {
-			c.getOrSetLock.Lock()
-			delete(c.getOrSetKeyLocks, key)
-			c.getOrSetLock.Unlock()
-		}()
+// notifyEvicted calls the eviction callback with the evicted entries. The locks must not be held.
+func (c *Cache[Key, Value]) notifyEvicted(evicted []evictedEntry[Key, Value]) {
+	if c.evictionCallback == nil {
+		return
+	}
+	for _, entry := range evicted {
+		c.evictionCallback(entry.key, entry.value, entry.reason)
 	}
+}
 
-	var valueFound bool
-	keyLock.FnValue, valueFound = c.Get(key)
-	if valueFound {
-		close(keyLock.WaitChan)
-		return keyLock.FnValue, nil
+// RemoveExpired removes all the expired entries from the Cache. The janitor calls it at each interval.
+func (c *Cache[Key, Value]) RemoveExpired() {
+	var evicted []evictedEntry[Key, Value]
+	now := time.Now()
+	c.rwMutex.Lock()
+	for key, itemValue := range c.keyToItem {
+		if itemValue.isExpired(now) {
+			evicted = append(evicted, evictedEntry[Key, Value]{key: key, value: itemValue.value, reason: EvictionReasonExpired})
+			c.deleteLocked(key)
+			c.expirations.Add(1)
+		}
 	}
+	c.rwMutex.Unlock()
+	c.notifyEvicted(evicted)
+}
 
-	var ttl *time.Duration
-	keyLock.FnValue, ttl, keyLock.FnError = fn(key)
-	defer close(keyLock.WaitChan)
-	if keyLock.FnError != nil {
-		return keyLock.FnValue, keyLock.FnError
+// runJanitor removes the expired entries at each interval until the Cache is closed.
+func (c *Cache[Key, Value]) runJanitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stopJanitor:
+			return
+		case <-ticker.C:
+			c.RemoveExpired()
+		}
 	}
+}
 
-	c.Set(key, keyLock.FnValue, ttl)
-	return keyLock.FnValue, nil
+// Close stops the janitor of the Cache, if it has one. It is safe to call Close many times.
+func (c *Cache[Key, Value]) Close() {
+	c.closeOnce.Do(func() {
+		close(c.stopJanitor)
+	})
+}
+
+// Stats returns the counters of the Cache.
+func (c *Cache[Key, Value]) Stats() Stats {
+	c.rwMutex.RLock()
+	entries := len(c.keyToItem)
+	c.rwMutex.RUnlock()
+	return Stats{
+		Hits:        c.hits.Load(),
+		Misses:      c.misses.Load(),
+		Evictions:   c.evictions.Load(),
+		Expirations: c.expirations.Load(),
+		Entries:     entries,
+	}
+}
+
+// GetOrSet is the implementation of the Cache interface.
+// Concurrent calls for a key that is not cached call the function once. The other callers wait for it and receive
+// its value or error. If the function panics, the caller that ran it panics and the others receive ErrLoaderPanicked.
+func (c *Cache[Key, Value]) GetOrSet(key Key, fn GetOrSetFn[Key, Value]) (Value, error) {
+	return c.load(key, fn, 0)
 }
 
 // Remove is the implementation of the Cache interface.
 func (c *Cache[Key, Value]) Remove(key Key) {
 	c.rwMutex.Lock()
-	delete(c.keyToItem, key)
+	c.deleteLocked(key)
 	c.rwMutex.Unlock()
 }
 
 // Reset is the implementation of the Cache interface.
 func (c *Cache[Key, Value]) Reset() {
 	c.rwMutex.Lock()
-	c.keyToItem = make(map[Key]*item[Value])
+	for key := range c.keyToItem {
+		c.deleteLocked(key)
+	}
 	c.rwMutex.Unlock()
 }
@@ -263,3 +263,61 @@ func TestCache(t *testing.T) {
 		assert.Equals(t, len(testCache.getOrSetKeyLocks), 0)
 	})
 }
+
+func TestCacheUpdate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when the key is not set it should call the function with not found", func(t *testing.T) {
+		t.Parallel()
+		testCache := New[string, int]()
+		updated := testCache.Update("key", func(current int, found bool) (int, *time.Duration) {
+			assert.False(t, found)
+			assert.Equals(t, current, 0)
+			return 1, nil
+		})
+		assert.Equals(t, updated, 1)
+		cacheMustHaveKeyAndValue(t, testCache, "key", 1)
+	})
+
+	t.Run("when the key is set it should call the function with the current value", func(t *testing.T) {
+		t.Parallel()
+		testCache := New[string, int]()
+		testCache.Set("key", 1, nil)
+		updated := testCache.Update("key", func(current int, found bool) (int, *time.Duration) {
+			assert.True(t, found)
+			return current + 1, nil
+		})
+		assert.Equals(t, updated, 2)
+		cacheMustHaveKeyAndValue(t, testCache, "key", 2)
+	})
+
+	t.Run("when the key is expired it should call the function with not found", func(t *testing.T) {
+		t.Parallel()
+		testCache := New[string, int]()
+		testCache.Set("key", 1, ptr.Of(time.Nanosecond))
+		time.Sleep(time.Millisecond)
+		updated := testCache.Update("key", func(current int, found bool) (int, *time.Duration) {
+			assert.False(t, found)
+			return current + 1, nil
+		})
+		assert.Equals(t, updated, 1)
+		assert.Equals(t, testCache.Stats().Expirations, uint64(1))
+	})
+
+	t.Run("when the key is updated concurrently it should not lose updates", func(t *testing.T) {
+		t.Parallel()
+		testCache := New[string, int]()
+		waitGroup := sync.WaitGroup{}
+		for i := 0; i < 100; i++ {
+			waitGroup.Add(1)
+			go func() {
+				defer waitGroup.Done()
+				testCache.Update("key", func(current int, _ bool) (int, *time.Duration) {
+					return current + 1, nil
+				})
+			}()
+		}
+		waitGroup.Wait()
+		cacheMustHaveKeyAndValue(t, testCache, "key", 100)
+	})
+}
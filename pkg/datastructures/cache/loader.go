@@ -0,0 +1,128 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrLoaderPanicked is returned to the callers waiting on the function of a key when the function panics.
+	ErrLoaderPanicked = errors.New("the function of the key panicked")
+)
+
+// LoadOrRefresh fetches the value of the key, or sets it with the function like GetOrSet, but serves stale values
+// while they are revalidated.
+//
+// The values set by the function are kept for the stale window after their TTL. When a value is past its TTL but
+// within its stale window, it is returned right away and the function is called in the background to refresh it.
+// Only one refresh runs at a time for a key. If the refresh fails or panics, the stale value is kept until the end of
+// its stale window. Once the stale window has passed, the callers wait for the function like GetOrSet.
+// Values set without a TTL are never refreshed. Get also returns the values that are within their stale window.
+func (c *Cache[Key, Value]) LoadOrRefresh(key Key, staleWindow time.Duration, fn GetOrSetFn[Key, Value]) (Value, error) {
+	if staleWindow < 0 {
+		panic(fmt.Sprintf("the stale window %s cannot be negative", staleWindow))
+	}
+	if itemValue, found := c.getItem(key); found {
+		if itemValue.isStale(time.Now()) {
+			c.refresh(key, fn, staleWindow)
+		}
+		return itemValue.value, nil
+	}
+	return c.load(key, fn, staleWindow)
+}
+
+// acquireKeyLock returns the lock of the key. If no caller holds it, it is created and the caller becomes its owner.
+// The owner must release it with releaseKeyLock.
+func (c *Cache[Key, Value]) acquireKeyLock(key Key) (*getOrSetKeyLock[Value], bool) {
+	c.getOrSetLock.Lock()
+	defer c.getOrSetLock.Unlock()
+	keyLock, keyLockFound := c.getOrSetKeyLocks[key]
+	if keyLockFound {
+		return keyLock, false
+	}
+	keyLock = &getOrSetKeyLock[Value]{
+		WaitChan: make(chan struct{}),
+	}
+	c.getOrSetKeyLocks[key] = keyLock
+	return keyLock, true
+}
+
+// releaseKeyLock wakes the callers waiting on the lock of the key and deletes it.
+func (c *Cache[Key, Value]) releaseKeyLock(key Key, keyLock *getOrSetKeyLock[Value]) {
+	close(keyLock.WaitChan)
+	c.getOrSetLock.Lock()
+	delete(c.getOrSetKeyLocks, key)
+	c.getOrSetLock.Unlock()
+}
+
+// load calls the function of the key once for all the concurrent callers and stores its value.
+func (c *Cache[Key, Value]) load(key Key, fn GetOrSetFn[Key, Value], staleWindow time.Duration) (Value, error) {
+	keyLock, isOwner := c.acquireKeyLock(key)
+	if !isOwner {
+		<-keyLock.WaitChan
+		return keyLock.FnValue, keyLock.FnError
+	}
+	defer c.releaseKeyLock(key, keyLock)
+
+	var valueFound bool
+	keyLock.FnValue, valueFound = c.Get(key)
+	if valueFound {
+		return keyLock.FnValue, nil
+	}
+
+	var panicValue any
+	keyLock.FnValue, panicValue, keyLock.FnError = c.callAndSet(key, fn, staleWindow)
+	if panicValue != nil {
+		panic(panicValue)
+	}
+	return keyLock.FnValue, keyLock.FnError
+}
+
+// refresh calls the function of the key in the background, unless another caller is already calling it.
+func (c *Cache[Key, Value]) refresh(key Key, fn GetOrSetFn[Key, Value], staleWindow time.Duration) {
+	keyLock, isOwner := c.acquireKeyLock(key)
+	if !isOwner {
+		return
+	}
+	go func() {
+		defer c.releaseKeyLock(key, keyLock)
+		var panicValue any
+		keyLock.FnValue, panicValue, keyLock.FnError = c.callAndSet(key, fn, staleWindow)
+		if panicValue != nil {
+			// There is no caller to panic in, so the stale value is kept like when the function fails.
+			var zeroValue Value
+			keyLock.FnValue = zeroValue
+		}
+	}()
+}
+
+// callAndSet calls the function and stores its value if it succeeds. A panic of the function is recovered
+// and returned, with ErrLoaderPanicked as the error.
+func (c *Cache[Key, Value]) callAndSet(key Key, fn GetOrSetFn[Key, Value], staleWindow time.Duration) (Value, any, error) {
+	value, ttl, panicValue, err := callRecovered(key, fn)
+	if panicValue != nil {
+		return value, panicValue, ErrLoaderPanicked
+	}
+	if err != nil {
+		return value, nil, err
+	}
+
+	c.rwMutex.Lock()
+	evicted := c.setLocked(key, value, ttl, staleWindow)
+	c.rwMutex.Unlock()
+	c.notifyEvicted(evicted)
+	return value, nil, nil
+}
+
+// callRecovered calls the function and recovers its panic.
+func callRecovered[Key comparable, Value any](key Key, fn GetOrSetFn[Key, Value]) (value Value, ttl *time.Duration, panicValue any, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			var zeroValue Value
+			value, ttl, panicValue, err = zeroValue, nil, recovered, nil
+		}
+	}()
+	value, ttl, err = fn(key)
+	return value, ttl, nil, err
+}
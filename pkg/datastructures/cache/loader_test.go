@@ -0,0 +1,250 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+	"github.com/TriangleSide/GoBase/pkg/utils/ptr"
+)
+
+func TestGetOrSetDeduplication(t *testing.T) {
+	t.Parallel()
+
+	const callers = 50
+
+	t.Run("when many callers miss the same key it should call the function once", func(t *testing.T) {
+		t.Parallel()
+		testCache := New[string, int]()
+		var calls atomic.Int32
+		release := make(chan struct{})
+		waitGroup := sync.WaitGroup{}
+		values := make([]int, callers)
+		for i := 0; i < callers; i++ {
+			waitGroup.Add(1)
+			go func() {
+				defer waitGroup.Done()
+				values[i], _ = testCache.GetOrSet("key", func(string) (int, *time.Duration, error) {
+					calls.Add(1)
+					<-release
+					return 1, nil, nil
+				})
+			}()
+		}
+		for calls.Load() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		close(release)
+		waitGroup.Wait()
+		assert.Equals(t, calls.Load(), int32(1))
+		for _, value := range values {
+			assert.Equals(t, value, 1, assert.Continue())
+		}
+	})
+
+	t.Run("when the function fails it should return the error to all the waiting callers", func(t *testing.T) {
+		t.Parallel()
+		testCache := New[string, int]()
+		release := make(chan struct{})
+		started := make(chan struct{})
+		go func() {
+			_, _ = testCache.GetOrSet("key", func(string) (int, *time.Duration, error) {
+				close(started)
+				<-release
+				return 0, nil, errors.New("fn error")
+			})
+		}()
+		<-started
+		var lateCalls atomic.Int32
+		errs := make(chan error, callers)
+		for i := 0; i < callers; i++ {
+			go func() {
+				_, err := testCache.GetOrSet("key", func(string) (int, *time.Duration, error) {
+					lateCalls.Add(1)
+					return 1, nil, nil
+				})
+				errs <- err
+			}()
+		}
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+		waiterErrors := 0
+		for i := 0; i < callers; i++ {
+			if err := <-errs; err != nil {
+				assert.ErrorExact(t, err, "fn error", assert.Continue())
+				waiterErrors++
+			}
+		}
+		assert.True(t, waiterErrors > 0)
+		assert.Equals(t, waiterErrors+int(lateCalls.Load()), callers)
+	})
+
+	t.Run("when the function panics it should panic in the caller and return an error to the others", func(t *testing.T) {
+		t.Parallel()
+		testCache := New[string, int]()
+		release := make(chan struct{})
+		started := make(chan struct{})
+		panicked := make(chan any, 1)
+		go func() {
+			defer func() {
+				panicked <- recover()
+			}()
+			_, _ = testCache.GetOrSet("key", func(string) (int, *time.Duration, error) {
+				close(started)
+				<-release
+				panic("fn panic")
+			})
+		}()
+		<-started
+		errChan := make(chan error, 1)
+		go func() {
+			_, err := testCache.GetOrSet("key", func(string) (int, *time.Duration, error) {
+				return 1, nil, nil
+			})
+			errChan <- err
+		}()
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+		assert.Equals(t, <-panicked, any("fn panic"))
+		err := <-errChan
+		if err != nil {
+			assert.True(t, errors.Is(err, ErrLoaderPanicked))
+		}
+		assert.Equals(t, testWaiters(testCache), 0)
+	})
+}
+
+// testWaiters returns the number of keys being loaded.
+func testWaiters[Key comparable, Value any](c *Cache[Key, Value]) int {
+	c.getOrSetLock.Lock()
+	defer c.getOrSetLock.Unlock()
+	return len(c.getOrSetKeyLocks)
+}
+
+func TestLoadOrRefresh(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when the stale window is negative it should panic", func(t *testing.T) {
+		t.Parallel()
+		testCache := New[string, int]()
+		assert.PanicPart(t, func() {
+			_, _ = testCache.LoadOrRefresh("key", -time.Second, func(string) (int, *time.Duration, error) {
+				return 0, nil, nil
+			})
+		}, "the stale window -1s cannot be negative")
+	})
+
+	t.Run("when the key is not cached it should load the value", func(t *testing.T) {
+		t.Parallel()
+		testCache := New[string, int]()
+		value, err := testCache.LoadOrRefresh("key", time.Hour, func(string) (int, *time.Duration, error) {
+			return 1, ptr.Of(time.Hour), nil
+		})
+		assert.NoError(t, err)
+		assert.Equals(t, value, 1)
+		cacheMustHaveKeyAndValue(t, testCache, "key", 1)
+	})
+
+	t.Run("when the value is stale it should return it and refresh it in the background", func(t *testing.T) {
+		t.Parallel()
+		testCache := New[string, int]()
+		var calls atomic.Int32
+		loader := func(string) (int, *time.Duration, error) {
+			return int(calls.Add(1)), ptr.Of(time.Millisecond), nil
+		}
+		value, err := testCache.LoadOrRefresh("key", time.Hour, loader)
+		assert.NoError(t, err)
+		assert.Equals(t, value, 1)
+		time.Sleep(5 * time.Millisecond)
+
+		value, err = testCache.LoadOrRefresh("key", time.Hour, loader)
+		assert.NoError(t, err)
+		assert.Equals(t, value, 1)
+		for {
+			if refreshed, _ := testCache.Get("key"); refreshed == 2 {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		assert.Equals(t, calls.Load(), int32(2))
+	})
+
+	t.Run("when the value is stale and the refresh fails it should keep the stale value", func(t *testing.T) {
+		t.Parallel()
+		testCache := New[string, int]()
+		_, _ = testCache.LoadOrRefresh("key", time.Hour, func(string) (int, *time.Duration, error) {
+			return 1, ptr.Of(time.Millisecond), nil
+		})
+		time.Sleep(5 * time.Millisecond)
+		for _, loader := range []GetOrSetFn[string, int]{
+			func(string) (int, *time.Duration, error) { return 0, nil, errors.New("refresh error") },
+			func(string) (int, *time.Duration, error) { panic("refresh panic") },
+		} {
+			value, err := testCache.LoadOrRefresh("key", time.Hour, loader)
+			assert.NoError(t, err)
+			assert.Equals(t, value, 1)
+			for testWaiters(testCache) != 0 {
+				time.Sleep(time.Millisecond)
+			}
+			cacheMustHaveKeyAndValue(t, testCache, "key", 1)
+		}
+	})
+
+	t.Run("when many callers see a stale value it should refresh it once", func(t *testing.T) {
+		t.Parallel()
+		testCache := New[string, int]()
+		_, _ = testCache.LoadOrRefresh("key", time.Hour, func(string) (int, *time.Duration, error) {
+			return 1, ptr.Of(time.Millisecond), nil
+		})
+		time.Sleep(5 * time.Millisecond)
+		var calls atomic.Int32
+		release := make(chan struct{})
+		for i := 0; i < 10; i++ {
+			value, err := testCache.LoadOrRefresh("key", time.Hour, func(string) (int, *time.Duration, error) {
+				calls.Add(1)
+				<-release
+				return 2, ptr.Of(time.Hour), nil
+			})
+			assert.NoError(t, err)
+			assert.Equals(t, value, 1)
+		}
+		close(release)
+		for testWaiters(testCache) != 0 {
+			time.Sleep(time.Millisecond)
+		}
+		assert.Equals(t, calls.Load(), int32(1))
+		cacheMustHaveKeyAndValue(t, testCache, "key", 2)
+	})
+
+	t.Run("when the stale window has passed it should wait for the function", func(t *testing.T) {
+		t.Parallel()
+		testCache := New[string, int]()
+		_, _ = testCache.LoadOrRefresh("key", time.Millisecond, func(string) (int, *time.Duration, error) {
+			return 1, ptr.Of(time.Millisecond), nil
+		})
+		time.Sleep(5 * time.Millisecond)
+		value, err := testCache.LoadOrRefresh("key", time.Millisecond, func(string) (int, *time.Duration, error) {
+			return 2, nil, nil
+		})
+		assert.NoError(t, err)
+		assert.Equals(t, value, 2)
+	})
+
+	t.Run("when the value has no TTL it should not refresh it", func(t *testing.T) {
+		t.Parallel()
+		testCache := New[string, int]()
+		var calls atomic.Int32
+		loader := func(string) (int, *time.Duration, error) {
+			return int(calls.Add(1)), nil, nil
+		}
+		for i := 0; i < 3; i++ {
+			value, err := testCache.LoadOrRefresh("key", time.Hour, loader)
+			assert.NoError(t, err)
+			assert.Equals(t, value, 1)
+		}
+		assert.Equals(t, calls.Load(), int32(1))
+	})
+}
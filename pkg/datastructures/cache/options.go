@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+// EvictionReason is why an entry was evicted from a Cache.
+type EvictionReason string
+
+const (
+	// EvictionReasonCapacity is the reason of the entries evicted to make room for a new entry.
+	EvictionReasonCapacity EvictionReason = "capacity"
+
+	// EvictionReasonExpired is the reason of the entries removed because their TTL has passed.
+	EvictionReasonExpired EvictionReason = "expired"
+)
+
+// config is configured by the Option functions.
+type config struct {
+	maxEntries       int
+	evictionPolicy   EvictionPolicy
+	janitorInterval  time.Duration
+	evictionCallback any
+}
+
+// Option is used to configure the Cache.
+type Option func(cfg *config)
+
+// WithMaxEntries bounds the number of entries of the Cache. When a new entry is added to a full Cache,
+// an entry is evicted with the eviction policy. If maxEntries is not positive, this function panics.
+func WithMaxEntries(maxEntries int) Option {
+	if maxEntries <= 0 {
+		panic(fmt.Sprintf("the max entries %d must be greater than zero", maxEntries))
+	}
+	return func(cfg *config) {
+		cfg.maxEntries = maxEntries
+	}
+}
+
+// WithEvictionPolicy sets how the entries to evict are chosen when the Cache has a maximum number of entries.
+// The default is EvictionLRU.
+func WithEvictionPolicy(policy EvictionPolicy) Option {
+	if policy != EvictionLRU && policy != EvictionLFU {
+		panic(fmt.Sprintf("invalid eviction policy '%s'", policy))
+	}
+	return func(cfg *config) {
+		cfg.evictionPolicy = policy
+	}
+}
+
+// WithJanitor starts a goroutine that removes the expired entries at each interval. Without it, the expired entries
+// are only removed when they are fetched. The goroutine is stopped by Close. If the interval is not positive,
+// this function panics.
+func WithJanitor(interval time.Duration) Option {
+	if interval <= 0 {
+		panic(fmt.Sprintf("the janitor interval %s must be greater than zero", interval))
+	}
+	return func(cfg *config) {
+		cfg.janitorInterval = interval
+	}
+}
+
+// WithEvictionCallback calls the callback with the entries that are evicted because of the capacity of the Cache,
+// or because they expired. The callback is called without holding the locks of the Cache, so it can use the Cache.
+// The key and value types of the callback must be those of the Cache.
+func WithEvictionCallback[Key comparable, Value any](callback func(key Key, value Value, reason EvictionReason)) Option {
+	if callback == nil {
+		panic("the eviction callback cannot be nil")
+	}
+	return func(cfg *config) {
+		cfg.evictionCallback = callback
+	}
+}
@@ -0,0 +1,173 @@
+package cache
+
+import (
+	"container/list"
+	"fmt"
+)
+
+// EvictionPolicy chooses which entry is evicted when a Cache with a maximum number of entries is full.
+type EvictionPolicy string
+
+const (
+	// EvictionLRU evicts the least recently used entry.
+	EvictionLRU EvictionPolicy = "lru"
+
+	// EvictionLFU evicts the least frequently used entry. Among the entries used as often, the least recently
+	// used entry is evicted.
+	EvictionLFU EvictionPolicy = "lfu"
+)
+
+// policyTracker tracks the use of the keys of a Cache to choose the key to evict.
+// It is not safe for concurrent use, so it is guarded by the lock of the Cache.
+type policyTracker[Key comparable] interface {
+	// added starts tracking a key that was added to the Cache.
+	added(key Key)
+
+	// accessed records a use of a tracked key.
+	accessed(key Key)
+
+	// removed stops tracking a key.
+	removed(key Key)
+
+	// victim returns the key to evict. It returns false if no key is tracked.
+	victim() (Key, bool)
+}
+
+// newPolicyTracker creates the tracker of an eviction policy.
+func newPolicyTracker[Key comparable](policy EvictionPolicy) policyTracker[Key] {
+	switch policy {
+	case EvictionLRU:
+		return &lruTracker[Key]{
+			recency:     list.New(),
+			keyToRecent: make(map[Key]*list.Element),
+		}
+	case EvictionLFU:
+		return &lfuTracker[Key]{
+			frequencyToKeys: make(map[int]*list.List),
+			keyToEntry:      make(map[Key]*lfuEntry[Key]),
+			minFrequency:    0,
+		}
+	default:
+		panic(fmt.Sprintf("invalid eviction policy '%s'", policy))
+	}
+}
+
+// lruTracker orders the keys from the most recently used at the front to the least recently used at the back.
+type lruTracker[Key comparable] struct {
+	recency     *list.List
+	keyToRecent map[Key]*list.Element
+}
+
+// added is the implementation of the policyTracker interface.
+func (t *lruTracker[Key]) added(key Key) {
+	t.keyToRecent[key] = t.recency.PushFront(key)
+}
+
+// accessed is the implementation of the policyTracker interface.
+func (t *lruTracker[Key]) accessed(key Key) {
+	if element, tracked := t.keyToRecent[key]; tracked {
+		t.recency.MoveToFront(element)
+	}
+}
+
+// removed is the implementation of the policyTracker interface.
+func (t *lruTracker[Key]) removed(key Key) {
+	if element, tracked := t.keyToRecent[key]; tracked {
+		t.recency.Remove(element)
+		delete(t.keyToRecent, key)
+	}
+}
+
+// victim is the implementation of the policyTracker interface.
+func (t *lruTracker[Key]) victim() (Key, bool) {
+	back := t.recency.Back()
+	if back == nil {
+		var zeroKey Key
+		return zeroKey, false
+	}
+	return back.Value.(Key), true
+}
+
+// lfuEntry is the position of a key in the lists of the lfuTracker.
+type lfuEntry[Key comparable] struct {
+	frequency int
+	element   *list.Element
+}
+
+// lfuTracker groups the keys by the number of times they were used. Each group is ordered from the most recently
+// used at the front to the least recently used at the back.
+type lfuTracker[Key comparable] struct {
+	frequencyToKeys map[int]*list.List
+	keyToEntry      map[Key]*lfuEntry[Key]
+	minFrequency    int
+}
+
+// added is the implementation of the policyTracker interface.
+func (t *lfuTracker[Key]) added(key Key) {
+	t.keyToEntry[key] = &lfuEntry[Key]{
+		frequency: 1,
+		element:   t.keys(1).PushFront(key),
+	}
+	t.minFrequency = 1
+}
+
+// accessed is the implementation of the policyTracker interface.
+func (t *lfuTracker[Key]) accessed(key Key) {
+	entry, tracked := t.keyToEntry[key]
+	if !tracked {
+		return
+	}
+	t.unlink(entry)
+	if t.minFrequency == entry.frequency && t.frequencyToKeys[entry.frequency] == nil {
+		t.minFrequency++
+	}
+	entry.frequency++
+	entry.element = t.keys(entry.frequency).PushFront(key)
+}
+
+// removed is the implementation of the policyTracker interface.
+func (t *lfuTracker[Key]) removed(key Key) {
+	entry, tracked := t.keyToEntry[key]
+	if !tracked {
+		return
+	}
+	t.unlink(entry)
+	delete(t.keyToEntry, key)
+	if t.frequencyToKeys[t.minFrequency] == nil {
+		t.minFrequency = 0
+		for frequency := range t.frequencyToKeys {
+			if t.minFrequency == 0 || frequency < t.minFrequency {
+				t.minFrequency = frequency
+			}
+		}
+	}
+}
+
+// victim is the implementation of the policyTracker interface.
+func (t *lfuTracker[Key]) victim() (Key, bool) {
+	keys, hasKeys := t.frequencyToKeys[t.minFrequency]
+	if !hasKeys {
+		var zeroKey Key
+		return zeroKey, false
+	}
+	return keys.Back().Value.(Key), true
+}
+
+// keys returns the list of the keys with the frequency, creating it if needed.
+func (t *lfuTracker[Key]) keys(frequency int) *list.List {
+	keys, hasKeys := t.frequencyToKeys[frequency]
+	if !hasKeys {
+		keys = list.New()
+		t.frequencyToKeys[frequency] = keys
+	}
+	return keys
+}
+
+// unlink removes the entry from the list of its frequency. Empty lists are deleted.
+func (t *lfuTracker[Key]) unlink(entry *lfuEntry[Key]) {
+	keys := t.frequencyToKeys[entry.frequency]
+	keys.Remove(entry.element)
+	if keys.Len() == 0 {
+		delete(t.frequencyToKeys, entry.frequency)
+	}
+}
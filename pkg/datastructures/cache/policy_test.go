@@ -0,0 +1,229 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+	"github.com/TriangleSide/GoBase/pkg/utils/ptr"
+)
+
+func TestCacheOptions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when the max entries is not positive it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			WithMaxEntries(0)
+		}, "the max entries 0 must be greater than zero")
+	})
+
+	t.Run("when the eviction policy is invalid it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			WithEvictionPolicy("invalid")
+		}, "invalid eviction policy 'invalid'")
+	})
+
+	t.Run("when the janitor interval is not positive it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			WithJanitor(0)
+		}, "the janitor interval 0s must be greater than zero")
+	})
+
+	t.Run("when the eviction callback is nil it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			WithEvictionCallback[string, int](nil)
+		}, "the eviction callback cannot be nil")
+	})
+
+	t.Run("when the eviction callback types do not match the cache it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			New[string, int](WithEvictionCallback(func(string, string, EvictionReason) {}))
+		}, "the eviction callback must be a func(string, int, EvictionReason)")
+	})
+}
+
+func TestCacheEviction(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when the cache is full with the LRU policy it should evict the least recently used entry", func(t *testing.T) {
+		t.Parallel()
+		testCache := New[string, int](WithMaxEntries(2))
+		testCache.Set("a", 1, nil)
+		testCache.Set("b", 2, nil)
+		_, _ = testCache.Get("a")
+		testCache.Set("c", 3, nil)
+		_, found := testCache.Get("b")
+		assert.False(t, found)
+		cacheMustHaveKeyAndValue(t, testCache, "a", 1)
+		cacheMustHaveKeyAndValue(t, testCache, "c", 3)
+		assert.Equals(t, testCache.Stats().Evictions, uint64(1))
+	})
+
+	t.Run("when the cache is full with the LFU policy it should evict the least frequently used entry", func(t *testing.T) {
+		t.Parallel()
+		testCache := New[string, int](WithMaxEntries(2), WithEvictionPolicy(EvictionLFU))
+		testCache.Set("a", 1, nil)
+		testCache.Set("b", 2, nil)
+		_, _ = testCache.Get("a")
+		_, _ = testCache.Get("a")
+		_, _ = testCache.Get("b")
+		testCache.Set("c", 3, nil)
+		_, found := testCache.Get("b")
+		assert.False(t, found)
+		cacheMustHaveKeyAndValue(t, testCache, "a", 1)
+		cacheMustHaveKeyAndValue(t, testCache, "c", 3)
+	})
+
+	t.Run("when LFU entries are used as often it should evict the least recently used of them", func(t *testing.T) {
+		t.Parallel()
+		testCache := New[string, int](WithMaxEntries(2), WithEvictionPolicy(EvictionLFU))
+		testCache.Set("a", 1, nil)
+		testCache.Set("b", 2, nil)
+		testCache.Set("c", 3, nil)
+		_, found := testCache.Get("a")
+		assert.False(t, found)
+		cacheMustHaveKeyAndValue(t, testCache, "b", 2)
+	})
+
+	t.Run("when an existing key is set in a full cache it should not evict an entry", func(t *testing.T) {
+		t.Parallel()
+		testCache := New[string, int](WithMaxEntries(2))
+		testCache.Set("a", 1, nil)
+		testCache.Set("b", 2, nil)
+		testCache.Set("a", 3, nil)
+		cacheMustHaveKeyAndValue(t, testCache, "a", 3)
+		cacheMustHaveKeyAndValue(t, testCache, "b", 2)
+		assert.Equals(t, testCache.Stats().Evictions, uint64(0))
+	})
+
+	t.Run("when keys are removed or reset it should stop tracking them", func(t *testing.T) {
+		t.Parallel()
+		for _, policy := range []EvictionPolicy{EvictionLRU, EvictionLFU} {
+			testCache := New[string, int](WithMaxEntries(2), WithEvictionPolicy(policy))
+			testCache.Set("a", 1, nil)
+			testCache.Set("b", 2, nil)
+			testCache.Remove("a")
+			testCache.Set("c", 3, nil)
+			cacheMustHaveKeyAndValue(t, testCache, "b", 2)
+			cacheMustHaveKeyAndValue(t, testCache, "c", 3)
+			testCache.Reset()
+			_, hasVictim := testCache.tracker.victim()
+			assert.False(t, hasVictim)
+		}
+	})
+
+	t.Run("when entries are evicted it should call the callback with the reason", func(t *testing.T) {
+		t.Parallel()
+		type eviction struct {
+			key    string
+			value  int
+			reason EvictionReason
+		}
+		var evictions []eviction
+		testCache := New[string, int](WithMaxEntries(1), WithEvictionCallback(func(key string, value int, reason EvictionReason) {
+			evictions = append(evictions, eviction{key: key, value: value, reason: reason})
+		}))
+		testCache.Set("a", 1, nil)
+		testCache.Set("b", 2, ptr.Of(time.Nanosecond))
+		time.Sleep(time.Millisecond)
+		_, found := testCache.Get("b")
+		assert.False(t, found)
+		assert.Equals(t, evictions, []eviction{
+			{key: "a", value: 1, reason: EvictionReasonCapacity},
+			{key: "b", value: 2, reason: EvictionReasonExpired},
+		})
+	})
+
+	t.Run("when the callback uses the cache it should not deadlock", func(t *testing.T) {
+		t.Parallel()
+		var testCache *Cache[string, int]
+		testCache = New[string, int](WithMaxEntries(1), WithEvictionCallback(func(key string, value int, _ EvictionReason) {
+			testCache.Remove(key)
+		}))
+		testCache.Set("a", 1, nil)
+		testCache.Set("b", 2, nil)
+		cacheMustHaveKeyAndValue(t, testCache, "b", 2)
+	})
+
+	t.Run("it should be able to handle concurrency with a bounded cache", func(t *testing.T) {
+		t.Parallel()
+		const maxEntries = 8
+		testCache := New[int, int](WithMaxEntries(maxEntries), WithEvictionPolicy(EvictionLFU))
+		waitGroup := sync.WaitGroup{}
+		for i := 0; i < 16; i++ {
+			waitGroup.Add(1)
+			go func() {
+				defer waitGroup.Done()
+				for j := 0; j < 100; j++ {
+					testCache.Set(i*100+j, j, nil)
+					_, _ = testCache.Get(j)
+					testCache.Remove(i)
+				}
+			}()
+		}
+		waitGroup.Wait()
+		assert.True(t, testCache.Stats().Entries <= maxEntries)
+	})
+}
+
+func TestCacheJanitor(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when the janitor runs it should remove the expired entries", func(t *testing.T) {
+		t.Parallel()
+		testCache := New[string, int](WithJanitor(time.Millisecond))
+		defer testCache.Close()
+		testCache.Set("expires", 1, ptr.Of(time.Millisecond))
+		testCache.Set("stays", 2, nil)
+		for testCache.Stats().Entries != 1 {
+			time.Sleep(time.Millisecond)
+		}
+		assert.Equals(t, testCache.Stats().Expirations, uint64(1))
+		cacheMustHaveKeyAndValue(t, testCache, "stays", 2)
+	})
+
+	t.Run("when the cache is closed repeatedly it should not panic", func(t *testing.T) {
+		t.Parallel()
+		testCache := New[string, int](WithJanitor(time.Millisecond))
+		for i := 0; i < 3; i++ {
+			testCache.Close()
+		}
+	})
+
+	t.Run("when the expired entries are removed manually it should keep the others", func(t *testing.T) {
+		t.Parallel()
+		testCache := New[string, int]()
+		testCache.Set("expires", 1, ptr.Of(time.Nanosecond))
+		testCache.Set("stays", 2, ptr.Of(time.Hour))
+		time.Sleep(time.Millisecond)
+		testCache.RemoveExpired()
+		assert.Equals(t, len(testCache.keyToItem), 1)
+		cacheMustHaveKeyAndValue(t, testCache, "stays", 2)
+	})
+}
+
+func TestCacheStats(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when the cache is used it should count the hits and misses", func(t *testing.T) {
+		t.Parallel()
+		testCache := New[string, int]()
+		testCache.Set("a", 1, nil)
+		_, _ = testCache.Get("a")
+		_, _ = testCache.Get("a")
+		_, _ = testCache.Get("b")
+		assert.Equals(t, testCache.Stats(), Stats{
+			Hits:        2,
+			Misses:      1,
+			Evictions:   0,
+			Expirations: 0,
+			Entries:     1,
+		})
+	})
+}
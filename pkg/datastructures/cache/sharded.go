@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"fmt"
+	"hash/maphash"
+	"time"
+)
+
+const (
+	// DefaultShardCount is a shard count that keeps the contention low on machines with many cores.
+	DefaultShardCount = 32
+)
+
+// Sharded is a Cache split into shards that each have their own lock. A key always belongs to the same shard,
+// chosen by the hash of the key, so operations on keys of different shards don't wait on each other.
+type Sharded[Key comparable, Value any] struct {
+	seed   maphash.Seed
+	shards []*Cache[Key, Value]
+}
+
+// NewSharded creates a Sharded cache with the number of shards. The options are applied to each shard, except
+// the maximum number of entries which is split evenly between the shards, rounded up. Since the entries are evicted
+// per shard, the evicted entry is the victim of its shard and not of the whole cache.
+// If the shard count is not positive, this function panics.
+func NewSharded[Key comparable, Value any](shardCount int, opts ...Option) *Sharded[Key, Value] {
+	if shardCount <= 0 {
+		panic(fmt.Sprintf("the shard count %d must be greater than zero", shardCount))
+	}
+
+	cfg := &config{
+		maxEntries:       0,
+		evictionPolicy:   EvictionLRU,
+		janitorInterval:  0,
+		evictionCallback: nil,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.maxEntries > 0 {
+		cfg.maxEntries = (cfg.maxEntries + shardCount - 1) / shardCount
+	}
+
+	shards := make([]*Cache[Key, Value], shardCount)
+	for i := range shards {
+		shards[i] = newFromConfig[Key, Value](cfg)
+	}
+	return &Sharded[Key, Value]{
+		seed:   maphash.MakeSeed(),
+		shards: shards,
+	}
+}
+
+// shard returns the shard of the key.
+func (s *Sharded[Key, Value]) shard(key Key) *Cache[Key, Value] {
+	return s.shards[maphash.Comparable(s.seed, key)%uint64(len(s.shards))]
+}
+
+// Set stores the value of the key in its shard. See Cache.Set.
+func (s *Sharded[Key, Value]) Set(key Key, value Value, ttl *time.Duration) {
+	s.shard(key).Set(key, value, ttl)
+}
+
+// Get fetches the value of the key from its shard. See Cache.Get.
+func (s *Sharded[Key, Value]) Get(key Key) (Value, bool) {
+	return s.shard(key).Get(key)
+}
+
+// GetOrSet fetches the value of the key from its shard, or sets it with the function. See Cache.GetOrSet.
+func (s *Sharded[Key, Value]) GetOrSet(key Key, fn GetOrSetFn[Key, Value]) (Value, error) {
+	return s.shard(key).GetOrSet(key, fn)
+}
+
+// LoadOrRefresh fetches the value of the key from its shard, serving stale values while they are revalidated.
+// See Cache.LoadOrRefresh.
+func (s *Sharded[Key, Value]) LoadOrRefresh(key Key, staleWindow time.Duration, fn GetOrSetFn[Key, Value]) (Value, error) {
+	return s.shard(key).LoadOrRefresh(key, staleWindow, fn)
+}
+
+// Update atomically replaces the value of the key in its shard. See Cache.Update.
+func (s *Sharded[Key, Value]) Update(key Key, fn UpdateFn[Value]) Value {
+	return s.shard(key).Update(key, fn)
+}
+
+// Remove deletes the key from its shard.
+func (s *Sharded[Key, Value]) Remove(key Key) {
+	s.shard(key).Remove(key)
+}
+
+// Reset removes all the entries of all the shards.
+func (s *Sharded[Key, Value]) Reset() {
+	for _, shard := range s.shards {
+		shard.Reset()
+	}
+}
+
+// RemoveExpired removes the expired entries of all the shards.
+func (s *Sharded[Key, Value]) RemoveExpired() {
+	for _, shard := range s.shards {
+		shard.RemoveExpired()
+	}
+}
+
+// Close stops the janitors of the shards. It is safe to call Close many times.
+func (s *Sharded[Key, Value]) Close() {
+	for _, shard := range s.shards {
+		shard.Close()
+	}
+}
+
+// Stats returns the sum of the counters of the shards.
+func (s *Sharded[Key, Value]) Stats() Stats {
+	total := Stats{}
+	for _, shard := range s.shards {
+		shardStats := shard.Stats()
+		total.Hits += shardStats.Hits
+		total.Misses += shardStats.Misses
+		total.Evictions += shardStats.Evictions
+		total.Expirations += shardStats.Expirations
+		total.Entries += shardStats.Entries
+	}
+	return total
+}
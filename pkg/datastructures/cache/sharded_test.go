@@ -0,0 +1,193 @@
+package cache
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+	"github.com/TriangleSide/GoBase/pkg/utils/ptr"
+)
+
+func TestSharded(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when the shard count is not positive it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			NewSharded[string, int](0)
+		}, "the shard count 0 must be greater than zero")
+	})
+
+	t.Run("when keys are set it should be able to get them from their shards", func(t *testing.T) {
+		t.Parallel()
+		testCache := NewSharded[string, int](4)
+		for i := 0; i < 100; i++ {
+			testCache.Set(strconv.Itoa(i), i, nil)
+		}
+		for i := 0; i < 100; i++ {
+			value, found := testCache.Get(strconv.Itoa(i))
+			assert.True(t, found, assert.Continue())
+			assert.Equals(t, value, i, assert.Continue())
+		}
+		_, found := testCache.Get("missing")
+		assert.False(t, found)
+		assert.Equals(t, testCache.Stats(), Stats{
+			Hits:        100,
+			Misses:      1,
+			Evictions:   0,
+			Expirations: 0,
+			Entries:     100,
+		})
+	})
+
+	t.Run("when a key is removed or the cache is reset it should not be available", func(t *testing.T) {
+		t.Parallel()
+		testCache := NewSharded[string, int](4)
+		testCache.Set("a", 1, nil)
+		testCache.Set("b", 2, nil)
+		testCache.Remove("a")
+		_, found := testCache.Get("a")
+		assert.False(t, found)
+		testCache.Reset()
+		assert.Equals(t, testCache.Stats().Entries, 0)
+	})
+
+	t.Run("when get or set is called it should use the shard of the key", func(t *testing.T) {
+		t.Parallel()
+		testCache := NewSharded[string, int](4)
+		value, err := testCache.GetOrSet("key", func(string) (int, *time.Duration, error) {
+			return 1, nil, nil
+		})
+		assert.NoError(t, err)
+		assert.Equals(t, value, 1)
+		_, err = testCache.GetOrSet("other", func(string) (int, *time.Duration, error) {
+			return 0, nil, errors.New("fn error")
+		})
+		assert.ErrorExact(t, err, "fn error")
+		value = testCache.Update("key", func(current int, _ bool) (int, *time.Duration) {
+			return current + 1, nil
+		})
+		assert.Equals(t, value, 2)
+	})
+
+	t.Run("when there is a max number of entries it should split it between the shards", func(t *testing.T) {
+		t.Parallel()
+		testCache := NewSharded[int, int](4, WithMaxEntries(10))
+		for _, shard := range testCache.shards {
+			assert.Equals(t, shard.maxEntries, 3, assert.Continue())
+		}
+		for i := 0; i < 100; i++ {
+			testCache.Set(i, i, nil)
+		}
+		assert.True(t, testCache.Stats().Entries <= 12)
+		assert.Equals(t, testCache.Stats().Evictions, uint64(100-testCache.Stats().Entries))
+	})
+
+	t.Run("when the expired entries are removed it should remove them from all the shards", func(t *testing.T) {
+		t.Parallel()
+		testCache := NewSharded[int, int](4, WithJanitor(time.Hour))
+		defer testCache.Close()
+		for i := 0; i < 10; i++ {
+			testCache.Set(i, i, ptr.Of(time.Nanosecond))
+		}
+		time.Sleep(time.Millisecond)
+		testCache.RemoveExpired()
+		assert.Equals(t, testCache.Stats().Entries, 0)
+		assert.Equals(t, testCache.Stats().Expirations, uint64(10))
+	})
+
+	t.Run("it should be able to handle concurrency across the shards", func(t *testing.T) {
+		t.Parallel()
+		testCache := NewSharded[int, int](8)
+		waitGroup := sync.WaitGroup{}
+		for i := 0; i < 16; i++ {
+			waitGroup.Add(1)
+			go func() {
+				defer waitGroup.Done()
+				for j := 0; j < 100; j++ {
+					testCache.Update(j, func(current int, _ bool) (int, *time.Duration) {
+						return current + 1, nil
+					})
+				}
+			}()
+		}
+		waitGroup.Wait()
+		for j := 0; j < 100; j++ {
+			value, _ := testCache.Get(j)
+			assert.Equals(t, value, 16, assert.Continue())
+		}
+	})
+}
+
+const benchmarkKeyCount = 1024
+
+func benchmarkKeys() []string {
+	keys := make([]string, benchmarkKeyCount)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+	return keys
+}
+
+func BenchmarkCacheParallelGet(b *testing.B) {
+	testCache := New[string, int]()
+	keys := benchmarkKeys()
+	for i, key := range keys {
+		testCache.Set(key, i, nil)
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			testCache.Get(keys[i%benchmarkKeyCount])
+			i++
+		}
+	})
+}
+
+func BenchmarkShardedParallelGet(b *testing.B) {
+	testCache := NewSharded[string, int](DefaultShardCount)
+	keys := benchmarkKeys()
+	for i, key := range keys {
+		testCache.Set(key, i, nil)
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			testCache.Get(keys[i%benchmarkKeyCount])
+			i++
+		}
+	})
+}
+
+func BenchmarkCacheParallelUpdate(b *testing.B) {
+	testCache := New[string, int]()
+	keys := benchmarkKeys()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			testCache.Update(keys[i%benchmarkKeyCount], func(current int, _ bool) (int, *time.Duration) {
+				return current + 1, nil
+			})
+			i++
+		}
+	})
+}
+
+func BenchmarkShardedParallelUpdate(b *testing.B) {
+	testCache := NewSharded[string, int](DefaultShardCount)
+	keys := benchmarkKeys()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			testCache.Update(keys[i%benchmarkKeyCount], func(current int, _ bool) (int, *time.Duration) {
+				return current + 1, nil
+			})
+			i++
+		}
+	})
+}
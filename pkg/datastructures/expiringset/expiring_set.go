@@ -0,0 +1,112 @@
+package expiringset
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/datastructures/cache"
+)
+
+// config is configured by the Option functions.
+type config struct {
+	maxItems        int
+	cleanupInterval time.Duration
+}
+
+// Option is used to configure the Set.
+type Option func(cfg *config)
+
+// WithMaxItems bounds the number of items of the Set. When a new item is added to a full Set,
+// the least recently added or checked item is removed. If maxItems is not positive, this function panics.
+func WithMaxItems(maxItems int) Option {
+	if maxItems <= 0 {
+		panic(fmt.Sprintf("the max items %d must be greater than zero", maxItems))
+	}
+	return func(cfg *config) {
+		cfg.maxItems = maxItems
+	}
+}
+
+// WithCleanupInterval removes the expired items from memory at each interval. Without it, the expired items
+// are only removed when they are checked. The Set must be closed to stop the cleanup.
+// If the interval is not positive, this function panics.
+func WithCleanupInterval(interval time.Duration) Option {
+	if interval <= 0 {
+		panic(fmt.Sprintf("the cleanup interval %s must be greater than zero", interval))
+	}
+	return func(cfg *config) {
+		cfg.cleanupInterval = interval
+	}
+}
+
+// Set holds items for a fixed duration after they are added. It is safe for concurrent use.
+// It is useful to remember recently seen values, like the nonces of requests to protect against replays.
+type Set[T comparable] struct {
+	ttl   time.Duration
+	items *cache.Cache[T, time.Time]
+}
+
+// New creates a Set whose items expire after the TTL. If the TTL is not positive, this function panics.
+func New[T comparable](ttl time.Duration, opts ...Option) *Set[T] {
+	if ttl <= 0 {
+		panic(fmt.Sprintf("the TTL %s must be greater than zero", ttl))
+	}
+
+	cfg := &config{
+		maxItems:        0,
+		cleanupInterval: 0,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	var cacheOpts []cache.Option
+	if cfg.maxItems > 0 {
+		cacheOpts = append(cacheOpts, cache.WithMaxEntries(cfg.maxItems))
+	}
+	if cfg.cleanupInterval > 0 {
+		cacheOpts = append(cacheOpts, cache.WithJanitor(cfg.cleanupInterval))
+	}
+	return &Set[T]{
+		ttl:   ttl,
+		items: cache.New[T, time.Time](cacheOpts...),
+	}
+}
+
+// Add adds the item to the Set. It returns false if the item is already in the Set, in which case
+// its expiry is not extended. Checking and adding the item is atomic, so only one of many concurrent
+// calls with the same item returns true.
+func (s *Set[T]) Add(item T) bool {
+	added := false
+	s.items.Update(item, func(expiry time.Time, found bool) (time.Time, *time.Duration) {
+		if found {
+			remaining := time.Until(expiry)
+			return expiry, &remaining
+		}
+		added = true
+		return time.Now().Add(s.ttl), &s.ttl
+	})
+	return added
+}
+
+// Contains returns true if the item is in the Set and has not expired.
+func (s *Set[T]) Contains(item T) bool {
+	_, found := s.items.Get(item)
+	return found
+}
+
+// Remove removes the item from the Set.
+func (s *Set[T]) Remove(item T) {
+	s.items.Remove(item)
+}
+
+// Len returns the number of items in the Set that have not expired.
+func (s *Set[T]) Len() int {
+	s.items.RemoveExpired()
+	return s.items.Stats().Entries
+}
+
+// Close stops the cleanup of the expired items. It is safe to call Close many times.
+func (s *Set[T]) Close() {
+	s.items.Close()
+}
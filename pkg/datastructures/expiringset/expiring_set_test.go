@@ -0,0 +1,102 @@
+package expiringset_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/datastructures/expiringset"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestSet(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when the options are invalid it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			expiringset.New[string](0)
+		}, "the TTL 0s must be greater than zero")
+		assert.PanicPart(t, func() {
+			expiringset.WithMaxItems(0)
+		}, "the max items 0 must be greater than zero")
+		assert.PanicPart(t, func() {
+			expiringset.WithCleanupInterval(0)
+		}, "the cleanup interval 0s must be greater than zero")
+	})
+
+	t.Run("when an item is added it should be contained until it is removed", func(t *testing.T) {
+		t.Parallel()
+		set := expiringset.New[string](time.Hour)
+		assert.False(t, set.Contains("item"))
+		assert.True(t, set.Add("item"))
+		assert.False(t, set.Add("item"))
+		assert.True(t, set.Contains("item"))
+		assert.Equals(t, set.Len(), 1)
+		set.Remove("item")
+		assert.False(t, set.Contains("item"))
+		assert.Equals(t, set.Len(), 0)
+	})
+
+	t.Run("when an item expires it should not be contained and can be added again", func(t *testing.T) {
+		t.Parallel()
+		set := expiringset.New[string](time.Millisecond)
+		assert.True(t, set.Add("item"))
+		time.Sleep(5 * time.Millisecond)
+		assert.False(t, set.Contains("item"))
+		assert.Equals(t, set.Len(), 0)
+		assert.True(t, set.Add("item"))
+	})
+
+	t.Run("when an item is added again it should not extend its expiry", func(t *testing.T) {
+		t.Parallel()
+		set := expiringset.New[string](20 * time.Millisecond)
+		assert.True(t, set.Add("item"))
+		time.Sleep(10 * time.Millisecond)
+		assert.False(t, set.Add("item"))
+		time.Sleep(15 * time.Millisecond)
+		assert.False(t, set.Contains("item"))
+	})
+
+	t.Run("when the set is full it should remove the least recently used item", func(t *testing.T) {
+		t.Parallel()
+		set := expiringset.New[string](time.Hour, expiringset.WithMaxItems(2))
+		set.Add("a")
+		set.Add("b")
+		set.Contains("a")
+		set.Add("c")
+		assert.False(t, set.Contains("b"))
+		assert.True(t, set.Contains("a"))
+		assert.True(t, set.Contains("c"))
+	})
+
+	t.Run("when there is a cleanup interval it should remove the expired items", func(t *testing.T) {
+		t.Parallel()
+		set := expiringset.New[string](time.Millisecond, expiringset.WithCleanupInterval(time.Millisecond))
+		defer set.Close()
+		set.Add("item")
+		for set.Len() != 0 {
+			time.Sleep(time.Millisecond)
+		}
+		set.Close()
+	})
+
+	t.Run("when the same item is added concurrently it should be added once", func(t *testing.T) {
+		t.Parallel()
+		set := expiringset.New[string](time.Hour)
+		var added atomic.Int32
+		waitGroup := sync.WaitGroup{}
+		for i := 0; i < 50; i++ {
+			waitGroup.Add(1)
+			go func() {
+				defer waitGroup.Done()
+				if set.Add("nonce") {
+					added.Add(1)
+				}
+			}()
+		}
+		waitGroup.Wait()
+		assert.Equals(t, added.Load(), int32(1))
+	})
+}
@@ -0,0 +1,48 @@
+package lru
+
+import (
+	"fmt"
+
+	"github.com/TriangleSide/GoBase/pkg/datastructures/cache"
+)
+
+// Cache holds up to a fixed number of entries. When a new entry is added to a full Cache,
+// the least recently used entry is evicted. It is safe for concurrent use.
+type Cache[Key comparable, Value any] struct {
+	entries *cache.Cache[Key, Value]
+}
+
+// New creates a Cache that holds up to capacity entries. If the capacity is not positive, this function panics.
+func New[Key comparable, Value any](capacity int) *Cache[Key, Value] {
+	if capacity <= 0 {
+		panic(fmt.Sprintf("the capacity %d must be greater than zero", capacity))
+	}
+	return &Cache[Key, Value]{
+		entries: cache.New[Key, Value](cache.WithMaxEntries(capacity), cache.WithEvictionPolicy(cache.EvictionLRU)),
+	}
+}
+
+// Get fetches the value of the key and marks it as the most recently used.
+func (c *Cache[Key, Value]) Get(key Key) (Value, bool) {
+	return c.entries.Get(key)
+}
+
+// Set stores the value of the key and marks it as the most recently used.
+func (c *Cache[Key, Value]) Set(key Key, value Value) {
+	c.entries.Set(key, value, nil)
+}
+
+// Remove deletes the key from the Cache.
+func (c *Cache[Key, Value]) Remove(key Key) {
+	c.entries.Remove(key)
+}
+
+// Reset removes all the entries from the Cache.
+func (c *Cache[Key, Value]) Reset() {
+	c.entries.Reset()
+}
+
+// Len returns the number of entries in the Cache.
+func (c *Cache[Key, Value]) Len() int {
+	return c.entries.Stats().Entries
+}
@@ -0,0 +1,79 @@
+package lru_test
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/datastructures/lru"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when the capacity is not positive it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			lru.New[string, int](0)
+		}, "the capacity 0 must be greater than zero")
+	})
+
+	t.Run("when the cache is full it should evict the least recently used entry", func(t *testing.T) {
+		t.Parallel()
+		cache := lru.New[string, int](2)
+		cache.Set("a", 1)
+		cache.Set("b", 2)
+		_, _ = cache.Get("a")
+		cache.Set("c", 3)
+		_, found := cache.Get("b")
+		assert.False(t, found)
+		value, found := cache.Get("a")
+		assert.True(t, found)
+		assert.Equals(t, value, 1)
+		assert.Equals(t, cache.Len(), 2)
+	})
+
+	t.Run("when an entry is overwritten it should be the most recently used", func(t *testing.T) {
+		t.Parallel()
+		cache := lru.New[string, int](2)
+		cache.Set("a", 1)
+		cache.Set("b", 2)
+		cache.Set("a", 3)
+		cache.Set("c", 4)
+		_, found := cache.Get("b")
+		assert.False(t, found)
+		value, _ := cache.Get("a")
+		assert.Equals(t, value, 3)
+	})
+
+	t.Run("when entries are removed or reset it should not hold them", func(t *testing.T) {
+		t.Parallel()
+		cache := lru.New[string, int](2)
+		cache.Set("a", 1)
+		cache.Set("b", 2)
+		cache.Remove("a")
+		assert.Equals(t, cache.Len(), 1)
+		cache.Reset()
+		assert.Equals(t, cache.Len(), 0)
+	})
+
+	t.Run("it should be able to handle concurrency", func(t *testing.T) {
+		t.Parallel()
+		const capacity = 10
+		cache := lru.New[string, int](capacity)
+		waitGroup := sync.WaitGroup{}
+		for i := 0; i < 8; i++ {
+			waitGroup.Add(1)
+			go func() {
+				defer waitGroup.Done()
+				for j := 0; j < 100; j++ {
+					cache.Set(strconv.Itoa(i*100+j), j)
+					_, _ = cache.Get(strconv.Itoa(j))
+				}
+			}()
+		}
+		waitGroup.Wait()
+		assert.Equals(t, cache.Len(), capacity)
+	})
+}
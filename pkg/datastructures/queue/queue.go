@@ -0,0 +1,173 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	// ErrClosed is returned when pushing to a closed Queue, or when popping from a closed and empty Queue.
+	ErrClosed = errors.New("the queue is closed")
+)
+
+// Queue is a bounded first-in first-out queue that is safe for many producers and consumers.
+// Pushing to a full Queue blocks until an item is popped, so the producers are slowed down to the pace
+// of the consumers.
+type Queue[T any] struct {
+	lock     sync.Mutex
+	items    []T
+	head     int
+	size     int
+	closed   bool
+	notEmpty chan struct{}
+	notFull  chan struct{}
+}
+
+// New creates a Queue that holds up to capacity items. If the capacity is not positive, this function panics.
+func New[T any](capacity int) *Queue[T] {
+	if capacity <= 0 {
+		panic(fmt.Sprintf("the capacity %d must be greater than zero", capacity))
+	}
+	return &Queue[T]{
+		lock:     sync.Mutex{},
+		items:    make([]T, capacity),
+		head:     0,
+		size:     0,
+		closed:   false,
+		notEmpty: nil,
+		notFull:  nil,
+	}
+}
+
+// Push adds the item to the back of the Queue. If the Queue is full, it waits until there is room for the item.
+// It returns ErrClosed if the Queue is closed, or the error of the context if it is done first.
+func (q *Queue[T]) Push(ctx context.Context, item T) error {
+	for {
+		q.lock.Lock()
+		if q.closed {
+			q.lock.Unlock()
+			return ErrClosed
+		}
+		if q.size < len(q.items) {
+			q.pushLocked(item)
+			q.lock.Unlock()
+			return nil
+		}
+		notFull := waitChannel(&q.notFull)
+		q.lock.Unlock()
+
+		select {
+		case <-notFull:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// TryPush adds the item to the back of the Queue without waiting. It returns false if the Queue is full or closed.
+func (q *Queue[T]) TryPush(item T) bool {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if q.closed || q.size == len(q.items) {
+		return false
+	}
+	q.pushLocked(item)
+	return true
+}
+
+// Pop removes the item at the front of the Queue. If the Queue is empty, it waits until an item is pushed.
+// The items that are left when the Queue is closed can still be popped. It returns ErrClosed once the Queue
+// is closed and empty, or the error of the context if it is done first.
+func (q *Queue[T]) Pop(ctx context.Context) (T, error) {
+	for {
+		q.lock.Lock()
+		if q.size > 0 {
+			item := q.popLocked()
+			q.lock.Unlock()
+			return item, nil
+		}
+		if q.closed {
+			q.lock.Unlock()
+			var zeroValue T
+			return zeroValue, ErrClosed
+		}
+		notEmpty := waitChannel(&q.notEmpty)
+		q.lock.Unlock()
+
+		select {
+		case <-notEmpty:
+		case <-ctx.Done():
+			var zeroValue T
+			return zeroValue, ctx.Err()
+		}
+	}
+}
+
+// TryPop removes the item at the front of the Queue without waiting. It returns false if the Queue is empty.
+func (q *Queue[T]) TryPop() (T, bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if q.size == 0 {
+		var zeroValue T
+		return zeroValue, false
+	}
+	return q.popLocked(), true
+}
+
+// Close stops the Queue from accepting items and wakes the waiting producers and consumers.
+// It is safe to call Close many times.
+func (q *Queue[T]) Close() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.closed = true
+	wake(&q.notEmpty)
+	wake(&q.notFull)
+}
+
+// Len returns the number of items in the Queue.
+func (q *Queue[T]) Len() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return q.size
+}
+
+// Cap returns the maximum number of items of the Queue.
+func (q *Queue[T]) Cap() int {
+	return len(q.items)
+}
+
+// pushLocked adds the item to the back of the Queue, which must not be full. The lock must be held.
+func (q *Queue[T]) pushLocked(item T) {
+	q.items[(q.head+q.size)%len(q.items)] = item
+	q.size++
+	wake(&q.notEmpty)
+}
+
+// popLocked removes the item at the front of the Queue, which must not be empty. The lock must be held.
+func (q *Queue[T]) popLocked() T {
+	item := q.items[q.head]
+	var zeroValue T
+	q.items[q.head] = zeroValue
+	q.head = (q.head + 1) % len(q.items)
+	q.size--
+	wake(&q.notFull)
+	return item
+}
+
+// waitChannel returns a channel that is closed by the next call to wake. The lock must be held.
+func waitChannel(channel *chan struct{}) chan struct{} {
+	if *channel == nil {
+		*channel = make(chan struct{})
+	}
+	return *channel
+}
+
+// wake closes the channel returned by waitChannel, if there is one. The lock must be held.
+func wake(channel *chan struct{}) {
+	if *channel != nil {
+		close(*channel)
+		*channel = nil
+	}
+}
@@ -0,0 +1,156 @@
+package queue_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/datastructures/queue"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestQueue(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when the capacity is not positive it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			queue.New[int](0)
+		}, "the capacity 0 must be greater than zero")
+	})
+
+	t.Run("when items are pushed it should pop them in order", func(t *testing.T) {
+		t.Parallel()
+		q := queue.New[int](3)
+		assert.Equals(t, q.Cap(), 3)
+		for i := 0; i < 10; i++ {
+			assert.NoError(t, q.Push(t.Context(), i))
+			assert.True(t, q.TryPush(i+100))
+			assert.Equals(t, q.Len(), 2)
+			item, err := q.Pop(t.Context())
+			assert.NoError(t, err)
+			assert.Equals(t, item, i)
+			item, popped := q.TryPop()
+			assert.True(t, popped)
+			assert.Equals(t, item, i+100)
+		}
+	})
+
+	t.Run("when the queue is full it should not push without waiting", func(t *testing.T) {
+		t.Parallel()
+		q := queue.New[int](1)
+		assert.True(t, q.TryPush(1))
+		assert.False(t, q.TryPush(2))
+	})
+
+	t.Run("when the queue is empty it should not pop without waiting", func(t *testing.T) {
+		t.Parallel()
+		q := queue.New[int](1)
+		_, popped := q.TryPop()
+		assert.False(t, popped)
+	})
+
+	t.Run("when the queue is full it should wait for an item to be popped", func(t *testing.T) {
+		t.Parallel()
+		q := queue.New[int](1)
+		assert.NoError(t, q.Push(t.Context(), 1))
+		pushed := make(chan error)
+		go func() {
+			pushed <- q.Push(context.Background(), 2)
+		}()
+		time.Sleep(10 * time.Millisecond)
+		item, err := q.Pop(t.Context())
+		assert.NoError(t, err)
+		assert.Equals(t, item, 1)
+		assert.NoError(t, <-pushed)
+		item, _ = q.TryPop()
+		assert.Equals(t, item, 2)
+	})
+
+	t.Run("when the context is done while waiting it should return its error", func(t *testing.T) {
+		t.Parallel()
+		q := queue.New[int](1)
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+		_, err := q.Pop(ctx)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.True(t, q.TryPush(1))
+		err = q.Push(ctx, 2)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("when the queue is closed it should drain the items and then return an error", func(t *testing.T) {
+		t.Parallel()
+		q := queue.New[int](2)
+		assert.True(t, q.TryPush(1))
+		q.Close()
+		q.Close()
+		assert.ErrorExact(t, q.Push(t.Context(), 2), "the queue is closed")
+		assert.False(t, q.TryPush(2))
+		item, err := q.Pop(t.Context())
+		assert.NoError(t, err)
+		assert.Equals(t, item, 1)
+		_, err = q.Pop(t.Context())
+		assert.True(t, errors.Is(err, queue.ErrClosed))
+	})
+
+	t.Run("when the queue is closed it should wake the waiting producers and consumers", func(t *testing.T) {
+		t.Parallel()
+		empty := queue.New[int](1)
+		full := queue.New[int](1)
+		assert.True(t, full.TryPush(1))
+		errs := make(chan error, 2)
+		go func() {
+			_, err := empty.Pop(context.Background())
+			errs <- err
+		}()
+		go func() {
+			errs <- full.Push(context.Background(), 2)
+		}()
+		time.Sleep(10 * time.Millisecond)
+		empty.Close()
+		full.Close()
+		assert.True(t, errors.Is(<-errs, queue.ErrClosed))
+		assert.True(t, errors.Is(<-errs, queue.ErrClosed))
+	})
+
+	t.Run("it should be able to handle many producers and consumers", func(t *testing.T) {
+		t.Parallel()
+		const producers = 8
+		const itemsPerProducer = 200
+		q := queue.New[int](4)
+		producerGroup := sync.WaitGroup{}
+		for i := 0; i < producers; i++ {
+			producerGroup.Add(1)
+			go func() {
+				defer producerGroup.Done()
+				for j := 0; j < itemsPerProducer; j++ {
+					assert.NoError(t, q.Push(context.Background(), 1), assert.Continue())
+				}
+			}()
+		}
+		totals := make(chan int, producers)
+		for i := 0; i < producers; i++ {
+			go func() {
+				total := 0
+				for {
+					item, err := q.Pop(context.Background())
+					if err != nil {
+						totals <- total
+						return
+					}
+					total += item
+				}
+			}()
+		}
+		producerGroup.Wait()
+		q.Close()
+		total := 0
+		for i := 0; i < producers; i++ {
+			total += <-totals
+		}
+		assert.Equals(t, total, producers*itemsPerProducer)
+	})
+}
@@ -0,0 +1,149 @@
+package ringbuffer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	// ErrClosed is returned when popping from a closed and empty RingBuffer.
+	ErrClosed = errors.New("the ring buffer is closed")
+)
+
+// RingBuffer holds the most recent items pushed to it, up to a fixed capacity. It is safe for many producers and
+// consumers. Pushing to a full RingBuffer drops its oldest item, so the producers are never blocked by slow consumers.
+type RingBuffer[T any] struct {
+	lock     sync.Mutex
+	items    []T
+	head     int
+	size     int
+	closed   bool
+	notEmpty chan struct{}
+}
+
+// New creates a RingBuffer that holds up to capacity items. If the capacity is not positive, this function panics.
+func New[T any](capacity int) *RingBuffer[T] {
+	if capacity <= 0 {
+		panic(fmt.Sprintf("the capacity %d must be greater than zero", capacity))
+	}
+	return &RingBuffer[T]{
+		lock:     sync.Mutex{},
+		items:    make([]T, capacity),
+		head:     0,
+		size:     0,
+		closed:   false,
+		notEmpty: nil,
+	}
+}
+
+// Push adds the item to the back of the RingBuffer. If it is full, its oldest item is dropped and returned
+// with true. Pushing to a closed RingBuffer drops the item and returns it with true.
+func (r *RingBuffer[T]) Push(item T) (T, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.closed {
+		return item, true
+	}
+
+	var dropped T
+	overwritten := r.size == len(r.items)
+	if overwritten {
+		dropped = r.popLocked()
+	}
+	r.items[(r.head+r.size)%len(r.items)] = item
+	r.size++
+	if r.notEmpty != nil {
+		close(r.notEmpty)
+		r.notEmpty = nil
+	}
+	return dropped, overwritten
+}
+
+// Pop removes the oldest item of the RingBuffer. If it is empty, it waits until an item is pushed.
+// The items that are left when the RingBuffer is closed can still be popped. It returns ErrClosed once the
+// RingBuffer is closed and empty, or the error of the context if it is done first.
+func (r *RingBuffer[T]) Pop(ctx context.Context) (T, error) {
+	for {
+		r.lock.Lock()
+		if r.size > 0 {
+			item := r.popLocked()
+			r.lock.Unlock()
+			return item, nil
+		}
+		if r.closed {
+			r.lock.Unlock()
+			var zeroValue T
+			return zeroValue, ErrClosed
+		}
+		if r.notEmpty == nil {
+			r.notEmpty = make(chan struct{})
+		}
+		notEmpty := r.notEmpty
+		r.lock.Unlock()
+
+		select {
+		case <-notEmpty:
+		case <-ctx.Done():
+			var zeroValue T
+			return zeroValue, ctx.Err()
+		}
+	}
+}
+
+// TryPop removes the oldest item of the RingBuffer without waiting. It returns false if the RingBuffer is empty.
+func (r *RingBuffer[T]) TryPop() (T, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.size == 0 {
+		var zeroValue T
+		return zeroValue, false
+	}
+	return r.popLocked(), true
+}
+
+// Snapshot returns a copy of the items of the RingBuffer from the oldest to the newest, without removing them.
+func (r *RingBuffer[T]) Snapshot() []T {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	snapshot := make([]T, r.size)
+	for i := range snapshot {
+		snapshot[i] = r.items[(r.head+i)%len(r.items)]
+	}
+	return snapshot
+}
+
+// Close stops the RingBuffer from accepting items and wakes the waiting consumers.
+// It is safe to call Close many times.
+func (r *RingBuffer[T]) Close() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.closed = true
+	if r.notEmpty != nil {
+		close(r.notEmpty)
+		r.notEmpty = nil
+	}
+}
+
+// Len returns the number of items in the RingBuffer.
+func (r *RingBuffer[T]) Len() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.size
+}
+
+// Cap returns the maximum number of items of the RingBuffer.
+func (r *RingBuffer[T]) Cap() int {
+	return len(r.items)
+}
+
+// popLocked removes the oldest item of the RingBuffer, which must not be empty. The lock must be held.
+func (r *RingBuffer[T]) popLocked() T {
+	item := r.items[r.head]
+	var zeroValue T
+	r.items[r.head] = zeroValue
+	r.head = (r.head + 1) % len(r.items)
+	r.size--
+	return item
+}
@@ -0,0 +1,129 @@
+package ringbuffer_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/datastructures/ringbuffer"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestRingBuffer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when the capacity is not positive it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			ringbuffer.New[int](0)
+		}, "the capacity 0 must be greater than zero")
+	})
+
+	t.Run("when the ring buffer is full it should drop the oldest item", func(t *testing.T) {
+		t.Parallel()
+		buffer := ringbuffer.New[int](3)
+		assert.Equals(t, buffer.Cap(), 3)
+		for i := 1; i <= 3; i++ {
+			_, dropped := buffer.Push(i)
+			assert.False(t, dropped)
+		}
+		droppedItem, dropped := buffer.Push(4)
+		assert.True(t, dropped)
+		assert.Equals(t, droppedItem, 1)
+		assert.Equals(t, buffer.Snapshot(), []int{2, 3, 4})
+		assert.Equals(t, buffer.Len(), 3)
+	})
+
+	t.Run("when items are popped it should return them from the oldest", func(t *testing.T) {
+		t.Parallel()
+		buffer := ringbuffer.New[int](2)
+		for i := 0; i < 5; i++ {
+			buffer.Push(i)
+		}
+		item, err := buffer.Pop(t.Context())
+		assert.NoError(t, err)
+		assert.Equals(t, item, 3)
+		item, popped := buffer.TryPop()
+		assert.True(t, popped)
+		assert.Equals(t, item, 4)
+		_, popped = buffer.TryPop()
+		assert.False(t, popped)
+		assert.Equals(t, buffer.Snapshot(), []int{})
+	})
+
+	t.Run("when the ring buffer is empty it should wait for an item to be pushed", func(t *testing.T) {
+		t.Parallel()
+		buffer := ringbuffer.New[int](1)
+		popped := make(chan int)
+		go func() {
+			item, _ := buffer.Pop(context.Background())
+			popped <- item
+		}()
+		time.Sleep(10 * time.Millisecond)
+		buffer.Push(1)
+		assert.Equals(t, <-popped, 1)
+	})
+
+	t.Run("when the context is done while waiting it should return its error", func(t *testing.T) {
+		t.Parallel()
+		buffer := ringbuffer.New[int](1)
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+		_, err := buffer.Pop(ctx)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("when the ring buffer is closed it should drain the items and then return an error", func(t *testing.T) {
+		t.Parallel()
+		buffer := ringbuffer.New[int](2)
+		buffer.Push(1)
+		buffer.Close()
+		buffer.Close()
+		droppedItem, dropped := buffer.Push(2)
+		assert.True(t, dropped)
+		assert.Equals(t, droppedItem, 2)
+		item, err := buffer.Pop(t.Context())
+		assert.NoError(t, err)
+		assert.Equals(t, item, 1)
+		_, err = buffer.Pop(t.Context())
+		assert.ErrorExact(t, err, "the ring buffer is closed")
+	})
+
+	t.Run("when the ring buffer is closed it should wake the waiting consumers", func(t *testing.T) {
+		t.Parallel()
+		buffer := ringbuffer.New[int](1)
+		errs := make(chan error)
+		go func() {
+			_, err := buffer.Pop(context.Background())
+			errs <- err
+		}()
+		time.Sleep(10 * time.Millisecond)
+		buffer.Close()
+		assert.True(t, errors.Is(<-errs, ringbuffer.ErrClosed))
+	})
+
+	t.Run("it should be able to handle many producers and consumers", func(t *testing.T) {
+		t.Parallel()
+		buffer := ringbuffer.New[int](4)
+		waitGroup := sync.WaitGroup{}
+		for i := 0; i < 8; i++ {
+			waitGroup.Add(2)
+			go func() {
+				defer waitGroup.Done()
+				for j := 0; j < 100; j++ {
+					buffer.Push(j)
+				}
+			}()
+			go func() {
+				defer waitGroup.Done()
+				for j := 0; j < 100; j++ {
+					buffer.TryPop()
+				}
+			}()
+		}
+		waitGroup.Wait()
+		assert.True(t, buffer.Len() <= buffer.Cap())
+	})
+}
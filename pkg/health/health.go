@@ -0,0 +1,262 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/logger"
+)
+
+const (
+	// DefaultTimeout is the time a check can take when WithTimeout is not used.
+	DefaultTimeout = 5 * time.Second
+)
+
+// Checker checks the health of a component, like a database connection.
+// It returns an error if the component is unhealthy. It must return when the context is done.
+type Checker func(ctx context.Context) error
+
+// Status is the health of a check or of all the checks of a Registry.
+type Status string
+
+const (
+	// StatusHealthy means the check succeeded.
+	StatusHealthy Status = "healthy"
+
+	// StatusUnhealthy means the check failed or timed out.
+	StatusUnhealthy Status = "unhealthy"
+
+	// StatusUnknown means the check runs in the background and has not completed yet.
+	StatusUnknown Status = "unknown"
+)
+
+// Result is the outcome of a check.
+type Result struct {
+	Status    Status    `json:"status"`
+	LatencyMs float64   `json:"latencyMs"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checkedAt,omitzero"`
+}
+
+// Report aggregates the results of the checks of a Registry. Its Status is healthy only if every check is healthy.
+type Report struct {
+	Status Status            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// checkOptions is configured by the caller with the CheckOption functions.
+type checkOptions struct {
+	timeout  time.Duration
+	interval time.Duration
+}
+
+// CheckOption is used to configure a check.
+type CheckOption func(checkOpts *checkOptions)
+
+// WithTimeout bounds the time the check can take. A check that times out is unhealthy.
+// The default is DefaultTimeout.
+func WithTimeout(timeout time.Duration) CheckOption {
+	if timeout <= 0 {
+		panic("the check timeout must be greater than zero")
+	}
+	return func(checkOpts *checkOptions) {
+		checkOpts.timeout = timeout
+	}
+}
+
+// WithInterval runs the check in the background on the interval while the Registry is running, instead of
+// when a report is requested. This is used for checks that are too slow or expensive to run on every request.
+// The reports use the latest result of the check, which is unknown until it first completes.
+func WithInterval(interval time.Duration) CheckOption {
+	if interval <= 0 {
+		panic("the check interval must be greater than zero")
+	}
+	return func(checkOpts *checkOptions) {
+		checkOpts.interval = interval
+	}
+}
+
+// check is a named Checker registered in a Registry.
+type check struct {
+	name    string
+	checker Checker
+	opts    *checkOptions
+	latest  atomic.Pointer[Result]
+}
+
+// Registry holds named health checks and reports their results.
+type Registry struct {
+	mu      sync.RWMutex
+	checks  []*check
+	running atomic.Bool
+}
+
+// NewRegistry allocates an empty Registry. A Registry without checks is healthy.
+func NewRegistry() *Registry {
+	return &Registry{
+		mu:     sync.RWMutex{},
+		checks: make([]*check, 0),
+	}
+}
+
+// MustRegister adds a named check to the registry. Checks with an interval must be registered before Run.
+// If the name is empty or already registered, or the checker is nil, this function panics.
+func (registry *Registry) MustRegister(name string, checker Checker, opts ...CheckOption) {
+	if name == "" {
+		panic("the check name cannot be empty")
+	}
+	if checker == nil {
+		panic("the checker cannot be nil")
+	}
+	checkOpts := &checkOptions{
+		timeout:  DefaultTimeout,
+		interval: 0,
+	}
+	for _, opt := range opts {
+		opt(checkOpts)
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	for _, registered := range registry.checks {
+		if registered.name == name {
+			panic(fmt.Sprintf("the check '%s' is already registered", name))
+		}
+	}
+	newCheck := &check{
+		name:    name,
+		checker: checker,
+		opts:    checkOpts,
+	}
+	newCheck.latest.Store(&Result{Status: StatusUnknown})
+	registry.checks = append(registry.checks, newCheck)
+}
+
+// Run runs the checks that have an interval in the background until the context is done. Each check runs
+// immediately, then on its interval. Run blocks until the checks stop. If the registry is already running,
+// Run returns immediately.
+func (registry *Registry) Run(ctx context.Context) {
+	if !registry.running.CompareAndSwap(false, true) {
+		return
+	}
+	defer registry.running.Store(false)
+
+	registry.mu.RLock()
+	checks := registry.checks
+	registry.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, c := range checks {
+		if c.opts.interval == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticker := time.NewTicker(c.opts.interval)
+			defer ticker.Stop()
+			for {
+				result := c.run(ctx)
+				if ctx.Err() != nil {
+					return
+				}
+				c.latest.Store(&result)
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Check returns a report of all the checks. The checks without an interval are run concurrently,
+// and the checks with an interval report their latest result.
+func (registry *Registry) Check(ctx context.Context) *Report {
+	registry.mu.RLock()
+	checks := registry.checks
+	registry.mu.RUnlock()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		if c.opts.interval != 0 {
+			results[i] = *c.latest.Load()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.run(ctx)
+		}()
+	}
+	wg.Wait()
+
+	report := &Report{
+		Status: StatusHealthy,
+		Checks: make(map[string]Result, len(checks)),
+	}
+	for i, c := range checks {
+		report.Checks[c.name] = results[i]
+		if results[i].Status != StatusHealthy {
+			report.Status = StatusUnhealthy
+		}
+	}
+	return report
+}
+
+// Handler returns an http.HandlerFunc that responds with the JSON Report of the checks. The status of the
+// response is HTTP 200 OK if the report is healthy, and HTTP 503 service unavailable otherwise.
+func (registry *Registry) Handler() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		report := registry.Check(request.Context())
+		writer.Header().Set(headers.ContentType, headers.ContentTypeApplicationJson)
+		writer.Header().Set(headers.CacheControl, "no-store")
+		if report.Status == StatusHealthy {
+			writer.WriteHeader(http.StatusOK)
+		} else {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+		}
+		if err := json.NewEncoder(writer).Encode(report); err != nil {
+			logger.Errorf(request.Context(), "Error encoding the health report (%s).", err)
+		}
+	}
+}
+
+// run invokes the checker with the timeout of the check and returns its result.
+func (c *check) run(ctx context.Context) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.opts.timeout)
+	defer cancel()
+
+	start := time.Now()
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- c.checker(ctx)
+	}()
+	var err error
+	select {
+	case err = <-errChan:
+	case <-ctx.Done():
+		err = fmt.Errorf("the check did not complete in time (%w)", ctx.Err())
+	}
+	latency := time.Since(start)
+
+	result := Result{
+		Status:    StatusHealthy,
+		LatencyMs: float64(latency.Microseconds()) / 1000,
+		CheckedAt: start.UTC(),
+	}
+	if err != nil {
+		result.Status = StatusUnhealthy
+		result.Error = err.Error()
+	}
+	return result
+}
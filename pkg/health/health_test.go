@@ -0,0 +1,154 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/health"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when the registry has no checks it should be healthy", func(t *testing.T) {
+		t.Parallel()
+		report := health.NewRegistry().Check(context.Background())
+		assert.Equals(t, report.Status, health.StatusHealthy)
+		assert.Equals(t, len(report.Checks), 0)
+	})
+
+	t.Run("when a check fails it should make the report unhealthy", func(t *testing.T) {
+		t.Parallel()
+		registry := health.NewRegistry()
+		registry.MustRegister("ok", func(context.Context) error { return nil })
+		registry.MustRegister("broken", func(context.Context) error { return errors.New("broken") })
+		report := registry.Check(context.Background())
+		assert.Equals(t, report.Status, health.StatusUnhealthy)
+		assert.Equals(t, report.Checks["ok"].Status, health.StatusHealthy)
+		assert.Equals(t, report.Checks["ok"].Error, "")
+		assert.False(t, report.Checks["ok"].CheckedAt.IsZero())
+		assert.Equals(t, report.Checks["broken"].Status, health.StatusUnhealthy)
+		assert.Equals(t, report.Checks["broken"].Error, "broken")
+	})
+
+	t.Run("when the checks are slow they should run concurrently and report their latency", func(t *testing.T) {
+		t.Parallel()
+		registry := health.NewRegistry()
+		for _, name := range []string{"a", "b", "c"} {
+			registry.MustRegister(name, func(context.Context) error {
+				time.Sleep(time.Millisecond * 50)
+				return nil
+			})
+		}
+		start := time.Now()
+		report := registry.Check(context.Background())
+		assert.True(t, time.Since(start) < time.Millisecond*140)
+		assert.Equals(t, report.Status, health.StatusHealthy)
+		assert.True(t, report.Checks["a"].LatencyMs >= 50)
+	})
+
+	t.Run("when a check exceeds its timeout it should be unhealthy", func(t *testing.T) {
+		t.Parallel()
+		registry := health.NewRegistry()
+		registry.MustRegister("stuck", func(ctx context.Context) error {
+			<-ctx.Done()
+			return nil
+		}, health.WithTimeout(time.Millisecond*10))
+		registry.MustRegister("ignores context", func(context.Context) error {
+			time.Sleep(time.Millisecond * 100)
+			return nil
+		}, health.WithTimeout(time.Millisecond*10))
+		report := registry.Check(context.Background())
+		assert.Equals(t, report.Status, health.StatusUnhealthy)
+		assert.Equals(t, report.Checks["ignores context"].Error, "the check did not complete in time (context deadline exceeded)")
+		assert.True(t, report.Checks["ignores context"].LatencyMs < 100)
+	})
+
+	t.Run("when a check has an interval it should run in the background and report its latest result", func(t *testing.T) {
+		t.Parallel()
+		var calls atomic.Int32
+		registry := health.NewRegistry()
+		registry.MustRegister("background", func(context.Context) error {
+			if calls.Add(1) == 1 {
+				return errors.New("warming up")
+			}
+			return nil
+		}, health.WithInterval(time.Millisecond*10))
+
+		report := registry.Check(context.Background())
+		assert.Equals(t, report.Status, health.StatusUnhealthy)
+		assert.Equals(t, report.Checks["background"].Status, health.StatusUnknown)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			registry.Run(ctx)
+			close(done)
+		}()
+		for registry.Check(context.Background()).Status != health.StatusHealthy {
+			time.Sleep(time.Millisecond)
+		}
+		assert.True(t, calls.Load() >= 2)
+		registry.Run(ctx)
+		cancel()
+		<-done
+
+		callsAfterStop := calls.Load()
+		time.Sleep(time.Millisecond * 30)
+		assert.Equals(t, calls.Load(), callsAfterStop)
+	})
+
+	t.Run("when the handler is called it should respond with the JSON report", func(t *testing.T) {
+		t.Parallel()
+		registry := health.NewRegistry()
+		registry.MustRegister("ok", func(context.Context) error { return nil })
+		recorder := httptest.NewRecorder()
+		registry.Handler()(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.Equals(t, recorder.Header().Get(headers.ContentType), headers.ContentTypeApplicationJson)
+		assert.Equals(t, recorder.Header().Get(headers.CacheControl), "no-store")
+		decoded := map[string]any{}
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &decoded))
+		assert.Equals(t, decoded["status"], "healthy")
+		check := decoded["checks"].(map[string]any)["ok"].(map[string]any)
+		assert.Equals(t, check["status"], "healthy")
+		_, hasLatency := check["latencyMs"]
+		assert.True(t, hasLatency)
+		_, hasError := check["error"]
+		assert.False(t, hasError)
+
+		registry.MustRegister("broken", func(context.Context) error { return errors.New("broken") })
+		recorder = httptest.NewRecorder()
+		registry.Handler()(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		assert.Equals(t, recorder.Code, http.StatusServiceUnavailable)
+	})
+
+	t.Run("when a check is registered incorrectly it should panic", func(t *testing.T) {
+		t.Parallel()
+		registry := health.NewRegistry()
+		registry.MustRegister("db", func(context.Context) error { return nil })
+		assert.PanicExact(t, func() {
+			registry.MustRegister("db", func(context.Context) error { return nil })
+		}, "the check 'db' is already registered")
+		assert.PanicExact(t, func() {
+			registry.MustRegister("", func(context.Context) error { return nil })
+		}, "the check name cannot be empty")
+		assert.PanicExact(t, func() {
+			registry.MustRegister("nil", nil)
+		}, "the checker cannot be nil")
+		assert.PanicExact(t, func() {
+			health.WithTimeout(0)
+		}, "the check timeout must be greater than zero")
+		assert.PanicExact(t, func() {
+			health.WithInterval(-time.Second)
+		}, "the check interval must be greater than zero")
+	})
+}
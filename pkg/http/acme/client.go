@@ -0,0 +1,399 @@
+package acme
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+)
+
+const (
+	// contentTypeJOSE is the media type of the signed requests sent to the ACME server.
+	contentTypeJOSE = "application/jose+json"
+
+	// replayNonceHeader holds the nonce that must be used in the next request to the ACME server.
+	replayNonceHeader = "Replay-Nonce"
+
+	// badNonceError is the problem type of a request that was rejected because of its nonce.
+	badNonceError = "urn:ietf:params:acme:error:badNonce"
+
+	// defaultPollInterval is the wait between polls of a resource when the server does not send a Retry-After header.
+	defaultPollInterval = time.Second
+)
+
+// directory lists the URLs of the ACME server resources, as defined by RFC 8555 section 7.1.1.
+type directory struct {
+	NewNonce   string `json:"newNonce"`
+	NewAccount string `json:"newAccount"`
+	NewOrder   string `json:"newOrder"`
+}
+
+// identifier is the subject of an order or an authorization.
+type identifier struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// order is a request for a certificate, as defined by RFC 8555 section 7.1.3.
+type order struct {
+	Status         string   `json:"status"`
+	Authorizations []string `json:"authorizations"`
+	Finalize       string   `json:"finalize"`
+	Certificate    string   `json:"certificate"`
+}
+
+// authorization is the proof that the account controls an identifier, as defined by RFC 8555 section 7.1.4.
+type authorization struct {
+	Status     string      `json:"status"`
+	Identifier identifier  `json:"identifier"`
+	Challenges []challenge `json:"challenges"`
+}
+
+// challenge is a way of proving control of an identifier, as defined by RFC 8555 section 8.
+type challenge struct {
+	Type   string `json:"type"`
+	URL    string `json:"url"`
+	Token  string `json:"token"`
+	Status string `json:"status"`
+}
+
+// Problem is an error returned by the ACME server, as defined by RFC 7807.
+type Problem struct {
+	Type   string `json:"type"`
+	Detail string `json:"detail"`
+	Status int    `json:"status"`
+}
+
+// Error returns the type and the detail of the problem.
+func (p *Problem) Error() string {
+	return fmt.Sprintf("the ACME server responded with status %d (%s: %s)", p.Status, p.Type, p.Detail)
+}
+
+// client sends signed requests to an ACME server on behalf of an account.
+type client struct {
+	httpClient   *http.Client
+	directoryURL string
+	key          *ecdsa.PrivateKey
+	jwk          *jsonWebKey
+	lock         sync.Mutex
+	directory    *directory
+	keyID        string
+	nonces       []string
+}
+
+// newClient allocates a client for the account key.
+func newClient(httpClient *http.Client, directoryURL string, key *ecdsa.PrivateKey) (*client, error) {
+	jwk, err := newJSONWebKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return &client{
+		httpClient:   httpClient,
+		directoryURL: directoryURL,
+		key:          key,
+		jwk:          jwk,
+	}, nil
+}
+
+// keyAuthorization returns the key authorization of a challenge token, as defined by RFC 8555 section 8.1.
+func (c *client) keyAuthorization(token string) string {
+	return token + "." + c.jwk.thumbprint()
+}
+
+// discover fetches the directory of the ACME server once.
+func (c *client) discover(ctx context.Context) (*directory, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.directory != nil {
+		return c.directory, nil
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, c.directoryURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the ACME directory (%w)", err)
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	if response.StatusCode != http.StatusOK {
+		return nil, responseProblem(response)
+	}
+	dir := &directory{}
+	if err := json.NewDecoder(response.Body).Decode(dir); err != nil {
+		return nil, fmt.Errorf("failed to decode the ACME directory (%w)", err)
+	}
+	c.directory = dir
+	return dir, nil
+}
+
+// nonce returns an unused nonce, fetching a new one from the server if none were saved from previous responses.
+func (c *client) nonce(ctx context.Context, dir *directory) (string, error) {
+	c.lock.Lock()
+	if len(c.nonces) != 0 {
+		nonce := c.nonces[len(c.nonces)-1]
+		c.nonces = c.nonces[:len(c.nonces)-1]
+		c.lock.Unlock()
+		return nonce, nil
+	}
+	c.lock.Unlock()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodHead, dir.NewNonce, nil)
+	if err != nil {
+		return "", err
+	}
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch a nonce (%w)", err)
+	}
+	_ = response.Body.Close()
+	nonce := response.Header.Get(replayNonceHeader)
+	if nonce == "" {
+		return "", errors.New("the ACME server did not send a nonce")
+	}
+	return nonce, nil
+}
+
+// saveNonce keeps the nonce of a response for the next request.
+func (c *client) saveNonce(response *http.Response) {
+	if nonce := response.Header.Get(replayNonceHeader); nonce != "" {
+		c.lock.Lock()
+		c.nonces = append(c.nonces, nonce)
+		c.lock.Unlock()
+	}
+}
+
+// post sends a signed request with the payload to the URL and returns the response if it has one of the
+// expected statuses. A nil payload sends a POST-as-GET request. A request rejected because of its nonce is
+// sent again once with a new nonce. The caller must close the body of the response.
+func (c *client) post(ctx context.Context, url string, payload any, expectedStatuses ...int) (*http.Response, error) {
+	dir, err := c.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for attempt := 0; ; attempt++ {
+		nonce, err := c.nonce(ctx, dir)
+		if err != nil {
+			return nil, err
+		}
+		header := &protectedHeader{Nonce: nonce, URL: url}
+		c.lock.Lock()
+		if c.keyID == "" {
+			header.JWK = c.jwk
+		} else {
+			header.KeyID = c.keyID
+		}
+		c.lock.Unlock()
+		body, err := signJWS(c.key, header, payload)
+		if err != nil {
+			return nil, err
+		}
+
+		request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		request.Header.Set(headers.ContentType, contentTypeJOSE)
+		response, err := c.httpClient.Do(request)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send the request to %s (%w)", url, err)
+		}
+		c.saveNonce(response)
+		for _, expectedStatus := range expectedStatuses {
+			if response.StatusCode == expectedStatus {
+				return response, nil
+			}
+		}
+
+		problem := responseProblem(response)
+		_ = response.Body.Close()
+		if problem.Type == badNonceError && attempt == 0 {
+			continue
+		}
+		return nil, problem
+	}
+}
+
+// postJSON sends a signed request and decodes the JSON response into the result.
+// It returns the Location header of the response, which is the URL of a created resource.
+func (c *client) postJSON(ctx context.Context, url string, payload any, result any, expectedStatuses ...int) (string, error) {
+	response, err := c.post(ctx, url, payload, expectedStatuses...)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	if result != nil {
+		if err := json.NewDecoder(response.Body).Decode(result); err != nil {
+			return "", fmt.Errorf("failed to decode the response of %s (%w)", url, err)
+		}
+	}
+	return response.Header.Get("Location"), nil
+}
+
+// register creates the account of the key, or finds it if it already exists, and saves its key ID.
+func (c *client) register(ctx context.Context, contact []string) error {
+	c.lock.Lock()
+	registered := c.keyID != ""
+	c.lock.Unlock()
+	if registered {
+		return nil
+	}
+	dir, err := c.discover(ctx)
+	if err != nil {
+		return err
+	}
+	payload := map[string]any{"termsOfServiceAgreed": true}
+	if len(contact) != 0 {
+		payload["contact"] = contact
+	}
+	keyID, err := c.postJSON(ctx, dir.NewAccount, payload, nil, http.StatusOK, http.StatusCreated)
+	if err != nil {
+		return fmt.Errorf("failed to register the ACME account (%w)", err)
+	}
+	if keyID == "" {
+		return errors.New("the ACME server did not return the account URL")
+	}
+	c.lock.Lock()
+	c.keyID = keyID
+	c.lock.Unlock()
+	return nil
+}
+
+// newOrder requests a certificate for the domains and returns the order and its URL.
+func (c *client) newOrder(ctx context.Context, domains []string) (*order, string, error) {
+	dir, err := c.discover(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	identifiers := make([]identifier, 0, len(domains))
+	for _, domain := range domains {
+		identifiers = append(identifiers, identifier{Type: "dns", Value: domain})
+	}
+	created := &order{}
+	orderURL, err := c.postJSON(ctx, dir.NewOrder, map[string]any{"identifiers": identifiers}, created, http.StatusCreated)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create the order (%w)", err)
+	}
+	return created, orderURL, nil
+}
+
+// authorization fetches the authorization at the URL.
+func (c *client) authorization(ctx context.Context, url string) (*authorization, error) {
+	authz := &authorization{}
+	if _, err := c.postJSON(ctx, url, nil, authz, http.StatusOK); err != nil {
+		return nil, fmt.Errorf("failed to fetch the authorization (%w)", err)
+	}
+	return authz, nil
+}
+
+// accept tells the server that the challenge is ready to be validated.
+func (c *client) accept(ctx context.Context, chal *challenge) error {
+	if _, err := c.postJSON(ctx, chal.URL, struct{}{}, nil, http.StatusOK); err != nil {
+		return fmt.Errorf("failed to accept the %s challenge (%w)", chal.Type, err)
+	}
+	return nil
+}
+
+// waitAuthorization polls the authorization until it is valid, or returns an error if it is invalid.
+func (c *client) waitAuthorization(ctx context.Context, url string) error {
+	for {
+		authz := &authorization{}
+		response, err := c.post(ctx, url, nil, http.StatusOK)
+		if err != nil {
+			return fmt.Errorf("failed to fetch the authorization (%w)", err)
+		}
+		err = json.NewDecoder(response.Body).Decode(authz)
+		_ = response.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to decode the authorization (%w)", err)
+		}
+		switch authz.Status {
+		case "valid":
+			return nil
+		case "pending", "processing":
+		default:
+			return fmt.Errorf("the authorization of %s is %s", authz.Identifier.Value, authz.Status)
+		}
+		if err := sleep(ctx, retryAfter(response)); err != nil {
+			return err
+		}
+	}
+}
+
+// finalize sends the certificate signing request and polls the order until the certificate is issued.
+// It returns the certificate chain in the PEM format.
+func (c *client) finalize(ctx context.Context, pending *order, orderURL string, csr []byte) ([]byte, error) {
+	current := &order{}
+	if _, err := c.postJSON(ctx, pending.Finalize, map[string]string{"csr": encodeSegment(csr)}, current, http.StatusOK); err != nil {
+		return nil, fmt.Errorf("failed to finalize the order (%w)", err)
+	}
+	for current.Status != "valid" {
+		if current.Status != "pending" && current.Status != "ready" && current.Status != "processing" {
+			return nil, fmt.Errorf("the order is %s", current.Status)
+		}
+		if err := sleep(ctx, defaultPollInterval); err != nil {
+			return nil, err
+		}
+		current = &order{}
+		if _, err := c.postJSON(ctx, orderURL, nil, current, http.StatusOK); err != nil {
+			return nil, fmt.Errorf("failed to fetch the order (%w)", err)
+		}
+	}
+
+	response, err := c.post(ctx, current.Certificate, nil, http.StatusOK)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download the certificate (%w)", err)
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	chain, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the certificate (%w)", err)
+	}
+	return chain, nil
+}
+
+// responseProblem decodes the problem of an error response. If the body is not a problem, the status text is used.
+func responseProblem(response *http.Response) *Problem {
+	problem := &Problem{}
+	if err := json.NewDecoder(response.Body).Decode(problem); err != nil || problem.Type == "" {
+		problem.Type = "about:blank"
+		problem.Detail = http.StatusText(response.StatusCode)
+	}
+	problem.Status = response.StatusCode
+	return problem
+}
+
+// retryAfter returns the wait of the Retry-After header of the response, or the default poll interval.
+func retryAfter(response *http.Response) time.Duration {
+	if seconds, err := strconv.Atoi(response.Header.Get(headers.RetryAfter)); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return defaultPollInterval
+}
+
+// sleep waits for the duration or until the context is done.
+func sleep(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
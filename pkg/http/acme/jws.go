@@ -0,0 +1,100 @@
+package acme
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// jsonWebKey is the public part of an ECDSA P-256 account key, as defined by RFC 7517.
+// The fields are in lexicographic order so the encoding can be used for the RFC 7638 thumbprint.
+type jsonWebKey struct {
+	Crv string `json:"crv"`
+	Kty string `json:"kty"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// protectedHeader is the protected header of a JWS sent to the ACME server, as defined by RFC 8555 section 6.2.
+// The account is identified with the JWK before it is registered, and with the key ID after.
+type protectedHeader struct {
+	Alg   string      `json:"alg"`
+	JWK   *jsonWebKey `json:"jwk,omitempty"`
+	KeyID string      `json:"kid,omitempty"`
+	Nonce string      `json:"nonce"`
+	URL   string      `json:"url"`
+}
+
+// signedRequest is a JWS with the flattened JSON serialization.
+type signedRequest struct {
+	Protected string `json:"protected"`
+	Payload   string `json:"payload"`
+	Signature string `json:"signature"`
+}
+
+// encodeSegment encodes the data with base64url without padding.
+func encodeSegment(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// newJSONWebKey returns the JWK of the public key of the account. The key must be on the P-256 curve.
+func newJSONWebKey(key *ecdsa.PrivateKey) (*jsonWebKey, error) {
+	publicKey, err := key.PublicKey.ECDH()
+	if err != nil {
+		return nil, fmt.Errorf("the account key is invalid (%w)", err)
+	}
+	// The uncompressed point is 0x04 followed by the X and Y coordinates.
+	point := publicKey.Bytes()
+	size := (len(point) - 1) / 2
+	return &jsonWebKey{
+		Crv: "P-256",
+		Kty: "EC",
+		X:   encodeSegment(point[1 : 1+size]),
+		Y:   encodeSegment(point[1+size:]),
+	}, nil
+}
+
+// thumbprint returns the base64url encoded RFC 7638 thumbprint of the JWK.
+func (jwk *jsonWebKey) thumbprint() string {
+	encoded, _ := json.Marshal(jwk)
+	digest := sha256.Sum256(encoded)
+	return encodeSegment(digest[:])
+}
+
+// signJWS signs the payload with the ES256 algorithm. A nil payload is encoded as an empty string,
+// which is how RFC 8555 section 6.3 represents a POST-as-GET request.
+func signJWS(key *ecdsa.PrivateKey, header *protectedHeader, payload any) ([]byte, error) {
+	header.Alg = "ES256"
+	encodedHeader, err := json.Marshal(header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the protected header (%w)", err)
+	}
+	encodedPayload := ""
+	if payload != nil {
+		payloadJSON, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode the payload (%w)", err)
+		}
+		encodedPayload = encodeSegment(payloadJSON)
+	}
+	request := signedRequest{
+		Protected: encodeSegment(encodedHeader),
+		Payload:   encodedPayload,
+	}
+	digest := sha256.Sum256([]byte(request.Protected + "." + request.Payload))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign the request (%w)", err)
+	}
+	request.Signature = encodeSegment(append(fixedBytes(r, 32), fixedBytes(s, 32)...))
+	return json.Marshal(request)
+}
+
+// fixedBytes encodes the integer as big-endian bytes padded to the size.
+func fixedBytes(value *big.Int, size int) []byte {
+	return value.FillBytes(make([]byte, size))
+}
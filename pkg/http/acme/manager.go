@@ -0,0 +1,557 @@
+package acme
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/logger"
+)
+
+const (
+	// LetsEncryptURL is the directory URL of the Let's Encrypt production environment.
+	LetsEncryptURL = "https://acme-v02.api.letsencrypt.org/directory"
+
+	// LetsEncryptStagingURL is the directory URL of the Let's Encrypt staging environment.
+	// Its certificates are not trusted, but its rate limits are much higher, so it is used for testing.
+	LetsEncryptStagingURL = "https://acme-staging-v02.api.letsencrypt.org/directory"
+
+	// ALPNProtocol is the application protocol negotiated by the tls-alpn-01 challenge, as defined by RFC 8737.
+	ALPNProtocol = "acme-tls/1"
+
+	// HTTPChallengePath is the path prefix of the http-01 challenge responses, as defined by RFC 8555 section 8.3.
+	HTTPChallengePath = "/.well-known/acme-challenge/"
+
+	// DefaultRenewBefore is how long before a certificate expires that it is renewed when WithRenewBefore is not used.
+	DefaultRenewBefore = 30 * 24 * time.Hour
+
+	// accountKeyFile is the name of the file in the cache directory that holds the account key.
+	accountKeyFile = "account.key"
+
+	// challengeTypeHTTP is the http-01 challenge type.
+	challengeTypeHTTP = "http-01"
+
+	// challengeTypeTLSALPN is the tls-alpn-01 challenge type.
+	challengeTypeTLSALPN = "tls-alpn-01"
+)
+
+// acmeIdentifierOID is the id-pe-acmeIdentifier certificate extension of the tls-alpn-01 challenge.
+var acmeIdentifierOID = asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 31}
+
+// config is configured by the Option functions.
+type config struct {
+	directoryURL  string
+	email         string
+	httpClient    *http.Client
+	renewBefore   time.Duration
+	httpChallenge bool
+}
+
+// Option is used to configure the Manager.
+type Option func(cfg *config)
+
+// WithDirectoryURL sets the directory URL of the ACME server. The default is LetsEncryptURL.
+func WithDirectoryURL(directoryURL string) Option {
+	return func(cfg *config) {
+		cfg.directoryURL = directoryURL
+	}
+}
+
+// WithEmail sets the contact email of the ACME account. The certificate authority uses it to send expiry notices.
+func WithEmail(email string) Option {
+	return func(cfg *config) {
+		cfg.email = email
+	}
+}
+
+// WithHTTPClient sets the http.Client used to send requests to the ACME server.
+func WithHTTPClient(httpClient *http.Client) Option {
+	if httpClient == nil {
+		panic("the ACME HTTP client cannot be nil")
+	}
+	return func(cfg *config) {
+		cfg.httpClient = httpClient
+	}
+}
+
+// WithRenewBefore sets how long before a certificate expires that it is renewed. The default is DefaultRenewBefore.
+func WithRenewBefore(renewBefore time.Duration) Option {
+	if renewBefore <= 0 {
+		panic("the ACME renewal period must be greater than zero")
+	}
+	return func(cfg *config) {
+		cfg.renewBefore = renewBefore
+	}
+}
+
+// WithHTTPChallenge allows the Manager to solve http-01 challenges. The handler returned by HTTPHandler must be
+// served on port 80 of the domains. Without this option, only tls-alpn-01 challenges are solved.
+func WithHTTPChallenge() Option {
+	return func(cfg *config) {
+		cfg.httpChallenge = true
+	}
+}
+
+// Manager obtains and renews certificates from an ACME certificate authority, like Let's Encrypt.
+//
+// A certificate is obtained the first time a client connects with the server name of an allowed domain. The
+// certificates and the account key are stored in the cache directory so they survive restarts. Certificates
+// are renewed when they are close to expiring, either by RenewExpiring or when they are served.
+type Manager struct {
+	cfg        *config
+	cacheDir   string
+	domains    []string
+	client     *client
+	lock       sync.Mutex
+	certs      map[string]*tls.Certificate
+	obtaining  map[string]*sync.Mutex
+	renewing   map[string]bool
+	httpTokens map[string]string
+	alpnCerts  map[string]*tls.Certificate
+}
+
+// NewManager allocates a Manager for the allowed domains. The account key is loaded from the cache directory,
+// or created if the directory does not have one.
+func NewManager(cacheDir string, domains []string, opts ...Option) (*Manager, error) {
+	cfg := &config{
+		directoryURL:  LetsEncryptURL,
+		email:         "",
+		httpClient:    &http.Client{Timeout: time.Minute},
+		renewBefore:   DefaultRenewBefore,
+		httpChallenge: false,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if cacheDir == "" {
+		return nil, errors.New("the ACME cache directory cannot be empty")
+	}
+	if len(domains) == 0 {
+		return nil, errors.New("at least one ACME domain must be allowed")
+	}
+	normalizedDomains := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		normalizedDomains = append(normalizedDomains, normalizeDomain(domain))
+	}
+
+	if err := os.MkdirAll(cacheDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create the ACME cache directory (%w)", err)
+	}
+	accountKey, err := loadOrCreateAccountKey(filepath.Join(cacheDir, accountKeyFile))
+	if err != nil {
+		return nil, err
+	}
+	acmeClient, err := newClient(cfg.httpClient, cfg.directoryURL, accountKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Manager{
+		cfg:        cfg,
+		cacheDir:   cacheDir,
+		domains:    normalizedDomains,
+		client:     acmeClient,
+		certs:      make(map[string]*tls.Certificate),
+		obtaining:  make(map[string]*sync.Mutex),
+		renewing:   make(map[string]bool),
+		httpTokens: make(map[string]string),
+		alpnCerts:  make(map[string]*tls.Certificate),
+	}, nil
+}
+
+// TLSConfig returns a tls.Config that serves the certificates of the Manager and answers tls-alpn-01 challenges.
+func (m *Manager) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS13,
+		NextProtos:     []string{ALPNProtocol},
+		GetCertificate: m.GetCertificate,
+	}
+}
+
+// GetCertificate returns the certificate of the server name of the handshake, obtaining it if needed.
+// It is meant to be used as the tls.Config.GetCertificate function.
+func (m *Manager) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	domain := normalizeDomain(hello.ServerName)
+	if domain == "" {
+		return nil, errors.New("the client did not send a server name")
+	}
+
+	if slices.Contains(hello.SupportedProtos, ALPNProtocol) {
+		m.lock.Lock()
+		defer m.lock.Unlock()
+		if cert, found := m.alpnCerts[domain]; found {
+			return cert, nil
+		}
+		return nil, fmt.Errorf("there is no tls-alpn-01 challenge for %s", domain)
+	}
+
+	if !m.allowed(domain) {
+		return nil, fmt.Errorf("the server name %s is not allowed", domain)
+	}
+
+	cert, err := m.cachedCertificate(domain)
+	if err == nil && time.Now().Before(cert.Leaf.NotAfter) {
+		if m.needsRenewal(cert) {
+			m.renewInBackground(domain)
+		}
+		return cert, nil
+	}
+
+	ctx := hello.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return m.Obtain(ctx, domain)
+}
+
+// HTTPHandler returns a handler that answers http-01 challenges. Other requests are passed to the fallback
+// handler. If the fallback is nil, other requests are redirected to HTTPS.
+func (m *Manager) HTTPHandler(fallback http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if !strings.HasPrefix(request.URL.Path, HTTPChallengePath) {
+			if fallback != nil {
+				fallback.ServeHTTP(writer, request)
+				return
+			}
+			if request.Method != http.MethodGet && request.Method != http.MethodHead {
+				http.Error(writer, "Use HTTPS.", http.StatusBadRequest)
+				return
+			}
+			target := "https://" + stripPort(request.Host) + request.URL.RequestURI()
+			http.Redirect(writer, request, target, http.StatusMovedPermanently)
+			return
+		}
+		token := strings.TrimPrefix(request.URL.Path, HTTPChallengePath)
+		m.lock.Lock()
+		keyAuth, found := m.httpTokens[token]
+		m.lock.Unlock()
+		if !found {
+			http.NotFound(writer, request)
+			return
+		}
+		writer.Header().Set(headers.ContentType, "text/plain")
+		_, _ = writer.Write([]byte(keyAuth))
+	})
+}
+
+// RenewExpiring renews the certificates of the allowed domains that expire within the renewal period.
+// Domains without a certificate are skipped, since their certificate is obtained on the first handshake.
+func (m *Manager) RenewExpiring(ctx context.Context) error {
+	var errs error
+	for _, domain := range m.domains {
+		cert, err := m.cachedCertificate(domain)
+		if err != nil || !m.needsRenewal(cert) {
+			continue
+		}
+		if _, err := m.obtain(ctx, domain, cert); err != nil {
+			errs = errors.Join(errs, err)
+		}
+	}
+	return errs
+}
+
+// Obtain requests a new certificate for the domain, stores it in the cache, and returns it.
+// Concurrent calls for the same domain wait for the first one and return its certificate.
+func (m *Manager) Obtain(ctx context.Context, domain string) (*tls.Certificate, error) {
+	domain = normalizeDomain(domain)
+	if !m.allowed(domain) {
+		return nil, fmt.Errorf("the domain %s is not allowed", domain)
+	}
+	return m.obtain(ctx, domain, nil)
+}
+
+// obtain requests a certificate for the domain unless another call replaced the previous certificate
+// while this call was waiting for the lock of the domain.
+func (m *Manager) obtain(ctx context.Context, domain string, previous *tls.Certificate) (*tls.Certificate, error) {
+	m.lock.Lock()
+	domainLock, found := m.obtaining[domain]
+	if !found {
+		domainLock = &sync.Mutex{}
+		m.obtaining[domain] = domainLock
+	}
+	m.lock.Unlock()
+	domainLock.Lock()
+	defer domainLock.Unlock()
+
+	if cert, err := m.cachedCertificate(domain); err == nil && cert != previous && !m.needsRenewal(cert) {
+		return cert, nil
+	}
+
+	cert, err := m.requestCertificate(ctx, domain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to obtain a certificate for %s (%w)", domain, err)
+	}
+	m.lock.Lock()
+	m.certs[domain] = cert
+	m.lock.Unlock()
+	return cert, nil
+}
+
+// requestCertificate proves control of the domain to the ACME server and has it issue a certificate.
+func (m *Manager) requestCertificate(ctx context.Context, domain string) (*tls.Certificate, error) {
+	var contact []string
+	if m.cfg.email != "" {
+		contact = []string{"mailto:" + m.cfg.email}
+	}
+	if err := m.client.register(ctx, contact); err != nil {
+		return nil, err
+	}
+	pending, orderURL, err := m.client.newOrder(ctx, []string{domain})
+	if err != nil {
+		return nil, err
+	}
+	for _, authzURL := range pending.Authorizations {
+		if err := m.authorize(ctx, authzURL); err != nil {
+			return nil, err
+		}
+	}
+
+	certKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate the certificate key (%w)", err)
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: domain},
+		DNSNames: []string{domain},
+	}, certKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the certificate request (%w)", err)
+	}
+	chain, err := m.client.finalize(ctx, pending, orderURL, csr)
+	if err != nil {
+		return nil, err
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(certKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the certificate key (%w)", err)
+	}
+	contents := append(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), chain...)
+	cert, err := tls.X509KeyPair(contents, contents)
+	if err != nil {
+		return nil, fmt.Errorf("the issued certificate is invalid (%w)", err)
+	}
+	if err := os.WriteFile(m.certificatePath(domain), contents, 0600); err != nil {
+		return nil, fmt.Errorf("failed to cache the certificate (%w)", err)
+	}
+	return &cert, nil
+}
+
+// authorize solves a challenge of the authorization unless it is already valid.
+// The tls-alpn-01 challenge is preferred, and the http-01 challenge is used if it is enabled.
+func (m *Manager) authorize(ctx context.Context, authzURL string) error {
+	authz, err := m.client.authorization(ctx, authzURL)
+	if err != nil {
+		return err
+	}
+	if authz.Status == "valid" {
+		return nil
+	}
+	domain := authz.Identifier.Value
+
+	var selected *challenge
+	for _, challengeType := range []string{challengeTypeTLSALPN, challengeTypeHTTP} {
+		if challengeType == challengeTypeHTTP && !m.cfg.httpChallenge {
+			continue
+		}
+		for index := range authz.Challenges {
+			if authz.Challenges[index].Type == challengeType {
+				selected = &authz.Challenges[index]
+				break
+			}
+		}
+		if selected != nil {
+			break
+		}
+	}
+	if selected == nil {
+		return fmt.Errorf("the ACME server did not offer a supported challenge for %s", domain)
+	}
+
+	keyAuth := m.client.keyAuthorization(selected.Token)
+	cleanup, err := m.prepareChallenge(selected, domain, keyAuth)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if err := m.client.accept(ctx, selected); err != nil {
+		return err
+	}
+	return m.client.waitAuthorization(ctx, authzURL)
+}
+
+// prepareChallenge makes the server answer the challenge and returns a function that stops answering it.
+func (m *Manager) prepareChallenge(chal *challenge, domain string, keyAuth string) (func(), error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	switch chal.Type {
+	case challengeTypeHTTP:
+		m.httpTokens[chal.Token] = keyAuth
+		return func() {
+			m.lock.Lock()
+			delete(m.httpTokens, chal.Token)
+			m.lock.Unlock()
+		}, nil
+	default:
+		cert, err := tlsALPNCertificate(domain, keyAuth)
+		if err != nil {
+			return nil, err
+		}
+		m.alpnCerts[domain] = cert
+		return func() {
+			m.lock.Lock()
+			delete(m.alpnCerts, domain)
+			m.lock.Unlock()
+		}, nil
+	}
+}
+
+// cachedCertificate returns the certificate of the domain from memory, or from the cache directory.
+func (m *Manager) cachedCertificate(domain string) (*tls.Certificate, error) {
+	m.lock.Lock()
+	cert, found := m.certs[domain]
+	m.lock.Unlock()
+	if found {
+		return cert, nil
+	}
+	contents, err := os.ReadFile(m.certificatePath(domain))
+	if err != nil {
+		return nil, err
+	}
+	loaded, err := tls.X509KeyPair(contents, contents)
+	if err != nil {
+		return nil, fmt.Errorf("the cached certificate of %s is invalid (%w)", domain, err)
+	}
+	m.lock.Lock()
+	m.certs[domain] = &loaded
+	m.lock.Unlock()
+	return &loaded, nil
+}
+
+// renewInBackground renews the certificate of the domain without blocking the handshake that noticed it expires soon.
+func (m *Manager) renewInBackground(domain string) {
+	m.lock.Lock()
+	if m.renewing[domain] {
+		m.lock.Unlock()
+		return
+	}
+	m.renewing[domain] = true
+	m.lock.Unlock()
+	go func() {
+		defer func() {
+			m.lock.Lock()
+			delete(m.renewing, domain)
+			m.lock.Unlock()
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+		if err := m.RenewExpiring(ctx); err != nil {
+			logger.Errorf(ctx, "Failed to renew the ACME certificates (%s).", err)
+		}
+	}()
+}
+
+// needsRenewal returns true if the certificate expires within the renewal period.
+func (m *Manager) needsRenewal(cert *tls.Certificate) bool {
+	return time.Until(cert.Leaf.NotAfter) < m.cfg.renewBefore
+}
+
+// allowed returns true if the domain is one of the allowed domains.
+func (m *Manager) allowed(domain string) bool {
+	return slices.Contains(m.domains, domain)
+}
+
+// certificatePath returns the path of the cached certificate of the domain.
+func (m *Manager) certificatePath(domain string) string {
+	return filepath.Join(m.cacheDir, domain+".pem")
+}
+
+// tlsALPNCertificate creates the self-signed certificate that answers a tls-alpn-01 challenge, as defined by RFC 8737.
+func tlsALPNCertificate(domain string, keyAuth string) (*tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate the challenge key (%w)", err)
+	}
+	digest := sha256.Sum256([]byte(keyAuth))
+	extensionValue, err := asn1.Marshal(digest[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the challenge extension (%w)", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:    big.NewInt(1),
+		Subject:         pkix.Name{CommonName: domain},
+		DNSNames:        []string{domain},
+		NotBefore:       time.Now().Add(-time.Hour),
+		NotAfter:        time.Now().Add(24 * time.Hour),
+		ExtraExtensions: []pkix.Extension{{Id: acmeIdentifierOID, Critical: true, Value: extensionValue}},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the challenge certificate (%w)", err)
+	}
+	return &tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
+
+// loadOrCreateAccountKey reads the account key at the path, or generates and writes one if the file does not exist.
+func loadOrCreateAccountKey(path string) (*ecdsa.PrivateKey, error) {
+	contents, err := os.ReadFile(path)
+	if err == nil {
+		block, _ := pem.Decode(contents)
+		if block == nil {
+			return nil, fmt.Errorf("the ACME account key %s is not PEM encoded", path)
+		}
+		key, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the ACME account key (%w)", err)
+		}
+		return key, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read the ACME account key (%w)", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate the ACME account key (%w)", err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the ACME account key (%w)", err)
+	}
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write the ACME account key (%w)", err)
+	}
+	return key, nil
+}
+
+// normalizeDomain lowercases the domain and removes its trailing dot.
+func normalizeDomain(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(domain), ".")
+}
+
+// stripPort removes the port from the host, if it has one.
+func stripPort(host string) string {
+	if index := strings.LastIndex(host, ":"); index != -1 && !strings.HasSuffix(host, "]") {
+		return host[:index]
+	}
+	return host
+}
@@ -0,0 +1,645 @@
+package acme_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/http/acme"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+// fakeJWK is the JWK of an account key sent to the fake ACME server.
+type fakeJWK struct {
+	Crv string `json:"crv"`
+	Kty string `json:"kty"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// fakeACMEServer is an ACME server that validates the challenges by calling the Manager directly.
+type fakeACMEServer struct {
+	t              *testing.T
+	server         *httptest.Server
+	caKey          *ecdsa.PrivateKey
+	caCert         *x509.Certificate
+	challengeTypes []string
+	lifetime       time.Duration
+	manager        atomic.Pointer[acme.Manager]
+	badNonceOnce   atomic.Bool
+	orders         atomic.Int32
+	accounts       atomic.Int32
+
+	lock    sync.Mutex
+	nonces  map[string]bool
+	nextID  int
+	jwk     *fakeJWK
+	domains map[string]string
+	tokens  map[string]string
+	valid   map[string]bool
+	certs   map[string][]byte
+}
+
+// newFakeACMEServer starts a fake ACME server that offers the challenge types.
+func newFakeACMEServer(t *testing.T, challengeTypes ...string) *fakeACMEServer {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "fake-acme-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	assert.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	assert.NoError(t, err)
+
+	fake := &fakeACMEServer{
+		t:              t,
+		caKey:          caKey,
+		caCert:         caCert,
+		challengeTypes: challengeTypes,
+		lifetime:       90 * 24 * time.Hour,
+		nonces:         make(map[string]bool),
+		domains:        make(map[string]string),
+		tokens:         make(map[string]string),
+		valid:          make(map[string]bool),
+		certs:          make(map[string][]byte),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /directory", fake.directory)
+	mux.HandleFunc("HEAD /nonce", fake.nonce)
+	mux.HandleFunc("POST /account", fake.account)
+	mux.HandleFunc("POST /order", fake.order)
+	mux.HandleFunc("POST /authz/{id}", fake.authorization)
+	mux.HandleFunc("POST /challenge/{id}", fake.challenge)
+	mux.HandleFunc("POST /finalize/{id}", fake.finalize)
+	mux.HandleFunc("POST /cert/{id}", fake.certificate)
+	fake.server = httptest.NewServer(mux)
+	t.Cleanup(fake.server.Close)
+	return fake
+}
+
+// directoryURL returns the URL of the directory of the fake server.
+func (f *fakeACMEServer) directoryURL() string {
+	return f.server.URL + "/directory"
+}
+
+// newManager creates a Manager that uses the fake server and registers it for the challenge validations.
+func (f *fakeACMEServer) newManager(t *testing.T, cacheDir string, opts ...acme.Option) *acme.Manager {
+	t.Helper()
+	opts = append([]acme.Option{acme.WithDirectoryURL(f.directoryURL()), acme.WithEmail("admin@example.com")}, opts...)
+	manager, err := acme.NewManager(cacheDir, []string{"example.com", "www.example.com"}, opts...)
+	assert.NoError(t, err)
+	f.manager.Store(manager)
+	return manager
+}
+
+func (f *fakeACMEServer) newNonce() string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.nextID++
+	nonce := fmt.Sprintf("nonce-%d", f.nextID)
+	f.nonces[nonce] = true
+	return nonce
+}
+
+func (f *fakeACMEServer) newID() string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.nextID++
+	return fmt.Sprintf("%d", f.nextID)
+}
+
+func (f *fakeACMEServer) problem(writer http.ResponseWriter, status int, problemType string, detail string) {
+	writer.Header().Set("Replay-Nonce", f.newNonce())
+	writer.Header().Set("Content-Type", "application/problem+json")
+	writer.WriteHeader(status)
+	_ = json.NewEncoder(writer).Encode(map[string]any{"type": problemType, "detail": detail})
+}
+
+func (f *fakeACMEServer) respond(writer http.ResponseWriter, status int, location string, body any) {
+	writer.Header().Set("Replay-Nonce", f.newNonce())
+	if location != "" {
+		writer.Header().Set("Location", location)
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	_ = json.NewEncoder(writer).Encode(body)
+}
+
+// verify checks the signature, the nonce, and the URL of a signed request and decodes its payload.
+// It returns false if it responded with a problem.
+func (f *fakeACMEServer) verify(writer http.ResponseWriter, request *http.Request, payload any) bool {
+	var signed struct {
+		Protected string `json:"protected"`
+		Payload   string `json:"payload"`
+		Signature string `json:"signature"`
+	}
+	if request.Header.Get("Content-Type") != "application/jose+json" {
+		f.problem(writer, http.StatusUnsupportedMediaType, "urn:ietf:params:acme:error:malformed", "bad content type")
+		return false
+	}
+	if err := json.NewDecoder(request.Body).Decode(&signed); err != nil {
+		f.problem(writer, http.StatusBadRequest, "urn:ietf:params:acme:error:malformed", err.Error())
+		return false
+	}
+	headerJSON, err := base64.RawURLEncoding.DecodeString(signed.Protected)
+	assert.NoError(f.t, err)
+	var header struct {
+		Alg   string   `json:"alg"`
+		JWK   *fakeJWK `json:"jwk"`
+		KeyID string   `json:"kid"`
+		Nonce string   `json:"nonce"`
+		URL   string   `json:"url"`
+	}
+	assert.NoError(f.t, json.Unmarshal(headerJSON, &header))
+	assert.Equals(f.t, header.Alg, "ES256")
+	assert.Equals(f.t, header.URL, f.server.URL+request.URL.RequestURI())
+
+	f.lock.Lock()
+	validNonce := f.nonces[header.Nonce]
+	delete(f.nonces, header.Nonce)
+	jwk := header.JWK
+	if jwk == nil {
+		assert.Equals(f.t, header.KeyID, f.server.URL+"/account/1")
+		jwk = f.jwk
+	} else {
+		f.jwk = jwk
+	}
+	f.lock.Unlock()
+	if !validNonce || f.badNonceOnce.Swap(false) {
+		f.problem(writer, http.StatusBadRequest, "urn:ietf:params:acme:error:badNonce", "bad nonce")
+		return false
+	}
+
+	x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+	assert.NoError(f.t, err)
+	y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+	assert.NoError(f.t, err)
+	publicKey := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+	signature, err := base64.RawURLEncoding.DecodeString(signed.Signature)
+	assert.NoError(f.t, err)
+	assert.Equals(f.t, len(signature), 64)
+	digest := sha256.Sum256([]byte(signed.Protected + "." + signed.Payload))
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:])
+	if !ecdsa.Verify(publicKey, digest[:], r, s) {
+		f.problem(writer, http.StatusUnauthorized, "urn:ietf:params:acme:error:unauthorized", "bad signature")
+		return false
+	}
+
+	if payload == nil {
+		assert.Equals(f.t, signed.Payload, "")
+		return true
+	}
+	payloadJSON, err := base64.RawURLEncoding.DecodeString(signed.Payload)
+	assert.NoError(f.t, err)
+	assert.NoError(f.t, json.Unmarshal(payloadJSON, payload))
+	return true
+}
+
+// keyAuthorization computes the key authorization of the token with the JWK of the account.
+func (f *fakeACMEServer) keyAuthorization(token string) string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	encoded, err := json.Marshal(f.jwk)
+	assert.NoError(f.t, err)
+	digest := sha256.Sum256(encoded)
+	return token + "." + base64.RawURLEncoding.EncodeToString(digest[:])
+}
+
+func (f *fakeACMEServer) directory(writer http.ResponseWriter, _ *http.Request) {
+	_ = json.NewEncoder(writer).Encode(map[string]string{
+		"newNonce":   f.server.URL + "/nonce",
+		"newAccount": f.server.URL + "/account",
+		"newOrder":   f.server.URL + "/order",
+	})
+}
+
+func (f *fakeACMEServer) nonce(writer http.ResponseWriter, _ *http.Request) {
+	writer.Header().Set("Replay-Nonce", f.newNonce())
+}
+
+func (f *fakeACMEServer) account(writer http.ResponseWriter, request *http.Request) {
+	var payload struct {
+		TermsOfServiceAgreed bool     `json:"termsOfServiceAgreed"`
+		Contact              []string `json:"contact"`
+	}
+	if !f.verify(writer, request, &payload) {
+		return
+	}
+	assert.True(f.t, payload.TermsOfServiceAgreed)
+	assert.Equals(f.t, payload.Contact, []string{"mailto:admin@example.com"})
+	f.accounts.Add(1)
+	f.respond(writer, http.StatusCreated, f.server.URL+"/account/1", map[string]string{"status": "valid"})
+}
+
+func (f *fakeACMEServer) order(writer http.ResponseWriter, request *http.Request) {
+	var payload struct {
+		Identifiers []struct {
+			Type  string `json:"type"`
+			Value string `json:"value"`
+		} `json:"identifiers"`
+	}
+	if !f.verify(writer, request, &payload) {
+		return
+	}
+	assert.Equals(f.t, len(payload.Identifiers), 1)
+	if payload.Identifiers[0].Value == "www.example.com" {
+		f.problem(writer, http.StatusForbidden, "urn:ietf:params:acme:error:rejectedIdentifier", "www is rejected")
+		return
+	}
+	f.orders.Add(1)
+	id := f.newID()
+	f.lock.Lock()
+	f.domains[id] = payload.Identifiers[0].Value
+	f.tokens[id] = "token-" + id
+	f.lock.Unlock()
+	f.respond(writer, http.StatusCreated, f.server.URL+"/order/"+id, map[string]any{
+		"status":         "pending",
+		"authorizations": []string{f.server.URL + "/authz/" + id},
+		"finalize":       f.server.URL + "/finalize/" + id,
+	})
+}
+
+func (f *fakeACMEServer) authorization(writer http.ResponseWriter, request *http.Request) {
+	if !f.verify(writer, request, nil) {
+		return
+	}
+	id := request.PathValue("id")
+	f.lock.Lock()
+	domain, token, valid := f.domains[id], f.tokens[id], f.valid[id]
+	f.lock.Unlock()
+	status := "pending"
+	if valid {
+		status = "valid"
+	}
+	challenges := make([]map[string]string, 0, len(f.challengeTypes))
+	for _, challengeType := range f.challengeTypes {
+		challenges = append(challenges, map[string]string{
+			"type":   challengeType,
+			"url":    f.server.URL + "/challenge/" + id + "?type=" + challengeType,
+			"token":  token,
+			"status": status,
+		})
+	}
+	f.respond(writer, http.StatusOK, "", map[string]any{
+		"status":     status,
+		"identifier": map[string]string{"type": "dns", "value": domain},
+		"challenges": challenges,
+	})
+}
+
+func (f *fakeACMEServer) challenge(writer http.ResponseWriter, request *http.Request) {
+	var payload struct{}
+	if !f.verify(writer, request, &payload) {
+		return
+	}
+	id := request.PathValue("id")
+	f.lock.Lock()
+	domain, token := f.domains[id], f.tokens[id]
+	f.lock.Unlock()
+	keyAuth := f.keyAuthorization(token)
+	manager := f.manager.Load()
+
+	switch request.URL.Query().Get("type") {
+	case "tls-alpn-01":
+		cert, err := manager.GetCertificate(&tls.ClientHelloInfo{ServerName: domain, SupportedProtos: []string{acme.ALPNProtocol}})
+		assert.NoError(f.t, err)
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		assert.NoError(f.t, err)
+		assert.Equals(f.t, leaf.DNSNames, []string{domain})
+		digest := sha256.Sum256([]byte(keyAuth))
+		expected, err := asn1.Marshal(digest[:])
+		assert.NoError(f.t, err)
+		found := false
+		for _, extension := range leaf.Extensions {
+			if extension.Id.Equal(asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 31}) {
+				assert.True(f.t, extension.Critical)
+				assert.Equals(f.t, extension.Value, expected)
+				found = true
+			}
+		}
+		assert.True(f.t, found)
+	case "http-01":
+		recorder := httptest.NewRecorder()
+		challengeRequest := httptest.NewRequest(http.MethodGet, "http://"+domain+acme.HTTPChallengePath+token, nil)
+		manager.HTTPHandler(nil).ServeHTTP(recorder, challengeRequest)
+		assert.Equals(f.t, recorder.Code, http.StatusOK)
+		assert.Equals(f.t, recorder.Body.String(), keyAuth)
+	}
+
+	f.lock.Lock()
+	f.valid[id] = true
+	f.lock.Unlock()
+	f.respond(writer, http.StatusOK, "", map[string]string{"status": "valid"})
+}
+
+func (f *fakeACMEServer) finalize(writer http.ResponseWriter, request *http.Request) {
+	var payload struct {
+		CSR string `json:"csr"`
+	}
+	if !f.verify(writer, request, &payload) {
+		return
+	}
+	id := request.PathValue("id")
+	csrDER, err := base64.RawURLEncoding.DecodeString(payload.CSR)
+	assert.NoError(f.t, err)
+	csr, err := x509.ParseCertificateRequest(csrDER)
+	assert.NoError(f.t, err)
+	assert.NoError(f.t, csr.CheckSignature())
+	f.lock.Lock()
+	domain, valid := f.domains[id], f.valid[id]
+	f.lock.Unlock()
+	assert.True(f.t, valid)
+	assert.Equals(f.t, csr.DNSNames, []string{domain})
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: domain},
+		DNSNames:     csr.DNSNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(f.lifetime),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, f.caCert, csr.PublicKey, f.caKey)
+	assert.NoError(f.t, err)
+	chain := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	chain = append(chain, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: f.caCert.Raw})...)
+	f.lock.Lock()
+	f.certs[id] = chain
+	f.lock.Unlock()
+	f.respond(writer, http.StatusOK, "", map[string]any{
+		"status":      "valid",
+		"finalize":    f.server.URL + "/finalize/" + id,
+		"certificate": f.server.URL + "/cert/" + id,
+	})
+}
+
+func (f *fakeACMEServer) certificate(writer http.ResponseWriter, request *http.Request) {
+	if !f.verify(writer, request, nil) {
+		return
+	}
+	f.lock.Lock()
+	chain := f.certs[request.PathValue("id")]
+	f.lock.Unlock()
+	writer.Header().Set("Replay-Nonce", f.newNonce())
+	writer.Header().Set("Content-Type", "application/pem-certificate-chain")
+	_, _ = writer.Write(chain)
+}
+
+// servedLeaf returns the leaf of the certificate the manager serves for the server name.
+func servedLeaf(t *testing.T, manager *acme.Manager, serverName string) *x509.Certificate {
+	t.Helper()
+	cert, err := manager.GetCertificate(&tls.ClientHelloInfo{ServerName: serverName})
+	assert.NoError(t, err)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	assert.NoError(t, err)
+	return leaf
+}
+
+func TestManager(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when the options are invalid it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicExact(t, func() {
+			acme.WithHTTPClient(nil)
+		}, "the ACME HTTP client cannot be nil")
+		assert.PanicExact(t, func() {
+			acme.WithRenewBefore(0)
+		}, "the ACME renewal period must be greater than zero")
+	})
+
+	t.Run("when the cache directory is empty it should return an error", func(t *testing.T) {
+		t.Parallel()
+		manager, err := acme.NewManager("", []string{"example.com"})
+		assert.ErrorExact(t, err, "the ACME cache directory cannot be empty")
+		assert.Nil(t, manager)
+	})
+
+	t.Run("when no domains are allowed it should return an error", func(t *testing.T) {
+		t.Parallel()
+		manager, err := acme.NewManager(t.TempDir(), nil)
+		assert.ErrorExact(t, err, "at least one ACME domain must be allowed")
+		assert.Nil(t, manager)
+	})
+
+	t.Run("when the account key in the cache is invalid it should return an error", func(t *testing.T) {
+		t.Parallel()
+		cacheDir := t.TempDir()
+		assert.NoError(t, os.WriteFile(filepath.Join(cacheDir, "account.key"), []byte("invalid"), 0600))
+		manager, err := acme.NewManager(cacheDir, []string{"example.com"})
+		assert.ErrorPart(t, err, "is not PEM encoded")
+		assert.Nil(t, manager)
+	})
+
+	t.Run("when the TLS config is created it should negotiate the tls-alpn-01 protocol", func(t *testing.T) {
+		t.Parallel()
+		manager, err := acme.NewManager(t.TempDir(), []string{"example.com"})
+		assert.NoError(t, err)
+		tlsConfig := manager.TLSConfig()
+		assert.Equals(t, tlsConfig.NextProtos, []string{acme.ALPNProtocol})
+		assert.Equals(t, tlsConfig.MinVersion, uint16(tls.VersionTLS13))
+		assert.NotNil(t, tlsConfig.GetCertificate)
+	})
+
+	t.Run("when the server name is missing or not allowed it should return an error", func(t *testing.T) {
+		t.Parallel()
+		manager, err := acme.NewManager(t.TempDir(), []string{"example.com"})
+		assert.NoError(t, err)
+		_, err = manager.GetCertificate(&tls.ClientHelloInfo{})
+		assert.ErrorExact(t, err, "the client did not send a server name")
+		_, err = manager.GetCertificate(&tls.ClientHelloInfo{ServerName: "other.com"})
+		assert.ErrorExact(t, err, "the server name other.com is not allowed")
+		_, err = manager.GetCertificate(&tls.ClientHelloInfo{ServerName: "example.com", SupportedProtos: []string{acme.ALPNProtocol}})
+		assert.ErrorExact(t, err, "there is no tls-alpn-01 challenge for example.com")
+		_, err = manager.Obtain(context.Background(), "other.com")
+		assert.ErrorExact(t, err, "the domain other.com is not allowed")
+	})
+
+	t.Run("when a client connects it should obtain a certificate with the tls-alpn-01 challenge", func(t *testing.T) {
+		t.Parallel()
+		fake := newFakeACMEServer(t, "http-01", "tls-alpn-01")
+		cacheDir := t.TempDir()
+		manager := fake.newManager(t, cacheDir)
+		leaf := servedLeaf(t, manager, "Example.com.")
+		assert.Equals(t, leaf.DNSNames, []string{"example.com"})
+		assert.Equals(t, leaf.Issuer.CommonName, "fake-acme-ca")
+		assert.Equals(t, fake.orders.Load(), int32(1))
+
+		again := servedLeaf(t, manager, "example.com")
+		assert.Equals(t, again.SerialNumber, leaf.SerialNumber)
+		assert.Equals(t, fake.orders.Load(), int32(1))
+
+		_, err := os.Stat(filepath.Join(cacheDir, "example.com.pem"))
+		assert.NoError(t, err)
+		accountKey, err := os.ReadFile(filepath.Join(cacheDir, "account.key"))
+		assert.NoError(t, err)
+
+		restarted := fake.newManager(t, cacheDir)
+		cached := servedLeaf(t, restarted, "example.com")
+		assert.Equals(t, cached.SerialNumber, leaf.SerialNumber)
+		assert.Equals(t, fake.orders.Load(), int32(1))
+		accountKeyAfterRestart, err := os.ReadFile(filepath.Join(cacheDir, "account.key"))
+		assert.NoError(t, err)
+		assert.Equals(t, accountKeyAfterRestart, accountKey)
+	})
+
+	t.Run("when the http-01 challenge is enabled it should be used if it is the only one offered", func(t *testing.T) {
+		t.Parallel()
+		fake := newFakeACMEServer(t, "http-01")
+		manager := fake.newManager(t, t.TempDir(), acme.WithHTTPChallenge())
+		leaf := servedLeaf(t, manager, "example.com")
+		assert.Equals(t, leaf.DNSNames, []string{"example.com"})
+	})
+
+	t.Run("when no offered challenge is supported it should return an error", func(t *testing.T) {
+		t.Parallel()
+		fake := newFakeACMEServer(t, "http-01", "dns-01")
+		manager := fake.newManager(t, t.TempDir())
+		_, err := manager.GetCertificate(&tls.ClientHelloInfo{ServerName: "example.com"})
+		assert.ErrorExact(t, err, "failed to obtain a certificate for example.com (the ACME server did not offer a supported challenge for example.com)")
+	})
+
+	t.Run("when the server rejects the order it should return the problem", func(t *testing.T) {
+		t.Parallel()
+		fake := newFakeACMEServer(t, "tls-alpn-01")
+		manager := fake.newManager(t, t.TempDir())
+		_, err := manager.Obtain(context.Background(), "www.example.com")
+		assert.ErrorPart(t, err, "urn:ietf:params:acme:error:rejectedIdentifier: www is rejected")
+		var problem *acme.Problem
+		assert.True(t, errors.As(err, &problem))
+		assert.Equals(t, problem.Status, http.StatusForbidden)
+	})
+
+	t.Run("when the server rejects a nonce it should retry the request once", func(t *testing.T) {
+		t.Parallel()
+		fake := newFakeACMEServer(t, "tls-alpn-01")
+		fake.badNonceOnce.Store(true)
+		manager := fake.newManager(t, t.TempDir())
+		_, err := manager.Obtain(context.Background(), "example.com")
+		assert.NoError(t, err)
+		assert.Equals(t, fake.accounts.Load(), int32(1))
+	})
+
+	t.Run("when no certificate expires within the renewal period it should not renew them", func(t *testing.T) {
+		t.Parallel()
+		fake := newFakeACMEServer(t, "tls-alpn-01")
+		fake.lifetime = 48 * time.Hour
+		manager := fake.newManager(t, t.TempDir(), acme.WithRenewBefore(24*time.Hour))
+		first := servedLeaf(t, manager, "example.com")
+		assert.NoError(t, manager.RenewExpiring(context.Background()))
+		assert.Equals(t, fake.orders.Load(), int32(1))
+		assert.Equals(t, servedLeaf(t, manager, "example.com").SerialNumber, first.SerialNumber)
+	})
+
+	t.Run("when RenewExpiring finds a certificate close to expiring it should obtain a new one", func(t *testing.T) {
+		t.Parallel()
+		fake := newFakeACMEServer(t, "tls-alpn-01")
+		fake.lifetime = 2 * time.Hour
+		manager := fake.newManager(t, t.TempDir(), acme.WithRenewBefore(24*time.Hour))
+		first, err := manager.Obtain(context.Background(), "example.com")
+		assert.NoError(t, err)
+		fake.lifetime = 90 * 24 * time.Hour
+		assert.NoError(t, manager.RenewExpiring(context.Background()))
+		renewed, err := manager.GetCertificate(&tls.ClientHelloInfo{ServerName: "example.com"})
+		assert.NoError(t, err)
+		assert.True(t, renewed.Leaf.NotAfter.After(first.Leaf.NotAfter))
+	})
+
+	t.Run("when a client connects over TLS it should be served the obtained certificate", func(t *testing.T) {
+		t.Parallel()
+		fake := newFakeACMEServer(t, "tls-alpn-01")
+		manager := fake.newManager(t, t.TempDir())
+		tlsConfig := manager.TLSConfig()
+		tlsConfig.NextProtos = append(tlsConfig.NextProtos, "http/1.1")
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+			_, _ = writer.Write([]byte("hello"))
+		}))
+		server.TLS = tlsConfig
+		server.StartTLS()
+		t.Cleanup(server.Close)
+
+		roots := x509.NewCertPool()
+		roots.AddCert(fake.caCert)
+		address := strings.TrimPrefix(server.URL, "https://")
+		httpClient := &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{RootCAs: roots, ServerName: "example.com"},
+		}}
+		response, err := httpClient.Get("https://" + address)
+		assert.NoError(t, err)
+		body, err := io.ReadAll(response.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, response.Body.Close())
+		assert.Equals(t, string(body), "hello")
+	})
+}
+
+func TestManagerHTTPHandler(t *testing.T) {
+	t.Parallel()
+
+	manager, err := acme.NewManager(t.TempDir(), []string{"example.com"})
+	assert.NoError(t, err)
+
+	t.Run("when the request is not a challenge and there is no fallback it should redirect to HTTPS", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		manager.HTTPHandler(nil).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://example.com:8080/path?query=1", nil))
+		assert.Equals(t, recorder.Code, http.StatusMovedPermanently)
+		assert.Equals(t, recorder.Header().Get("Location"), "https://example.com/path?query=1")
+	})
+
+	t.Run("when the request is not a GET or HEAD and there is no fallback it should be rejected", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		manager.HTTPHandler(nil).ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "http://example.com/path", nil))
+		assert.Equals(t, recorder.Code, http.StatusBadRequest)
+	})
+
+	t.Run("when the request is not a challenge it should be passed to the fallback", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		fallback := http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+			writer.WriteHeader(http.StatusTeapot)
+		})
+		manager.HTTPHandler(fallback).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://example.com/path", nil))
+		assert.Equals(t, recorder.Code, http.StatusTeapot)
+	})
+
+	t.Run("when the challenge token is unknown it should respond with not found", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		manager.HTTPHandler(nil).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://example.com"+acme.HTTPChallengePath+"unknown", nil))
+		assert.Equals(t, recorder.Code, http.StatusNotFound)
+	})
+}
@@ -4,12 +4,14 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"reflect"
 	"regexp"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
 
 	"github.com/TriangleSide/GoBase/pkg/http/middleware"
+	"github.com/TriangleSide/GoBase/pkg/http/websocket"
 	"github.com/TriangleSide/GoBase/pkg/validation"
 )
 
@@ -19,7 +21,7 @@ const (
 
 // init adds a validator for the Path.
 func init() {
-	isValidCharacters := regexp.MustCompile(`^[a-zA-Z0-9/{}]+$`).MatchString
+	isValidCharacters := regexp.MustCompile(`^[a-zA-Z0-9/{}:.]+$`).MatchString
 
 	errMsgForValidation := func(value any) error {
 		path, ok := value.(string)
@@ -43,6 +45,7 @@ func init() {
 		}
 		parts := strings.Split(path, "/")
 		parameters := map[string]bool{}
+		parameterNames := map[string]bool{}
 		for i := 1; i < len(parts); i++ {
 			part := parts[i]
 			if part == "" {
@@ -53,14 +56,8 @@ func init() {
 			}
 			parameters[part] = true
 			if strings.Contains(part, "{") || strings.Contains(part, "}") {
-				if !strings.HasPrefix(part, "{") || !strings.HasSuffix(part, "}") {
-					return errors.New("path parameters must start with '{' and end with '}'")
-				}
-				if strings.Count(part, "{") != 1 || strings.Count(part, "}") != 1 {
-					return errors.New("path parameters have only one '{' and '}'")
-				}
-				if part == "{}" {
-					return errors.New("path parameters cannot be empty")
+				if err := validatePathParameter(part, i == len(parts)-1, parameterNames); err != nil {
+					return err
 				}
 			}
 		}
@@ -82,33 +79,63 @@ type Method string
 type Path string
 
 // Handler encapsulates middleware and an HTTP handler for request processing.
+//
+// AcceptedContentTypes restricts the media types of the request body. Requests with a body of another type
+// are rejected with an HTTP 415 unsupported media type before the Middleware and Handler run.
+// An empty list means that any content type is accepted.
+//
+// Limits bounds the body size and the time the route can take. It applies to the common middleware of the
+// server as well as the Middleware and Handler. A nil Limits means the route has no limits.
+//
+// Documentation describes the route in the OpenAPI document of the server. It is optional.
 type Handler struct {
-	Middleware []middleware.Middleware
-	Handler    http.HandlerFunc
+	Middleware           []middleware.Middleware
+	Handler              http.HandlerFunc
+	AcceptedContentTypes []string
+	Limits               *middleware.Limits
+	Documentation        *Documentation
+}
+
+// Documentation describes a route for the generation of an OpenAPI document.
+//
+// Parameters is the type of the request parameter struct that the route decodes with the parameters package, like
+// reflect.TypeFor[CreateUserParams](). Its query, header, path, and JSON body fields are documented from their
+// struct tags, along with the constraints of their validate tags. Responses maps the status codes of the route
+// to the type of their JSON body. A nil type means that the response has no body.
+type Documentation struct {
+	Summary     string
+	Description string
+	Tags        []string
+	Parameters  reflect.Type
+	Responses   map[int]reflect.Type
 }
 
 // HTTPAPIBuilder is used in the HTTPEndpointHandler's visitor to set routes to handlers.
 type HTTPAPIBuilder struct {
 	handlers map[Path]map[Method]*Handler
+	opts     *builderOptions
 }
 
 // NewHTTPAPIBuilder allocates and sets default values in an HTTPAPIBuilder.
-func NewHTTPAPIBuilder() *HTTPAPIBuilder {
+func NewHTTPAPIBuilder(opts ...Option) *HTTPAPIBuilder {
+	builderOpts := &builderOptions{
+		methodNotAllowed: true,
+		automaticOptions: true,
+		automaticHead:    true,
+	}
+	for _, opt := range opts {
+		opt(builderOpts)
+	}
 	return &HTTPAPIBuilder{
 		handlers: make(map[Path]map[Method]*Handler),
+		opts:     builderOpts,
 	}
 }
 
 // MustRegister assigns a Path and Method to a Handler. This function does validation to ensure
 // duplicates are not registered. If the path and method is already registered, this function panics.
 func (builder *HTTPAPIBuilder) MustRegister(path Path, method Method, handler *Handler) {
-	if err := validation.Var(string(path), pathValidationTag); err != nil {
-		panic(fmt.Sprintf("The API path '%s' is not correctly formatted (%s).", path, err.Error()))
-	}
-
-	if err := validation.Var(string(method), "oneof=GET POST HEAD PUT PATCH DELETE CONNECT OPTIONS TRACE"); err != nil {
-		panic(fmt.Sprintf("HTTP method '%s' is invalid (%s).", method, err.Error()))
-	}
+	builder.mustValidateRoute(path, method)
 
 	// The handler can be nil in cases like cors requests. The Go HTTP server needs the route
 	// to exist to handle the request, but there is no handler needed for it.
@@ -128,11 +155,82 @@ func (builder *HTTPAPIBuilder) MustRegister(path Path, method Method, handler *H
 		builder.handlers[path] = methodToHandlerMap
 	}
 
-	if _, methodAlreadyRegistered := methodToHandlerMap[method]; methodAlreadyRegistered {
+	methodToHandlerMap[method] = handler
+}
+
+// MustRegisterMany assigns every combination of the paths and methods to the same Handler.
+// This is useful for a handler that serves multiple methods, or a path with legacy aliases.
+// All the routes are validated before any of them are registered, so if a route is invalid
+// or is already registered, this function panics and the builder is left unchanged.
+func (builder *HTTPAPIBuilder) MustRegisterMany(paths []Path, methods []Method, handler *Handler) {
+	if len(paths) == 0 || len(methods) == 0 {
+		panic("at least one path and one method must be provided")
+	}
+
+	seen := make(map[Path]map[Method]bool, len(paths))
+	for _, path := range paths {
+		if _, pathSeen := seen[path]; !pathSeen {
+			seen[path] = make(map[Method]bool, len(methods))
+		}
+		for _, method := range methods {
+			builder.mustValidateRoute(path, method)
+			if seen[path][method] {
+				panic(fmt.Sprintf("method '%s' is provided more than once for path '%s'", method, path))
+			}
+			seen[path][method] = true
+		}
+	}
+
+	if handler == nil {
+		handler = &Handler{}
+	}
+	for _, path := range paths {
+		for _, method := range methods {
+			builder.MustRegister(path, method, handler)
+		}
+	}
+}
+
+// WebSocketHandler encapsulates middleware and a handler for WebSocket connections.
+//
+// The Middleware runs on the opening handshake request, before the connection is upgraded, so it can
+// authenticate or reject the connection. The Options configure the connection, like its keepalive.
+type WebSocketHandler struct {
+	Middleware []middleware.Middleware
+	Handler    func(conn *websocket.Conn, request *http.Request)
+	Options    []websocket.Option
+}
+
+// MustRegisterWebSocket assigns a Path to a WebSocketHandler. The opening handshake is a GET request, so this
+// registers the GET method of the path. If the path is invalid or already has a GET handler, this function panics.
+func (builder *HTTPAPIBuilder) MustRegisterWebSocket(path Path, handler *WebSocketHandler) {
+	if handler == nil || handler.Handler == nil {
+		panic("the websocket handler cannot be nil")
+	}
+	builder.MustRegister(path, http.MethodGet, &Handler{
+		Middleware: handler.Middleware,
+		Handler:    websocket.Handler(handler.Handler, handler.Options...),
+	})
+}
+
+// mustValidateRoute panics if the path or method is not correctly formatted, or if the route is already registered.
+func (builder *HTTPAPIBuilder) mustValidateRoute(path Path, method Method) {
+	mustValidatePath(path)
+
+	if err := validation.Var(string(method), "oneof=GET POST HEAD PUT PATCH DELETE CONNECT OPTIONS TRACE"); err != nil {
+		panic(fmt.Sprintf("HTTP method '%s' is invalid (%s).", method, err.Error()))
+	}
+
+	if _, methodAlreadyRegistered := builder.handlers[path][method]; methodAlreadyRegistered {
 		panic(fmt.Sprintf("method '%s' already registered for path '%s'", method, path))
 	}
+}
 
-	methodToHandlerMap[method] = handler
+// mustValidatePath panics if the path is not correctly formatted.
+func mustValidatePath(path Path) {
+	if err := validation.Var(string(path), pathValidationTag); err != nil {
+		panic(fmt.Sprintf("The API path '%s' is not correctly formatted (%s).", path, err.Error()))
+	}
 }
 
 // Handlers returns a map of Path to Method to Handler.
@@ -6,6 +6,8 @@ import (
 	"testing"
 
 	"github.com/TriangleSide/GoBase/pkg/http/api"
+	"github.com/TriangleSide/GoBase/pkg/http/middleware"
+	"github.com/TriangleSide/GoBase/pkg/http/websocket"
 	"github.com/TriangleSide/GoBase/pkg/test/assert"
 	"github.com/TriangleSide/GoBase/pkg/validation"
 )
@@ -219,6 +221,94 @@ func TestHTTPApi(t *testing.T) {
 		assert.Equals(t, getRecorder2.Code, http.StatusAccepted)
 	})
 
+	t.Run("when one handler is registered for many methods and paths it should route every combination to it", func(t *testing.T) {
+		t.Parallel()
+		builder := api.NewHTTPAPIBuilder()
+		handler := &api.Handler{
+			Handler: func(writer http.ResponseWriter, request *http.Request) {
+				writer.WriteHeader(http.StatusAccepted)
+			},
+		}
+		builder.MustRegisterMany([]api.Path{"/items", "/legacy/items"}, []api.Method{http.MethodPost, http.MethodPut}, handler)
+
+		handlers := builder.Handlers()
+		assert.Equals(t, len(handlers), 2)
+		for _, path := range []api.Path{"/items", "/legacy/items"} {
+			assert.Equals(t, len(handlers[path]), 2)
+			for _, method := range []api.Method{http.MethodPost, http.MethodPut} {
+				recorder := httptest.NewRecorder()
+				handlers[path][method].Handler(recorder, httptest.NewRequest(string(method), string(path), nil))
+				assert.Equals(t, recorder.Code, http.StatusAccepted)
+			}
+		}
+	})
+
+	t.Run("when a websocket handler is registered it should register the GET method of the path", func(t *testing.T) {
+		t.Parallel()
+		builder := api.NewHTTPAPIBuilder()
+		handshakeMiddleware := func(next http.HandlerFunc) http.HandlerFunc {
+			return next
+		}
+		builder.MustRegisterWebSocket("/events", &api.WebSocketHandler{
+			Middleware: []middleware.Middleware{handshakeMiddleware},
+			Handler:    func(conn *websocket.Conn, request *http.Request) {},
+		})
+		handlers := builder.Handlers()
+		assert.Equals(t, len(handlers["/events"]), 1)
+		registered := handlers["/events"][http.MethodGet]
+		assert.Equals(t, len(registered.Middleware), 1)
+		recorder := httptest.NewRecorder()
+		registered.Handler(recorder, httptest.NewRequest(http.MethodGet, "/events", nil))
+		assert.Equals(t, recorder.Code, http.StatusBadRequest)
+		assert.PanicExact(t, func() {
+			builder.MustRegisterWebSocket("/events", &api.WebSocketHandler{
+				Handler: func(conn *websocket.Conn, request *http.Request) {},
+			})
+		}, "method 'GET' already registered for path '/events'")
+	})
+
+	t.Run("when a nil websocket handler is registered it should panic", func(t *testing.T) {
+		t.Parallel()
+		builder := api.NewHTTPAPIBuilder()
+		assert.PanicExact(t, func() {
+			builder.MustRegisterWebSocket("/events", nil)
+		}, "the websocket handler cannot be nil")
+		assert.PanicExact(t, func() {
+			builder.MustRegisterWebSocket("/events", &api.WebSocketHandler{})
+		}, "the websocket handler cannot be nil")
+	})
+
+	t.Run("when many routes are registered without paths or methods it should panic", func(t *testing.T) {
+		t.Parallel()
+		builder := api.NewHTTPAPIBuilder()
+		assert.PanicExact(t, func() {
+			builder.MustRegisterMany(nil, []api.Method{http.MethodGet}, nil)
+		}, "at least one path and one method must be provided")
+		assert.PanicExact(t, func() {
+			builder.MustRegisterMany([]api.Path{"/"}, nil, nil)
+		}, "at least one path and one method must be provided")
+	})
+
+	t.Run("when many routes are registered with a duplicate method it should panic", func(t *testing.T) {
+		t.Parallel()
+		builder := api.NewHTTPAPIBuilder()
+		assert.PanicExact(t, func() {
+			builder.MustRegisterMany([]api.Path{"/a"}, []api.Method{http.MethodGet, http.MethodGet}, nil)
+		}, "method 'GET' is provided more than once for path '/a'")
+		assert.Equals(t, len(builder.Handlers()), 0)
+	})
+
+	t.Run("when many routes conflict with a registered route it should panic without registering any of them", func(t *testing.T) {
+		t.Parallel()
+		builder := api.NewHTTPAPIBuilder()
+		builder.MustRegister("/b", http.MethodPut, nil)
+		assert.PanicExact(t, func() {
+			builder.MustRegisterMany([]api.Path{"/a", "/b"}, []api.Method{http.MethodPost, http.MethodPut}, nil)
+		}, "method 'PUT' already registered for path '/b'")
+		assert.Equals(t, len(builder.Handlers()), 1)
+		assert.Equals(t, len(builder.Handlers()["/b"]), 1)
+	})
+
 	t.Run("cases for path validation", func(t *testing.T) {
 		t.Parallel()
 
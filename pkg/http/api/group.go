@@ -0,0 +1,99 @@
+package api
+
+import (
+	"slices"
+
+	"github.com/TriangleSide/GoBase/pkg/http/middleware"
+)
+
+// Group registers routes under a common path prefix with shared middleware.
+//
+// The paths registered with a Group are relative to its prefix, so "/users" in the group "/api/v1" is
+// registered as "/api/v1/users", and "/" is registered as the prefix itself. The middleware of the group
+// runs before the middleware of the handlers, and the middleware of a parent group runs before the
+// middleware of its nested groups.
+type Group struct {
+	builder    *HTTPAPIBuilder
+	prefix     Path
+	middleware []middleware.Middleware
+}
+
+// Group returns a Group that registers routes in the builder under the prefix with the middleware.
+// If the prefix is not correctly formatted, this function panics.
+func (builder *HTTPAPIBuilder) Group(prefix Path, mw ...middleware.Middleware) *Group {
+	mustValidatePath(prefix)
+	return &Group{
+		builder:    builder,
+		prefix:     prefix,
+		middleware: slices.Clone(mw),
+	}
+}
+
+// Group returns a nested Group whose prefix is relative to this group, and whose middleware runs after
+// the middleware of this group. If the prefix is not correctly formatted, this function panics.
+func (group *Group) Group(prefix Path, mw ...middleware.Middleware) *Group {
+	return &Group{
+		builder:    group.builder,
+		prefix:     group.fullPath(prefix),
+		middleware: slices.Concat(group.middleware, mw),
+	}
+}
+
+// Prefix returns the path prefix of the group, including the prefixes of its parent groups.
+func (group *Group) Prefix() Path {
+	return group.prefix
+}
+
+// MustRegister assigns a Path relative to the group and a Method to a Handler. The Handler is copied
+// so the middleware of the group can be added without modifying it. Like HTTPAPIBuilder.MustRegister,
+// this function panics if the route is invalid or already registered.
+func (group *Group) MustRegister(path Path, method Method, handler *Handler) {
+	group.builder.MustRegister(group.fullPath(path), method, group.withMiddleware(handler))
+}
+
+// MustRegisterMany assigns every combination of the paths relative to the group and the methods to the
+// same Handler. Like HTTPAPIBuilder.MustRegisterMany, this function panics without registering any route
+// if a route is invalid or already registered.
+func (group *Group) MustRegisterMany(paths []Path, methods []Method, handler *Handler) {
+	fullPaths := make([]Path, 0, len(paths))
+	for _, path := range paths {
+		fullPaths = append(fullPaths, group.fullPath(path))
+	}
+	group.builder.MustRegisterMany(fullPaths, methods, group.withMiddleware(handler))
+}
+
+// MustRegisterWebSocket assigns a Path relative to the group to a WebSocketHandler.
+// The middleware of the group runs on the opening handshake request.
+func (group *Group) MustRegisterWebSocket(path Path, handler *WebSocketHandler) {
+	if handler == nil || handler.Handler == nil {
+		panic("the websocket handler cannot be nil")
+	}
+	grouped := *handler
+	grouped.Middleware = slices.Concat(group.middleware, handler.Middleware)
+	group.builder.MustRegisterWebSocket(group.fullPath(path), &grouped)
+}
+
+// fullPath returns the path with the prefix of the group. The path is validated on its own first,
+// so a path like "users" is not joined into "/api/v1users".
+func (group *Group) fullPath(path Path) Path {
+	mustValidatePath(path)
+	if path == "/" {
+		return group.prefix
+	}
+	if group.prefix == "/" {
+		return path
+	}
+	return group.prefix + path
+}
+
+// withMiddleware returns a copy of the handler with the middleware of the group before its own.
+func (group *Group) withMiddleware(handler *Handler) *Handler {
+	if handler == nil {
+		handler = &Handler{}
+	}
+	grouped := *handler
+	if len(group.middleware) != 0 {
+		grouped.Middleware = slices.Concat(group.middleware, handler.Middleware)
+	}
+	return &grouped
+}
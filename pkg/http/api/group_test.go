@@ -0,0 +1,162 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/http/api"
+	"github.com/TriangleSide/GoBase/pkg/http/middleware"
+	"github.com/TriangleSide/GoBase/pkg/http/websocket"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+// recordingMiddleware appends its name to the calls before calling the next handler.
+func recordingMiddleware(calls *[]string, name string) middleware.Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(writer http.ResponseWriter, request *http.Request) {
+			*calls = append(*calls, name)
+			next(writer, request)
+		}
+	}
+}
+
+// invoke runs the middleware chain and handler registered for the path and method.
+func invoke(t *testing.T, builder *api.HTTPAPIBuilder, path api.Path, method api.Method) {
+	t.Helper()
+	handler, found := builder.Handlers()[path][method]
+	assert.True(t, found)
+	chain := middleware.CreateChain(handler.Middleware, handler.Handler)
+	chain(httptest.NewRecorder(), httptest.NewRequest(string(method), string(path), nil))
+}
+
+func TestGroup(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when a route is registered in a group it should be prefixed and run the group middleware first", func(t *testing.T) {
+		t.Parallel()
+		var calls []string
+		builder := api.NewHTTPAPIBuilder()
+		group := builder.Group("/api/v1", recordingMiddleware(&calls, "group"))
+		assert.Equals(t, group.Prefix(), api.Path("/api/v1"))
+		handler := &api.Handler{
+			Middleware: []middleware.Middleware{recordingMiddleware(&calls, "handler")},
+			Handler: func(http.ResponseWriter, *http.Request) {
+				calls = append(calls, "final")
+			},
+		}
+		group.MustRegister("/users/{id}", http.MethodGet, handler)
+
+		invoke(t, builder, "/api/v1/users/{id}", http.MethodGet)
+		assert.Equals(t, calls, []string{"group", "handler", "final"})
+		assert.Equals(t, len(handler.Middleware), 1)
+	})
+
+	t.Run("when groups are nested it should join the prefixes and run the parent middleware first", func(t *testing.T) {
+		t.Parallel()
+		var calls []string
+		builder := api.NewHTTPAPIBuilder()
+		parent := builder.Group("/api", recordingMiddleware(&calls, "parent"))
+		child := parent.Group("/v2", recordingMiddleware(&calls, "child"))
+		sibling := parent.Group("/v3")
+		assert.Equals(t, child.Prefix(), api.Path("/api/v2"))
+		child.MustRegister("/items", http.MethodPost, &api.Handler{
+			Handler: func(http.ResponseWriter, *http.Request) {
+				calls = append(calls, "final")
+			},
+		})
+		sibling.MustRegister("/items", http.MethodPost, nil)
+
+		invoke(t, builder, "/api/v2/items", http.MethodPost)
+		assert.Equals(t, calls, []string{"parent", "child", "final"})
+
+		calls = nil
+		invoke(t, builder, "/api/v3/items", http.MethodPost)
+		assert.Equals(t, calls, []string{"parent"})
+	})
+
+	t.Run("when the root path is registered in a group it should register the prefix", func(t *testing.T) {
+		t.Parallel()
+		builder := api.NewHTTPAPIBuilder()
+		builder.Group("/status").MustRegister("/", http.MethodGet, nil)
+		builder.Group("/").MustRegister("/health", http.MethodGet, nil)
+		_, found := builder.Handlers()["/status"][http.MethodGet]
+		assert.True(t, found)
+		_, found = builder.Handlers()["/health"][http.MethodGet]
+		assert.True(t, found)
+	})
+
+	t.Run("when a group without middleware registers a handler it should not add middleware", func(t *testing.T) {
+		t.Parallel()
+		builder := api.NewHTTPAPIBuilder()
+		builder.Group("/api").MustRegister("/", http.MethodGet, nil)
+		handler := builder.Handlers()["/api"][http.MethodGet]
+		assert.Nil(t, handler.Middleware)
+		recorder := httptest.NewRecorder()
+		handler.Handler(recorder, httptest.NewRequest(http.MethodGet, "/api", nil))
+		assert.Equals(t, recorder.Code, http.StatusNotImplemented)
+	})
+
+	t.Run("when many routes are registered in a group they should all be prefixed", func(t *testing.T) {
+		t.Parallel()
+		var calls []string
+		builder := api.NewHTTPAPIBuilder()
+		builder.Group("/api", recordingMiddleware(&calls, "group")).MustRegisterMany(
+			[]api.Path{"/a", "/b"}, []api.Method{http.MethodGet, http.MethodPut}, nil)
+		assert.Equals(t, len(builder.Handlers()), 2)
+		for _, path := range []api.Path{"/api/a", "/api/b"} {
+			for _, method := range []api.Method{http.MethodGet, http.MethodPut} {
+				invoke(t, builder, path, method)
+			}
+		}
+		assert.Equals(t, len(calls), 4)
+	})
+
+	t.Run("when a websocket handler is registered in a group it should run the group middleware on the handshake", func(t *testing.T) {
+		t.Parallel()
+		var calls []string
+		builder := api.NewHTTPAPIBuilder()
+		builder.Group("/ws", recordingMiddleware(&calls, "group")).MustRegisterWebSocket("/events", &api.WebSocketHandler{
+			Middleware: []middleware.Middleware{recordingMiddleware(&calls, "handler")},
+			Handler:    func(*websocket.Conn, *http.Request) {},
+		})
+		invoke(t, builder, "/ws/events", http.MethodGet)
+		assert.Equals(t, calls, []string{"group", "handler"})
+
+		assert.PanicExact(t, func() {
+			builder.Group("/ws").MustRegisterWebSocket("/other", nil)
+		}, "the websocket handler cannot be nil")
+	})
+
+	t.Run("when the prefix or a relative path is invalid it should panic", func(t *testing.T) {
+		t.Parallel()
+		builder := api.NewHTTPAPIBuilder()
+		assert.PanicPart(t, func() {
+			builder.Group("api")
+		}, "path must start with '/'")
+		assert.PanicPart(t, func() {
+			builder.Group("/api").Group("/v1/")
+		}, "path cannot end with '/'")
+		assert.PanicPart(t, func() {
+			builder.Group("/api").MustRegister("users", http.MethodGet, nil)
+		}, "The API path 'users' is not correctly formatted")
+		assert.Equals(t, len(builder.Handlers()), 0)
+	})
+
+	t.Run("when a route is registered twice through groups it should panic", func(t *testing.T) {
+		t.Parallel()
+		builder := api.NewHTTPAPIBuilder()
+		builder.MustRegister("/api/users", http.MethodGet, nil)
+		assert.PanicExact(t, func() {
+			builder.Group("/api").MustRegister("/users", http.MethodGet, nil)
+		}, "method 'GET' already registered for path '/api/users'")
+	})
+
+	t.Run("when a path parameter is repeated in the prefix it should panic", func(t *testing.T) {
+		t.Parallel()
+		builder := api.NewHTTPAPIBuilder()
+		assert.PanicPart(t, func() {
+			builder.Group("/{id}").MustRegister("/{id}", http.MethodGet, nil)
+		}, "path part must be unique")
+	})
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+)
+
+// builderOptions is configured by the caller with the Option functions.
+type builderOptions struct {
+	methodNotAllowed bool
+	automaticOptions bool
+	automaticHead    bool
+}
+
+// Option is used to configure the HTTPAPIBuilder.
+type Option func(builderOpts *builderOptions)
+
+// WithoutMethodNotAllowed makes the FallbackHandler respond with an HTTP 404 not found, instead of an
+// HTTP 405 method not allowed, when a path is registered but the method of the request is not.
+func WithoutMethodNotAllowed() Option {
+	return func(builderOpts *builderOptions) {
+		builderOpts.methodNotAllowed = false
+	}
+}
+
+// WithoutAutomaticOptions stops the FallbackHandler from answering OPTIONS requests with the allowed methods
+// of the paths that have no OPTIONS handler. The requests are then treated like any other unregistered method.
+func WithoutAutomaticOptions() Option {
+	return func(builderOpts *builderOptions) {
+		builderOpts.automaticOptions = false
+	}
+}
+
+// WithoutAutomaticHead stops HEAD requests from being served by the GET handler of paths without a HEAD handler.
+func WithoutAutomaticHead() Option {
+	return func(builderOpts *builderOptions) {
+		builderOpts.automaticHead = false
+	}
+}
+
+// AllowedMethods returns the sorted methods that the path responds to. Along with the registered methods, these
+// include HEAD if the path has a GET handler and HEAD is automatic, and OPTIONS if OPTIONS is automatic.
+// A path that is not registered has no allowed methods.
+func (builder *HTTPAPIBuilder) AllowedMethods(path Path) []Method {
+	methodToHandlerMap, found := builder.handlers[path]
+	if !found {
+		return []Method{}
+	}
+	allowed := make([]Method, 0, len(methodToHandlerMap)+2)
+	for method := range methodToHandlerMap {
+		allowed = append(allowed, method)
+	}
+	if _, hasGet := methodToHandlerMap[http.MethodGet]; hasGet && builder.opts.automaticHead {
+		allowed = append(allowed, http.MethodHead)
+	}
+	if builder.opts.automaticOptions {
+		allowed = append(allowed, http.MethodOptions)
+	}
+	slices.Sort(allowed)
+	return slices.Compact(allowed)
+}
+
+// DerivesHead returns true if the HEAD requests of a path with a GET handler, but no HEAD handler, are served
+// by the GET handler. The body written by the GET handler is discarded by the Go HTTP server.
+func (builder *HTTPAPIBuilder) DerivesHead() bool {
+	return builder.opts.automaticHead
+}
+
+// FallbackHandler responds to the requests that have no handler for their path and method. If the path of the
+// request matches registered paths, OPTIONS requests are answered with an HTTP 204 no content, and other methods
+// with an HTTP 405 method not allowed, along with an Allow header of the methods of the paths. Otherwise, or if
+// these responses are disabled by the options of the builder, it responds with an HTTP 404 not found.
+func (builder *HTTPAPIBuilder) FallbackHandler() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		allowed := make([]Method, 0)
+		for path := range builder.handlers {
+			if path.Matches(request) {
+				allowed = append(allowed, builder.AllowedMethods(path)...)
+			}
+		}
+		if len(allowed) == 0 {
+			http.NotFound(writer, request)
+			return
+		}
+		slices.Sort(allowed)
+		allowed = slices.Compact(allowed)
+
+		allowedNames := make([]string, 0, len(allowed))
+		for _, method := range allowed {
+			allowedNames = append(allowedNames, string(method))
+		}
+		allowHeader := strings.Join(allowedNames, ", ")
+
+		if request.Method == http.MethodOptions && builder.opts.automaticOptions {
+			writer.Header().Set(headers.Allow, allowHeader)
+			writer.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if !builder.opts.methodNotAllowed {
+			http.NotFound(writer, request)
+			return
+		}
+		writer.Header().Set(headers.Allow, allowHeader)
+		http.Error(writer, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,93 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/http/api"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestMethods(t *testing.T) {
+	t.Parallel()
+
+	// serveFallback returns the response of the fallback handler of the builder to the request.
+	serveFallback := func(builder *api.HTTPAPIBuilder, method string, target string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		builder.FallbackHandler()(recorder, httptest.NewRequest(method, target, nil))
+		return recorder
+	}
+
+	t.Run("when a path is registered it should allow its methods with automatic HEAD and OPTIONS", func(t *testing.T) {
+		t.Parallel()
+		builder := api.NewHTTPAPIBuilder()
+		builder.MustRegisterMany([]api.Path{"/users"}, []api.Method{http.MethodPost, http.MethodGet}, nil)
+		builder.MustRegister("/items", http.MethodPut, nil)
+		assert.Equals(t, builder.AllowedMethods("/users"), []api.Method{http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPost})
+		assert.Equals(t, builder.AllowedMethods("/items"), []api.Method{http.MethodOptions, http.MethodPut})
+		assert.Equals(t, builder.AllowedMethods("/missing"), []api.Method{})
+		assert.True(t, builder.DerivesHead())
+	})
+
+	t.Run("when the automatic methods are disabled it should only allow the registered methods", func(t *testing.T) {
+		t.Parallel()
+		builder := api.NewHTTPAPIBuilder(api.WithoutAutomaticHead(), api.WithoutAutomaticOptions())
+		builder.MustRegister("/users", http.MethodGet, nil)
+		assert.Equals(t, builder.AllowedMethods("/users"), []api.Method{http.MethodGet})
+		assert.False(t, builder.DerivesHead())
+	})
+
+	t.Run("when the method of a registered path is not allowed it should respond with method not allowed", func(t *testing.T) {
+		t.Parallel()
+		builder := api.NewHTTPAPIBuilder()
+		builder.MustRegister("/users/{id:int}", http.MethodGet, nil)
+		builder.MustRegister("/users/{name:alpha}", http.MethodDelete, nil)
+		recorder := serveFallback(builder, http.MethodPost, "/users/42")
+		assert.Equals(t, recorder.Code, http.StatusMethodNotAllowed)
+		assert.Equals(t, recorder.Header().Get(headers.Allow), "GET, HEAD, OPTIONS")
+		recorder = serveFallback(builder, http.MethodPost, "/users/abc")
+		assert.Equals(t, recorder.Code, http.StatusMethodNotAllowed)
+		assert.Equals(t, recorder.Header().Get(headers.Allow), "DELETE, OPTIONS")
+	})
+
+	t.Run("when an OPTIONS request has no handler it should respond with the allowed methods", func(t *testing.T) {
+		t.Parallel()
+		builder := api.NewHTTPAPIBuilder()
+		builder.MustRegisterMany([]api.Path{"/users"}, []api.Method{http.MethodGet, http.MethodPatch}, nil)
+		recorder := serveFallback(builder, http.MethodOptions, "/users")
+		assert.Equals(t, recorder.Code, http.StatusNoContent)
+		assert.Equals(t, recorder.Header().Get(headers.Allow), "GET, HEAD, OPTIONS, PATCH")
+	})
+
+	t.Run("when the automatic OPTIONS is disabled it should respond to OPTIONS with method not allowed", func(t *testing.T) {
+		t.Parallel()
+		builder := api.NewHTTPAPIBuilder(api.WithoutAutomaticOptions())
+		builder.MustRegister("/users", http.MethodGet, nil)
+		recorder := serveFallback(builder, http.MethodOptions, "/users")
+		assert.Equals(t, recorder.Code, http.StatusMethodNotAllowed)
+		assert.Equals(t, recorder.Header().Get(headers.Allow), "GET, HEAD")
+	})
+
+	t.Run("when method not allowed is disabled it should respond with not found", func(t *testing.T) {
+		t.Parallel()
+		builder := api.NewHTTPAPIBuilder(api.WithoutMethodNotAllowed())
+		builder.MustRegister("/users", http.MethodGet, nil)
+		recorder := serveFallback(builder, http.MethodPost, "/users")
+		assert.Equals(t, recorder.Code, http.StatusNotFound)
+		assert.Equals(t, recorder.Header().Get(headers.Allow), "")
+		recorder = serveFallback(builder, http.MethodOptions, "/users")
+		assert.Equals(t, recorder.Code, http.StatusNoContent)
+	})
+
+	t.Run("when no registered path matches it should respond with not found", func(t *testing.T) {
+		t.Parallel()
+		builder := api.NewHTTPAPIBuilder()
+		builder.MustRegister("/", http.MethodGet, nil)
+		builder.MustRegister("/users/{id:int}", http.MethodGet, nil)
+		assert.Equals(t, serveFallback(builder, http.MethodPost, "/missing").Code, http.StatusNotFound)
+		assert.Equals(t, serveFallback(builder, http.MethodPost, "/users/abc").Code, http.StatusNotFound)
+		assert.Equals(t, serveFallback(builder, http.MethodPost, "/").Code, http.StatusMethodNotAllowed)
+	})
+}
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	// wildcardSuffix marks a path parameter that matches the rest of the path, like {path...}.
+	wildcardSuffix = "..."
+
+	// patternSeparator separates the name of a path parameter from its pattern, like {id:int}.
+	patternSeparator = ":"
+)
+
+var (
+	// pathPatterns maps the names of the patterns that path parameters can be restricted to, to their matchers.
+	pathPatterns = map[string]func(value string) bool{
+		"int":   regexp.MustCompile(`^-?[0-9]+$`).MatchString,
+		"uint":  regexp.MustCompile(`^[0-9]+$`).MatchString,
+		"alpha": regexp.MustCompile(`^[a-zA-Z]+$`).MatchString,
+		"alnum": regexp.MustCompile(`^[a-zA-Z0-9]+$`).MatchString,
+		"hex":   regexp.MustCompile(`^[a-fA-F0-9]+$`).MatchString,
+		"uuid":  regexp.MustCompile(`^[a-fA-F0-9]{8}-[a-fA-F0-9]{4}-[a-fA-F0-9]{4}-[a-fA-F0-9]{4}-[a-fA-F0-9]{12}$`).MatchString,
+	}
+
+	// isAlphanumeric matches the names of path parameters and path patterns.
+	isAlphanumeric = regexp.MustCompile(`^[a-zA-Z0-9]+$`).MatchString
+)
+
+// RegisterPathPattern registers a pattern that path parameters can be restricted to, like {id:name}.
+// The patterns int, uint, alpha, alnum, hex, and uuid are registered by default. Patterns must be
+// registered before the routes that use them, and not concurrently with requests.
+// If the name is invalid or already registered, a panic occurs.
+func RegisterPathPattern(name string, matches func(value string) bool) {
+	if !isAlphanumeric(name) {
+		panic(fmt.Sprintf("the path pattern name '%s' must only contain letters and digits", name))
+	}
+	if matches == nil {
+		panic("the path pattern matcher cannot be nil")
+	}
+	if _, found := pathPatterns[name]; found {
+		panic(fmt.Sprintf("the path pattern '%s' is already registered", name))
+	}
+	pathPatterns[name] = matches
+}
+
+// PathSegment is a part of a Path between slashes.
+//
+// A literal segment only has its Literal set. A parameter segment has the Parameter name, which is the
+// key of its value in http.Request.PathValue. Its Pattern restricts the values it matches, and a Wildcard
+// parameter matches the rest of the path, including slashes. It is always the last segment.
+type PathSegment struct {
+	Literal   string
+	Parameter string
+	Pattern   string
+	Wildcard  bool
+}
+
+// Segments returns the segments of the path. The root path has no segments.
+// The path is expected to be correctly formatted, as it is when it is registered.
+func (path Path) Segments() []PathSegment {
+	trimmed := strings.TrimPrefix(string(path), "/")
+	if trimmed == "" {
+		return []PathSegment{}
+	}
+	parts := strings.Split(trimmed, "/")
+	segments := make([]PathSegment, 0, len(parts))
+	for _, part := range parts {
+		if !strings.HasPrefix(part, "{") || !strings.HasSuffix(part, "}") {
+			segments = append(segments, PathSegment{Literal: part})
+			continue
+		}
+		inner := part[1 : len(part)-1]
+		segment := PathSegment{}
+		if name, found := strings.CutSuffix(inner, wildcardSuffix); found {
+			segment.Parameter = name
+			segment.Wildcard = true
+		} else if name, pattern, found := strings.Cut(inner, patternSeparator); found {
+			segment.Parameter = name
+			segment.Pattern = pattern
+		} else {
+			segment.Parameter = inner
+		}
+		segments = append(segments, segment)
+	}
+	return segments
+}
+
+// MuxPattern returns the path in the format of an http.ServeMux pattern, where the parameters have no pattern.
+// For example, /users/{id:int}/{rest...} becomes /users/{id}/{rest...}.
+func (path Path) MuxPattern() string {
+	return path.format(func(segment PathSegment) string {
+		if segment.Wildcard {
+			return "{" + segment.Parameter + wildcardSuffix + "}"
+		}
+		return "{" + segment.Parameter + "}"
+	})
+}
+
+// Template returns the path with its parameters in the {name} format of URI templates and OpenAPI documents.
+// For example, /users/{id:int}/{rest...} becomes /users/{id}/{rest}.
+func (path Path) Template() string {
+	return path.format(func(segment PathSegment) string {
+		return "{" + segment.Parameter + "}"
+	})
+}
+
+// MatchesPatterns returns true if the path values of the request match the patterns of the parameters of the path.
+// The request must have been routed by the pattern of MuxPattern, so it has a value for each parameter.
+func (path Path) MatchesPatterns(request *http.Request) bool {
+	for _, segment := range path.Segments() {
+		if !segment.matches(request.PathValue(segment.Parameter)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches returns true if the URL path of the request matches the path, including the patterns of its parameters,
+// regardless of the method of the request. Unlike the ServeMux pattern of the root path, which matches every path,
+// the root path only matches the root.
+func (path Path) Matches(request *http.Request) bool {
+	segments := path.Segments()
+	escapedPath := strings.TrimPrefix(request.URL.EscapedPath(), "/")
+	if len(segments) == 0 {
+		return escapedPath == ""
+	}
+	parts := strings.Split(escapedPath, "/")
+	for i, segment := range segments {
+		if i >= len(parts) {
+			return false
+		}
+		if segment.Wildcard {
+			return true
+		}
+		value, err := url.PathUnescape(parts[i])
+		if err != nil {
+			return false
+		}
+		if segment.Parameter == "" {
+			if value != segment.Literal {
+				return false
+			}
+			continue
+		}
+		if value == "" || !segment.matches(value) {
+			return false
+		}
+	}
+	return len(parts) == len(segments)
+}
+
+// format returns the path with each parameter segment replaced by the result of the formatter.
+func (path Path) format(formatParameter func(segment PathSegment) string) string {
+	segments := path.Segments()
+	if len(segments) == 0 {
+		return string(path)
+	}
+	var builder strings.Builder
+	for _, segment := range segments {
+		builder.WriteString("/")
+		if segment.Parameter == "" {
+			builder.WriteString(segment.Literal)
+		} else {
+			builder.WriteString(formatParameter(segment))
+		}
+	}
+	return builder.String()
+}
+
+// matches returns true if the value of the segment matches its pattern. Segments without a pattern match any value.
+func (segment PathSegment) matches(value string) bool {
+	if segment.Pattern == "" {
+		return true
+	}
+	matches, found := pathPatterns[segment.Pattern]
+	return found && matches(value)
+}
+
+// validatePathParameter returns an error if the segment, which contains braces, is not a valid path parameter.
+func validatePathParameter(part string, isLast bool, parameterNames map[string]bool) error {
+	if !strings.HasPrefix(part, "{") || !strings.HasSuffix(part, "}") {
+		return errors.New("path parameters must start with '{' and end with '}'")
+	}
+	if strings.Count(part, "{") != 1 || strings.Count(part, "}") != 1 {
+		return errors.New("path parameters have only one '{' and '}'")
+	}
+	if part == "{}" {
+		return errors.New("path parameters cannot be empty")
+	}
+	segment := Path("/" + part).Segments()[0]
+	if !isAlphanumeric(segment.Parameter) {
+		return errors.New("path parameter names must only contain letters and digits")
+	}
+	if parameterNames[segment.Parameter] {
+		return errors.New("path parameter names must be unique")
+	}
+	parameterNames[segment.Parameter] = true
+	if segment.Wildcard && !isLast {
+		return errors.New("wildcard path parameters must be the last part of the path")
+	}
+	if strings.Contains(part, patternSeparator) {
+		if _, found := pathPatterns[segment.Pattern]; !found {
+			return fmt.Errorf("the path pattern '%s' is not registered", segment.Pattern)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/http/api"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestPath(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when a path is parsed it should return its literal, patterned, and wildcard segments", func(t *testing.T) {
+		t.Parallel()
+		segments := api.Path("/users/{id:int}/files/{name}/{rest...}").Segments()
+		assert.Equals(t, segments, []api.PathSegment{
+			{Literal: "users"},
+			{Parameter: "id", Pattern: "int"},
+			{Literal: "files"},
+			{Parameter: "name"},
+			{Parameter: "rest", Wildcard: true},
+		})
+		assert.Equals(t, api.Path("/").Segments(), []api.PathSegment{})
+	})
+
+	t.Run("when a path is formatted it should produce the mux pattern and the template", func(t *testing.T) {
+		t.Parallel()
+		path := api.Path("/users/{id:uuid}/{rest...}")
+		assert.Equals(t, path.MuxPattern(), "/users/{id}/{rest...}")
+		assert.Equals(t, path.Template(), "/users/{id}/{rest}")
+		assert.Equals(t, api.Path("/").MuxPattern(), "/")
+		assert.Equals(t, api.Path("/static/file.json").Template(), "/static/file.json")
+	})
+
+	t.Run("when the path values are checked it should match the patterns of the parameters", func(t *testing.T) {
+		t.Parallel()
+		testCases := []struct {
+			path    api.Path
+			values  map[string]string
+			matches bool
+		}{
+			{path: "/{id:int}", values: map[string]string{"id": "-42"}, matches: true},
+			{path: "/{id:int}", values: map[string]string{"id": "4x"}, matches: false},
+			{path: "/{id:uint}", values: map[string]string{"id": "-42"}, matches: false},
+			{path: "/{id:uint}", values: map[string]string{"id": "42"}, matches: true},
+			{path: "/{id:alpha}", values: map[string]string{"id": "abc"}, matches: true},
+			{path: "/{id:alpha}", values: map[string]string{"id": "abc1"}, matches: false},
+			{path: "/{id:alnum}", values: map[string]string{"id": "abc1"}, matches: true},
+			{path: "/{id:hex}", values: map[string]string{"id": "deadBEEF"}, matches: true},
+			{path: "/{id:hex}", values: map[string]string{"id": "xyz"}, matches: false},
+			{path: "/{id:uuid}", values: map[string]string{"id": "123e4567-e89b-12d3-a456-426614174000"}, matches: true},
+			{path: "/{id:uuid}", values: map[string]string{"id": "123e4567"}, matches: false},
+			{path: "/{id}/{rest...}", values: map[string]string{"id": "anything", "rest": "a/b"}, matches: true},
+		}
+		for _, testCase := range testCases {
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			for name, value := range testCase.values {
+				request.SetPathValue(name, value)
+			}
+			assert.Equals(t, testCase.path.MatchesPatterns(request), testCase.matches)
+		}
+	})
+
+	t.Run("when a request path is matched it should compare the literals and the patterns of the parameters", func(t *testing.T) {
+		t.Parallel()
+		testCases := []struct {
+			path    api.Path
+			target  string
+			matches bool
+		}{
+			{path: "/", target: "/", matches: true},
+			{path: "/", target: "/users", matches: false},
+			{path: "/users/{id:int}", target: "/users/42", matches: true},
+			{path: "/users/{id:int}", target: "/users/abc", matches: false},
+			{path: "/users/{id:int}", target: "/users/42/files", matches: false},
+			{path: "/users/{id}", target: "/users/", matches: false},
+			{path: "/users/{id}", target: "/users", matches: false},
+			{path: "/users/{id}", target: "/users/a%2Fb", matches: true},
+			{path: "/files/data.json", target: "/files/data.json", matches: true},
+			{path: "/files/data.json", target: "/files/other.json", matches: false},
+			{path: "/static/{path...}", target: "/static/", matches: true},
+			{path: "/static/{path...}", target: "/static/css/site.css", matches: true},
+			{path: "/static/{path...}", target: "/static", matches: false},
+		}
+		for _, testCase := range testCases {
+			request := httptest.NewRequest(http.MethodGet, testCase.target, nil)
+			assert.Equals(t, testCase.path.Matches(request), testCase.matches)
+		}
+	})
+
+	t.Run("when a path pattern is registered it can be used in routes", func(t *testing.T) {
+		t.Parallel()
+		api.RegisterPathPattern("lowercaseTestPattern", func(value string) bool {
+			return value == strings.ToLower(value)
+		})
+		builder := api.NewHTTPAPIBuilder()
+		builder.MustRegister("/{name:lowercaseTestPattern}", http.MethodGet, nil)
+
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.SetPathValue("name", "Upper")
+		assert.False(t, api.Path("/{name:lowercaseTestPattern}").MatchesPatterns(request))
+	})
+
+	t.Run("when a path pattern registration is invalid it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicExact(t, func() {
+			api.RegisterPathPattern("int", func(string) bool { return true })
+		}, "the path pattern 'int' is already registered")
+		assert.PanicExact(t, func() {
+			api.RegisterPathPattern("bad-name", func(string) bool { return true })
+		}, "the path pattern name 'bad-name' must only contain letters and digits")
+		assert.PanicExact(t, func() {
+			api.RegisterPathPattern("nilMatcher", nil)
+		}, "the path pattern matcher cannot be nil")
+	})
+
+	t.Run("when a path parameter is invalid it should fail to register", func(t *testing.T) {
+		t.Parallel()
+		testCases := []struct {
+			path        api.Path
+			expectedErr string
+		}{
+			{path: "/{id:unknown}", expectedErr: "the path pattern 'unknown' is not registered"},
+			{path: "/{rest...}/more", expectedErr: "wildcard path parameters must be the last part of the path"},
+			{path: "/{id:int}/{id}", expectedErr: "path parameter names must be unique"},
+			{path: "/{id.x}", expectedErr: "path parameter names must only contain letters and digits"},
+			{path: "/{:int}", expectedErr: "path parameter names must only contain letters and digits"},
+			{path: "/{id:int...}", expectedErr: "path parameter names must only contain letters and digits"},
+			{path: "/a{id}", expectedErr: "path parameters must start with '{' and end with '}'"},
+		}
+		for _, testCase := range testCases {
+			assert.PanicPart(t, func() {
+				api.NewHTTPAPIBuilder().MustRegister(testCase.path, http.MethodGet, nil)
+			}, testCase.expectedErr)
+		}
+	})
+
+	t.Run("when a path has literal dots and patterned parameters it should register", func(t *testing.T) {
+		t.Parallel()
+		builder := api.NewHTTPAPIBuilder()
+		builder.MustRegister("/files/{id:int}/data.json", http.MethodGet, nil)
+		builder.MustRegister("/static/{path...}", http.MethodGet, nil)
+		assert.Equals(t, len(builder.Handlers()), 2)
+	})
+}
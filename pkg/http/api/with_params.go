@@ -0,0 +1,31 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/TriangleSide/GoBase/pkg/http/parameters"
+	"github.com/TriangleSide/GoBase/pkg/http/responders"
+)
+
+// WithParams adapts a handler that requires a parameter struct into an http.HandlerFunc.
+// The parameters are decoded and validated with parameters.Decode. If that fails, the error is written with the
+// DecodeError responder and the handler is not called. The options, like responders.WithValidationFailureStatus,
+// configure the error response.
+//
+// For example:
+//
+//	builder.MustRegister("/items/{id}", http.MethodGet, &api.Handler{
+//	    Handler: api.WithParams(func(params *getItemParams, writer http.ResponseWriter, request *http.Request) {
+//	        // Handle the request here.
+//	    }),
+//	})
+func WithParams[P any](fn func(params *P, writer http.ResponseWriter, request *http.Request), options ...responders.Option) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		params, err := parameters.Decode[P](request)
+		if err != nil {
+			responders.DecodeError(request, writer, err, options...)
+			return
+		}
+		fn(params, writer, request)
+	}
+}
@@ -0,0 +1,102 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/TriangleSide/GoBase/pkg/http/api"
+	"github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/responders"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestWithParams(t *testing.T) {
+	t.Parallel()
+
+	type params struct {
+		ID int `urlQuery:"id" json:"-" validate:"gt=0"`
+	}
+
+	t.Run("when the parameters are valid it should call the handler with the decoded struct", func(t *testing.T) {
+		t.Parallel()
+		var decodedID int
+		handler := api.WithParams(func(params *params, writer http.ResponseWriter, request *http.Request) {
+			decodedID = params.ID
+			writer.WriteHeader(http.StatusAccepted)
+		})
+		recorder := httptest.NewRecorder()
+		handler(recorder, httptest.NewRequest(http.MethodGet, "/?id=12", nil))
+		assert.Equals(t, recorder.Code, http.StatusAccepted)
+		assert.Equals(t, decodedID, 12)
+	})
+
+	t.Run("when the parameters fail validation it should respond with a bad request and not call the handler", func(t *testing.T) {
+		t.Parallel()
+		called := false
+		handler := api.WithParams(func(params *params, writer http.ResponseWriter, request *http.Request) {
+			called = true
+		})
+		recorder := httptest.NewRecorder()
+		handler(recorder, httptest.NewRequest(http.MethodGet, "/?id=-1", nil))
+		assert.False(t, called)
+		assert.Equals(t, recorder.Code, http.StatusBadRequest)
+		httpError := &errors.Error{}
+		assert.NoError(t, json.NewDecoder(recorder.Body).Decode(httpError))
+		assert.Contains(t, httpError.Message, "validation failed on field 'ID'")
+	})
+
+	t.Run("when the parameters cannot be decoded it should respond with a bad request", func(t *testing.T) {
+		t.Parallel()
+		handler := api.WithParams(func(params *params, writer http.ResponseWriter, request *http.Request) {
+			writer.WriteHeader(http.StatusOK)
+		})
+		recorder := httptest.NewRecorder()
+		handler(recorder, httptest.NewRequest(http.MethodGet, "/?id=abc", nil))
+		assert.Equals(t, recorder.Code, http.StatusBadRequest)
+	})
+
+	type bodyParams struct {
+		Name string `json:"name"`
+	}
+
+	t.Run("when the body exceeds the maximum size it should respond with request entity too large", func(t *testing.T) {
+		t.Parallel()
+		handler := api.WithParams(func(params *bodyParams, writer http.ResponseWriter, request *http.Request) {
+			writer.WriteHeader(http.StatusOK)
+		})
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"`+strings.Repeat("a", 100)+`"}`))
+		request.Header.Set(headers.ContentType, headers.ContentTypeApplicationJson)
+		request.Body = http.MaxBytesReader(recorder, request.Body, 10)
+		handler(recorder, request)
+		assert.Equals(t, recorder.Code, http.StatusRequestEntityTooLarge)
+	})
+
+	t.Run("when the body is not read before the read deadline it should respond with request timeout", func(t *testing.T) {
+		t.Parallel()
+		handler := api.WithParams(func(params *bodyParams, writer http.ResponseWriter, request *http.Request) {
+			writer.WriteHeader(http.StatusOK)
+		})
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, "/", iotest.ErrReader(os.ErrDeadlineExceeded))
+		request.Header.Set(headers.ContentType, headers.ContentTypeApplicationJson)
+		handler(recorder, request)
+		assert.Equals(t, recorder.Code, http.StatusRequestTimeout)
+	})
+
+	t.Run("when a validation failure status is set it should respond with it", func(t *testing.T) {
+		t.Parallel()
+		handler := api.WithParams(func(params *params, writer http.ResponseWriter, request *http.Request) {
+			writer.WriteHeader(http.StatusOK)
+		}, responders.WithValidationFailureStatus(http.StatusUnprocessableEntity))
+		recorder := httptest.NewRecorder()
+		handler(recorder, httptest.NewRequest(http.MethodGet, "/?id=-1", nil))
+		assert.Equals(t, recorder.Code, http.StatusUnprocessableEntity)
+	})
+}
@@ -0,0 +1,207 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/config"
+	"github.com/TriangleSide/GoBase/pkg/config/envprocessor"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+)
+
+// retryPolicy defines how many times a request is attempted and how long to wait between the attempts.
+type retryPolicy struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+// clientOptions is configured by the caller with the Option functions.
+type clientOptions struct {
+	configProvider func() (*config.HTTPClient, error)
+	transport      http.RoundTripper
+	retry          retryPolicy
+}
+
+// Option is used to configure the HTTP client.
+type Option func(clientOpts *clientOptions)
+
+// WithConfigProvider sets the provider for the config.HTTPClient.
+func WithConfigProvider(provider func() (*config.HTTPClient, error)) Option {
+	return func(clientOpts *clientOptions) {
+		clientOpts.configProvider = provider
+	}
+}
+
+// WithTransport sets the http.RoundTripper that sends the requests.
+// The TLS mode of the config.HTTPClient is not applied to a custom transport.
+func WithTransport(transport http.RoundTripper) Option {
+	if transport == nil {
+		panic("the transport cannot be nil")
+	}
+	return func(clientOpts *clientOptions) {
+		clientOpts.transport = transport
+	}
+}
+
+// WithRetries attempts requests with idempotent methods up to maxAttempts times. A request is retried when it
+// fails to reach the server, or when the server responds with HTTP 429, 502, 503, or 504. The wait between the
+// attempts starts at the initial backoff and doubles up to the maximum backoff, with jitter. If the response has
+// a Retry-After header, it is used instead, up to the maximum backoff.
+func WithRetries(maxAttempts int, initialBackoff time.Duration, maxBackoff time.Duration) Option {
+	if maxAttempts < 1 {
+		panic("the maximum number of attempts must be at least 1")
+	}
+	if initialBackoff <= 0 || maxBackoff < initialBackoff {
+		panic("the initial backoff must be greater than zero and not exceed the maximum backoff")
+	}
+	return func(clientOpts *clientOptions) {
+		clientOpts.retry = retryPolicy{
+			maxAttempts:    maxAttempts,
+			initialBackoff: initialBackoff,
+			maxBackoff:     maxBackoff,
+		}
+	}
+}
+
+// Client sends requests to an HTTP API. It is used with Do to make typed requests.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	retry      retryPolicy
+}
+
+// New allocates a Client for the API at the base URL. Paths of requests are appended to the base URL.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	clientOpts := &clientOptions{
+		configProvider: func() (*config.HTTPClient, error) {
+			return envprocessor.ProcessAndValidate[config.HTTPClient]()
+		},
+		transport: nil,
+		retry: retryPolicy{
+			maxAttempts:    1,
+			initialBackoff: 0,
+			maxBackoff:     0,
+		},
+	}
+	for _, opt := range opts {
+		opt(clientOpts)
+	}
+
+	parsedURL, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the base URL (%w)", err)
+	}
+	if (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+		return nil, fmt.Errorf("the base URL '%s' must be an absolute http or https URL", baseURL)
+	}
+
+	cfg, err := clientOpts.configProvider()
+	if err != nil {
+		return nil, fmt.Errorf("could not load configuration (%w)", err)
+	}
+
+	transport := clientOpts.transport
+	if transport == nil {
+		tlsConfig, err := config.BuildClientTLSConfig(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the TLS config (%w)", err)
+		}
+		defaultTransport := http.DefaultTransport.(*http.Transport).Clone()
+		if tlsConfig != nil {
+			defaultTransport.TLSClientConfig = tlsConfig
+		}
+		transport = defaultTransport
+	}
+
+	return &Client{
+		baseURL: parsedURL,
+		httpClient: &http.Client{
+			Transport: transport,
+			Timeout:   time.Duration(cfg.HTTPClientTimeoutSeconds) * time.Second,
+		},
+		retry: clientOpts.retry,
+	}, nil
+}
+
+// send sends the request built by newRequest, retrying it according to the retry policy.
+// The newRequest function is called for every attempt, so each attempt has a fresh body.
+func (c *Client) send(ctx context.Context, method string, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	maxAttempts := 1
+	if isIdempotent(method) {
+		maxAttempts = c.retry.maxAttempts
+	}
+	backoff := c.retry.initialBackoff
+	for attempt := 1; ; attempt++ {
+		request, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+		response, err := c.httpClient.Do(request)
+		if attempt >= maxAttempts || ctx.Err() != nil {
+			return response, err
+		}
+
+		wait := backoff/2 + rand.N(backoff/2+1)
+		if err == nil {
+			if !isRetryableStatus(response.StatusCode) {
+				return response, nil
+			}
+			if retryAfter, ok := parseRetryAfter(response.Header.Get(headers.RetryAfter)); ok {
+				wait = min(retryAfter, c.retry.maxBackoff)
+			}
+			_ = response.Body.Close()
+		} else if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return nil, err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, c.retry.maxBackoff)
+	}
+}
+
+// isIdempotent returns true if sending the request with the method many times has the same effect as sending it once.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// isRetryableStatus returns true if the status indicates that the request may succeed if it is sent again.
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// parseRetryAfter parses a Retry-After header that is either a number of seconds or an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
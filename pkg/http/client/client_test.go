@@ -0,0 +1,210 @@
+package client_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/config"
+	"github.com/TriangleSide/GoBase/pkg/http/client"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestClient(t *testing.T) {
+	t.Parallel()
+
+	offConfig := client.WithConfigProvider(func() (*config.HTTPClient, error) {
+		return &config.HTTPClient{HTTPClientTLSMode: config.HTTPServerTLSModeOff}, nil
+	})
+
+	t.Run("when the retries are invalid it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicExact(t, func() {
+			client.WithRetries(0, time.Millisecond, time.Second)
+		}, "the maximum number of attempts must be at least 1")
+		assert.PanicExact(t, func() {
+			client.WithRetries(3, time.Second, time.Millisecond)
+		}, "the initial backoff must be greater than zero and not exceed the maximum backoff")
+	})
+
+	t.Run("when the transport is nil it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicExact(t, func() {
+			client.WithTransport(nil)
+		}, "the transport cannot be nil")
+	})
+
+	t.Run("when the base URL is not an absolute HTTP URL it should return an error", func(t *testing.T) {
+		t.Parallel()
+		c, err := client.New("ftp://example.com", offConfig)
+		assert.ErrorExact(t, err, "the base URL 'ftp://example.com' must be an absolute http or https URL")
+		assert.Nil(t, c)
+		c, err = client.New("%", offConfig)
+		assert.ErrorPart(t, err, "failed to parse the base URL")
+		assert.Nil(t, c)
+	})
+
+	t.Run("when the config provider fails it should return an error", func(t *testing.T) {
+		t.Parallel()
+		c, err := client.New("http://example.com", client.WithConfigProvider(func() (*config.HTTPClient, error) {
+			return nil, errors.New("config error")
+		}))
+		assert.ErrorExact(t, err, "could not load configuration (config error)")
+		assert.Nil(t, c)
+	})
+
+	t.Run("when the TLS config cannot be built it should return an error", func(t *testing.T) {
+		t.Parallel()
+		c, err := client.New("https://example.com", client.WithConfigProvider(func() (*config.HTTPClient, error) {
+			return &config.HTTPClient{HTTPClientTLSMode: config.HTTPServerTLSModeMutualTLS}, nil
+		}))
+		assert.ErrorPart(t, err, "failed to create the TLS config")
+		assert.Nil(t, c)
+	})
+
+	t.Run("when the server is unavailable it should retry idempotent requests with backoff", func(t *testing.T) {
+		t.Parallel()
+		attempts := atomic.Int32{}
+		c := newTestClient(t, http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if attempts.Add(1) < 3 {
+				writer.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = writer.Write([]byte(`"ok"`))
+		}), client.WithRetries(3, time.Millisecond, time.Millisecond*5))
+		response, err := client.Do[struct{}, string](context.Background(), c, http.MethodGet, "/", nil)
+		assert.NoError(t, err)
+		assert.Equals(t, *response, "ok")
+		assert.Equals(t, attempts.Load(), int32(3))
+	})
+
+	t.Run("when the attempts are exhausted it should return the last response", func(t *testing.T) {
+		t.Parallel()
+		attempts := atomic.Int32{}
+		c := newTestClient(t, http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			attempts.Add(1)
+			writer.Header().Set(headers.RetryAfter, "0")
+			writer.WriteHeader(http.StatusTooManyRequests)
+		}), client.WithRetries(2, time.Hour, time.Hour))
+		_, err := client.Do[struct{}, string](context.Background(), c, http.MethodPut, "/", nil)
+		assert.ErrorExact(t, err, "the server responded with status 429 (Too Many Requests)")
+		assert.Equals(t, attempts.Load(), int32(2))
+	})
+
+	t.Run("when the method is not idempotent it should not retry", func(t *testing.T) {
+		t.Parallel()
+		attempts := atomic.Int32{}
+		c := newTestClient(t, http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			attempts.Add(1)
+			writer.WriteHeader(http.StatusBadGateway)
+		}), client.WithRetries(3, time.Millisecond, time.Millisecond))
+		_, err := client.Do[struct{}, string](context.Background(), c, http.MethodPost, "/", nil)
+		assert.ErrorPart(t, err, "status 502")
+		assert.Equals(t, attempts.Load(), int32(1))
+	})
+
+	t.Run("when the status is not retryable it should not retry", func(t *testing.T) {
+		t.Parallel()
+		attempts := atomic.Int32{}
+		c := newTestClient(t, http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			attempts.Add(1)
+			writer.WriteHeader(http.StatusInternalServerError)
+		}), client.WithRetries(3, time.Millisecond, time.Millisecond))
+		_, err := client.Do[struct{}, string](context.Background(), c, http.MethodGet, "/", nil)
+		assert.ErrorPart(t, err, "status 500")
+		assert.Equals(t, attempts.Load(), int32(1))
+	})
+
+	t.Run("when the connection fails it should retry until the context is cancelled", func(t *testing.T) {
+		t.Parallel()
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+		address := listener.Addr().String()
+		assert.NoError(t, listener.Close())
+		c, err := client.New("http://"+address, offConfig, client.WithRetries(100, time.Millisecond*10, time.Millisecond*10))
+		assert.NoError(t, err)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+		defer cancel()
+		_, err = client.Do[struct{}, string](ctx, c, http.MethodGet, "/", nil)
+		assert.ErrorPart(t, err, "failed to send the request")
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("when the TLS mode is mutual TLS it should authenticate with the server", func(t *testing.T) {
+		t.Parallel()
+		certPath, keyPath := writeCertificate(t)
+		serverTLSConfig, err := config.BuildTLSConfig(&config.HTTPServer{
+			HTTPServerTLSMode:       config.HTTPServerTLSModeMutualTLS,
+			HTTPServerCert:          certPath,
+			HTTPServerKey:           keyPath,
+			HTTPServerClientCACerts: []string{certPath},
+		})
+		assert.NoError(t, err)
+		srv := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			_, _ = writer.Write([]byte(`"` + request.TLS.PeerCertificates[0].Subject.CommonName + `"`))
+		}))
+		srv.TLS = serverTLSConfig
+		srv.StartTLS()
+		t.Cleanup(srv.Close)
+
+		newClient := func(mode config.HTTPServerTLSMode) *client.Client {
+			c, err := client.New(srv.URL, client.WithConfigProvider(func() (*config.HTTPClient, error) {
+				return &config.HTTPClient{
+					HTTPClientTLSMode:     mode,
+					HTTPClientCert:        certPath,
+					HTTPClientKey:         keyPath,
+					HTTPClientRootCACerts: []string{certPath},
+				}, nil
+			}))
+			assert.NoError(t, err)
+			return c
+		}
+
+		response, err := client.Do[struct{}, string](context.Background(), newClient(config.HTTPServerTLSModeMutualTLS), http.MethodGet, "/", nil)
+		assert.NoError(t, err)
+		assert.Equals(t, *response, "client-test")
+
+		_, err = client.Do[struct{}, string](context.Background(), newClient(config.HTTPServerTLSModeTLS), http.MethodGet, "/", nil)
+		assert.ErrorPart(t, err, "failed to send the request")
+	})
+}
+
+// writeCertificate writes a self-signed certificate for 127.0.0.1 that can authenticate servers and clients.
+func writeCertificate(t *testing.T) (string, string) {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	certTemplate := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "client-test"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, &certTemplate, &certTemplate, &privateKey.PublicKey, privateKey)
+	assert.NoError(t, err)
+	tempDir := t.TempDir()
+	certPath := filepath.Join(tempDir, "cert.pem")
+	assert.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certBytes}), 0644))
+	keyPath := filepath.Join(tempDir, "key.pem")
+	assert.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)}), 0600))
+	return certPath, keyPath
+}
@@ -0,0 +1,346 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/token"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	httperrors "github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/parameters"
+	"github.com/TriangleSide/GoBase/pkg/utils/fields"
+	"github.com/TriangleSide/GoBase/pkg/validation"
+)
+
+var (
+	// pathParameterRegex matches the path parameters of a route, like {id}, {id:int}, or {path...}.
+	pathParameterRegex = regexp.MustCompile(`\{([^{}]+)\}`)
+
+	// textMarshalerType is the reflected type of the encoding.TextMarshaler interface.
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// StatusError is returned by Do when the server responds with a status that is not in the 2xx range.
+// The Code and Retryable hint are those of the error response, and are derived from the status if the
+// response does not have them.
+type StatusError struct {
+	Status    int
+	Message   string
+	Code      string
+	Retryable bool
+}
+
+// Error returns the status and the message of the error response.
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("the server responded with status %d (%s)", e.Status, e.Message)
+}
+
+// IsRetryable returns the retryable hint of the error response.
+func (e *StatusError) IsRetryable() bool {
+	return e.Retryable
+}
+
+// Do sends a request to the path of the API and decodes the JSON response into the Response type.
+//
+// The Request parameters are encoded with the same struct tags that parameters.Decode reads. Fields with the
+// urlPath tag replace the matching {name}, {name:pattern}, or {name...} segments of the path, where the slashes
+// of a {name...} value are kept. Fields with the urlQuery tag are added to the query, fields with the httpHeader tag are set as headers, and fields with the httpCookie tag are sent as cookies.
+// Zero values are not sent as query parameters, headers, or cookies. Slices with the urlQuerySplit tag are joined by
+// its separator. If the struct has other JSON fields, it is encoded as the JSON body of the request.
+//
+// If the Response is a struct, it is validated. A response without a body, like HTTP 204, returns a nil Response.
+// A response with a status outside the 2xx range returns a StatusError with the message of the error response.
+func Do[Request any, Response any](ctx context.Context, client *Client, method string, path string, params *Request) (*Response, error) {
+	if params == nil {
+		params = new(Request)
+	}
+	encoded, err := encodeParameters(path, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the request parameters (%w)", err)
+	}
+
+	requestURL := client.baseURL.JoinPath(encoded.path)
+	requestURL.RawQuery = encoded.query.Encode()
+	response, err := client.send(ctx, method, func() (*http.Request, error) {
+		var body io.Reader
+		if encoded.body != nil {
+			body = bytes.NewReader(encoded.body)
+		}
+		request, err := http.NewRequestWithContext(ctx, method, requestURL.String(), body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the request (%w)", err)
+		}
+		for name, values := range encoded.header {
+			request.Header[name] = values
+		}
+		for _, cookie := range encoded.cookies {
+			request.AddCookie(cookie)
+		}
+		request.Header.Set(headers.Accept, headers.ContentTypeApplicationJson)
+		if encoded.body != nil {
+			request.Header.Set(headers.ContentType, headers.ContentTypeApplicationJson)
+		}
+		return request, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send the request (%w)", err)
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the response body (%w)", err)
+	}
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		statusErr := &StatusError{
+			Status:    response.StatusCode,
+			Message:   http.StatusText(response.StatusCode),
+			Code:      httperrors.CodeForStatus(response.StatusCode),
+			Retryable: httperrors.IsRetryableStatus(response.StatusCode),
+		}
+		errorResponse := &httperrors.Error{}
+		if err := json.Unmarshal(responseBody, errorResponse); err == nil {
+			statusErr.Message = errorResponse.Message
+			if errorResponse.Code != "" {
+				statusErr.Code = errorResponse.Code
+				statusErr.Retryable = errorResponse.Retryable
+			}
+		}
+		return nil, statusErr
+	}
+
+	if len(bytes.TrimSpace(responseBody)) == 0 {
+		return nil, nil
+	}
+	decoded := new(Response)
+	if err := json.Unmarshal(responseBody, decoded); err != nil {
+		return nil, fmt.Errorf("failed to decode the response body (%w)", err)
+	}
+	if reflect.TypeFor[Response]().Kind() == reflect.Struct {
+		if err := validation.Struct(decoded); err != nil {
+			return nil, fmt.Errorf("validation failed for the response body (%w)", err)
+		}
+	}
+	return decoded, nil
+}
+
+// encodedParameters are the parts of a request encoded from a parameter struct.
+type encodedParameters struct {
+	path    string
+	query   url.Values
+	header  http.Header
+	cookies []*http.Cookie
+	body    []byte
+}
+
+// encodeParameters encodes the parameter struct into the path, query, headers, cookies, and body of a request.
+func encodeParameters[T any](path string, params *T) (*encodedParameters, error) {
+	tagToLookupKeyToFieldName, err := parameters.ExtractAndValidateFieldTagLookupKeys[T]()
+	if err != nil {
+		return nil, fmt.Errorf("tags are not correctly formatted (%w)", err)
+	}
+	fieldsMetadata := fields.StructMetadata[T]()
+	structValue := reflect.ValueOf(params).Elem()
+
+	encoded := &encodedParameters{
+		path:   path,
+		query:  url.Values{},
+		header: http.Header{},
+		body:   nil,
+	}
+
+	var pathErr error
+	pathLookupKeyToFieldName := tagToLookupKeyToFieldName.Get(parameters.PathTag)
+	encoded.path = pathParameterRegex.ReplaceAllStringFunc(path, func(segment string) string {
+		name, wildcard := strings.CutSuffix(segment[1:len(segment)-1], "...")
+		name, _, _ = strings.Cut(name, ":")
+		fieldName, found := pathLookupKeyToFieldName[name]
+		if !found {
+			pathErr = errors.Join(pathErr, fmt.Errorf("the path parameter '%s' has no field", name))
+			return segment
+		}
+		value, isSet, err := formatField(structValue, fieldsMetadata.Get(fieldName), fieldName)
+		if err != nil {
+			pathErr = errors.Join(pathErr, fmt.Errorf("failed to format the path parameter '%s' (%w)", name, err))
+			return segment
+		}
+		if !isSet || value == "" {
+			pathErr = errors.Join(pathErr, fmt.Errorf("the path parameter '%s' has no value", name))
+			return segment
+		}
+		if wildcard {
+			parts := strings.Split(value, "/")
+			for index, part := range parts {
+				parts[index] = url.PathEscape(part)
+			}
+			return strings.Join(parts, "/")
+		}
+		return url.PathEscape(value)
+	})
+	if pathErr != nil {
+		return nil, pathErr
+	}
+
+	for lookupKey, fieldName := range tagToLookupKeyToFieldName.Get(parameters.QueryTag) {
+		if separator, hasSeparator := parameters.QuerySplitSeparator(fieldsMetadata.Get(fieldName)); hasSeparator {
+			value, isSet, err := formatSplitField(structValue, fieldsMetadata.Get(fieldName), fieldName, separator)
+			if err != nil {
+				return nil, fmt.Errorf("failed to format the query parameter '%s' (%w)", lookupKey, err)
+			}
+			if isSet {
+				encoded.query.Set(lookupKey, value)
+			}
+			continue
+		}
+		value, isSet, err := formatField(structValue, fieldsMetadata.Get(fieldName), fieldName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to format the query parameter '%s' (%w)", lookupKey, err)
+		}
+		if isSet {
+			encoded.query.Set(lookupKey, value)
+		}
+	}
+
+	for lookupKey, fieldName := range tagToLookupKeyToFieldName.Get(parameters.HeaderTag) {
+		value, isSet, err := formatField(structValue, fieldsMetadata.Get(fieldName), fieldName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to format the header parameter '%s' (%w)", lookupKey, err)
+		}
+		if isSet {
+			encoded.header.Set(lookupKey, value)
+		}
+	}
+
+	for lookupKey, fieldName := range tagToLookupKeyToFieldName.Get(parameters.CookieTag) {
+		value, isSet, err := formatField(structValue, fieldsMetadata.Get(fieldName), fieldName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to format the cookie parameter '%s' (%w)", lookupKey, err)
+		}
+		if isSet {
+			encoded.cookies = append(encoded.cookies, &http.Cookie{Name: lookupKey, Value: value})
+		}
+	}
+
+	for fieldName, fieldMetadata := range fieldsMetadata.Iterator() {
+		if token.IsExported(fieldName) && fieldMetadata.Tags[string(parameters.JSONTag)] != "-" {
+			if encoded.body, err = json.Marshal(params); err != nil {
+				return nil, fmt.Errorf("failed to encode the json body (%w)", err)
+			}
+			break
+		}
+	}
+
+	return encoded, nil
+}
+
+// formatField encodes the value of a field as a string, the inverse of how assign.StructField parses it.
+// It returns false if the field is nil or has its zero value.
+func formatField(structValue reflect.Value, fieldMetadata *fields.FieldMetadata, fieldName string) (string, bool, error) {
+	fieldValue, isSet := fieldValueByName(structValue, fieldMetadata, fieldName)
+	if !isSet {
+		return "", false, nil
+	}
+	value, err := formatValue(fieldValue)
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+// formatSplitField encodes the elements of a slice field joined by the separator of its parameters.QuerySplitTag.
+// It returns false if the field is nil or empty.
+func formatSplitField(structValue reflect.Value, fieldMetadata *fields.FieldMetadata, fieldName string, separator string) (string, bool, error) {
+	fieldValue, isSet := fieldValueByName(structValue, fieldMetadata, fieldName)
+	if !isSet || fieldValue.Len() == 0 {
+		return "", false, nil
+	}
+	elements := make([]string, 0, fieldValue.Len())
+	for i := range fieldValue.Len() {
+		element := fieldValue.Index(i)
+		if element.Kind() == reflect.Pointer {
+			if element.IsNil() {
+				return "", false, fmt.Errorf("element %d is nil", i)
+			}
+			element = element.Elem()
+		}
+		value, err := formatValue(element)
+		if err != nil {
+			return "", false, fmt.Errorf("element %d (%w)", i, err)
+		}
+		elements = append(elements, value)
+	}
+	return strings.Join(elements, separator), true, nil
+}
+
+// fieldValueByName returns the value of the field with the pointers dereferenced.
+// It returns false if the field is nil or has its zero value.
+func fieldValueByName(structValue reflect.Value, fieldMetadata *fields.FieldMetadata, fieldName string) (reflect.Value, bool) {
+	fieldValue := structValue
+	for _, name := range slices.Concat(fieldMetadata.Anonymous, []string{fieldName}) {
+		if fieldValue.Kind() == reflect.Pointer {
+			if fieldValue.IsNil() {
+				return reflect.Value{}, false
+			}
+			fieldValue = fieldValue.Elem()
+		}
+		fieldValue = fieldValue.FieldByName(name)
+	}
+	if fieldValue.Kind() == reflect.Pointer {
+		if fieldValue.IsNil() {
+			return reflect.Value{}, false
+		}
+		fieldValue = fieldValue.Elem()
+	} else if fieldValue.IsZero() {
+		return reflect.Value{}, false
+	}
+	return fieldValue, true
+}
+
+// formatValue encodes a value as a string, the inverse of how assign.StructField parses it.
+func formatValue(fieldValue reflect.Value) (string, error) {
+	if reflect.PointerTo(fieldValue.Type()).Implements(textMarshalerType) || fieldValue.Type().Implements(textMarshalerType) {
+		addressable := reflect.New(fieldValue.Type())
+		addressable.Elem().Set(fieldValue)
+		text, err := addressable.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return "", fmt.Errorf("text marshal error (%w)", err)
+		}
+		return string(text), nil
+	}
+
+	switch fieldValue.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Struct:
+		encoded, err := json.Marshal(fieldValue.Interface())
+		if err != nil {
+			return "", fmt.Errorf("json marshal error (%w)", err)
+		}
+		return string(encoded), nil
+	case reflect.String:
+		return fieldValue.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(fieldValue.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(fieldValue.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(fieldValue.Float(), 'g', -1, fieldValue.Type().Bits()), nil
+	case reflect.Bool:
+		return strconv.FormatBool(fieldValue.Bool()), nil
+	default:
+		return "", fmt.Errorf("unsupported field type: %s", fieldValue.Type())
+	}
+}
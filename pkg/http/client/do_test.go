@@ -0,0 +1,229 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/config"
+	"github.com/TriangleSide/GoBase/pkg/http/client"
+	httperrors "github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/http/responders"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+type pagination struct {
+	Limit *int `urlQuery:"limit" json:"-" validate:"omitempty,gte=1"`
+}
+
+type updateUserParams struct {
+	pagination
+	ID        string            `urlPath:"id" json:"-" validate:"required"`
+	Version   int               `urlPath:"version" json:"-"`
+	RequestID string            `httpHeader:"X-Request-ID" json:"-"`
+	Session   string            `httpCookie:"session" json:"-"`
+	Since     time.Time         `urlQuery:"since" json:"-"`
+	Filter    map[string]string `urlQuery:"filter" json:"-"`
+	Verbose   bool              `urlQuery:"verbose" json:"-"`
+	Roles     []string          `urlQuery:"role" urlQuerySplit:"comma" json:"-"`
+	IDs       []int             `urlQuery:"id" json:"-"`
+	Name      string            `json:"name" validate:"required"`
+	Tags      []string          `json:"tags,omitempty"`
+}
+
+type updateUserResponse struct {
+	ID        string            `json:"id" validate:"required"`
+	Version   int               `json:"version"`
+	RequestID string            `json:"requestId"`
+	Session   string            `json:"session"`
+	Limit     *int              `json:"limit"`
+	Since     time.Time         `json:"since"`
+	Filter    map[string]string `json:"filter"`
+	Verbose   bool              `json:"verbose"`
+	Roles     []string          `json:"roles"`
+	IDs       []int             `json:"ids"`
+	Name      string            `json:"name"`
+	Tags      []string          `json:"tags"`
+}
+
+type getUserParams struct {
+	ID string `urlPath:"id" json:"-"`
+}
+
+type fileParams struct {
+	UserID int    `urlPath:"userId" json:"-"`
+	Path   string `urlPath:"path" json:"-"`
+}
+
+type unsupportedParams struct {
+	Channel chan int `urlQuery:"channel" json:"-"`
+}
+
+// newTestClient starts a server with the handler and returns a client for it without TLS.
+func newTestClient(t *testing.T, handler http.Handler, opts ...client.Option) *client.Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	opts = append([]client.Option{client.WithConfigProvider(func() (*config.HTTPClient, error) {
+		return &config.HTTPClient{HTTPClientTLSMode: config.HTTPServerTLSModeOff}, nil
+	})}, opts...)
+	c, err := client.New(srv.URL, opts...)
+	assert.NoError(t, err)
+	return c
+}
+
+func TestDo(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("PUT /users/{id}/versions/{version}", func(writer http.ResponseWriter, request *http.Request) {
+		responders.JSON(writer, request, func(params *updateUserParams) (*updateUserResponse, int, error) {
+			return &updateUserResponse{
+				ID:        params.ID,
+				Version:   params.Version,
+				RequestID: params.RequestID,
+				Session:   params.Session,
+				Limit:     params.Limit,
+				Since:     params.Since,
+				Filter:    params.Filter,
+				Verbose:   params.Verbose,
+				Roles:     params.Roles,
+				IDs:       params.IDs,
+				Name:      params.Name,
+				Tags:      params.Tags,
+			}, http.StatusOK, nil
+		})
+	})
+	mux.HandleFunc("GET /users/{id}", func(writer http.ResponseWriter, request *http.Request) {
+		responders.JSON(writer, request, func(params *getUserParams) (*updateUserResponse, int, error) {
+			if params.ID == "missing" {
+				return nil, 0, &httperrors.BadRequest{Err: errors.New("the user does not exist")}
+			}
+			return &updateUserResponse{ID: ""}, http.StatusOK, nil
+		})
+	})
+	mux.HandleFunc("DELETE /users/{id}", func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /users/{userId}/files/{path...}", func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte(`{"id":"` + request.PathValue("userId") + ":" + request.PathValue("path") + `"}`))
+	})
+	mux.HandleFunc("GET /teapot", func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusTeapot)
+	})
+	mux.HandleFunc("GET /names", func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte(`["a","b"]`))
+	})
+	mux.HandleFunc("GET /invalid", func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte(`{`))
+	})
+	c := newTestClient(t, mux)
+
+	t.Run("when the parameters are tagged it should encode them the way they are decoded", func(t *testing.T) {
+		t.Parallel()
+		limit := 10
+		since := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+		params := &updateUserParams{
+			pagination: pagination{Limit: &limit},
+			ID:         "a/b c",
+			Version:    3,
+			RequestID:  "req-1",
+			Session:    "token",
+			Since:      since,
+			Filter:     map[string]string{"role": "admin"},
+			Verbose:    true,
+			Roles:      []string{"admin", "owner"},
+			IDs:        []int{1, 2},
+			Name:       "gopher",
+			Tags:       []string{"x"},
+		}
+		response, err := client.Do[updateUserParams, updateUserResponse](context.Background(), c, http.MethodPut, "/users/{id}/versions/{version}", params)
+		assert.NoError(t, err)
+		assert.Equals(t, response.ID, "a/b c")
+		assert.Equals(t, response.Version, 3)
+		assert.Equals(t, response.RequestID, "req-1")
+		assert.Equals(t, response.Session, "token")
+		assert.Equals(t, *response.Limit, 10)
+		assert.True(t, response.Since.Equal(since))
+		assert.Equals(t, response.Filter, map[string]string{"role": "admin"})
+		assert.True(t, response.Verbose)
+		assert.Equals(t, response.Roles, []string{"admin", "owner"})
+		assert.Equals(t, response.IDs, []int{1, 2})
+		assert.Equals(t, response.Name, "gopher")
+		assert.Equals(t, response.Tags, []string{"x"})
+	})
+
+	t.Run("when the path has patterned and wildcard parameters it should keep the slashes of the wildcard", func(t *testing.T) {
+		t.Parallel()
+		params := &fileParams{UserID: 7, Path: "docs/a b.txt"}
+		response, err := client.Do[fileParams, updateUserResponse](context.Background(), c, http.MethodGet, "/users/{userId:int}/files/{path...}", params)
+		assert.NoError(t, err)
+		assert.Equals(t, response.ID, "7:docs/a b.txt")
+	})
+
+	t.Run("when the server responds with an error it should return a status error with the message", func(t *testing.T) {
+		t.Parallel()
+		response, err := client.Do[getUserParams, updateUserResponse](context.Background(), c, http.MethodGet, "/users/{id}", &getUserParams{ID: "missing"})
+		assert.Nil(t, response)
+		assert.ErrorExact(t, err, "the server responded with status 400 (the user does not exist)")
+		statusErr, isStatusErr := err.(*client.StatusError)
+		assert.True(t, isStatusErr)
+		assert.Equals(t, statusErr.Status, http.StatusBadRequest)
+		assert.Equals(t, statusErr.Code, httperrors.CodeBadRequest)
+		assert.False(t, statusErr.IsRetryable())
+	})
+
+	t.Run("when the error response has no message it should use the status text", func(t *testing.T) {
+		t.Parallel()
+		_, err := client.Do[struct{}, struct{}](context.Background(), c, http.MethodGet, "/teapot", nil)
+		assert.ErrorExact(t, err, "the server responded with status 418 (I'm a teapot)")
+		assert.Equals(t, err.(*client.StatusError).Code, "i_m_a_teapot")
+	})
+
+	t.Run("when the response body fails validation it should return an error", func(t *testing.T) {
+		t.Parallel()
+		_, err := client.Do[getUserParams, updateUserResponse](context.Background(), c, http.MethodGet, "/users/{id}", &getUserParams{ID: "1"})
+		assert.ErrorPart(t, err, "validation failed for the response body")
+	})
+
+	t.Run("when the response has no body it should return a nil response", func(t *testing.T) {
+		t.Parallel()
+		response, err := client.Do[getUserParams, updateUserResponse](context.Background(), c, http.MethodDelete, "/users/{id}", &getUserParams{ID: "1"})
+		assert.NoError(t, err)
+		assert.Nil(t, response)
+	})
+
+	t.Run("when the response is not a struct it should decode it without validation", func(t *testing.T) {
+		t.Parallel()
+		response, err := client.Do[struct{}, []string](context.Background(), c, http.MethodGet, "/names", nil)
+		assert.NoError(t, err)
+		assert.Equals(t, *response, []string{"a", "b"})
+	})
+
+	t.Run("when the response body is not valid JSON it should return an error", func(t *testing.T) {
+		t.Parallel()
+		_, err := client.Do[struct{}, []string](context.Background(), c, http.MethodGet, "/invalid", nil)
+		assert.ErrorPart(t, err, "failed to decode the response body")
+	})
+
+	t.Run("when a path parameter has no value it should return an error", func(t *testing.T) {
+		t.Parallel()
+		_, err := client.Do[getUserParams, updateUserResponse](context.Background(), c, http.MethodGet, "/users/{id}", &getUserParams{})
+		assert.ErrorExact(t, err, "failed to encode the request parameters (the path parameter 'id' has no value)")
+	})
+
+	t.Run("when a path parameter has no field it should return an error", func(t *testing.T) {
+		t.Parallel()
+		_, err := client.Do[getUserParams, updateUserResponse](context.Background(), c, http.MethodGet, "/users/{id}/{other}", &getUserParams{ID: "1"})
+		assert.ErrorExact(t, err, "failed to encode the request parameters (the path parameter 'other' has no field)")
+	})
+
+	t.Run("when a field type cannot be encoded it should return an error", func(t *testing.T) {
+		t.Parallel()
+		_, err := client.Do[unsupportedParams, struct{}](context.Background(), c, http.MethodGet, "/names", &unsupportedParams{Channel: make(chan int)})
+		assert.ErrorExact(t, err, "failed to encode the request parameters (failed to format the query parameter 'channel' (unsupported field type: chan int))")
+	})
+}
@@ -0,0 +1,164 @@
+package codec
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+)
+
+// Codec encodes and decodes the bodies of HTTP requests and responses of a content type.
+type Codec interface {
+	// ContentType is the media type of the bodies, like application/json.
+	ContentType() string
+
+	// Supports returns true if the value can be encoded and decoded by the codec.
+	Supports(value any) bool
+
+	// Encode writes the value to the writer.
+	Encode(writer io.Writer, value any) error
+
+	// Decode reads a value from the reader into the value, which must be a pointer.
+	Decode(reader io.Reader, value any) error
+}
+
+// jsonCodec is the Codec of application/json.
+type jsonCodec struct{}
+
+// JSON returns the Codec of application/json. Decoding fails on fields that are not in the value.
+func JSON() Codec {
+	return jsonCodec{}
+}
+
+// ContentType is application/json.
+func (jsonCodec) ContentType() string {
+	return headers.ContentTypeApplicationJson
+}
+
+// Supports returns true, since any value can be attempted.
+func (jsonCodec) Supports(any) bool {
+	return true
+}
+
+// Encode writes the value as JSON followed by a newline.
+func (jsonCodec) Encode(writer io.Writer, value any) error {
+	return json.NewEncoder(writer).Encode(value)
+}
+
+// Decode reads a JSON document into the value.
+func (jsonCodec) Decode(reader io.Reader, value any) error {
+	decoder := json.NewDecoder(reader)
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(value)
+}
+
+// xmlCodec is the Codec of application/xml.
+type xmlCodec struct{}
+
+// XML returns the Codec of application/xml. The values are encoded with the encoding/xml package,
+// so their fields can be customized with xml struct tags. Maps are not supported.
+func XML() Codec {
+	return xmlCodec{}
+}
+
+// ContentType is application/xml.
+func (xmlCodec) ContentType() string {
+	return headers.ContentTypeApplicationXML
+}
+
+// Supports returns true, since any value can be attempted.
+func (xmlCodec) Supports(any) bool {
+	return true
+}
+
+// Encode writes the XML declaration and the value as an XML element.
+func (xmlCodec) Encode(writer io.Writer, value any) error {
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+	return xml.NewEncoder(writer).Encode(value)
+}
+
+// Decode reads an XML element into the value.
+func (xmlCodec) Decode(reader io.Reader, value any) error {
+	return xml.NewDecoder(reader).Decode(value)
+}
+
+// protobufCodec is the Codec of application/x-protobuf for the messages of type M.
+type protobufCodec[M any] struct {
+	marshal   func(M) ([]byte, error)
+	unmarshal func([]byte, M) error
+}
+
+// NewProtobuf returns the Codec of application/x-protobuf. Since this module does not depend on a protobuf
+// library, the marshal and unmarshal functions of the library are provided by the caller:
+//
+//	codec.NewProtobuf(proto.Marshal, proto.Unmarshal)
+//
+// The codec only supports the values that are of type M, like proto.Message.
+func NewProtobuf[M any](marshal func(M) ([]byte, error), unmarshal func([]byte, M) error) Codec {
+	if marshal == nil || unmarshal == nil {
+		panic("the protobuf marshal and unmarshal functions cannot be nil")
+	}
+	return &protobufCodec[M]{
+		marshal:   marshal,
+		unmarshal: unmarshal,
+	}
+}
+
+// ContentType is application/x-protobuf.
+func (*protobufCodec[M]) ContentType() string {
+	return headers.ContentTypeApplicationProtobuf
+}
+
+// Supports returns true if the value is a message of type M.
+func (*protobufCodec[M]) Supports(value any) bool {
+	_, ok := value.(M)
+	return ok
+}
+
+// Encode writes the wire format of the message.
+func (codec *protobufCodec[M]) Encode(writer io.Writer, value any) error {
+	message, ok := value.(M)
+	if !ok {
+		return fmt.Errorf("the type %T is not a protobuf message", value)
+	}
+	data, err := codec.marshal(message)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(data)
+	return err
+}
+
+// Decode reads the wire format of a message into the value.
+func (codec *protobufCodec[M]) Decode(reader io.Reader, value any) error {
+	message, ok := value.(M)
+	if !ok {
+		return fmt.Errorf("the type %T is not a protobuf message", value)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	return codec.unmarshal(data, message)
+}
+
+// ForContentType returns the codec of the media type of a Content-Type header, ignoring its parameters
+// like the charset. If the header is invalid or no codec matches, nil is returned.
+func ForContentType(contentType string, codecs []Codec) Codec {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil
+	}
+	for _, codec := range codecs {
+		if strings.EqualFold(codec.ContentType(), mediaType) {
+			return codec
+		}
+	}
+	return nil
+}
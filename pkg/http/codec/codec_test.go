@@ -0,0 +1,109 @@
+package codec_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/http/codec"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+type testMessage struct {
+	Name  string `json:"name" xml:"name"`
+	Count int    `json:"count" xml:"count"`
+}
+
+type testProtoMessage struct {
+	data []byte
+}
+
+func TestCodecs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when a value is encoded and decoded with the JSON codec it should round trip", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		assert.NoError(t, codec.JSON().Encode(&buf, &testMessage{Name: "a", Count: 2}))
+		assert.Equals(t, buf.String(), "{\"name\":\"a\",\"count\":2}\n")
+		decoded := &testMessage{}
+		assert.NoError(t, codec.JSON().Decode(&buf, decoded))
+		assert.Equals(t, *decoded, testMessage{Name: "a", Count: 2})
+		assert.Equals(t, codec.JSON().ContentType(), headers.ContentTypeApplicationJson)
+	})
+
+	t.Run("when JSON with an unknown field is decoded it should fail", func(t *testing.T) {
+		t.Parallel()
+		err := codec.JSON().Decode(strings.NewReader(`{"unknown":1}`), &testMessage{})
+		assert.ErrorPart(t, err, "unknown field")
+	})
+
+	t.Run("when a value is encoded and decoded with the XML codec it should round trip", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		assert.NoError(t, codec.XML().Encode(&buf, &testMessage{Name: "a", Count: 2}))
+		assert.True(t, strings.HasPrefix(buf.String(), "<?xml"))
+		assert.True(t, strings.Contains(buf.String(), "<name>a</name><count>2</count>"))
+		decoded := &testMessage{}
+		assert.NoError(t, codec.XML().Decode(&buf, decoded))
+		assert.Equals(t, *decoded, testMessage{Name: "a", Count: 2})
+		assert.Equals(t, codec.XML().ContentType(), headers.ContentTypeApplicationXML)
+	})
+
+	t.Run("when the protobuf codec is created without functions it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			codec.NewProtobuf[*testProtoMessage](nil, nil)
+		}, "cannot be nil")
+	})
+
+	t.Run("when the protobuf codec is used it should call the marshal and unmarshal functions", func(t *testing.T) {
+		t.Parallel()
+		protobuf := codec.NewProtobuf(func(message *testProtoMessage) ([]byte, error) {
+			return message.data, nil
+		}, func(data []byte, message *testProtoMessage) error {
+			message.data = data
+			return nil
+		})
+		assert.Equals(t, protobuf.ContentType(), headers.ContentTypeApplicationProtobuf)
+		assert.True(t, protobuf.Supports(&testProtoMessage{}))
+		assert.False(t, protobuf.Supports(&testMessage{}))
+
+		var buf bytes.Buffer
+		assert.NoError(t, protobuf.Encode(&buf, &testProtoMessage{data: []byte{1, 2, 3}}))
+		decoded := &testProtoMessage{}
+		assert.NoError(t, protobuf.Decode(&buf, decoded))
+		assert.Equals(t, decoded.data, []byte{1, 2, 3})
+	})
+
+	t.Run("when the protobuf codec is used with another type it should fail", func(t *testing.T) {
+		t.Parallel()
+		protobuf := codec.NewProtobuf(func(*testProtoMessage) ([]byte, error) {
+			return nil, nil
+		}, func([]byte, *testProtoMessage) error {
+			return nil
+		})
+		assert.ErrorPart(t, protobuf.Encode(&bytes.Buffer{}, &testMessage{}), "is not a protobuf message")
+		assert.ErrorPart(t, protobuf.Decode(strings.NewReader(""), &testMessage{}), "is not a protobuf message")
+	})
+
+	t.Run("when the protobuf marshal function fails it should return the error", func(t *testing.T) {
+		t.Parallel()
+		protobuf := codec.NewProtobuf(func(*testProtoMessage) ([]byte, error) {
+			return nil, errors.New("marshal failure")
+		}, func([]byte, *testProtoMessage) error {
+			return nil
+		})
+		assert.ErrorPart(t, protobuf.Encode(&bytes.Buffer{}, &testProtoMessage{}), "marshal failure")
+	})
+
+	t.Run("when a codec is looked up by content type it should ignore the parameters and case", func(t *testing.T) {
+		t.Parallel()
+		codecs := []codec.Codec{codec.JSON(), codec.XML()}
+		assert.Equals(t, codec.ForContentType("Application/XML; charset=utf-8", codecs).ContentType(), headers.ContentTypeApplicationXML)
+		assert.Nil(t, codec.ForContentType("text/plain", codecs))
+		assert.Nil(t, codec.ForContentType("invalid;;", codecs))
+	})
+}
@@ -0,0 +1,774 @@
+package codec
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+)
+
+const (
+	// msgpackMaxDepth is the deepest nesting of arrays and maps that is decoded, to bound the recursion.
+	msgpackMaxDepth = 1000
+
+	// msgpackTimestampType is the extension type of timestamps.
+	msgpackTimestampType = -1
+
+	// msgpackMaxPreallocation bounds the capacity allocated for arrays and maps before their elements are read,
+	// so a length in a malicious payload cannot allocate a large amount of memory.
+	msgpackMaxPreallocation = 1024
+)
+
+// msgpackCodec is the Codec of application/msgpack.
+type msgpackCodec struct{}
+
+// MessagePack returns the Codec of application/msgpack.
+//
+// Structs are encoded as maps keyed by the msgpack struct tag of their fields, or their json struct tag if they
+// have none, or their name. The tags support the "-" name and the omitempty option, and embedded structs without
+// a tag have their fields promoted, like with encoding/json. A time.Time is encoded with the timestamp extension.
+// Decoding fails on map keys that are not fields of the struct.
+func MessagePack() Codec {
+	return msgpackCodec{}
+}
+
+// ContentType is application/msgpack.
+func (msgpackCodec) ContentType() string {
+	return headers.ContentTypeApplicationMsgPack
+}
+
+// Supports returns true, since any value can be attempted.
+func (msgpackCodec) Supports(any) bool {
+	return true
+}
+
+// Encode writes the value in the MessagePack format.
+func (msgpackCodec) Encode(writer io.Writer, value any) error {
+	encoder := &msgpackEncoder{}
+	if err := encoder.encode(reflect.ValueOf(value)); err != nil {
+		return err
+	}
+	_, err := writer.Write(encoder.buf.Bytes())
+	return err
+}
+
+// Decode reads a MessagePack value into the value, which must be a non-nil pointer.
+func (msgpackCodec) Decode(reader io.Reader, value any) error {
+	target := reflect.ValueOf(value)
+	if target.Kind() != reflect.Pointer || target.IsNil() {
+		return fmt.Errorf("the decoded value must be a non-nil pointer but got %T", value)
+	}
+	byteReader, ok := reader.(msgpackReader)
+	if !ok {
+		byteReader = bufio.NewReader(reader)
+	}
+	decoder := &msgpackDecoder{reader: byteReader}
+	return decoder.decode(target.Elem(), 0)
+}
+
+// msgpackField is an encoded field of a struct.
+type msgpackField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+// msgpackFieldsCache holds the encoded fields of each struct type.
+var msgpackFieldsCache sync.Map
+
+// msgpackFields returns the encoded fields of the struct type, in declaration order.
+func msgpackFields(structType reflect.Type) []msgpackField {
+	if cached, found := msgpackFieldsCache.Load(structType); found {
+		return cached.([]msgpackField)
+	}
+	fields := make([]msgpackField, 0, structType.NumField())
+	nameToFieldIndex := make(map[string]int)
+	var collect func(structType reflect.Type, index []int)
+	collect = func(structType reflect.Type, index []int) {
+		for i := range structType.NumField() {
+			field := structType.Field(i)
+			tag, hasTag := field.Tag.Lookup("msgpack")
+			if !hasTag {
+				tag, hasTag = field.Tag.Lookup("json")
+			}
+			name, tagOptions, _ := strings.Cut(tag, ",")
+			if name == "-" && tagOptions == "" {
+				continue
+			}
+			fieldIndex := append(append([]int{}, index...), i)
+			if field.Anonymous && name == "" {
+				embeddedType := field.Type
+				if embeddedType.Kind() == reflect.Pointer {
+					embeddedType = embeddedType.Elem()
+				}
+				if embeddedType.Kind() == reflect.Struct {
+					collect(embeddedType, fieldIndex)
+					continue
+				}
+			}
+			if !field.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			newField := msgpackField{
+				name:      name,
+				index:     fieldIndex,
+				omitEmpty: hasTag && strings.Contains(","+tagOptions+",", ",omitempty,"),
+			}
+			// Like with encoding/json, the shallowest field wins when promoted fields have the same name.
+			if existing, duplicate := nameToFieldIndex[name]; duplicate {
+				if len(fields[existing].index) > len(fieldIndex) {
+					fields[existing] = newField
+				}
+				continue
+			}
+			nameToFieldIndex[name] = len(fields)
+			fields = append(fields, newField)
+		}
+	}
+	collect(structType, nil)
+	cached, _ := msgpackFieldsCache.LoadOrStore(structType, fields)
+	return cached.([]msgpackField)
+}
+
+// msgpackEncoder encodes values in a buffer.
+type msgpackEncoder struct {
+	buf bytes.Buffer
+}
+
+// writeHeader writes a format byte followed by a big endian length or value of the size.
+func (encoder *msgpackEncoder) writeHeader(format byte, value uint64, size int) {
+	encoder.buf.WriteByte(format)
+	switch size {
+	case 1:
+		encoder.buf.WriteByte(byte(value))
+	case 2:
+		encoder.buf.Write(binary.BigEndian.AppendUint16(nil, uint16(value)))
+	case 4:
+		encoder.buf.Write(binary.BigEndian.AppendUint32(nil, uint32(value)))
+	case 8:
+		encoder.buf.Write(binary.BigEndian.AppendUint64(nil, value))
+	}
+}
+
+// writeLength writes the header of a string, binary, array, or map of the length.
+// The fix format is used if fixLimit is not zero and the length is below it.
+func (encoder *msgpackEncoder) writeLength(length int, fixFormat byte, fixLimit int, format8 byte, format16 byte, format32 byte) error {
+	switch {
+	case fixLimit != 0 && length < fixLimit:
+		encoder.buf.WriteByte(fixFormat | byte(length))
+	case format8 != 0 && length <= math.MaxUint8:
+		encoder.writeHeader(format8, uint64(length), 1)
+	case length <= math.MaxUint16:
+		encoder.writeHeader(format16, uint64(length), 2)
+	case uint64(length) <= math.MaxUint32:
+		encoder.writeHeader(format32, uint64(length), 4)
+	default:
+		return fmt.Errorf("the length %d exceeds the maximum of the msgpack format", length)
+	}
+	return nil
+}
+
+// encodeUint writes the unsigned integer in its smallest format.
+func (encoder *msgpackEncoder) encodeUint(value uint64) {
+	switch {
+	case value <= 0x7f:
+		encoder.buf.WriteByte(byte(value))
+	case value <= math.MaxUint8:
+		encoder.writeHeader(0xcc, value, 1)
+	case value <= math.MaxUint16:
+		encoder.writeHeader(0xcd, value, 2)
+	case value <= math.MaxUint32:
+		encoder.writeHeader(0xce, value, 4)
+	default:
+		encoder.writeHeader(0xcf, value, 8)
+	}
+}
+
+// encodeInt writes the signed integer in its smallest format.
+func (encoder *msgpackEncoder) encodeInt(value int64) {
+	switch {
+	case value >= 0:
+		encoder.encodeUint(uint64(value))
+	case value >= -32:
+		encoder.buf.WriteByte(byte(value))
+	case value >= math.MinInt8:
+		encoder.writeHeader(0xd0, uint64(uint8(value)), 1)
+	case value >= math.MinInt16:
+		encoder.writeHeader(0xd1, uint64(uint16(value)), 2)
+	case value >= math.MinInt32:
+		encoder.writeHeader(0xd2, uint64(uint32(value)), 4)
+	default:
+		encoder.writeHeader(0xd3, uint64(value), 8)
+	}
+}
+
+// encodeTime writes the time with the timestamp extension in its 96-bit format.
+func (encoder *msgpackEncoder) encodeTime(value time.Time) {
+	// The extension type -1 is written in two's complement.
+	encoder.buf.Write([]byte{0xc7, 12, 0xff})
+	encoder.buf.Write(binary.BigEndian.AppendUint32(nil, uint32(value.Nanosecond())))
+	encoder.buf.Write(binary.BigEndian.AppendUint64(nil, uint64(value.Unix())))
+}
+
+// encode writes the value.
+func (encoder *msgpackEncoder) encode(value reflect.Value) error {
+	if !value.IsValid() {
+		encoder.buf.WriteByte(0xc0)
+		return nil
+	}
+	if value.Type() == reflect.TypeFor[time.Time]() {
+		encoder.encodeTime(value.Interface().(time.Time))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			encoder.buf.WriteByte(0xc0)
+			return nil
+		}
+		return encoder.encode(value.Elem())
+	case reflect.Bool:
+		if value.Bool() {
+			encoder.buf.WriteByte(0xc3)
+		} else {
+			encoder.buf.WriteByte(0xc2)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		encoder.encodeInt(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		encoder.encodeUint(value.Uint())
+	case reflect.Float32:
+		encoder.writeHeader(0xca, uint64(math.Float32bits(float32(value.Float()))), 4)
+	case reflect.Float64:
+		encoder.writeHeader(0xcb, math.Float64bits(value.Float()), 8)
+	case reflect.String:
+		if err := encoder.writeLength(value.Len(), 0xa0, 32, 0xd9, 0xda, 0xdb); err != nil {
+			return err
+		}
+		encoder.buf.WriteString(value.String())
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			encoder.buf.WriteByte(0xc0)
+			return nil
+		}
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			if err := encoder.writeLength(value.Len(), 0, 0, 0xc4, 0xc5, 0xc6); err != nil {
+				return err
+			}
+			for i := range value.Len() {
+				encoder.buf.WriteByte(byte(value.Index(i).Uint()))
+			}
+			return nil
+		}
+		if err := encoder.writeLength(value.Len(), 0x90, 16, 0, 0xdc, 0xdd); err != nil {
+			return err
+		}
+		for i := range value.Len() {
+			if err := encoder.encode(value.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if value.IsNil() {
+			encoder.buf.WriteByte(0xc0)
+			return nil
+		}
+		if err := encoder.writeLength(value.Len(), 0x80, 16, 0, 0xde, 0xdf); err != nil {
+			return err
+		}
+		iter := value.MapRange()
+		for iter.Next() {
+			if err := encoder.encode(iter.Key()); err != nil {
+				return err
+			}
+			if err := encoder.encode(iter.Value()); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		return encoder.encodeStruct(value)
+	default:
+		return fmt.Errorf("the type %s cannot be encoded as msgpack", value.Type())
+	}
+	return nil
+}
+
+// encodeStruct writes the struct as a map of its fields.
+func (encoder *msgpackEncoder) encodeStruct(value reflect.Value) error {
+	fields := msgpackFields(value.Type())
+	fieldValues := make([]reflect.Value, 0, len(fields))
+	fieldNames := make([]string, 0, len(fields))
+	for _, field := range fields {
+		fieldValue, err := value.FieldByIndexErr(field.index)
+		if err != nil {
+			// The field is promoted from a nil embedded pointer.
+			continue
+		}
+		if field.omitEmpty && fieldValue.IsZero() {
+			continue
+		}
+		fieldValues = append(fieldValues, fieldValue)
+		fieldNames = append(fieldNames, field.name)
+	}
+	if err := encoder.writeLength(len(fieldValues), 0x80, 16, 0, 0xde, 0xdf); err != nil {
+		return err
+	}
+	for i, fieldValue := range fieldValues {
+		if err := encoder.encode(reflect.ValueOf(fieldNames[i])); err != nil {
+			return err
+		}
+		if err := encoder.encode(fieldValue); err != nil {
+			return fmt.Errorf("failed to encode the field %s (%w)", fieldNames[i], err)
+		}
+	}
+	return nil
+}
+
+// msgpackReader is the reader of the decoder.
+type msgpackReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+// msgpackKind is the kind of a decoded msgpack value.
+type msgpackKind int
+
+const (
+	msgpackNil msgpackKind = iota
+	msgpackBool
+	msgpackInt
+	msgpackUint
+	msgpackFloat
+	msgpackString
+	msgpackBinary
+	msgpackArray
+	msgpackMap
+	msgpackExtension
+)
+
+// msgpackHeader is the decoded header of a msgpack value. Scalars are fully decoded in the header.
+type msgpackHeader struct {
+	kind      msgpackKind
+	boolean   bool
+	integer   int64
+	unsigned  uint64
+	float     float64
+	length    int
+	extension int8
+}
+
+// msgpackDecoder decodes values from a reader.
+type msgpackDecoder struct {
+	reader msgpackReader
+}
+
+// readUint reads a big endian unsigned integer of the size in bytes.
+func (decoder *msgpackDecoder) readUint(size int) (uint64, error) {
+	var buf [8]byte
+	if _, err := io.ReadFull(decoder.reader, buf[8-size:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(buf[:]), nil
+}
+
+// readBytes reads the number of bytes. The buffer grows as the bytes are read, so a large length
+// that is not followed by the bytes does not allocate its size.
+func (decoder *msgpackDecoder) readBytes(length int) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, decoder.reader, int64(length)); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// readLength reads a length of the size in bytes.
+func (decoder *msgpackDecoder) readLength(size int) (int, error) {
+	length, err := decoder.readUint(size)
+	if err != nil {
+		return 0, err
+	}
+	if length > math.MaxInt32 {
+		return 0, fmt.Errorf("the msgpack length %d is too large", length)
+	}
+	return int(length), nil
+}
+
+// readHeader reads the format of the next value and its scalar value or length.
+func (decoder *msgpackDecoder) readHeader() (msgpackHeader, error) {
+	format, err := decoder.reader.ReadByte()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return msgpackHeader{}, io.ErrUnexpectedEOF
+		}
+		return msgpackHeader{}, err
+	}
+
+	header := msgpackHeader{}
+	lengthSize := 0
+	switch {
+	case format <= 0x7f:
+		return msgpackHeader{kind: msgpackUint, unsigned: uint64(format)}, nil
+	case format >= 0xe0:
+		return msgpackHeader{kind: msgpackInt, integer: int64(int8(format))}, nil
+	case format >= 0x80 && format <= 0x8f:
+		return msgpackHeader{kind: msgpackMap, length: int(format & 0x0f)}, nil
+	case format >= 0x90 && format <= 0x9f:
+		return msgpackHeader{kind: msgpackArray, length: int(format & 0x0f)}, nil
+	case format >= 0xa0 && format <= 0xbf:
+		return msgpackHeader{kind: msgpackString, length: int(format & 0x1f)}, nil
+	}
+
+	switch format {
+	case 0xc0:
+		return msgpackHeader{kind: msgpackNil}, nil
+	case 0xc2, 0xc3:
+		return msgpackHeader{kind: msgpackBool, boolean: format == 0xc3}, nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		header.kind = msgpackUint
+		header.unsigned, err = decoder.readUint(1 << (format - 0xcc))
+		return header, err
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (format - 0xd0)
+		unsigned, err := decoder.readUint(size)
+		if err != nil {
+			return header, err
+		}
+		shift := 64 - 8*size
+		return msgpackHeader{kind: msgpackInt, integer: int64(unsigned<<shift) >> shift}, nil
+	case 0xca:
+		bits, err := decoder.readUint(4)
+		return msgpackHeader{kind: msgpackFloat, float: float64(math.Float32frombits(uint32(bits)))}, err
+	case 0xcb:
+		bits, err := decoder.readUint(8)
+		return msgpackHeader{kind: msgpackFloat, float: math.Float64frombits(bits)}, err
+	case 0xd9, 0xda, 0xdb:
+		header.kind, lengthSize = msgpackString, 1<<(format-0xd9)
+	case 0xc4, 0xc5, 0xc6:
+		header.kind, lengthSize = msgpackBinary, 1<<(format-0xc4)
+	case 0xdc, 0xdd:
+		header.kind, lengthSize = msgpackArray, 2<<(format-0xdc)
+	case 0xde, 0xdf:
+		header.kind, lengthSize = msgpackMap, 2<<(format-0xde)
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		header.kind, header.length = msgpackExtension, 1<<(format-0xd4)
+	case 0xc7, 0xc8, 0xc9:
+		header.kind, lengthSize = msgpackExtension, 1<<(format-0xc7)
+	default:
+		return header, fmt.Errorf("the msgpack format 0x%x is not valid", format)
+	}
+
+	if lengthSize != 0 {
+		if header.length, err = decoder.readLength(lengthSize); err != nil {
+			return header, err
+		}
+	}
+	if header.kind == msgpackExtension {
+		extension, err := decoder.reader.ReadByte()
+		if err != nil {
+			return header, err
+		}
+		header.extension = int8(extension)
+	}
+	return header, nil
+}
+
+// readTime reads the body of a timestamp extension of the length.
+func (decoder *msgpackDecoder) readTime(length int) (time.Time, error) {
+	switch length {
+	case 4:
+		seconds, err := decoder.readUint(4)
+		return time.Unix(int64(seconds), 0).UTC(), err
+	case 8:
+		data, err := decoder.readUint(8)
+		return time.Unix(int64(data&0x3ffffffff), int64(data>>34)).UTC(), err
+	case 12:
+		nanoseconds, err := decoder.readUint(4)
+		if err != nil {
+			return time.Time{}, err
+		}
+		seconds, err := decoder.readUint(8)
+		return time.Unix(int64(seconds), int64(nanoseconds)).UTC(), err
+	default:
+		return time.Time{}, fmt.Errorf("the msgpack timestamp length %d is not valid", length)
+	}
+}
+
+// decode reads the next value into the target.
+func (decoder *msgpackDecoder) decode(target reflect.Value, depth int) error {
+	if depth > msgpackMaxDepth {
+		return fmt.Errorf("the msgpack value is nested deeper than %d levels", msgpackMaxDepth)
+	}
+	header, err := decoder.readHeader()
+	if err != nil {
+		return err
+	}
+	return decoder.decodeValue(header, target, depth)
+}
+
+// decodeValue reads the rest of the value of the header into the target.
+func (decoder *msgpackDecoder) decodeValue(header msgpackHeader, target reflect.Value, depth int) error {
+	if header.kind == msgpackNil {
+		target.SetZero()
+		return nil
+	}
+	if target.Kind() == reflect.Pointer {
+		if target.IsNil() {
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+		return decoder.decodeValue(header, target.Elem(), depth)
+	}
+	if target.Kind() == reflect.Interface && target.NumMethod() == 0 {
+		generic, err := decoder.decodeGeneric(header, depth)
+		if err != nil {
+			return err
+		}
+		if generic == nil {
+			target.SetZero()
+		} else {
+			target.Set(reflect.ValueOf(generic))
+		}
+		return nil
+	}
+
+	mismatch := func() error {
+		return fmt.Errorf("cannot decode a msgpack value into the type %s", target.Type())
+	}
+	switch header.kind {
+	case msgpackBool:
+		if target.Kind() != reflect.Bool {
+			return mismatch()
+		}
+		target.SetBool(header.boolean)
+	case msgpackInt, msgpackUint:
+		return decodeInteger(header, target)
+	case msgpackFloat:
+		if target.Kind() != reflect.Float32 && target.Kind() != reflect.Float64 {
+			return mismatch()
+		}
+		target.SetFloat(header.float)
+	case msgpackString, msgpackBinary:
+		data, err := decoder.readBytes(header.length)
+		if err != nil {
+			return err
+		}
+		switch {
+		case target.Kind() == reflect.String:
+			target.SetString(string(data))
+		case target.Kind() == reflect.Slice && target.Type().Elem().Kind() == reflect.Uint8:
+			target.SetBytes(data)
+		default:
+			return mismatch()
+		}
+	case msgpackArray:
+		return decoder.decodeArray(header.length, target, depth)
+	case msgpackMap:
+		switch target.Kind() {
+		case reflect.Map:
+			return decoder.decodeMap(header.length, target, depth)
+		case reflect.Struct:
+			return decoder.decodeStruct(header.length, target, depth)
+		default:
+			return mismatch()
+		}
+	case msgpackExtension:
+		if header.extension != msgpackTimestampType {
+			return fmt.Errorf("the msgpack extension type %d is not supported", header.extension)
+		}
+		if target.Type() != reflect.TypeFor[time.Time]() {
+			return mismatch()
+		}
+		timestamp, err := decoder.readTime(header.length)
+		if err != nil {
+			return err
+		}
+		target.Set(reflect.ValueOf(timestamp))
+	}
+	return nil
+}
+
+// decodeInteger sets the integer of the header in the target, checking for overflows.
+func decodeInteger(header msgpackHeader, target reflect.Value) error {
+	overflow := func() error {
+		return fmt.Errorf("the msgpack integer overflows the type %s", target.Type())
+	}
+	switch target.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value := header.integer
+		if header.kind == msgpackUint {
+			if header.unsigned > math.MaxInt64 {
+				return overflow()
+			}
+			value = int64(header.unsigned)
+		}
+		if target.OverflowInt(value) {
+			return overflow()
+		}
+		target.SetInt(value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		value := header.unsigned
+		if header.kind == msgpackInt {
+			if header.integer < 0 {
+				return overflow()
+			}
+			value = uint64(header.integer)
+		}
+		if target.OverflowUint(value) {
+			return overflow()
+		}
+		target.SetUint(value)
+	case reflect.Float32, reflect.Float64:
+		if header.kind == msgpackInt {
+			target.SetFloat(float64(header.integer))
+		} else {
+			target.SetFloat(float64(header.unsigned))
+		}
+	default:
+		return fmt.Errorf("cannot decode a msgpack value into the type %s", target.Type())
+	}
+	return nil
+}
+
+// decodeArray reads the elements of an array into a slice or array target.
+func (decoder *msgpackDecoder) decodeArray(length int, target reflect.Value, depth int) error {
+	switch target.Kind() {
+	case reflect.Slice:
+		slice := reflect.MakeSlice(target.Type(), 0, min(length, msgpackMaxPreallocation))
+		for range length {
+			element := reflect.New(target.Type().Elem()).Elem()
+			if err := decoder.decode(element, depth+1); err != nil {
+				return err
+			}
+			slice = reflect.Append(slice, element)
+		}
+		target.Set(slice)
+	case reflect.Array:
+		if length != target.Len() {
+			return fmt.Errorf("cannot decode a msgpack array of length %d into the type %s", length, target.Type())
+		}
+		for i := range length {
+			if err := decoder.decode(target.Index(i), depth+1); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("cannot decode a msgpack value into the type %s", target.Type())
+	}
+	return nil
+}
+
+// decodeMap reads the entries of a map into a map target.
+func (decoder *msgpackDecoder) decodeMap(length int, target reflect.Value, depth int) error {
+	result := reflect.MakeMapWithSize(target.Type(), min(length, msgpackMaxPreallocation))
+	for range length {
+		key := reflect.New(target.Type().Key()).Elem()
+		if err := decoder.decode(key, depth+1); err != nil {
+			return err
+		}
+		value := reflect.New(target.Type().Elem()).Elem()
+		if err := decoder.decode(value, depth+1); err != nil {
+			return err
+		}
+		result.SetMapIndex(key, value)
+	}
+	target.Set(result)
+	return nil
+}
+
+// decodeStruct reads the entries of a map into the fields of a struct target.
+func (decoder *msgpackDecoder) decodeStruct(length int, target reflect.Value, depth int) error {
+	fields := msgpackFields(target.Type())
+	for range length {
+		var name string
+		if err := decoder.decode(reflect.ValueOf(&name).Elem(), depth+1); err != nil {
+			return fmt.Errorf("failed to decode a field name of the type %s (%w)", target.Type(), err)
+		}
+		fieldIndex := -1
+		for i, field := range fields {
+			if field.name == name {
+				fieldIndex = i
+				break
+			}
+		}
+		if fieldIndex == -1 {
+			return fmt.Errorf("the type %s has no field %s", target.Type(), name)
+		}
+		field, err := fieldByIndexAlloc(target, fields[fieldIndex].index)
+		if err != nil {
+			return err
+		}
+		if err := decoder.decode(field, depth+1); err != nil {
+			return fmt.Errorf("failed to decode the field %s (%w)", name, err)
+		}
+	}
+	return nil
+}
+
+// fieldByIndexAlloc returns the nested field, allocating the embedded pointers on the way.
+func fieldByIndexAlloc(value reflect.Value, index []int) (reflect.Value, error) {
+	for i, fieldIndex := range index {
+		if i > 0 && value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				if !value.CanSet() {
+					return reflect.Value{}, fmt.Errorf("cannot set the embedded pointer %s", value.Type())
+				}
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+			value = value.Elem()
+		}
+		value = value.Field(fieldIndex)
+	}
+	return value, nil
+}
+
+// decodeGeneric reads the rest of the value of the header as a nil, bool, int64, uint64 for integers that do not
+// fit in an int64, float64, string, []byte, []any, map[string]any, or time.Time.
+func (decoder *msgpackDecoder) decodeGeneric(header msgpackHeader, depth int) (any, error) {
+	switch header.kind {
+	case msgpackNil:
+		return nil, nil
+	case msgpackBool:
+		return header.boolean, nil
+	case msgpackInt:
+		return header.integer, nil
+	case msgpackUint:
+		if header.unsigned > math.MaxInt64 {
+			return header.unsigned, nil
+		}
+		return int64(header.unsigned), nil
+	case msgpackFloat:
+		return header.float, nil
+	case msgpackString:
+		data, err := decoder.readBytes(header.length)
+		return string(data), err
+	case msgpackBinary:
+		return decoder.readBytes(header.length)
+	case msgpackArray:
+		var slice []any
+		err := decoder.decodeArray(header.length, reflect.ValueOf(&slice).Elem(), depth)
+		return slice, err
+	case msgpackMap:
+		var generic map[string]any
+		err := decoder.decodeMap(header.length, reflect.ValueOf(&generic).Elem(), depth)
+		return generic, err
+	default:
+		var timestamp time.Time
+		err := decoder.decodeValue(header, reflect.ValueOf(&timestamp).Elem(), depth)
+		return timestamp, err
+	}
+}
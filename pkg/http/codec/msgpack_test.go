@@ -0,0 +1,101 @@
+package codec_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/http/codec"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+type testMsgPackEmbedded struct {
+	Embedded string `msgpack:"embedded"`
+}
+
+type testMsgPackValue struct {
+	testMsgPackEmbedded
+	Name      string            `msgpack:"name"`
+	Count     int64             `json:"count"`
+	Unsigned  uint16            `msgpack:"unsigned"`
+	Ratio     float64           `msgpack:"ratio"`
+	Enabled   bool              `msgpack:"enabled"`
+	Tags      []string          `msgpack:"tags"`
+	Labels    map[string]string `msgpack:"labels"`
+	Data      []byte            `msgpack:"data"`
+	Timestamp time.Time         `msgpack:"timestamp"`
+	Pointer   *int              `msgpack:"pointer"`
+	Omitted   string            `msgpack:"omitted,omitempty"`
+	Skipped   string            `msgpack:"-"`
+}
+
+func TestMessagePackCodec(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when a struct is encoded and decoded it should round trip", func(t *testing.T) {
+		t.Parallel()
+		pointed := 7
+		original := &testMsgPackValue{
+			testMsgPackEmbedded: testMsgPackEmbedded{Embedded: "inner"},
+			Name:                "name",
+			Count:               -70000,
+			Unsigned:            300,
+			Ratio:               1.5,
+			Enabled:             true,
+			Tags:                []string{"a", "b"},
+			Labels:              map[string]string{"key": "value"},
+			Data:                []byte{0, 1, 2},
+			Timestamp:           time.Unix(1700000000, 5).UTC(),
+			Pointer:             &pointed,
+			Skipped:             "skipped",
+		}
+		var buf bytes.Buffer
+		assert.NoError(t, codec.MessagePack().Encode(&buf, original))
+		decoded := &testMsgPackValue{}
+		assert.NoError(t, codec.MessagePack().Decode(&buf, decoded))
+		original.Skipped = ""
+		assert.Equals(t, decoded, original)
+	})
+
+	t.Run("when a small positive integer is encoded it should use the fixint format", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		assert.NoError(t, codec.MessagePack().Encode(&buf, 5))
+		assert.Equals(t, buf.Bytes(), []byte{0x05})
+	})
+
+	t.Run("when a value is decoded into an interface it should use the generic types", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		assert.NoError(t, codec.MessagePack().Encode(&buf, map[string]any{"number": 3, "list": []any{"x", true}}))
+		var decoded any
+		assert.NoError(t, codec.MessagePack().Decode(&buf, &decoded))
+		assert.Equals(t, decoded, any(map[string]any{"number": int64(3), "list": []any{"x", true}}))
+	})
+
+	t.Run("when a map has a key that is not a field of the struct it should fail to decode", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		assert.NoError(t, codec.MessagePack().Encode(&buf, map[string]any{"unknown": 1}))
+		assert.ErrorPart(t, codec.MessagePack().Decode(&buf, &testMsgPackValue{}), "has no field unknown")
+	})
+
+	t.Run("when the decoded value is not a pointer it should fail", func(t *testing.T) {
+		t.Parallel()
+		err := codec.MessagePack().Decode(bytes.NewReader([]byte{0x05}), testMsgPackValue{})
+		assert.ErrorPart(t, err, "must be a non-nil pointer")
+	})
+
+	t.Run("when the data is truncated it should fail to decode", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		assert.NoError(t, codec.MessagePack().Encode(&buf, "a longer string"))
+		var decoded string
+		assert.Error(t, codec.MessagePack().Decode(bytes.NewReader(buf.Bytes()[:4]), &decoded))
+	})
+
+	t.Run("when a channel is encoded it should fail", func(t *testing.T) {
+		t.Parallel()
+		assert.Error(t, codec.MessagePack().Encode(&bytes.Buffer{}, make(chan int)))
+	})
+}
@@ -0,0 +1,101 @@
+package codec
+
+import (
+	"strconv"
+	"strings"
+)
+
+// mediaRange is a media range of an Accept header with its quality.
+type mediaRange struct {
+	mainType string
+	subType  string
+	quality  float64
+}
+
+// specificity ranks how precisely the media range matches the content type. A negative value means it does not
+// match. The most specific matching range of an Accept header sets the quality of a content type.
+func (r mediaRange) specificity(mainType string, subType string) int {
+	switch {
+	case r.mainType == "*" && r.subType == "*":
+		return 0
+	case r.mainType == mainType && r.subType == "*":
+		return 1
+	case r.mainType == mainType && r.subType == subType:
+		return 2
+	default:
+		return -1
+	}
+}
+
+// parseAccept parses the media ranges of an Accept header. Invalid media ranges are skipped,
+// and invalid or missing quality values are treated as 1.
+func parseAccept(accept string) []mediaRange {
+	ranges := make([]mediaRange, 0)
+	for part := range strings.SplitSeq(accept, ",") {
+		params := strings.Split(part, ";")
+		mainType, subType, found := strings.Cut(strings.ToLower(strings.TrimSpace(params[0])), "/")
+		if !found || mainType == "" || subType == "" || (mainType == "*" && subType != "*") {
+			continue
+		}
+		quality := 1.0
+		for _, param := range params[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if !strings.EqualFold(strings.TrimSpace(key), "q") {
+				continue
+			}
+			if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil && parsed >= 0 && parsed <= 1 {
+				quality = parsed
+			}
+		}
+		ranges = append(ranges, mediaRange{mainType: mainType, subType: subType, quality: quality})
+	}
+	return ranges
+}
+
+// quality returns the quality of the content type in the media ranges, which is the quality of the most
+// specific range that matches it, or zero if none do.
+func quality(ranges []mediaRange, contentType string) float64 {
+	mainType, subType, _ := strings.Cut(strings.ToLower(contentType), "/")
+	bestSpecificity := -1
+	bestQuality := 0.0
+	for _, r := range ranges {
+		if specificity := r.specificity(mainType, subType); specificity > bestSpecificity {
+			bestSpecificity = specificity
+			bestQuality = r.quality
+		}
+	}
+	return bestQuality
+}
+
+// Negotiate selects the codec of the response from the Accept header of the request. Only the codecs that
+// support the value are considered. The codec with the highest quality is selected, and ties are broken by
+// preferring the default codec, then the order of the codecs. If the Accept header is empty, the default codec
+// is selected. If no codec is acceptable, false is returned.
+func Negotiate(accept string, codecs []Codec, defaultCodec Codec, value any) (Codec, bool) {
+	if strings.TrimSpace(accept) == "" {
+		if defaultCodec != nil && defaultCodec.Supports(value) {
+			return defaultCodec, true
+		}
+		accept = "*/*"
+	}
+	ranges := parseAccept(accept)
+
+	candidates := make([]Codec, 0, len(codecs)+1)
+	if defaultCodec != nil {
+		candidates = append(candidates, defaultCodec)
+	}
+	candidates = append(candidates, codecs...)
+
+	var selected Codec
+	selectedQuality := 0.0
+	for _, candidate := range candidates {
+		if !candidate.Supports(value) {
+			continue
+		}
+		if candidateQuality := quality(ranges, candidate.ContentType()); candidateQuality > selectedQuality {
+			selected = candidate
+			selectedQuality = candidateQuality
+		}
+	}
+	return selected, selected != nil
+}
@@ -0,0 +1,108 @@
+package codec_test
+
+import (
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/http/codec"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestNegotiate(t *testing.T) {
+	t.Parallel()
+
+	codecs := []codec.Codec{codec.XML(), codec.MessagePack()}
+	protobuf := codec.NewProtobuf(func(*testProtoMessage) ([]byte, error) {
+		return nil, nil
+	}, func([]byte, *testProtoMessage) error {
+		return nil
+	})
+
+	testCases := []struct {
+		name        string
+		accept      string
+		codecs      []codec.Codec
+		value       any
+		contentType string
+	}{
+		{
+			name:        "when the Accept header is empty it should select the default codec",
+			accept:      "",
+			codecs:      codecs,
+			value:       &testMessage{},
+			contentType: headers.ContentTypeApplicationJson,
+		},
+		{
+			name:        "when the Accept header lists a single type it should select its codec",
+			accept:      "application/xml",
+			codecs:      codecs,
+			value:       &testMessage{},
+			contentType: headers.ContentTypeApplicationXML,
+		},
+		{
+			name:        "when the Accept header has quality values it should select the highest",
+			accept:      "application/json;q=0.5, application/msgpack;q=0.9, application/xml;q=0.1",
+			codecs:      codecs,
+			value:       &testMessage{},
+			contentType: headers.ContentTypeApplicationMsgPack,
+		},
+		{
+			name:        "when the Accept header is a wildcard it should prefer the default codec",
+			accept:      "*/*",
+			codecs:      codecs,
+			value:       &testMessage{},
+			contentType: headers.ContentTypeApplicationJson,
+		},
+		{
+			name:        "when a specific range excludes a type matched by a wildcard it should not select it",
+			accept:      "application/*, application/json;q=0",
+			codecs:      codecs,
+			value:       &testMessage{},
+			contentType: headers.ContentTypeApplicationXML,
+		},
+		{
+			name:        "when the default codec does not support the value it should select another codec",
+			accept:      "",
+			codecs:      []codec.Codec{protobuf},
+			value:       &testProtoMessage{},
+			contentType: headers.ContentTypeApplicationJson,
+		},
+		{
+			name:        "when protobuf is requested for a message it should select the protobuf codec",
+			accept:      "application/x-protobuf, application/json;q=0.5",
+			codecs:      []codec.Codec{protobuf},
+			value:       &testProtoMessage{},
+			contentType: headers.ContentTypeApplicationProtobuf,
+		},
+		{
+			name:        "when protobuf is requested for a value that is not a message it should not select it",
+			accept:      "application/x-protobuf, application/json;q=0.5",
+			codecs:      []codec.Codec{protobuf},
+			value:       &testMessage{},
+			contentType: headers.ContentTypeApplicationJson,
+		},
+		{
+			name:        "when the quality value is invalid it should be treated as 1",
+			accept:      "application/xml;q=abc, application/json;q=0.9",
+			codecs:      codecs,
+			value:       &testMessage{},
+			contentType: headers.ContentTypeApplicationXML,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			selected, ok := codec.Negotiate(testCase.accept, testCase.codecs, codec.JSON(), testCase.value)
+			assert.True(t, ok)
+			assert.Equals(t, selected.ContentType(), testCase.contentType)
+		})
+	}
+
+	t.Run("when no codec is acceptable it should return false", func(t *testing.T) {
+		t.Parallel()
+		selected, ok := codec.Negotiate("text/html", codecs, codec.JSON(), &testMessage{})
+		assert.False(t, ok)
+		assert.Nil(t, selected)
+	})
+}
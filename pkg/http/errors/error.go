@@ -1,6 +1,118 @@
 package errors
 
+import (
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+)
+
+const (
+	// DefaultMessageFieldName is the JSON field name of the error message unless changed with SetMessageFieldName.
+	DefaultMessageFieldName = "message"
+)
+
+var (
+	// messageFieldName is the JSON field name used to serialize the Message of an Error.
+	messageFieldName atomic.Value
+)
+
+// init sets the default message field name.
+func init() {
+	messageFieldName.Store(DefaultMessageFieldName)
+}
+
+// SetMessageFieldName changes the JSON field name used for the message of an Error.
+// This allows the error response to match the conventions of an API, such as "error" or "detail".
+// It panics if the name is empty, or if it is the name of another field of the error, like "code".
+func SetMessageFieldName(name string) {
+	if name == "" {
+		panic("the error message field name cannot be empty")
+	}
+	if name == codeFieldName || name == retryableFieldName || name == fieldsFieldName {
+		panic(fmt.Sprintf("the error message field name cannot be '%s' since it is the name of another field", name))
+	}
+	messageFieldName.Store(name)
+}
+
+// MessageFieldName returns the JSON field name used for the message of an Error.
+func MessageFieldName() string {
+	return messageFieldName.Load().(string)
+}
+
+const (
+	// codeFieldName is the JSON field name of the Code of an Error.
+	codeFieldName = "code"
+
+	// retryableFieldName is the JSON field name of the Retryable hint of an Error.
+	retryableFieldName = "retryable"
+
+	// fieldsFieldName is the JSON field name of the Fields of an Error.
+	fieldsFieldName = "fields"
+)
+
+// FieldError describes a request field that failed its validation rules. The Value is omitted from the JSON when nil.
+type FieldError struct {
+	Field   string `json:"field"`
+	Tag     string `json:"tag"`
+	Value   any    `json:"value,omitempty"`
+	Message string `json:"message"`
+}
+
 // Error is the standard JSON response an API endpoint makes when an error occurs in the endpoint handler.
+// The Code is a machine-readable name of the failure class, like not_found, and the Retryable hint tells the client
+// whether the request may succeed if it is sent again. They are omitted from the JSON when empty or false.
+// The Fields list the request fields that failed their validation rules, and are omitted from the JSON when empty.
 type Error struct {
-	Message string `json:"message"`
+	Message   string
+	Code      string
+	Retryable bool
+	Fields    []FieldError
+}
+
+// MarshalJSON encodes the Error using the configured message field name.
+func (e Error) MarshalJSON() ([]byte, error) {
+	fields := map[string]any{
+		MessageFieldName(): e.Message,
+	}
+	if e.Code != "" {
+		fields[codeFieldName] = e.Code
+	}
+	if e.Retryable {
+		fields[retryableFieldName] = true
+	}
+	if len(e.Fields) > 0 {
+		fields[fieldsFieldName] = e.Fields
+	}
+	return json.Marshal(fields)
+}
+
+// UnmarshalJSON decodes the Error using the configured message field name.
+func (e *Error) UnmarshalJSON(data []byte) error {
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	rawMessage, found := fields[MessageFieldName()]
+	if !found {
+		return fmt.Errorf("the error is missing the field '%s'", MessageFieldName())
+	}
+	if err := json.Unmarshal(rawMessage, &e.Message); err != nil {
+		return err
+	}
+	if rawCode, found := fields[codeFieldName]; found {
+		if err := json.Unmarshal(rawCode, &e.Code); err != nil {
+			return err
+		}
+	}
+	if rawRetryable, found := fields[retryableFieldName]; found {
+		if err := json.Unmarshal(rawRetryable, &e.Retryable); err != nil {
+			return err
+		}
+	}
+	if rawFields, found := fields[fieldsFieldName]; found {
+		if err := json.Unmarshal(rawFields, &e.Fields); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,96 @@
+package errors_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestError(t *testing.T) {
+	t.Run("when the error is serialized it should use the default message field name", func(t *testing.T) {
+		encoded, err := json.Marshal(errors.Error{Message: "msg"})
+		assert.NoError(t, err)
+		assert.Equals(t, string(encoded), `{"message":"msg"}`)
+		decoded := &errors.Error{}
+		assert.NoError(t, json.Unmarshal(encoded, decoded))
+		assert.Equals(t, decoded.Message, "msg")
+	})
+
+	t.Run("when the message field name is customized it should appear in the serialized error", func(t *testing.T) {
+		errors.SetMessageFieldName("detail")
+		t.Cleanup(func() {
+			errors.SetMessageFieldName(errors.DefaultMessageFieldName)
+		})
+		assert.Equals(t, errors.MessageFieldName(), "detail")
+		encoded, err := json.Marshal(&errors.Error{Message: "msg"})
+		assert.NoError(t, err)
+		assert.Equals(t, string(encoded), `{"detail":"msg"}`)
+		decoded := &errors.Error{}
+		assert.NoError(t, json.Unmarshal(encoded, decoded))
+		assert.Equals(t, decoded.Message, "msg")
+	})
+
+	t.Run("when the error has a code and is retryable they should be serialized", func(t *testing.T) {
+		encoded, err := json.Marshal(errors.Error{Message: "msg", Code: errors.CodeServiceUnavailable, Retryable: true})
+		assert.NoError(t, err)
+		assert.Equals(t, string(encoded), `{"code":"service_unavailable","message":"msg","retryable":true}`)
+		decoded := &errors.Error{}
+		assert.NoError(t, json.Unmarshal(encoded, decoded))
+		assert.Equals(t, *decoded, errors.Error{Message: "msg", Code: errors.CodeServiceUnavailable, Retryable: true})
+	})
+
+	t.Run("when the error has field errors they should be serialized", func(t *testing.T) {
+		httpErr := errors.Error{
+			Message: "msg",
+			Fields: []errors.FieldError{
+				{Field: "Name", Tag: "required", Value: "", Message: "name is required"},
+				{Field: "Age", Tag: "gte", Value: float64(-1), Message: "age must be positive"},
+			},
+		}
+		encoded, err := json.Marshal(httpErr)
+		assert.NoError(t, err)
+		assert.Equals(t, string(encoded), `{"fields":[`+
+			`{"field":"Name","tag":"required","value":"","message":"name is required"},`+
+			`{"field":"Age","tag":"gte","value":-1,"message":"age must be positive"}],"message":"msg"}`)
+		decoded := &errors.Error{}
+		assert.NoError(t, json.Unmarshal(encoded, decoded))
+		assert.Equals(t, *decoded, httpErr)
+	})
+
+	t.Run("when the field errors have the wrong type it should fail to deserialize", func(t *testing.T) {
+		decoded := &errors.Error{}
+		assert.Error(t, json.Unmarshal([]byte(`{"message":"msg","fields":{}}`), decoded))
+	})
+
+	t.Run("when the code has the wrong type it should fail to deserialize", func(t *testing.T) {
+		decoded := &errors.Error{}
+		assert.Error(t, json.Unmarshal([]byte(`{"message":"msg","code":1}`), decoded))
+	})
+
+	t.Run("when the message field name is empty it should panic", func(t *testing.T) {
+		assert.PanicPart(t, func() {
+			errors.SetMessageFieldName("")
+		}, "the error message field name cannot be empty")
+	})
+
+	t.Run("when the message field name is the name of another field it should panic", func(t *testing.T) {
+		for _, name := range []string{"code", "retryable", "fields"} {
+			assert.PanicExact(t, func() {
+				errors.SetMessageFieldName(name)
+			}, "the error message field name cannot be '"+name+"' since it is the name of another field")
+		}
+		assert.Equals(t, errors.MessageFieldName(), errors.DefaultMessageFieldName)
+	})
+
+	t.Run("when the serialized error is missing the message field it should fail to decode", func(t *testing.T) {
+		decoded := &errors.Error{}
+		assert.ErrorPart(t, json.Unmarshal([]byte(`{"error":"msg"}`), decoded), "the error is missing the field 'message'")
+	})
+
+	t.Run("when the serialized error is not an object it should fail to decode", func(t *testing.T) {
+		decoded := &errors.Error{}
+		assert.Error(t, json.Unmarshal([]byte(`"msg"`), decoded))
+	})
+}
@@ -0,0 +1,97 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// mapping converts the errors that match a type to a status and a message.
+type mapping func(err error) (status int, message string, matched error)
+
+var (
+	// mappingsMu guards the mappings and the mapped types.
+	mappingsMu sync.RWMutex
+
+	// mappings are the registered mappings in the order they were registered.
+	mappings []mapping
+
+	// mappedTypes are the error types that have a mapping.
+	mappedTypes = make(map[reflect.Type]struct{})
+
+	// typeMappings are the mappings of the errors whose type is exactly the type of the key.
+	typeMappings = make(map[reflect.Type]func(err error) (status int, message string))
+)
+
+// MustRegisterMapping makes the Error responder answer the errors that match the type T with errors.As with the
+// status and the message returned by the callback. This allows application error types to be mapped to responses
+// without wrapping them in the types of this package. The callback returns a message that is safe to send to the
+// client, so the details of the error can be kept private.
+//
+//	errors.MustRegisterMapping(http.StatusNotFound, func(err *store.NotFoundError) string {
+//	    return "the resource does not exist"
+//	})
+//
+// The mappings are tried in the order they were registered, after the mappings of MustRegisterTypeMapping and
+// before the types of this package. It panics if the status is not a 4xx or 5xx status, if the callback is nil,
+// or if the type already has a mapping. It is meant to be called during initialization.
+func MustRegisterMapping[T error](status int, message func(err T) string) {
+	if status < 400 || status > 599 {
+		panic(fmt.Sprintf("the status of an error mapping must be between 400 and 599 but is %d", status))
+	}
+	if message == nil {
+		panic("the message callback of an error mapping cannot be nil")
+	}
+	mappingsMu.Lock()
+	defer mappingsMu.Unlock()
+	errorType := reflect.TypeFor[T]()
+	if _, found := mappedTypes[errorType]; found {
+		panic(fmt.Sprintf("the error type %s already has a mapping", errorType))
+	}
+	mappedTypes[errorType] = struct{}{}
+	mappings = append(mappings, func(err error) (int, string, error) {
+		var target T
+		if !errors.As(err, &target) {
+			return 0, "", nil
+		}
+		return status, message(target), target
+	})
+}
+
+// MustRegisterTypeMapping makes the Error responder answer the errors whose type is exactly the error type with the
+// status and the message returned by the callback. Unlike MustRegisterMapping, wrapped errors do not match and the
+// status is not checked, which is how responders.MustRegisterErrorResponse has always matched the errors. These
+// mappings are tried before the others. It panics if the callback is nil, or if the type already has a mapping.
+// It is meant to be called during initialization.
+func MustRegisterTypeMapping(errorType reflect.Type, status int, message func(err error) string) {
+	if message == nil {
+		panic("the message callback of an error mapping cannot be nil")
+	}
+	mappingsMu.Lock()
+	defer mappingsMu.Unlock()
+	if _, found := mappedTypes[errorType]; found {
+		panic(fmt.Sprintf("the error type %s already has a mapping", errorType))
+	}
+	mappedTypes[errorType] = struct{}{}
+	typeMappings[errorType] = func(err error) (int, string) {
+		return status, message(err)
+	}
+}
+
+// MapError returns the status and message of the first registered mapping that matches the error, along with the
+// error of the tree that matched it, which decided the status. It returns a nil error if no mapping matches.
+func MapError(err error) (int, string, error) {
+	mappingsMu.RLock()
+	defer mappingsMu.RUnlock()
+	if typeMapping, found := typeMappings[reflect.TypeOf(err)]; found {
+		status, message := typeMapping(err)
+		return status, message, err
+	}
+	for _, m := range mappings {
+		if status, message, matched := m(err); matched != nil {
+			return status, message, matched
+		}
+	}
+	return 0, "", nil
+}
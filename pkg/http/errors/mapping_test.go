@@ -0,0 +1,122 @@
+package errors_test
+
+import (
+	goerrors "errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+type testMappingError struct {
+	detail string
+}
+
+func (e *testMappingError) Error() string {
+	return e.detail
+}
+
+type testTypeMappingError struct{}
+
+func (e *testTypeMappingError) Error() string {
+	return "type mapping"
+}
+
+type testUnmappedError struct{}
+
+func (testUnmappedError) Error() string {
+	return "unmapped"
+}
+
+func TestMapping(t *testing.T) {
+	t.Parallel()
+
+	errors.MustRegisterMapping(http.StatusConflict, func(err *testMappingError) string {
+		return "conflict on " + err.detail
+	})
+
+	errors.MustRegisterTypeMapping(reflect.TypeOf(&testTypeMappingError{}), http.StatusFound, func(err error) string {
+		return "exact " + err.Error()
+	})
+
+	t.Run("when the error matches a registered mapping it should return its status and message", func(t *testing.T) {
+		t.Parallel()
+		mappingErr := &testMappingError{detail: "user"}
+		status, message, matched := errors.MapError(mappingErr)
+		assert.Equals(t, matched, mappingErr)
+		assert.Equals(t, status, http.StatusConflict)
+		assert.Equals(t, message, "conflict on user")
+	})
+
+	t.Run("when the error wraps a mapped error it should match with errors.As", func(t *testing.T) {
+		t.Parallel()
+		mappingErr := &testMappingError{detail: "team"}
+		status, message, matched := errors.MapError(fmt.Errorf("outer (%w)", mappingErr))
+		assert.Equals(t, matched, mappingErr)
+		assert.Equals(t, status, http.StatusConflict)
+		assert.Equals(t, message, "conflict on team")
+	})
+
+	t.Run("when the error does not match a mapping it should return false", func(t *testing.T) {
+		t.Parallel()
+		_, _, matched := errors.MapError(goerrors.New("plain"))
+		assert.Nil(t, matched)
+		_, _, matched = errors.MapError(testUnmappedError{})
+		assert.Nil(t, matched)
+	})
+
+	t.Run("when the status is not an error status it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			errors.MustRegisterMapping(http.StatusOK, func(testUnmappedError) string { return "" })
+		}, "must be between 400 and 599 but is 200")
+	})
+
+	t.Run("when the type already has a mapping it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			errors.MustRegisterMapping(http.StatusNotFound, func(err *testMappingError) string { return "" })
+		}, "the error type *errors_test.testMappingError already has a mapping")
+	})
+
+	t.Run("when the message callback is nil it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			errors.MustRegisterMapping[testUnmappedError](http.StatusBadRequest, nil)
+		}, "the message callback of an error mapping cannot be nil")
+	})
+
+	t.Run("when the error is exactly a registered type it should return its status and message", func(t *testing.T) {
+		t.Parallel()
+		typeMappingErr := &testTypeMappingError{}
+		status, message, matched := errors.MapError(typeMappingErr)
+		assert.Equals(t, matched, typeMappingErr)
+		assert.Equals(t, status, http.StatusFound)
+		assert.Equals(t, message, "exact type mapping")
+	})
+
+	t.Run("when the error wraps a registered type it should not match the type mapping", func(t *testing.T) {
+		t.Parallel()
+		_, _, matched := errors.MapError(fmt.Errorf("outer (%w)", &testTypeMappingError{}))
+		assert.Nil(t, matched)
+	})
+
+	t.Run("when a type is registered twice it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			errors.MustRegisterTypeMapping(reflect.TypeOf(&testTypeMappingError{}), http.StatusFound, func(err error) string {
+				return ""
+			})
+		}, "the error type *errors_test.testTypeMappingError already has a mapping")
+	})
+
+	t.Run("when the message callback of a type mapping is nil it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			errors.MustRegisterTypeMapping(reflect.TypeOf(testUnmappedError{}), http.StatusFound, nil)
+		}, "the message callback of an error mapping cannot be nil")
+	})
+}
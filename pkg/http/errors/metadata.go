@@ -0,0 +1,141 @@
+package errors
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"unicode"
+)
+
+const (
+	// CodeBadRequest is the code of the BadRequest errors.
+	CodeBadRequest = "bad_request"
+
+	// CodeUnauthorized is the code of the Unauthorized errors.
+	CodeUnauthorized = "unauthorized"
+
+	// CodeForbidden is the code of the Forbidden errors.
+	CodeForbidden = "forbidden"
+
+	// CodeNotFound is the code of the NotFound errors.
+	CodeNotFound = "not_found"
+
+	// CodeNotAcceptable is the code of the NotAcceptable errors.
+	CodeNotAcceptable = "not_acceptable"
+
+	// CodeRequestTimeout is the code of the RequestTimeout errors.
+	CodeRequestTimeout = "request_timeout"
+
+	// CodeConflict is the code of the Conflict errors.
+	CodeConflict = "conflict"
+
+	// CodeRequestEntityTooLarge is the code of the RequestEntityTooLarge errors.
+	CodeRequestEntityTooLarge = "request_entity_too_large"
+
+	// CodeURITooLong is the code of the URITooLong errors.
+	CodeURITooLong = "uri_too_long"
+
+	// CodeUnsupportedMediaType is the code of the UnsupportedMediaType errors.
+	CodeUnsupportedMediaType = "unsupported_media_type"
+
+	// CodeUnprocessableEntity is the code of the UnprocessableEntity errors.
+	CodeUnprocessableEntity = "unprocessable_entity"
+
+	// CodeTooManyRequests is the code of the TooManyRequests errors.
+	CodeTooManyRequests = "too_many_requests"
+
+	// CodeInternal is the code of the errors that are not mapped to a response.
+	CodeInternal = "internal"
+
+	// CodeBadGateway is the code of the BadGateway errors.
+	CodeBadGateway = "bad_gateway"
+
+	// CodeServiceUnavailable is the code of the ServiceUnavailable errors.
+	CodeServiceUnavailable = "service_unavailable"
+
+	// CodeGatewayTimeout is the code of the GatewayTimeout errors.
+	CodeGatewayTimeout = "gateway_timeout"
+)
+
+// Coder is implemented by the errors that have a machine-readable code, which the Error responder sends
+// in the code field of the error response.
+type Coder interface {
+	Code() string
+}
+
+// Retryable is implemented by the errors that hint whether the request may succeed if it is sent again.
+type Retryable interface {
+	IsRetryable() bool
+}
+
+// CodeOf returns the code of the first error in the chain that implements Coder.
+// If there is none, the code is derived from the status, like not_found for HTTP 404.
+func CodeOf(err error, status int) string {
+	var coder Coder
+	if errors.As(err, &coder) {
+		return coder.Code()
+	}
+	return CodeForStatus(status)
+}
+
+// statusToCode maps the statuses of the errors of this package to their code.
+var statusToCode = map[int]string{
+	http.StatusBadRequest:            CodeBadRequest,
+	http.StatusUnauthorized:          CodeUnauthorized,
+	http.StatusForbidden:             CodeForbidden,
+	http.StatusNotFound:              CodeNotFound,
+	http.StatusNotAcceptable:         CodeNotAcceptable,
+	http.StatusRequestTimeout:        CodeRequestTimeout,
+	http.StatusConflict:              CodeConflict,
+	http.StatusRequestEntityTooLarge: CodeRequestEntityTooLarge,
+	http.StatusRequestURITooLong:     CodeURITooLong,
+	http.StatusUnsupportedMediaType:  CodeUnsupportedMediaType,
+	http.StatusUnprocessableEntity:   CodeUnprocessableEntity,
+	http.StatusTooManyRequests:       CodeTooManyRequests,
+	http.StatusInternalServerError:   CodeInternal,
+	http.StatusBadGateway:            CodeBadGateway,
+	http.StatusServiceUnavailable:    CodeServiceUnavailable,
+	http.StatusGatewayTimeout:        CodeGatewayTimeout,
+}
+
+// CodeForStatus returns the code of the errors of this package that have the status. Other statuses are converted
+// from their status text, like im_a_teapot for HTTP 418, and unknown statuses are CodeInternal.
+func CodeForStatus(status int) string {
+	if code, found := statusToCode[status]; found {
+		return code
+	}
+	text := http.StatusText(status)
+	if text == "" {
+		return CodeInternal
+	}
+	var code strings.Builder
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if code.Len() > 0 {
+			code.WriteByte('_')
+		}
+		code.WriteString(strings.ToLower(word))
+	}
+	return code.String()
+}
+
+// IsRetryable returns the hint of the first error in the chain that implements Retryable. If there is none,
+// the hint is derived from the status, which is retryable for HTTP 408, 429, 502, 503, and 504.
+func IsRetryable(err error, status int) bool {
+	var retryable Retryable
+	if errors.As(err, &retryable) {
+		return retryable.IsRetryable()
+	}
+	return IsRetryableStatus(status)
+}
+
+// IsRetryableStatus returns true if the status indicates that the request may succeed if it is sent again.
+func IsRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,80 @@
+package errors_test
+
+import (
+	goerrors "errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+type testCodedError struct{}
+
+func (testCodedError) Error() string {
+	return "coded"
+}
+
+func (testCodedError) Code() string {
+	return "custom_code"
+}
+
+func (testCodedError) IsRetryable() bool {
+	return true
+}
+
+func TestMetadata(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when the errors of the package are created they should unwrap and have a code and retryable hint", func(t *testing.T) {
+		t.Parallel()
+		cause := goerrors.New("cause")
+		testCases := []struct {
+			err       error
+			code      string
+			retryable bool
+		}{
+			{err: &errors.BadRequest{Err: cause}, code: errors.CodeBadRequest},
+			{err: &errors.Unauthorized{Err: cause}, code: errors.CodeUnauthorized},
+			{err: &errors.Forbidden{Err: cause}, code: errors.CodeForbidden},
+			{err: &errors.NotFound{Err: cause}, code: errors.CodeNotFound},
+			{err: &errors.NotAcceptable{Err: cause}, code: errors.CodeNotAcceptable},
+			{err: &errors.RequestTimeout{Err: cause}, code: errors.CodeRequestTimeout, retryable: true},
+			{err: &errors.Conflict{Err: cause}, code: errors.CodeConflict},
+			{err: &errors.RequestEntityTooLarge{Err: cause}, code: errors.CodeRequestEntityTooLarge},
+			{err: &errors.URITooLong{Err: cause}, code: errors.CodeURITooLong},
+			{err: &errors.UnsupportedMediaType{Err: cause}, code: errors.CodeUnsupportedMediaType},
+			{err: &errors.UnprocessableEntity{Err: cause}, code: errors.CodeUnprocessableEntity},
+			{err: &errors.TooManyRequests{Err: cause}, code: errors.CodeTooManyRequests, retryable: true},
+			{err: &errors.BadGateway{Err: cause}, code: errors.CodeBadGateway, retryable: true},
+			{err: &errors.ServiceUnavailable{Err: cause}, code: errors.CodeServiceUnavailable, retryable: true},
+			{err: &errors.GatewayTimeout{Err: cause}, code: errors.CodeGatewayTimeout, retryable: true},
+		}
+		for _, testCase := range testCases {
+			assert.True(t, goerrors.Is(testCase.err, cause))
+			assert.Equals(t, testCase.err.(errors.Coder).Code(), testCase.code)
+			assert.Equals(t, testCase.err.(errors.Retryable).IsRetryable(), testCase.retryable)
+			wrapped := fmt.Errorf("wrapped (%w)", testCase.err)
+			assert.Equals(t, errors.CodeOf(wrapped, http.StatusInternalServerError), testCase.code)
+			assert.Equals(t, errors.IsRetryable(wrapped, http.StatusInternalServerError), testCase.retryable)
+		}
+	})
+
+	t.Run("when the error implements the interfaces it should use its code and retryable hint", func(t *testing.T) {
+		t.Parallel()
+		assert.Equals(t, errors.CodeOf(testCodedError{}, http.StatusBadRequest), "custom_code")
+		assert.True(t, errors.IsRetryable(testCodedError{}, http.StatusBadRequest))
+	})
+
+	t.Run("when the error does not implement the interfaces it should derive them from the status", func(t *testing.T) {
+		t.Parallel()
+		plain := goerrors.New("plain")
+		assert.Equals(t, errors.CodeOf(plain, http.StatusNotFound), errors.CodeNotFound)
+		assert.Equals(t, errors.CodeOf(nil, http.StatusInternalServerError), errors.CodeInternal)
+		assert.Equals(t, errors.CodeOf(plain, http.StatusTeapot), "i_m_a_teapot")
+		assert.Equals(t, errors.CodeOf(plain, 799), errors.CodeInternal)
+		assert.True(t, errors.IsRetryable(plain, http.StatusServiceUnavailable))
+		assert.False(t, errors.IsRetryable(plain, http.StatusInternalServerError))
+	})
+}
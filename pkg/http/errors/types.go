@@ -9,3 +9,368 @@ type BadRequest struct {
 func (e *BadRequest) Error() string {
 	return e.Err.Error()
 }
+
+// Unwrap returns the error that caused the BadRequest.
+func (e *BadRequest) Unwrap() error {
+	return e.Err
+}
+
+// Code returns CodeBadRequest.
+func (e *BadRequest) Code() string {
+	return CodeBadRequest
+}
+
+// IsRetryable returns false, since sending the request again does not change the outcome.
+func (e *BadRequest) IsRetryable() bool {
+	return false
+}
+
+// ServiceUnavailable indicates that the server is temporarily unable to handle the request.
+type ServiceUnavailable struct {
+	Err error
+}
+
+// Error is ServiceUnavailable implementing the error interface.
+func (e *ServiceUnavailable) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error that caused the ServiceUnavailable.
+func (e *ServiceUnavailable) Unwrap() error {
+	return e.Err
+}
+
+// Code returns CodeServiceUnavailable.
+func (e *ServiceUnavailable) Code() string {
+	return CodeServiceUnavailable
+}
+
+// IsRetryable returns true, since the request may succeed if it is sent again.
+func (e *ServiceUnavailable) IsRetryable() bool {
+	return true
+}
+
+// URITooLong indicates that the request URI is longer than the server is willing to interpret.
+type URITooLong struct {
+	Err error
+}
+
+// Error is URITooLong implementing the error interface.
+func (e *URITooLong) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error that caused the URITooLong.
+func (e *URITooLong) Unwrap() error {
+	return e.Err
+}
+
+// Code returns CodeURITooLong.
+func (e *URITooLong) Code() string {
+	return CodeURITooLong
+}
+
+// IsRetryable returns false, since sending the request again does not change the outcome.
+func (e *URITooLong) IsRetryable() bool {
+	return false
+}
+
+// UnsupportedMediaType indicates that the server refuses the request because the payload format is not supported.
+type UnsupportedMediaType struct {
+	Err error
+}
+
+// Error is UnsupportedMediaType implementing the error interface.
+func (e *UnsupportedMediaType) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error that caused the UnsupportedMediaType.
+func (e *UnsupportedMediaType) Unwrap() error {
+	return e.Err
+}
+
+// Code returns CodeUnsupportedMediaType.
+func (e *UnsupportedMediaType) Code() string {
+	return CodeUnsupportedMediaType
+}
+
+// IsRetryable returns false, since sending the request again does not change the outcome.
+func (e *UnsupportedMediaType) IsRetryable() bool {
+	return false
+}
+
+// Forbidden indicates that the server understood the request but refuses to fulfill it.
+type Forbidden struct {
+	Err error
+}
+
+// Error is Forbidden implementing the error interface.
+func (e *Forbidden) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error that caused the Forbidden.
+func (e *Forbidden) Unwrap() error {
+	return e.Err
+}
+
+// Code returns CodeForbidden.
+func (e *Forbidden) Code() string {
+	return CodeForbidden
+}
+
+// IsRetryable returns false, since sending the request again does not change the outcome.
+func (e *Forbidden) IsRetryable() bool {
+	return false
+}
+
+// TooManyRequests indicates that the client has sent too many requests in a given amount of time.
+type TooManyRequests struct {
+	Err error
+}
+
+// Error is TooManyRequests implementing the error interface.
+func (e *TooManyRequests) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error that caused the TooManyRequests.
+func (e *TooManyRequests) Unwrap() error {
+	return e.Err
+}
+
+// Code returns CodeTooManyRequests.
+func (e *TooManyRequests) Code() string {
+	return CodeTooManyRequests
+}
+
+// IsRetryable returns true, since the request may succeed if it is sent again.
+func (e *TooManyRequests) IsRetryable() bool {
+	return true
+}
+
+// RequestEntityTooLarge indicates that the request body is larger than the server is willing to process.
+type RequestEntityTooLarge struct {
+	Err error
+}
+
+// Error is RequestEntityTooLarge implementing the error interface.
+func (e *RequestEntityTooLarge) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error that caused the RequestEntityTooLarge.
+func (e *RequestEntityTooLarge) Unwrap() error {
+	return e.Err
+}
+
+// Code returns CodeRequestEntityTooLarge.
+func (e *RequestEntityTooLarge) Code() string {
+	return CodeRequestEntityTooLarge
+}
+
+// IsRetryable returns false, since sending the request again does not change the outcome.
+func (e *RequestEntityTooLarge) IsRetryable() bool {
+	return false
+}
+
+// RequestTimeout indicates that the server did not receive or process the complete request in the time it was prepared to wait.
+type RequestTimeout struct {
+	Err error
+}
+
+// Error is RequestTimeout implementing the error interface.
+func (e *RequestTimeout) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error that caused the RequestTimeout.
+func (e *RequestTimeout) Unwrap() error {
+	return e.Err
+}
+
+// Code returns CodeRequestTimeout.
+func (e *RequestTimeout) Code() string {
+	return CodeRequestTimeout
+}
+
+// IsRetryable returns true, since the request may succeed if it is sent again.
+func (e *RequestTimeout) IsRetryable() bool {
+	return true
+}
+
+// Unauthorized indicates that the request lacks valid authentication credentials for the resource.
+type Unauthorized struct {
+	Err error
+}
+
+// Error is Unauthorized implementing the error interface.
+func (e *Unauthorized) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error that caused the Unauthorized.
+func (e *Unauthorized) Unwrap() error {
+	return e.Err
+}
+
+// Code returns CodeUnauthorized.
+func (e *Unauthorized) Code() string {
+	return CodeUnauthorized
+}
+
+// IsRetryable returns false, since sending the request again does not change the outcome.
+func (e *Unauthorized) IsRetryable() bool {
+	return false
+}
+
+// BadGateway indicates that the server, while acting as a gateway or proxy, received an invalid response from the upstream server.
+type BadGateway struct {
+	Err error
+}
+
+// Error is BadGateway implementing the error interface.
+func (e *BadGateway) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error that caused the BadGateway.
+func (e *BadGateway) Unwrap() error {
+	return e.Err
+}
+
+// Code returns CodeBadGateway.
+func (e *BadGateway) Code() string {
+	return CodeBadGateway
+}
+
+// IsRetryable returns true, since the request may succeed if it is sent again.
+func (e *BadGateway) IsRetryable() bool {
+	return true
+}
+
+// GatewayTimeout indicates that the server, while acting as a gateway or proxy, did not get a response from the upstream server in time.
+type GatewayTimeout struct {
+	Err error
+}
+
+// Error is GatewayTimeout implementing the error interface.
+func (e *GatewayTimeout) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error that caused the GatewayTimeout.
+func (e *GatewayTimeout) Unwrap() error {
+	return e.Err
+}
+
+// Code returns CodeGatewayTimeout.
+func (e *GatewayTimeout) Code() string {
+	return CodeGatewayTimeout
+}
+
+// IsRetryable returns true, since the request may succeed if it is sent again.
+func (e *GatewayTimeout) IsRetryable() bool {
+	return true
+}
+
+// NotAcceptable indicates that the server cannot produce a response in any of the formats accepted by the client.
+type NotAcceptable struct {
+	Err error
+}
+
+// Error is NotAcceptable implementing the error interface.
+func (e *NotAcceptable) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error that caused the NotAcceptable.
+func (e *NotAcceptable) Unwrap() error {
+	return e.Err
+}
+
+// Code returns CodeNotAcceptable.
+func (e *NotAcceptable) Code() string {
+	return CodeNotAcceptable
+}
+
+// IsRetryable returns false, since sending the request again does not change the outcome.
+func (e *NotAcceptable) IsRetryable() bool {
+	return false
+}
+
+// NotFound indicates that the server cannot find the requested resource.
+type NotFound struct {
+	Err error
+}
+
+// Error is NotFound implementing the error interface.
+func (e *NotFound) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error that caused the NotFound.
+func (e *NotFound) Unwrap() error {
+	return e.Err
+}
+
+// Code returns CodeNotFound.
+func (e *NotFound) Code() string {
+	return CodeNotFound
+}
+
+// IsRetryable returns false, since sending the request again does not change the outcome.
+func (e *NotFound) IsRetryable() bool {
+	return false
+}
+
+// Conflict indicates that the request conflicts with the current state of the resource.
+type Conflict struct {
+	Err error
+}
+
+// Error is Conflict implementing the error interface.
+func (e *Conflict) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error that caused the Conflict.
+func (e *Conflict) Unwrap() error {
+	return e.Err
+}
+
+// Code returns CodeConflict.
+func (e *Conflict) Code() string {
+	return CodeConflict
+}
+
+// IsRetryable returns false, since sending the request again does not change the outcome.
+func (e *Conflict) IsRetryable() bool {
+	return false
+}
+
+// UnprocessableEntity indicates that the request is well-formed but its content cannot be processed.
+type UnprocessableEntity struct {
+	Err error
+}
+
+// Error is UnprocessableEntity implementing the error interface.
+func (e *UnprocessableEntity) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error that caused the UnprocessableEntity.
+func (e *UnprocessableEntity) Unwrap() error {
+	return e.Err
+}
+
+// Code returns CodeUnprocessableEntity.
+func (e *UnprocessableEntity) Code() string {
+	return CodeUnprocessableEntity
+}
+
+// IsRetryable returns false, since sending the request again does not change the outcome.
+func (e *UnprocessableEntity) IsRetryable() bool {
+	return false
+}
@@ -0,0 +1,242 @@
+package fileserver
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/http/api"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/middleware"
+	"github.com/TriangleSide/GoBase/pkg/http/responders"
+)
+
+const (
+	// indexFile is the file served for directories, and for the paths of a single page application.
+	indexFile = "index.html"
+
+	// pathParameter is the name of the wildcard path parameter that holds the name of the requested file.
+	pathParameter = "path"
+)
+
+// fileServerOptions is configured by the caller with the Option functions.
+type fileServerOptions struct {
+	directoryListing bool
+	spaFallback      bool
+	middleware       []middleware.Middleware
+}
+
+// Option is used to configure the FileServer.
+type Option func(fileServerOpts *fileServerOptions)
+
+// WithDirectoryListing lists the files of the directories that have no index.html.
+// Without this option, these directories respond with an HTTP 404 not found.
+func WithDirectoryListing() Option {
+	return func(fileServerOpts *fileServerOptions) {
+		fileServerOpts.directoryListing = true
+	}
+}
+
+// WithSPAFallback serves the index.html at the root of the file system for the paths that are not found and have
+// no file extension, so the client-side router of a single page application can handle them. Missing assets,
+// like /app.js, still respond with an HTTP 404 not found.
+func WithSPAFallback() Option {
+	return func(fileServerOpts *fileServerOptions) {
+		fileServerOpts.spaFallback = true
+	}
+}
+
+// WithMiddleware sets the middleware of the routes of the FileServer.
+func WithMiddleware(mw ...middleware.Middleware) Option {
+	return func(fileServerOpts *fileServerOptions) {
+		fileServerOpts.middleware = append(fileServerOpts.middleware, mw...)
+	}
+}
+
+// FileServer is an api.HTTPEndpointHandler that serves the files of a file system under a path.
+//
+// The file system can be an embed.FS, which should be narrowed to the served directory with fs.Sub, or a
+// directory on disk with os.DirFS. The content type of the files is detected from their extension or content.
+// The responses have an ETag of the content of the file and, if the file system has modification times, a
+// Last-Modified header, so the If-None-Match and If-Modified-Since conditional requests are supported, along
+// with range requests. Directories are served with their index.html.
+type FileServer struct {
+	path  api.Path
+	fsys  fs.FS
+	opts  *fileServerOptions
+	etags sync.Map
+}
+
+// etag is an ETag that is cached for a version of a file.
+type etag struct {
+	size    int64
+	modTime time.Time
+	value   string
+}
+
+// New allocates a FileServer that serves the files of the file system under the path.
+func New(path api.Path, fsys fs.FS, opts ...Option) *FileServer {
+	if fsys == nil {
+		panic("the file system cannot be nil")
+	}
+	fileServerOpts := &fileServerOptions{
+		directoryListing: false,
+		spaFallback:      false,
+	}
+	for _, opt := range opts {
+		opt(fileServerOpts)
+	}
+	return &FileServer{
+		path: path,
+		fsys: fsys,
+		opts: fileServerOpts,
+	}
+}
+
+// AcceptHTTPAPIBuilder registers the GET and HEAD routes of the path and of the files under it.
+func (fileServer *FileServer) AcceptHTTPAPIBuilder(builder *api.HTTPAPIBuilder) {
+	filesPath := fileServer.path + "/{" + pathParameter + "...}"
+	paths := []api.Path{fileServer.path, filesPath}
+	if fileServer.path == "/" {
+		paths = []api.Path{"/{" + pathParameter + "...}"}
+	}
+	builder.MustRegisterMany(paths, []api.Method{http.MethodGet, http.MethodHead}, &api.Handler{
+		Middleware: fileServer.opts.middleware,
+		Handler:    fileServer.ServeHTTP,
+	})
+}
+
+// ServeHTTP serves the file named by the path parameter of the request.
+func (fileServer *FileServer) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	name := strings.TrimSuffix(request.PathValue(pathParameter), "/")
+	if name == "" {
+		name = "."
+	}
+	if !fs.ValidPath(name) {
+		http.NotFound(writer, request)
+		return
+	}
+
+	err := fileServer.serve(writer, request, name)
+	if errors.Is(err, fs.ErrNotExist) && fileServer.opts.spaFallback && path.Ext(name) == "" {
+		err = fileServer.serve(writer, request, indexFile)
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		http.NotFound(writer, request)
+		return
+	}
+	if err != nil {
+		responders.Error(request, writer, err)
+	}
+}
+
+// serve writes the file or directory with the name. If it does not exist, an fs.ErrNotExist error is returned
+// before anything is written.
+func (fileServer *FileServer) serve(writer http.ResponseWriter, request *http.Request, name string) error {
+	info, err := fs.Stat(fileServer.fsys, name)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		indexName := path.Join(name, indexFile)
+		indexInfo, err := fs.Stat(fileServer.fsys, indexName)
+		if err == nil && !indexInfo.IsDir() {
+			return fileServer.serveFile(writer, request, indexName, indexInfo)
+		}
+		if !errors.Is(err, fs.ErrNotExist) && err != nil {
+			return err
+		}
+		if !fileServer.opts.directoryListing {
+			return fs.ErrNotExist
+		}
+		return fileServer.serveDirectory(writer, request, name)
+	}
+	return fileServer.serveFile(writer, request, name, info)
+}
+
+// serveFile writes the file with http.ServeContent, which handles the conditional and range requests.
+func (fileServer *FileServer) serveFile(writer http.ResponseWriter, request *http.Request, name string, info fs.FileInfo) error {
+	file, err := fileServer.fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	content, ok := file.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return fmt.Errorf("failed to read the file %s (%w)", name, err)
+		}
+		content = bytes.NewReader(data)
+	}
+
+	tag, err := fileServer.etag(name, info, content)
+	if err != nil {
+		return err
+	}
+	writer.Header().Set(headers.ETag, tag)
+	http.ServeContent(writer, request, info.Name(), info.ModTime(), content)
+	return nil
+}
+
+// etag returns the ETag of the content of the file. It is cached until the size or modification time of the
+// file changes. The content is rewound after it is hashed.
+func (fileServer *FileServer) etag(name string, info fs.FileInfo, content io.ReadSeeker) (string, error) {
+	if cached, found := fileServer.etags.Load(name); found {
+		cachedTag := cached.(*etag)
+		if cachedTag.size == info.Size() && cachedTag.modTime.Equal(info.ModTime()) {
+			return cachedTag.value, nil
+		}
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return "", fmt.Errorf("failed to hash the file %s (%w)", name, err)
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("failed to rewind the file %s (%w)", name, err)
+	}
+	value := `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+	fileServer.etags.Store(name, &etag{size: info.Size(), modTime: info.ModTime(), value: value})
+	return value, nil
+}
+
+// serveDirectory writes an HTML list of the entries of the directory.
+func (fileServer *FileServer) serveDirectory(writer http.ResponseWriter, request *http.Request, name string) error {
+	entries, err := fs.ReadDir(fileServer.fsys, name)
+	if err != nil {
+		return err
+	}
+	basePath := strings.TrimSuffix(request.URL.Path, "/")
+	var listing strings.Builder
+	listing.WriteString("<!doctype html>\n<meta name=\"viewport\" content=\"width=device-width\">\n<pre>\n")
+	for _, entry := range entries {
+		entryName := entry.Name()
+		if entry.IsDir() {
+			entryName += "/"
+		}
+		link := url.URL{Path: basePath + "/" + entryName}
+		fmt.Fprintf(&listing, "<a href=\"%s\">%s</a>\n", html.EscapeString(link.EscapedPath()), html.EscapeString(entryName))
+	}
+	listing.WriteString("</pre>\n")
+
+	writer.Header().Set(headers.ContentType, "text/html; charset=utf-8")
+	writer.WriteHeader(http.StatusOK)
+	if request.Method != http.MethodHead {
+		_, _ = io.WriteString(writer, listing.String())
+	}
+	return nil
+}
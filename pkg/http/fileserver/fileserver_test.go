@@ -0,0 +1,192 @@
+package fileserver_test
+
+import (
+	"embed"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/http/api"
+	"github.com/TriangleSide/GoBase/pkg/http/fileserver"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/middleware"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+//go:embed testdata/site
+var embedded embed.FS
+
+// modTime is the modification time of the files of the test file system.
+var modTime = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// newFS returns a file system with a site and a directory without an index.
+func newFS() fstest.MapFS {
+	return fstest.MapFS{
+		"index.html":        {Data: []byte("<html>home</html>"), ModTime: modTime},
+		"css/site.css":      {Data: []byte("body {}"), ModTime: modTime},
+		"docs/index.html":   {Data: []byte("<html>docs</html>"), ModTime: modTime},
+		"files/a <b>.txt":   {Data: []byte("a"), ModTime: modTime},
+		"files/nested/b.md": {Data: []byte("b"), ModTime: modTime},
+		"LICENSE":           {Data: []byte("plain license text"), ModTime: modTime},
+	}
+}
+
+// newMux registers the routes of the file server in a ServeMux, the way the server does.
+func newMux(fileServer *fileserver.FileServer) *http.ServeMux {
+	builder := api.NewHTTPAPIBuilder()
+	fileServer.AcceptHTTPAPIBuilder(builder)
+	mux := http.NewServeMux()
+	for path, methodToHandler := range builder.Handlers() {
+		for method, handler := range methodToHandler {
+			mux.HandleFunc(string(method)+" "+path.MuxPattern(), middleware.CreateChain(handler.Middleware, handler.Handler))
+		}
+	}
+	return mux
+}
+
+// serve returns the response of the mux to a request with the method, target, and headers.
+func serve(mux *http.ServeMux, method string, target string, requestHeaders map[string]string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, target, nil)
+	for name, value := range requestHeaders {
+		request.Header.Set(name, value)
+	}
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestFileServer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when a file is requested it should be served with its content type and validators", func(t *testing.T) {
+		t.Parallel()
+		mux := newMux(fileserver.New("/static", newFS()))
+		recorder := serve(mux, http.MethodGet, "/static/css/site.css", nil)
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.Equals(t, recorder.Body.String(), "body {}")
+		assert.Equals(t, recorder.Header().Get(headers.ContentType), "text/css; charset=utf-8")
+		assert.Equals(t, recorder.Header().Get(headers.LastModified), modTime.Format(http.TimeFormat))
+		assert.NotEquals(t, recorder.Header().Get(headers.ETag), "")
+
+		recorder = serve(mux, http.MethodGet, "/static/LICENSE", nil)
+		assert.Equals(t, recorder.Header().Get(headers.ContentType), "text/plain; charset=utf-8")
+
+		recorder = serve(mux, http.MethodHead, "/static/css/site.css", nil)
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.Equals(t, recorder.Body.Len(), 0)
+	})
+
+	t.Run("when the client has the current version it should respond with not modified", func(t *testing.T) {
+		t.Parallel()
+		mux := newMux(fileserver.New("/static", newFS()))
+		tag := serve(mux, http.MethodGet, "/static/css/site.css", nil).Header().Get(headers.ETag)
+		recorder := serve(mux, http.MethodGet, "/static/css/site.css", map[string]string{"If-None-Match": tag})
+		assert.Equals(t, recorder.Code, http.StatusNotModified)
+		recorder = serve(mux, http.MethodGet, "/static/css/site.css", map[string]string{"If-None-Match": `"other"`})
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		recorder = serve(mux, http.MethodGet, "/static/css/site.css", map[string]string{
+			headers.IfModifiedSince: modTime.Format(http.TimeFormat),
+		})
+		assert.Equals(t, recorder.Code, http.StatusNotModified)
+		recorder = serve(mux, http.MethodGet, "/static/css/site.css", map[string]string{"Range": "bytes=0-3"})
+		assert.Equals(t, recorder.Code, http.StatusPartialContent)
+		assert.Equals(t, recorder.Body.String(), "body")
+	})
+
+	t.Run("when a file changes it should have a new ETag", func(t *testing.T) {
+		t.Parallel()
+		fsys := newFS()
+		mux := newMux(fileserver.New("/", fsys))
+		firstTag := serve(mux, http.MethodGet, "/css/site.css", nil).Header().Get(headers.ETag)
+		fsys["css/site.css"] = &fstest.MapFile{Data: []byte("body { margin: 0 }"), ModTime: modTime.Add(time.Hour)}
+		secondTag := serve(mux, http.MethodGet, "/css/site.css", nil).Header().Get(headers.ETag)
+		assert.NotEquals(t, firstTag, secondTag)
+	})
+
+	t.Run("when a directory is requested it should serve its index", func(t *testing.T) {
+		t.Parallel()
+		mux := newMux(fileserver.New("/static", newFS()))
+		for target, body := range map[string]string{
+			"/static":       "<html>home</html>",
+			"/static/":      "<html>home</html>",
+			"/static/docs":  "<html>docs</html>",
+			"/static/docs/": "<html>docs</html>",
+		} {
+			recorder := serve(mux, http.MethodGet, target, nil)
+			assert.Equals(t, recorder.Code, http.StatusOK)
+			assert.Equals(t, recorder.Body.String(), body)
+		}
+	})
+
+	t.Run("when a directory without an index is requested it should only be listed if listing is enabled", func(t *testing.T) {
+		t.Parallel()
+		recorder := serve(newMux(fileserver.New("/static", newFS())), http.MethodGet, "/static/files", nil)
+		assert.Equals(t, recorder.Code, http.StatusNotFound)
+
+		recorder = serve(newMux(fileserver.New("/static", newFS(), fileserver.WithDirectoryListing())), http.MethodGet, "/static/files/", nil)
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.Equals(t, recorder.Header().Get(headers.ContentType), "text/html; charset=utf-8")
+		assert.Contains(t, recorder.Body.String(), `<a href="/static/files/a%20%3Cb%3E.txt">a &lt;b&gt;.txt</a>`)
+		assert.Contains(t, recorder.Body.String(), `<a href="/static/files/nested/">nested/</a>`)
+	})
+
+	t.Run("when a path is not found it should only fall back to the index for non-asset paths of a SPA", func(t *testing.T) {
+		t.Parallel()
+		recorder := serve(newMux(fileserver.New("/", newFS())), http.MethodGet, "/users/42", nil)
+		assert.Equals(t, recorder.Code, http.StatusNotFound)
+
+		mux := newMux(fileserver.New("/", newFS(), fileserver.WithSPAFallback()))
+		recorder = serve(mux, http.MethodGet, "/users/42", nil)
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.Equals(t, recorder.Body.String(), "<html>home</html>")
+		recorder = serve(mux, http.MethodGet, "/files", nil)
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.Equals(t, recorder.Body.String(), "<html>home</html>")
+		recorder = serve(mux, http.MethodGet, "/missing.js", nil)
+		assert.Equals(t, recorder.Code, http.StatusNotFound)
+	})
+
+	t.Run("when the file system is embedded it should serve its files", func(t *testing.T) {
+		t.Parallel()
+		site, err := fs.Sub(embedded, "testdata/site")
+		assert.NoError(t, err)
+		mux := newMux(fileserver.New("/app", site))
+		recorder := serve(mux, http.MethodGet, "/app/", nil)
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.Contains(t, recorder.Body.String(), "<title>embedded</title>")
+		assert.Equals(t, recorder.Header().Get(headers.LastModified), "")
+
+		tag := recorder.Header().Get(headers.ETag)
+		recorder = serve(mux, http.MethodGet, "/app/", map[string]string{"If-None-Match": tag})
+		assert.Equals(t, recorder.Code, http.StatusNotModified)
+
+		recorder = serve(mux, http.MethodGet, "/app/app.js", nil)
+		assert.Equals(t, recorder.Header().Get(headers.ContentType), "text/javascript; charset=utf-8")
+		body, err := io.ReadAll(recorder.Body)
+		assert.NoError(t, err)
+		assert.Equals(t, string(body), "console.log(\"embedded\");\n")
+	})
+
+	t.Run("when middleware is set it should run on the routes of the file server", func(t *testing.T) {
+		t.Parallel()
+		mux := newMux(fileserver.New("/static", newFS(), fileserver.WithMiddleware(func(next http.HandlerFunc) http.HandlerFunc {
+			return func(writer http.ResponseWriter, request *http.Request) {
+				writer.Header().Set(headers.CacheControl, "max-age=60")
+				next(writer, request)
+			}
+		})))
+		recorder := serve(mux, http.MethodGet, "/static/css/site.css", nil)
+		assert.Equals(t, recorder.Header().Get(headers.CacheControl), "max-age=60")
+	})
+
+	t.Run("when the file system is nil it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicExact(t, func() {
+			fileserver.New("/static", nil)
+		}, "the file system cannot be nil")
+	})
+}
@@ -7,9 +7,111 @@ const (
 	// ContentTypeApplicationJson indicates that the body of the HTTP request or response contains JSON.
 	ContentTypeApplicationJson = "application/json"
 
+	// ContentTypeApplicationYAML is the media type of YAML documents.
+	ContentTypeApplicationYAML = "application/yaml"
+
+	// ContentTypeApplicationXML indicates that the body of the HTTP request or response contains XML.
+	ContentTypeApplicationXML = "application/xml"
+
+	// ContentTypeApplicationMsgPack indicates that the body of the HTTP request or response contains MessagePack.
+	ContentTypeApplicationMsgPack = "application/msgpack"
+
+	// ContentTypeApplicationProtobuf indicates that the body of the HTTP request or response contains a protobuf message.
+	ContentTypeApplicationProtobuf = "application/x-protobuf"
+
+	// ContentTypeApplicationNDJSON indicates that the body is a stream of JSON values delimited by newlines.
+	ContentTypeApplicationNDJSON = "application/x-ndjson"
+
+	// ContentTypeTextEventStream indicates that the body is a stream of server-sent events.
+	ContentTypeTextEventStream = "text/event-stream"
+
+	// ContentTypeMultipartMixed indicates that the body contains many parts, each with their own content type.
+	ContentTypeMultipartMixed = "multipart/mixed"
+
 	// TransferEncoding specifies the form of encoding used to transfer the payload body to the caller.
 	TransferEncoding = "Transfer-Encoding"
 
 	// TransferEncodingChunked allows data to be sent in a series of chunks without specifying the total size beforehand.
 	TransferEncodingChunked = "chunked"
+
+	// RetryAfter indicates how long the client should wait before making a follow-up request.
+	RetryAfter = "Retry-After"
+
+	// AcceptEncoding indicates the content encodings the client can understand, optionally weighted with q-values.
+	AcceptEncoding = "Accept-Encoding"
+
+	// AcceptLanguage indicates the natural languages the client prefers, optionally weighted with q-values.
+	AcceptLanguage = "Accept-Language"
+
+	// ContentEncoding lists the encodings that have been applied to the body of the message.
+	ContentEncoding = "Content-Encoding"
+
+	// ContentLength indicates the size of the body in bytes.
+	ContentLength = "Content-Length"
+
+	// Vary lists the request headers that were used to select the representation of the response.
+	Vary = "Vary"
+
+	// Trailer lists the headers that are sent in the trailer, after the body of a chunked message.
+	Trailer = "Trailer"
+
+	// ContentSHA256 is the hex encoded SHA-256 digest of the body. It is sent as a trailer on streamed responses.
+	ContentSHA256 = "X-Content-SHA256"
+
+	// LastModified is the date and time at which the origin server believes the resource was last modified.
+	LastModified = "Last-Modified"
+
+	// IfModifiedSince makes a GET or HEAD request conditional on the resource being modified after the date.
+	IfModifiedSince = "If-Modified-Since"
+
+	// Connection controls whether the network connection stays open and lists the hop-by-hop headers, like Upgrade.
+	Connection = "Connection"
+
+	// Upgrade is used by the client to ask the server to switch to another protocol, like websocket.
+	Upgrade = "Upgrade"
+
+	// Origin indicates the origin (scheme, host, and port) that caused the request.
+	Origin = "Origin"
+
+	// SecWebSocketKey is sent by the client in the WebSocket opening handshake to prove that the server supports WebSockets.
+	SecWebSocketKey = "Sec-WebSocket-Key"
+
+	// SecWebSocketAccept is the response of the server to the SecWebSocketKey in the WebSocket opening handshake.
+	SecWebSocketAccept = "Sec-WebSocket-Accept"
+
+	// SecWebSocketVersion is the version of the WebSocket protocol used in the opening handshake.
+	SecWebSocketVersion = "Sec-WebSocket-Version"
+
+	// CacheControl holds directives that control caching in browsers and shared caches, like proxies.
+	CacheControl = "Cache-Control"
+
+	// LastEventID is sent by an EventSource when it reconnects, with the ID of the last server-sent event it received.
+	LastEventID = "Last-Event-ID"
+
+	// RequestID is a unique identifier of a request that is used to correlate its logs across services.
+	RequestID = "X-Request-ID"
+
+	// Traceparent holds the W3C trace context of a request, which identifies the trace and the span it belongs to.
+	Traceparent = "Traceparent"
+
+	// Authorization holds the credentials that authenticate the client with the server.
+	Authorization = "Authorization"
+
+	// WWWAuthenticate defines the authentication method that should be used to access a resource.
+	WWWAuthenticate = "WWW-Authenticate"
+
+	// APIKey holds the API key that authenticates the client with the server.
+	APIKey = "X-API-Key"
+
+	// Accept indicates which content types the client is able to understand.
+	Accept = "Accept"
+
+	// Allow lists the methods supported by the target resource.
+	Allow = "Allow"
+
+	// ETag identifies a specific version of a resource.
+	ETag = "ETag"
+
+	// IfNoneMatch makes a GET or HEAD request conditional on the current ETag of the resource not being listed.
+	IfNoneMatch = "If-None-Match"
 )
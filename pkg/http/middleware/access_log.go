@@ -0,0 +1,160 @@
+package middleware
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/logger"
+)
+
+const (
+	// AccessLogFieldMethod is the access log field of the request method.
+	AccessLogFieldMethod = "method"
+
+	// AccessLogFieldPath is the access log field of the request path.
+	AccessLogFieldPath = "path"
+
+	// AccessLogFieldStatus is the access log field of the response status code.
+	AccessLogFieldStatus = "status"
+
+	// AccessLogFieldLatency is the access log field of the time taken to handle the request.
+	AccessLogFieldLatency = "latency"
+
+	// AccessLogFieldBytes is the access log field of the number of response body bytes written.
+	AccessLogFieldBytes = "bytes"
+
+	// AccessLogFieldRemoteIP is the access log field of the IP address of the client.
+	AccessLogFieldRemoteIP = "remote_ip"
+
+	// AccessLogFieldUserAgent is the access log field of the User-Agent of the client.
+	AccessLogFieldUserAgent = "user_agent"
+
+	// redactedValue replaces the value of redacted access log fields.
+	redactedValue = "[REDACTED]"
+)
+
+// accessLogConfig is configured by the AccessLogOption functions.
+type accessLogConfig struct {
+	redactedFields map[string]bool
+	sampleRate     float64
+}
+
+// AccessLogOption is used to configure the AccessLog middleware.
+type AccessLogOption func(cfg *accessLogConfig)
+
+// WithRedactedFields replaces the values of the access log fields with a placeholder.
+// This is used for fields that are sensitive in some environments, like AccessLogFieldRemoteIP.
+func WithRedactedFields(fields ...string) AccessLogOption {
+	return func(cfg *accessLogConfig) {
+		for _, field := range fields {
+			cfg.redactedFields[field] = true
+		}
+	}
+}
+
+// WithSampleRate sets the fraction of the requests, from 0 to 1, that are logged. The default is 1.
+// Responses with a server error status are always logged. If the rate is out of range, this function panics.
+func WithSampleRate(rate float64) AccessLogOption {
+	if rate < 0 || rate > 1 {
+		panic(fmt.Sprintf("the access log sample rate %v must be between 0 and 1", rate))
+	}
+	return func(cfg *accessLogConfig) {
+		cfg.sampleRate = rate
+	}
+}
+
+// AccessLog returns a Middleware that logs every request after it is handled with the method, path, status,
+// latency, bytes written, remote IP, and user agent. The fields are added to the logger fields of the context,
+// so fields set by earlier middleware, like the request ID, are included too.
+func AccessLog(opts ...AccessLogOption) Middleware {
+	cfg := &accessLogConfig{
+		redactedFields: make(map[string]bool),
+		sampleRate:     1,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(writer http.ResponseWriter, request *http.Request) {
+			accessWriter := &accessLogResponseWriter{ResponseWriter: writer, status: 0, bytes: 0}
+			start := time.Now()
+			next(accessWriter, request)
+			latency := time.Since(start)
+
+			status := accessWriter.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			if status < http.StatusInternalServerError && cfg.sampleRate < 1 && rand.Float64() >= cfg.sampleRate {
+				return
+			}
+
+			fields := map[string]any{
+				AccessLogFieldMethod:    request.Method,
+				AccessLogFieldPath:      request.URL.Path,
+				AccessLogFieldStatus:    status,
+				AccessLogFieldLatency:   latency,
+				AccessLogFieldBytes:     accessWriter.bytes,
+				AccessLogFieldRemoteIP:  remoteIP(request),
+				AccessLogFieldUserAgent: request.UserAgent(),
+			}
+			for field := range cfg.redactedFields {
+				if _, found := fields[field]; found {
+					fields[field] = redactedValue
+				}
+			}
+			logger.Info(logger.WithFields(request.Context(), fields), "Request handled.")
+		}
+	}
+}
+
+// remoteIP returns the IP address of the client without the port.
+func remoteIP(request *http.Request) string {
+	host, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		return request.RemoteAddr
+	}
+	return host
+}
+
+// accessLogResponseWriter records the status code and the number of bytes written in the response.
+type accessLogResponseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+// WriteHeader records the status code and sends the HTTP response header with it.
+// Informational headers are sent without being recorded, since the final status follows them.
+func (aw *accessLogResponseWriter) WriteHeader(statusCode int) {
+	if aw.status == 0 && statusCode >= http.StatusOK {
+		aw.status = statusCode
+	}
+	aw.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write records the number of bytes written as part of the HTTP reply.
+func (aw *accessLogResponseWriter) Write(data []byte) (int, error) {
+	if aw.status == 0 {
+		aw.status = http.StatusOK
+	}
+	written, err := aw.ResponseWriter.Write(data)
+	aw.bytes += int64(written)
+	return written, err
+}
+
+// Flush sends any buffered data to the client. Streaming responders flush after every message.
+func (aw *accessLogResponseWriter) Flush() {
+	if flusher, ok := aw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying http.ResponseWriter. This is used by the http.ResponseController.
+func (aw *accessLogResponseWriter) Unwrap() http.ResponseWriter {
+	return aw.ResponseWriter
+}
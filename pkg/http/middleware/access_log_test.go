@@ -0,0 +1,100 @@
+package middleware_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/http/middleware"
+	"github.com/TriangleSide/GoBase/pkg/http/responders"
+	"github.com/TriangleSide/GoBase/pkg/logger"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestAccessLog(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	logger.SetOutput(buffer)
+	t.Cleanup(func() {
+		logger.SetOutput(os.Stdout)
+	})
+
+	serve := func(handler http.HandlerFunc, opts ...middleware.AccessLogOption) (*httptest.ResponseRecorder, string) {
+		buffer.Reset()
+		request := httptest.NewRequest(http.MethodPost, "/items?secret=value", nil)
+		request.RemoteAddr = "192.0.2.1:4567"
+		request.Header.Set("User-Agent", "test-agent")
+		recorder := httptest.NewRecorder()
+		middleware.CreateChain([]middleware.Middleware{middleware.AccessLog(opts...)}, handler)(recorder, request)
+		return recorder, buffer.String()
+	}
+
+	t.Run("when the sample rate is out of range it should panic", func(t *testing.T) {
+		assert.PanicExact(t, func() {
+			middleware.WithSampleRate(1.5)
+		}, "the access log sample rate 1.5 must be between 0 and 1")
+	})
+
+	t.Run("when a request is handled it should log its fields", func(t *testing.T) {
+		_, logs := serve(func(writer http.ResponseWriter, request *http.Request) {
+			writer.WriteHeader(http.StatusCreated)
+			_, _ = io.WriteString(writer, "created")
+		})
+		assert.Contains(t, logs, "method=POST ")
+		assert.Contains(t, logs, "path=/items ")
+		assert.Contains(t, logs, "status=201 ")
+		assert.Contains(t, logs, "bytes=7 ")
+		assert.Contains(t, logs, "remote_ip=192.0.2.1 ")
+		assert.Contains(t, logs, "user_agent=test-agent ")
+		assert.Contains(t, logs, "latency=")
+		assert.Contains(t, logs, "Request handled.")
+		assert.False(t, strings.Contains(logs, "secret"))
+	})
+
+	t.Run("when the handler does not write the header it should log the implicit status", func(t *testing.T) {
+		_, logs := serve(func(writer http.ResponseWriter, request *http.Request) {})
+		assert.Contains(t, logs, "status=200 ")
+		assert.Contains(t, logs, "bytes=0 ")
+	})
+
+	t.Run("when fields are redacted it should replace their values", func(t *testing.T) {
+		_, logs := serve(func(writer http.ResponseWriter, request *http.Request) {}, middleware.WithRedactedFields(middleware.AccessLogFieldRemoteIP, middleware.AccessLogFieldUserAgent, "unknown"))
+		assert.Contains(t, logs, "remote_ip=[REDACTED] ")
+		assert.Contains(t, logs, "user_agent=[REDACTED] ")
+		assert.False(t, strings.Contains(logs, "192.0.2.1"))
+		assert.False(t, strings.Contains(logs, "unknown"))
+	})
+
+	t.Run("when the sample rate is zero it should only log server errors", func(t *testing.T) {
+		_, logs := serve(func(writer http.ResponseWriter, request *http.Request) {
+			writer.WriteHeader(http.StatusNotFound)
+		}, middleware.WithSampleRate(0))
+		assert.Equals(t, logs, "")
+		_, logs = serve(func(writer http.ResponseWriter, request *http.Request) {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+		}, middleware.WithSampleRate(0))
+		assert.Contains(t, logs, "status=503 ")
+	})
+
+	t.Run("when the response is streamed it should count the bytes and flush", func(t *testing.T) {
+		type response struct {
+			Value int `json:"value"`
+		}
+		recorder, logs := serve(func(writer http.ResponseWriter, request *http.Request) {
+			responders.JSONStream[struct{}, response](writer, request, func(_ *struct{}, _ <-chan struct{}) (<-chan *response, int, error) {
+				stream := make(chan *response, 2)
+				stream <- &response{Value: 1}
+				stream <- &response{Value: 2}
+				close(stream)
+				return stream, http.StatusOK, nil
+			})
+		})
+		assert.True(t, recorder.Flushed)
+		assert.Contains(t, logs, "status=200 ")
+		assert.Contains(t, logs, "bytes="+strconv.Itoa(recorder.Body.Len())+" ")
+	})
+}
@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	httperrors "github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/responders"
+	"github.com/TriangleSide/GoBase/pkg/utils/ctxkey"
+)
+
+var (
+	// ErrUnknownAPIKey is returned by an APIKeyValidator when the key does not belong to any principal.
+	ErrUnknownAPIKey = errors.New("the API key is not valid")
+
+	// apiKeyPrincipalKey is the context key of the principal of the API key.
+	apiKeyPrincipalKey = ctxkey.New[string]("apiKeyPrincipal")
+)
+
+// APIKeyValidator resolves the principal that an API key belongs to.
+type APIKeyValidator interface {
+	// Validate returns the principal of the key. If the key is unknown, ErrUnknownAPIKey is returned.
+	// Other errors are passed to the Error responder, so an *httperrors.Forbidden rejects a known key with an HTTP 403.
+	Validate(ctx context.Context, key string) (principal string, err error)
+}
+
+// APIKeyValidatorFunc is a function that implements the APIKeyValidator interface.
+type APIKeyValidatorFunc func(ctx context.Context, key string) (string, error)
+
+// Validate calls the function.
+func (f APIKeyValidatorFunc) Validate(ctx context.Context, key string) (string, error) {
+	return f(ctx, key)
+}
+
+// APIKeyMap returns an APIKeyValidator for a map of API keys to their principal.
+// The keys are stored as SHA-256 digests so that they are not kept in memory in plain text.
+func APIKeyMap(keyToPrincipal map[string]string) APIKeyValidator {
+	digestToPrincipal := make(map[[sha256.Size]byte]string, len(keyToPrincipal))
+	for key, principal := range keyToPrincipal {
+		digestToPrincipal[sha256.Sum256([]byte(key))] = principal
+	}
+	return APIKeyValidatorFunc(func(_ context.Context, key string) (string, error) {
+		principal, found := digestToPrincipal[sha256.Sum256([]byte(key))]
+		if !found {
+			return "", ErrUnknownAPIKey
+		}
+		return principal, nil
+	})
+}
+
+// APIKeyFile reads the API keys from a file and returns an APIKeyValidator for them.
+// Each line of the file is a principal and its key separated by a colon, like "billing-service:abc123".
+// Empty lines and lines starting with '#' are ignored.
+func APIKeyFile(path string) (APIKeyValidator, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the API key file (%w)", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	keyToPrincipal := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		principal, key, found := strings.Cut(line, ":")
+		principal, key = strings.TrimSpace(principal), strings.TrimSpace(key)
+		if !found || principal == "" || key == "" {
+			return nil, fmt.Errorf("line %d of the API key file must be formatted as 'principal:key'", lineNumber)
+		}
+		if _, duplicate := keyToPrincipal[key]; duplicate {
+			return nil, fmt.Errorf("line %d of the API key file has a key that is already used", lineNumber)
+		}
+		keyToPrincipal[key] = principal
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the API key file (%w)", err)
+	}
+	return APIKeyMap(keyToPrincipal), nil
+}
+
+// APIKeyExtractor returns the API key of the request, or an empty string if it does not have one.
+type APIKeyExtractor func(request *http.Request) string
+
+// APIKeyFromHeader extracts the API key from the header.
+func APIKeyFromHeader(name string) APIKeyExtractor {
+	return func(request *http.Request) string {
+		return request.Header.Get(name)
+	}
+}
+
+// APIKeyFromQuery extracts the API key from the query parameter.
+// Keys in the URL can end up in the logs of proxies, so headers should be preferred.
+func APIKeyFromQuery(name string) APIKeyExtractor {
+	return func(request *http.Request) string {
+		return request.URL.Query().Get(name)
+	}
+}
+
+// APIKey returns a Middleware that authenticates requests with an API key.
+//
+// The key is taken from the first extractor that finds one, or from the X-API-Key header if no extractors
+// are provided. Requests without a key, or with an unknown key, are rejected with an HTTP 401 unauthorized.
+// Otherwise, the principal of the key is put in the request context, where it can be read with
+// APIKeyPrincipalFromContext.
+func APIKey(validator APIKeyValidator, extractors ...APIKeyExtractor) Middleware {
+	if validator == nil {
+		panic("the API key validator cannot be nil")
+	}
+	if len(extractors) == 0 {
+		extractors = []APIKeyExtractor{APIKeyFromHeader(headers.APIKey)}
+	}
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(writer http.ResponseWriter, request *http.Request) {
+			var key string
+			for _, extractor := range extractors {
+				if key = extractor(request); key != "" {
+					break
+				}
+			}
+			if key == "" {
+				responders.Error(request, writer, &httperrors.Unauthorized{Err: errors.New("the request does not have an API key")})
+				return
+			}
+
+			principal, err := validator.Validate(request.Context(), key)
+			if err != nil {
+				if errors.Is(err, ErrUnknownAPIKey) {
+					err = &httperrors.Unauthorized{Err: err}
+				}
+				responders.Error(request, writer, err)
+				return
+			}
+
+			next(writer, request.WithContext(apiKeyPrincipalKey.WithValue(request.Context(), principal)))
+		}
+	}
+}
+
+// APIKeyPrincipalFromContext returns the principal put in the context by the APIKey middleware.
+func APIKeyPrincipalFromContext(ctx context.Context) (string, bool) {
+	return apiKeyPrincipalKey.Value(ctx)
+}
@@ -0,0 +1,137 @@
+package middleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	httperrors "github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/middleware"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestAPIKey(t *testing.T) {
+	t.Parallel()
+
+	serve := func(mw middleware.Middleware, request *http.Request) (*httptest.ResponseRecorder, string) {
+		recorder := httptest.NewRecorder()
+		var principal string
+		middleware.CreateChain([]middleware.Middleware{mw}, func(writer http.ResponseWriter, request *http.Request) {
+			var found bool
+			principal, found = middleware.APIKeyPrincipalFromContext(request.Context())
+			assert.True(t, found)
+			writer.WriteHeader(http.StatusOK)
+		})(recorder, request)
+		return recorder, principal
+	}
+
+	requestWithHeader := func(key string) *http.Request {
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		if key != "" {
+			request.Header.Set(headers.APIKey, key)
+		}
+		return request
+	}
+
+	decodeError := func(t *testing.T, recorder *httptest.ResponseRecorder) string {
+		t.Helper()
+		httpError := &httperrors.Error{}
+		assert.NoError(t, json.NewDecoder(recorder.Body).Decode(httpError))
+		return httpError.Message
+	}
+
+	writeKeyFile := func(t *testing.T, contents string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "keys")
+		assert.NoError(t, os.WriteFile(path, []byte(contents), 0600))
+		return path
+	}
+
+	t.Run("when the validator is nil it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicExact(t, func() {
+			middleware.APIKey(nil)
+		}, "the API key validator cannot be nil")
+	})
+
+	t.Run("when the key is in the map it should put the principal in the context", func(t *testing.T) {
+		t.Parallel()
+		recorder, principal := serve(middleware.APIKey(middleware.APIKeyMap(map[string]string{"secret": "billing"})), requestWithHeader("secret"))
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.Equals(t, principal, "billing")
+	})
+
+	t.Run("when the key is missing or unknown it should respond with unauthorized", func(t *testing.T) {
+		t.Parallel()
+		mw := middleware.APIKey(middleware.APIKeyMap(map[string]string{"secret": "billing"}))
+		recorder, _ := serve(mw, requestWithHeader(""))
+		assert.Equals(t, recorder.Code, http.StatusUnauthorized)
+		assert.Equals(t, decodeError(t, recorder), "the request does not have an API key")
+		recorder, _ = serve(mw, requestWithHeader("wrong"))
+		assert.Equals(t, recorder.Code, http.StatusUnauthorized)
+		assert.Equals(t, decodeError(t, recorder), "the API key is not valid")
+	})
+
+	t.Run("when the validator forbids the key it should respond with forbidden", func(t *testing.T) {
+		t.Parallel()
+		validator := middleware.APIKeyValidatorFunc(func(ctx context.Context, key string) (string, error) {
+			return "", &httperrors.Forbidden{Err: errors.New("the API key is revoked")}
+		})
+		recorder, _ := serve(middleware.APIKey(validator), requestWithHeader("revoked"))
+		assert.Equals(t, recorder.Code, http.StatusForbidden)
+		assert.Equals(t, decodeError(t, recorder), "the API key is revoked")
+	})
+
+	t.Run("when the validator fails it should respond with an internal server error", func(t *testing.T) {
+		t.Parallel()
+		validator := middleware.APIKeyValidatorFunc(func(ctx context.Context, key string) (string, error) {
+			return "", errors.New("database unavailable")
+		})
+		recorder, _ := serve(middleware.APIKey(validator), requestWithHeader("key"))
+		assert.Equals(t, recorder.Code, http.StatusInternalServerError)
+	})
+
+	t.Run("when extractors are provided it should use the first key found", func(t *testing.T) {
+		t.Parallel()
+		mw := middleware.APIKey(middleware.APIKeyMap(map[string]string{"header-key": "header", "query-key": "query"}),
+			middleware.APIKeyFromHeader("Authorization"), middleware.APIKeyFromQuery("api_key"))
+		recorder, principal := serve(mw, httptest.NewRequest(http.MethodGet, "/?api_key=query-key", nil))
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.Equals(t, principal, "query")
+		request := httptest.NewRequest(http.MethodGet, "/?api_key=query-key", nil)
+		request.Header.Set("Authorization", "header-key")
+		recorder, principal = serve(mw, request)
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.Equals(t, principal, "header")
+	})
+
+	t.Run("when the keys are read from a file it should validate them", func(t *testing.T) {
+		t.Parallel()
+		validator, err := middleware.APIKeyFile(writeKeyFile(t, "# Service keys.\n\nbilling: key-one\nreports:key-two\n"))
+		assert.NoError(t, err)
+		principal, err := validator.Validate(context.Background(), "key-one")
+		assert.NoError(t, err)
+		assert.Equals(t, principal, "billing")
+		principal, err = validator.Validate(context.Background(), "key-two")
+		assert.NoError(t, err)
+		assert.Equals(t, principal, "reports")
+		_, err = validator.Validate(context.Background(), "key-three")
+		assert.True(t, errors.Is(err, middleware.ErrUnknownAPIKey))
+	})
+
+	t.Run("when the key file is invalid it should return an error", func(t *testing.T) {
+		t.Parallel()
+		_, err := middleware.APIKeyFile(writeKeyFile(t, "billing:key\nmissing-separator\n"))
+		assert.ErrorExact(t, err, "line 2 of the API key file must be formatted as 'principal:key'")
+		_, err = middleware.APIKeyFile(writeKeyFile(t, "billing:key\nreports:key\n"))
+		assert.ErrorExact(t, err, "line 2 of the API key file has a key that is already used")
+		_, err = middleware.APIKeyFile(filepath.Join(t.TempDir(), "missing"))
+		assert.ErrorPart(t, err, "failed to open the API key file")
+	})
+}
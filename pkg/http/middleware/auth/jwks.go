@@ -0,0 +1,253 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/logger"
+)
+
+// jwksConfig is configured by the JWKSOption functions.
+type jwksConfig struct {
+	client             *http.Client
+	refreshInterval    time.Duration
+	minRefreshInterval time.Duration
+}
+
+// JWKSOption is used to configure a JWKS.
+type JWKSOption func(cfg *jwksConfig)
+
+// WithHTTPClient sets the client that fetches the key set. The default client has a 10 second timeout.
+func WithHTTPClient(client *http.Client) JWKSOption {
+	return func(cfg *jwksConfig) {
+		cfg.client = client
+	}
+}
+
+// WithRefreshInterval sets how long the key set is cached before it is fetched again. The default is an hour.
+func WithRefreshInterval(interval time.Duration) JWKSOption {
+	return func(cfg *jwksConfig) {
+		cfg.refreshInterval = interval
+	}
+}
+
+// WithMinRefreshInterval sets how long to wait between fetches, including those caused by tokens with an unknown
+// key ID and those that failed. This stops forged tokens and an unavailable key set URL from making the server
+// hammer it. The default is a minute.
+func WithMinRefreshInterval(interval time.Duration) JWKSOption {
+	return func(cfg *jwksConfig) {
+		cfg.minRefreshInterval = interval
+	}
+}
+
+// jsonWebKey is a key of a JSON Web Key Set (RFC 7517). Only the fields of RSA and EC keys are decoded.
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	Curve   string `json:"crv"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+}
+
+// JWKS is a KeySource that fetches the keys from a JSON Web Key Set URL. The keys are cached and refreshed
+// periodically. When a token has a key ID that is not cached, the key set is fetched again, which picks up
+// keys added by a key rotation.
+type JWKS struct {
+	url         string
+	cfg         *jwksConfig
+	lock        sync.Mutex
+	keys        map[string]any
+	fetchErr    error
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	refreshing  chan struct{}
+}
+
+// NewJWKS allocates a JWKS for the URL. The key set is fetched when the first token is verified.
+func NewJWKS(url string, opts ...JWKSOption) *JWKS {
+	cfg := &jwksConfig{
+		client:             &http.Client{Timeout: time.Second * 10},
+		refreshInterval:    time.Hour,
+		minRefreshInterval: time.Minute,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return &JWKS{
+		url:         url,
+		cfg:         cfg,
+		lock:        sync.Mutex{},
+		keys:        nil,
+		fetchErr:    nil,
+		fetchedAt:   time.Time{},
+		attemptedAt: time.Time{},
+		refreshing:  nil,
+	}
+}
+
+// Key returns the key with the ID. The key set is fetched if it is stale, or if it does not have the key, but
+// never more than once per minimum refresh interval, even if the fetches fail. Concurrent calls share the same
+// fetch. If a refresh fails, the cached keys are still used.
+func (j *JWKS) Key(ctx context.Context, keyID string) (any, error) {
+	j.lock.Lock()
+	now := time.Now()
+	key, found := j.keys[keyID]
+	stale := j.keys == nil || now.Sub(j.fetchedAt) >= j.cfg.refreshInterval
+	canRetry := now.Sub(j.attemptedAt) >= j.cfg.minRefreshInterval
+	if (stale || !found) && canRetry && j.refreshing == nil {
+		j.attemptedAt = now
+		j.refreshing = make(chan struct{})
+		go j.refresh(context.WithoutCancel(ctx), j.refreshing)
+	}
+	refreshing := j.refreshing
+	j.lock.Unlock()
+
+	if refreshing != nil && (stale || !found) {
+		select {
+		case <-refreshing:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("failed to wait for the JSON web key set (%w)", ctx.Err())
+		}
+	}
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	if j.keys == nil && j.fetchErr != nil {
+		return nil, j.fetchErr
+	}
+	key, found = j.keys[keyID]
+	if !found {
+		return nil, fmt.Errorf("the key '%s' is not in the JSON web key set", keyID)
+	}
+	return key, nil
+}
+
+// refresh fetches the key set and closes the done channel once the result is stored. The context is detached
+// from the request that started the refresh so the other requests waiting on it are not failed by its cancellation.
+func (j *JWKS) refresh(ctx context.Context, done chan struct{}) {
+	keys, err := j.fetch(ctx)
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	defer close(done)
+	j.refreshing = nil
+	j.fetchErr = err
+	if err != nil {
+		if j.keys != nil {
+			logger.Errorf(ctx, "Failed to refresh the JSON web key set (%s).", err)
+		}
+		return
+	}
+	j.keys = keys
+	j.fetchedAt = time.Now()
+}
+
+// fetch downloads and decodes the key set. Keys that are not for signatures, or of unsupported types, are skipped.
+func (j *JWKS) fetch(ctx context.Context) (map[string]any, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, j.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the JSON web key set request (%w)", err)
+	}
+	response, err := j.cfg.client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the JSON web key set (%w)", err)
+	}
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch the JSON web key set (status %d)", response.StatusCode)
+	}
+
+	keySet := struct {
+		Keys []jsonWebKey `json:"keys"`
+	}{}
+	if err := json.NewDecoder(response.Body).Decode(&keySet); err != nil {
+		return nil, fmt.Errorf("failed to decode the JSON web key set (%w)", err)
+	}
+
+	keys := make(map[string]any, len(keySet.Keys))
+	for _, webKey := range keySet.Keys {
+		if webKey.Use != "" && webKey.Use != "sig" {
+			continue
+		}
+		key, err := webKey.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the key '%s' (%w)", webKey.KeyID, err)
+		}
+		if key != nil {
+			keys[webKey.KeyID] = key
+		}
+	}
+	return keys, nil
+}
+
+// publicKey decodes the RSA or EC public key. Nil is returned for other key types.
+func (k *jsonWebKey) publicKey() (any, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > int64(^uint32(0)>>1) {
+			return nil, errors.New("the RSA exponent is too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Curve != "P-256" {
+			return nil, fmt.Errorf("the curve '%s' is not supported", k.Curve)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if len(x.Bytes()) > es256ComponentSize || len(y.Bytes()) > es256ComponentSize {
+			return nil, errors.New("the EC point is not on the curve")
+		}
+		point := make([]byte, 1+2*es256ComponentSize)
+		point[0] = 4
+		x.FillBytes(point[1 : 1+es256ComponentSize])
+		y.FillBytes(point[1+es256ComponentSize:])
+		if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+			return nil, errors.New("the EC point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// decodeBigInt decodes a base64url encoded big-endian integer.
+func decodeBigInt(encoded string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("the integer cannot be empty")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,201 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/http/middleware/auth"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+// keySetServer serves a JSON web key set that can be replaced while it is running.
+type keySetServer struct {
+	lock    sync.Mutex
+	keys    []map[string]string
+	status  int
+	fetches atomic.Int32
+}
+
+func (s *keySetServer) setKeys(status int, keys ...map[string]string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.status = status
+	s.keys = keys
+}
+
+func (s *keySetServer) ServeHTTP(writer http.ResponseWriter, _ *http.Request) {
+	s.fetches.Add(1)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	writer.WriteHeader(s.status)
+	_ = json.NewEncoder(writer).Encode(map[string]any{"keys": s.keys})
+}
+
+func encodeInt(value *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(value.Bytes())
+}
+
+func rsaWebKey(keyID string, key *rsa.PublicKey) map[string]string {
+	return map[string]string{"kty": "RSA", "kid": keyID, "use": "sig", "n": encodeInt(key.N), "e": encodeInt(big.NewInt(int64(key.E)))}
+}
+
+func ecWebKey(keyID string, key *ecdsa.PublicKey) map[string]string {
+	return map[string]string{"kty": "EC", "kid": keyID, "crv": "P-256", "x": encodeInt(key.X), "y": encodeInt(key.Y)}
+}
+
+func TestJWKS(t *testing.T) {
+	t.Parallel()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	startKeySet := func(t *testing.T) (*keySetServer, string) {
+		t.Helper()
+		keySet := &keySetServer{status: http.StatusOK}
+		srv := httptest.NewServer(keySet)
+		t.Cleanup(srv.Close)
+		return keySet, srv.URL
+	}
+
+	t.Run("when tokens are signed with keys of the set it should verify them", func(t *testing.T) {
+		t.Parallel()
+		keySet, url := startKeySet(t)
+		keySet.setKeys(http.StatusOK, rsaWebKey("rsa", &rsaKey.PublicKey), ecWebKey("ec", &ecKey.PublicKey), map[string]string{"kty": "oct", "kid": "secret"})
+		jwks := auth.NewJWKS(url)
+		for keyID, signingKey := range map[string]any{"rsa": rsaKey, "ec": ecKey} {
+			algorithm := auth.RS256
+			if keyID == "ec" {
+				algorithm = auth.ES256
+			}
+			token, err := auth.Sign(&testClaims{Role: keyID}, algorithm, keyID, signingKey)
+			assert.NoError(t, err)
+			claims, _, err := auth.Verify[testClaims](context.Background(), token, jwks)
+			assert.NoError(t, err)
+			assert.Equals(t, claims.Role, keyID)
+		}
+		assert.Equals(t, keySet.fetches.Load(), int32(1))
+	})
+
+	t.Run("when the keys are rotated it should fetch the set for the unknown key ID", func(t *testing.T) {
+		t.Parallel()
+		keySet, url := startKeySet(t)
+		keySet.setKeys(http.StatusOK, rsaWebKey("old", &rsaKey.PublicKey))
+		jwks := auth.NewJWKS(url, auth.WithMinRefreshInterval(0))
+		_, err := jwks.Key(context.Background(), "old")
+		assert.NoError(t, err)
+		keySet.setKeys(http.StatusOK, ecWebKey("new", &ecKey.PublicKey))
+		key, err := jwks.Key(context.Background(), "new")
+		assert.NoError(t, err)
+		assert.Equals(t, key.(*ecdsa.PublicKey).X, ecKey.X)
+		assert.Equals(t, keySet.fetches.Load(), int32(2))
+	})
+
+	t.Run("when an unknown key ID is requested within the minimum refresh interval it should not fetch again", func(t *testing.T) {
+		t.Parallel()
+		keySet, url := startKeySet(t)
+		keySet.setKeys(http.StatusOK, rsaWebKey("known", &rsaKey.PublicKey))
+		jwks := auth.NewJWKS(url)
+		_, err := jwks.Key(context.Background(), "known")
+		assert.NoError(t, err)
+		for range 3 {
+			_, err = jwks.Key(context.Background(), "unknown")
+			assert.ErrorExact(t, err, "the key 'unknown' is not in the JSON web key set")
+		}
+		assert.Equals(t, keySet.fetches.Load(), int32(1))
+	})
+
+	t.Run("when the refresh interval expires it should fetch the set again and keep the cached keys on failure", func(t *testing.T) {
+		t.Parallel()
+		keySet, url := startKeySet(t)
+		keySet.setKeys(http.StatusOK, rsaWebKey("key", &rsaKey.PublicKey))
+		jwks := auth.NewJWKS(url, auth.WithRefreshInterval(time.Millisecond), auth.WithMinRefreshInterval(0))
+		_, err := jwks.Key(context.Background(), "key")
+		assert.NoError(t, err)
+		keySet.setKeys(http.StatusInternalServerError)
+		time.Sleep(time.Millisecond * 5)
+		_, err = jwks.Key(context.Background(), "key")
+		assert.NoError(t, err)
+		assert.Equals(t, keySet.fetches.Load(), int32(2))
+	})
+
+	t.Run("when the first fetch fails it should return the error", func(t *testing.T) {
+		t.Parallel()
+		keySet, url := startKeySet(t)
+		keySet.setKeys(http.StatusNotFound)
+		jwks := auth.NewJWKS(url)
+		for range 3 {
+			_, err := jwks.Key(context.Background(), "key")
+			assert.ErrorExact(t, err, "failed to fetch the JSON web key set (status 404)")
+		}
+		assert.Equals(t, keySet.fetches.Load(), int32(1))
+	})
+
+	t.Run("when the endpoint fails after the set is stale it should serve the cached keys without fetching again within the minimum refresh interval", func(t *testing.T) {
+		t.Parallel()
+		keySet, url := startKeySet(t)
+		keySet.setKeys(http.StatusOK, rsaWebKey("key", &rsaKey.PublicKey))
+		jwks := auth.NewJWKS(url, auth.WithRefreshInterval(time.Millisecond), auth.WithMinRefreshInterval(time.Millisecond*50))
+		_, err := jwks.Key(context.Background(), "key")
+		assert.NoError(t, err)
+		keySet.setKeys(http.StatusInternalServerError)
+		time.Sleep(time.Millisecond * 60)
+		for range 5 {
+			_, err = jwks.Key(context.Background(), "key")
+			assert.NoError(t, err)
+		}
+		assert.Equals(t, keySet.fetches.Load(), int32(2))
+	})
+
+	t.Run("when keys are requested concurrently it should fetch the set once", func(t *testing.T) {
+		t.Parallel()
+		keySet, url := startKeySet(t)
+		keySet.setKeys(http.StatusOK, rsaWebKey("key", &rsaKey.PublicKey))
+		jwks := auth.NewJWKS(url)
+		var waitGroup sync.WaitGroup
+		for range 10 {
+			waitGroup.Add(1)
+			go func() {
+				defer waitGroup.Done()
+				_, err := jwks.Key(context.Background(), "key")
+				assert.NoError(t, err)
+			}()
+		}
+		waitGroup.Wait()
+		assert.Equals(t, keySet.fetches.Load(), int32(1))
+	})
+
+	t.Run("when the context of the request that started the fetch is cancelled it should still store the set", func(t *testing.T) {
+		t.Parallel()
+		keySet, url := startKeySet(t)
+		keySet.setKeys(http.StatusOK, rsaWebKey("key", &rsaKey.PublicKey))
+		jwks := auth.NewJWKS(url)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, _ = jwks.Key(ctx, "key")
+		_, err := jwks.Key(context.Background(), "key")
+		assert.NoError(t, err)
+		assert.Equals(t, keySet.fetches.Load(), int32(1))
+	})
+
+	t.Run("when a key of the set is invalid it should return an error", func(t *testing.T) {
+		t.Parallel()
+		keySet, url := startKeySet(t)
+		keySet.setKeys(http.StatusOK, map[string]string{"kty": "EC", "kid": "bad", "crv": "P-256", "x": encodeInt(big.NewInt(1)), "y": encodeInt(big.NewInt(1))})
+		_, err := auth.NewJWKS(url).Key(context.Background(), "bad")
+		assert.ErrorExact(t, err, "failed to decode the key 'bad' (the EC point is not on the curve)")
+	})
+}
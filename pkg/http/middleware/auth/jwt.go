@@ -0,0 +1,357 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Algorithm is a JSON Web Signature algorithm that can sign and verify tokens.
+type Algorithm string
+
+const (
+	// HS256 is HMAC with SHA-256. The key is a []byte shared secret.
+	HS256 Algorithm = "HS256"
+
+	// RS256 is RSASSA-PKCS1-v1_5 with SHA-256. The key is an *rsa.PublicKey, or an *rsa.PrivateKey to sign.
+	RS256 Algorithm = "RS256"
+
+	// ES256 is ECDSA with the P-256 curve and SHA-256. The key is an *ecdsa.PublicKey, or an *ecdsa.PrivateKey to sign.
+	ES256 Algorithm = "ES256"
+
+	// es256ComponentSize is the size of the R and S components of an ES256 signature.
+	es256ComponentSize = 32
+)
+
+// Audience is the aud claim. It is encoded as a string if it has one value, and as an array otherwise.
+type Audience []string
+
+// UnmarshalJSON decodes the audience from a string or an array of strings.
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return errors.New("the audience must be a string or an array of strings")
+	}
+	*a = many
+	return nil
+}
+
+// MarshalJSON encodes the audience as a string if it has one value, and as an array otherwise.
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+// NumericDate is a JSON numeric date, which is the number of seconds since the Unix epoch.
+type NumericDate struct {
+	time.Time
+}
+
+// NewNumericDate truncates the time to the second and wraps it in a NumericDate.
+func NewNumericDate(t time.Time) *NumericDate {
+	return &NumericDate{Time: t.Truncate(time.Second)}
+}
+
+// UnmarshalJSON decodes the numeric date from a number of seconds, which can have a fractional part.
+func (d *NumericDate) UnmarshalJSON(data []byte) error {
+	var seconds float64
+	if err := json.Unmarshal(data, &seconds); err != nil {
+		return errors.New("the numeric date must be a number")
+	}
+	whole, fraction := int64(seconds), seconds-float64(int64(seconds))
+	d.Time = time.Unix(whole, int64(fraction*float64(time.Second)))
+	return nil
+}
+
+// MarshalJSON encodes the numeric date as a number of seconds.
+func (d NumericDate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Unix())
+}
+
+// RegisteredClaims are the claims of RFC 7519 that are checked when a token is verified.
+// Embed it in a claims struct to decode it together with custom claims.
+type RegisteredClaims struct {
+	Issuer    string       `json:"iss,omitempty"`
+	Subject   string       `json:"sub,omitempty"`
+	Audience  Audience     `json:"aud,omitempty"`
+	ExpiresAt *NumericDate `json:"exp,omitempty"`
+	NotBefore *NumericDate `json:"nbf,omitempty"`
+	IssuedAt  *NumericDate `json:"iat,omitempty"`
+	ID        string       `json:"jti,omitempty"`
+}
+
+// header is the JOSE header of a token.
+type header struct {
+	Algorithm Algorithm `json:"alg"`
+	Type      string    `json:"typ,omitempty"`
+	KeyID     string    `json:"kid,omitempty"`
+}
+
+// KeySource provides the key that verifies the signature of a token.
+type KeySource interface {
+	// Key returns the verification key with the ID from the token header. The ID is empty if the header has none.
+	Key(ctx context.Context, keyID string) (any, error)
+}
+
+// KeySourceFunc is a function that implements the KeySource interface.
+type KeySourceFunc func(ctx context.Context, keyID string) (any, error)
+
+// Key calls the function.
+func (f KeySourceFunc) Key(ctx context.Context, keyID string) (any, error) {
+	return f(ctx, keyID)
+}
+
+// StaticKey returns a KeySource that verifies every token with the key, regardless of its key ID.
+func StaticKey(key any) KeySource {
+	return KeySourceFunc(func(context.Context, string) (any, error) {
+		return key, nil
+	})
+}
+
+// config is configured by the Option functions.
+type config struct {
+	algorithms []Algorithm
+	audience   string
+	issuer     string
+	leeway     time.Duration
+}
+
+// Option is used to configure how tokens are verified.
+type Option func(cfg *config)
+
+// newConfig allocates a config with its default values and applies the options.
+func newConfig(opts ...Option) *config {
+	cfg := &config{
+		algorithms: []Algorithm{HS256, RS256, ES256},
+		audience:   "",
+		issuer:     "",
+		leeway:     0,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// WithAlgorithms restricts the algorithms that tokens can be signed with. By default, HS256, RS256, and ES256
+// are accepted. If no algorithm is provided, or an algorithm is not supported, this function panics.
+func WithAlgorithms(algorithms ...Algorithm) Option {
+	if len(algorithms) == 0 {
+		panic("at least one algorithm must be provided")
+	}
+	for _, algorithm := range algorithms {
+		if algorithm != HS256 && algorithm != RS256 && algorithm != ES256 {
+			panic(fmt.Sprintf("the algorithm '%s' is not supported", algorithm))
+		}
+	}
+	return func(cfg *config) {
+		cfg.algorithms = algorithms
+	}
+}
+
+// WithAudience requires the aud claim of the tokens to contain the audience.
+func WithAudience(audience string) Option {
+	return func(cfg *config) {
+		cfg.audience = audience
+	}
+}
+
+// WithIssuer requires the iss claim of the tokens to equal the issuer.
+func WithIssuer(issuer string) Option {
+	return func(cfg *config) {
+		cfg.issuer = issuer
+	}
+}
+
+// WithLeeway allows for clock skew between the issuer and the server when checking the exp and nbf claims.
+func WithLeeway(leeway time.Duration) Option {
+	return func(cfg *config) {
+		cfg.leeway = leeway
+	}
+}
+
+// Verify checks the signature and registered claims of the token and decodes its payload into the Claims.
+// The exp and nbf claims are checked if they are present, as are the aud and iss claims if WithAudience
+// and WithIssuer are used.
+func Verify[Claims any](ctx context.Context, token string, keys KeySource, opts ...Option) (*Claims, *RegisteredClaims, error) {
+	cfg := newConfig(opts...)
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, nil, errors.New("the token must have three parts")
+	}
+
+	tokenHeader := &header{}
+	if err := decodeSegment(parts[0], tokenHeader); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode the token header (%w)", err)
+	}
+	if !slices.Contains(cfg.algorithms, tokenHeader.Algorithm) {
+		return nil, nil, fmt.Errorf("the token algorithm '%s' is not allowed", tokenHeader.Algorithm)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode the token signature (%w)", err)
+	}
+	key, err := keys.Key(ctx, tokenHeader.KeyID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get the key of the token (%w)", err)
+	}
+	if err := verifySignature(tokenHeader.Algorithm, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, nil, err
+	}
+
+	registeredClaims := &RegisteredClaims{}
+	if err := decodeSegment(parts[1], registeredClaims); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode the token claims (%w)", err)
+	}
+	if err := checkRegisteredClaims(registeredClaims, cfg); err != nil {
+		return nil, nil, err
+	}
+	claims := new(Claims)
+	if err := decodeSegment(parts[1], claims); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode the token claims (%w)", err)
+	}
+
+	return claims, registeredClaims, nil
+}
+
+// decodeSegment decodes a base64url encoded JSON segment of a token.
+func decodeSegment(segment string, value any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, value)
+}
+
+// verifySignature returns an error if the signature of the signing input is not valid for the algorithm and key.
+func verifySignature(algorithm Algorithm, key any, signingInput string, signature []byte) error {
+	digest := sha256.Sum256([]byte(signingInput))
+	switch algorithm {
+	case HS256:
+		secret, ok := key.([]byte)
+		if !ok {
+			return fmt.Errorf("the key for %s must be a []byte but is %T", algorithm, key)
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return errors.New("the token signature is invalid")
+		}
+	case RS256:
+		publicKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("the key for %s must be an *rsa.PublicKey but is %T", algorithm, key)
+		}
+		if err := rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature); err != nil {
+			return errors.New("the token signature is invalid")
+		}
+	case ES256:
+		publicKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || publicKey.Curve != elliptic.P256() {
+			return fmt.Errorf("the key for %s must be an *ecdsa.PublicKey on the P-256 curve but is %T", algorithm, key)
+		}
+		if len(signature) != 2*es256ComponentSize {
+			return errors.New("the token signature is invalid")
+		}
+		r := new(big.Int).SetBytes(signature[:es256ComponentSize])
+		s := new(big.Int).SetBytes(signature[es256ComponentSize:])
+		if !ecdsa.Verify(publicKey, digest[:], r, s) {
+			return errors.New("the token signature is invalid")
+		}
+	default:
+		return fmt.Errorf("the token algorithm '%s' is not supported", algorithm)
+	}
+	return nil
+}
+
+// checkRegisteredClaims returns an error if the token is expired, not yet valid, or for another audience or issuer.
+func checkRegisteredClaims(claims *RegisteredClaims, cfg *config) error {
+	now := time.Now()
+	if claims.ExpiresAt != nil && !now.Before(claims.ExpiresAt.Add(cfg.leeway)) {
+		return errors.New("the token is expired")
+	}
+	if claims.NotBefore != nil && now.Before(claims.NotBefore.Add(-cfg.leeway)) {
+		return errors.New("the token is not valid yet")
+	}
+	if cfg.audience != "" && !slices.Contains(claims.Audience, cfg.audience) {
+		return fmt.Errorf("the token is not intended for the audience '%s'", cfg.audience)
+	}
+	if cfg.issuer != "" && claims.Issuer != cfg.issuer {
+		return fmt.Errorf("the token is not issued by '%s'", cfg.issuer)
+	}
+	return nil
+}
+
+// Sign encodes the claims and signs them with the algorithm and key. The key is a []byte for HS256,
+// an *rsa.PrivateKey for RS256, and an *ecdsa.PrivateKey for ES256. The key ID is put in the header if it is not empty.
+func Sign(claims any, algorithm Algorithm, keyID string, key any) (string, error) {
+	headerJSON, err := json.Marshal(header{Algorithm: algorithm, Type: "JWT", KeyID: keyID})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode the token header (%w)", err)
+	}
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode the token claims (%w)", err)
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+	switch algorithm {
+	case HS256:
+		secret, ok := key.([]byte)
+		if !ok {
+			return "", fmt.Errorf("the key for %s must be a []byte but is %T", algorithm, key)
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(signingInput))
+		signature = mac.Sum(nil)
+	case RS256:
+		privateKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return "", fmt.Errorf("the key for %s must be an *rsa.PrivateKey but is %T", algorithm, key)
+		}
+		signature, err = rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, digest[:])
+		if err != nil {
+			return "", fmt.Errorf("failed to sign the token (%w)", err)
+		}
+	case ES256:
+		privateKey, ok := key.(*ecdsa.PrivateKey)
+		if !ok || privateKey.Curve != elliptic.P256() {
+			return "", fmt.Errorf("the key for %s must be an *ecdsa.PrivateKey on the P-256 curve but is %T", algorithm, key)
+		}
+		r, s, err := ecdsa.Sign(rand.Reader, privateKey, digest[:])
+		if err != nil {
+			return "", fmt.Errorf("failed to sign the token (%w)", err)
+		}
+		signature = make([]byte, 2*es256ComponentSize)
+		r.FillBytes(signature[:es256ComponentSize])
+		s.FillBytes(signature[es256ComponentSize:])
+	default:
+		return "", fmt.Errorf("the token algorithm '%s' is not supported", algorithm)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
@@ -0,0 +1,182 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/http/middleware/auth"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+type testClaims struct {
+	auth.RegisteredClaims
+	Role string `json:"role"`
+}
+
+func TestJWT(t *testing.T) {
+	t.Parallel()
+
+	secret := []byte("secret")
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	validClaims := func() *testClaims {
+		return &testClaims{
+			RegisteredClaims: auth.RegisteredClaims{
+				Issuer:    "issuer",
+				Subject:   "subject",
+				Audience:  auth.Audience{"api"},
+				ExpiresAt: auth.NewNumericDate(time.Now().Add(time.Hour)),
+				NotBefore: auth.NewNumericDate(time.Now().Add(-time.Minute)),
+			},
+			Role: "admin",
+		}
+	}
+
+	mustSign := func(t *testing.T, claims any, algorithm auth.Algorithm, key any) string {
+		t.Helper()
+		token, err := auth.Sign(claims, algorithm, "", key)
+		assert.NoError(t, err)
+		return token
+	}
+
+	t.Run("when a token is signed with each algorithm it should verify with the matching key", func(t *testing.T) {
+		t.Parallel()
+		testCases := []struct {
+			algorithm auth.Algorithm
+			signKey   any
+			verifyKey any
+		}{
+			{algorithm: auth.HS256, signKey: secret, verifyKey: secret},
+			{algorithm: auth.RS256, signKey: rsaKey, verifyKey: &rsaKey.PublicKey},
+			{algorithm: auth.ES256, signKey: ecKey, verifyKey: &ecKey.PublicKey},
+		}
+		for _, testCase := range testCases {
+			token := mustSign(t, validClaims(), testCase.algorithm, testCase.signKey)
+			claims, registeredClaims, err := auth.Verify[testClaims](context.Background(), token, auth.StaticKey(testCase.verifyKey), auth.WithAudience("api"), auth.WithIssuer("issuer"))
+			assert.NoError(t, err)
+			assert.Equals(t, claims.Role, "admin")
+			assert.Equals(t, claims.Subject, "subject")
+			assert.Equals(t, registeredClaims.Subject, "subject")
+			assert.Equals(t, registeredClaims.Audience, auth.Audience{"api"})
+		}
+	})
+
+	t.Run("when the key does not match the algorithm it should fail", func(t *testing.T) {
+		t.Parallel()
+		token := mustSign(t, validClaims(), auth.RS256, rsaKey)
+		_, _, err := auth.Verify[testClaims](context.Background(), token, auth.StaticKey(secret))
+		assert.ErrorExact(t, err, "the key for RS256 must be an *rsa.PublicKey but is []uint8")
+	})
+
+	t.Run("when the signature is tampered it should fail", func(t *testing.T) {
+		t.Parallel()
+		for _, testCase := range []struct {
+			algorithm auth.Algorithm
+			signKey   any
+			verifyKey any
+		}{
+			{algorithm: auth.HS256, signKey: secret, verifyKey: []byte("other")},
+			{algorithm: auth.RS256, signKey: rsaKey, verifyKey: &rsaKey.PublicKey},
+			{algorithm: auth.ES256, signKey: ecKey, verifyKey: &ecKey.PublicKey},
+		} {
+			token := mustSign(t, validClaims(), testCase.algorithm, testCase.signKey)
+			parts := strings.Split(token, ".")
+			if testCase.algorithm != auth.HS256 {
+				parts[1] = base64.RawURLEncoding.EncodeToString([]byte(`{"role":"admin","sub":"someone else"}`))
+			}
+			_, _, err := auth.Verify[testClaims](context.Background(), strings.Join(parts, "."), auth.StaticKey(testCase.verifyKey))
+			assert.ErrorExact(t, err, "the token signature is invalid")
+		}
+	})
+
+	t.Run("when the algorithm is not allowed it should fail", func(t *testing.T) {
+		t.Parallel()
+		token := mustSign(t, validClaims(), auth.HS256, secret)
+		_, _, err := auth.Verify[testClaims](context.Background(), token, auth.StaticKey(secret), auth.WithAlgorithms(auth.RS256))
+		assert.ErrorExact(t, err, "the token algorithm 'HS256' is not allowed")
+		unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + base64.RawURLEncoding.EncodeToString([]byte(`{}`)) + "."
+		_, _, err = auth.Verify[testClaims](context.Background(), unsigned, auth.StaticKey(secret))
+		assert.ErrorExact(t, err, "the token algorithm 'none' is not allowed")
+	})
+
+	t.Run("when the algorithms option is invalid it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicExact(t, func() {
+			auth.WithAlgorithms()
+		}, "at least one algorithm must be provided")
+		assert.PanicExact(t, func() {
+			auth.WithAlgorithms("none")
+		}, "the algorithm 'none' is not supported")
+	})
+
+	t.Run("when the token is malformed it should fail", func(t *testing.T) {
+		t.Parallel()
+		_, _, err := auth.Verify[testClaims](context.Background(), "a.b", auth.StaticKey(secret))
+		assert.ErrorExact(t, err, "the token must have three parts")
+		_, _, err = auth.Verify[testClaims](context.Background(), "!.b.c", auth.StaticKey(secret))
+		assert.ErrorPart(t, err, "failed to decode the token header")
+	})
+
+	t.Run("when the registered claims are not satisfied it should fail", func(t *testing.T) {
+		t.Parallel()
+		expired := validClaims()
+		expired.ExpiresAt = auth.NewNumericDate(time.Now().Add(-time.Minute))
+		notYetValid := validClaims()
+		notYetValid.NotBefore = auth.NewNumericDate(time.Now().Add(time.Hour))
+		testCases := []struct {
+			claims      *testClaims
+			opts        []auth.Option
+			expectedErr string
+		}{
+			{claims: expired, expectedErr: "the token is expired"},
+			{claims: notYetValid, expectedErr: "the token is not valid yet"},
+			{claims: validClaims(), opts: []auth.Option{auth.WithAudience("other")}, expectedErr: "the token is not intended for the audience 'other'"},
+			{claims: validClaims(), opts: []auth.Option{auth.WithIssuer("other")}, expectedErr: "the token is not issued by 'other'"},
+		}
+		for _, testCase := range testCases {
+			token := mustSign(t, testCase.claims, auth.HS256, secret)
+			_, _, err := auth.Verify[testClaims](context.Background(), token, auth.StaticKey(secret), testCase.opts...)
+			assert.ErrorExact(t, err, testCase.expectedErr)
+		}
+	})
+
+	t.Run("when the leeway covers the clock skew it should accept the token", func(t *testing.T) {
+		t.Parallel()
+		claims := validClaims()
+		claims.ExpiresAt = auth.NewNumericDate(time.Now().Add(-time.Minute))
+		token := mustSign(t, claims, auth.HS256, secret)
+		_, _, err := auth.Verify[testClaims](context.Background(), token, auth.StaticKey(secret), auth.WithLeeway(time.Minute*5))
+		assert.NoError(t, err)
+	})
+
+	t.Run("when the audience has many values it should be encoded as an array", func(t *testing.T) {
+		t.Parallel()
+		claims := validClaims()
+		claims.Audience = auth.Audience{"web", "api"}
+		token := mustSign(t, claims, auth.HS256, secret)
+		payload, err := base64.RawURLEncoding.DecodeString(strings.Split(token, ".")[1])
+		assert.NoError(t, err)
+		assert.Contains(t, string(payload), `"aud":["web","api"]`)
+		_, registeredClaims, err := auth.Verify[testClaims](context.Background(), token, auth.StaticKey(secret), auth.WithAudience("api"))
+		assert.NoError(t, err)
+		assert.Equals(t, registeredClaims.Audience, auth.Audience{"web", "api"})
+	})
+
+	t.Run("when the signing key does not match the algorithm it should fail to sign", func(t *testing.T) {
+		t.Parallel()
+		_, err := auth.Sign(validClaims(), auth.ES256, "", rsaKey)
+		assert.ErrorPart(t, err, "the key for ES256 must be an *ecdsa.PrivateKey on the P-256 curve")
+		_, err = auth.Sign(validClaims(), "none", "", secret)
+		assert.ErrorExact(t, err, "the token algorithm 'none' is not supported")
+	})
+}
@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	httperrors "github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/middleware"
+	"github.com/TriangleSide/GoBase/pkg/http/responders"
+	"github.com/TriangleSide/GoBase/pkg/logger"
+	"github.com/TriangleSide/GoBase/pkg/utils/ctxkey"
+)
+
+const (
+	// UserIDLogField is the logger field that holds the subject of the token.
+	UserIDLogField = "user_id"
+
+	// bearerPrefix is the prefix of the Authorization header value for bearer tokens.
+	bearerPrefix = "Bearer "
+)
+
+// registeredClaimsKey is the context key of the registered claims of the token.
+var registeredClaimsKey = ctxkey.New[*RegisteredClaims]("registeredClaims")
+
+// claimsKey is the context key of the claims of the token. It is a distinct type for every claims type.
+type claimsKey[Claims any] struct{}
+
+// JWT returns a Middleware that authenticates requests with a bearer token in the Authorization header.
+//
+// The token is verified with the keys and options, as done by Verify. Requests without a valid token are
+// rejected with an HTTP 401 unauthorized. Otherwise, the claims are put in the request context, where they
+// can be read with ClaimsFromContext and RegisteredClaimsFromContext. The subject of the token is added to the
+// logger fields of the context, so that every log of the request includes it.
+func JWT[Claims any](keys KeySource, opts ...Option) middleware.Middleware {
+	if keys == nil {
+		panic("the key source cannot be nil")
+	}
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(writer http.ResponseWriter, request *http.Request) {
+			authorization := request.Header.Get(headers.Authorization)
+			if len(authorization) < len(bearerPrefix) || !strings.EqualFold(authorization[:len(bearerPrefix)], bearerPrefix) {
+				writer.Header().Set(headers.WWWAuthenticate, "Bearer")
+				responders.Error(request, writer, &httperrors.Unauthorized{Err: errors.New("the request does not have a bearer token")})
+				return
+			}
+
+			claims, registeredClaims, err := Verify[Claims](request.Context(), authorization[len(bearerPrefix):], keys, opts...)
+			if err != nil {
+				logger.Debugf(request.Context(), "The bearer token is invalid (%s).", err)
+				writer.Header().Set(headers.WWWAuthenticate, `Bearer error="invalid_token"`)
+				responders.Error(request, writer, &httperrors.Unauthorized{Err: fmt.Errorf("the bearer token is invalid (%w)", err)})
+				return
+			}
+
+			ctx := registeredClaimsKey.WithValue(request.Context(), registeredClaims)
+			ctx = context.WithValue(ctx, claimsKey[Claims]{}, claims)
+			if registeredClaims.Subject != "" {
+				ctx = logger.WithField(ctx, UserIDLogField, registeredClaims.Subject)
+			}
+			next(writer, request.WithContext(ctx))
+		}
+	}
+}
+
+// ClaimsFromContext returns the claims put in the context by the JWT middleware with the same Claims type.
+func ClaimsFromContext[Claims any](ctx context.Context) (*Claims, bool) {
+	claims, found := ctx.Value(claimsKey[Claims]{}).(*Claims)
+	return claims, found
+}
+
+// RegisteredClaimsFromContext returns the registered claims put in the context by the JWT middleware.
+func RegisteredClaimsFromContext(ctx context.Context) (*RegisteredClaims, bool) {
+	return registeredClaimsKey.Value(ctx)
+}
@@ -0,0 +1,96 @@
+package auth_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	httperrors "github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/middleware"
+	"github.com/TriangleSide/GoBase/pkg/http/middleware/auth"
+	"github.com/TriangleSide/GoBase/pkg/logger"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestJWTMiddleware(t *testing.T) {
+	t.Parallel()
+
+	secret := []byte("secret")
+
+	serve := func(authorization string) (*httptest.ResponseRecorder, *testClaims) {
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		if authorization != "" {
+			request.Header.Set(headers.Authorization, authorization)
+		}
+		recorder := httptest.NewRecorder()
+		var claims *testClaims
+		middleware.CreateChain([]middleware.Middleware{auth.JWT[testClaims](auth.StaticKey(secret), auth.WithAudience("api"))}, func(writer http.ResponseWriter, request *http.Request) {
+			var found bool
+			claims, found = auth.ClaimsFromContext[testClaims](request.Context())
+			assert.True(t, found)
+			registeredClaims, found := auth.RegisteredClaimsFromContext(request.Context())
+			assert.True(t, found)
+			assert.Equals(t, registeredClaims.Subject, claims.Subject)
+			assert.Equals(t, logger.Fields(request.Context())[auth.UserIDLogField], any(claims.Subject))
+			writer.WriteHeader(http.StatusOK)
+		})(recorder, request)
+		return recorder, claims
+	}
+
+	decodeError := func(t *testing.T, recorder *httptest.ResponseRecorder) string {
+		t.Helper()
+		httpError := &httperrors.Error{}
+		assert.NoError(t, json.NewDecoder(recorder.Body).Decode(httpError))
+		return httpError.Message
+	}
+
+	t.Run("when the key source is nil it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicExact(t, func() {
+			auth.JWT[testClaims](nil)
+		}, "the key source cannot be nil")
+	})
+
+	t.Run("when the token is valid it should put the claims in the context", func(t *testing.T) {
+		t.Parallel()
+		token, err := auth.Sign(&testClaims{
+			RegisteredClaims: auth.RegisteredClaims{Subject: "user", Audience: auth.Audience{"api"}, ExpiresAt: auth.NewNumericDate(time.Now().Add(time.Hour))},
+			Role:             "reader",
+		}, auth.HS256, "", secret)
+		assert.NoError(t, err)
+		recorder, claims := serve("bearer " + token)
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.Equals(t, claims.Subject, "user")
+		assert.Equals(t, claims.Role, "reader")
+	})
+
+	t.Run("when the request has no bearer token it should respond with unauthorized", func(t *testing.T) {
+		t.Parallel()
+		for _, authorization := range []string{"", "Basic dXNlcjpwYXNz"} {
+			recorder, _ := serve(authorization)
+			assert.Equals(t, recorder.Code, http.StatusUnauthorized)
+			assert.Equals(t, recorder.Header().Get(headers.WWWAuthenticate), "Bearer")
+			assert.Equals(t, decodeError(t, recorder), "the request does not have a bearer token")
+		}
+	})
+
+	t.Run("when the token is invalid it should respond with unauthorized", func(t *testing.T) {
+		t.Parallel()
+		token, err := auth.Sign(&testClaims{RegisteredClaims: auth.RegisteredClaims{Audience: auth.Audience{"other"}}}, auth.HS256, "", secret)
+		assert.NoError(t, err)
+		recorder, _ := serve("Bearer " + token)
+		assert.Equals(t, recorder.Code, http.StatusUnauthorized)
+		assert.Equals(t, recorder.Header().Get(headers.WWWAuthenticate), `Bearer error="invalid_token"`)
+		assert.Equals(t, decodeError(t, recorder), "the bearer token is invalid (the token is not intended for the audience 'api')")
+	})
+
+	t.Run("when the claims type does not match the middleware it should not be found", func(t *testing.T) {
+		t.Parallel()
+		claims, found := auth.ClaimsFromContext[auth.RegisteredClaims](httptest.NewRequest(http.MethodGet, "/", nil).Context())
+		assert.False(t, found)
+		assert.Nil(t, claims)
+	})
+}
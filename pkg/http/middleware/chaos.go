@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	httperrors "github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/logger"
+)
+
+// ChaosFault describes the fault that the Chaos middleware injects into the requests it affects.
+type ChaosFault struct {
+	// Probability is the chance, from 0 to 1, that a matching request is affected.
+	Probability float64
+
+	// RouteMatcher selects the requests that can be affected. All requests match if it is nil.
+	RouteMatcher func(request *http.Request) bool
+
+	// Latency is added before the request is handled.
+	Latency time.Duration
+
+	// StatusCode, if not zero, is returned instead of calling the next handler.
+	StatusCode int
+
+	// DropConnection aborts the connection instead of calling the next handler.
+	DropConnection bool
+}
+
+// ChaosConfig holds the fault injected by the Chaos middleware. It can be changed while the server is running.
+// A new ChaosConfig is disabled, so no fault is injected until Enable is called.
+type ChaosConfig struct {
+	fault atomic.Pointer[ChaosFault]
+}
+
+// NewChaosConfig allocates and initializes a disabled ChaosConfig.
+func NewChaosConfig() *ChaosConfig {
+	return &ChaosConfig{}
+}
+
+// Enable starts injecting the fault. If the fault is invalid, this function panics.
+func (c *ChaosConfig) Enable(fault ChaosFault) {
+	if fault.Probability < 0 || fault.Probability > 1 {
+		panic(fmt.Sprintf("the chaos probability %v must be between 0 and 1", fault.Probability))
+	}
+	if fault.Latency < 0 {
+		panic("the chaos latency cannot be negative")
+	}
+	if fault.StatusCode != 0 && (fault.StatusCode < 100 || fault.StatusCode > 999) {
+		panic(fmt.Sprintf("the chaos status code %d is invalid", fault.StatusCode))
+	}
+	c.fault.Store(&fault)
+}
+
+// Disable stops injecting faults.
+func (c *ChaosConfig) Disable() {
+	c.fault.Store(nil)
+}
+
+// Chaos returns a Middleware that injects the fault of the ChaosConfig into the requests it affects.
+// The latency is applied first, stopping early if the request is cancelled. Then the connection is dropped,
+// the status code is returned, or the next handler is called. This is meant for testing resilience, like
+// timeouts and retries, and should never be enabled by default.
+func Chaos(config *ChaosConfig) Middleware {
+	if config == nil {
+		panic("the chaos config cannot be nil")
+	}
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(writer http.ResponseWriter, request *http.Request) {
+			fault := config.fault.Load()
+			if fault == nil || (fault.RouteMatcher != nil && !fault.RouteMatcher(request)) || rand.Float64() >= fault.Probability {
+				next(writer, request)
+				return
+			}
+
+			if fault.Latency > 0 {
+				timer := time.NewTimer(fault.Latency)
+				select {
+				case <-timer.C:
+				case <-request.Context().Done():
+					timer.Stop()
+					return
+				}
+			}
+
+			switch {
+			case fault.DropConnection:
+				panic(http.ErrAbortHandler)
+			case fault.StatusCode != 0:
+				writer.Header().Set(headers.ContentType, headers.ContentTypeApplicationJson)
+				writer.WriteHeader(fault.StatusCode)
+				if err := json.NewEncoder(writer).Encode(httperrors.Error{
+					Message:   "fault injected by chaos testing",
+					Code:      httperrors.CodeForStatus(fault.StatusCode),
+					Retryable: httperrors.IsRetryableStatus(fault.StatusCode),
+				}); err != nil {
+					logger.Errorf(request.Context(), "Error encoding chaos response (%s).", err)
+				}
+			default:
+				next(writer, request)
+			}
+		}
+	}
+}
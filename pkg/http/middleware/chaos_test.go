@@ -0,0 +1,129 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/http/middleware"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestChaos(t *testing.T) {
+	t.Parallel()
+
+	serve := func(config *middleware.ChaosConfig, request *http.Request) (*httptest.ResponseRecorder, bool) {
+		recorder := httptest.NewRecorder()
+		nextCalled := false
+		middleware.CreateChain([]middleware.Middleware{middleware.Chaos(config)}, func(writer http.ResponseWriter, request *http.Request) {
+			nextCalled = true
+			writer.WriteHeader(http.StatusOK)
+		})(recorder, request)
+		return recorder, nextCalled
+	}
+
+	t.Run("when the config is invalid it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicExact(t, func() {
+			middleware.Chaos(nil)
+		}, "the chaos config cannot be nil")
+		config := middleware.NewChaosConfig()
+		assert.PanicExact(t, func() {
+			config.Enable(middleware.ChaosFault{Probability: 1.5})
+		}, "the chaos probability 1.5 must be between 0 and 1")
+		assert.PanicExact(t, func() {
+			config.Enable(middleware.ChaosFault{Probability: 1, Latency: -time.Second})
+		}, "the chaos latency cannot be negative")
+		assert.PanicExact(t, func() {
+			config.Enable(middleware.ChaosFault{Probability: 1, StatusCode: 42})
+		}, "the chaos status code 42 is invalid")
+	})
+
+	t.Run("when the config is not enabled it should call the next handler", func(t *testing.T) {
+		t.Parallel()
+		recorder, nextCalled := serve(middleware.NewChaosConfig(), httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.True(t, nextCalled)
+		assert.Equals(t, recorder.Code, http.StatusOK)
+	})
+
+	t.Run("when the probability is 1 it should inject the status code", func(t *testing.T) {
+		t.Parallel()
+		config := middleware.NewChaosConfig()
+		config.Enable(middleware.ChaosFault{Probability: 1, StatusCode: http.StatusServiceUnavailable})
+		recorder, nextCalled := serve(config, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.False(t, nextCalled)
+		assert.Equals(t, recorder.Code, http.StatusServiceUnavailable)
+		assert.True(t, strings.Contains(recorder.Body.String(), "fault injected by chaos testing"))
+	})
+
+	t.Run("when the probability is 0 it should not inject the fault", func(t *testing.T) {
+		t.Parallel()
+		config := middleware.NewChaosConfig()
+		config.Enable(middleware.ChaosFault{Probability: 0, StatusCode: http.StatusServiceUnavailable, Latency: time.Hour})
+		for range 100 {
+			recorder, nextCalled := serve(config, httptest.NewRequest(http.MethodGet, "/", nil))
+			assert.True(t, nextCalled)
+			assert.Equals(t, recorder.Code, http.StatusOK)
+		}
+	})
+
+	t.Run("when the fault is disabled at runtime it should stop injecting it", func(t *testing.T) {
+		t.Parallel()
+		config := middleware.NewChaosConfig()
+		config.Enable(middleware.ChaosFault{Probability: 1, StatusCode: http.StatusTeapot})
+		recorder, _ := serve(config, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equals(t, recorder.Code, http.StatusTeapot)
+		config.Disable()
+		recorder, nextCalled := serve(config, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.True(t, nextCalled)
+		assert.Equals(t, recorder.Code, http.StatusOK)
+	})
+
+	t.Run("when the route does not match it should not inject the fault", func(t *testing.T) {
+		t.Parallel()
+		config := middleware.NewChaosConfig()
+		config.Enable(middleware.ChaosFault{
+			Probability:  1,
+			StatusCode:   http.StatusBadGateway,
+			RouteMatcher: func(request *http.Request) bool { return strings.HasPrefix(request.URL.Path, "/orders") },
+		})
+		recorder, nextCalled := serve(config, httptest.NewRequest(http.MethodGet, "/users", nil))
+		assert.True(t, nextCalled)
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		recorder, nextCalled = serve(config, httptest.NewRequest(http.MethodGet, "/orders/1", nil))
+		assert.False(t, nextCalled)
+		assert.Equals(t, recorder.Code, http.StatusBadGateway)
+	})
+
+	t.Run("when latency is configured it should delay the request", func(t *testing.T) {
+		t.Parallel()
+		config := middleware.NewChaosConfig()
+		config.Enable(middleware.ChaosFault{Probability: 1, Latency: time.Millisecond * 50})
+		start := time.Now()
+		_, nextCalled := serve(config, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.True(t, nextCalled)
+		assert.True(t, time.Since(start) >= time.Millisecond*50)
+	})
+
+	t.Run("when the request is cancelled during the latency it should return early", func(t *testing.T) {
+		t.Parallel()
+		config := middleware.NewChaosConfig()
+		config.Enable(middleware.ChaosFault{Probability: 1, Latency: time.Hour})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, nextCalled := serve(config, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+		assert.False(t, nextCalled)
+	})
+
+	t.Run("when the connection is dropped it should abort the handler", func(t *testing.T) {
+		t.Parallel()
+		config := middleware.NewChaosConfig()
+		config.Enable(middleware.ChaosFault{Probability: 1, DropConnection: true})
+		assert.PanicExact(t, func() {
+			serve(config, httptest.NewRequest(http.MethodGet, "/", nil))
+		}, http.ErrAbortHandler.Error())
+	})
+}
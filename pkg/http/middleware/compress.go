@@ -0,0 +1,198 @@
+package middleware
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+)
+
+// Algorithm is a content coding that can be used to compress a response body.
+type Algorithm string
+
+const (
+	// AlgorithmGzip compresses the response with the gzip format.
+	AlgorithmGzip Algorithm = "gzip"
+
+	// AlgorithmDeflate compresses the response with the deflate format in a zlib wrapper, as the HTTP deflate
+	// content coding requires.
+	AlgorithmDeflate Algorithm = "deflate"
+)
+
+// compressConfig is configured by the CompressOption functions.
+type compressConfig struct {
+	algorithms []Algorithm
+	level      int
+}
+
+// CompressOption is used to configure the Compress middleware.
+type CompressOption func(cfg *compressConfig)
+
+// WithAlgorithms sets the supported algorithms in order of preference.
+// The preference is used when the client weighs many algorithms equally.
+// The default is gzip followed by deflate. If an algorithm is not supported, this function panics.
+func WithAlgorithms(algorithms ...Algorithm) CompressOption {
+	if len(algorithms) == 0 {
+		panic("at least one compression algorithm must be provided")
+	}
+	for _, algorithm := range algorithms {
+		if algorithm != AlgorithmGzip && algorithm != AlgorithmDeflate {
+			panic(fmt.Sprintf("the compression algorithm '%s' is not supported", algorithm))
+		}
+	}
+	return func(cfg *compressConfig) {
+		cfg.algorithms = algorithms
+	}
+}
+
+// WithLevel sets the compression level. It ranges from flate.HuffmanOnly to flate.BestCompression.
+// The default is flate.DefaultCompression. If the level is out of range, this function panics.
+func WithLevel(level int) CompressOption {
+	if level < flate.HuffmanOnly || level > flate.BestCompression {
+		panic(fmt.Sprintf("the compression level %d is invalid", level))
+	}
+	return func(cfg *compressConfig) {
+		cfg.level = level
+	}
+}
+
+// Compress returns a Middleware that compresses the response body with the algorithm the client prefers.
+// The algorithm is negotiated with the q-values of the Accept-Encoding header. If the client does not
+// accept any of the supported algorithms, the response is sent uncompressed.
+func Compress(opts ...CompressOption) Middleware {
+	cfg := &compressConfig{
+		algorithms: []Algorithm{AlgorithmGzip, AlgorithmDeflate},
+		level:      flate.DefaultCompression,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(writer http.ResponseWriter, request *http.Request) {
+			writer.Header().Add(headers.Vary, headers.AcceptEncoding)
+			algorithm, accepted := negotiateAlgorithm(request.Header.Get(headers.AcceptEncoding), cfg.algorithms)
+			if !accepted || request.Method == http.MethodHead {
+				next(writer, request)
+				return
+			}
+			compressWriter := &compressResponseWriter{
+				ResponseWriter: writer,
+				algorithm:      algorithm,
+				level:          cfg.level,
+				encoder:        nil,
+				wroteHeader:    false,
+			}
+			defer func() {
+				if compressWriter.encoder != nil {
+					_ = compressWriter.encoder.Close()
+				}
+			}()
+			next(compressWriter, request)
+		}
+	}
+}
+
+// negotiateAlgorithm returns the supported algorithm with the highest q-value in the Accept-Encoding header.
+// Ties are broken with the order of the supported algorithms. The wildcard applies to algorithms that are not listed.
+func negotiateAlgorithm(acceptEncoding string, supported []Algorithm) (Algorithm, bool) {
+	qValues := make(map[string]float64)
+	for _, entry := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(entry), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding == "" {
+			continue
+		}
+		qValue := 1.0
+		if name, value, hasValue := strings.Cut(strings.TrimSpace(params), "="); hasValue && strings.TrimSpace(name) == "q" {
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				continue
+			}
+			qValue = parsed
+		}
+		qValues[coding] = qValue
+	}
+
+	var selected Algorithm
+	selectedQValue := 0.0
+	for _, algorithm := range supported {
+		qValue, listed := qValues[string(algorithm)]
+		if !listed {
+			qValue = qValues["*"]
+		}
+		if qValue > selectedQValue {
+			selected, selectedQValue = algorithm, qValue
+		}
+	}
+	return selected, selectedQValue > 0
+}
+
+// compressResponseWriter compresses what is written to the response with the negotiated algorithm.
+// Responses that already have a Content-Encoding, or that cannot have a body, are not compressed.
+type compressResponseWriter struct {
+	http.ResponseWriter
+	algorithm   Algorithm
+	level       int
+	encoder     io.WriteCloser
+	wroteHeader bool
+}
+
+// WriteHeader decides whether the response is compressed, then sends the HTTP response header with the status code.
+// Informational statuses, like 103 Early Hints, are sent without deciding since the final status comes after them.
+func (cw *compressResponseWriter) WriteHeader(statusCode int) {
+	if cw.wroteHeader || (statusCode >= 100 && statusCode < http.StatusOK) {
+		cw.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+	cw.wroteHeader = true
+
+	noBody := statusCode == http.StatusNoContent || statusCode == http.StatusNotModified
+	if !noBody && cw.Header().Get(headers.ContentEncoding) == "" {
+		cw.Header().Set(headers.ContentEncoding, string(cw.algorithm))
+		cw.Header().Del(headers.ContentLength)
+		switch cw.algorithm {
+		case AlgorithmGzip:
+			cw.encoder, _ = gzip.NewWriterLevel(cw.ResponseWriter, cw.level)
+		case AlgorithmDeflate:
+			cw.encoder, _ = zlib.NewWriterLevel(cw.ResponseWriter, cw.level)
+		}
+	}
+
+	cw.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write compresses the data as part of the HTTP reply. The header is implicitly written if it has not been.
+func (cw *compressResponseWriter) Write(data []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.encoder == nil {
+		return cw.ResponseWriter.Write(data)
+	}
+	return cw.encoder.Write(data)
+}
+
+// Flush sends the compressed data buffered so far to the client.
+func (cw *compressResponseWriter) Flush() {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if flusher, ok := cw.encoder.(interface{ Flush() error }); ok {
+		_ = flusher.Flush()
+	}
+	if flusher, ok := cw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying http.ResponseWriter. This is used by the http.ResponseController.
+func (cw *compressResponseWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
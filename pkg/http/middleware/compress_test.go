@@ -0,0 +1,169 @@
+package middleware_test
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/middleware"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestCompress(t *testing.T) {
+	t.Parallel()
+
+	const body = "the quick brown fox jumps over the lazy dog"
+
+	serve := func(t *testing.T, method string, acceptEncoding string, handler http.HandlerFunc, opts ...middleware.CompressOption) *httptest.ResponseRecorder {
+		t.Helper()
+		request := httptest.NewRequest(method, "/", nil)
+		if acceptEncoding != "" {
+			request.Header.Set(headers.AcceptEncoding, acceptEncoding)
+		}
+		recorder := httptest.NewRecorder()
+		middleware.CreateChain([]middleware.Middleware{middleware.Compress(opts...)}, handler)(recorder, request)
+		return recorder
+	}
+
+	bodyHandler := func(writer http.ResponseWriter, request *http.Request) {
+		_, err := io.WriteString(writer, body)
+		assert.NoError(t, err)
+	}
+
+	decompress := func(t *testing.T, recorder *httptest.ResponseRecorder) string {
+		t.Helper()
+		var reader io.Reader
+		switch recorder.Header().Get(headers.ContentEncoding) {
+		case "gzip":
+			gzipReader, err := gzip.NewReader(recorder.Body)
+			assert.NoError(t, err)
+			reader = gzipReader
+		case "deflate":
+			zlibReader, err := zlib.NewReader(recorder.Body)
+			assert.NoError(t, err)
+			reader = zlibReader
+		default:
+			reader = recorder.Body
+		}
+		decompressed, err := io.ReadAll(reader)
+		assert.NoError(t, err)
+		return string(decompressed)
+	}
+
+	t.Run("when invalid options are provided it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicExact(t, func() {
+			middleware.WithAlgorithms()
+		}, "at least one compression algorithm must be provided")
+		assert.PanicExact(t, func() {
+			middleware.WithAlgorithms("br")
+		}, "the compression algorithm 'br' is not supported")
+		assert.PanicExact(t, func() {
+			middleware.WithLevel(10)
+		}, "the compression level 10 is invalid")
+	})
+
+	t.Run("when the Accept-Encoding header varies it should select the expected algorithm", func(t *testing.T) {
+		t.Parallel()
+		testCases := []struct {
+			acceptEncoding string
+			opts           []middleware.CompressOption
+			expected       string
+		}{
+			{acceptEncoding: "", expected: ""},
+			{acceptEncoding: "gzip", expected: "gzip"},
+			{acceptEncoding: "deflate", expected: "deflate"},
+			{acceptEncoding: "br", expected: ""},
+			{acceptEncoding: "gzip, deflate", expected: "gzip"},
+			{acceptEncoding: "gzip;q=0.5, deflate;q=0.8", expected: "deflate"},
+			{acceptEncoding: "GZIP;q=0.9, deflate;q=0.1", expected: "gzip"},
+			{acceptEncoding: "gzip;q=0, deflate;q=0", expected: ""},
+			{acceptEncoding: "*", expected: "gzip"},
+			{acceptEncoding: "gzip;q=0, *;q=0.3", expected: "deflate"},
+			{acceptEncoding: "identity", expected: ""},
+			{acceptEncoding: "gzip, deflate", opts: []middleware.CompressOption{middleware.WithAlgorithms(middleware.AlgorithmDeflate, middleware.AlgorithmGzip)}, expected: "deflate"},
+			{acceptEncoding: "gzip", opts: []middleware.CompressOption{middleware.WithAlgorithms(middleware.AlgorithmDeflate)}, expected: ""},
+			{acceptEncoding: "deflate", opts: []middleware.CompressOption{middleware.WithLevel(flate.BestCompression)}, expected: "deflate"},
+		}
+		for _, testCase := range testCases {
+			recorder := serve(t, http.MethodGet, testCase.acceptEncoding, bodyHandler, testCase.opts...)
+			assert.Equals(t, recorder.Header().Get(headers.ContentEncoding), testCase.expected)
+			assert.Equals(t, recorder.Header().Get(headers.Vary), headers.AcceptEncoding)
+			assert.Equals(t, decompress(t, recorder), body)
+		}
+	})
+
+	t.Run("when the handler sets its own Content-Encoding it should not compress the response", func(t *testing.T) {
+		t.Parallel()
+		recorder := serve(t, http.MethodGet, "gzip", func(writer http.ResponseWriter, request *http.Request) {
+			writer.Header().Set(headers.ContentEncoding, "custom")
+			_, err := io.WriteString(writer, body)
+			assert.NoError(t, err)
+		})
+		assert.Equals(t, recorder.Header().Get(headers.ContentEncoding), "custom")
+		assert.Equals(t, recorder.Body.String(), body)
+	})
+
+	t.Run("when the response has no body it should not set a Content-Encoding", func(t *testing.T) {
+		t.Parallel()
+		recorder := serve(t, http.MethodGet, "gzip", func(writer http.ResponseWriter, request *http.Request) {
+			writer.WriteHeader(http.StatusNoContent)
+		})
+		assert.Equals(t, recorder.Code, http.StatusNoContent)
+		assert.Equals(t, recorder.Header().Get(headers.ContentEncoding), "")
+		assert.Equals(t, recorder.Body.Len(), 0)
+	})
+
+	t.Run("when the handler sends an informational status it should compress the final response", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(middleware.CreateChain([]middleware.Middleware{middleware.Compress()}, func(writer http.ResponseWriter, request *http.Request) {
+			writer.Header().Set("Link", "</style.css>; rel=preload")
+			writer.WriteHeader(http.StatusEarlyHints)
+			writer.WriteHeader(http.StatusCreated)
+			_, err := io.WriteString(writer, body)
+			assert.NoError(t, err)
+		}))
+		t.Cleanup(server.Close)
+		request, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		assert.NoError(t, err)
+		request.Header.Set(headers.AcceptEncoding, "deflate")
+		response, err := http.DefaultClient.Do(request)
+		assert.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, response.Body.Close())
+		})
+		assert.Equals(t, response.StatusCode, http.StatusCreated)
+		assert.Equals(t, response.Header.Get(headers.ContentEncoding), "deflate")
+		zlibReader, err := zlib.NewReader(response.Body)
+		assert.NoError(t, err)
+		decompressed, err := io.ReadAll(zlibReader)
+		assert.NoError(t, err)
+		assert.Equals(t, string(decompressed), body)
+	})
+
+	t.Run("when the request is a HEAD request it should not compress the response", func(t *testing.T) {
+		t.Parallel()
+		recorder := serve(t, http.MethodHead, "gzip", bodyHandler)
+		assert.Equals(t, recorder.Header().Get(headers.ContentEncoding), "")
+	})
+
+	t.Run("when the handler flushes the response it should send the data compressed so far", func(t *testing.T) {
+		t.Parallel()
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set(headers.AcceptEncoding, "gzip")
+		recorder := httptest.NewRecorder()
+		middleware.CreateChain([]middleware.Middleware{middleware.Compress()}, func(writer http.ResponseWriter, request *http.Request) {
+			_, err := io.WriteString(writer, body)
+			assert.NoError(t, err)
+			writer.(http.Flusher).Flush()
+			assert.True(t, recorder.Flushed)
+			assert.True(t, recorder.Body.Len() > 0)
+		})(recorder, request)
+		assert.Equals(t, decompress(t, recorder), body)
+	})
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/TriangleSide/GoBase/pkg/config/envprocessor"
+)
+
+// FromEnv builds a Middleware from a configuration struct that is read from the environment variables.
+// The struct C is processed with envprocessor, so it uses the same config_format, config_default and validate
+// tags as the rest of the configuration. The options, such as envprocessor.WithPrefix, are passed to the processor.
+//
+//	type RateLimitConfig struct {
+//		RequestsPerSecond int `config_format:"snake" config_default:"10" validate:"gt=0"`
+//	}
+//
+//	rateLimitMw, err := middleware.FromEnv(func(cfg RateLimitConfig) middleware.Middleware {
+//		return newRateLimiter(cfg.RequestsPerSecond)
+//	})
+func FromEnv[C any](construct func(cfg C) Middleware, opts ...envprocessor.Option) (Middleware, error) {
+	if construct == nil {
+		panic("the middleware constructor cannot be nil")
+	}
+	cfg, err := envprocessor.ProcessAndValidate[C](opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to process the middleware configuration (%w)", err)
+	}
+	mw := construct(*cfg)
+	if mw == nil {
+		return nil, errors.New("the constructor returned a nil middleware")
+	}
+	return mw, nil
+}
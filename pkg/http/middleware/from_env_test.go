@@ -0,0 +1,78 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/config/envprocessor"
+	"github.com/TriangleSide/GoBase/pkg/http/middleware"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+type rateLimitConfig struct {
+	RateLimitMaxRequests int `config_format:"snake" config_default:"10" validate:"gt=0"`
+}
+
+// newRateLimit returns a Middleware that rejects requests once the maximum number of requests is reached.
+func newRateLimit(cfg rateLimitConfig) middleware.Middleware {
+	remaining := cfg.RateLimitMaxRequests
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(writer http.ResponseWriter, request *http.Request) {
+			if remaining <= 0 {
+				writer.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			remaining--
+			next(writer, request)
+		}
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	serve := func(mw middleware.Middleware) int {
+		recorder := httptest.NewRecorder()
+		middleware.CreateChain([]middleware.Middleware{mw}, func(writer http.ResponseWriter, request *http.Request) {
+			writer.WriteHeader(http.StatusOK)
+		})(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+		return recorder.Code
+	}
+
+	t.Run("when the environment variables are set it should construct the middleware with them", func(t *testing.T) {
+		t.Setenv("RATE_LIMIT_MAX_REQUESTS", "2")
+		rateLimitMw, err := middleware.FromEnv(newRateLimit)
+		assert.NoError(t, err)
+		assert.Equals(t, serve(rateLimitMw), http.StatusOK)
+		assert.Equals(t, serve(rateLimitMw), http.StatusOK)
+		assert.Equals(t, serve(rateLimitMw), http.StatusTooManyRequests)
+	})
+
+	t.Run("when a prefix is used it should read the prefixed environment variables", func(t *testing.T) {
+		t.Setenv("API_RATE_LIMIT_MAX_REQUESTS", "1")
+		rateLimitMw, err := middleware.FromEnv(newRateLimit, envprocessor.WithPrefix("API"))
+		assert.NoError(t, err)
+		assert.Equals(t, serve(rateLimitMw), http.StatusOK)
+		assert.Equals(t, serve(rateLimitMw), http.StatusTooManyRequests)
+	})
+
+	t.Run("when the configuration is invalid it should return an error", func(t *testing.T) {
+		t.Setenv("RATE_LIMIT_MAX_REQUESTS", "0")
+		rateLimitMw, err := middleware.FromEnv(newRateLimit)
+		assert.ErrorPart(t, err, "failed to process the middleware configuration")
+		assert.Nil(t, rateLimitMw)
+	})
+
+	t.Run("when the constructor returns nil it should return an error", func(t *testing.T) {
+		rateLimitMw, err := middleware.FromEnv(func(rateLimitConfig) middleware.Middleware {
+			return nil
+		})
+		assert.ErrorExact(t, err, "the constructor returned a nil middleware")
+		assert.Nil(t, rateLimitMw)
+	})
+
+	t.Run("when the constructor is nil it should panic", func(t *testing.T) {
+		assert.PanicExact(t, func() {
+			_, _ = middleware.FromEnv[rateLimitConfig](nil)
+		}, "the middleware constructor cannot be nil")
+	})
+}
@@ -0,0 +1,200 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	httperrors "github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/http/responders"
+	"github.com/TriangleSide/GoBase/pkg/logger"
+)
+
+// errHandlerTimeout is returned to the client when the handler does not finish within the HandlerTimeout.
+var errHandlerTimeout = errors.New("the request was not handled in time")
+
+// Limits bounds the resources a request can use. A zero value for a field disables its limit.
+type Limits struct {
+	// MaxBodyBytes is the largest request body that is accepted. Requests that declare a larger Content-Length are
+	// rejected with an HTTP 413 request entity too large before the handler is called. Otherwise, reading past the
+	// limit fails with an http.MaxBytesError, which the responders turn into an HTTP 413.
+	MaxBodyBytes int64
+
+	// ReadTimeout is how long the handler has to read the request body. Reading after the deadline fails
+	// with os.ErrDeadlineExceeded, which the responders turn into an HTTP 408 request timeout.
+	ReadTimeout time.Duration
+
+	// HandlerTimeout is how long the handler has to respond. When it expires, the request context is cancelled and,
+	// if the handler has not written the header yet, the client gets an HTTP 408 request timeout. Writes the handler
+	// makes after the timeout fail with http.ErrHandlerTimeout. Connections cannot be hijacked with this limit.
+	HandlerTimeout time.Duration
+}
+
+// Middleware returns a Middleware that enforces the limits. If a limit is negative, this function panics.
+func (limits *Limits) Middleware() Middleware {
+	if limits.MaxBodyBytes < 0 || limits.ReadTimeout < 0 || limits.HandlerTimeout < 0 {
+		panic("the limits cannot be negative")
+	}
+	maxBodyBytes := limits.MaxBodyBytes
+	readTimeout := limits.ReadTimeout
+	handlerTimeout := limits.HandlerTimeout
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(writer http.ResponseWriter, request *http.Request) {
+			if maxBodyBytes > 0 {
+				if request.ContentLength > maxBodyBytes {
+					responders.Error(request, writer, &httperrors.RequestEntityTooLarge{
+						Err: fmt.Errorf("the request body exceeds the maximum size of %d bytes", maxBodyBytes),
+					})
+					return
+				}
+				if request.Body != nil {
+					request.Body = http.MaxBytesReader(writer, request.Body, maxBodyBytes)
+				}
+			}
+			if readTimeout > 0 {
+				if err := http.NewResponseController(writer).SetReadDeadline(time.Now().Add(readTimeout)); err != nil {
+					logger.Debugf(request.Context(), "Failed to set the read deadline of the request (%s).", err)
+				}
+			}
+			if handlerTimeout > 0 {
+				serveWithTimeout(writer, request, next, handlerTimeout)
+				return
+			}
+			next(writer, request)
+		}
+	}
+}
+
+// serveWithTimeout runs the handler in a go routine and stops waiting for it once the timeout expires.
+// A panic in the handler is propagated to the caller. A panic after the timeout expired cannot be propagated
+// since the caller has returned, so it is logged instead.
+func serveWithTimeout(writer http.ResponseWriter, request *http.Request, next http.HandlerFunc, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(request.Context(), timeout)
+	defer cancel()
+
+	timeoutWriter := &timeoutResponseWriter{ResponseWriter: writer, ctx: ctx, header: writer.Header().Clone()}
+	done := make(chan struct{})
+	panicChan := make(chan any, 1)
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				timeoutWriter.lock.Lock()
+				defer timeoutWriter.lock.Unlock()
+				if timeoutWriter.timedOut {
+					logger.Errorf(ctx, "The handler panicked after its timeout expired (%v).\n%s", recovered, debug.Stack())
+					return
+				}
+				panicChan <- recovered
+			}
+		}()
+		next(timeoutWriter, request.WithContext(ctx))
+		close(done)
+	}()
+
+	finished := false
+	select {
+	case recovered := <-panicChan:
+		panic(recovered)
+	case <-done:
+		finished = true
+	case <-ctx.Done():
+	}
+
+	timeoutWriter.lock.Lock()
+	defer timeoutWriter.lock.Unlock()
+	select {
+	case recovered := <-panicChan:
+		panic(recovered)
+	default:
+	}
+	timeoutWriter.timedOut = true
+	if timeoutWriter.wroteHeader {
+		return
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		responders.Error(request, writer, &httperrors.RequestTimeout{Err: errHandlerTimeout})
+	} else if finished {
+		timeoutWriter.copyHeader()
+	}
+}
+
+// timeoutResponseWriter stops the handler from writing to the response once its context is done.
+// The handler sets its headers on a copy, so they do not race with the timeout response.
+type timeoutResponseWriter struct {
+	http.ResponseWriter
+	ctx         context.Context
+	lock        sync.Mutex
+	header      http.Header
+	timedOut    bool
+	wroteHeader bool
+}
+
+// Header returns the headers of the handler. They are sent when the handler writes the header.
+func (tw *timeoutResponseWriter) Header() http.Header {
+	return tw.header
+}
+
+// isDone returns true if the handler can no longer write to the response. It is checked against the context,
+// and not only the timedOut flag, so a handler that sees its context expire cannot write before the timeout
+// response. The lock must be held.
+func (tw *timeoutResponseWriter) isDone() bool {
+	return tw.timedOut || tw.ctx.Err() != nil
+}
+
+// copyHeader replaces the headers of the response with the headers of the handler. The lock must be held.
+func (tw *timeoutResponseWriter) copyHeader() {
+	destination := tw.ResponseWriter.Header()
+	clear(destination)
+	for name, values := range tw.header {
+		destination[name] = values
+	}
+}
+
+// WriteHeader sends the HTTP response header with the status code if the handler has not timed out.
+func (tw *timeoutResponseWriter) WriteHeader(statusCode int) {
+	tw.lock.Lock()
+	defer tw.lock.Unlock()
+	if tw.isDone() {
+		return
+	}
+	if statusCode >= http.StatusOK {
+		tw.wroteHeader = true
+	}
+	tw.copyHeader()
+	tw.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write writes the data as part of the HTTP reply if the handler has not timed out.
+func (tw *timeoutResponseWriter) Write(data []byte) (int, error) {
+	tw.lock.Lock()
+	defer tw.lock.Unlock()
+	if tw.isDone() {
+		return 0, http.ErrHandlerTimeout
+	}
+	if !tw.wroteHeader {
+		tw.wroteHeader = true
+		tw.copyHeader()
+	}
+	return tw.ResponseWriter.Write(data)
+}
+
+// Flush sends any buffered data to the client if the handler has not timed out.
+func (tw *timeoutResponseWriter) Flush() {
+	tw.lock.Lock()
+	defer tw.lock.Unlock()
+	if tw.isDone() {
+		return
+	}
+	if !tw.wroteHeader {
+		tw.wroteHeader = true
+		tw.copyHeader()
+	}
+	if flusher, ok := tw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
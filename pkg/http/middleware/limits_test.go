@@ -0,0 +1,166 @@
+package middleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	httperrors "github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/middleware"
+	"github.com/TriangleSide/GoBase/pkg/http/responders"
+	"github.com/TriangleSide/GoBase/pkg/logger"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestLimits(t *testing.T) {
+	t.Parallel()
+
+	type requestParams struct {
+		Name string `json:"name" validate:"required"`
+	}
+	type responseBody struct {
+		Name string `json:"name"`
+	}
+
+	echoHandler := func(writer http.ResponseWriter, request *http.Request) {
+		responders.JSON[requestParams, responseBody](writer, request, func(params *requestParams) (*responseBody, int, error) {
+			return &responseBody{Name: params.Name}, http.StatusOK, nil
+		})
+	}
+
+	serve := func(limits *middleware.Limits, handler http.HandlerFunc, request *http.Request) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		middleware.CreateChain([]middleware.Middleware{limits.Middleware()}, handler)(recorder, request)
+		return recorder
+	}
+
+	decodeError := func(t *testing.T, recorder *httptest.ResponseRecorder) string {
+		t.Helper()
+		httpError := &httperrors.Error{}
+		assert.NoError(t, json.NewDecoder(recorder.Body).Decode(httpError))
+		return httpError.Message
+	}
+
+	jsonRequest := func(body io.Reader) *http.Request {
+		request := httptest.NewRequest(http.MethodPost, "/", body)
+		request.Header.Set(headers.ContentType, headers.ContentTypeApplicationJson)
+		return request
+	}
+
+	t.Run("when a limit is negative it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicExact(t, func() {
+			(&middleware.Limits{HandlerTimeout: -1}).Middleware()
+		}, "the limits cannot be negative")
+	})
+
+	t.Run("when the content length exceeds the maximum body size it should respond with request entity too large", func(t *testing.T) {
+		t.Parallel()
+		called := false
+		recorder := serve(&middleware.Limits{MaxBodyBytes: 8}, func(writer http.ResponseWriter, request *http.Request) {
+			called = true
+		}, jsonRequest(strings.NewReader(`{"name":"too long"}`)))
+		assert.False(t, called)
+		assert.Equals(t, recorder.Code, http.StatusRequestEntityTooLarge)
+		assert.Equals(t, decodeError(t, recorder), "the request body exceeds the maximum size of 8 bytes")
+	})
+
+	t.Run("when a body of unknown length exceeds the maximum body size it should respond with request entity too large", func(t *testing.T) {
+		t.Parallel()
+		request := jsonRequest(io.MultiReader(strings.NewReader(`{"name":"`), strings.NewReader(strings.Repeat("a", 64)+`"}`)))
+		request.ContentLength = -1
+		recorder := serve(&middleware.Limits{MaxBodyBytes: 16}, echoHandler, request)
+		assert.Equals(t, recorder.Code, http.StatusRequestEntityTooLarge)
+		assert.Equals(t, decodeError(t, recorder), "the request body exceeds the maximum size of 16 bytes")
+	})
+
+	t.Run("when the body is within the maximum body size it should call the handler", func(t *testing.T) {
+		t.Parallel()
+		recorder := serve(&middleware.Limits{MaxBodyBytes: 64, ReadTimeout: time.Second}, echoHandler, jsonRequest(strings.NewReader(`{"name":"gopher"}`)))
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.Contains(t, recorder.Body.String(), `"name":"gopher"`)
+	})
+
+	t.Run("when the handler exceeds its timeout it should respond with request timeout and cancel the context", func(t *testing.T) {
+		t.Parallel()
+		handlerDone := make(chan error, 1)
+		recorder := serve(&middleware.Limits{HandlerTimeout: time.Millisecond * 20}, func(writer http.ResponseWriter, request *http.Request) {
+			writer.Header().Set("X-Handler", "late")
+			<-request.Context().Done()
+			_, err := writer.Write([]byte("late"))
+			handlerDone <- err
+		}, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equals(t, recorder.Code, http.StatusRequestTimeout)
+		assert.Equals(t, decodeError(t, recorder), "the request was not handled in time")
+		assert.Equals(t, recorder.Header().Get("X-Handler"), "")
+		assert.ErrorExact(t, <-handlerDone, http.ErrHandlerTimeout.Error())
+	})
+
+	t.Run("when the handler finishes before its timeout it should send its response", func(t *testing.T) {
+		t.Parallel()
+		recorder := serve(&middleware.Limits{HandlerTimeout: time.Second}, func(writer http.ResponseWriter, request *http.Request) {
+			writer.Header().Set("X-Handler", "on time")
+			writer.WriteHeader(http.StatusAccepted)
+			writer.(http.Flusher).Flush()
+		}, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equals(t, recorder.Code, http.StatusAccepted)
+		assert.Equals(t, recorder.Header().Get("X-Handler"), "on time")
+		assert.True(t, recorder.Flushed)
+	})
+
+	t.Run("when the handler sets headers without writing a response it should send its headers", func(t *testing.T) {
+		t.Parallel()
+		recorder := serve(&middleware.Limits{HandlerTimeout: time.Second}, func(writer http.ResponseWriter, request *http.Request) {
+			writer.Header().Set("X-Handler", "headers only")
+		}, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.Equals(t, recorder.Header().Get("X-Handler"), "headers only")
+	})
+
+	t.Run("when the handler panics within its timeout it should propagate the panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicExact(t, func() {
+			serve(&middleware.Limits{HandlerTimeout: time.Second}, func(writer http.ResponseWriter, request *http.Request) {
+				panic("handler panic")
+			}, httptest.NewRequest(http.MethodGet, "/", nil))
+		}, "handler panic")
+	})
+}
+
+// messageBackend sends the messages of the log entries on a channel.
+type messageBackend chan string
+
+func (b messageBackend) Write(_ context.Context, _ logger.LogLevel, _ map[string]any, msg string) {
+	b <- msg
+}
+
+func TestLimitsPanicAfterTimeout(t *testing.T) {
+	backend := make(messageBackend, 1)
+	logger.SetBackend(backend)
+	t.Cleanup(func() {
+		logger.SetBackend(nil)
+	})
+
+	recorder := httptest.NewRecorder()
+	handlerPanic := make(chan struct{})
+	limits := &middleware.Limits{HandlerTimeout: time.Millisecond * 20}
+	middleware.CreateChain([]middleware.Middleware{limits.Middleware()}, func(writer http.ResponseWriter, request *http.Request) {
+		<-handlerPanic
+		panic("late panic")
+	})(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equals(t, recorder.Code, http.StatusRequestTimeout)
+
+	close(handlerPanic)
+	select {
+	case msg := <-backend:
+		assert.HasPrefix(t, msg, "The handler panicked after its timeout expired (late panic).")
+	case <-time.After(time.Second * 5):
+		t.Fatal("the panic was not logged")
+	}
+}
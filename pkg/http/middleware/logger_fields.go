@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/logger"
+)
+
+const (
+	// TraceIDLogField is the logger field that holds the trace ID of the W3C trace context of the request.
+	TraceIDLogField = logger.TraceIDLogField
+
+	// SpanIDLogField is the logger field that holds the parent span ID of the W3C trace context of the request.
+	SpanIDLogField = logger.SpanIDLogField
+)
+
+// LogFieldsFn returns the logger fields of a request. A nil or empty map adds no fields.
+type LogFieldsFn func(request *http.Request) map[string]any
+
+// LoggerFields returns a Middleware that adds the fields returned by the functions to the logger fields of the
+// request context, so that every log of the request includes them. When functions return the same field,
+// the value of the last one is kept.
+func LoggerFields(fns ...LogFieldsFn) Middleware {
+	for _, fn := range fns {
+		if fn == nil {
+			panic("the log fields function cannot be nil")
+		}
+	}
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(writer http.ResponseWriter, request *http.Request) {
+			fields := make(map[string]any)
+			for _, fn := range fns {
+				for key, value := range fn(request) {
+					fields[key] = value
+				}
+			}
+			if len(fields) != 0 {
+				request = request.WithContext(logger.WithFields(request.Context(), fields))
+			}
+			next(writer, request)
+		}
+	}
+}
+
+// TraceLogFields is a LogFieldsFn that reads the trace ID and the parent span ID from the Traceparent header,
+// in the format of the W3C trace context (version-traceid-parentid-flags). Malformed headers are ignored.
+func TraceLogFields(request *http.Request) map[string]any {
+	parts := strings.Split(request.Header.Get(headers.Traceparent), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return nil
+	}
+	traceID, spanID := parts[1], parts[2]
+	if len(traceID) != 32 || !isLowerHex(traceID) || strings.Count(traceID, "0") == len(traceID) {
+		return nil
+	}
+	if len(spanID) != 16 || !isLowerHex(spanID) || strings.Count(spanID, "0") == len(spanID) {
+		return nil
+	}
+	return map[string]any{
+		TraceIDLogField: traceID,
+		SpanIDLogField:  spanID,
+	}
+}
+
+// HeaderLogField returns a LogFieldsFn that sets the field to the value of the request header.
+// Missing values, and values that are too long or have characters other than printable ASCII, are ignored.
+func HeaderLogField(field string, header string) LogFieldsFn {
+	if field == "" || header == "" {
+		panic("the field and header cannot be empty")
+	}
+	return func(request *http.Request) map[string]any {
+		value := request.Header.Get(header)
+		if !isLoggableHeaderValue(value) {
+			return nil
+		}
+		return map[string]any{field: value}
+	}
+}
+
+// isLowerHex returns true if the value only has lowercase hexadecimal digits.
+func isLowerHex(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if (value[i] < '0' || value[i] > '9') && (value[i] < 'a' || value[i] > 'f') {
+			return false
+		}
+	}
+	return true
+}
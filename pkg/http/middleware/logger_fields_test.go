@@ -0,0 +1,106 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/middleware"
+	"github.com/TriangleSide/GoBase/pkg/logger"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestLoggerFields(t *testing.T) {
+	t.Parallel()
+
+	serve := func(request *http.Request, fns ...middleware.LogFieldsFn) map[string]any {
+		var fields map[string]any
+		middleware.CreateChain([]middleware.Middleware{middleware.RequestID(), middleware.LoggerFields(fns...)}, func(_ http.ResponseWriter, request *http.Request) {
+			fields = logger.Fields(request.Context())
+		})(httptest.NewRecorder(), request)
+		delete(fields, middleware.RequestIDLogField)
+		return fields
+	}
+
+	t.Run("when a log fields function is nil it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicExact(t, func() {
+			middleware.LoggerFields(nil)
+		}, "the log fields function cannot be nil")
+	})
+
+	t.Run("when the functions return fields it should add them to the logger fields of the request", func(t *testing.T) {
+		t.Parallel()
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		fields := serve(request, func(*http.Request) map[string]any {
+			return map[string]any{"first": 1, "shared": "first"}
+		}, func(*http.Request) map[string]any {
+			return map[string]any{"second": 2, "shared": "second"}
+		}, func(*http.Request) map[string]any {
+			return nil
+		})
+		assert.Equals(t, fields, map[string]any{"first": 1, "second": 2, "shared": "second"})
+	})
+
+	t.Run("when the request has a valid traceparent it should add the trace and span IDs", func(t *testing.T) {
+		t.Parallel()
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set(headers.Traceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		fields := serve(request, middleware.TraceLogFields)
+		assert.Equals(t, fields, map[string]any{
+			middleware.TraceIDLogField: "4bf92f3577b34da6a3ce929d0e0e4736",
+			middleware.SpanIDLogField:  "00f067aa0ba902b7",
+		})
+	})
+
+	t.Run("when the request has a traceparent of a future version it should accept the extra parts", func(t *testing.T) {
+		t.Parallel()
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set(headers.Traceparent, "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra")
+		fields := serve(request, middleware.TraceLogFields)
+		assert.Equals(t, fields[middleware.TraceIDLogField], any("4bf92f3577b34da6a3ce929d0e0e4736"))
+	})
+
+	t.Run("when the traceparent is missing or malformed it should not add fields", func(t *testing.T) {
+		t.Parallel()
+		for _, traceparent := range []string{
+			"",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+			"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+			"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+			"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902bz-01",
+		} {
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			request.Header.Set(headers.Traceparent, traceparent)
+			assert.Equals(t, serve(request, middleware.TraceLogFields), map[string]any{}, assert.Continue())
+		}
+	})
+
+	t.Run("when a header log field is used it should add the value of the header", func(t *testing.T) {
+		t.Parallel()
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set("X-Tenant-ID", "tenant")
+		fields := serve(request, middleware.HeaderLogField("tenant_id", "X-Tenant-ID"))
+		assert.Equals(t, fields, map[string]any{"tenant_id": "tenant"})
+	})
+
+	t.Run("when the header value is not loggable it should not add the field", func(t *testing.T) {
+		t.Parallel()
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set("X-Tenant-ID", "tenant\x01")
+		fields := serve(request, middleware.HeaderLogField("tenant_id", "X-Tenant-ID"))
+		assert.Equals(t, fields, map[string]any{})
+	})
+
+	t.Run("when the field or header of a header log field is empty it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicExact(t, func() {
+			middleware.HeaderLogField("", "X-Tenant-ID")
+		}, "the field and header cannot be empty")
+	})
+}
@@ -0,0 +1,322 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/middleware"
+	"github.com/TriangleSide/GoBase/pkg/logger"
+)
+
+const (
+	// ContentType is the media type of the Prometheus text exposition format.
+	ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+	// unmatchedPath is the path label of requests that did not match a route.
+	unmatchedPath = "unmatched"
+)
+
+var (
+	// DefaultDurationBuckets are the upper bounds, in seconds, of the request duration histogram buckets.
+	DefaultDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+	// DefaultSizeBuckets are the upper bounds, in bytes, of the response size histogram buckets.
+	DefaultSizeBuckets = []float64{100, 1000, 10000, 100000, 1000000, 10000000}
+)
+
+// config is configured by the Option functions.
+type config struct {
+	namespace       string
+	durationBuckets []float64
+	sizeBuckets     []float64
+}
+
+// Option is used to configure the Metrics.
+type Option func(cfg *config)
+
+// WithNamespace prefixes the name of every metric with the namespace and an underscore.
+func WithNamespace(namespace string) Option {
+	return func(cfg *config) {
+		cfg.namespace = namespace
+	}
+}
+
+// WithDurationBuckets sets the upper bounds, in seconds, of the request duration histogram buckets.
+// The buckets must be sorted in increasing order, or this function panics.
+func WithDurationBuckets(buckets ...float64) Option {
+	mustBeValidBuckets(buckets)
+	return func(cfg *config) {
+		cfg.durationBuckets = buckets
+	}
+}
+
+// WithSizeBuckets sets the upper bounds, in bytes, of the response size histogram buckets.
+// The buckets must be sorted in increasing order, or this function panics.
+func WithSizeBuckets(buckets ...float64) Option {
+	mustBeValidBuckets(buckets)
+	return func(cfg *config) {
+		cfg.sizeBuckets = buckets
+	}
+}
+
+// mustBeValidBuckets panics if the buckets are empty or not strictly increasing.
+func mustBeValidBuckets(buckets []float64) {
+	if len(buckets) == 0 {
+		panic("at least one bucket must be provided")
+	}
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i] <= buckets[i-1] {
+			panic("the buckets must be in strictly increasing order")
+		}
+	}
+}
+
+// seriesKey identifies a time series by its labels.
+type seriesKey struct {
+	method string
+	path   string
+	status string
+}
+
+// histogram counts observations in buckets. The counts are not cumulative.
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// observe adds the value to the first bucket with an upper bound that is greater than or equal to it.
+func (h *histogram) observe(buckets []float64, value float64) {
+	index, _ := slices.BinarySearch(buckets, value)
+	h.counts[index]++
+	h.sum += value
+	h.count++
+}
+
+// Metrics records the requests of an HTTP server and renders them in the Prometheus text exposition format.
+//
+// The requests are counted, and their duration and response size are recorded in histograms. The series are
+// labelled by method, route path, and status code. The route path is the pattern that matched the request,
+// like /users/{id}, so the number of series does not grow with the number of distinct URLs.
+type Metrics struct {
+	cfg       *config
+	lock      sync.Mutex
+	inFlight  int64
+	requests  map[seriesKey]uint64
+	durations map[seriesKey]*histogram
+	sizes     map[seriesKey]*histogram
+}
+
+// New allocates a Metrics with no recorded requests.
+func New(opts ...Option) *Metrics {
+	cfg := &config{
+		namespace:       "",
+		durationBuckets: DefaultDurationBuckets,
+		sizeBuckets:     DefaultSizeBuckets,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return &Metrics{
+		cfg:       cfg,
+		lock:      sync.Mutex{},
+		inFlight:  0,
+		requests:  make(map[seriesKey]uint64),
+		durations: make(map[seriesKey]*histogram),
+		sizes:     make(map[seriesKey]*histogram),
+	}
+}
+
+// Middleware returns a Middleware that records the requests it handles.
+func (m *Metrics) Middleware() middleware.Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(writer http.ResponseWriter, request *http.Request) {
+			m.lock.Lock()
+			m.inFlight++
+			m.lock.Unlock()
+
+			recorder := &statusRecorder{ResponseWriter: writer, status: 0, size: 0}
+			start := time.Now()
+			defer func() {
+				m.record(request, recorder, time.Since(start))
+			}()
+			next(recorder, request)
+		}
+	}
+}
+
+// record adds the request to the series of its method, path, and status.
+func (m *Metrics) record(request *http.Request, recorder *statusRecorder, elapsed time.Duration) {
+	status := recorder.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	key := seriesKey{
+		method: request.Method,
+		path:   routePath(request),
+		status: strconv.Itoa(status),
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.inFlight--
+	m.requests[key]++
+	if _, found := m.durations[key]; !found {
+		m.durations[key] = &histogram{counts: make([]uint64, len(m.cfg.durationBuckets)+1)}
+		m.sizes[key] = &histogram{counts: make([]uint64, len(m.cfg.sizeBuckets)+1)}
+	}
+	m.durations[key].observe(m.cfg.durationBuckets, elapsed.Seconds())
+	m.sizes[key].observe(m.cfg.sizeBuckets, float64(recorder.size))
+}
+
+// routePath returns the path of the pattern that matched the request, without its method and host.
+func routePath(request *http.Request) string {
+	pattern := request.Pattern
+	if pattern == "" {
+		return unmatchedPath
+	}
+	if _, path, hasMethod := strings.Cut(pattern, " "); hasMethod {
+		pattern = path
+	}
+	if index := strings.Index(pattern, "/"); index > 0 {
+		pattern = pattern[index:]
+	}
+	return pattern
+}
+
+// Handler returns an http.HandlerFunc that responds with the metrics in the Prometheus text exposition format.
+func (m *Metrics) Handler() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set(headers.ContentType, ContentType)
+		writer.WriteHeader(http.StatusOK)
+		if err := m.Render(writer); err != nil {
+			logger.Errorf(request.Context(), "Failed to write the metrics (%s).", err)
+		}
+	}
+}
+
+// Render writes the metrics in the Prometheus text exposition format. The series are sorted by their labels.
+func (m *Metrics) Render(writer io.Writer) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	keys := make([]seriesKey, 0, len(m.requests))
+	for key := range m.requests {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b seriesKey) int {
+		return strings.Compare(a.path+" "+a.method+" "+a.status, b.path+" "+b.method+" "+b.status)
+	})
+
+	var builder strings.Builder
+
+	requestsName := m.name("http_requests_total")
+	writeHeader(&builder, requestsName, "counter", "The total number of HTTP requests.")
+	for _, key := range keys {
+		fmt.Fprintf(&builder, "%s{%s} %d\n", requestsName, key.labels(), m.requests[key])
+	}
+
+	inFlightName := m.name("http_requests_in_flight")
+	writeHeader(&builder, inFlightName, "gauge", "The number of HTTP requests being served.")
+	fmt.Fprintf(&builder, "%s %d\n", inFlightName, m.inFlight)
+
+	durationName := m.name("http_request_duration_seconds")
+	writeHeader(&builder, durationName, "histogram", "The duration of the HTTP requests in seconds.")
+	for _, key := range keys {
+		writeHistogram(&builder, durationName, key, m.cfg.durationBuckets, m.durations[key])
+	}
+
+	sizeName := m.name("http_response_size_bytes")
+	writeHeader(&builder, sizeName, "histogram", "The size of the HTTP response bodies in bytes.")
+	for _, key := range keys {
+		writeHistogram(&builder, sizeName, key, m.cfg.sizeBuckets, m.sizes[key])
+	}
+
+	_, err := io.WriteString(writer, builder.String())
+	return err
+}
+
+// name prefixes the metric name with the namespace, if there is one.
+func (m *Metrics) name(name string) string {
+	if m.cfg.namespace == "" {
+		return name
+	}
+	return m.cfg.namespace + "_" + name
+}
+
+// labels formats the labels of the series.
+func (key seriesKey) labels() string {
+	return fmt.Sprintf(`method="%s",path="%s",status="%s"`, escapeLabelValue(key.method), escapeLabelValue(key.path), escapeLabelValue(key.status))
+}
+
+// writeHeader writes the HELP and TYPE lines of a metric.
+func writeHeader(builder *strings.Builder, name string, metricType string, help string) {
+	fmt.Fprintf(builder, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+// writeHistogram writes the cumulative buckets, sum, and count of a histogram series.
+func writeHistogram(builder *strings.Builder, name string, key seriesKey, buckets []float64, h *histogram) {
+	labels := key.labels()
+	cumulative := uint64(0)
+	for index, upperBound := range buckets {
+		cumulative += h.counts[index]
+		fmt.Fprintf(builder, "%s_bucket{%s,le=\"%s\"} %d\n", name, labels, formatFloat(upperBound), cumulative)
+	}
+	fmt.Fprintf(builder, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, h.count)
+	fmt.Fprintf(builder, "%s_sum{%s} %s\n", name, labels, formatFloat(h.sum))
+	fmt.Fprintf(builder, "%s_count{%s} %d\n", name, labels, h.count)
+}
+
+// formatFloat formats a float with the shortest representation that parses back to the same value.
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// escapeLabelValue escapes the backslashes, double quotes, and line feeds of a label value.
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// statusRecorder records the status code and the number of bytes written in the response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+// WriteHeader records the status code and sends the HTTP response header with it.
+func (r *statusRecorder) WriteHeader(statusCode int) {
+	if r.status == 0 && statusCode >= http.StatusOK {
+		r.status = statusCode
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write records the number of bytes written as part of the HTTP reply.
+func (r *statusRecorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	written, err := r.ResponseWriter.Write(data)
+	r.size += int64(written)
+	return written, err
+}
+
+// Flush sends any buffered data to the client.
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying http.ResponseWriter. This is used by the http.ResponseController.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,120 @@
+package metrics_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/middleware"
+	"github.com/TriangleSide/GoBase/pkg/http/middleware/metrics"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+
+	// serve routes a request through a mux so the request has a pattern.
+	serve := func(collector *metrics.Metrics, pattern string, target string, handler http.HandlerFunc) {
+		mux := http.NewServeMux()
+		mux.HandleFunc(pattern, middleware.CreateChain([]middleware.Middleware{collector.Middleware()}, handler))
+		mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	render := func(t *testing.T, collector *metrics.Metrics) string {
+		t.Helper()
+		recorder := httptest.NewRecorder()
+		collector.Handler()(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.Equals(t, recorder.Header().Get(headers.ContentType), metrics.ContentType)
+		return recorder.Body.String()
+	}
+
+	t.Run("when the buckets are invalid it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicExact(t, func() {
+			metrics.WithDurationBuckets()
+		}, "at least one bucket must be provided")
+		assert.PanicExact(t, func() {
+			metrics.WithSizeBuckets(10, 5)
+		}, "the buckets must be in strictly increasing order")
+	})
+
+	t.Run("when no requests are recorded it should only render the in-flight gauge", func(t *testing.T) {
+		t.Parallel()
+		body := render(t, metrics.New())
+		assert.Equals(t, body, "# HELP http_requests_total The total number of HTTP requests.\n"+
+			"# TYPE http_requests_total counter\n"+
+			"# HELP http_requests_in_flight The number of HTTP requests being served.\n"+
+			"# TYPE http_requests_in_flight gauge\n"+
+			"http_requests_in_flight 0\n"+
+			"# HELP http_request_duration_seconds The duration of the HTTP requests in seconds.\n"+
+			"# TYPE http_request_duration_seconds histogram\n"+
+			"# HELP http_response_size_bytes The size of the HTTP response bodies in bytes.\n"+
+			"# TYPE http_response_size_bytes histogram\n")
+	})
+
+	t.Run("when requests are recorded it should render the series per method, route, and status", func(t *testing.T) {
+		t.Parallel()
+		collector := metrics.New(metrics.WithNamespace("app"), metrics.WithDurationBuckets(60), metrics.WithSizeBuckets(1, 10))
+		okHandler := func(writer http.ResponseWriter, request *http.Request) {
+			_, _ = io.WriteString(writer, "hello")
+		}
+		notFoundHandler := func(writer http.ResponseWriter, request *http.Request) {
+			writer.WriteHeader(http.StatusNotFound)
+		}
+		serve(collector, "GET /users/{id}", "/users/1", okHandler)
+		serve(collector, "GET /users/{id}", "/users/2", okHandler)
+		serve(collector, "GET /users/{id}", "/users/3", notFoundHandler)
+
+		body := render(t, collector)
+		assert.Contains(t, body, "app_http_requests_total{method=\"GET\",path=\"/users/{id}\",status=\"200\"} 2\n")
+		assert.Contains(t, body, "app_http_requests_total{method=\"GET\",path=\"/users/{id}\",status=\"404\"} 1\n")
+		assert.Contains(t, body, "app_http_requests_in_flight 0\n")
+		assert.Contains(t, body, "app_http_request_duration_seconds_bucket{method=\"GET\",path=\"/users/{id}\",status=\"200\",le=\"60\"} 2\n")
+		assert.Contains(t, body, "app_http_request_duration_seconds_bucket{method=\"GET\",path=\"/users/{id}\",status=\"200\",le=\"+Inf\"} 2\n")
+		assert.Contains(t, body, "app_http_request_duration_seconds_count{method=\"GET\",path=\"/users/{id}\",status=\"200\"} 2\n")
+		assert.Contains(t, body, "app_http_response_size_bytes_bucket{method=\"GET\",path=\"/users/{id}\",status=\"200\",le=\"1\"} 0\n")
+		assert.Contains(t, body, "app_http_response_size_bytes_bucket{method=\"GET\",path=\"/users/{id}\",status=\"200\",le=\"10\"} 2\n")
+		assert.Contains(t, body, "app_http_response_size_bytes_sum{method=\"GET\",path=\"/users/{id}\",status=\"200\"} 10\n")
+		assert.Contains(t, body, "app_http_response_size_bytes_bucket{method=\"GET\",path=\"/users/{id}\",status=\"404\",le=\"1\"} 1\n")
+		assert.True(t, strings.Index(body, `status="200"} 2`) < strings.Index(body, `status="404"} 1`))
+	})
+
+	t.Run("when a request is being served it should be counted in the in-flight gauge", func(t *testing.T) {
+		t.Parallel()
+		collector := metrics.New()
+		serve(collector, "/", "/", func(writer http.ResponseWriter, request *http.Request) {
+			assert.Contains(t, render(t, collector), "http_requests_in_flight 1\n")
+		})
+		assert.Contains(t, render(t, collector), "http_requests_in_flight 0\n")
+	})
+
+	t.Run("when a request has no pattern it should be labelled as unmatched", func(t *testing.T) {
+		t.Parallel()
+		collector := metrics.New()
+		handler := middleware.CreateChain([]middleware.Middleware{collector.Middleware()}, func(writer http.ResponseWriter, request *http.Request) {})
+		handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/anything", nil))
+		assert.Contains(t, render(t, collector), "http_requests_total{method=\"POST\",path=\"unmatched\",status=\"200\"} 1\n")
+	})
+
+	t.Run("when the pattern has a host it should label the path without the host", func(t *testing.T) {
+		t.Parallel()
+		collector := metrics.New()
+		serve(collector, "example.com/items", "http://example.com/items", func(writer http.ResponseWriter, request *http.Request) {})
+		assert.Contains(t, render(t, collector), "http_requests_total{method=\"GET\",path=\"/items\",status=\"200\"} 1\n")
+	})
+
+	t.Run("when the handler flushes it should reach the underlying writer", func(t *testing.T) {
+		t.Parallel()
+		collector := metrics.New()
+		recorder := httptest.NewRecorder()
+		handler := middleware.CreateChain([]middleware.Middleware{collector.Middleware()}, func(writer http.ResponseWriter, request *http.Request) {
+			writer.(http.Flusher).Flush()
+		})
+		handler(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.True(t, recorder.Flushed)
+	})
+}
@@ -16,6 +16,10 @@ import (
 //	        next(writer, request)
 //	    }
 //	}
+//
+// The context of the request is cancelled when the client closes the connection, which lets handlers abandon
+// expensive work early. Middleware that replaces the request must derive the new context from request.Context()
+// so the cancellation reaches the next handler.
 type Middleware func(next http.HandlerFunc) http.HandlerFunc
 
 // CreateChain returns a http.HandlerFunc that invokes each middleware in order then the final http.HandlerFunc.
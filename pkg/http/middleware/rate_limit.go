@@ -0,0 +1,201 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/datastructures/cache"
+	httperrors "github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/responders"
+	"github.com/TriangleSide/GoBase/pkg/logger"
+)
+
+// errRateLimitExceeded is returned to the client when its requests exceed the rate limit.
+var errRateLimitExceeded = errors.New("the rate limit has been exceeded")
+
+const (
+	// minRateLimitJanitorInterval is the shortest interval at which the in-memory stores remove the idle keys.
+	// It keeps the janitors from waking up constantly when the keys become idle quickly.
+	minRateLimitJanitorInterval = time.Second
+)
+
+// RateLimitStore decides if a request is allowed by the rate limit of its key.
+// Stores can be backed by a shared database, like Redis, so that the limit applies across server instances.
+type RateLimitStore interface {
+	// Allow records a request for the key and returns whether it is allowed.
+	// If it is not allowed, retryAfter is how long the client should wait before trying again.
+	Allow(ctx context.Context, key string) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// RateLimitKeyFunc returns the key that the rate limit of the request is counted against.
+type RateLimitKeyFunc func(request *http.Request) string
+
+// RateLimitByIP counts the requests against the IP address of the client.
+func RateLimitByIP() RateLimitKeyFunc {
+	return remoteIP
+}
+
+// RateLimitByHeader counts the requests against the value of the header.
+// Requests without the header share the limit of the empty key.
+func RateLimitByHeader(name string) RateLimitKeyFunc {
+	return func(request *http.Request) string {
+		return request.Header.Get(name)
+	}
+}
+
+// RateLimit returns a Middleware that rejects requests that exceed the limit of the store for their key.
+// Rejected requests get an HTTP 429 too many requests with a Retry-After header in seconds.
+// If the store fails, the error is logged and the request is allowed, so an unavailable store does not
+// take down the server.
+func RateLimit(store RateLimitStore, keyFunc RateLimitKeyFunc) Middleware {
+	if store == nil {
+		panic("the rate limit store cannot be nil")
+	}
+	if keyFunc == nil {
+		panic("the rate limit key function cannot be nil")
+	}
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(writer http.ResponseWriter, request *http.Request) {
+			allowed, retryAfter, err := store.Allow(request.Context(), keyFunc(request))
+			if err != nil {
+				logger.Errorf(request.Context(), "Failed to check the rate limit (%s).", err)
+				next(writer, request)
+				return
+			}
+			if !allowed {
+				retryAfterSeconds := int(math.Ceil(retryAfter.Seconds()))
+				writer.Header().Set(headers.RetryAfter, strconv.Itoa(max(retryAfterSeconds, 1)))
+				responders.Error(request, writer, &httperrors.TooManyRequests{Err: errRateLimitExceeded})
+				return
+			}
+			next(writer, request)
+		}
+	}
+}
+
+// tokenBucket is the state of a key in the TokenBucketStore.
+type tokenBucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+// TokenBucketStore is an in-memory RateLimitStore that uses the token bucket algorithm.
+// Each key has a bucket that holds up to burst tokens and is refilled at the rate per second.
+// A request takes a token, and is rejected if the bucket is empty.
+type TokenBucketStore struct {
+	rate    float64
+	burst   float64
+	ttl     time.Duration
+	buckets *cache.Sharded[string, tokenBucket]
+}
+
+// NewTokenBucketStore allocates a TokenBucketStore. If the rate or burst is not positive, this function panics.
+// Once a bucket is idle long enough to be full again, it is removed from memory by a janitor that runs at that
+// interval, or every second if it is shorter. The store must be closed to stop the janitor.
+func NewTokenBucketStore(ratePerSecond float64, burst int) *TokenBucketStore {
+	if ratePerSecond <= 0 || burst <= 0 {
+		panic(fmt.Sprintf("the token bucket rate %v and burst %d must be greater than zero", ratePerSecond, burst))
+	}
+	ttl := time.Duration(float64(burst) / ratePerSecond * float64(time.Second))
+	return &TokenBucketStore{
+		rate:    ratePerSecond,
+		burst:   float64(burst),
+		ttl:     ttl,
+		buckets: cache.NewSharded[string, tokenBucket](cache.DefaultShardCount, cache.WithJanitor(max(ttl, minRateLimitJanitorInterval))),
+	}
+}
+
+// Allow takes a token from the bucket of the key if it has one.
+func (s *TokenBucketStore) Allow(_ context.Context, key string) (bool, time.Duration, error) {
+	var allowed bool
+	var retryAfter time.Duration
+	s.buckets.Update(key, func(bucket tokenBucket, found bool) (tokenBucket, *time.Duration) {
+		now := time.Now()
+		if !found {
+			bucket = tokenBucket{tokens: s.burst, lastRefill: now}
+		}
+		bucket.tokens = min(s.burst, bucket.tokens+now.Sub(bucket.lastRefill).Seconds()*s.rate)
+		bucket.lastRefill = now
+
+		allowed = bucket.tokens >= 1
+		if allowed {
+			bucket.tokens--
+		} else {
+			retryAfter = time.Duration((1 - bucket.tokens) / s.rate * float64(time.Second))
+		}
+		return bucket, &s.ttl
+	})
+	return allowed, retryAfter, nil
+}
+
+// Len returns the number of keys held in memory, including the idle keys that the janitor has not removed yet.
+func (s *TokenBucketStore) Len() int {
+	return s.buckets.Stats().Entries
+}
+
+// Close stops the janitor of the store. It is safe to call Close many times.
+func (s *TokenBucketStore) Close() {
+	s.buckets.Close()
+}
+
+// SlidingWindowStore is an in-memory RateLimitStore that uses the sliding window algorithm.
+// Each key is allowed up to limit requests in any period of the window's duration.
+type SlidingWindowStore struct {
+	limit   int
+	window  time.Duration
+	entries *cache.Sharded[string, []time.Time]
+}
+
+// NewSlidingWindowStore allocates a SlidingWindowStore. If the limit or window is not positive, this function panics.
+// Once a key is idle for the window, its requests are removed from memory by a janitor that runs at the interval
+// of the window, or every second if it is shorter. The store must be closed to stop the janitor.
+func NewSlidingWindowStore(limit int, window time.Duration) *SlidingWindowStore {
+	if limit <= 0 || window <= 0 {
+		panic(fmt.Sprintf("the sliding window limit %d and window %s must be greater than zero", limit, window))
+	}
+	return &SlidingWindowStore{
+		limit:   limit,
+		window:  window,
+		entries: cache.NewSharded[string, []time.Time](cache.DefaultShardCount, cache.WithJanitor(max(window, minRateLimitJanitorInterval))),
+	}
+}
+
+// Allow records the request if the key has made fewer than limit requests within the window.
+func (s *SlidingWindowStore) Allow(_ context.Context, key string) (bool, time.Duration, error) {
+	var allowed bool
+	var retryAfter time.Duration
+	s.entries.Update(key, func(timestamps []time.Time, _ bool) ([]time.Time, *time.Duration) {
+		now := time.Now()
+		windowStart := now.Add(-s.window)
+		firstInWindow := 0
+		for firstInWindow < len(timestamps) && !timestamps[firstInWindow].After(windowStart) {
+			firstInWindow++
+		}
+		timestamps = timestamps[firstInWindow:]
+
+		if len(timestamps) >= s.limit {
+			retryAfter = timestamps[0].Sub(windowStart)
+			return timestamps, &s.window
+		}
+		allowed = true
+		return append(timestamps, now), &s.window
+	})
+	return allowed, retryAfter, nil
+}
+
+// Len returns the number of keys held in memory, including the idle keys that the janitor has not removed yet.
+func (s *SlidingWindowStore) Len() int {
+	return s.entries.Stats().Entries
+}
+
+// Close stops the janitor of the store. It is safe to call Close many times.
+func (s *SlidingWindowStore) Close() {
+	s.entries.Close()
+}
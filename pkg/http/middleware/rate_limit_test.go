@@ -0,0 +1,186 @@
+package middleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	httperrors "github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/middleware"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+// failingStore is a RateLimitStore that always fails.
+type failingStore struct{}
+
+func (failingStore) Allow(context.Context, string) (bool, time.Duration, error) {
+	return false, 0, errors.New("store unavailable")
+}
+
+func TestRateLimit(t *testing.T) {
+	t.Parallel()
+
+	serve := func(handler http.HandlerFunc, remoteAddr string, apiKey string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.RemoteAddr = remoteAddr
+		if apiKey != "" {
+			request.Header.Set("X-API-Key", apiKey)
+		}
+		recorder := httptest.NewRecorder()
+		handler(recorder, request)
+		return recorder
+	}
+
+	okHandler := func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	}
+
+	t.Run("when the store or key function is nil it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicExact(t, func() {
+			middleware.RateLimit(nil, middleware.RateLimitByIP())
+		}, "the rate limit store cannot be nil")
+		store := middleware.NewSlidingWindowStore(1, time.Second)
+		defer store.Close()
+		assert.PanicExact(t, func() {
+			middleware.RateLimit(store, nil)
+		}, "the rate limit key function cannot be nil")
+	})
+
+	t.Run("when the store parameters are not positive it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicExact(t, func() {
+			middleware.NewTokenBucketStore(0, 1)
+		}, "the token bucket rate 0 and burst 1 must be greater than zero")
+		assert.PanicExact(t, func() {
+			middleware.NewSlidingWindowStore(1, 0)
+		}, "the sliding window limit 1 and window 0s must be greater than zero")
+	})
+
+	t.Run("when the token bucket is empty it should respond with too many requests and a retry after", func(t *testing.T) {
+		t.Parallel()
+		store := middleware.NewTokenBucketStore(0.1, 2)
+		defer store.Close()
+		handler := middleware.CreateChain([]middleware.Middleware{
+			middleware.RateLimit(store, middleware.RateLimitByIP()),
+		}, okHandler)
+		assert.Equals(t, serve(handler, "192.0.2.1:1000", "").Code, http.StatusOK)
+		assert.Equals(t, serve(handler, "192.0.2.1:2000", "").Code, http.StatusOK)
+		recorder := serve(handler, "192.0.2.1:3000", "")
+		assert.Equals(t, recorder.Code, http.StatusTooManyRequests)
+		assert.Equals(t, recorder.Header().Get(headers.RetryAfter), "10")
+		httpError := &httperrors.Error{}
+		assert.NoError(t, json.NewDecoder(recorder.Body).Decode(httpError))
+		assert.Equals(t, httpError.Message, "the rate limit has been exceeded")
+		assert.Equals(t, serve(handler, "192.0.2.2:1000", "").Code, http.StatusOK)
+	})
+
+	t.Run("when the token bucket refills it should allow requests again", func(t *testing.T) {
+		t.Parallel()
+		store := middleware.NewTokenBucketStore(100, 1)
+		defer store.Close()
+		allowed, _, err := store.Allow(context.Background(), "key")
+		assert.NoError(t, err)
+		assert.True(t, allowed)
+		allowed, retryAfter, err := store.Allow(context.Background(), "key")
+		assert.NoError(t, err)
+		assert.False(t, allowed)
+		assert.True(t, retryAfter > 0 && retryAfter <= time.Millisecond*10)
+		time.Sleep(retryAfter + time.Millisecond*5)
+		allowed, _, err = store.Allow(context.Background(), "key")
+		assert.NoError(t, err)
+		assert.True(t, allowed)
+	})
+
+	t.Run("when the sliding window is full it should reject requests until the oldest one leaves the window", func(t *testing.T) {
+		t.Parallel()
+		store := middleware.NewSlidingWindowStore(2, time.Millisecond*50)
+		defer store.Close()
+		for range 2 {
+			allowed, _, err := store.Allow(context.Background(), "key")
+			assert.NoError(t, err)
+			assert.True(t, allowed)
+		}
+		allowed, retryAfter, err := store.Allow(context.Background(), "key")
+		assert.NoError(t, err)
+		assert.False(t, allowed)
+		assert.True(t, retryAfter > 0 && retryAfter <= time.Millisecond*50)
+		allowed, _, err = store.Allow(context.Background(), "other")
+		assert.NoError(t, err)
+		assert.True(t, allowed)
+		time.Sleep(retryAfter + time.Millisecond*5)
+		allowed, _, err = store.Allow(context.Background(), "key")
+		assert.NoError(t, err)
+		assert.True(t, allowed)
+	})
+
+	t.Run("when requests are keyed by header it should limit each header value separately", func(t *testing.T) {
+		t.Parallel()
+		store := middleware.NewSlidingWindowStore(1, time.Minute)
+		defer store.Close()
+		handler := middleware.CreateChain([]middleware.Middleware{
+			middleware.RateLimit(store, middleware.RateLimitByHeader("X-API-Key")),
+		}, okHandler)
+		assert.Equals(t, serve(handler, "192.0.2.1:1000", "first").Code, http.StatusOK)
+		assert.Equals(t, serve(handler, "192.0.2.1:1000", "first").Code, http.StatusTooManyRequests)
+		assert.Equals(t, serve(handler, "192.0.2.1:1000", "second").Code, http.StatusOK)
+	})
+
+	t.Run("when a custom key function is used it should limit by its key", func(t *testing.T) {
+		t.Parallel()
+		store := middleware.NewSlidingWindowStore(1, time.Minute)
+		defer store.Close()
+		handler := middleware.CreateChain([]middleware.Middleware{
+			middleware.RateLimit(store, func(request *http.Request) string {
+				return request.URL.Path
+			}),
+		}, okHandler)
+		assert.Equals(t, serve(handler, "192.0.2.1:1000", "").Code, http.StatusOK)
+		assert.Equals(t, serve(handler, "192.0.2.2:1000", "").Code, http.StatusTooManyRequests)
+	})
+
+	t.Run("when the token buckets are idle until they are full it should remove them from memory", func(t *testing.T) {
+		t.Parallel()
+		store := middleware.NewTokenBucketStore(100, 1)
+		defer store.Close()
+		for _, key := range []string{"first", "second", "third"} {
+			_, _, err := store.Allow(context.Background(), key)
+			assert.NoError(t, err)
+		}
+		assert.Equals(t, store.Len(), 3)
+		deadline := time.Now().Add(time.Second * 5)
+		for store.Len() > 0 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond * 50)
+		}
+		assert.Equals(t, store.Len(), 0)
+	})
+
+	t.Run("when the sliding window keys are idle for the window it should remove them from memory", func(t *testing.T) {
+		t.Parallel()
+		store := middleware.NewSlidingWindowStore(1, time.Millisecond*10)
+		defer store.Close()
+		for _, key := range []string{"first", "second", "third"} {
+			_, _, err := store.Allow(context.Background(), key)
+			assert.NoError(t, err)
+		}
+		assert.Equals(t, store.Len(), 3)
+		deadline := time.Now().Add(time.Second * 5)
+		for store.Len() > 0 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond * 50)
+		}
+		assert.Equals(t, store.Len(), 0)
+	})
+
+	t.Run("when the store fails it should allow the request", func(t *testing.T) {
+		t.Parallel()
+		handler := middleware.CreateChain([]middleware.Middleware{
+			middleware.RateLimit(failingStore{}, middleware.RateLimitByIP()),
+		}, okHandler)
+		assert.Equals(t, serve(handler, "192.0.2.1:1000", "").Code, http.StatusOK)
+	})
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/logger"
+	"github.com/TriangleSide/GoBase/pkg/utils/ctxkey"
+)
+
+const (
+	// RequestIDLogField is the logger field that holds the request ID.
+	RequestIDLogField = "request_id"
+
+	// maxLoggableHeaderLength is the longest header value, like a request ID, that is accepted from a client
+	// to be logged.
+	maxLoggableHeaderLength = 128
+)
+
+// requestIDKey is the context key of the request ID.
+var requestIDKey = ctxkey.New[string]("request_id")
+
+// RequestID returns a Middleware that assigns an ID to every request.
+//
+// The ID is read from the X-Request-ID header of the request so that it can be correlated with the logs of the
+// caller. If the header is missing, too long, or contains characters other than printable ASCII, a random UUID is
+// generated instead. The ID is added to the request context, where it can be read with RequestIDFromContext,
+// and to the logger fields of the context, so that every log of the request includes it.
+// The ID is also echoed in the X-Request-ID header of the response.
+func RequestID() Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(writer http.ResponseWriter, request *http.Request) {
+			requestID := request.Header.Get(headers.RequestID)
+			if !isLoggableHeaderValue(requestID) {
+				requestID = newUUID()
+			}
+			ctx := requestIDKey.WithValue(request.Context(), requestID)
+			ctx = logger.WithField(ctx, RequestIDLogField, requestID)
+			writer.Header().Set(headers.RequestID, requestID)
+			next(writer, request.WithContext(ctx))
+		}
+	}
+}
+
+// RequestIDFromContext returns the request ID set by the RequestID middleware and whether it was found.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	return requestIDKey.Value(ctx)
+}
+
+// isLoggableHeaderValue returns true if the header value is not empty, not too long, and only has printable ASCII
+// characters. This prevents clients from injecting control characters into the logs.
+func isLoggableHeaderValue(value string) bool {
+	if value == "" || len(value) > maxLoggableHeaderLength {
+		return false
+	}
+	for i := 0; i < len(value); i++ {
+		if value[i] < ' ' || value[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// newUUID generates a random version 4 UUID (RFC 9562).
+func newUUID() string {
+	var uuid [16]byte
+	_, _ = rand.Read(uuid[:])
+	uuid[6] = (uuid[6] & 0x0F) | 0x40
+	uuid[8] = (uuid[8] & 0x3F) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:16])
+}
@@ -0,0 +1,77 @@
+package middleware_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/middleware"
+	"github.com/TriangleSide/GoBase/pkg/logger"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	uuidPattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	serve := func(requestID string) (*httptest.ResponseRecorder, string) {
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		if requestID != "" {
+			request.Header.Set(headers.RequestID, requestID)
+		}
+		recorder := httptest.NewRecorder()
+		var contextID string
+		middleware.CreateChain([]middleware.Middleware{middleware.RequestID()}, func(writer http.ResponseWriter, request *http.Request) {
+			var found bool
+			contextID, found = middleware.RequestIDFromContext(request.Context())
+			assert.True(t, found)
+			writer.WriteHeader(http.StatusOK)
+		})(recorder, request)
+		return recorder, contextID
+	}
+
+	t.Run("when the request has an ID it should be propagated to the context and the response", func(t *testing.T) {
+		recorder, contextID := serve("abc-123")
+		assert.Equals(t, contextID, "abc-123")
+		assert.Equals(t, recorder.Header().Get(headers.RequestID), "abc-123")
+	})
+
+	t.Run("when the request has no ID it should generate a UUID", func(t *testing.T) {
+		firstRecorder, firstID := serve("")
+		assert.True(t, uuidPattern.MatchString(firstID))
+		assert.Equals(t, firstRecorder.Header().Get(headers.RequestID), firstID)
+		_, secondID := serve("")
+		assert.True(t, firstID != secondID)
+	})
+
+	t.Run("when the request ID is invalid it should generate a UUID", func(t *testing.T) {
+		for _, requestID := range []string{"line\tbreak", "caf\xe9", strings.Repeat("a", 129)} {
+			_, contextID := serve(requestID)
+			assert.True(t, uuidPattern.MatchString(contextID))
+		}
+	})
+
+	t.Run("when the context has no request ID it should not be found", func(t *testing.T) {
+		requestID, found := middleware.RequestIDFromContext(httptest.NewRequest(http.MethodGet, "/", nil).Context())
+		assert.False(t, found)
+		assert.Equals(t, requestID, "")
+	})
+
+	t.Run("when a handler logs it should include the request ID", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+		logger.SetOutput(buffer)
+		t.Cleanup(func() {
+			logger.SetOutput(os.Stdout)
+		})
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set(headers.RequestID, "log-correlation-id")
+		middleware.CreateChain([]middleware.Middleware{middleware.RequestID()}, func(writer http.ResponseWriter, request *http.Request) {
+			logger.Error(request.Context(), "handled")
+		})(httptest.NewRecorder(), request)
+		assert.Contains(t, buffer.String(), middleware.RequestIDLogField+"=log-correlation-id handled")
+	})
+}
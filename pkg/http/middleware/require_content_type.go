@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	httperrors "github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/responders"
+)
+
+// RequireContentType returns a Middleware that rejects requests with a body whose Content-Type is not one of the
+// accepted media types. The request is rejected with an HTTP 415 unsupported media type before the next handler runs.
+// Parameters such as the charset are ignored when matching, and a subtype of * matches any subtype, like multipart/*.
+// Requests without a body are passed to the next handler.
+func RequireContentType(contentTypes ...string) Middleware {
+	if len(contentTypes) == 0 {
+		panic("at least one content type must be provided")
+	}
+	accepted := make([]string, 0, len(contentTypes))
+	for _, contentType := range contentTypes {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			panic(fmt.Sprintf("the content type '%s' is invalid (%s)", contentType, err.Error()))
+		}
+		accepted = append(accepted, mediaType)
+	}
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(writer http.ResponseWriter, request *http.Request) {
+			if request.Body == nil || request.Body == http.NoBody || request.ContentLength == 0 {
+				next(writer, request)
+				return
+			}
+			contentType := request.Header.Get(headers.ContentType)
+			mediaType, _, err := mime.ParseMediaType(contentType)
+			if err != nil || !mediaTypeAccepted(mediaType, accepted) {
+				responders.Error(request, writer, &httperrors.UnsupportedMediaType{
+					Err: fmt.Errorf("the content type '%s' is not accepted, the accepted content types are: %s", contentType, strings.Join(accepted, ", ")),
+				})
+				return
+			}
+			next(writer, request)
+		}
+	}
+}
+
+// mediaTypeAccepted returns true if the media type matches one of the accepted media types.
+func mediaTypeAccepted(mediaType string, accepted []string) bool {
+	for _, acceptedType := range accepted {
+		if acceptedType == mediaType {
+			return true
+		}
+		if prefix, isWildcard := strings.CutSuffix(acceptedType, "/*"); isWildcard && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	httperrors "github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/middleware"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestRequireContentType(t *testing.T) {
+	t.Parallel()
+
+	serve := func(mw middleware.Middleware, contentType string, body string) (*httptest.ResponseRecorder, bool) {
+		var request *http.Request
+		if body == "" {
+			request = httptest.NewRequest(http.MethodPost, "/", nil)
+		} else {
+			request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		}
+		if contentType != "" {
+			request.Header.Set(headers.ContentType, contentType)
+		}
+		recorder := httptest.NewRecorder()
+		called := false
+		middleware.CreateChain([]middleware.Middleware{mw}, func(writer http.ResponseWriter, request *http.Request) {
+			called = true
+			writer.WriteHeader(http.StatusOK)
+		})(recorder, request)
+		return recorder, called
+	}
+
+	t.Run("when invalid content types are provided it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicExact(t, func() {
+			middleware.RequireContentType()
+		}, "at least one content type must be provided")
+		assert.PanicPart(t, func() {
+			middleware.RequireContentType("not a type;;")
+		}, "the content type 'not a type;;' is invalid")
+	})
+
+	t.Run("when the content type is accepted it should call the next handler", func(t *testing.T) {
+		t.Parallel()
+		mw := middleware.RequireContentType(headers.ContentTypeApplicationJson, "multipart/*")
+		for _, contentType := range []string{"application/json", "Application/JSON; charset=utf-8", "multipart/form-data; boundary=x"} {
+			recorder, called := serve(mw, contentType, "{}")
+			assert.True(t, called)
+			assert.Equals(t, recorder.Code, http.StatusOK)
+		}
+	})
+
+	t.Run("when the content type is not accepted it should respond with unsupported media type", func(t *testing.T) {
+		t.Parallel()
+		mw := middleware.RequireContentType(headers.ContentTypeApplicationJson)
+		for _, contentType := range []string{"text/plain", "", "application/jsonx"} {
+			recorder, called := serve(mw, contentType, "{}")
+			assert.False(t, called)
+			assert.Equals(t, recorder.Code, http.StatusUnsupportedMediaType)
+			httpError := &httperrors.Error{}
+			assert.NoError(t, json.NewDecoder(recorder.Body).Decode(httpError))
+			assert.Equals(t, httpError.Message, "the content type '"+contentType+"' is not accepted, the accepted content types are: application/json")
+		}
+	})
+
+	t.Run("when the request has no body it should call the next handler", func(t *testing.T) {
+		t.Parallel()
+		recorder, called := serve(middleware.RequireContentType(headers.ContentTypeApplicationJson), "text/plain", "")
+		assert.True(t, called)
+		assert.Equals(t, recorder.Code, http.StatusOK)
+	})
+}
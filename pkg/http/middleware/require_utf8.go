@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	httperrors "github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/responders"
+)
+
+// RequireUTF8 returns a Middleware that rejects requests whose Content-Type declares a charset other than UTF-8.
+// The request is rejected with an HTTP 415 unsupported media type before the body is decoded, so the client
+// gets an actionable error instead of a confusing decoding failure. US-ASCII is accepted since it is a subset of UTF-8.
+// Requests without a charset, or with a Content-Type that cannot be parsed, are passed to the next handler.
+func RequireUTF8() Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(writer http.ResponseWriter, request *http.Request) {
+			contentType := request.Header.Get(headers.ContentType)
+			if contentType != "" {
+				if _, params, err := mime.ParseMediaType(contentType); err == nil {
+					if charset, hasCharset := params["charset"]; hasCharset && !isUTF8Charset(charset) {
+						responders.Error(request, writer, &httperrors.UnsupportedMediaType{
+							Err: fmt.Errorf("the charset '%s' is not supported, the request body must be encoded with UTF-8", charset),
+						})
+						return
+					}
+				}
+			}
+			next(writer, request)
+		}
+	}
+}
+
+// isUTF8Charset returns true if the charset is UTF-8 or one of its subsets.
+func isUTF8Charset(charset string) bool {
+	switch strings.ToLower(strings.TrimSpace(charset)) {
+	case "utf-8", "utf8", "us-ascii":
+		return true
+	default:
+		return false
+	}
+}
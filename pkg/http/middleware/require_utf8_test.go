@@ -0,0 +1,65 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	httperrors "github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/middleware"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestRequireUTF8(t *testing.T) {
+	t.Parallel()
+
+	serve := func(contentType string) (*httptest.ResponseRecorder, bool) {
+		request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"caf\xe9"}`))
+		if contentType != "" {
+			request.Header.Set(headers.ContentType, contentType)
+		}
+		recorder := httptest.NewRecorder()
+		called := false
+		middleware.CreateChain([]middleware.Middleware{middleware.RequireUTF8()}, func(writer http.ResponseWriter, request *http.Request) {
+			called = true
+			writer.WriteHeader(http.StatusOK)
+		})(recorder, request)
+		return recorder, called
+	}
+
+	t.Run("when the body declares the iso-8859-1 charset it should respond with unsupported media type", func(t *testing.T) {
+		t.Parallel()
+		recorder, called := serve("application/json; charset=iso-8859-1")
+		assert.False(t, called)
+		assert.Equals(t, recorder.Code, http.StatusUnsupportedMediaType)
+		httpError := &httperrors.Error{}
+		assert.NoError(t, json.NewDecoder(recorder.Body).Decode(httpError))
+		assert.Equals(t, httpError.Message, "the charset 'iso-8859-1' is not supported, the request body must be encoded with UTF-8")
+	})
+
+	t.Run("when the body declares a UTF-8 compatible charset it should call the next handler", func(t *testing.T) {
+		t.Parallel()
+		for _, contentType := range []string{
+			"application/json; charset=utf-8",
+			"application/json; charset=UTF-8",
+			"application/json; charset=\"utf8\"",
+			"text/plain; charset=us-ascii",
+		} {
+			recorder, called := serve(contentType)
+			assert.True(t, called)
+			assert.Equals(t, recorder.Code, http.StatusOK)
+		}
+	})
+
+	t.Run("when the charset is not declared or the content type is invalid it should call the next handler", func(t *testing.T) {
+		t.Parallel()
+		for _, contentType := range []string{"", headers.ContentTypeApplicationJson, "not a media type;;"} {
+			recorder, called := serve(contentType)
+			assert.True(t, called)
+			assert.Equals(t, recorder.Code, http.StatusOK)
+		}
+	})
+}
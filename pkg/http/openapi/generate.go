@@ -0,0 +1,404 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/http/api"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/parameters"
+)
+
+var (
+	// timeType is the reflect.Type of time.Time, which is documented as a date-time string.
+	timeType = reflect.TypeFor[time.Time]()
+
+	// invalidSchemaNameCharacters matches the characters that cannot be in the name of a component schema.
+	invalidSchemaNameCharacters = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+
+	// methodsInSpecification are the HTTP methods that an OpenAPI path item can describe.
+	methodsInSpecification = map[api.Method]bool{
+		http.MethodGet: true, http.MethodPut: true, http.MethodPost: true, http.MethodDelete: true,
+		http.MethodOptions: true, http.MethodHead: true, http.MethodPatch: true, http.MethodTrace: true,
+	}
+)
+
+// generator builds the schemas of a document. Named struct types are added to the components once and referenced.
+type generator struct {
+	schemas     map[string]*Schema
+	schemaNames map[reflect.Type]string
+}
+
+// Generate builds an OpenAPI document from the routes registered in the builder. Routes without Documentation
+// are included with a default response. An error is returned if the documented types cannot be described.
+func Generate(builder *api.HTTPAPIBuilder, info Info) (*Document, error) {
+	gen := &generator{
+		schemas:     make(map[string]*Schema),
+		schemaNames: make(map[reflect.Type]string),
+	}
+	document := &Document{
+		OpenAPI:    Version,
+		Info:       info,
+		Paths:      make(map[string]map[string]*Operation),
+		Components: nil,
+	}
+
+	for path, methodToHandler := range builder.Handlers() {
+		for method, handler := range methodToHandler {
+			if !methodsInSpecification[method] {
+				continue
+			}
+			operation, err := gen.operation(handler.Documentation)
+			if err != nil {
+				return nil, fmt.Errorf("failed to document the route '%s %s' (%w)", method, path, err)
+			}
+			template := path.Template()
+			if _, found := document.Paths[template]; !found {
+				document.Paths[template] = make(map[string]*Operation)
+			}
+			document.Paths[template][strings.ToLower(string(method))] = operation
+		}
+	}
+
+	if len(gen.schemas) != 0 {
+		document.Components = &Components{Schemas: gen.schemas}
+	}
+	return document, nil
+}
+
+// operation describes a route from its documentation.
+func (gen *generator) operation(documentation *api.Documentation) (*Operation, error) {
+	operation := &Operation{
+		Responses: map[string]*Response{
+			"default": {Description: "The response of the operation."},
+		},
+	}
+	if documentation == nil {
+		return operation, nil
+	}
+	operation.Summary = documentation.Summary
+	operation.Description = documentation.Description
+	operation.Tags = documentation.Tags
+
+	if documentation.Parameters != nil {
+		if err := gen.requestParameters(operation, documentation.Parameters); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(documentation.Responses) != 0 {
+		operation.Responses = make(map[string]*Response, len(documentation.Responses))
+		for status, bodyType := range documentation.Responses {
+			response := &Response{Description: http.StatusText(status)}
+			if response.Description == "" {
+				response.Description = "The response of the operation."
+			}
+			if bodyType != nil {
+				schema, err := gen.schema(bodyType)
+				if err != nil {
+					return nil, fmt.Errorf("failed to describe the response body of status %d (%w)", status, err)
+				}
+				response.Content = map[string]*MediaType{headers.ContentTypeApplicationJson: {Schema: schema}}
+			}
+			operation.Responses[strconv.Itoa(status)] = response
+		}
+	}
+
+	return operation, nil
+}
+
+// requestParameters adds the query, header, cookie, and path parameters, and the JSON body, of the parameter struct.
+func (gen *generator) requestParameters(operation *Operation, parametersType reflect.Type) error {
+	if parametersType.Kind() == reflect.Pointer {
+		parametersType = parametersType.Elem()
+	}
+	if parametersType.Kind() != reflect.Struct {
+		return fmt.Errorf("the request parameters must be a struct but are %s", parametersType)
+	}
+
+	parameterLocations := []struct {
+		tag parameters.Tag
+		in  string
+	}{
+		{tag: parameters.PathTag, in: "path"},
+		{tag: parameters.QueryTag, in: "query"},
+		{tag: parameters.HeaderTag, in: "header"},
+		{tag: parameters.CookieTag, in: "cookie"},
+	}
+
+	body := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for _, field := range structFields(parametersType) {
+		schema, err := gen.schema(field.Type)
+		if err != nil {
+			return fmt.Errorf("failed to describe the field '%s' (%w)", field.Name, err)
+		}
+		required := applyValidationRules(schema, field.Tag.Get("validate"))
+
+		located := false
+		for _, location := range parameterLocations {
+			name, found := field.Tag.Lookup(string(location.tag))
+			if !found {
+				continue
+			}
+			located = true
+			operation.Parameters = append(operation.Parameters, &Parameter{
+				Name:     name,
+				In:       location.in,
+				Required: required || location.in == "path",
+				Schema:   schema,
+			})
+		}
+		if located {
+			continue
+		}
+
+		if name, ok := jsonName(field); ok {
+			body.Properties[name] = schema
+			if required {
+				body.Required = append(body.Required, name)
+			}
+		}
+	}
+
+	if len(body.Properties) != 0 {
+		sort.Strings(body.Required)
+		operation.RequestBody = &RequestBody{
+			Required: len(body.Required) != 0,
+			Content:  map[string]*MediaType{headers.ContentTypeApplicationJson: {Schema: body}},
+		}
+	}
+	return nil
+}
+
+// schema describes the type. Named struct types are added to the components and referenced.
+func (gen *generator) schema(reflectType reflect.Type) (*Schema, error) {
+	for reflectType.Kind() == reflect.Pointer {
+		reflectType = reflectType.Elem()
+	}
+
+	if reflectType == timeType {
+		return &Schema{Type: "string", Format: "date-time"}, nil
+	}
+
+	switch reflectType.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}, nil
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}, nil
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		zero := float64(0)
+		return &Schema{Type: "integer", Minimum: &zero}, nil
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}, nil
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}, nil
+	case reflect.Interface:
+		return &Schema{}, nil
+	case reflect.Slice, reflect.Array:
+		if reflectType.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}, nil
+		}
+		items, err := gen.schema(reflectType.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	case reflect.Map:
+		if reflectType.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("the map key of %s must be a string", reflectType)
+		}
+		values, err := gen.schema(reflectType.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "object", AdditionalProperties: values}, nil
+	case reflect.Struct:
+		if reflectType.Name() == "" {
+			return gen.structSchema(reflectType)
+		}
+		return gen.reference(reflectType)
+	default:
+		return nil, fmt.Errorf("the type %s cannot be described", reflectType)
+	}
+}
+
+// reference adds the named struct to the components if it is not there yet, and returns a reference to it.
+func (gen *generator) reference(reflectType reflect.Type) (*Schema, error) {
+	name, found := gen.schemaNames[reflectType]
+	if !found {
+		name = invalidSchemaNameCharacters.ReplaceAllString(reflectType.Name(), "_")
+		if _, taken := gen.schemas[name]; taken {
+			name = invalidSchemaNameCharacters.ReplaceAllString(reflectType.PkgPath()+"."+reflectType.Name(), "_")
+		}
+		gen.schemaNames[reflectType] = name
+		gen.schemas[name] = nil
+		schema, err := gen.structSchema(reflectType)
+		if err != nil {
+			return nil, err
+		}
+		gen.schemas[name] = schema
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}, nil
+}
+
+// structSchema describes the JSON fields of the struct.
+func (gen *generator) structSchema(reflectType reflect.Type) (*Schema, error) {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for _, field := range structFields(reflectType) {
+		name, ok := jsonName(field)
+		if !ok {
+			continue
+		}
+		fieldSchema, err := gen.schema(field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe the field '%s' (%w)", field.Name, err)
+		}
+		if applyValidationRules(fieldSchema, field.Tag.Get("validate")) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = fieldSchema
+	}
+	sort.Strings(schema.Required)
+	return schema, nil
+}
+
+// structFields returns the exported fields of the struct. The fields of embedded structs without a JSON name are
+// promoted, the same way encoding/json promotes them.
+func structFields(reflectType reflect.Type) []reflect.StructField {
+	fields := make([]reflect.StructField, 0, reflectType.NumField())
+	for fieldIndex := 0; fieldIndex < reflectType.NumField(); fieldIndex++ {
+		field := reflectType.Field(fieldIndex)
+		if field.Anonymous {
+			embeddedType := field.Type
+			if embeddedType.Kind() == reflect.Pointer {
+				embeddedType = embeddedType.Elem()
+			}
+			if jsonTag, _, _ := strings.Cut(field.Tag.Get(string(parameters.JSONTag)), ","); jsonTag == "" && embeddedType.Kind() == reflect.Struct {
+				fields = append(fields, structFields(embeddedType)...)
+				continue
+			}
+		}
+		if field.IsExported() {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// jsonName returns the name of the field in JSON, or false if the field is not encoded.
+func jsonName(field reflect.StructField) (string, bool) {
+	name, _, _ := strings.Cut(field.Tag.Get(string(parameters.JSONTag)), ",")
+	switch name {
+	case "-":
+		return "", false
+	case "":
+		return field.Name, true
+	default:
+		return name, true
+	}
+}
+
+// applyValidationRules adds the constraints of the validate tag to the schema. The rules after a dive
+// apply to the elements of a collection, so they are not documented. It returns true if the field is required.
+func applyValidationRules(schema *Schema, validateTag string) bool {
+	required := false
+	for _, rule := range strings.Split(validateTag, ",") {
+		name, value, _ := strings.Cut(rule, "=")
+		switch name {
+		case "dive":
+			return required
+		case "required":
+			required = true
+		case "oneof":
+			for _, option := range strings.Fields(value) {
+				schema.Enum = append(schema.Enum, enumValue(schema.Type, option))
+			}
+		case "email":
+			schema.Format = "email"
+		case "url", "uri":
+			schema.Format = "uri"
+		case "uuid", "uuid4":
+			schema.Format = "uuid"
+		case "ipv4", "ipv6":
+			schema.Format = name
+		case "min", "max", "len", "gt", "gte", "lt", "lte":
+			applyBound(schema, name, value)
+		}
+	}
+	return required
+}
+
+// applyBound adds a size or range constraint to the schema. Strings and arrays are bound by their length.
+// Bounds that cannot be parsed, or that are on other types, are not documented.
+func applyBound(schema *Schema, rule string, value string) {
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return
+	}
+
+	if schema.Type == "integer" || schema.Type == "number" {
+		switch rule {
+		case "min", "gte":
+			schema.Minimum = &number
+		case "max", "lte":
+			schema.Maximum = &number
+		case "gt":
+			schema.ExclusiveMinimum = &number
+		case "lt":
+			schema.ExclusiveMaximum = &number
+		case "len":
+			schema.Minimum, schema.Maximum = &number, &number
+		}
+		return
+	}
+
+	length := int(number)
+	var minimum, maximum **int
+	switch schema.Type {
+	case "string":
+		minimum, maximum = &schema.MinLength, &schema.MaxLength
+	case "array":
+		minimum, maximum = &schema.MinItems, &schema.MaxItems
+	default:
+		return
+	}
+	switch rule {
+	case "min", "gte":
+		*minimum = &length
+	case "max", "lte":
+		*maximum = &length
+	case "gt":
+		length++
+		*minimum = &length
+	case "lt":
+		length--
+		*maximum = &length
+	case "len":
+		*minimum, *maximum = &length, &length
+	}
+}
+
+// enumValue converts an option of a oneof rule to the type of the schema.
+func enumValue(schemaType string, option string) any {
+	switch schemaType {
+	case "integer":
+		if value, err := strconv.ParseInt(option, 10, 64); err == nil {
+			return value
+		}
+	case "number":
+		if value, err := strconv.ParseFloat(option, 64); err == nil {
+			return value
+		}
+	}
+	return option
+}
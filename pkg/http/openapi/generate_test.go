@@ -0,0 +1,204 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/http/api"
+	"github.com/TriangleSide/GoBase/pkg/http/openapi"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+type address struct {
+	Street string `json:"street" validate:"required"`
+	City   string `json:"city,omitempty"`
+}
+
+type pagination struct {
+	Limit int `urlQuery:"limit" json:"-" validate:"gte=1,lte=100"`
+}
+
+type createUserParams struct {
+	pagination
+	ID        string            `urlPath:"id" json:"-"`
+	RequestID string            `httpHeader:"X-Request-ID" json:"-" validate:"required,uuid"`
+	Session   string            `httpCookie:"session" json:"-"`
+	Name      string            `json:"name" validate:"required,min=2,max=10"`
+	Email     string            `json:"email" validate:"omitempty,email"`
+	Role      string            `json:"role" validate:"oneof=admin reader"`
+	Level     int               `json:"level" validate:"oneof=1 2 3"`
+	Score     float64           `json:"score" validate:"gt=0,lt=1"`
+	Tags      []string          `json:"tags" validate:"max=5,dive,min=1"`
+	Labels    map[string]string `json:"labels"`
+	Address   *address          `json:"address"`
+	Born      time.Time         `json:"born"`
+	Avatar    []byte            `json:"avatar"`
+	Count     uint              `json:"count"`
+	Extra     any               `json:"extra"`
+	Ignored   string            `json:"-"`
+	internal  string
+}
+
+type user struct {
+	Name    string   `json:"name"`
+	Friends []*user  `json:"friends"`
+	Home    address  `json:"home"`
+	Work    *address `json:"work"`
+}
+
+type testEndpoints struct {
+	handlers map[api.Path]map[api.Method]*api.Handler
+}
+
+func (e *testEndpoints) AcceptHTTPAPIBuilder(builder *api.HTTPAPIBuilder) {
+	for path, methods := range e.handlers {
+		for method, handler := range methods {
+			builder.MustRegister(path, method, handler)
+		}
+	}
+}
+
+func generate(t *testing.T, handlers map[api.Path]map[api.Method]*api.Handler) (*openapi.Document, error) {
+	t.Helper()
+	builder := api.NewHTTPAPIBuilder()
+	(&testEndpoints{handlers: handlers}).AcceptHTTPAPIBuilder(builder)
+	return openapi.Generate(builder, openapi.Info{Title: "Test", Version: "1.0.0"})
+}
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when a route is not documented it should have a default response", func(t *testing.T) {
+		t.Parallel()
+		document, err := generate(t, map[api.Path]map[api.Method]*api.Handler{
+			"/ping": {http.MethodGet: {}, http.MethodConnect: {}},
+		})
+		assert.NoError(t, err)
+		assert.Equals(t, document.OpenAPI, "3.1.0")
+		assert.Equals(t, document.Info.Title, "Test")
+		assert.Nil(t, document.Components)
+		assert.Equals(t, len(document.Paths["/ping"]), 1)
+		operation := document.Paths["/ping"]["get"]
+		assert.Equals(t, operation.Responses["default"].Description, "The response of the operation.")
+	})
+
+	t.Run("when a route documents its parameters it should describe them from the struct tags", func(t *testing.T) {
+		t.Parallel()
+		document, err := generate(t, map[api.Path]map[api.Method]*api.Handler{
+			"/users/{id}": {http.MethodPut: {Documentation: &api.Documentation{
+				Summary:     "Update a user",
+				Description: "Replaces the user.",
+				Tags:        []string{"users"},
+				Parameters:  reflect.TypeFor[createUserParams](),
+				Responses: map[int]reflect.Type{
+					http.StatusOK:        reflect.TypeFor[user](),
+					http.StatusNoContent: nil,
+				},
+			}}},
+		})
+		assert.NoError(t, err)
+		operation := document.Paths["/users/{id}"]["put"]
+		assert.Equals(t, operation.Summary, "Update a user")
+		assert.Equals(t, operation.Description, "Replaces the user.")
+		assert.Equals(t, operation.Tags, []string{"users"})
+
+		parametersByName := make(map[string]*openapi.Parameter)
+		for _, parameter := range operation.Parameters {
+			parametersByName[parameter.Name] = parameter
+		}
+		assert.Equals(t, len(parametersByName), 4)
+		assert.Equals(t, parametersByName["id"].In, "path")
+		assert.True(t, parametersByName["id"].Required)
+		assert.Equals(t, parametersByName["X-Request-ID"].In, "header")
+		assert.True(t, parametersByName["X-Request-ID"].Required)
+		assert.Equals(t, parametersByName["X-Request-ID"].Schema.Format, "uuid")
+		assert.Equals(t, parametersByName["session"].In, "cookie")
+		assert.False(t, parametersByName["session"].Required)
+		assert.Equals(t, parametersByName["limit"].In, "query")
+		assert.False(t, parametersByName["limit"].Required)
+		assert.Equals(t, *parametersByName["limit"].Schema.Minimum, float64(1))
+		assert.Equals(t, *parametersByName["limit"].Schema.Maximum, float64(100))
+
+		assert.True(t, operation.RequestBody.Required)
+		body := operation.RequestBody.Content["application/json"].Schema
+		assert.Equals(t, body.Required, []string{"name"})
+		assert.Equals(t, len(body.Properties), 12)
+		assert.Equals(t, body.Properties["name"].Type, "string")
+		assert.Equals(t, *body.Properties["name"].MinLength, 2)
+		assert.Equals(t, *body.Properties["name"].MaxLength, 10)
+		assert.Equals(t, body.Properties["email"].Format, "email")
+		assert.Equals(t, body.Properties["role"].Enum, []any{"admin", "reader"})
+		assert.Equals(t, body.Properties["level"].Enum, []any{int64(1), int64(2), int64(3)})
+		assert.Equals(t, body.Properties["level"].Format, "int64")
+		assert.Equals(t, *body.Properties["score"].ExclusiveMinimum, float64(0))
+		assert.Equals(t, *body.Properties["score"].ExclusiveMaximum, float64(1))
+		assert.Equals(t, body.Properties["tags"].Type, "array")
+		assert.Equals(t, *body.Properties["tags"].MaxItems, 5)
+		assert.Nil(t, body.Properties["tags"].Items.MinLength)
+		assert.Equals(t, body.Properties["labels"].AdditionalProperties.Type, "string")
+		assert.Equals(t, body.Properties["address"].Ref, "#/components/schemas/address")
+		assert.Equals(t, body.Properties["born"].Format, "date-time")
+		assert.Equals(t, body.Properties["avatar"].Format, "byte")
+		assert.Equals(t, *body.Properties["count"].Minimum, float64(0))
+		assert.Equals(t, body.Properties["extra"].Type, "")
+
+		assert.Equals(t, operation.Responses["200"].Description, "OK")
+		assert.Equals(t, operation.Responses["200"].Content["application/json"].Schema.Ref, "#/components/schemas/user")
+		assert.Equals(t, operation.Responses["204"].Description, "No Content")
+		assert.Nil(t, operation.Responses["204"].Content)
+
+		userSchema := document.Components.Schemas["user"]
+		assert.Equals(t, userSchema.Properties["friends"].Items.Ref, "#/components/schemas/user")
+		assert.Equals(t, userSchema.Properties["home"].Ref, "#/components/schemas/address")
+		assert.Equals(t, document.Components.Schemas["address"].Required, []string{"street"})
+	})
+
+	t.Run("when a route has patterned and wildcard parameters it should use the template of the path", func(t *testing.T) {
+		t.Parallel()
+		document, err := generate(t, map[api.Path]map[api.Method]*api.Handler{
+			"/users/{id:int}/files/{path...}": {http.MethodGet: {}},
+		})
+		assert.NoError(t, err)
+		assert.Equals(t, len(document.Paths), 1)
+		_, found := document.Paths["/users/{id}/files/{path}"]["get"]
+		assert.True(t, found)
+	})
+
+	t.Run("when the document is encoded as JSON it should use the specification field names", func(t *testing.T) {
+		t.Parallel()
+		document, err := generate(t, map[api.Path]map[api.Method]*api.Handler{
+			"/users": {http.MethodGet: {Documentation: &api.Documentation{
+				Responses: map[int]reflect.Type{http.StatusOK: reflect.TypeFor[[]user]()},
+			}}},
+		})
+		assert.NoError(t, err)
+		encoded, err := document.JSON()
+		assert.NoError(t, err)
+		decoded := map[string]any{}
+		assert.NoError(t, json.Unmarshal(encoded, &decoded))
+		schema := decoded["paths"].(map[string]any)["/users"].(map[string]any)["get"].(map[string]any)["responses"].(map[string]any)["200"].(map[string]any)["content"].(map[string]any)["application/json"].(map[string]any)["schema"].(map[string]any)
+		assert.Equals(t, schema["type"], "array")
+		assert.Equals(t, schema["items"].(map[string]any)["$ref"], "#/components/schemas/user")
+	})
+
+	t.Run("when the parameters are not a struct it should return an error", func(t *testing.T) {
+		t.Parallel()
+		_, err := generate(t, map[api.Path]map[api.Method]*api.Handler{
+			"/users": {http.MethodPost: {Documentation: &api.Documentation{Parameters: reflect.TypeFor[string]()}}},
+		})
+		assert.ErrorExact(t, err, "failed to document the route 'POST /users' (the request parameters must be a struct but are string)")
+	})
+
+	t.Run("when a type cannot be described it should return an error", func(t *testing.T) {
+		t.Parallel()
+		_, err := generate(t, map[api.Path]map[api.Method]*api.Handler{
+			"/users": {http.MethodGet: {Documentation: &api.Documentation{
+				Responses: map[int]reflect.Type{http.StatusOK: reflect.TypeFor[map[int]string]()},
+			}}},
+		})
+		assert.ErrorExact(t, err, "failed to document the route 'GET /users' (failed to describe the response body of status 200 (the map key of map[int]string must be a string))")
+	})
+}
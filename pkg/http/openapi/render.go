@@ -0,0 +1,142 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/logger"
+)
+
+// plainYAMLKey matches the mapping keys that can be written in YAML without quotes.
+var plainYAMLKey = regexp.MustCompile(`^[a-zA-Z_/$][a-zA-Z0-9_./{}$-]*$`)
+
+// JSON encodes the document as indented JSON.
+func (document *Document) JSON() ([]byte, error) {
+	return json.MarshalIndent(document, "", "  ")
+}
+
+// YAML encodes the document as YAML. The keys of every mapping are sorted.
+func (document *Document) YAML() ([]byte, error) {
+	encoded, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	builder := &strings.Builder{}
+	if err := writeYAML(builder, value, 0); err != nil {
+		return nil, err
+	}
+	return []byte(builder.String()), nil
+}
+
+// writeYAML writes a mapping or sequence as a block at the indentation.
+func writeYAML(builder *strings.Builder, value any, indent int) error {
+	prefix := strings.Repeat(" ", indent)
+	switch typed := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(typed))
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			builder.WriteString(prefix)
+			if plainYAMLKey.MatchString(key) {
+				builder.WriteString(key)
+			} else if err := writeYAMLScalar(builder, key); err != nil {
+				return err
+			}
+			builder.WriteString(":")
+			if err := writeYAMLValue(builder, typed[key], indent); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, item := range typed {
+			builder.WriteString(prefix)
+			builder.WriteString("-")
+			if err := writeYAMLValue(builder, item, indent); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("the value %v is not a mapping or a sequence", value)
+	}
+	return nil
+}
+
+// writeYAMLValue writes the value after a mapping key or sequence indicator. Empty collections and scalars
+// are written on the same line, and other collections are written as an indented block on the next lines.
+func writeYAMLValue(builder *strings.Builder, value any, indent int) error {
+	switch typed := value.(type) {
+	case map[string]any:
+		if len(typed) == 0 {
+			builder.WriteString(" {}\n")
+			return nil
+		}
+		builder.WriteString("\n")
+		return writeYAML(builder, typed, indent+2)
+	case []any:
+		if len(typed) == 0 {
+			builder.WriteString(" []\n")
+			return nil
+		}
+		builder.WriteString("\n")
+		return writeYAML(builder, typed, indent+2)
+	default:
+		builder.WriteString(" ")
+		if err := writeYAMLScalar(builder, value); err != nil {
+			return err
+		}
+		builder.WriteString("\n")
+		return nil
+	}
+}
+
+// writeYAMLScalar writes the scalar in its JSON form, which is valid YAML. Strings are double quoted.
+func writeYAMLScalar(builder *strings.Builder, value any) error {
+	buffer := &bytes.Buffer{}
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return err
+	}
+	builder.WriteString(strings.TrimSuffix(buffer.String(), "\n"))
+	return nil
+}
+
+// Handler returns an http.HandlerFunc that serves the document. It is served as YAML if the Accept header of
+// the request mentions YAML, and as JSON otherwise. The document is encoded once, when this function is called.
+func Handler(document *Document) (http.HandlerFunc, error) {
+	jsonDocument, err := document.JSON()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the document as JSON (%w)", err)
+	}
+	yamlDocument, err := document.YAML()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the document as YAML (%w)", err)
+	}
+
+	return func(writer http.ResponseWriter, request *http.Request) {
+		contentType, body := headers.ContentTypeApplicationJson, jsonDocument
+		if strings.Contains(strings.ToLower(request.Header.Get(headers.Accept)), "yaml") {
+			contentType, body = headers.ContentTypeApplicationYAML, yamlDocument
+		}
+		writer.Header().Set(headers.ContentType, contentType)
+		writer.WriteHeader(http.StatusOK)
+		if _, err := writer.Write(body); err != nil {
+			logger.Errorf(request.Context(), "Failed to write the OpenAPI document (%s).", err)
+		}
+	}, nil
+}
@@ -0,0 +1,94 @@
+package openapi_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/http/api"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/openapi"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	type item struct {
+		Name string   `json:"name" validate:"required"`
+		Tags []string `json:"tags"`
+	}
+
+	newDocument := func(t *testing.T) *openapi.Document {
+		t.Helper()
+		document, err := generate(t, map[api.Path]map[api.Method]*api.Handler{
+			"/items/{id}": {http.MethodGet: {Documentation: &api.Documentation{
+				Summary:   "Get an item: by ID",
+				Responses: map[int]reflect.Type{http.StatusOK: reflect.TypeFor[item]()},
+			}}},
+		})
+		assert.NoError(t, err)
+		return document
+	}
+
+	t.Run("when the document is encoded as YAML it should quote the keys and scalars that need it", func(t *testing.T) {
+		t.Parallel()
+		encoded, err := newDocument(t).YAML()
+		assert.NoError(t, err)
+		expected := `components:
+  schemas:
+    item:
+      properties:
+        name:
+          type: "string"
+        tags:
+          items:
+            type: "string"
+          type: "array"
+      required:
+        - "name"
+      type: "object"
+info:
+  title: "Test"
+  version: "1.0.0"
+openapi: "3.1.0"
+paths:
+  /items/{id}:
+    get:
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/item"
+          description: "OK"
+      summary: "Get an item: by ID"
+`
+		assert.Equals(t, string(encoded), expected)
+	})
+
+	t.Run("when the handler is requested without an accept header it should respond with JSON", func(t *testing.T) {
+		t.Parallel()
+		handler, err := openapi.Handler(newDocument(t))
+		assert.NoError(t, err)
+		recorder := httptest.NewRecorder()
+		handler(recorder, httptest.NewRequest(http.MethodGet, "/openapi", nil))
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.Equals(t, recorder.Header().Get(headers.ContentType), headers.ContentTypeApplicationJson)
+		assert.Contains(t, recorder.Body.String(), `"openapi": "3.1.0"`)
+	})
+
+	t.Run("when the handler is requested with a YAML accept header it should respond with YAML", func(t *testing.T) {
+		t.Parallel()
+		handler, err := openapi.Handler(newDocument(t))
+		assert.NoError(t, err)
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/openapi", nil)
+		request.Header.Set(headers.Accept, headers.ContentTypeApplicationYAML)
+		handler(recorder, request)
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.Equals(t, recorder.Header().Get(headers.ContentType), headers.ContentTypeApplicationYAML)
+		assert.Contains(t, recorder.Body.String(), `openapi: "3.1.0"`)
+	})
+}
@@ -0,0 +1,48 @@
+package parameters
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/TriangleSide/GoBase/pkg/http/codec"
+)
+
+var (
+	// bodyCodecsMu guards the bodyCodecs.
+	bodyCodecsMu sync.RWMutex
+
+	// bodyCodecs decode the request bodies of their content type in Decode.
+	bodyCodecs = []codec.Codec{codec.JSON()}
+)
+
+// MustRegisterBodyCodec adds a codec that Decode uses for the request bodies of its content type, like
+// codec.XML, codec.MessagePack, or a protobuf codec. Only the JSON codec is registered by default.
+//
+// The codecs other than JSON don't know the json:"-" tag, so the fields with a parameter tag, like HeaderTag,
+// are reset after the body is decoded with them. These fields are only set from their own request parameters.
+// This function panics if a codec is already registered for the content type. It is meant to be called
+// during initialization.
+func MustRegisterBodyCodec(bodyCodec codec.Codec) {
+	if bodyCodec == nil {
+		panic("the body codec cannot be nil")
+	}
+	bodyCodecsMu.Lock()
+	defer bodyCodecsMu.Unlock()
+	for _, registered := range bodyCodecs {
+		if strings.EqualFold(registered.ContentType(), bodyCodec.ContentType()) {
+			panic(fmt.Sprintf("a body codec is already registered for the content type %s", bodyCodec.ContentType()))
+		}
+	}
+	bodyCodecs = append(bodyCodecs, bodyCodec)
+}
+
+// bodyCodecForContentType returns the registered codec of the Content-Type header, or nil if there is none.
+func bodyCodecForContentType(contentType string) codec.Codec {
+	if contentType == "" {
+		return nil
+	}
+	bodyCodecsMu.RLock()
+	defer bodyCodecsMu.RUnlock()
+	return codec.ForContentType(contentType, bodyCodecs)
+}
@@ -0,0 +1,139 @@
+package parameters_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/http/codec"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/parameters"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+type testProtoBody struct {
+	Data []byte `json:"-"`
+}
+
+func TestBodyCodecs(t *testing.T) {
+	t.Parallel()
+
+	parameters.MustRegisterBodyCodec(codec.XML())
+	parameters.MustRegisterBodyCodec(codec.MessagePack())
+	parameters.MustRegisterBodyCodec(codec.NewProtobuf(func(message *testProtoBody) ([]byte, error) {
+		return message.Data, nil
+	}, func(data []byte, message *testProtoBody) error {
+		message.Data = data
+		return nil
+	}))
+
+	type bodyParams struct {
+		Name  string `json:"name" xml:"name" msgpack:"name" validate:"required"`
+		Query string `urlQuery:"query" json:"-" xml:"-" msgpack:"-" validate:"required"`
+	}
+
+	newRequest := func(t *testing.T, contentType string, body io.Reader) *http.Request {
+		t.Helper()
+		request, err := http.NewRequest(http.MethodPost, "/?query=value", body)
+		assert.NoError(t, err)
+		request.Header.Set(headers.ContentType, contentType)
+		return request
+	}
+
+	t.Run("when the body is XML it should be decoded with the XML codec", func(t *testing.T) {
+		t.Parallel()
+		request := newRequest(t, headers.ContentTypeApplicationXML, strings.NewReader(`<bodyParams><name>xml</name></bodyParams>`))
+		params, err := parameters.Decode[bodyParams](request)
+		assert.NoError(t, err)
+		assert.Equals(t, params.Name, "xml")
+		assert.Equals(t, params.Query, "value")
+	})
+
+	t.Run("when an XML body has a header bound field it should not set the field", func(t *testing.T) {
+		t.Parallel()
+		type headerParams struct {
+			Name   string `json:"name" xml:"name"`
+			UserID string `httpHeader:"X-User-Id" json:"-"`
+			Query  string `urlQuery:"query" json:"-"`
+		}
+		request := newRequest(t, headers.ContentTypeApplicationXML, strings.NewReader(
+			`<headerParams><name>xml</name><UserID>admin</UserID><Query>body</Query></headerParams>`))
+		params, err := parameters.Decode[headerParams](request)
+		assert.NoError(t, err)
+		assert.Equals(t, params.Name, "xml")
+		assert.Equals(t, params.UserID, "")
+		assert.Equals(t, params.Query, "value")
+	})
+
+	t.Run("when a MessagePack body has a header bound field it should not set the field", func(t *testing.T) {
+		t.Parallel()
+		type headerParams struct {
+			Name   string `json:"name" msgpack:"name"`
+			UserID string `httpHeader:"X-User-Id" json:"-" msgpack:"user_id"`
+		}
+		var body bytes.Buffer
+		assert.NoError(t, codec.MessagePack().Encode(&body, map[string]string{"name": "msgpack", "user_id": "admin"}))
+		request := newRequest(t, headers.ContentTypeApplicationMsgPack, &body)
+		request.Header.Set("X-User-Id", "user")
+		params, err := parameters.Decode[headerParams](request)
+		assert.NoError(t, err)
+		assert.Equals(t, params.Name, "msgpack")
+		assert.Equals(t, params.UserID, "user")
+	})
+
+	t.Run("when the body is malformed XML it should fail to decode", func(t *testing.T) {
+		t.Parallel()
+		request := newRequest(t, headers.ContentTypeApplicationXML, strings.NewReader(`<bodyParams>`))
+		_, err := parameters.Decode[bodyParams](request)
+		assert.ErrorPart(t, err, "failed to decode xml body")
+	})
+
+	t.Run("when the body is MessagePack it should be decoded with the MessagePack codec", func(t *testing.T) {
+		t.Parallel()
+		var body bytes.Buffer
+		assert.NoError(t, codec.MessagePack().Encode(&body, map[string]string{"name": "msgpack"}))
+		request := newRequest(t, headers.ContentTypeApplicationMsgPack, &body)
+		params, err := parameters.Decode[bodyParams](request)
+		assert.NoError(t, err)
+		assert.Equals(t, params.Name, "msgpack")
+	})
+
+	t.Run("when the content type has parameters it should still select the codec", func(t *testing.T) {
+		t.Parallel()
+		request := newRequest(t, headers.ContentTypeApplicationJson+"; charset=utf-8", strings.NewReader(`{"name":"json"}`))
+		params, err := parameters.Decode[bodyParams](request)
+		assert.NoError(t, err)
+		assert.Equals(t, params.Name, "json")
+	})
+
+	t.Run("when the content type has no codec the body should be ignored", func(t *testing.T) {
+		t.Parallel()
+		request := newRequest(t, "text/plain", strings.NewReader(`name`))
+		_, err := parameters.Decode[bodyParams](request)
+		assert.ErrorPart(t, err, "validation failed on field 'Name'")
+	})
+
+	t.Run("when a protobuf codec is registered it should decode protobuf bodies", func(t *testing.T) {
+		t.Parallel()
+		request := newRequest(t, headers.ContentTypeApplicationProtobuf, bytes.NewReader([]byte{8, 1}))
+		params, err := parameters.Decode[testProtoBody](request)
+		assert.NoError(t, err)
+		assert.Equals(t, params.Data, []byte{8, 1})
+	})
+
+	t.Run("when a codec is registered twice for a content type it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			parameters.MustRegisterBodyCodec(codec.JSON())
+		}, "a body codec is already registered for the content type application/json")
+	})
+
+	t.Run("when a nil codec is registered it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			parameters.MustRegisterBodyCodec(nil)
+		}, "the body codec cannot be nil")
+	})
+}
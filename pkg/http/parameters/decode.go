@@ -2,19 +2,40 @@ package parameters
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"mime"
 	"net/http"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/TriangleSide/GoBase/pkg/datastructures/readonlymap"
 	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/logger"
 	"github.com/TriangleSide/GoBase/pkg/utils/assign"
+	"github.com/TriangleSide/GoBase/pkg/utils/fields"
 	"github.com/TriangleSide/GoBase/pkg/validation"
 )
 
 // Decode populates a parameter struct with values from an HTTP request and performs validation on the struct.
+// If the logger has a slow operation threshold, decoding and validation that exceed it are logged with the type name.
+// The validation failure messages are translated to the locale that best matches the Accept-Language header.
 func Decode[T any](request *http.Request) (*T, error) {
+	return decode[T](request, true)
+}
+
+// DecodeWithoutBody is like Decode, but leaves the request body unread and open, so it can be streamed by the caller.
+// Only the query, header, path, and cookie parameters are decoded.
+func DecodeWithoutBody[T any](request *http.Request) (*T, error) {
+	return decode[T](request, false)
+}
+
+// decode populates and validates the parameter struct. The request body is decoded and closed if includeBody is true.
+func decode[T any](request *http.Request, includeBody bool) (*T, error) {
+	slowThreshold := logger.GetSlowOperationThreshold()
+	decodeStart := time.Now()
+
 	params := new(T)
 	if reflect.ValueOf(*params).Kind() != reflect.Struct {
 		panic("the generic must be a struct")
@@ -25,8 +46,10 @@ func Decode[T any](request *http.Request) (*T, error) {
 		panic(fmt.Sprintf("tags are not correctly formatted (%s)", err.Error()))
 	}
 
-	if err := decodeJSONBodyParameters(params, request); err != nil {
-		return nil, fmt.Errorf("failed to parse json body parameters (%w)", err)
+	if includeBody {
+		if err := decodeBodyParameters(params, tagToLookupKeyToFieldName, request); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := decodeQueryParameters(params, tagToLookupKeyToFieldName, request); err != nil {
@@ -41,11 +64,27 @@ func Decode[T any](request *http.Request) (*T, error) {
 		return nil, fmt.Errorf("failed to parse path parameters (%w)", err)
 	}
 
-	if err := validation.Struct(params); err != nil {
+	if err := decodeCookieParameters(params, tagToLookupKeyToFieldName, request); err != nil {
+		return nil, fmt.Errorf("failed to parse cookie parameters (%w)", err)
+	}
+
+	validationOpts := []validation.Option{
+		validation.WithLocale(validation.MatchLocale(request.Header.Get(headers.AcceptLanguage))),
+	}
+	if slowThreshold > 0 {
+		if elapsed := time.Since(decodeStart); elapsed > slowThreshold {
+			logger.Warnf(request.Context(), "Decoding the parameters %T took %s, which exceeds the threshold of %s.", params, elapsed, slowThreshold)
+		}
+		validationOpts = append(validationOpts, validation.WithSlowValidationReporter(slowThreshold, func(typeName string, elapsed time.Duration) {
+			logger.Warnf(request.Context(), "Validating the parameters %s took %s, which exceeds the threshold of %s.", typeName, elapsed, slowThreshold)
+		}))
+	}
+
+	if err := validation.Struct(params, validationOpts...); err != nil {
 		return nil, fmt.Errorf("validation failed for request parameters (%w)", err)
 	}
 
-	if request.Body != nil {
+	if includeBody && request.Body != nil {
 		if err := request.Body.Close(); err != nil {
 			return nil, err
 		}
@@ -54,22 +93,79 @@ func Decode[T any](request *http.Request) (*T, error) {
 	return params, nil
 }
 
-// decodeJSONBodyParameters decodes JSON from the request body into the parameter struct.
-func decodeJSONBodyParameters[T any](params *T, request *http.Request) error {
-	if strings.EqualFold(request.Header.Get(headers.ContentType), headers.ContentTypeApplicationJson) {
-		decoder := json.NewDecoder(request.Body)
-		decoder.DisallowUnknownFields()
-		if err := decoder.Decode(&params); err != nil {
-			return fmt.Errorf("failed to decode json body (%w)", err)
+// DecodeBody decodes the JSON request body into a value of any type, such as a slice or a map.
+// Unlike Decode, the generic does not need to be a struct. The value is only validated if it is a struct.
+// The parameters of the Content-Type header, like the charset, are ignored.
+func DecodeBody[T any](request *http.Request) (*T, error) {
+	mediaType, _, err := mime.ParseMediaType(request.Header.Get(headers.ContentType))
+	if err != nil || !strings.EqualFold(mediaType, headers.ContentTypeApplicationJson) {
+		return nil, fmt.Errorf("the content type must be %s", headers.ContentTypeApplicationJson)
+	}
+	if request.Body == nil {
+		return nil, errors.New("the request has no body")
+	}
+
+	body := new(T)
+	decoder := json.NewDecoder(request.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(body); err != nil {
+		return nil, fmt.Errorf("failed to decode json body (%w)", err)
+	}
+
+	if reflect.TypeFor[T]().Kind() == reflect.Struct {
+		locale := validation.MatchLocale(request.Header.Get(headers.AcceptLanguage))
+		if err := validation.Struct(body, validation.WithLocale(locale)); err != nil {
+			return nil, fmt.Errorf("validation failed for request body (%w)", err)
 		}
 	}
+
+	if err := request.Body.Close(); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+// decodeBodyParameters decodes the request body into the parameter struct with the body codec of its content type.
+// The body is ignored if no body codec is registered for the content type. The codecs other than JSON ignore the
+// json:"-" tag, so the fields with a parameter tag are reset to prevent the body from setting them.
+func decodeBodyParameters[T any](params *T, tagToLookupKeyToFieldName *readonlymap.ReadOnlyMap[Tag, LookupKeyToFieldName], request *http.Request) error {
+	bodyCodec := bodyCodecForContentType(request.Header.Get(headers.ContentType))
+	if bodyCodec == nil || request.Body == nil {
+		return nil
+	}
+	if err := bodyCodec.Decode(request.Body, params); err != nil {
+		// The format is named by the subtype of the content type, like json for application/json.
+		_, format, _ := strings.Cut(bodyCodec.ContentType(), "/")
+		return fmt.Errorf("failed to decode %s body (%w)", strings.TrimPrefix(format, "x-"), err)
+	}
+	if !strings.EqualFold(bodyCodec.ContentType(), headers.ContentTypeApplicationJson) {
+		resetParameterFields(params, tagToLookupKeyToFieldName)
+	}
 	return nil
 }
 
+// resetParameterFields sets the fields with a parameter tag to their zero value.
+func resetParameterFields[T any](params *T, tagToLookupKeyToFieldName *readonlymap.ReadOnlyMap[Tag, LookupKeyToFieldName]) {
+	fieldsMetadata := fields.StructMetadata[T]()
+	paramsValue := reflect.ValueOf(params).Elem()
+	for tag := range tagToLookupKeyNormalizer {
+		for _, fieldName := range tagToLookupKeyToFieldName.Get(tag) {
+			field, err := paramsValue.FieldByIndexErr(fieldsMetadata.Get(fieldName).Index)
+			if err != nil {
+				// The field is in a nil embedded struct pointer, so the body did not set it.
+				continue
+			}
+			field.SetZero()
+		}
+	}
+}
+
 // decodeQueryParameters identifies fields tagged with QueryTag and maps corresponding URL query parameters to these fields.
 func decodeQueryParameters[T any](params *T, tagToLookupKeyToFieldName *readonlymap.ReadOnlyMap[Tag, LookupKeyToFieldName], request *http.Request) error {
 	lookupKeyToFieldName := tagToLookupKeyToFieldName.Get(QueryTag)
 	normalizer := tagToLookupKeyNormalizer[QueryTag]
+	fieldsMetadata := fields.StructMetadata[T]()
 
 	for queryParameterName, queryParameterValues := range request.URL.Query() {
 		normalizedQueryParameterName := normalizer(queryParameterName)
@@ -77,6 +173,12 @@ func decodeQueryParameters[T any](params *T, tagToLookupKeyToFieldName *readonly
 		if !hasMatchedFieldName {
 			continue
 		}
+		if fieldMetadata := fieldsMetadata.Get(matchedFieldName); isQuerySliceType(fieldMetadata.Type) {
+			if err := decodeQuerySliceParameter(params, matchedFieldName, fieldMetadata, queryParameterValues); err != nil {
+				return fmt.Errorf("failed to set value for query parameter %s with values of %v (%w)", queryParameterName, queryParameterValues, err)
+			}
+			continue
+		}
 		if len(queryParameterValues) != 1 {
 			return fmt.Errorf("expecting one value for query parameter %s but found %v", queryParameterName, queryParameterValues)
 		}
@@ -88,6 +190,28 @@ func decodeQueryParameters[T any](params *T, tagToLookupKeyToFieldName *readonly
 	return nil
 }
 
+// decodeQuerySliceParameter sets the values of a query parameter into the elements of a slice field.
+// Every occurrence of the query parameter is an element, and if the field has a QuerySplitTag, the values are also
+// split on its separator. For compatibility, a single value of a field without a QuerySplitTag that starts with '['
+// is decoded as a JSON array.
+func decodeQuerySliceParameter[T any](params *T, fieldName string, fieldMetadata *fields.FieldMetadata, values []string) error {
+	separator, hasSeparator := QuerySplitSeparator(fieldMetadata)
+	if !hasSeparator {
+		if len(values) == 1 && strings.HasPrefix(values[0], "[") {
+			return assign.StructField(params, fieldName, values[0])
+		}
+		return assign.StructFieldValues(params, fieldName, values)
+	}
+	elements := make([]string, 0, len(values))
+	for _, value := range values {
+		if value == "" {
+			continue
+		}
+		elements = append(elements, strings.Split(value, separator)...)
+	}
+	return assign.StructFieldValues(params, fieldName, elements)
+}
+
 // decodeHeaderParameters identifies fields tagged with HeaderTag and maps corresponding HTTP headers to these fields.
 func decodeHeaderParameters[T any](params *T, tagToLookupKeyToFieldName *readonlymap.ReadOnlyMap[Tag, LookupKeyToFieldName], request *http.Request) error {
 	lookupKeyToFieldName := tagToLookupKeyToFieldName.Get(HeaderTag)
@@ -128,3 +252,25 @@ func decodePathParameters[T any](params *T, tagToLookupKeyToFieldName *readonlym
 
 	return nil
 }
+
+// decodeCookieParameters identifies fields tagged with CookieTag and maps corresponding request cookies to these fields.
+// Cookie names are case-sensitive.
+func decodeCookieParameters[T any](params *T, tagToLookupKeyToFieldName *readonlymap.ReadOnlyMap[Tag, LookupKeyToFieldName], request *http.Request) error {
+	lookupKeyToFieldName := tagToLookupKeyToFieldName.Get(CookieTag)
+	normalizer := tagToLookupKeyNormalizer[CookieTag]
+
+	for cookieName, field := range lookupKeyToFieldName {
+		cookies := request.CookiesNamed(normalizer(cookieName))
+		if len(cookies) == 0 {
+			continue
+		}
+		if len(cookies) != 1 {
+			return fmt.Errorf("expecting one value for cookie parameter %s but found %d", cookieName, len(cookies))
+		}
+		if err := assign.StructField(params, field, cookies[0].Value); err != nil {
+			return fmt.Errorf("failed to set value for cookie parameter %s with values of %v (%w)", cookieName, cookies[0].Value, err)
+		}
+	}
+
+	return nil
+}
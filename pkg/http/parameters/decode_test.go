@@ -7,15 +7,36 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"os"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
 
 	"github.com/TriangleSide/GoBase/pkg/http/headers"
 	"github.com/TriangleSide/GoBase/pkg/http/parameters"
+	"github.com/TriangleSide/GoBase/pkg/logger"
 	"github.com/TriangleSide/GoBase/pkg/test/assert"
+	"github.com/TriangleSide/GoBase/pkg/utils/enum"
 	"github.com/TriangleSide/GoBase/pkg/validation"
 )
 
+type testDecodeMode string
+
+type testDecodeRange struct {
+	From int `urlQuery:"from" json:"-" validate:"gte=0"`
+	To   int `urlQuery:"to" json:"-" validate:"gtefield=From"`
+	Step int `urlQuery:"step" json:"-" validate:"required_with=To"`
+}
+
+func (r *testDecodeRange) Validate() error {
+	if (r.To-r.From)%r.Step != 0 {
+		return errors.New("the range must be a multiple of the step")
+	}
+	return nil
+}
+
 type testJsonReadCloser struct {
 	ReturnedError error
 	Closed        bool
@@ -31,8 +52,15 @@ func (j *testJsonReadCloser) Close() error {
 	return j.ReturnedError
 }
 
+func init() {
+	validation.RegisterLocale("es", map[string]string{
+		"gte": "el campo '{field}' debe ser mayor o igual a {param}",
+	})
+}
+
 func TestDecodeHTTPParameters(t *testing.T) {
 	t.Parallel()
+	enum.MustRegister(enum.New([]testDecodeMode{"off", "tls", "mutual_tls"}, enum.WithCaseInsensitive()))
 
 	t.Run("when decoding a struct that fails the tag validation it should panic", func(t *testing.T) {
 		t.Parallel()
@@ -141,6 +169,198 @@ func TestDecodeHTTPParameters(t *testing.T) {
 		assert.ErrorPart(t, decodeErr, `failed to set value for path parameter urlTestPath`)
 	})
 
+	t.Run("when a query parameter is repeated it should decode each value into the slice elements", func(t *testing.T) {
+		t.Parallel()
+		request, err := http.NewRequest(http.MethodGet, "/?tag=a&tag=b&id=1&id=2&single=c&json="+url.QueryEscape(`["d","e"]`), nil)
+		assert.NoError(t, err)
+		params, err := parameters.Decode[struct {
+			Tags   []string  `urlQuery:"tag" json:"-"`
+			IDs    *[]int    `urlQuery:"id" json:"-"`
+			Single []string  `urlQuery:"single" json:"-"`
+			JSON   []string  `urlQuery:"json" json:"-"`
+			IP     net.IP    `urlQuery:"ip" json:"-"`
+			Ptrs   []*string `urlQuery:"ptr" json:"-"`
+		}](request)
+		assert.NoError(t, err)
+		assert.Equals(t, params.Tags, []string{"a", "b"})
+		assert.Equals(t, *params.IDs, []int{1, 2})
+		assert.Equals(t, params.Single, []string{"c"})
+		assert.Equals(t, params.JSON, []string{"d", "e"})
+	})
+
+	t.Run("when a query parameter has a split tag it should split the values on the separator", func(t *testing.T) {
+		t.Parallel()
+		request, err := http.NewRequest(http.MethodGet, "/?comma=a,b&comma=c&space=1%202&pipe=x|y&empty=", nil)
+		assert.NoError(t, err)
+		params, err := parameters.Decode[struct {
+			Comma []string `urlQuery:"comma" urlQuerySplit:"comma" json:"-"`
+			Space []int    `urlQuery:"space" urlQuerySplit:"space" json:"-"`
+			Pipe  []string `urlQuery:"pipe" urlQuerySplit:"pipe" json:"-"`
+			Empty []string `urlQuery:"empty" urlQuerySplit:"comma" json:"-"`
+		}](request)
+		assert.NoError(t, err)
+		assert.Equals(t, params.Comma, []string{"a", "b", "c"})
+		assert.Equals(t, params.Space, []int{1, 2})
+		assert.Equals(t, params.Pipe, []string{"x", "y"})
+		assert.Equals(t, params.Empty, []string{})
+	})
+
+	t.Run("when a slice element can't be parsed it should fail to decode", func(t *testing.T) {
+		t.Parallel()
+		request, err := http.NewRequest(http.MethodGet, "/?id=1,two", nil)
+		assert.NoError(t, err)
+		_, err = parameters.Decode[struct {
+			IDs []int `urlQuery:"id" urlQuerySplit:"comma" json:"-"`
+		}](request)
+		assert.ErrorPart(t, err, "failed to set value for query parameter id")
+		assert.ErrorPart(t, err, "element 1")
+	})
+
+	t.Run("when a slice element fails validation it should fail to decode", func(t *testing.T) {
+		t.Parallel()
+		request, err := http.NewRequest(http.MethodGet, "/?id=1&id=0", nil)
+		assert.NoError(t, err)
+		_, err = parameters.Decode[struct {
+			IDs []int `urlQuery:"id" json:"-" validate:"dive,gt=0"`
+		}](request)
+		assert.ErrorPart(t, err, "validation failed")
+	})
+
+	t.Run("when a request has cookies it should decode them into the cookie fields", func(t *testing.T) {
+		t.Parallel()
+		request, err := http.NewRequest(http.MethodGet, "/", nil)
+		assert.NoError(t, err)
+		request.AddCookie(&http.Cookie{Name: "session", Value: "token"})
+		request.AddCookie(&http.Cookie{Name: "count", Value: "3"})
+		request.AddCookie(&http.Cookie{Name: "Session", Value: "other"})
+		params, err := parameters.Decode[struct {
+			Session string `httpCookie:"session" json:"-" validate:"required"`
+			Count   *int   `httpCookie:"count" json:"-"`
+			Missing string `httpCookie:"missing" json:"-"`
+		}](request)
+		assert.NoError(t, err)
+		assert.Equals(t, params.Session, "token")
+		assert.Equals(t, *params.Count, 3)
+		assert.Equals(t, params.Missing, "")
+	})
+
+	t.Run("when a required cookie is missing it should fail validation", func(t *testing.T) {
+		t.Parallel()
+		request, err := http.NewRequest(http.MethodGet, "/", nil)
+		assert.NoError(t, err)
+		_, err = parameters.Decode[struct {
+			Session string `httpCookie:"session" json:"-" validate:"required"`
+		}](request)
+		assert.ErrorPart(t, err, "validation failed on field 'Session'")
+	})
+
+	t.Run("when a parameter uses a registered validator it should report the validator error", func(t *testing.T) {
+		t.Parallel()
+		validation.RegisterValidator("decode_lowercase_test", func(value any, _ string) error {
+			if str, ok := value.(string); ok && str == strings.ToLower(str) {
+				return nil
+			}
+			return errors.New("value must be lowercase")
+		})
+		type params struct {
+			Name string `urlQuery:"name" json:"-" validate:"decode_lowercase_test"`
+		}
+		request, err := http.NewRequest(http.MethodGet, "/?name=abc", nil)
+		assert.NoError(t, err)
+		decoded, err := parameters.Decode[params](request)
+		assert.NoError(t, err)
+		assert.Equals(t, decoded.Name, "abc")
+		request, err = http.NewRequest(http.MethodGet, "/?name=ABC", nil)
+		assert.NoError(t, err)
+		_, err = parameters.Decode[params](request)
+		assert.ErrorPart(t, err, "validation failed on field 'Name' with validator 'decode_lowercase_test': value must be lowercase")
+	})
+
+	t.Run("when the parameters have invariants between fields it should validate them", func(t *testing.T) {
+		t.Parallel()
+		for _, testCase := range []struct {
+			query string
+			err   string
+		}{
+			{query: "from=1&to=5&step=2", err: ""},
+			{query: "from=5&to=1&step=2", err: "validation failed on field 'To' with validator 'gtefield' and parameter(s) 'From'"},
+			{query: "from=1&to=5", err: "validation failed on field 'Step' with validator 'required_with' and parameter(s) 'To'"},
+			{query: "from=1&to=4&step=2", err: "struct validation failed (the range must be a multiple of the step)"},
+		} {
+			request, err := http.NewRequest(http.MethodGet, "/?"+testCase.query, nil)
+			assert.NoError(t, err)
+			_, err = parameters.Decode[testDecodeRange](request)
+			if testCase.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorPart(t, err, testCase.err)
+			}
+		}
+	})
+
+	t.Run("when the request has an Accept-Language header it should translate the validation messages", func(t *testing.T) {
+		t.Parallel()
+		request, err := http.NewRequest(http.MethodGet, "/?count=1", nil)
+		assert.NoError(t, err)
+		request.Header.Set(headers.AcceptLanguage, "es-MX, en;q=0.5")
+		_, err = parameters.Decode[struct {
+			Count int `urlQuery:"count" json:"-" validate:"gte=2"`
+		}](request)
+		assert.ErrorPart(t, err, "el campo 'Count' debe ser mayor o igual a 2")
+	})
+
+	t.Run("when there are multiple cookies with the same name it should fail to decode", func(t *testing.T) {
+		t.Parallel()
+		request, err := http.NewRequest(http.MethodGet, "/", nil)
+		assert.NoError(t, err)
+		request.AddCookie(&http.Cookie{Name: "session", Value: "a"})
+		request.AddCookie(&http.Cookie{Name: "session", Value: "b"})
+		_, err = parameters.Decode[struct {
+			Session string `httpCookie:"session" json:"-"`
+		}](request)
+		assert.ErrorPart(t, err, "expecting one value for cookie parameter session but found 2")
+	})
+
+	t.Run("when there is a cookie field that can't be set it should fail to decode", func(t *testing.T) {
+		t.Parallel()
+		request, err := http.NewRequest(http.MethodGet, "/", nil)
+		assert.NoError(t, err)
+		request.AddCookie(&http.Cookie{Name: "count", Value: "NotAnInt"})
+		_, err = parameters.Decode[struct {
+			Count int `httpCookie:"count" json:"-"`
+		}](request)
+		assert.ErrorPart(t, err, "failed to set value for cookie parameter count")
+	})
+
+	t.Run("when a case insensitive enum parameter has mixed case values it should decode into the canonical value", func(t *testing.T) {
+		t.Parallel()
+		for _, value := range []string{"TLS", "tls", "Tls"} {
+			request, err := http.NewRequest(http.MethodGet, "/?mode="+value, nil)
+			assert.NoError(t, err)
+			request.Header.Set("Mode", strings.ToUpper(value))
+			request.SetPathValue("mode", value)
+			params, err := parameters.Decode[struct {
+				QueryMode  testDecodeMode  `urlQuery:"mode" json:"-" validate:"oneof=off tls mutual_tls"`
+				HeaderMode *testDecodeMode `httpHeader:"mode" json:"-" validate:"required"`
+				PathMode   testDecodeMode  `urlPath:"mode" json:"-"`
+			}](request)
+			assert.NoError(t, err)
+			assert.Equals(t, params.QueryMode, testDecodeMode("tls"))
+			assert.Equals(t, *params.HeaderMode, testDecodeMode("tls"))
+			assert.Equals(t, params.PathMode, testDecodeMode("tls"))
+		}
+	})
+
+	t.Run("when an enum parameter has an unknown value it should fail with the valid options", func(t *testing.T) {
+		t.Parallel()
+		request, err := http.NewRequest(http.MethodGet, "/?mode=ssl", nil)
+		assert.NoError(t, err)
+		_, err = parameters.Decode[struct {
+			Mode testDecodeMode `urlQuery:"mode" json:"-"`
+		}](request)
+		assert.ErrorPart(t, err, "invalid value 'ssl', the valid options are: off, tls, mutual_tls")
+	})
+
 	t.Run("when the validation fails it should fail to decode", func(t *testing.T) {
 		t.Parallel()
 		request, err := http.NewRequest(http.MethodGet, "/", nil)
@@ -407,3 +627,186 @@ func TestDecodeHTTPParameters(t *testing.T) {
 		assert.Equals(t, (*params.JSONPtrListField)[1], "item2")
 	})
 }
+
+func TestDecodeBody(t *testing.T) {
+	t.Parallel()
+
+	type item struct {
+		Name string `json:"name" validate:"required"`
+	}
+
+	newJSONRequest := func(t *testing.T, body string) *http.Request {
+		t.Helper()
+		request, err := http.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		assert.NoError(t, err)
+		request.Header.Set(headers.ContentType, headers.ContentTypeApplicationJson)
+		return request
+	}
+
+	t.Run("when the body is a list it should decode into a slice", func(t *testing.T) {
+		t.Parallel()
+		items, err := parameters.DecodeBody[[]item](newJSONRequest(t, `[{"name":"first"},{"name":"second"}]`))
+		assert.NoError(t, err)
+		assert.Equals(t, *items, []item{{Name: "first"}, {Name: "second"}})
+	})
+
+	t.Run("when the body is an object it should decode into a map", func(t *testing.T) {
+		t.Parallel()
+		itemMap, err := parameters.DecodeBody[map[string]int](newJSONRequest(t, `{"first":1,"second":2}`))
+		assert.NoError(t, err)
+		assert.Equals(t, *itemMap, map[string]int{"first": 1, "second": 2})
+	})
+
+	t.Run("when the body is a struct it should be validated", func(t *testing.T) {
+		t.Parallel()
+		decoded, err := parameters.DecodeBody[item](newJSONRequest(t, `{"name":""}`))
+		assert.ErrorPart(t, err, "validation failed for request body")
+		assert.Nil(t, decoded)
+		decoded, err = parameters.DecodeBody[item](newJSONRequest(t, `{"name":"value"}`))
+		assert.NoError(t, err)
+		assert.Equals(t, decoded.Name, "value")
+	})
+
+	t.Run("when the body has an unknown field it should fail to decode", func(t *testing.T) {
+		t.Parallel()
+		decoded, err := parameters.DecodeBody[[]item](newJSONRequest(t, `[{"unknown":"value"}]`))
+		assert.ErrorPart(t, err, `unknown field "unknown"`)
+		assert.Nil(t, decoded)
+	})
+
+	t.Run("when the body is not correctly formatted it should fail to decode", func(t *testing.T) {
+		t.Parallel()
+		decoded, err := parameters.DecodeBody[[]item](newJSONRequest(t, `[{"name":"value"}`))
+		assert.ErrorPart(t, err, "failed to decode json body")
+		assert.Nil(t, decoded)
+	})
+
+	t.Run("when the content type is JSON with a charset it should decode the body", func(t *testing.T) {
+		t.Parallel()
+		request := newJSONRequest(t, `[{"name":"first"}]`)
+		request.Header.Set(headers.ContentType, "application/json; charset=utf-8")
+		items, err := parameters.DecodeBody[[]item](request)
+		assert.NoError(t, err)
+		assert.Equals(t, *items, []item{{Name: "first"}})
+	})
+
+	t.Run("when the content type is invalid it should fail to decode", func(t *testing.T) {
+		t.Parallel()
+		request := newJSONRequest(t, `[]`)
+		request.Header.Set(headers.ContentType, "application/json; charset")
+		decoded, err := parameters.DecodeBody[[]item](request)
+		assert.ErrorExact(t, err, "the content type must be application/json")
+		assert.Nil(t, decoded)
+	})
+
+	t.Run("when the content type is not JSON it should fail to decode", func(t *testing.T) {
+		t.Parallel()
+		request := newJSONRequest(t, `[]`)
+		request.Header.Set(headers.ContentType, "text/plain")
+		decoded, err := parameters.DecodeBody[[]item](request)
+		assert.ErrorExact(t, err, "the content type must be application/json")
+		assert.Nil(t, decoded)
+	})
+
+	t.Run("when the request has no body it should fail to decode", func(t *testing.T) {
+		t.Parallel()
+		request, err := http.NewRequest(http.MethodPost, "/", nil)
+		assert.NoError(t, err)
+		request.Header.Set(headers.ContentType, headers.ContentTypeApplicationJson)
+		decoded, err := parameters.DecodeBody[[]item](request)
+		assert.ErrorExact(t, err, "the request has no body")
+		assert.Nil(t, decoded)
+	})
+
+	t.Run("when the body fails to close it should return an error", func(t *testing.T) {
+		t.Parallel()
+		request := newJSONRequest(t, "")
+		readCloser := &testJsonReadCloser{
+			ReturnedError: errors.New("test error"),
+		}
+		request.Body = readCloser
+		decoded, err := parameters.DecodeBody[map[string]string](request)
+		assert.ErrorPart(t, err, "test error")
+		assert.True(t, readCloser.Closed)
+		assert.Nil(t, decoded)
+	})
+}
+
+func TestDecodeWithoutBody(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when the request has a json body it should decode the other parameters and leave the body open", func(t *testing.T) {
+		t.Parallel()
+		body := &testJsonReadCloser{}
+		request, err := http.NewRequest(http.MethodPost, "/?name=value", body)
+		assert.NoError(t, err)
+		request.Header.Set(headers.ContentType, headers.ContentTypeApplicationJson)
+		params, err := parameters.DecodeWithoutBody[struct {
+			Name    string `urlQuery:"name" json:"-" validate:"required"`
+			Message string `json:"message"`
+		}](request)
+		assert.NoError(t, err)
+		assert.Equals(t, params.Name, "value")
+		assert.Equals(t, params.Message, "")
+		assert.False(t, body.Closed)
+	})
+
+	t.Run("when the parameters fail validation it should return an error", func(t *testing.T) {
+		t.Parallel()
+		request, err := http.NewRequest(http.MethodPost, "/", nil)
+		assert.NoError(t, err)
+		_, err = parameters.DecodeWithoutBody[struct {
+			Name string `urlQuery:"name" json:"-" validate:"required"`
+		}](request)
+		assert.ErrorPart(t, err, "validation failed for request parameters")
+	})
+}
+
+func TestDecodeSlowOperationLogs(t *testing.T) {
+	var output bytes.Buffer
+	logger.SetOutput(&output)
+	t.Cleanup(func() {
+		logger.SetOutput(os.Stdout)
+		logger.SetSlowOperationThreshold(0)
+	})
+
+	validation.RegisterValidation("slow_decode_test", func(fl validator.FieldLevel) bool {
+		time.Sleep(time.Millisecond * 20)
+		return true
+	}, func(err validator.FieldError) string {
+		return ""
+	})
+
+	type slowParams struct {
+		Value string `urlQuery:"value" json:"-" validate:"slow_decode_test"`
+	}
+
+	decode := func() {
+		request, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "/?value=a", nil)
+		assert.NoError(t, err)
+		_, err = parameters.Decode[slowParams](request)
+		assert.NoError(t, err)
+	}
+
+	t.Run("when the slow operation threshold is disabled it should not log", func(t *testing.T) {
+		output.Reset()
+		logger.SetSlowOperationThreshold(0)
+		decode()
+		assert.Equals(t, output.String(), "")
+	})
+
+	t.Run("when the validation is slower than the threshold it should log the type and duration", func(t *testing.T) {
+		output.Reset()
+		logger.SetSlowOperationThreshold(time.Millisecond * 5)
+		decode()
+		assert.Contains(t, output.String(), "Validating the parameters *parameters_test.slowParams took")
+		assert.Contains(t, output.String(), "which exceeds the threshold of 5ms")
+	})
+
+	t.Run("when the validation is faster than the threshold it should not log", func(t *testing.T) {
+		output.Reset()
+		logger.SetSlowOperationThreshold(time.Hour)
+		decode()
+		assert.Equals(t, output.String(), "")
+	})
+}
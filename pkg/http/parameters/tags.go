@@ -1,6 +1,8 @@
 package parameters
 
 import (
+	"encoding"
+	"errors"
 	"fmt"
 	"reflect"
 	"regexp"
@@ -31,6 +33,28 @@ const (
 	// PathTag is a struct field tag used to specify that the field's value should be sourced from the URL path parameters.
 	PathTag Tag = "urlPath"
 
+	// CookieTag is a struct field tag used to specify that the field's value should be sourced from the request cookies.
+	CookieTag Tag = "httpCookie"
+
+	// QuerySplitTag is a struct field tag that sets how the query parameter values of a slice field tagged with QueryTag
+	// are split into elements. It is one of the QuerySplitComma, QuerySplitSpace, or QuerySplitPipe delimiters.
+	//
+	//	type MyStruct struct {
+	//	    Tags []string `urlQuery:"tag" urlQuerySplit:"comma" json:"-"`
+	//	}
+	//
+	// In this case, both ?tag=a,b and ?tag=a&tag=b decode into []string{"a", "b"}.
+	QuerySplitTag Tag = "urlQuerySplit"
+
+	// QuerySplitComma splits the query parameter values on commas, like ?tag=a,b.
+	QuerySplitComma = "comma"
+
+	// QuerySplitSpace splits the query parameter values on spaces, like ?tag=a%20b.
+	QuerySplitSpace = "space"
+
+	// QuerySplitPipe splits the query parameter values on pipes, like ?tag=a|b.
+	QuerySplitPipe = "pipe"
+
 	// JSONTag is a struct field tag used to specify that the field's value should be sourced from the request JSON body.
 	JSONTag Tag = "json"
 
@@ -59,13 +83,23 @@ var (
 		PathTag: func(s string) string {
 			return s
 		},
+		CookieTag: func(s string) string {
+			return s
+		},
+	}
+
+	// querySplitSeparators maps the values of the QuerySplitTag to the separator of the elements.
+	querySplitSeparators = map[string]string{
+		QuerySplitComma: ",",
+		QuerySplitSpace: " ",
+		QuerySplitPipe:  "|",
 	}
 
 	// lookupKeyFollowsNamingConvention is used to verify that a tags lookup key follow the naming convention as defined by TagLookupKeyNamingConvention.
 	lookupKeyFollowsNamingConvention func(lookupKey string) bool
 
 	// lookupKeyExtractionCache stores the results of the ExtractAndValidateFieldTagLookupKeys function.
-	lookupKeyExtractionCache = cache.New[reflect.Type, *readonlymap.ReadOnlyMap[Tag, LookupKeyToFieldName]]()
+	lookupKeyExtractionCache = cache.NewSharded[reflect.Type, *readonlymap.ReadOnlyMap[Tag, LookupKeyToFieldName]](cache.DefaultShardCount)
 )
 
 // init creates the variables needed by the processor.
@@ -132,8 +166,51 @@ func ExtractAndValidateFieldTagLookupKeys[T any]() (*readonlymap.ReadOnlyMap[Tag
 					return nil, nil, fmt.Errorf("struct field '%s' with tag '%s' must have accompanying tag %s:\"-\"", fieldName, customTag, JSONTag)
 				}
 			}
+
+			if split, hasSplit := fieldMetadata.Tags[string(QuerySplitTag)]; hasSplit {
+				if _, isQuery := fieldMetadata.Tags[string(QueryTag)]; !isQuery || !isQuerySliceType(fieldMetadata.Type) {
+					return nil, nil, fmt.Errorf("tag '%s' on the field '%s' requires a slice field with the tag '%s'", QuerySplitTag, fieldName, QueryTag)
+				}
+				if _, validSplit := querySplitSeparators[split]; !validSplit {
+					return nil, nil, fmt.Errorf("tag '%s' on the field '%s' has an unknown value '%s'", QuerySplitTag, fieldName, split)
+				}
+			}
 		}
 
 		return readonlymap.NewBuilder[Tag, LookupKeyToFieldName]().SetMap(tagToLookupKeyToFieldName).Build(), nil, nil
 	})
 }
+
+// ValidateTags checks that the tags of a parameter struct are well-formed. It runs the same checks as Decode,
+// but returns an error instead of panicking, so malformed tags can be found when the application starts
+// or in a test rather than on the first request.
+func ValidateTags[T any]() (err error) {
+	if reflect.TypeFor[T]().Kind() != reflect.Struct {
+		return errors.New("the generic must be a struct")
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("tags are not correctly formatted (%v)", recovered)
+		}
+	}()
+	if _, err := ExtractAndValidateFieldTagLookupKeys[T](); err != nil {
+		return fmt.Errorf("tags are not correctly formatted (%w)", err)
+	}
+	return nil
+}
+
+// isQuerySliceType returns true if the field type is a slice, or a pointer to a slice, whose query parameter values
+// are decoded into its elements. Slices that implement encoding.TextUnmarshaler, like net.IP, are decoded as a whole.
+func isQuerySliceType(fieldType reflect.Type) bool {
+	if fieldType.Kind() == reflect.Pointer {
+		fieldType = fieldType.Elem()
+	}
+	return fieldType.Kind() == reflect.Slice && !reflect.PointerTo(fieldType).Implements(reflect.TypeFor[encoding.TextUnmarshaler]())
+}
+
+// QuerySplitSeparator returns the separator of the elements of a slice field with the QuerySplitTag.
+// It returns false if the field has no QuerySplitTag.
+func QuerySplitSeparator(fieldMetadata *fields.FieldMetadata) (string, bool) {
+	separator, found := querySplitSeparators[fieldMetadata.Tags[string(QuerySplitTag)]]
+	return separator, found
+}
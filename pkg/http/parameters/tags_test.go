@@ -51,6 +51,8 @@ func TestTags(t *testing.T) {
 			HeaderField2 string `httpHeader:"Header2" json:"-" otherTag2:"value2"`
 			PathField1   string `urlPath:"Path1" json:"-" otherTag3:""`
 			PathField2   string `urlPath:"Path2" json:"-" otherTag4:"!@#$%^&*()"`
+			CookieField1 string `httpCookie:"Cookie1" json:"-"`
+			CookieField2 string `httpCookie:"cookie1" json:"-"`
 			JSONField1   string `json:"JSON1,omitempty"`
 			JSONField2   string `json:"JSON2,omitempty"`
 		}
@@ -74,6 +76,10 @@ func TestTags(t *testing.T) {
 			assert.Equals(t, len(tagToLookupKeyToFieldName.Get(parameters.PathTag)), 2)
 			assert.Equals(t, tagToLookupKeyToFieldName.Get(parameters.PathTag)["Path1"], "PathField1")
 			assert.Equals(t, tagToLookupKeyToFieldName.Get(parameters.PathTag)["Path2"], "PathField2")
+
+			assert.Equals(t, len(tagToLookupKeyToFieldName.Get(parameters.CookieTag)), 2)
+			assert.Equals(t, tagToLookupKeyToFieldName.Get(parameters.CookieTag)["Cookie1"], "CookieField1")
+			assert.Equals(t, tagToLookupKeyToFieldName.Get(parameters.CookieTag)["cookie1"], "CookieField2")
 		}
 	})
 
@@ -139,6 +145,30 @@ func TestTags(t *testing.T) {
 		assert.Nil(t, tagToLookupKeyToFieldName)
 	})
 
+	t.Run("it should fail when validating a struct that has a split tag without a query tag", func(t *testing.T) {
+		t.Parallel()
+		_, err := parameters.ExtractAndValidateFieldTagLookupKeys[struct {
+			Field []string `httpHeader:"field" urlQuerySplit:"comma" json:"-"`
+		}]()
+		assert.ErrorPart(t, err, "tag 'urlQuerySplit' on the field 'Field' requires a slice field with the tag 'urlQuery'")
+	})
+
+	t.Run("it should fail when validating a struct that has a split tag on a field that is not a slice", func(t *testing.T) {
+		t.Parallel()
+		_, err := parameters.ExtractAndValidateFieldTagLookupKeys[struct {
+			Field string `urlQuery:"field" urlQuerySplit:"comma" json:"-"`
+		}]()
+		assert.ErrorPart(t, err, "requires a slice field")
+	})
+
+	t.Run("it should fail when validating a struct that has a split tag with an unknown value", func(t *testing.T) {
+		t.Parallel()
+		_, err := parameters.ExtractAndValidateFieldTagLookupKeys[struct {
+			Field []string `urlQuery:"field" urlQuerySplit:"semicolon" json:"-"`
+		}]()
+		assert.ErrorPart(t, err, "tag 'urlQuerySplit' on the field 'Field' has an unknown value 'semicolon'")
+	})
+
 	t.Run("it should panic when the generic isn't a struct", func(t *testing.T) {
 		t.Parallel()
 		assert.Panic(t, func() {
@@ -156,3 +186,43 @@ func TestTags(t *testing.T) {
 		})
 	})
 }
+
+func TestValidateTags(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when the tags are well-formed it should succeed", func(t *testing.T) {
+		t.Parallel()
+		type testStruct struct {
+			Query  string `urlQuery:"query" json:"-"`
+			Header string `httpHeader:"header" json:"-"`
+			Body   string `json:"body"`
+		}
+		assert.NoError(t, parameters.ValidateTags[testStruct]())
+	})
+
+	t.Run("when two fields have the same lookup key it should return an error", func(t *testing.T) {
+		t.Parallel()
+		type testStruct struct {
+			First  string `urlQuery:"value" json:"-"`
+			Second string `urlQuery:"VALUE" json:"-"`
+		}
+		assert.ErrorPart(t, parameters.ValidateTags[testStruct](), "is not unique")
+	})
+
+	t.Run("when the struct field names are not unique it should return an error instead of panicking", func(t *testing.T) {
+		t.Parallel()
+		type embedded struct {
+			Field string `json:"field"`
+		}
+		type testStruct struct {
+			embedded
+			Field string `json:"other"`
+		}
+		assert.ErrorPart(t, parameters.ValidateTags[testStruct](), "tags are not correctly formatted")
+	})
+
+	t.Run("when the generic is not a struct it should return an error", func(t *testing.T) {
+		t.Parallel()
+		assert.ErrorExact(t, parameters.ValidateTags[*struct{}](), "the generic must be a struct")
+	})
+}
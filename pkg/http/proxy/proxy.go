@@ -0,0 +1,327 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/config"
+	"github.com/TriangleSide/GoBase/pkg/config/envprocessor"
+	httperrors "github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/http/responders"
+	"github.com/TriangleSide/GoBase/pkg/logger"
+)
+
+// proxyOptions is configured by the caller with the Option functions.
+type proxyOptions struct {
+	configProvider  func() (*config.HTTPClient, error)
+	transport       http.RoundTripper
+	stripPrefix     string
+	requestHeaders  []func(header http.Header)
+	responseHeaders []func(header http.Header)
+	maxAttempts     int
+	backoff         time.Duration
+	timeout         time.Duration
+}
+
+// Option is used to configure the Proxy.
+type Option func(proxyOpts *proxyOptions)
+
+// WithConfigProvider sets the provider for the config.HTTPClient. Its TLS mode configures the connections to
+// the upstream, so the certificates of the mutual_tls mode authenticate the proxy to the upstream. The timeout
+// of the config is not used, since it would cut off streamed responses. Use WithTimeout instead.
+func WithConfigProvider(provider func() (*config.HTTPClient, error)) Option {
+	return func(proxyOpts *proxyOptions) {
+		proxyOpts.configProvider = provider
+	}
+}
+
+// WithTransport sets the http.RoundTripper that sends the requests to the upstream.
+// The TLS mode of the config.HTTPClient is not applied to a custom transport.
+func WithTransport(transport http.RoundTripper) Option {
+	if transport == nil {
+		panic("the transport cannot be nil")
+	}
+	return func(proxyOpts *proxyOptions) {
+		proxyOpts.transport = transport
+	}
+}
+
+// WithStripPrefix removes the prefix from the path of the requests before it is joined to the path of the upstream.
+// Requests with paths that do not start with the prefix are forwarded unchanged.
+func WithStripPrefix(prefix string) Option {
+	if !strings.HasPrefix(prefix, "/") || strings.HasSuffix(prefix, "/") {
+		panic("the prefix to strip must start with '/' and not end with '/'")
+	}
+	return func(proxyOpts *proxyOptions) {
+		proxyOpts.stripPrefix = prefix
+	}
+}
+
+// WithRequestHeaders rewrites the headers of the requests sent to the upstream. The rewrites run in the order
+// they are added, after the hop-by-hop headers are removed and the X-Forwarded headers are set.
+func WithRequestHeaders(rewrite func(header http.Header)) Option {
+	if rewrite == nil {
+		panic("the request header rewrite cannot be nil")
+	}
+	return func(proxyOpts *proxyOptions) {
+		proxyOpts.requestHeaders = append(proxyOpts.requestHeaders, rewrite)
+	}
+}
+
+// WithResponseHeaders rewrites the headers of the responses of the upstream before they are sent to the client.
+func WithResponseHeaders(rewrite func(header http.Header)) Option {
+	if rewrite == nil {
+		panic("the response header rewrite cannot be nil")
+	}
+	return func(proxyOpts *proxyOptions) {
+		proxyOpts.responseHeaders = append(proxyOpts.responseHeaders, rewrite)
+	}
+}
+
+// WithRetries attempts the requests with idempotent methods and no body up to maxAttempts times, waiting the
+// backoff between the attempts. A request is retried when it fails to reach the upstream, when the upstream
+// does not respond within the timeout, or when the upstream responds with HTTP 502, 503, or 504. Requests with
+// a body are never retried, since their body is streamed to the upstream and cannot be sent again.
+func WithRetries(maxAttempts int, backoff time.Duration) Option {
+	if maxAttempts < 1 {
+		panic("the maximum number of attempts must be at least 1")
+	}
+	if backoff < 0 {
+		panic("the backoff cannot be negative")
+	}
+	return func(proxyOpts *proxyOptions) {
+		proxyOpts.maxAttempts = maxAttempts
+		proxyOpts.backoff = backoff
+	}
+}
+
+// WithTimeout bounds the time an attempt waits for the response header of the upstream. The response body is
+// not bounded, so streamed responses are not cut off. If the upstream does not respond in time, the client gets
+// an HTTP 504 gateway timeout.
+func WithTimeout(timeout time.Duration) Option {
+	if timeout <= 0 {
+		panic("the timeout must be greater than zero")
+	}
+	return func(proxyOpts *proxyOptions) {
+		proxyOpts.timeout = timeout
+	}
+}
+
+// Proxy forwards requests to an upstream server and streams the responses back to the client.
+//
+// The path of a request is joined to the path of the upstream URL, and its query is kept. The hop-by-hop
+// headers are removed, the X-Forwarded headers are set, and protocol upgrades like WebSockets are supported.
+// A Proxy is registered like any other handler, for example with a wildcard path like /users/{path...}
+// and &api.Handler{Handler: proxy.ServeHTTP}, so each route can have its own Proxy and options.
+type Proxy struct {
+	reverseProxy *httputil.ReverseProxy
+}
+
+// New allocates a Proxy that forwards requests to the upstream URL.
+func New(upstream string, opts ...Option) (*Proxy, error) {
+	proxyOpts := &proxyOptions{
+		configProvider: func() (*config.HTTPClient, error) {
+			return envprocessor.ProcessAndValidate[config.HTTPClient]()
+		},
+		transport:   nil,
+		maxAttempts: 1,
+	}
+	for _, opt := range opts {
+		opt(proxyOpts)
+	}
+
+	upstreamURL, err := url.Parse(upstream)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the upstream URL (%w)", err)
+	}
+	if (upstreamURL.Scheme != "http" && upstreamURL.Scheme != "https") || upstreamURL.Host == "" {
+		return nil, fmt.Errorf("the upstream URL '%s' must be an absolute http or https URL", upstream)
+	}
+
+	cfg, err := proxyOpts.configProvider()
+	if err != nil {
+		return nil, fmt.Errorf("could not load configuration (%w)", err)
+	}
+
+	transport := proxyOpts.transport
+	if transport == nil {
+		tlsConfig, err := config.BuildClientTLSConfig(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the TLS config (%w)", err)
+		}
+		defaultTransport := http.DefaultTransport.(*http.Transport).Clone()
+		if tlsConfig != nil {
+			defaultTransport.TLSClientConfig = tlsConfig
+		}
+		transport = defaultTransport
+	}
+
+	return &Proxy{
+		reverseProxy: &httputil.ReverseProxy{
+			Rewrite: func(proxyRequest *httputil.ProxyRequest) {
+				stripPrefix(proxyRequest.Out.URL, proxyOpts.stripPrefix)
+				proxyRequest.SetURL(upstreamURL)
+				proxyRequest.SetXForwarded()
+				for _, rewrite := range proxyOpts.requestHeaders {
+					rewrite(proxyRequest.Out.Header)
+				}
+			},
+			Transport: &retryTransport{
+				next:        transport,
+				maxAttempts: proxyOpts.maxAttempts,
+				backoff:     proxyOpts.backoff,
+				timeout:     proxyOpts.timeout,
+			},
+			ModifyResponse: func(response *http.Response) error {
+				for _, rewrite := range proxyOpts.responseHeaders {
+					rewrite(response.Header)
+				}
+				return nil
+			},
+			ErrorHandler: handleError,
+		},
+	}, nil
+}
+
+// ServeHTTP forwards the request to the upstream and copies the response to the writer.
+func (proxy *Proxy) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	proxy.reverseProxy.ServeHTTP(writer, request)
+}
+
+// stripPrefix removes the prefix from the path of the URL if the path starts with it.
+func stripPrefix(requestURL *url.URL, prefix string) {
+	if prefix == "" {
+		return
+	}
+	trimmed, found := strings.CutPrefix(requestURL.Path, prefix)
+	if !found || (trimmed != "" && !strings.HasPrefix(trimmed, "/")) {
+		return
+	}
+	requestURL.Path = trimmed
+	if requestURL.RawPath != "" {
+		requestURL.RawPath = strings.TrimPrefix(requestURL.RawPath, prefix)
+	}
+}
+
+// errUpstreamTimeout is returned by the retryTransport when the upstream does not respond within the timeout.
+var errUpstreamTimeout = errors.New("the upstream did not respond in time")
+
+// handleError responds to the client when the request could not be forwarded to the upstream.
+func handleError(writer http.ResponseWriter, request *http.Request, err error) {
+	if request.Context().Err() != nil {
+		// The client is gone, so there is no one to respond to.
+		return
+	}
+	if errors.Is(err, errUpstreamTimeout) {
+		responders.Error(request, writer, &httperrors.GatewayTimeout{Err: errUpstreamTimeout})
+		return
+	}
+	logger.Errorf(request.Context(), "Failed to forward the request to the upstream (%s).", err)
+	responders.Error(request, writer, &httperrors.BadGateway{Err: errors.New("failed to reach the upstream")})
+}
+
+// retryTransport bounds the time to wait for the response header of each attempt, and retries the attempts
+// of idempotent requests without a body.
+type retryTransport struct {
+	next        http.RoundTripper
+	maxAttempts int
+	backoff     time.Duration
+	timeout     time.Duration
+}
+
+// RoundTrip sends the request to the upstream, retrying it if it fails and can be sent again.
+func (transport *retryTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	maxAttempts := 1
+	if isIdempotent(request.Method) && (request.Body == nil || request.Body == http.NoBody) {
+		maxAttempts = transport.maxAttempts
+	}
+	for attempt := 1; ; attempt++ {
+		response, err := transport.attempt(request)
+		if attempt >= maxAttempts || request.Context().Err() != nil {
+			return response, err
+		}
+		if err == nil {
+			if !isRetryableStatus(response.StatusCode) {
+				return response, nil
+			}
+			_ = response.Body.Close()
+		}
+
+		timer := time.NewTimer(transport.backoff)
+		select {
+		case <-request.Context().Done():
+			timer.Stop()
+			return nil, request.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt sends the request once. If the response header is not received within the timeout, the attempt is
+// canceled. Otherwise, the attempt is canceled when the response body is closed.
+func (transport *retryTransport) attempt(request *http.Request) (*http.Response, error) {
+	if transport.timeout == 0 {
+		return transport.next.RoundTrip(request)
+	}
+	ctx, cancel := context.WithCancel(request.Context())
+	timer := time.AfterFunc(transport.timeout, cancel)
+	response, err := transport.next.RoundTrip(request.WithContext(ctx))
+	if !timer.Stop() && request.Context().Err() == nil {
+		if err == nil {
+			_ = response.Body.Close()
+		}
+		cancel()
+		return nil, errUpstreamTimeout
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if response.StatusCode == http.StatusSwitchingProtocols {
+		// The body of an upgraded connection must stay writable, so it is not wrapped. The attempt is
+		// canceled with the request instead.
+		return response, nil
+	}
+	response.Body = &cancelOnClose{ReadCloser: response.Body, cancel: cancel}
+	return response, nil
+}
+
+// cancelOnClose cancels the context of an attempt when the response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the response body and cancels the context of its attempt.
+func (body *cancelOnClose) Close() error {
+	err := body.ReadCloser.Close()
+	body.cancel()
+	return err
+}
+
+// isIdempotent returns true if sending the request with the method many times has the same effect as sending it once.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// isRetryableStatus returns true if the status indicates that the upstream may respond successfully to another attempt.
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,283 @@
+package proxy_test
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/config"
+	"github.com/TriangleSide/GoBase/pkg/http/proxy"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+// offConfig is a config provider for upstreams without TLS.
+var offConfig = proxy.WithConfigProvider(func() (*config.HTTPClient, error) {
+	return &config.HTTPClient{HTTPClientTLSMode: config.HTTPServerTLSModeOff}, nil
+})
+
+// startProxy starts a server that forwards every request to the upstream URL with the options.
+func startProxy(t *testing.T, upstreamURL string, opts ...proxy.Option) string {
+	t.Helper()
+	p, err := proxy.New(upstreamURL, append([]proxy.Option{offConfig}, opts...)...)
+	assert.NoError(t, err)
+	srv := httptest.NewServer(p)
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+// startUpstream starts an upstream server with the handler.
+func startUpstream(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	upstream := httptest.NewServer(handler)
+	t.Cleanup(upstream.Close)
+	return upstream
+}
+
+func TestProxy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when a request is forwarded it should rewrite the path and headers", func(t *testing.T) {
+		t.Parallel()
+		upstream := startUpstream(t, func(writer http.ResponseWriter, request *http.Request) {
+			writer.Header().Set("X-Path", request.URL.Path)
+			writer.Header().Set("X-Query", request.URL.RawQuery)
+			writer.Header().Set("X-Forwarded", request.Header.Get("X-Forwarded-For"))
+			writer.Header().Set("X-Added", request.Header.Get("X-Added"))
+			writer.Header().Set("X-Internal", "secret")
+			writer.Header().Set("X-Had-Authorization", request.Header.Get("Authorization"))
+			_, _ = io.Copy(writer, request.Body)
+		})
+		proxyURL := startProxy(t, upstream.URL+"/internal",
+			proxy.WithStripPrefix("/api"),
+			proxy.WithRequestHeaders(func(header http.Header) {
+				header.Set("X-Added", "added")
+				header.Del("Authorization")
+			}),
+			proxy.WithResponseHeaders(func(header http.Header) {
+				header.Del("X-Internal")
+			}))
+
+		request, err := http.NewRequest(http.MethodPost, proxyURL+"/api/users?limit=2", strings.NewReader("body"))
+		assert.NoError(t, err)
+		request.Header.Set("Authorization", "Bearer token")
+		response, err := http.DefaultClient.Do(request)
+		assert.NoError(t, err)
+		body, err := io.ReadAll(response.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, response.Body.Close())
+
+		assert.Equals(t, response.StatusCode, http.StatusOK)
+		assert.Equals(t, string(body), "body")
+		assert.Equals(t, response.Header.Get("X-Path"), "/internal/users")
+		assert.Equals(t, response.Header.Get("X-Query"), "limit=2")
+		assert.Equals(t, response.Header.Get("X-Forwarded"), "127.0.0.1")
+		assert.Equals(t, response.Header.Get("X-Added"), "added")
+		assert.Equals(t, response.Header.Get("X-Had-Authorization"), "")
+		assert.Equals(t, response.Header.Get("X-Internal"), "")
+
+		response, err = http.Get(proxyURL + "/other")
+		assert.NoError(t, err)
+		assert.NoError(t, response.Body.Close())
+		assert.Equals(t, response.Header.Get("X-Path"), "/internal/other")
+	})
+
+	t.Run("when an idempotent request fails it should be retried", func(t *testing.T) {
+		t.Parallel()
+		var attempts atomic.Int32
+		upstream := startUpstream(t, func(writer http.ResponseWriter, request *http.Request) {
+			if attempts.Add(1) < 3 {
+				writer.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			writer.WriteHeader(http.StatusOK)
+		})
+		proxyURL := startProxy(t, upstream.URL, proxy.WithRetries(3, time.Millisecond))
+		response, err := http.Get(proxyURL)
+		assert.NoError(t, err)
+		assert.NoError(t, response.Body.Close())
+		assert.Equals(t, response.StatusCode, http.StatusOK)
+		assert.Equals(t, attempts.Load(), int32(3))
+	})
+
+	t.Run("when a request is not idempotent or has a body it should not be retried", func(t *testing.T) {
+		t.Parallel()
+		var attempts atomic.Int32
+		upstream := startUpstream(t, func(writer http.ResponseWriter, request *http.Request) {
+			attempts.Add(1)
+			writer.WriteHeader(http.StatusServiceUnavailable)
+		})
+		proxyURL := startProxy(t, upstream.URL, proxy.WithRetries(3, time.Millisecond))
+		for _, method := range []string{http.MethodPost, http.MethodPut} {
+			attempts.Store(0)
+			request, err := http.NewRequest(method, proxyURL, strings.NewReader("body"))
+			assert.NoError(t, err)
+			response, err := http.DefaultClient.Do(request)
+			assert.NoError(t, err)
+			assert.NoError(t, response.Body.Close())
+			assert.Equals(t, response.StatusCode, http.StatusServiceUnavailable)
+			assert.Equals(t, attempts.Load(), int32(1))
+		}
+	})
+
+	t.Run("when the upstream does not respond in time it should respond with gateway timeout", func(t *testing.T) {
+		t.Parallel()
+		release := make(chan struct{})
+		upstream := startUpstream(t, func(writer http.ResponseWriter, request *http.Request) {
+			select {
+			case <-release:
+			case <-request.Context().Done():
+			}
+		})
+		t.Cleanup(func() {
+			close(release)
+		})
+		proxyURL := startProxy(t, upstream.URL, proxy.WithTimeout(time.Millisecond*50))
+		response, err := http.Get(proxyURL)
+		assert.NoError(t, err)
+		body, err := io.ReadAll(response.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, response.Body.Close())
+		assert.Equals(t, response.StatusCode, http.StatusGatewayTimeout)
+		assert.Contains(t, string(body), "the upstream did not respond in time")
+	})
+
+	t.Run("when the upstream cannot be reached it should respond with bad gateway", func(t *testing.T) {
+		t.Parallel()
+		upstream := httptest.NewServer(http.NotFoundHandler())
+		upstream.Close()
+		proxyURL := startProxy(t, upstream.URL, proxy.WithRetries(2, time.Millisecond))
+		response, err := http.Get(proxyURL)
+		assert.NoError(t, err)
+		body, err := io.ReadAll(response.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, response.Body.Close())
+		assert.Equals(t, response.StatusCode, http.StatusBadGateway)
+		assert.Contains(t, string(body), "failed to reach the upstream")
+	})
+
+	t.Run("when the upstream streams its response it should be streamed to the client past the timeout", func(t *testing.T) {
+		t.Parallel()
+		release := make(chan struct{})
+		upstream := startUpstream(t, func(writer http.ResponseWriter, request *http.Request) {
+			writer.Header().Set("Content-Type", "text/event-stream")
+			_, _ = io.WriteString(writer, "first\n")
+			writer.(http.Flusher).Flush()
+			<-release
+			_, _ = io.WriteString(writer, "second\n")
+		})
+		proxyURL := startProxy(t, upstream.URL, proxy.WithTimeout(time.Millisecond*50))
+		response, err := http.Get(proxyURL)
+		assert.NoError(t, err)
+		reader := bufio.NewReader(response.Body)
+		line, err := reader.ReadString('\n')
+		assert.NoError(t, err)
+		assert.Equals(t, line, "first\n")
+		time.Sleep(time.Millisecond * 100)
+		close(release)
+		line, err = reader.ReadString('\n')
+		assert.NoError(t, err)
+		assert.Equals(t, line, "second\n")
+		assert.NoError(t, response.Body.Close())
+	})
+
+	t.Run("when the TLS mode is mutual TLS it should authenticate with the upstream", func(t *testing.T) {
+		t.Parallel()
+		certPath, keyPath := writeCertificate(t)
+		upstreamTLSConfig, err := config.BuildTLSConfig(&config.HTTPServer{
+			HTTPServerTLSMode:       config.HTTPServerTLSModeMutualTLS,
+			HTTPServerCert:          certPath,
+			HTTPServerKey:           keyPath,
+			HTTPServerClientCACerts: []string{certPath},
+		})
+		assert.NoError(t, err)
+		upstream := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			_, _ = io.WriteString(writer, request.TLS.PeerCertificates[0].Subject.CommonName)
+		}))
+		upstream.TLS = upstreamTLSConfig
+		upstream.StartTLS()
+		t.Cleanup(upstream.Close)
+
+		p, err := proxy.New(upstream.URL, proxy.WithConfigProvider(func() (*config.HTTPClient, error) {
+			return &config.HTTPClient{
+				HTTPClientTLSMode:     config.HTTPServerTLSModeMutualTLS,
+				HTTPClientCert:        certPath,
+				HTTPClientKey:         keyPath,
+				HTTPClientRootCACerts: []string{certPath},
+			}, nil
+		}))
+		assert.NoError(t, err)
+		recorder := httptest.NewRecorder()
+		p.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.Equals(t, recorder.Body.String(), "proxy-test")
+	})
+
+	t.Run("when the proxy is misconfigured it should fail to be created", func(t *testing.T) {
+		t.Parallel()
+		_, err := proxy.New("://bad", offConfig)
+		assert.ErrorPart(t, err, "failed to parse the upstream URL")
+		_, err = proxy.New("/relative", offConfig)
+		assert.ErrorExact(t, err, "the upstream URL '/relative' must be an absolute http or https URL")
+		_, err = proxy.New("http://localhost", proxy.WithConfigProvider(func() (*config.HTTPClient, error) {
+			return nil, errors.New("no config")
+		}))
+		assert.ErrorPart(t, err, "could not load configuration")
+		_, err = proxy.New("http://localhost", proxy.WithConfigProvider(func() (*config.HTTPClient, error) {
+			return &config.HTTPClient{HTTPClientTLSMode: config.HTTPServerTLSModeMutualTLS, HTTPClientCert: "missing"}, nil
+		}))
+		assert.ErrorPart(t, err, "failed to create the TLS config")
+	})
+
+	t.Run("when the options are invalid it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicExact(t, func() { proxy.WithTransport(nil) }, "the transport cannot be nil")
+		assert.PanicExact(t, func() { proxy.WithStripPrefix("api") }, "the prefix to strip must start with '/' and not end with '/'")
+		assert.PanicExact(t, func() { proxy.WithStripPrefix("/api/") }, "the prefix to strip must start with '/' and not end with '/'")
+		assert.PanicExact(t, func() { proxy.WithRequestHeaders(nil) }, "the request header rewrite cannot be nil")
+		assert.PanicExact(t, func() { proxy.WithResponseHeaders(nil) }, "the response header rewrite cannot be nil")
+		assert.PanicExact(t, func() { proxy.WithRetries(0, 0) }, "the maximum number of attempts must be at least 1")
+		assert.PanicExact(t, func() { proxy.WithRetries(1, -1) }, "the backoff cannot be negative")
+		assert.PanicExact(t, func() { proxy.WithTimeout(0) }, "the timeout must be greater than zero")
+	})
+}
+
+// writeCertificate writes a self-signed certificate for 127.0.0.1 that can authenticate servers and clients.
+func writeCertificate(t *testing.T) (string, string) {
+	t.Helper()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	certTemplate := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "proxy-test"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, &certTemplate, &certTemplate, &privateKey.PublicKey, privateKey)
+	assert.NoError(t, err)
+	tempDir := t.TempDir()
+	certPath := filepath.Join(tempDir, "cert.pem")
+	assert.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certBytes}), 0644))
+	keyPath := filepath.Join(tempDir, "key.pem")
+	assert.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)}), 0600))
+	return certPath, keyPath
+}
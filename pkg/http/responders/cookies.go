@@ -0,0 +1,28 @@
+package responders
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// setCookies adds the cookies configured with WithCookies for the response to the Set-Cookie headers.
+func setCookies(writer http.ResponseWriter, response any, cfg *config) {
+	if cfg.cookies == nil {
+		return
+	}
+	for _, cookie := range cfg.cookies(response) {
+		http.SetCookie(writer, cookie)
+	}
+}
+
+// cookiesForResponse adapts a cookie callback of a response type to the responder configuration.
+// It panics if the responder encodes another type, since the option does not match the responder.
+func cookiesForResponse[ResponseBody any](cookies func(response *ResponseBody) []*http.Cookie) func(response any) []*http.Cookie {
+	return func(response any) []*http.Cookie {
+		typedResponse, ok := response.(*ResponseBody)
+		if !ok {
+			panic(fmt.Sprintf("the cookie option is for responses of type %T but the response is of type %T", new(ResponseBody), response))
+		}
+		return cookies(typedResponse)
+	}
+}
@@ -0,0 +1,74 @@
+package responders_test
+
+import (
+	goerrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/http/responders"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestCookiesOption(t *testing.T) {
+	t.Parallel()
+
+	type responseBody struct {
+		Token string `json:"-" xml:"-"`
+		Name  string `json:"name" xml:"name"`
+	}
+
+	sessionCookie := responders.WithCookies(func(response *responseBody) []*http.Cookie {
+		return []*http.Cookie{{Name: "session", Value: response.Token, HttpOnly: true, Path: "/"}}
+	})
+
+	t.Run("when the JSON responder succeeds it should set the cookies of the response", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, "/", nil)
+		responders.JSON(recorder, request, func(*struct{}) (*responseBody, int, error) {
+			return &responseBody{Token: "abc", Name: "user"}, http.StatusOK, nil
+		}, sessionCookie)
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		cookies := recorder.Result().Cookies()
+		assert.Equals(t, len(cookies), 1)
+		assert.Equals(t, cookies[0].Name, "session")
+		assert.Equals(t, cookies[0].Value, "abc")
+		assert.True(t, cookies[0].HttpOnly)
+	})
+
+	t.Run("when the Negotiate responder succeeds it should set the cookies of the response", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		responders.Negotiate(recorder, request, func(*struct{}) (*responseBody, int, error) {
+			return &responseBody{Token: "def", Name: "user"}, http.StatusOK, nil
+		}, sessionCookie)
+		cookies := recorder.Result().Cookies()
+		assert.Equals(t, len(cookies), 1)
+		assert.Equals(t, cookies[0].Value, "def")
+	})
+
+	t.Run("when the callback fails it should not set the cookies", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, "/", nil)
+		responders.JSON(recorder, request, func(*struct{}) (*responseBody, int, error) {
+			return nil, 0, &errors.BadRequest{Err: goerrors.New("failure")}
+		}, sessionCookie)
+		assert.Equals(t, recorder.Code, http.StatusBadRequest)
+		assert.Equals(t, len(recorder.Result().Cookies()), 0)
+	})
+
+	t.Run("when the cookie option is for another response type it should panic", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, "/", nil)
+		assert.PanicPart(t, func() {
+			responders.JSON(recorder, request, func(*struct{}) (*struct{}, int, error) {
+				return &struct{}{}, http.StatusOK, nil
+			}, sessionCookie)
+		}, "the cookie option is for responses of type")
+	})
+}
@@ -3,72 +3,227 @@ package responders
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"reflect"
+	"sync/atomic"
 
 	httperrors "github.com/TriangleSide/GoBase/pkg/http/errors"
 	"github.com/TriangleSide/GoBase/pkg/http/headers"
 	"github.com/TriangleSide/GoBase/pkg/logger"
+	"github.com/TriangleSide/GoBase/pkg/utils/ctxkey"
+	"github.com/TriangleSide/GoBase/pkg/validation"
 )
 
-// registeredErrorTypeResponse is used by the Error responder to format the response.
-type registeredErrorResponse struct {
-	Status          int
-	MessageCallback func(err any) string
-}
-
 var (
-	// registeredErrorTypes holds error types and how to format their responses.
-	registeredErrorResponses = make(map[reflect.Type]registeredErrorResponse)
+	// handledErrorKey is the context key of the HandledError recorder.
+	handledErrorKey = ctxkey.New[*HandledError]("handledError")
+
+	// echoFieldValues is true if the values of the fields that failed validation are listed in the error responses.
+	echoFieldValues atomic.Bool
 )
 
+// SetEchoFieldValues sets whether the error responses list the values of the fields that failed validation.
+// They are not listed by default because the fields can be headers or cookies that hold credentials, which must
+// not be echoed back to the client, or written to the logs and caches that record the responses.
+func SetEchoFieldValues(echo bool) {
+	echoFieldValues.Store(echo)
+}
+
+// HandledError holds the error that was handled by the Error responder for a request.
+type HandledError struct {
+	Err error
+}
+
+// WithHandledErrorRecorder returns a copy of the request with a HandledError recorder in its context.
+// When a responder handles an error for the request, the error is stored in the recorder.
+// This allows middleware to inspect the error after the next handler returns.
+//
+//	middleware := func(next http.HandlerFunc) http.HandlerFunc {
+//	    return func(writer http.ResponseWriter, request *http.Request) {
+//	        request, handledErr := responders.WithHandledErrorRecorder(request)
+//	        next(writer, request)
+//	        if handledErr.Err != nil {
+//	            // Record the error here.
+//	        }
+//	    }
+//	}
+func WithHandledErrorRecorder(request *http.Request) (*http.Request, *HandledError) {
+	recorder := &HandledError{}
+	return request.WithContext(handledErrorKey.WithValue(request.Context(), recorder)), recorder
+}
+
 // MustRegisterErrorResponse allows error types to be registered for the Error responder.
 // The registered error type should always be instantiated as a pointer for this to work correctly.
+// Only errors whose type is exactly the pointer type match, and they take precedence over errors.MustRegisterMapping.
 func MustRegisterErrorResponse[T error](status int, callback func(err *T) string) {
 	typeOfError := reflect.TypeOf((*T)(nil))
 	if typeOfError.Elem().Kind() == reflect.Pointer {
 		panic("The generic for registered error types cannot be a pointer.")
 	}
-	convertedCallback := func(err any) string {
-		return callback(err.(*T))
-	}
-	errorResponse := registeredErrorResponse{
-		Status:          status,
-		MessageCallback: convertedCallback,
-	}
-	if _, found := registeredErrorResponses[typeOfError]; found {
-		panic("The error type has already been registered.")
-	}
-	registeredErrorResponses[typeOfError] = errorResponse
+	httperrors.MustRegisterTypeMapping(typeOfError, status, func(err error) string {
+		return callback(any(err).(*T))
+	})
 }
 
 // Error responds to an HTTP requests with an errors.Error. It tries to match it to a known error type
-// so it can return its corresponding status and message. It defaults to HTTP 500 internal server error.
+// so it can return its corresponding status and message. The error types registered with MustRegisterErrorResponse
+// are tried first, then the mappings registered with errors.MustRegisterMapping, then the types of the errors package.
+// It defaults to HTTP 500 internal server error.
+// If the request has a recorder from WithHandledErrorRecorder, the error is stored in it.
 func Error(request *http.Request, writer http.ResponseWriter, err error) {
+	recordHandledError(request, err)
+
 	statusCode := http.StatusInternalServerError
-	errResponse := httperrors.Error{
-		Message: http.StatusText(http.StatusInternalServerError),
-	}
+	message := http.StatusText(http.StatusInternalServerError)
+	var decidingErr error
 
 	if err != nil {
-		errType := reflect.TypeOf(err)
-		if registeredError, registeredErrorFound := registeredErrorResponses[errType]; registeredErrorFound {
-			statusCode = registeredError.Status
-			errResponse.Message = registeredError.MessageCallback(err)
-		} else {
-			var badRequestError *httperrors.BadRequest
-			switch {
-			case errors.As(err, &badRequestError):
-				statusCode = http.StatusBadRequest
-				errResponse.Message = badRequestError.Error()
+		if mappedStatus, mappedMessage, mappedErr := httperrors.MapError(err); mappedErr != nil {
+			statusCode = mappedStatus
+			message = mappedMessage
+			decidingErr = mappedErr
+		} else if packageStatus, packageErr := packageErrorResponse(err); packageErr != nil {
+			statusCode = packageStatus
+			message = packageErr.Error()
+			decidingErr = packageErr
+		}
+	}
+
+	writeError(request, writer, statusCode, message, decidingErr, err)
+}
+
+// packageErrorResponse returns the status of the first error of the errors package in the tree of the error, along
+// with that error. The tree is walked in the same order as errors.As, so the outermost error wins when they are
+// nested, like a NotFound that wraps a BadRequest. It returns a nil error if the tree has no error of the package.
+func packageErrorResponse(err error) (int, error) {
+	for err != nil {
+		if status, found := packageErrorStatus(err); found {
+			return status, err
+		}
+		switch wrapper := err.(type) {
+		case interface{ Unwrap() error }:
+			err = wrapper.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, wrapped := range wrapper.Unwrap() {
+				if status, packageErr := packageErrorResponse(wrapped); packageErr != nil {
+					return status, packageErr
+				}
 			}
+			return 0, nil
+		default:
+			return 0, nil
 		}
 	}
+	return 0, nil
+}
+
+// packageErrorStatus returns the status of the error if it is one of the types of the errors package.
+func packageErrorStatus(err error) (int, bool) {
+	switch err.(type) {
+	case *httperrors.BadRequest:
+		return http.StatusBadRequest, true
+	case *httperrors.ServiceUnavailable:
+		return http.StatusServiceUnavailable, true
+	case *httperrors.URITooLong:
+		return http.StatusRequestURITooLong, true
+	case *httperrors.UnsupportedMediaType:
+		return http.StatusUnsupportedMediaType, true
+	case *httperrors.Forbidden:
+		return http.StatusForbidden, true
+	case *httperrors.TooManyRequests:
+		return http.StatusTooManyRequests, true
+	case *httperrors.RequestEntityTooLarge:
+		return http.StatusRequestEntityTooLarge, true
+	case *httperrors.RequestTimeout:
+		return http.StatusRequestTimeout, true
+	case *httperrors.Unauthorized:
+		return http.StatusUnauthorized, true
+	case *httperrors.BadGateway:
+		return http.StatusBadGateway, true
+	case *httperrors.GatewayTimeout:
+		return http.StatusGatewayTimeout, true
+	case *httperrors.NotAcceptable:
+		return http.StatusNotAcceptable, true
+	case *httperrors.NotFound:
+		return http.StatusNotFound, true
+	case *httperrors.Conflict:
+		return http.StatusConflict, true
+	case *httperrors.UnprocessableEntity:
+		return http.StatusUnprocessableEntity, true
+	default:
+		return 0, false
+	}
+}
+
+// recordHandledError stores the error in the HandledError recorder of the request if it has one.
+func recordHandledError(request *http.Request, err error) {
+	if recorder, hasRecorder := handledErrorKey.Value(request.Context()); hasRecorder {
+		recorder.Err = err
+	}
+}
 
+// writeError writes an errors.Error response with the status and message. The deciding error is the error of the
+// tree of the cause that decided the status. The code and retryable hint are taken from it if it implements
+// errors.Coder and errors.Retryable, so they agree with the status, otherwise they are derived from the status.
+// If no error decided the status, they are taken from the first error of the cause that implements them.
+// If the cause wraps a validation.Error, the fields that failed validation are listed in the response.
+func writeError(request *http.Request, writer http.ResponseWriter, statusCode int, message string, decidingErr error, cause error) {
 	writer.Header().Set(headers.ContentType, headers.ContentTypeApplicationJson)
 	writer.WriteHeader(statusCode)
 
-	if err := json.NewEncoder(writer).Encode(errResponse); err != nil {
+	code, retryable := errorCodeAndRetryable(statusCode, decidingErr, cause)
+	response := httperrors.Error{
+		Message:   message,
+		Code:      code,
+		Retryable: retryable,
+		Fields:    validationFieldErrors(cause),
+	}
+	if err := json.NewEncoder(writer).Encode(response); err != nil {
 		logger.Errorf(request.Context(), "Error encoding error response (%s).", err)
 	}
 }
+
+// errorCodeAndRetryable returns the code and retryable hint of an error response. See writeError.
+func errorCodeAndRetryable(statusCode int, decidingErr error, cause error) (string, bool) {
+	if decidingErr == nil {
+		return httperrors.CodeOf(cause, statusCode), httperrors.IsRetryable(cause, statusCode)
+	}
+	code := httperrors.CodeForStatus(statusCode)
+	if coder, isCoder := decidingErr.(httperrors.Coder); isCoder {
+		code = coder.Code()
+	}
+	retryable := httperrors.IsRetryableStatus(statusCode)
+	if retryableErr, isRetryable := decidingErr.(httperrors.Retryable); isRetryable {
+		retryable = retryableErr.IsRetryable()
+	}
+	return code, retryable
+}
+
+// validationFieldErrors converts the field errors of a validation.Error to the field errors of an errors.Error.
+// The values are omitted unless SetEchoFieldValues enabled them, and values that cannot be encoded to JSON are
+// formatted as strings. It returns nil if the error is not a validation.Error.
+func validationFieldErrors(err error) []httperrors.FieldError {
+	var validationErr *validation.Error
+	if !errors.As(err, &validationErr) || len(validationErr.Fields()) == 0 {
+		return nil
+	}
+	echoValues := echoFieldValues.Load()
+	fieldErrors := make([]httperrors.FieldError, 0, len(validationErr.Fields()))
+	for _, fieldError := range validationErr.Fields() {
+		var value any
+		if echoValues {
+			value = fieldError.Value
+			if _, marshalErr := json.Marshal(value); marshalErr != nil {
+				value = fmt.Sprintf("%v", value)
+			}
+		}
+		fieldErrors = append(fieldErrors, httperrors.FieldError{
+			Field:   fieldError.Field,
+			Tag:     fieldError.Tag,
+			Value:   value,
+			Message: fieldError.Message,
+		})
+	}
+	return fieldErrors
+}
@@ -3,13 +3,18 @@ package responders_test
 import (
 	"encoding/json"
 	goerrors "errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/http/middleware"
 	"github.com/TriangleSide/GoBase/pkg/http/responders"
 	"github.com/TriangleSide/GoBase/pkg/test/assert"
+	"github.com/TriangleSide/GoBase/pkg/validation"
 )
 
 type testError struct{}
@@ -18,6 +23,39 @@ func (e testError) Error() string {
 	return "test error"
 }
 
+type testMappedError struct {
+	secret string
+	err    error
+}
+
+func (e *testMappedError) Error() string {
+	return e.secret
+}
+
+func (e *testMappedError) Unwrap() error {
+	return e.err
+}
+
+type testCodedError struct{}
+
+func (e *testCodedError) Error() string {
+	return "coded error"
+}
+
+func (e *testCodedError) Code() string {
+	return "quota_exhausted"
+}
+
+func (e *testCodedError) IsRetryable() bool {
+	return true
+}
+
+func init() {
+	errors.MustRegisterMapping(http.StatusNotFound, func(*testMappedError) string {
+		return "the record does not exist"
+	})
+}
+
 type failingWriter struct {
 	WriteFailed bool
 	http.ResponseWriter
@@ -82,6 +120,240 @@ func TestErrorResponder(t *testing.T) {
 		assert.Equals(t, httpError.Message, badRequestErr.Error())
 	})
 
+	t.Run("when the error is service unavailable it should return the correct status and message", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		unavailableErr := &errors.ServiceUnavailable{
+			Err: goerrors.New("unavailable"),
+		}
+		responders.Error(&http.Request{}, recorder, unavailableErr)
+		assert.Equals(t, recorder.Code, http.StatusServiceUnavailable)
+		httpError := mustDeserializeError(t, recorder)
+		assert.Equals(t, httpError.Message, "unavailable")
+		assert.Equals(t, httpError.Code, errors.CodeServiceUnavailable)
+		assert.True(t, httpError.Retryable)
+	})
+
+	t.Run("when the error is a URITooLong error it should return a URI too long status", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		responders.Error(&http.Request{}, recorder, &errors.URITooLong{Err: goerrors.New("too long")})
+		assert.Equals(t, recorder.Code, http.StatusRequestURITooLong)
+		httpError := mustDeserializeError(t, recorder)
+		assert.Equals(t, httpError.Message, "too long")
+	})
+
+	t.Run("when the error is an UnsupportedMediaType error it should return an unsupported media type status", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		responders.Error(&http.Request{}, recorder, &errors.UnsupportedMediaType{Err: goerrors.New("unsupported")})
+		assert.Equals(t, recorder.Code, http.StatusUnsupportedMediaType)
+		httpError := mustDeserializeError(t, recorder)
+		assert.Equals(t, httpError.Message, "unsupported")
+	})
+
+	t.Run("when the error is a Forbidden error it should return a forbidden status", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		responders.Error(&http.Request{}, recorder, &errors.Forbidden{Err: goerrors.New("forbidden")})
+		assert.Equals(t, recorder.Code, http.StatusForbidden)
+		httpError := mustDeserializeError(t, recorder)
+		assert.Equals(t, httpError.Message, "forbidden")
+	})
+
+	t.Run("when the error is a TooManyRequests error it should return a too many requests status", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		responders.Error(&http.Request{}, recorder, &errors.TooManyRequests{Err: goerrors.New("slow down")})
+		assert.Equals(t, recorder.Code, http.StatusTooManyRequests)
+		httpError := mustDeserializeError(t, recorder)
+		assert.Equals(t, httpError.Message, "slow down")
+	})
+
+	t.Run("when the error is a RequestEntityTooLarge error it should return a request entity too large status", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		responders.Error(&http.Request{}, recorder, &errors.RequestEntityTooLarge{Err: goerrors.New("too large")})
+		assert.Equals(t, recorder.Code, http.StatusRequestEntityTooLarge)
+		httpError := mustDeserializeError(t, recorder)
+		assert.Equals(t, httpError.Message, "too large")
+	})
+
+	t.Run("when the error is a RequestTimeout error it should return a request timeout status", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		responders.Error(&http.Request{}, recorder, &errors.RequestTimeout{Err: goerrors.New("too slow")})
+		assert.Equals(t, recorder.Code, http.StatusRequestTimeout)
+		httpError := mustDeserializeError(t, recorder)
+		assert.Equals(t, httpError.Message, "too slow")
+	})
+
+	t.Run("when the error is an Unauthorized error it should return an unauthorized status", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		responders.Error(&http.Request{}, recorder, &errors.Unauthorized{Err: goerrors.New("who are you")})
+		assert.Equals(t, recorder.Code, http.StatusUnauthorized)
+		httpError := mustDeserializeError(t, recorder)
+		assert.Equals(t, httpError.Message, "who are you")
+	})
+
+	t.Run("when the error is a BadGateway error it should return a bad gateway status", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		responders.Error(&http.Request{}, recorder, &errors.BadGateway{Err: goerrors.New("upstream down")})
+		assert.Equals(t, recorder.Code, http.StatusBadGateway)
+		httpError := mustDeserializeError(t, recorder)
+		assert.Equals(t, httpError.Message, "upstream down")
+	})
+
+	t.Run("when the error is a GatewayTimeout error it should return a gateway timeout status", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		responders.Error(&http.Request{}, recorder, &errors.GatewayTimeout{Err: goerrors.New("upstream slow")})
+		assert.Equals(t, recorder.Code, http.StatusGatewayTimeout)
+		httpError := mustDeserializeError(t, recorder)
+		assert.Equals(t, httpError.Message, "upstream slow")
+	})
+
+	t.Run("when the error is a NotAcceptable error it should return a not acceptable status", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		responders.Error(&http.Request{}, recorder, &errors.NotAcceptable{Err: goerrors.New("no acceptable format")})
+		assert.Equals(t, recorder.Code, http.StatusNotAcceptable)
+		httpError := mustDeserializeError(t, recorder)
+		assert.Equals(t, httpError.Message, "no acceptable format")
+	})
+
+	t.Run("when the error is a NotFound error it should return a not found status", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		responders.Error(&http.Request{}, recorder, &errors.NotFound{Err: goerrors.New("no such user")})
+		assert.Equals(t, recorder.Code, http.StatusNotFound)
+		assert.Equals(t, mustDeserializeError(t, recorder).Message, "no such user")
+	})
+
+	t.Run("when the error is a Conflict error it should return a conflict status", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		responders.Error(&http.Request{}, recorder, &errors.Conflict{Err: goerrors.New("already exists")})
+		assert.Equals(t, recorder.Code, http.StatusConflict)
+		assert.Equals(t, mustDeserializeError(t, recorder).Message, "already exists")
+	})
+
+	t.Run("when the error is an UnprocessableEntity error it should return an unprocessable entity status", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		responders.Error(&http.Request{}, recorder, &errors.UnprocessableEntity{Err: goerrors.New("cannot process")})
+		assert.Equals(t, recorder.Code, http.StatusUnprocessableEntity)
+		assert.Equals(t, mustDeserializeError(t, recorder).Message, "cannot process")
+	})
+
+	t.Run("when errors of the errors package are nested it should respond with the outermost one", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		nested := fmt.Errorf("lookup failed (%w)", &errors.NotFound{Err: &errors.BadRequest{Err: goerrors.New("bad id")}})
+		responders.Error(&http.Request{}, recorder, nested)
+		assert.Equals(t, recorder.Code, http.StatusNotFound)
+		httpError := mustDeserializeError(t, recorder)
+		assert.Equals(t, httpError.Message, "bad id")
+		assert.Equals(t, httpError.Code, errors.CodeNotFound)
+	})
+
+	t.Run("when errors of the errors package are joined it should respond with the first one", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		joined := goerrors.Join(goerrors.New("unrelated"), &errors.Conflict{Err: goerrors.New("taken")}, &errors.Forbidden{Err: goerrors.New("denied")})
+		responders.Error(&http.Request{}, recorder, joined)
+		assert.Equals(t, recorder.Code, http.StatusConflict)
+		assert.Equals(t, mustDeserializeError(t, recorder).Message, "taken")
+	})
+
+	t.Run("when the error wraps a validation error it should list the fields that failed validation without their values", func(t *testing.T) {
+		t.Parallel()
+		validationErr := validation.Struct(&struct {
+			Name string `validate:"required"`
+			Age  int    `validate:"gte=0"`
+		}{Age: -1})
+		assert.Error(t, validationErr)
+		recorder := httptest.NewRecorder()
+		responders.Error(&http.Request{}, recorder, &errors.BadRequest{Err: validationErr})
+		assert.Equals(t, recorder.Code, http.StatusBadRequest)
+		httpError := mustDeserializeError(t, recorder)
+		assert.Equals(t, httpError.Message, validationErr.Error())
+		assert.Equals(t, httpError.Fields, []errors.FieldError{
+			{Field: "Name", Tag: "required", Message: "validation failed on field 'Name' with validator 'required'"},
+			{Field: "Age", Tag: "gte", Message: "validation failed on field 'Age' with validator 'gte' and parameter(s) '0'"},
+		})
+	})
+
+	t.Run("when a header fails validation it should not return its value", func(t *testing.T) {
+		t.Parallel()
+		type headerParams struct {
+			Token string `httpHeader:"X-Token" json:"-" validate:"len=5"`
+		}
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set("X-Token", "secret-credential")
+		responders.JSON(recorder, request, func(*headerParams) (*struct{}, int, error) {
+			return &struct{}{}, http.StatusOK, nil
+		})
+		assert.Equals(t, recorder.Code, http.StatusBadRequest)
+		assert.False(t, strings.Contains(recorder.Body.String(), "secret-credential"))
+		httpError := mustDeserializeError(t, recorder)
+		assert.Equals(t, len(httpError.Fields), 1)
+		assert.Equals(t, httpError.Fields[0].Tag, "len")
+		assert.Nil(t, httpError.Fields[0].Value)
+	})
+
+	t.Run("when the error is not a validation error it should not list any fields", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		responders.Error(&http.Request{}, recorder, &errors.BadRequest{Err: goerrors.New("bad input")})
+		assert.Equals(t, len(mustDeserializeError(t, recorder).Fields), 0)
+	})
+
+	t.Run("when the error matches a registered mapping it should return the mapped status and message", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		wrapped := fmt.Errorf("lookup failed (%w)", &testMappedError{secret: "row 12 missing"})
+		responders.Error(&http.Request{}, recorder, wrapped)
+		assert.Equals(t, recorder.Code, http.StatusNotFound)
+		httpError := mustDeserializeError(t, recorder)
+		assert.Equals(t, httpError.Message, "the record does not exist")
+		assert.Equals(t, httpError.Code, errors.CodeNotFound)
+		assert.False(t, httpError.Retryable)
+	})
+
+	t.Run("when the error joins an error with a code and a package error it should respond with the code of the package error", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		responders.Error(&http.Request{}, recorder, goerrors.Join(&testCodedError{}, &errors.NotFound{Err: &testCodedError{}}))
+		assert.Equals(t, recorder.Code, http.StatusNotFound)
+		httpError := mustDeserializeError(t, recorder)
+		assert.Equals(t, httpError.Code, errors.CodeNotFound)
+		assert.False(t, httpError.Retryable)
+	})
+
+	t.Run("when a mapped error wraps an error with a code it should respond with the code of the mapped status", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		responders.Error(&http.Request{}, recorder, &testMappedError{secret: "row 12 missing", err: &testCodedError{}})
+		assert.Equals(t, recorder.Code, http.StatusNotFound)
+		httpError := mustDeserializeError(t, recorder)
+		assert.Equals(t, httpError.Code, errors.CodeNotFound)
+		assert.False(t, httpError.Retryable)
+	})
+
+	t.Run("when no error decides the status it should respond with the code of the error", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		responders.Error(&http.Request{}, recorder, fmt.Errorf("outer (%w)", &testCodedError{}))
+		assert.Equals(t, recorder.Code, http.StatusInternalServerError)
+		httpError := mustDeserializeError(t, recorder)
+		assert.Equals(t, httpError.Code, "quota_exhausted")
+		assert.True(t, httpError.Retryable)
+	})
+
 	t.Run("when the error is nil it should return internal server error", func(t *testing.T) {
 		t.Parallel()
 		recorder := httptest.NewRecorder()
@@ -100,6 +372,13 @@ func TestErrorResponder(t *testing.T) {
 		assert.Equals(t, httpError.Message, "custom message")
 	})
 
+	t.Run("when the error wraps a custom registered type it should not match it", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		responders.Error(&http.Request{}, recorder, fmt.Errorf("wrapped (%w)", &testError{}))
+		assert.Equals(t, recorder.Code, http.StatusInternalServerError)
+	})
+
 	t.Run("when the JSON encoding fails it should not write a response", func(t *testing.T) {
 		t.Parallel()
 		recorder := httptest.NewRecorder()
@@ -110,4 +389,68 @@ func TestErrorResponder(t *testing.T) {
 		responders.Error(&http.Request{}, fw, goerrors.New("some error"))
 		assert.True(t, fw.WriteFailed)
 	})
+	t.Run("when a middleware records the handled error it should be able to read the error type after the responder", func(t *testing.T) {
+		t.Parallel()
+		var handledErrType string
+		recordingMiddleware := func(next http.HandlerFunc) http.HandlerFunc {
+			return func(writer http.ResponseWriter, request *http.Request) {
+				request, handledErr := responders.WithHandledErrorRecorder(request)
+				next(writer, request)
+				handledErrType = reflect.TypeOf(handledErr.Err).String()
+			}
+		}
+		handler := middleware.CreateChain([]middleware.Middleware{recordingMiddleware}, func(writer http.ResponseWriter, request *http.Request) {
+			responders.JSON(writer, request, func(*struct{}) (*struct{}, int, error) {
+				return nil, 0, &testError{}
+			})
+		})
+		recorder := httptest.NewRecorder()
+		handler(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equals(t, recorder.Code, http.StatusFound)
+		assert.Equals(t, handledErrType, "*responders_test.testError")
+	})
+
+	t.Run("when a responder does not handle an error the recorder should not have an error", func(t *testing.T) {
+		t.Parallel()
+		request, handledErr := responders.WithHandledErrorRecorder(httptest.NewRequest(http.MethodGet, "/", nil))
+		responders.Status(httptest.NewRecorder(), request, func(*struct{}) (int, error) {
+			return http.StatusOK, nil
+		})
+		assert.Nil(t, handledErr.Err)
+	})
+}
+
+func TestErrorResponderEchoFieldValues(t *testing.T) {
+	responders.SetEchoFieldValues(true)
+	t.Cleanup(func() {
+		responders.SetEchoFieldValues(false)
+	})
+
+	validationErr := validation.Struct(&struct {
+		Name    string        `validate:"required"`
+		Age     int           `validate:"gte=0"`
+		Channel chan struct{} `validate:"isdefault"`
+	}{Age: -1, Channel: make(chan struct{})})
+	assert.Error(t, validationErr)
+	recorder := httptest.NewRecorder()
+	responders.Error(&http.Request{}, recorder, &errors.BadRequest{Err: validationErr})
+	assert.Equals(t, recorder.Code, http.StatusBadRequest)
+	httpError := mustDeserializeError(t, recorder)
+	assert.Equals(t, len(httpError.Fields), 3)
+	assert.Equals(t, httpError.Fields[0], errors.FieldError{
+		Field:   "Name",
+		Tag:     "required",
+		Value:   "",
+		Message: "validation failed on field 'Name' with validator 'required'",
+	})
+	assert.Equals(t, httpError.Fields[1], errors.FieldError{
+		Field:   "Age",
+		Tag:     "gte",
+		Value:   float64(-1),
+		Message: "validation failed on field 'Age' with validator 'gte' and parameter(s) '0'",
+	})
+	assert.Equals(t, httpError.Fields[2].Field, "Channel")
+	assert.Equals(t, httpError.Fields[2].Tag, "isdefault")
+	_, isString := httpError.Fields[2].Value.(string)
+	assert.True(t, isString)
 }
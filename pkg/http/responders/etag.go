@@ -0,0 +1,49 @@
+package responders
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+)
+
+// etagKind is the kind of ETag that the JSON responder sends.
+type etagKind int
+
+const (
+	// etagNone disables ETags.
+	etagNone etagKind = iota
+
+	// etagStrong is an ETag that changes whenever the bytes of the body change.
+	etagStrong
+
+	// etagWeak is an ETag that is prefixed with W/ to indicate semantic equivalence.
+	etagWeak
+)
+
+// computeETag returns the quoted ETag of the body, which is the hex encoded prefix of its SHA-256 digest.
+func computeETag(body []byte, kind etagKind) string {
+	digest := sha256.Sum256(body)
+	tag := `"` + hex.EncodeToString(digest[:16]) + `"`
+	if kind == etagWeak {
+		tag = "W/" + tag
+	}
+	return tag
+}
+
+// etagMatches returns true if the If-None-Match header of the request lists the ETag or is a wildcard.
+// As required for If-None-Match, the comparison is weak, so the W/ prefix is ignored.
+func etagMatches(request *http.Request, tag string) bool {
+	tag = strings.TrimPrefix(tag, "W/")
+	for _, value := range request.Header.Values(headers.IfNoneMatch) {
+		for candidate := range strings.SplitSeq(value, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == tag {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package responders_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/responders"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestETag(t *testing.T) {
+	t.Parallel()
+
+	type requestParams struct{}
+
+	type responseBody struct {
+		Message string `json:"message"`
+	}
+
+	serve := func(method string, requestHeaders map[string]string, message string, status int, options ...responders.Option) (*httptest.ResponseRecorder, bool) {
+		request := httptest.NewRequest(method, "/", nil)
+		for name, value := range requestHeaders {
+			request.Header.Set(name, value)
+		}
+		recorder := httptest.NewRecorder()
+		callbackCalled := false
+		responders.JSON[requestParams, responseBody](recorder, request, func(*requestParams) (*responseBody, int, error) {
+			callbackCalled = true
+			return &responseBody{Message: message}, status, nil
+		}, options...)
+		return recorder, callbackCalled
+	}
+
+	t.Run("when the option is set it should respond with a strong ETag of the body", func(t *testing.T) {
+		t.Parallel()
+		recorder, _ := serve(http.MethodGet, nil, "resource", http.StatusOK, responders.WithETag())
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.Equals(t, recorder.Body.String(), "{\"message\":\"resource\"}\n")
+		assert.Equals(t, recorder.Header().Get(headers.ContentType), headers.ContentTypeApplicationJson)
+		assert.Equals(t, recorder.Header().Get(headers.ContentLength), "23")
+		tag := recorder.Header().Get(headers.ETag)
+		assert.True(t, strings.HasPrefix(tag, `"`) && strings.HasSuffix(tag, `"`))
+		assert.Equals(t, len(tag), 34)
+
+		sameRecorder, _ := serve(http.MethodGet, nil, "resource", http.StatusOK, responders.WithETag())
+		assert.Equals(t, sameRecorder.Header().Get(headers.ETag), tag)
+		otherRecorder, _ := serve(http.MethodGet, nil, "changed", http.StatusOK, responders.WithETag())
+		assert.NotEquals(t, otherRecorder.Header().Get(headers.ETag), tag)
+	})
+
+	t.Run("when the weak option is set it should respond with a weak ETag", func(t *testing.T) {
+		t.Parallel()
+		recorder, _ := serve(http.MethodGet, nil, "resource", http.StatusOK, responders.WithWeakETag())
+		assert.True(t, strings.HasPrefix(recorder.Header().Get(headers.ETag), `W/"`))
+	})
+
+	t.Run("when the If-None-Match header lists the ETag it should respond with not modified", func(t *testing.T) {
+		t.Parallel()
+		tag := func() string {
+			recorder, _ := serve(http.MethodGet, nil, "resource", http.StatusOK, responders.WithETag())
+			return recorder.Header().Get(headers.ETag)
+		}()
+		for _, ifNoneMatch := range []string{tag, `"other", ` + tag, "W/" + tag, "*"} {
+			for _, method := range []string{http.MethodGet, http.MethodHead} {
+				recorder, callbackCalled := serve(method, map[string]string{headers.IfNoneMatch: ifNoneMatch}, "resource", http.StatusOK, responders.WithETag())
+				assert.True(t, callbackCalled)
+				assert.Equals(t, recorder.Code, http.StatusNotModified)
+				assert.Equals(t, recorder.Header().Get(headers.ETag), tag)
+				assert.Equals(t, recorder.Body.Len(), 0)
+			}
+		}
+
+		recorder, _ := serve(http.MethodGet, map[string]string{headers.IfNoneMatch: tag}, "resource", http.StatusOK, responders.WithWeakETag())
+		assert.Equals(t, recorder.Code, http.StatusNotModified)
+	})
+
+	t.Run("when the If-None-Match header does not list the ETag it should respond with the body", func(t *testing.T) {
+		t.Parallel()
+		recorder, _ := serve(http.MethodGet, map[string]string{headers.IfNoneMatch: `"other"`}, "resource", http.StatusOK, responders.WithETag())
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.Equals(t, recorder.Body.String(), "{\"message\":\"resource\"}\n")
+	})
+
+	t.Run("when the response is not an HTTP 200 OK to a GET or HEAD request it should not have an ETag", func(t *testing.T) {
+		t.Parallel()
+		recorder, _ := serve(http.MethodGet, map[string]string{headers.IfNoneMatch: "*"}, "resource", http.StatusAccepted, responders.WithETag())
+		assert.Equals(t, recorder.Code, http.StatusAccepted)
+		assert.Equals(t, recorder.Header().Get(headers.ETag), "")
+
+		recorder, _ = serve(http.MethodPost, map[string]string{headers.IfNoneMatch: "*"}, "resource", http.StatusOK, responders.WithETag())
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.Equals(t, recorder.Header().Get(headers.ETag), "")
+	})
+
+	t.Run("when the option is not set it should not have an ETag", func(t *testing.T) {
+		t.Parallel()
+		recorder, _ := serve(http.MethodGet, map[string]string{headers.IfNoneMatch: "*"}, "resource", http.StatusOK)
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.Equals(t, recorder.Header().Get(headers.ETag), "")
+	})
+
+	t.Run("when used with the last modified option the If-None-Match header should take precedence", func(t *testing.T) {
+		t.Parallel()
+		modifiedAt := time.Date(2024, 5, 1, 8, 30, 15, 0, time.UTC)
+		lastModified := responders.WithLastModified(func(*http.Request) (time.Time, error) {
+			return modifiedAt, nil
+		})
+		notModifiedSince := modifiedAt.Format(http.TimeFormat)
+
+		recorder, callbackCalled := serve(http.MethodGet, map[string]string{
+			headers.IfModifiedSince: notModifiedSince,
+			headers.IfNoneMatch:     `"other"`,
+		}, "resource", http.StatusOK, responders.WithETag(), lastModified)
+		assert.True(t, callbackCalled)
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.Equals(t, recorder.Header().Get(headers.LastModified), notModifiedSince)
+		assert.NotEquals(t, recorder.Header().Get(headers.ETag), "")
+
+		recorder, callbackCalled = serve(http.MethodGet, map[string]string{
+			headers.IfModifiedSince: notModifiedSince,
+		}, "resource", http.StatusOK, responders.WithETag(), lastModified)
+		assert.False(t, callbackCalled)
+		assert.Equals(t, recorder.Code, http.StatusNotModified)
+	})
+}
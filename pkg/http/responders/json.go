@@ -1,20 +1,32 @@
 package responders
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 
-	"github.com/TriangleSide/GoBase/pkg/http/errors"
 	"github.com/TriangleSide/GoBase/pkg/http/headers"
-	"github.com/TriangleSide/GoBase/pkg/http/parameters"
 	"github.com/TriangleSide/GoBase/pkg/logger"
 )
 
 // JSON responds to an HTTP request by encoding the response as JSON.
-func JSON[RequestParameters any, ResponseBody any](writer http.ResponseWriter, request *http.Request, callback func(*RequestParameters) (*ResponseBody, int, error)) {
-	requestParams, err := parameters.Decode[RequestParameters](request)
+func JSON[RequestParameters any, ResponseBody any](writer http.ResponseWriter, request *http.Request, callback func(*RequestParameters) (*ResponseBody, int, error), options ...Option) {
+	cfg := newConfig(options...)
+
+	requestParams, ok := decodeParameters[RequestParameters](writer, request, cfg)
+	if !ok {
+		return
+	}
+
+	lastModified, notModified, err := checkLastModified(writer, request, cfg)
 	if err != nil {
-		Error(request, writer, &errors.BadRequest{Err: err})
+		Error(request, writer, err)
+		return
+	}
+	if notModified {
 		return
 	}
 
@@ -24,11 +36,67 @@ func JSON[RequestParameters any, ResponseBody any](writer http.ResponseWriter, r
 		return
 	}
 
+	setCookies(writer, response, cfg)
+
+	if !lastModified.IsZero() {
+		writer.Header().Set(headers.LastModified, lastModified.Format(http.TimeFormat))
+	}
+
+	if cfg.etag != etagNone && status == http.StatusOK && (request.Method == http.MethodGet || request.Method == http.MethodHead) {
+		writeWithETag(writer, request, response, cfg)
+		return
+	}
+
 	writer.Header().Set(headers.ContentType, headers.ContentTypeApplicationJson)
 	writer.WriteHeader(status)
 
-	if err := json.NewEncoder(writer).Encode(response); err != nil {
+	if err := encodeJSON(writer, response, cfg.sortedKeys); err != nil {
 		logger.Errorf(request.Context(), "Failed to encode response (%s).", err)
 		return
 	}
 }
+
+// writeWithETag encodes the response in memory to compute its ETag. If the If-None-Match header of the request
+// matches the ETag, an HTTP 304 not modified is written without a body, otherwise the body is written.
+func writeWithETag(writer http.ResponseWriter, request *http.Request, response any, cfg *config) {
+	var body bytes.Buffer
+	if err := encodeJSON(&body, response, cfg.sortedKeys); err != nil {
+		Error(request, writer, fmt.Errorf("failed to encode the response (%w)", err))
+		return
+	}
+
+	tag := computeETag(body.Bytes(), cfg.etag)
+	writer.Header().Set(headers.ETag, tag)
+	if etagMatches(request, tag) {
+		writer.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	writer.Header().Set(headers.ContentType, headers.ContentTypeApplicationJson)
+	writer.Header().Set(headers.ContentLength, strconv.Itoa(body.Len()))
+	writer.WriteHeader(http.StatusOK)
+	if _, err := writer.Write(body.Bytes()); err != nil {
+		logger.Errorf(request.Context(), "Failed to write response (%s).", err)
+	}
+}
+
+// encodeJSON writes the value as JSON followed by a newline.
+// If sortedKeys is true, the keys of every object are written in sorted order.
+func encodeJSON(writer io.Writer, value any, sortedKeys bool) error {
+	if !sortedKeys {
+		return json.NewEncoder(writer).Encode(value)
+	}
+	marshaled, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	// Decoding into generic maps and re-encoding them sorts the keys, since the encoder sorts map keys.
+	// Numbers are kept as json.Number so their exact representation is preserved.
+	decoder := json.NewDecoder(bytes.NewReader(marshaled))
+	decoder.UseNumber()
+	var generic any
+	if err := decoder.Decode(&generic); err != nil {
+		return err
+	}
+	return json.NewEncoder(writer).Encode(generic)
+}
@@ -1,33 +1,41 @@
 package responders
 
 import (
-	"encoding/json"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
 	"net/http"
 	"time"
 
 	"github.com/TriangleSide/GoBase/pkg/http/errors"
 	"github.com/TriangleSide/GoBase/pkg/http/headers"
-	"github.com/TriangleSide/GoBase/pkg/http/parameters"
 	"github.com/TriangleSide/GoBase/pkg/logger"
 )
 
-// jsonStreamConfig is used to configure the JSON stream utility.
-type jsonStreamConfig struct {
-	deferredConsumerTimerDuration time.Duration
-}
+// StreamFormat is the format of the body of the JSONStream responder.
+type StreamFormat int
 
-// JSONStreamOption is used to set values on the stream configuration.
-type JSONStreamOption func(config *jsonStreamConfig)
+const (
+	// StreamFormatJSON writes each response as a JSON value followed by a newline, with the
+	// application/json content type. It is the default format.
+	StreamFormatJSON StreamFormat = iota
 
-// WithDeferredConsumerTimerDuration configures how long to wait before printing
-// an error log on the deferred consumer.
-func WithDeferredConsumerTimerDuration(duration time.Duration) JSONStreamOption {
-	return func(config *jsonStreamConfig) {
-		config.deferredConsumerTimerDuration = duration
-	}
-}
+	// StreamFormatNDJSON writes each response as a JSON value followed by a newline, with the
+	// application/x-ndjson content type.
+	StreamFormatNDJSON
+
+	// StreamFormatJSONArray writes the responses as the elements of a JSON array, with the application/json
+	// content type. The array is only terminated once the producer closes the channel, so a body that is
+	// interrupted is not a valid JSON document.
+	StreamFormatJSONArray
+
+	// StreamFormatSSE writes each response as the data of a server-sent event, with the text/event-stream content type.
+	StreamFormatSSE
+)
 
 // JSONStream responds to an HTTP request by streaming responses as JSON objects.
+// The format of the stream is set with WithStreamFormat, and each response is flushed to the client as soon as it is written.
 //
 // When this method exits, it launches a go routine to continue consuming the responses
 // to ensure the producer closes the channel appropriately. This is done in the
@@ -44,17 +52,24 @@ func WithDeferredConsumerTimerDuration(duration time.Duration) JSONStreamOption
 //	    // Producer work here.
 //	  }
 //	}
-func JSONStream[RequestParameters any, ResponseBody any](writer http.ResponseWriter, request *http.Request, callback func(requestParameters *RequestParameters, cancelChan <-chan struct{}) (responseStream <-chan *ResponseBody, status int, err error), options ...JSONStreamOption) {
-	cfg := &jsonStreamConfig{
-		deferredConsumerTimerDuration: time.Minute,
-	}
-	for _, option := range options {
-		option(cfg)
+//
+// The active streams and deferred consumers are counted in the StreamStats returned by Stats.
+func JSONStream[RequestParameters any, ResponseBody any](writer http.ResponseWriter, request *http.Request, callback func(requestParameters *RequestParameters, cancelChan <-chan struct{}) (responseStream <-chan *ResponseBody, status int, err error), options ...Option) {
+	cfg := newConfig(options...)
+
+	activeStreams.Add(1)
+	defer activeStreams.Add(-1)
+
+	if cfg.streamLimiter != nil {
+		if !cfg.streamLimiter.tryAcquire() {
+			Error(request, writer, &errors.ServiceUnavailable{Err: errStreamLimitReached})
+			return
+		}
+		defer cfg.streamLimiter.release()
 	}
 
-	requestParams, err := parameters.Decode[RequestParameters](request)
-	if err != nil {
-		Error(request, writer, &errors.BadRequest{Err: err})
+	requestParams, ok := decodeParameters[RequestParameters](writer, request, cfg)
+	if !ok {
 		return
 	}
 
@@ -67,38 +82,58 @@ func JSONStream[RequestParameters any, ResponseBody any](writer http.ResponseWri
 		return
 	}
 
-	defer func() {
-		go func() {
-			timer := time.After(cfg.deferredConsumerTimerDuration)
-			for {
-				select {
-				case <-timer:
-					logger.Errorf(request.Context(), "Potential leak detected: JSON stream producer did not close its channel after %s.", cfg.deferredConsumerTimerDuration.String())
-				case _, isResponseChannelOpen := <-responseChan:
-					if !isResponseChannelOpen {
-						return
-					}
-				}
-			}
-		}()
-	}()
+	defer consumeInBackground(request, cfg, "JSON stream", responseChan)
 
-	writer.Header().Set(headers.ContentType, headers.ContentTypeApplicationJson)
+	var bodyWriter io.Writer = writer
+	var bodyHash hash.Hash
+	if cfg.checksumTrailer {
+		bodyHash = sha256.New()
+		bodyWriter = io.MultiWriter(writer, bodyHash)
+		writer.Header().Set(headers.Trailer, headers.ContentSHA256)
+	}
+
+	switch cfg.streamFormat {
+	case StreamFormatNDJSON:
+		writer.Header().Set(headers.ContentType, headers.ContentTypeApplicationNDJSON)
+	case StreamFormatSSE:
+		writer.Header().Set(headers.ContentType, headers.ContentTypeTextEventStream)
+		writer.Header().Set(headers.CacheControl, "no-cache")
+	default:
+		writer.Header().Set(headers.ContentType, headers.ContentTypeApplicationJson)
+	}
 	writer.Header().Set(headers.TransferEncoding, headers.TransferEncodingChunked)
 	writer.WriteHeader(status)
 
 	ctx := request.Context()
-	jsonEncoder := json.NewEncoder(writer)
-	for {
+	if cfg.streamFormat == StreamFormatJSONArray {
+		if _, err := io.WriteString(bodyWriter, "["); err != nil {
+			logger.Errorf(ctx, "Failed to write response (%s).", err)
+			return
+		}
+	}
+	if flusher, ok := writer.(http.Flusher); ok {
+		flusher.Flush()
+	}
+
+	for index := 0; ; index++ {
 		select {
 		case <-ctx.Done():
 			logger.Errorf(ctx, "Request cancelled (%s).", ctx.Err())
 			return
 		case response, isResponseChannelOpen := <-responseChan:
 			if !isResponseChannelOpen {
+				if cfg.streamFormat == StreamFormatJSONArray {
+					if _, err := io.WriteString(bodyWriter, "]\n"); err != nil {
+						logger.Errorf(ctx, "Failed to write response (%s).", err)
+						return
+					}
+				}
+				if bodyHash != nil {
+					writer.Header().Set(headers.ContentSHA256, hex.EncodeToString(bodyHash.Sum(nil)))
+				}
 				return
 			}
-			if err := jsonEncoder.Encode(response); err != nil {
+			if err := writeStreamElement(bodyWriter, response, index, cfg); err != nil {
 				logger.Errorf(ctx, "Failed to encode response (%s).", err)
 				return
 			}
@@ -108,3 +143,47 @@ func JSONStream[RequestParameters any, ResponseBody any](writer http.ResponseWri
 		}
 	}
 }
+
+// writeStreamElement writes a response of the JSONStream responder in its stream format.
+// The index is the position of the response in the stream.
+func writeStreamElement[ResponseBody any](writer io.Writer, response *ResponseBody, index int, cfg *config) error {
+	switch cfg.streamFormat {
+	case StreamFormatJSONArray:
+		if index > 0 {
+			if _, err := io.WriteString(writer, ","); err != nil {
+				return err
+			}
+		}
+		return encodeJSON(writer, response, cfg.sortedKeys)
+	case StreamFormatSSE:
+		encoded, err := encodeSSEEvent(&SSEEvent[ResponseBody]{Data: response}, cfg.sortedKeys)
+		if err != nil {
+			return err
+		}
+		_, err = writer.Write(encoded)
+		return err
+	default:
+		return encodeJSON(writer, response, cfg.sortedKeys)
+	}
+}
+
+// consumeInBackground launches a go routine that consumes the channel until the producer closes it.
+// This unblocks a producer that is writing on the channel after the responder has returned.
+// An error is logged if the channel is still open after the deferred consumer timer duration.
+func consumeInBackground[T any](request *http.Request, cfg *config, streamName string, channel <-chan T) {
+	activeDeferredConsumers.Add(1)
+	go func() {
+		defer activeDeferredConsumers.Add(-1)
+		timer := time.After(cfg.deferredConsumerTimerDuration)
+		for {
+			select {
+			case <-timer:
+				logger.Errorf(request.Context(), "Potential leak detected: %s producer did not close its channel after %s.", streamName, cfg.deferredConsumerTimerDuration.String())
+			case _, isChannelOpen := <-channel:
+				if !isChannelOpen {
+					return
+				}
+			}
+		}
+	}()
+}
@@ -2,12 +2,16 @@ package responders_test
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	goerrors "errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/TriangleSide/GoBase/pkg/http/errors"
 	"github.com/TriangleSide/GoBase/pkg/http/headers"
@@ -55,6 +59,104 @@ func TestJSONStreamResponder(t *testing.T) {
 		assert.NoError(t, response.Body.Close())
 	})
 
+	streamFormatServer := func(t *testing.T, count int, options ...responders.Option) *http.Response {
+		t.Helper()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			responders.JSONStream[requestParams, responseBody](w, r, func(params *requestParams, cancelChan <-chan struct{}) (<-chan *responseBody, int, error) {
+				ch := make(chan *responseBody)
+				go func() {
+					defer close(ch)
+					for i := range count {
+						ch <- &responseBody{Message: strings.Repeat("x", i+1)}
+					}
+				}()
+				return ch, http.StatusOK, nil
+			}, options...)
+		}))
+		t.Cleanup(server.Close)
+		response, err := http.Post(server.URL, headers.ContentTypeApplicationJson, strings.NewReader(`{"id":1}`))
+		assert.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, response.Body.Close())
+		})
+		return response
+	}
+
+	t.Run("when the stream format is the default it should respond with JSON values separated by newlines", func(t *testing.T) {
+		t.Parallel()
+		response := streamFormatServer(t, 2)
+		assert.Equals(t, response.Header.Get(headers.ContentType), headers.ContentTypeApplicationJson)
+		body, err := io.ReadAll(response.Body)
+		assert.NoError(t, err)
+		assert.Equals(t, string(body), "{\"message\":\"x\"}\n{\"message\":\"xx\"}\n")
+	})
+
+	t.Run("when the stream format is NDJSON it should respond with newline-delimited JSON", func(t *testing.T) {
+		t.Parallel()
+		response := streamFormatServer(t, 2, responders.WithStreamFormat(responders.StreamFormatNDJSON))
+		assert.Equals(t, response.Header.Get(headers.ContentType), headers.ContentTypeApplicationNDJSON)
+		body, err := io.ReadAll(response.Body)
+		assert.NoError(t, err)
+		assert.Equals(t, string(body), "{\"message\":\"x\"}\n{\"message\":\"xx\"}\n")
+	})
+
+	t.Run("when the stream format is a JSON array it should respond with a well-formed JSON array", func(t *testing.T) {
+		t.Parallel()
+		response := streamFormatServer(t, 3, responders.WithStreamFormat(responders.StreamFormatJSONArray))
+		assert.Equals(t, response.Header.Get(headers.ContentType), headers.ContentTypeApplicationJson)
+		var decoded []responseBody
+		assert.NoError(t, json.NewDecoder(response.Body).Decode(&decoded))
+		assert.Equals(t, decoded, []responseBody{{Message: "x"}, {Message: "xx"}, {Message: "xxx"}})
+	})
+
+	t.Run("when the stream format is a JSON array and the stream is empty it should respond with an empty array", func(t *testing.T) {
+		t.Parallel()
+		response := streamFormatServer(t, 0, responders.WithStreamFormat(responders.StreamFormatJSONArray))
+		body, err := io.ReadAll(response.Body)
+		assert.NoError(t, err)
+		assert.Equals(t, string(body), "[]\n")
+	})
+
+	t.Run("when the stream format is SSE it should respond with each response as the data of an event", func(t *testing.T) {
+		t.Parallel()
+		response := streamFormatServer(t, 2, responders.WithStreamFormat(responders.StreamFormatSSE))
+		assert.Equals(t, response.Header.Get(headers.ContentType), headers.ContentTypeTextEventStream)
+		assert.Equals(t, response.Header.Get(headers.CacheControl), "no-cache")
+		body, err := io.ReadAll(response.Body)
+		assert.NoError(t, err)
+		assert.Equals(t, string(body), "data: {\"message\":\"x\"}\n\ndata: {\"message\":\"xx\"}\n\n")
+	})
+
+	t.Run("when the checksum trailer is enabled it should send the SHA-256 of the body in the trailer", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			responders.JSONStream[requestParams, responseBody](w, r, func(params *requestParams, cancelChan <-chan struct{}) (<-chan *responseBody, int, error) {
+				ch := make(chan *responseBody)
+				go func() {
+					defer close(ch)
+					for i := 0; i < 100; i++ {
+						ch <- &responseBody{Message: strings.Repeat("x", i)}
+					}
+				}()
+				return ch, http.StatusOK, nil
+			}, responders.WithChecksumTrailer())
+		}))
+		defer server.Close()
+
+		response, err := http.Post(server.URL, headers.ContentTypeApplicationJson, strings.NewReader(`{"id":1}`))
+		assert.NoError(t, err)
+		assert.Equals(t, response.StatusCode, http.StatusOK)
+		_, declared := response.Trailer[http.CanonicalHeaderKey(headers.ContentSHA256)]
+		assert.True(t, declared)
+
+		body, err := io.ReadAll(response.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, response.Body.Close())
+		digest := sha256.Sum256(body)
+		assert.Equals(t, response.Trailer.Get(headers.ContentSHA256), hex.EncodeToString(digest[:]))
+	})
+
 	t.Run("when the parameter decoder fails it should respond with an error JSON response and appropriate status code", func(t *testing.T) {
 		t.Parallel()
 
@@ -152,3 +254,16 @@ func TestJSONStreamResponder(t *testing.T) {
 		assert.NoError(t, response.Body.Close())
 	})
 }
+
+func TestJSONStreamOptionAlias(t *testing.T) {
+	t.Parallel()
+	var option responders.JSONStreamOption = responders.WithDeferredConsumerTimerDuration(time.Second)
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	responders.JSONStream(recorder, request, func(*struct{}, <-chan struct{}) (<-chan *struct{}, int, error) {
+		stream := make(chan *struct{})
+		close(stream)
+		return stream, http.StatusOK, nil
+	}, option)
+	assert.Equals(t, recorder.Code, http.StatusOK)
+}
@@ -107,4 +107,42 @@ func TestJSONResponder(t *testing.T) {
 		assert.Error(t, err)
 		assert.NoError(t, response.Body.Close())
 	})
+
+	t.Run("when sorted keys are enabled it should emit identical bytes with the keys in sorted order", func(t *testing.T) {
+		t.Parallel()
+
+		type nested struct {
+			Zulu  string `json:"zulu"`
+			Alpha string `json:"alpha"`
+		}
+		type sortedResponse struct {
+			Zebra  int               `json:"zebra"`
+			Apple  float64           `json:"apple"`
+			Nested nested            `json:"nested"`
+			Labels map[string]string `json:"labels"`
+			Items  []nested          `json:"items"`
+		}
+
+		respond := func() string {
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			responders.JSON[struct{}, sortedResponse](recorder, request, func(*struct{}) (*sortedResponse, int, error) {
+				return &sortedResponse{
+					Zebra:  12345678901234,
+					Apple:  1.5,
+					Nested: nested{Zulu: "z", Alpha: "a"},
+					Labels: map[string]string{"b": "2", "c": "3", "a": "1"},
+					Items:  []nested{{Zulu: "z1", Alpha: "a1"}},
+				}, http.StatusOK, nil
+			}, responders.WithSortedKeys())
+			assert.Equals(t, recorder.Code, http.StatusOK)
+			return recorder.Body.String()
+		}
+
+		first := respond()
+		for i := 0; i < 10; i++ {
+			assert.Equals(t, respond(), first)
+		}
+		assert.Equals(t, first, `{"apple":1.5,"items":[{"alpha":"a1","zulu":"z1"}],"labels":{"a":"1","b":"2","c":"3"},"nested":{"alpha":"a","zulu":"z"},"zebra":12345678901234}`+"\n")
+	})
 }
@@ -0,0 +1,42 @@
+package responders
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+)
+
+// checkLastModified fetches the last modified time configured with WithLastModified for GET and HEAD requests.
+// If the resource has not been modified since the If-Modified-Since header, an HTTP 304 not modified is written
+// and true is returned. The returned time is in UTC and truncated to the second, as it is sent in the header.
+func checkLastModified(writer http.ResponseWriter, request *http.Request, cfg *config) (time.Time, bool, error) {
+	if cfg.lastModified == nil || (request.Method != http.MethodGet && request.Method != http.MethodHead) {
+		return time.Time{}, false, nil
+	}
+
+	lastModified, err := cfg.lastModified(request)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if lastModified.IsZero() {
+		return time.Time{}, false, nil
+	}
+	lastModified = lastModified.UTC().Truncate(time.Second)
+
+	// The If-None-Match header takes precedence over the If-Modified-Since header when ETags are used.
+	if cfg.etag != etagNone && request.Header.Get(headers.IfNoneMatch) != "" {
+		return lastModified, false, nil
+	}
+
+	if ifModifiedSince := request.Header.Get(headers.IfModifiedSince); ifModifiedSince != "" {
+		since, err := http.ParseTime(ifModifiedSince)
+		if err == nil && !lastModified.After(since) {
+			writer.Header().Set(headers.LastModified, lastModified.Format(http.TimeFormat))
+			writer.WriteHeader(http.StatusNotModified)
+			return lastModified, true, nil
+		}
+	}
+
+	return lastModified, false, nil
+}
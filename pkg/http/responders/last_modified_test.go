@@ -0,0 +1,110 @@
+package responders_test
+
+import (
+	goerrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/responders"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestLastModified(t *testing.T) {
+	t.Parallel()
+
+	type requestParams struct{}
+
+	type responseBody struct {
+		Message string `json:"message"`
+	}
+
+	modifiedAt := time.Date(2024, 5, 1, 10, 30, 15, 750000000, time.FixedZone("offset", 2*60*60))
+
+	serve := func(method string, ifModifiedSince string, lastModified func(*http.Request) (time.Time, error)) (*httptest.ResponseRecorder, bool) {
+		request := httptest.NewRequest(method, "/", nil)
+		if ifModifiedSince != "" {
+			request.Header.Set(headers.IfModifiedSince, ifModifiedSince)
+		}
+		recorder := httptest.NewRecorder()
+		callbackCalled := false
+		responders.JSON[requestParams, responseBody](recorder, request, func(*requestParams) (*responseBody, int, error) {
+			callbackCalled = true
+			return &responseBody{Message: "resource"}, http.StatusOK, nil
+		}, responders.WithLastModified(lastModified))
+		return recorder, callbackCalled
+	}
+
+	fixedLastModified := func(*http.Request) (time.Time, error) {
+		return modifiedAt, nil
+	}
+
+	t.Run("when there is no If-Modified-Since header it should respond with the resource and its Last-Modified", func(t *testing.T) {
+		t.Parallel()
+		recorder, callbackCalled := serve(http.MethodGet, "", fixedLastModified)
+		assert.True(t, callbackCalled)
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.Equals(t, recorder.Header().Get(headers.LastModified), "Wed, 01 May 2024 08:30:15 GMT")
+	})
+
+	t.Run("when the If-Modified-Since header matches it should respond with not modified", func(t *testing.T) {
+		t.Parallel()
+		for _, ifModifiedSince := range []string{"Wed, 01 May 2024 08:30:15 GMT", "Wed, 01 May 2024 09:00:00 GMT"} {
+			recorder, callbackCalled := serve(http.MethodGet, ifModifiedSince, fixedLastModified)
+			assert.False(t, callbackCalled)
+			assert.Equals(t, recorder.Code, http.StatusNotModified)
+			assert.Equals(t, recorder.Header().Get(headers.LastModified), "Wed, 01 May 2024 08:30:15 GMT")
+			assert.Equals(t, recorder.Body.Len(), 0)
+		}
+	})
+
+	t.Run("when the resource was modified after the If-Modified-Since header it should respond with the resource", func(t *testing.T) {
+		t.Parallel()
+		recorder, callbackCalled := serve(http.MethodGet, "Wed, 01 May 2024 08:30:14 GMT", fixedLastModified)
+		assert.True(t, callbackCalled)
+		assert.Equals(t, recorder.Code, http.StatusOK)
+	})
+
+	t.Run("when the If-Modified-Since header is invalid it should respond with the resource", func(t *testing.T) {
+		t.Parallel()
+		recorder, callbackCalled := serve(http.MethodGet, "yesterday", fixedLastModified)
+		assert.True(t, callbackCalled)
+		assert.Equals(t, recorder.Code, http.StatusOK)
+	})
+
+	t.Run("when the request is a HEAD request it should respond with not modified", func(t *testing.T) {
+		t.Parallel()
+		recorder, _ := serve(http.MethodHead, "Wed, 01 May 2024 08:30:15 GMT", fixedLastModified)
+		assert.Equals(t, recorder.Code, http.StatusNotModified)
+	})
+
+	t.Run("when the request is not a GET or HEAD request it should ignore the conditional headers", func(t *testing.T) {
+		t.Parallel()
+		recorder, callbackCalled := serve(http.MethodPost, "Wed, 01 May 2024 08:30:15 GMT", fixedLastModified)
+		assert.True(t, callbackCalled)
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.Equals(t, recorder.Header().Get(headers.LastModified), "")
+	})
+
+	t.Run("when the last modified time is zero it should respond without a Last-Modified header", func(t *testing.T) {
+		t.Parallel()
+		recorder, callbackCalled := serve(http.MethodGet, "Wed, 01 May 2024 08:30:15 GMT", func(*http.Request) (time.Time, error) {
+			return time.Time{}, nil
+		})
+		assert.True(t, callbackCalled)
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.Equals(t, recorder.Header().Get(headers.LastModified), "")
+	})
+
+	t.Run("when the last modified callback fails it should respond with the error", func(t *testing.T) {
+		t.Parallel()
+		recorder, callbackCalled := serve(http.MethodGet, "", func(*http.Request) (time.Time, error) {
+			return time.Time{}, &errors.BadRequest{Err: goerrors.New("unknown resource")}
+		})
+		assert.False(t, callbackCalled)
+		assert.Equals(t, recorder.Code, http.StatusBadRequest)
+	})
+}
@@ -0,0 +1,58 @@
+package responders
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/logger"
+)
+
+// LongPoll responds to an HTTP request with the next event of a subscription, for clients that cannot use streams.
+//
+// The subscribe callback returns the channel of events and a function that cancels the subscription.
+// The responder waits up to the timeout for an event. If one arrives, it is sent as JSON with an HTTP 200 OK.
+// If the timeout expires or the channel is closed, it responds with an HTTP 204 no content so the client can poll again.
+// The subscription is cancelled when the responder returns, including when the client disconnects.
+func LongPoll[RequestParameters any, Event any](writer http.ResponseWriter, request *http.Request, subscribe func(*RequestParameters) (<-chan *Event, func(), error), timeout time.Duration, options ...Option) {
+	if timeout <= 0 {
+		panic("the long poll timeout must be greater than zero")
+	}
+
+	cfg := newConfig(options...)
+
+	requestParams, ok := decodeParameters[RequestParameters](writer, request, cfg)
+	if !ok {
+		return
+	}
+
+	eventChan, unsubscribe, err := subscribe(requestParams)
+	if err != nil {
+		Error(request, writer, err)
+		return
+	}
+	if unsubscribe != nil {
+		defer unsubscribe()
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	ctx := request.Context()
+	select {
+	case <-ctx.Done():
+		logger.Errorf(ctx, "Request cancelled (%s).", ctx.Err())
+	case <-timer.C:
+		writer.WriteHeader(http.StatusNoContent)
+	case event, isEventChannelOpen := <-eventChan:
+		if !isEventChannelOpen {
+			writer.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writer.Header().Set(headers.ContentType, headers.ContentTypeApplicationJson)
+		writer.WriteHeader(http.StatusOK)
+		if err := encodeJSON(writer, event, cfg.sortedKeys); err != nil {
+			logger.Errorf(ctx, "Failed to encode event (%s).", err)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package responders_test
+
+import (
+	"context"
+	"encoding/json"
+	goerrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/responders"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestLongPoll(t *testing.T) {
+	t.Parallel()
+
+	type requestParams struct {
+		Topic string `urlQuery:"topic" json:"-" validate:"required"`
+	}
+
+	type event struct {
+		Topic string `json:"topic"`
+		Value int    `json:"value"`
+	}
+
+	type subscription struct {
+		events       chan *event
+		unsubscribed atomic.Bool
+	}
+
+	newSubscription := func() *subscription {
+		return &subscription{events: make(chan *event, 1)}
+	}
+
+	serve := func(sub *subscription, request *http.Request, timeout time.Duration) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		responders.LongPoll[requestParams, event](recorder, request, func(params *requestParams) (<-chan *event, func(), error) {
+			return sub.events, func() { sub.unsubscribed.Store(true) }, nil
+		}, timeout)
+		return recorder
+	}
+
+	t.Run("when the timeout is not positive it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicExact(t, func() {
+			serve(newSubscription(), httptest.NewRequest(http.MethodGet, "/?topic=a", nil), 0)
+		}, "the long poll timeout must be greater than zero")
+	})
+
+	t.Run("when an event arrives before the timeout it should respond with the event", func(t *testing.T) {
+		t.Parallel()
+		sub := newSubscription()
+		go func() {
+			time.Sleep(time.Millisecond * 20)
+			sub.events <- &event{Topic: "a", Value: 1}
+		}()
+		recorder := serve(sub, httptest.NewRequest(http.MethodGet, "/?topic=a", nil), time.Second*10)
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.Equals(t, recorder.Header().Get(headers.ContentType), headers.ContentTypeApplicationJson)
+		received := &event{}
+		assert.NoError(t, json.NewDecoder(recorder.Body).Decode(received))
+		assert.Equals(t, *received, event{Topic: "a", Value: 1})
+		assert.True(t, sub.unsubscribed.Load())
+	})
+
+	t.Run("when no event arrives before the timeout it should respond with no content", func(t *testing.T) {
+		t.Parallel()
+		sub := newSubscription()
+		recorder := serve(sub, httptest.NewRequest(http.MethodGet, "/?topic=a", nil), time.Millisecond*20)
+		assert.Equals(t, recorder.Code, http.StatusNoContent)
+		assert.Equals(t, recorder.Body.Len(), 0)
+		assert.True(t, sub.unsubscribed.Load())
+	})
+
+	t.Run("when the event channel is closed it should respond with no content", func(t *testing.T) {
+		t.Parallel()
+		sub := newSubscription()
+		close(sub.events)
+		recorder := serve(sub, httptest.NewRequest(http.MethodGet, "/?topic=a", nil), time.Second*10)
+		assert.Equals(t, recorder.Code, http.StatusNoContent)
+		assert.True(t, sub.unsubscribed.Load())
+	})
+
+	t.Run("when the client disconnects it should unsubscribe", func(t *testing.T) {
+		t.Parallel()
+		sub := newSubscription()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		request := httptest.NewRequest(http.MethodGet, "/?topic=a", nil).WithContext(ctx)
+		serve(sub, request, time.Second*10)
+		assert.True(t, sub.unsubscribed.Load())
+	})
+
+	t.Run("when the parameters are invalid it should respond with a bad request without subscribing", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		subscribed := false
+		responders.LongPoll[requestParams, event](recorder, httptest.NewRequest(http.MethodGet, "/", nil), func(params *requestParams) (<-chan *event, func(), error) {
+			subscribed = true
+			return nil, nil, nil
+		}, time.Second)
+		assert.Equals(t, recorder.Code, http.StatusBadRequest)
+		assert.False(t, subscribed)
+	})
+
+	t.Run("when the subscription fails it should respond with the error", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		responders.LongPoll[requestParams, event](recorder, httptest.NewRequest(http.MethodGet, "/?topic=a", nil), func(params *requestParams) (<-chan *event, func(), error) {
+			return nil, nil, &errors.BadRequest{Err: goerrors.New("unknown topic")}
+		}, time.Second)
+		assert.Equals(t, recorder.Code, http.StatusBadRequest)
+	})
+}
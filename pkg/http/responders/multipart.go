@@ -0,0 +1,87 @@
+package responders
+
+import (
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/logger"
+)
+
+// Part is a section of a multipart response.
+type Part struct {
+	// ContentType is the media type of the part body.
+	ContentType string
+
+	// Header contains additional headers for the part, such as Content-Disposition.
+	Header http.Header
+
+	// Body is streamed into the response. If it implements io.Closer, it is closed once the response is written.
+	Body io.Reader
+}
+
+// contextReader stops reading from the io.Reader once the context is done.
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+// Read is the contextReader implementing the io.Reader interface.
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.reader.Read(p)
+}
+
+// Multipart responds to an HTTP request with a multipart/mixed body made of the parts.
+// Each part body is streamed into the response. This allows combining, for example, JSON metadata
+// and a binary file into one response without encoding the binary in the JSON.
+// The response stops being written if the request context is cancelled.
+func Multipart(writer http.ResponseWriter, request *http.Request, parts ...Part) {
+	defer func() {
+		for _, part := range parts {
+			if closer, ok := part.Body.(io.Closer); ok {
+				if err := closer.Close(); err != nil {
+					logger.Errorf(request.Context(), "Failed to close multipart body (%s).", err)
+				}
+			}
+		}
+	}()
+
+	ctx := request.Context()
+	multipartWriter := multipart.NewWriter(writer)
+	writer.Header().Set(headers.ContentType, headers.ContentTypeMultipartMixed+"; boundary="+multipartWriter.Boundary())
+	writer.WriteHeader(http.StatusOK)
+
+	for _, part := range parts {
+		partHeader := make(textproto.MIMEHeader, len(part.Header)+1)
+		for key, values := range part.Header {
+			partHeader[textproto.CanonicalMIMEHeaderKey(key)] = values
+		}
+		if part.ContentType != "" {
+			partHeader.Set(headers.ContentType, part.ContentType)
+		}
+		partWriter, err := multipartWriter.CreatePart(partHeader)
+		if err != nil {
+			logger.Errorf(ctx, "Failed to create multipart part (%s).", err)
+			return
+		}
+		if part.Body != nil {
+			if _, err := io.Copy(partWriter, &contextReader{ctx: ctx, reader: part.Body}); err != nil {
+				logger.Errorf(ctx, "Failed to write multipart part (%s).", err)
+				return
+			}
+		}
+		if flusher, ok := writer.(http.Flusher); ok {
+			flusher.Flush()
+		}
+	}
+
+	if err := multipartWriter.Close(); err != nil {
+		logger.Errorf(ctx, "Failed to close multipart writer (%s).", err)
+	}
+}
@@ -0,0 +1,100 @@
+package responders_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/responders"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+type testCloseReader struct {
+	io.Reader
+	closed   bool
+	closeErr error
+}
+
+func (r *testCloseReader) Close() error {
+	r.closed = true
+	return r.closeErr
+}
+
+func TestMultipartResponder(t *testing.T) {
+	t.Parallel()
+
+	readParts := func(t *testing.T, recorder *httptest.ResponseRecorder) []*multipart.Part {
+		t.Helper()
+		mediaType, params, err := mime.ParseMediaType(recorder.Header().Get(headers.ContentType))
+		assert.NoError(t, err)
+		assert.Equals(t, mediaType, headers.ContentTypeMultipartMixed)
+		reader := multipart.NewReader(recorder.Body, params["boundary"])
+		parts := make([]*multipart.Part, 0)
+		for {
+			part, err := reader.NextPart()
+			if errors.Is(err, io.EOF) {
+				return parts
+			}
+			assert.NoError(t, err)
+			parts = append(parts, part)
+			body, err := io.ReadAll(part)
+			assert.NoError(t, err)
+			part.Header.Set("X-Test-Body", string(body))
+		}
+	}
+
+	t.Run("when parts are written they should be readable with a multipart reader", func(t *testing.T) {
+		t.Parallel()
+		binaryBody := &testCloseReader{Reader: strings.NewReader("\x00\x01\x02")}
+		recorder := httptest.NewRecorder()
+		responders.Multipart(recorder, httptest.NewRequest(http.MethodGet, "/", nil), responders.Part{
+			ContentType: headers.ContentTypeApplicationJson,
+			Body:        strings.NewReader(`{"name":"file.bin"}`),
+		}, responders.Part{
+			ContentType: "application/octet-stream",
+			Header:      http.Header{"content-disposition": []string{`attachment; filename="file.bin"`}},
+			Body:        binaryBody,
+		}, responders.Part{
+			ContentType: "text/plain",
+		})
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.True(t, binaryBody.closed)
+
+		parts := readParts(t, recorder)
+		assert.Equals(t, len(parts), 3)
+		assert.Equals(t, parts[0].Header.Get(headers.ContentType), headers.ContentTypeApplicationJson)
+		assert.Equals(t, parts[0].Header.Get("X-Test-Body"), `{"name":"file.bin"}`)
+		assert.Equals(t, parts[1].Header.Get(headers.ContentType), "application/octet-stream")
+		assert.Equals(t, parts[1].FileName(), "file.bin")
+		assert.Equals(t, parts[1].Header.Get("X-Test-Body"), "\x00\x01\x02")
+		assert.Equals(t, parts[2].Header.Get("X-Test-Body"), "")
+	})
+
+	t.Run("when the request context is cancelled it should stop writing the parts and close the bodies", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		body := &testCloseReader{Reader: strings.NewReader("body"), closeErr: errors.New("close error")}
+		recorder := httptest.NewRecorder()
+		responders.Multipart(recorder, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx), responders.Part{
+			ContentType: "text/plain",
+			Body:        body,
+		})
+		assert.True(t, body.closed)
+		assert.False(t, strings.Contains(recorder.Body.String(), "body"))
+	})
+
+	t.Run("when there are no parts it should write an empty multipart body", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		responders.Multipart(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equals(t, len(readParts(t, recorder)), 0)
+	})
+}
@@ -0,0 +1,51 @@
+package responders
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/TriangleSide/GoBase/pkg/http/codec"
+	httperrors "github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/logger"
+)
+
+// Negotiate responds to an HTTP request by encoding the response in the format that the Accept header of the request
+// prefers. The JSON, XML, and MessagePack codecs are available by default, and more can be added with WithCodecs, like
+// a protobuf codec. The format is negotiated before the callback is called, so if the client accepts none of the
+// formats that support the response type, the responder replies with an HTTP 406 not acceptable.
+func Negotiate[RequestParameters any, ResponseBody any](writer http.ResponseWriter, request *http.Request, callback func(*RequestParameters) (*ResponseBody, int, error), options ...Option) {
+	cfg := newConfig(options...)
+
+	writer.Header().Add(headers.Vary, headers.Accept)
+
+	// A typed nil is enough for the codecs to tell whether they support the response type.
+	responseCodec, ok := codec.Negotiate(request.Header.Get(headers.Accept), cfg.codecs, cfg.defaultCodec, (*ResponseBody)(nil))
+	if !ok {
+		Error(request, writer, &httperrors.NotAcceptable{
+			Err: fmt.Errorf("none of the accepted media types (%s) can be produced", request.Header.Get(headers.Accept)),
+		})
+		return
+	}
+
+	requestParams, ok := decodeParameters[RequestParameters](writer, request, cfg)
+	if !ok {
+		return
+	}
+
+	response, status, err := callback(requestParams)
+	if err != nil {
+		Error(request, writer, err)
+		return
+	}
+
+	setCookies(writer, response, cfg)
+
+	writer.Header().Set(headers.ContentType, responseCodec.ContentType())
+	writer.WriteHeader(status)
+
+	if err := responseCodec.Encode(writer, response); err != nil {
+		logger.Errorf(request.Context(), "Failed to encode response (%s).", err)
+		return
+	}
+}
@@ -0,0 +1,129 @@
+package responders_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	goerrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/http/codec"
+	"github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/responders"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+type testNegotiateProtoMessage struct {
+	Data []byte
+}
+
+func TestNegotiateResponder(t *testing.T) {
+	t.Parallel()
+
+	type requestParams struct {
+		ID int `urlQuery:"id" json:"-" validate:"gt=0"`
+	}
+
+	type responseBody struct {
+		Message string `json:"message" xml:"message"`
+	}
+
+	callback := func(params *requestParams) (*responseBody, int, error) {
+		if params.ID == 500 {
+			return nil, 0, &errors.BadRequest{Err: goerrors.New("callback failure")}
+		}
+		return &responseBody{Message: "processed"}, http.StatusOK, nil
+	}
+
+	negotiate := func(accept string, options ...responders.Option) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/?id=1", nil)
+		if accept != "" {
+			request.Header.Set(headers.Accept, accept)
+		}
+		responders.Negotiate[requestParams, responseBody](recorder, request, callback, options...)
+		return recorder
+	}
+
+	t.Run("when the request has no Accept header it should respond with JSON", func(t *testing.T) {
+		t.Parallel()
+		recorder := negotiate("")
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.Equals(t, recorder.Header().Get(headers.ContentType), headers.ContentTypeApplicationJson)
+		assert.Equals(t, recorder.Header().Get(headers.Vary), headers.Accept)
+		body := &responseBody{}
+		assert.NoError(t, json.NewDecoder(recorder.Body).Decode(body))
+		assert.Equals(t, body.Message, "processed")
+	})
+
+	t.Run("when the request accepts XML it should respond with XML", func(t *testing.T) {
+		t.Parallel()
+		recorder := negotiate("application/xml, application/json;q=0.8")
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.Equals(t, recorder.Header().Get(headers.ContentType), headers.ContentTypeApplicationXML)
+		body := &responseBody{}
+		assert.NoError(t, xml.NewDecoder(recorder.Body).Decode(body))
+		assert.Equals(t, body.Message, "processed")
+	})
+
+	t.Run("when the request accepts MessagePack it should respond with MessagePack", func(t *testing.T) {
+		t.Parallel()
+		recorder := negotiate("application/msgpack")
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.Equals(t, recorder.Header().Get(headers.ContentType), headers.ContentTypeApplicationMsgPack)
+		body := &responseBody{}
+		assert.NoError(t, codec.MessagePack().Decode(recorder.Body, body))
+		assert.Equals(t, body.Message, "processed")
+	})
+
+	t.Run("when the request accepts none of the formats it should respond with not acceptable", func(t *testing.T) {
+		t.Parallel()
+		recorder := negotiate("text/html")
+		assert.Equals(t, recorder.Code, http.StatusNotAcceptable)
+		assert.Equals(t, recorder.Header().Get(headers.ContentType), headers.ContentTypeApplicationJson)
+	})
+
+	t.Run("when the default codec is changed it should respond with it without an Accept header", func(t *testing.T) {
+		t.Parallel()
+		recorder := negotiate("", responders.WithDefaultCodec(codec.XML()))
+		assert.Equals(t, recorder.Header().Get(headers.ContentType), headers.ContentTypeApplicationXML)
+	})
+
+	t.Run("when the callback returns an error it should respond with the error", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/?id=500", nil)
+		responders.Negotiate[requestParams, responseBody](recorder, request, callback)
+		assert.Equals(t, recorder.Code, http.StatusBadRequest)
+	})
+
+	t.Run("when the parameters fail validation it should respond with bad request", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/?id=0", nil)
+		responders.Negotiate[requestParams, responseBody](recorder, request, callback)
+		assert.Equals(t, recorder.Code, http.StatusBadRequest)
+	})
+
+	t.Run("when a protobuf codec is added it should respond with protobuf for messages", func(t *testing.T) {
+		t.Parallel()
+		protobuf := codec.NewProtobuf(func(message *testNegotiateProtoMessage) ([]byte, error) {
+			return message.Data, nil
+		}, func(data []byte, message *testNegotiateProtoMessage) error {
+			message.Data = data
+			return nil
+		})
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set(headers.Accept, headers.ContentTypeApplicationProtobuf)
+		responders.Negotiate[struct{}, testNegotiateProtoMessage](recorder, request, func(*struct{}) (*testNegotiateProtoMessage, int, error) {
+			return &testNegotiateProtoMessage{Data: []byte{8, 1}}, http.StatusOK, nil
+		}, responders.WithCodecs(protobuf))
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.Equals(t, recorder.Header().Get(headers.ContentType), headers.ContentTypeApplicationProtobuf)
+		assert.True(t, bytes.Equal(recorder.Body.Bytes(), []byte{8, 1}))
+	})
+}
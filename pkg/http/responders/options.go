@@ -0,0 +1,211 @@
+package responders
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/http/codec"
+	httperrors "github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/http/parameters"
+	"github.com/TriangleSide/GoBase/pkg/validation"
+)
+
+// config is used to configure the responders.
+type config struct {
+	deferredConsumerTimerDuration time.Duration
+	streamLimiter                 *StreamLimiter
+	validationFailureStatus       int
+	sortedKeys                    bool
+	checksumTrailer               bool
+	lastModified                  func(request *http.Request) (time.Time, error)
+	etag                          etagKind
+	codecs                        []codec.Codec
+	defaultCodec                  codec.Codec
+	cookies                       func(response any) []*http.Cookie
+	streamFormat                  StreamFormat
+}
+
+// Option is used to set values on the responder configuration.
+type Option func(config *config)
+
+// JSONStreamOption is used to set values on the stream configuration.
+//
+// Deprecated: Use Option, which configures every responder.
+type JSONStreamOption = Option
+
+// newConfig allocates a config with its default values and applies the options.
+func newConfig(options ...Option) *config {
+	cfg := &config{
+		deferredConsumerTimerDuration: time.Minute,
+		streamLimiter:                 nil,
+		validationFailureStatus:       http.StatusBadRequest,
+		sortedKeys:                    false,
+		checksumTrailer:               false,
+		lastModified:                  nil,
+		etag:                          etagNone,
+		codecs:                        []codec.Codec{codec.JSON(), codec.XML(), codec.MessagePack()},
+		defaultCodec:                  codec.JSON(),
+		cookies:                       nil,
+		streamFormat:                  StreamFormatJSON,
+	}
+	for _, option := range options {
+		option(cfg)
+	}
+	return cfg
+}
+
+// WithDeferredConsumerTimerDuration configures how long to wait before printing
+// an error log on the deferred consumer of the JSONStream responder.
+func WithDeferredConsumerTimerDuration(duration time.Duration) Option {
+	return func(config *config) {
+		config.deferredConsumerTimerDuration = duration
+	}
+}
+
+// WithStreamLimiter caps the number of concurrent streams with the StreamLimiter.
+// When the limit is reached, the request is rejected with an HTTP 503 service unavailable.
+func WithStreamLimiter(limiter *StreamLimiter) Option {
+	return func(config *config) {
+		config.streamLimiter = limiter
+	}
+}
+
+// WithValidationFailureStatus sets the status of the response when the request parameters are well-formed
+// but fail validation, such as HTTP 422 unprocessable entity. Requests that cannot be decoded still
+// respond with an HTTP 400 bad request. The default is HTTP 400 bad request.
+func WithValidationFailureStatus(status int) Option {
+	return func(config *config) {
+		config.validationFailureStatus = status
+	}
+}
+
+// WithSortedKeys makes the JSON responders emit the keys of every object in sorted order.
+// Struct fields are ordered by their JSON name instead of their declaration order, which makes
+// the output byte-for-byte reproducible for golden-file tests and response signing.
+func WithSortedKeys() Option {
+	return func(config *config) {
+		config.sortedKeys = true
+	}
+}
+
+// WithChecksumTrailer makes the JSONStream responder compute the SHA-256 of the streamed body and send it
+// in the X-Content-SHA256 trailer once the stream completes. Clients that support trailers can use it to
+// verify the integrity of the body. The trailer is not sent if the stream is interrupted.
+func WithChecksumTrailer() Option {
+	return func(config *config) {
+		config.checksumTrailer = true
+	}
+}
+
+// WithStreamFormat sets the format of the body of the JSONStream responder. The default is StreamFormatJSON.
+func WithStreamFormat(format StreamFormat) Option {
+	return func(config *config) {
+		config.streamFormat = format
+	}
+}
+
+// WithLastModified makes the JSON responder handle conditional GET and HEAD requests. The callback returns when
+// the resource was last modified, which is sent in the Last-Modified header. If the request has an If-Modified-Since
+// header and the resource has not changed since, the responder replies with an HTTP 304 not modified without calling
+// the responder callback. Times are compared at second granularity. A zero time disables the check for the request.
+func WithLastModified(lastModified func(request *http.Request) (time.Time, error)) Option {
+	return func(config *config) {
+		config.lastModified = lastModified
+	}
+}
+
+// WithETag makes the JSON responder send a strong ETag computed from the encoded body of the HTTP 200 OK responses
+// to GET and HEAD requests. If the request has an If-None-Match header that lists the ETag, the responder replies with an HTTP 304
+// not modified without a body. Since the ETag is derived from the body, the responder callback is still called.
+// When the request has an If-None-Match header, its If-Modified-Since header is ignored.
+func WithETag() Option {
+	return func(config *config) {
+		config.etag = etagStrong
+	}
+}
+
+// WithWeakETag is like WithETag, but the ETag is weak. A weak ETag only claims that the responses are semantically
+// equivalent, which is appropriate when the body can vary in insignificant ways, like the order of its keys.
+func WithWeakETag() Option {
+	return func(config *config) {
+		config.etag = etagWeak
+	}
+}
+
+// WithCodecs adds codecs that the Negotiate responder can encode the response with, like a protobuf codec.
+// The codecs that come first are preferred when the Accept header of the request rates formats equally.
+func WithCodecs(codecs ...codec.Codec) Option {
+	return func(config *config) {
+		config.codecs = append(config.codecs, codecs...)
+	}
+}
+
+// WithDefaultCodec sets the codec that the Negotiate responder uses when the request has no Accept header,
+// and prefers when the Accept header rates formats equally. The default is the JSON codec.
+func WithDefaultCodec(defaultCodec codec.Codec) Option {
+	return func(config *config) {
+		config.defaultCodec = defaultCodec
+	}
+}
+
+// WithCookies makes the JSON and Negotiate responders set cookies on the response, like a session token. The callback
+// receives the response returned by the responder callback, and is not called if the responder callback fails.
+// The response type must match the response type of the responder, otherwise the responder panics.
+func WithCookies[ResponseBody any](cookies func(response *ResponseBody) []*http.Cookie) Option {
+	return func(config *config) {
+		config.cookies = cookiesForResponse(cookies)
+	}
+}
+
+// decodeParameters decodes the request parameters. If it fails, the error response is written and false is returned.
+// Bodies that exceed the limit of an http.MaxBytesReader, or that are not read before the read deadline, are
+// rejected with an HTTP 413 request entity too large and an HTTP 408 request timeout respectively.
+func decodeParameters[RequestParameters any](writer http.ResponseWriter, request *http.Request, cfg *config) (*RequestParameters, bool) {
+	requestParams, err := parameters.Decode[RequestParameters](request)
+	if err != nil {
+		writeDecodeError(writer, request, cfg, err)
+		return nil, false
+	}
+	return requestParams, true
+}
+
+// DecodeError responds to a request whose parameters could not be decoded, like a responder does. Bodies that exceed
+// the limit of an http.MaxBytesReader, or that are not read before the read deadline, get an HTTP 413 request entity
+// too large and an HTTP 408 request timeout respectively. Validation failures get the status of
+// WithValidationFailureStatus, and the other errors an HTTP 400 bad request.
+func DecodeError(request *http.Request, writer http.ResponseWriter, err error, options ...Option) {
+	writeDecodeError(writer, request, newConfig(options...), err)
+}
+
+// writeDecodeError writes the error response of a request that could not be decoded.
+func writeDecodeError(writer http.ResponseWriter, request *http.Request, cfg *config, err error) {
+	var validationErr *validation.Error
+	if cfg.validationFailureStatus != http.StatusBadRequest && errors.As(err, &validationErr) {
+		recordHandledError(request, err)
+		writeError(request, writer, cfg.validationFailureStatus, err.Error(), validationErr, err)
+		return
+	}
+	if bodyErr := bodyReadError(err); bodyErr != nil {
+		Error(request, writer, bodyErr)
+		return
+	}
+	Error(request, writer, &httperrors.BadRequest{Err: err})
+}
+
+// bodyReadError maps the errors of reading a request body that exceeds the limit of an http.MaxBytesReader, or that
+// is not read before the read deadline, to an HTTP 413 request entity too large and an HTTP 408 request timeout
+// respectively. It returns nil for other errors.
+func bodyReadError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return &httperrors.RequestEntityTooLarge{Err: fmt.Errorf("the request body exceeds the maximum size of %d bytes", maxBytesErr.Limit)}
+	case errors.Is(err, os.ErrDeadlineExceeded):
+		return &httperrors.RequestTimeout{Err: errors.New("the request body was not received in time")}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,124 @@
+package responders
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	httperrors "github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/logger"
+)
+
+// SSEEvent is an event sent by the SSE responder.
+//
+// The ID is remembered by the client and sent back in the Last-Event-ID header when it reconnects.
+// The Event names the type of the event, and the client uses "message" if it is empty. The Retry
+// sets how long the client waits before it reconnects, and is not sent if it is zero. The Data is
+// encoded as JSON. The ID and Event cannot contain line breaks.
+type SSEEvent[ResponseBody any] struct {
+	ID    string
+	Event string
+	Retry time.Duration
+	Data  *ResponseBody
+}
+
+// SSE responds to an HTTP request by streaming server-sent events with the text/event-stream format.
+// Each event is flushed to the client as soon as it is written.
+//
+// The producer must close the channel when it is done, and stop producing when the cancel channel is closed.
+// The cancel channel is closed when the client disconnects or when the responder returns. Like the JSONStream
+// responder, the channel is consumed in the background after the responder returns so the producer is not blocked.
+//
+// The active streams and deferred consumers are counted in the StreamStats returned by Stats.
+func SSE[RequestParameters any, ResponseBody any](writer http.ResponseWriter, request *http.Request, callback func(requestParameters *RequestParameters, cancelChan <-chan struct{}) (eventStream <-chan *SSEEvent[ResponseBody], status int, err error), options ...Option) {
+	cfg := newConfig(options...)
+
+	activeStreams.Add(1)
+	defer activeStreams.Add(-1)
+
+	if cfg.streamLimiter != nil {
+		if !cfg.streamLimiter.tryAcquire() {
+			Error(request, writer, &httperrors.ServiceUnavailable{Err: errStreamLimitReached})
+			return
+		}
+		defer cfg.streamLimiter.release()
+	}
+
+	requestParams, ok := decodeParameters[RequestParameters](writer, request, cfg)
+	if !ok {
+		return
+	}
+
+	cancelChan := make(chan struct{})
+	defer close(cancelChan)
+
+	eventChan, status, err := callback(requestParams, cancelChan)
+	if err != nil {
+		Error(request, writer, err)
+		return
+	}
+
+	defer consumeInBackground(request, cfg, "SSE", eventChan)
+
+	writer.Header().Set(headers.ContentType, headers.ContentTypeTextEventStream)
+	writer.Header().Set(headers.CacheControl, "no-cache")
+	writer.WriteHeader(status)
+	if flusher, ok := writer.(http.Flusher); ok {
+		flusher.Flush()
+	}
+
+	ctx := request.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Errorf(ctx, "Request cancelled (%s).", ctx.Err())
+			return
+		case event, isEventChannelOpen := <-eventChan:
+			if !isEventChannelOpen {
+				return
+			}
+			encoded, err := encodeSSEEvent(event, cfg.sortedKeys)
+			if err != nil {
+				logger.Errorf(ctx, "Failed to encode event (%s).", err)
+				return
+			}
+			if _, err := writer.Write(encoded); err != nil {
+				logger.Errorf(ctx, "Failed to write event (%s).", err)
+				return
+			}
+			if flusher, ok := writer.(http.Flusher); ok {
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// encodeSSEEvent formats the event with the fields of the text/event-stream format, followed by a blank line.
+func encodeSSEEvent[ResponseBody any](event *SSEEvent[ResponseBody], sortedKeys bool) ([]byte, error) {
+	if strings.ContainsAny(event.ID, "\r\n") || strings.ContainsAny(event.Event, "\r\n") {
+		return nil, errors.New("the event ID and name cannot contain line breaks")
+	}
+
+	var buffer bytes.Buffer
+	if event.ID != "" {
+		buffer.WriteString("id: " + event.ID + "\n")
+	}
+	if event.Event != "" {
+		buffer.WriteString("event: " + event.Event + "\n")
+	}
+	if event.Retry > 0 {
+		buffer.WriteString("retry: " + strconv.FormatInt(event.Retry.Milliseconds(), 10) + "\n")
+	}
+	buffer.WriteString("data: ")
+	// The JSON encoder escapes line breaks in strings, so the data fits on a single line.
+	if err := encodeJSON(&buffer, event.Data, sortedKeys); err != nil {
+		return nil, err
+	}
+	buffer.WriteString("\n")
+
+	return buffer.Bytes(), nil
+}
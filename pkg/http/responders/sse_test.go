@@ -0,0 +1,150 @@
+package responders_test
+
+import (
+	"bufio"
+	goerrors "errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/responders"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestSSEResponder(t *testing.T) {
+	t.Parallel()
+
+	type requestParams struct {
+		Topic string `urlQuery:"topic" json:"-" validate:"required"`
+	}
+
+	type responseBody struct {
+		Message string `json:"message"`
+		Count   int    `json:"count"`
+	}
+
+	serve := func(t *testing.T, handler http.HandlerFunc, target string) *http.Response {
+		t.Helper()
+		server := httptest.NewServer(handler)
+		t.Cleanup(server.Close)
+		response, err := http.Get(server.URL + target)
+		assert.NoError(t, err)
+		t.Cleanup(func() {
+			_ = response.Body.Close()
+		})
+		return response
+	}
+
+	t.Run("when events are produced it should write them as an event stream", func(t *testing.T) {
+		t.Parallel()
+		response := serve(t, func(w http.ResponseWriter, r *http.Request) {
+			responders.SSE[requestParams, responseBody](w, r, func(params *requestParams, cancelChan <-chan struct{}) (<-chan *responders.SSEEvent[responseBody], int, error) {
+				ch := make(chan *responders.SSEEvent[responseBody])
+				go func() {
+					defer close(ch)
+					ch <- &responders.SSEEvent[responseBody]{ID: "1", Event: params.Topic, Retry: time.Second * 3, Data: &responseBody{Message: "line\nbreak", Count: 1}}
+					ch <- &responders.SSEEvent[responseBody]{Data: &responseBody{Message: "second", Count: 2}}
+				}()
+				return ch, http.StatusOK, nil
+			})
+		}, "/?topic=updates")
+		assert.Equals(t, response.StatusCode, http.StatusOK)
+		assert.Equals(t, response.Header.Get(headers.ContentType), headers.ContentTypeTextEventStream)
+		assert.Equals(t, response.Header.Get(headers.CacheControl), "no-cache")
+		body, err := io.ReadAll(response.Body)
+		assert.NoError(t, err)
+		assert.Equals(t, string(body), "id: 1\nevent: updates\nretry: 3000\ndata: {\"message\":\"line\\nbreak\",\"count\":1}\n\n"+
+			"data: {\"message\":\"second\",\"count\":2}\n\n")
+	})
+
+	t.Run("when sorted keys are enabled it should sort the keys of the event data", func(t *testing.T) {
+		t.Parallel()
+		response := serve(t, func(w http.ResponseWriter, r *http.Request) {
+			responders.SSE[requestParams, responseBody](w, r, func(params *requestParams, cancelChan <-chan struct{}) (<-chan *responders.SSEEvent[responseBody], int, error) {
+				ch := make(chan *responders.SSEEvent[responseBody], 1)
+				ch <- &responders.SSEEvent[responseBody]{Data: &responseBody{Message: "sorted", Count: 1}}
+				close(ch)
+				return ch, http.StatusOK, nil
+			}, responders.WithSortedKeys())
+		}, "/?topic=updates")
+		body, err := io.ReadAll(response.Body)
+		assert.NoError(t, err)
+		assert.Equals(t, string(body), "data: {\"count\":1,\"message\":\"sorted\"}\n\n")
+	})
+
+	t.Run("when the client disconnects it should close the cancel channel", func(t *testing.T) {
+		t.Parallel()
+		cancelled := make(chan struct{})
+		response := serve(t, func(w http.ResponseWriter, r *http.Request) {
+			responders.SSE[requestParams, responseBody](w, r, func(params *requestParams, cancelChan <-chan struct{}) (<-chan *responders.SSEEvent[responseBody], int, error) {
+				ch := make(chan *responders.SSEEvent[responseBody])
+				go func() {
+					defer close(ch)
+					for count := 0; ; count++ {
+						select {
+						case <-cancelChan:
+							close(cancelled)
+							return
+						case ch <- &responders.SSEEvent[responseBody]{Data: &responseBody{Count: count}}:
+						}
+					}
+				}()
+				return ch, http.StatusOK, nil
+			})
+		}, "/?topic=updates")
+		line, err := bufio.NewReader(response.Body).ReadString('\n')
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(line, "data: "))
+		assert.NoError(t, response.Body.Close())
+		select {
+		case <-cancelled:
+		case <-time.After(time.Second * 10):
+			t.Fatal("the producer was not cancelled")
+		}
+	})
+
+	t.Run("when an event has a line break in its name it should stop the stream", func(t *testing.T) {
+		t.Parallel()
+		response := serve(t, func(w http.ResponseWriter, r *http.Request) {
+			responders.SSE[requestParams, responseBody](w, r, func(params *requestParams, cancelChan <-chan struct{}) (<-chan *responders.SSEEvent[responseBody], int, error) {
+				ch := make(chan *responders.SSEEvent[responseBody], 2)
+				ch <- &responders.SSEEvent[responseBody]{Event: "bad\nname", Data: &responseBody{}}
+				ch <- &responders.SSEEvent[responseBody]{Data: &responseBody{}}
+				close(ch)
+				return ch, http.StatusOK, nil
+			})
+		}, "/?topic=updates")
+		body, err := io.ReadAll(response.Body)
+		assert.NoError(t, err)
+		assert.Equals(t, len(body), 0)
+	})
+
+	t.Run("when the parameters are invalid it should respond with a bad request", func(t *testing.T) {
+		t.Parallel()
+		response := serve(t, func(w http.ResponseWriter, r *http.Request) {
+			responders.SSE[requestParams, responseBody](w, r, func(params *requestParams, cancelChan <-chan struct{}) (<-chan *responders.SSEEvent[responseBody], int, error) {
+				t.Error("the callback should not be called")
+				return nil, 0, nil
+			})
+		}, "/")
+		assert.Equals(t, response.StatusCode, http.StatusBadRequest)
+	})
+
+	t.Run("when the callback returns an error it should respond with the error", func(t *testing.T) {
+		t.Parallel()
+		response := serve(t, func(w http.ResponseWriter, r *http.Request) {
+			responders.SSE[requestParams, responseBody](w, r, func(params *requestParams, cancelChan <-chan struct{}) (<-chan *responders.SSEEvent[responseBody], int, error) {
+				return nil, 0, &errors.BadRequest{Err: goerrors.New("unknown topic")}
+			})
+		}, "/?topic=updates")
+		assert.Equals(t, response.StatusCode, http.StatusBadRequest)
+		body, err := io.ReadAll(response.Body)
+		assert.NoError(t, err)
+		assert.Contains(t, string(body), "unknown topic")
+	})
+}
@@ -2,16 +2,14 @@ package responders
 
 import (
 	"net/http"
-
-	"github.com/TriangleSide/GoBase/pkg/http/errors"
-	"github.com/TriangleSide/GoBase/pkg/http/parameters"
 )
 
 // Status responds to an HTTP request with a status but no response body.
-func Status[RequestParameters any](writer http.ResponseWriter, request *http.Request, callback func(*RequestParameters) (int, error)) {
-	requestParams, err := parameters.Decode[RequestParameters](request)
-	if err != nil {
-		Error(request, writer, &errors.BadRequest{Err: err})
+func Status[RequestParameters any](writer http.ResponseWriter, request *http.Request, callback func(*RequestParameters) (int, error), options ...Option) {
+	cfg := newConfig(options...)
+
+	requestParams, ok := decodeParameters[RequestParameters](writer, request, cfg)
+	if !ok {
 		return
 	}
 
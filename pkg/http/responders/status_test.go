@@ -77,4 +77,36 @@ func TestStatus(t *testing.T) {
 		assert.Equals(t, responseBody.Message, "invalid parameters")
 		assert.NoError(t, response.Body.Close())
 	})
+
+	t.Run("when a validation failure status is configured and validation fails it should respond with that status", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			responders.Status[requestParams](w, r, statusHandler, responders.WithValidationFailureStatus(http.StatusUnprocessableEntity))
+		}))
+		defer server.Close()
+
+		response, err := http.Post(server.URL, headers.ContentTypeApplicationJson, strings.NewReader(`{"id":-1}`))
+		assert.NoError(t, err)
+		assert.Equals(t, response.StatusCode, http.StatusUnprocessableEntity)
+
+		responseBody := &errors.Error{}
+		assert.NoError(t, json.NewDecoder(response.Body).Decode(responseBody))
+		assert.True(t, strings.Contains(responseBody.Message, "validation failed on field 'ID'"))
+		assert.NoError(t, response.Body.Close())
+	})
+
+	t.Run("when a validation failure status is configured and the body is malformed it should respond with a bad request", func(t *testing.T) {
+		t.Parallel()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			responders.Status[requestParams](w, r, statusHandler, responders.WithValidationFailureStatus(http.StatusUnprocessableEntity))
+		}))
+		defer server.Close()
+
+		response, err := http.Post(server.URL, headers.ContentTypeApplicationJson, strings.NewReader(`{"id":`))
+		assert.NoError(t, err)
+		assert.Equals(t, response.StatusCode, http.StatusBadRequest)
+		assert.NoError(t, response.Body.Close())
+	})
 }
@@ -0,0 +1,48 @@
+package responders
+
+import (
+	"errors"
+)
+
+var (
+	// errStreamLimitReached is returned to the client when the StreamLimiter has no free slots.
+	errStreamLimitReached = errors.New("the maximum number of concurrent streams has been reached")
+)
+
+// StreamLimiter caps the number of streaming responses that can be active at the same time.
+// Streaming responses hold a connection and goroutines open for a long time, so a StreamLimiter
+// protects the server from exhausting its resources independently of other request limits.
+// A single StreamLimiter can be shared by many streaming endpoints.
+type StreamLimiter struct {
+	slots chan struct{}
+}
+
+// NewStreamLimiter allocates a StreamLimiter that allows up to maxStreams concurrent streams.
+func NewStreamLimiter(maxStreams int) *StreamLimiter {
+	if maxStreams <= 0 {
+		panic("the maximum number of concurrent streams must be greater than zero")
+	}
+	return &StreamLimiter{
+		slots: make(chan struct{}, maxStreams),
+	}
+}
+
+// Active returns the number of streams that are currently active.
+func (l *StreamLimiter) Active() int {
+	return len(l.slots)
+}
+
+// tryAcquire reserves a slot for a stream. It returns false if all the slots are taken.
+func (l *StreamLimiter) tryAcquire() bool {
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// release frees a slot reserved with tryAcquire.
+func (l *StreamLimiter) release() {
+	<-l.slots
+}
@@ -0,0 +1,87 @@
+package responders_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/http/responders"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestStreamLimiter(t *testing.T) {
+	t.Parallel()
+
+	type responseBody struct {
+		Message string `json:"message"`
+	}
+
+	t.Run("when the maximum number of streams is zero it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			responders.NewStreamLimiter(0)
+		}, "the maximum number of concurrent streams must be greater than zero")
+	})
+
+	t.Run("when more streams than the cap are opened it should refuse the last one until a stream closes", func(t *testing.T) {
+		t.Parallel()
+		const maxStreams = 2
+		limiter := responders.NewStreamLimiter(maxStreams)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			responders.JSONStream[struct{}, responseBody](w, r, func(params *struct{}, cancelChan <-chan struct{}) (<-chan *responseBody, int, error) {
+				ch := make(chan *responseBody)
+				go func() {
+					defer close(ch)
+					select {
+					case ch <- &responseBody{Message: "open"}:
+					case <-cancelChan:
+						return
+					}
+					<-cancelChan
+				}()
+				return ch, http.StatusOK, nil
+			}, responders.WithStreamLimiter(limiter))
+		}))
+		t.Cleanup(server.Close)
+
+		openStream := func() *http.Response {
+			response, err := http.Get(server.URL)
+			assert.NoError(t, err)
+			return response
+		}
+
+		openResponses := make([]*http.Response, 0, maxStreams)
+		for i := 0; i < maxStreams; i++ {
+			response := openStream()
+			assert.Equals(t, response.StatusCode, http.StatusOK)
+			responseObj := &responseBody{}
+			assert.NoError(t, json.NewDecoder(response.Body).Decode(responseObj))
+			assert.Equals(t, responseObj.Message, "open")
+			openResponses = append(openResponses, response)
+		}
+		assert.Equals(t, limiter.Active(), maxStreams)
+
+		refusedResponse := openStream()
+		assert.Equals(t, refusedResponse.StatusCode, http.StatusServiceUnavailable)
+		errResponse := &errors.Error{}
+		assert.NoError(t, json.NewDecoder(refusedResponse.Body).Decode(errResponse))
+		assert.Equals(t, errResponse.Message, "the maximum number of concurrent streams has been reached")
+		assert.NoError(t, refusedResponse.Body.Close())
+
+		for _, response := range openResponses {
+			assert.NoError(t, response.Body.Close())
+		}
+		server.CloseClientConnections()
+		for limiter.Active() != 0 {
+			runtime.Gosched()
+		}
+
+		acceptedResponse := openStream()
+		assert.Equals(t, acceptedResponse.StatusCode, http.StatusOK)
+		assert.NoError(t, acceptedResponse.Body.Close())
+	})
+}
@@ -0,0 +1,32 @@
+package responders
+
+import (
+	"sync/atomic"
+)
+
+var (
+	// activeStreams is the number of streaming responders that are currently writing a response.
+	activeStreams atomic.Int64
+
+	// activeDeferredConsumers is the number of goroutines draining a producer after its stream ended.
+	activeDeferredConsumers atomic.Int64
+)
+
+// StreamStats is a snapshot of the goroutines used by the streaming responders.
+type StreamStats struct {
+	// ActiveStreams is the number of streaming responses that are in progress.
+	ActiveStreams int64
+
+	// ActiveDeferredConsumers is the number of goroutines consuming the responses of a producer after
+	// its stream ended. A count that keeps growing means producers are not closing their channels.
+	ActiveDeferredConsumers int64
+}
+
+// Stats returns the current StreamStats. The counts are decremented with defer so they are
+// accurate even when a stream ends because of a panic or because the client disconnected.
+func Stats() StreamStats {
+	return StreamStats{
+		ActiveStreams:           activeStreams.Load(),
+		ActiveDeferredConsumers: activeDeferredConsumers.Load(),
+	}
+}
@@ -0,0 +1,76 @@
+package responders_test
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/http/responders"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestStats(t *testing.T) {
+	type requestParams struct{}
+
+	type responseBody struct {
+		Value int `json:"value"`
+	}
+
+	waitForZero := func(t *testing.T) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for responders.Stats() != (responders.StreamStats{}) {
+			if time.Now().After(deadline) {
+				t.Fatalf("the stream stats did not return to zero (%+v)", responders.Stats())
+			}
+			time.Sleep(time.Millisecond * 10)
+		}
+	}
+
+	t.Run("when clients disconnect in the middle of a stream it should return the counts to zero", func(t *testing.T) {
+		waitForZero(t)
+
+		const clients = 5
+		server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			responders.JSONStream[requestParams, responseBody](writer, request, func(params *requestParams, cancel <-chan struct{}) (<-chan *responseBody, int, error) {
+				responseChan := make(chan *responseBody)
+				go func() {
+					defer close(responseChan)
+					for i := 0; ; i++ {
+						select {
+						case <-cancel:
+							return
+						case responseChan <- &responseBody{Value: i}:
+						}
+					}
+				}()
+				return responseChan, http.StatusOK, nil
+			})
+		}))
+		defer server.Close()
+
+		for i := 0; i < clients; i++ {
+			response, err := http.Get(server.URL)
+			assert.NoError(t, err)
+			_, err = bufio.NewReader(response.Body).ReadString('\n')
+			assert.NoError(t, err)
+			assert.True(t, responders.Stats().ActiveStreams > 0)
+			assert.NoError(t, response.Body.Close())
+		}
+
+		waitForZero(t)
+	})
+
+	t.Run("when the callback panics it should return the counts to zero", func(t *testing.T) {
+		waitForZero(t)
+		assert.PanicExact(t, func() {
+			responders.JSONStream[requestParams, responseBody](httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil), func(params *requestParams, cancel <-chan struct{}) (<-chan *responseBody, int, error) {
+				assert.Equals(t, responders.Stats().ActiveStreams, int64(1))
+				panic("callback panic")
+			})
+		}, "callback panic")
+		waitForZero(t)
+	})
+}
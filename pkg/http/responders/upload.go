@@ -0,0 +1,168 @@
+package responders
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/parameters"
+	"github.com/TriangleSide/GoBase/pkg/logger"
+	"github.com/TriangleSide/GoBase/pkg/validation"
+)
+
+// Upload responds to an HTTP request whose body is streamed to the callback instead of being decoded, such as a large
+// binary file. The request parameters are decoded from the query, headers, path, and cookies, but not from the body.
+// The body stops being readable once the request context is cancelled. The response is encoded as JSON.
+//
+// If the callback fails with an error from reading a body that exceeds the limit of an http.MaxBytesReader, or that
+// is not read before the read deadline, the responder replies with an HTTP 413 request entity too large and an HTTP 408
+// request timeout respectively.
+func Upload[RequestParameters any, ResponseBody any](writer http.ResponseWriter, request *http.Request, callback func(requestParameters *RequestParameters, body io.Reader) (*ResponseBody, int, error), options ...Option) {
+	cfg := newConfig(options...)
+
+	requestParams, ok := decodeParametersWithoutBody[RequestParameters](writer, request, cfg)
+	if !ok {
+		return
+	}
+
+	var body io.Reader = http.NoBody
+	if request.Body != nil {
+		defer closeRequestBody(request)
+		body = &contextReader{ctx: request.Context(), reader: request.Body}
+	}
+
+	response, status, err := callback(requestParams, body)
+	if err != nil {
+		if bodyErr := bodyReadError(err); bodyErr != nil {
+			err = bodyErr
+		}
+		Error(request, writer, err)
+		return
+	}
+
+	writeUploadResponse(writer, request, response, status, cfg)
+}
+
+// JSONLinesUpload responds to an HTTP request whose body is a stream of newline-delimited JSON values. The values are
+// decoded one at a time and sent on the line stream, so the callback can process uploads that are too large to be
+// buffered. Lines that are structs are validated. The request parameters are decoded from the query, headers, path,
+// and cookies, but not from the body. The response is encoded as JSON.
+//
+// The line stream is closed once the body is fully read, or when a line cannot be decoded or fails validation. In the
+// latter case, the responder replies with an HTTP 400 bad request instead of the response of the callback. The callback
+// can return before the line stream is closed, in which case the rest of the body is not read.
+func JSONLinesUpload[RequestParameters any, Line any, ResponseBody any](writer http.ResponseWriter, request *http.Request, callback func(requestParameters *RequestParameters, lineStream <-chan *Line) (*ResponseBody, int, error), options ...Option) {
+	cfg := newConfig(options...)
+
+	requestParams, ok := decodeParametersWithoutBody[RequestParameters](writer, request, cfg)
+	if !ok {
+		return
+	}
+
+	var body io.Reader = http.NoBody
+	if request.Body != nil {
+		body = &contextReader{ctx: request.Context(), reader: request.Body}
+	}
+
+	cancelChan := make(chan struct{})
+	lineChan := make(chan *Line)
+	decodeDone := make(chan struct{})
+	var decodeErr error
+	go func() {
+		defer close(decodeDone)
+		defer close(lineChan)
+		decodeErr = decodeJSONLines(body, cancelChan, lineChan)
+	}()
+
+	response, status, err := callback(requestParams, lineChan)
+
+	// Closing the server body blocks until a pending read returns, so the read deadline is expired first to unblock
+	// the decoder if it is waiting for the client to send more data.
+	close(cancelChan)
+	select {
+	case <-decodeDone:
+	default:
+		if err := http.NewResponseController(writer).SetReadDeadline(time.Now()); err != nil {
+			logger.Debugf(request.Context(), "Failed to set the read deadline of the request (%s).", err)
+		}
+	}
+	if request.Body != nil {
+		closeRequestBody(request)
+	}
+	<-decodeDone
+
+	if err != nil {
+		Error(request, writer, err)
+		return
+	}
+	if decodeErr != nil {
+		writeDecodeError(writer, request, cfg, decodeErr)
+		return
+	}
+
+	writeUploadResponse(writer, request, response, status, cfg)
+}
+
+// decodeJSONLines decodes the JSON values of the reader and sends them on the channel until the reader is exhausted.
+// It returns nil without reporting the error if the cancel channel is closed.
+func decodeJSONLines[Line any](reader io.Reader, cancelChan <-chan struct{}, lineChan chan<- *Line) error {
+	decoder := json.NewDecoder(reader)
+	decoder.DisallowUnknownFields()
+	isStruct := reflect.TypeFor[Line]().Kind() == reflect.Struct
+	for lineNumber := 1; ; lineNumber++ {
+		line := new(Line)
+		if err := decoder.Decode(line); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			select {
+			case <-cancelChan:
+				return nil
+			default:
+				return fmt.Errorf("failed to decode line %d (%w)", lineNumber, err)
+			}
+		}
+		if isStruct {
+			if err := validation.Struct(line); err != nil {
+				return fmt.Errorf("validation failed for line %d (%w)", lineNumber, err)
+			}
+		}
+		select {
+		case <-cancelChan:
+			return nil
+		case lineChan <- line:
+		}
+	}
+}
+
+// decodeParametersWithoutBody is like decodeParameters, but leaves the request body unread.
+func decodeParametersWithoutBody[RequestParameters any](writer http.ResponseWriter, request *http.Request, cfg *config) (*RequestParameters, bool) {
+	requestParams, err := parameters.DecodeWithoutBody[RequestParameters](request)
+	if err != nil {
+		writeDecodeError(writer, request, cfg, err)
+		return nil, false
+	}
+	return requestParams, true
+}
+
+// closeRequestBody closes the body of the request, logging the error if it fails.
+func closeRequestBody(request *http.Request) {
+	if err := request.Body.Close(); err != nil {
+		logger.Errorf(request.Context(), "Failed to close the request body (%s).", err)
+	}
+}
+
+// writeUploadResponse writes the response of an upload responder as JSON.
+func writeUploadResponse(writer http.ResponseWriter, request *http.Request, response any, status int, cfg *config) {
+	setCookies(writer, response, cfg)
+	writer.Header().Set(headers.ContentType, headers.ContentTypeApplicationJson)
+	writer.WriteHeader(status)
+	if err := encodeJSON(writer, response, cfg.sortedKeys); err != nil {
+		logger.Errorf(request.Context(), "Failed to encode response (%s).", err)
+	}
+}
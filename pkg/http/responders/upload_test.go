@@ -0,0 +1,188 @@
+package responders_test
+
+import (
+	"context"
+	"encoding/json"
+	goerrors "errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/responders"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestUploadResponder(t *testing.T) {
+	t.Parallel()
+
+	type uploadParams struct {
+		Name string `urlQuery:"name" json:"-" validate:"required"`
+	}
+
+	type uploadResponse struct {
+		Name string `json:"name"`
+		Size int64  `json:"size"`
+	}
+
+	uploadCallback := func(params *uploadParams, body io.Reader) (*uploadResponse, int, error) {
+		size, err := io.Copy(io.Discard, body)
+		if err != nil {
+			return nil, 0, err
+		}
+		return &uploadResponse{Name: params.Name, Size: size}, http.StatusCreated, nil
+	}
+
+	t.Run("when a body is uploaded it should be streamed to the callback", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, "/?name=file", strings.NewReader(strings.Repeat("a", 1000)))
+		request.Header.Set(headers.ContentType, headers.ContentTypeApplicationJson)
+		responders.Upload(recorder, request, uploadCallback)
+		assert.Equals(t, recorder.Code, http.StatusCreated)
+		response := &uploadResponse{}
+		assert.NoError(t, json.NewDecoder(recorder.Body).Decode(response))
+		assert.Equals(t, *response, uploadResponse{Name: "file", Size: 1000})
+	})
+
+	t.Run("when the parameters fail validation it should respond with bad request", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("data"))
+		responders.Upload(recorder, request, uploadCallback)
+		assert.Equals(t, recorder.Code, http.StatusBadRequest)
+	})
+
+	t.Run("when the body exceeds the maximum size it should respond with request entity too large", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, "/?name=file", strings.NewReader(strings.Repeat("a", 100)))
+		request.Body = http.MaxBytesReader(recorder, request.Body, 10)
+		responders.Upload(recorder, request, uploadCallback)
+		assert.Equals(t, recorder.Code, http.StatusRequestEntityTooLarge)
+	})
+
+	t.Run("when the callback returns an error it should respond with the error", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, "/?name=file", strings.NewReader("data"))
+		responders.Upload(recorder, request, func(*uploadParams, io.Reader) (*uploadResponse, int, error) {
+			return nil, 0, &errors.BadRequest{Err: goerrors.New("bad upload")}
+		})
+		assert.Equals(t, recorder.Code, http.StatusBadRequest)
+	})
+}
+
+func TestJSONLinesUploadResponder(t *testing.T) {
+	t.Parallel()
+
+	type line struct {
+		Value int `json:"value" validate:"gt=0"`
+	}
+
+	type summary struct {
+		Count int `json:"count"`
+		Sum   int `json:"sum"`
+	}
+
+	sumCallback := func(_ *struct{}, lineStream <-chan *line) (*summary, int, error) {
+		result := &summary{}
+		for l := range lineStream {
+			result.Count++
+			result.Sum += l.Value
+		}
+		return result, http.StatusOK, nil
+	}
+
+	upload := func(body string, callback func(*struct{}, <-chan *line) (*summary, int, error)) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		request.Header.Set(headers.ContentType, headers.ContentTypeApplicationNDJSON)
+		responders.JSONLinesUpload(recorder, request, callback)
+		return recorder
+	}
+
+	t.Run("when lines are uploaded it should send each decoded line to the callback", func(t *testing.T) {
+		t.Parallel()
+		recorder := upload("{\"value\":1}\n{\"value\":2}\n\n{\"value\":3}\n", sumCallback)
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		response := &summary{}
+		assert.NoError(t, json.NewDecoder(recorder.Body).Decode(response))
+		assert.Equals(t, *response, summary{Count: 3, Sum: 6})
+	})
+
+	t.Run("when the body is empty it should close the line stream without lines", func(t *testing.T) {
+		t.Parallel()
+		recorder := upload("", sumCallback)
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		response := &summary{}
+		assert.NoError(t, json.NewDecoder(recorder.Body).Decode(response))
+		assert.Equals(t, *response, summary{})
+	})
+
+	t.Run("when a line is malformed it should respond with bad request", func(t *testing.T) {
+		t.Parallel()
+		recorder := upload("{\"value\":1}\n{\"value\":\n", sumCallback)
+		assert.Equals(t, recorder.Code, http.StatusBadRequest)
+		assert.True(t, strings.Contains(recorder.Body.String(), "failed to decode line 2"))
+	})
+
+	t.Run("when a line fails validation it should respond with bad request", func(t *testing.T) {
+		t.Parallel()
+		recorder := upload("{\"value\":1}\n{\"value\":0}\n", sumCallback)
+		assert.Equals(t, recorder.Code, http.StatusBadRequest)
+		assert.True(t, strings.Contains(recorder.Body.String(), "validation failed for line 2"))
+	})
+
+	t.Run("when the callback returns before the stream is closed it should stop reading the body", func(t *testing.T) {
+		t.Parallel()
+		recorder := upload(strings.Repeat("{\"value\":1}\n", 100), func(_ *struct{}, lineStream <-chan *line) (*summary, int, error) {
+			<-lineStream
+			return &summary{Count: 1}, http.StatusAccepted, nil
+		})
+		assert.Equals(t, recorder.Code, http.StatusAccepted)
+	})
+
+	t.Run("when the callback returns while the client stops sending the body it should respond", func(t *testing.T) {
+		t.Parallel()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			responders.JSONLinesUpload(w, r, func(_ *struct{}, lineStream <-chan *line) (*summary, int, error) {
+				<-lineStream
+				return &summary{Count: 1}, http.StatusAccepted, nil
+			})
+		}))
+		t.Cleanup(server.Close)
+
+		bodyReader, bodyWriter := io.Pipe()
+		t.Cleanup(func() {
+			assert.NoError(t, bodyWriter.Close())
+		})
+		go func() {
+			_, _ = bodyWriter.Write([]byte("{\"value\":1}\n"))
+		}()
+
+		ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+		t.Cleanup(cancel)
+		request, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, bodyReader)
+		assert.NoError(t, err)
+		request.Header.Set(headers.ContentType, headers.ContentTypeApplicationNDJSON)
+		response, err := server.Client().Do(request)
+		assert.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, response.Body.Close())
+		})
+		assert.Equals(t, response.StatusCode, http.StatusAccepted)
+	})
+
+	t.Run("when the callback returns an error it should respond with the error", func(t *testing.T) {
+		t.Parallel()
+		recorder := upload("{\"value\":1}\n", func(*struct{}, <-chan *line) (*summary, int, error) {
+			return nil, 0, &errors.Forbidden{Err: goerrors.New("forbidden")}
+		})
+		assert.Equals(t, recorder.Code, http.StatusForbidden)
+	})
+}
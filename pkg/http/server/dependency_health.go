@@ -0,0 +1,124 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/http/api"
+	httperrors "github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/middleware"
+	"github.com/TriangleSide/GoBase/pkg/http/responders"
+	"github.com/TriangleSide/GoBase/pkg/logger"
+)
+
+var (
+	// errDependencyUnhealthy is returned to the client when a request is shed.
+	errDependencyUnhealthy = errors.New("the service is temporarily unavailable")
+)
+
+// loadSheddingConfig is the configuration for rejecting requests when dependencies are unhealthy.
+type loadSheddingConfig struct {
+	retryAfter   time.Duration
+	guardedPaths []api.Path
+}
+
+// dependencyHealth tracks the health of the dependencies of the server.
+type dependencyHealth struct {
+	mu        sync.RWMutex
+	unhealthy map[string]struct{}
+}
+
+// newDependencyHealth allocates a dependencyHealth where all dependencies are healthy.
+func newDependencyHealth() *dependencyHealth {
+	return &dependencyHealth{
+		mu:        sync.RWMutex{},
+		unhealthy: make(map[string]struct{}),
+	}
+}
+
+// set marks a dependency as healthy or unhealthy.
+func (d *dependencyHealth) set(name string, healthy bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if healthy {
+		delete(d.unhealthy, name)
+	} else {
+		d.unhealthy[name] = struct{}{}
+	}
+}
+
+// allHealthy returns true if no dependencies are marked as unhealthy.
+func (d *dependencyHealth) allHealthy() bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return len(d.unhealthy) == 0
+}
+
+// WithLoadShedding makes the server respond with an HTTP 503 service unavailable on the guarded paths
+// while any dependency is unhealthy. The Retry-After header is set on the response to tell the client when
+// to try again. Dependencies are marked as unhealthy by Server.SetDependencyHealthy or WithDependencyCheck.
+func WithLoadShedding(retryAfter time.Duration, guardedPaths ...api.Path) Option {
+	if retryAfter < time.Second {
+		panic("the retry after duration must be at least one second")
+	}
+	return func(srvOpts *serverOptions) {
+		srvOpts.loadShedding = &loadSheddingConfig{
+			retryAfter:   retryAfter,
+			guardedPaths: guardedPaths,
+		}
+	}
+}
+
+// WithDependencyCheck registers a periodic task that checks the health of a dependency.
+// The dependency is marked as unhealthy when the check returns an error and healthy when it succeeds.
+// The check runs as soon as the server starts and then on the interval.
+func WithDependencyCheck(name string, interval time.Duration, check func(ctx context.Context) error, opts ...PeriodicTaskOption) Option {
+	if check == nil {
+		panic("the dependency check function cannot be nil")
+	}
+	taskOpts := make([]PeriodicTaskOption, 0, len(opts)+1)
+	taskOpts = append(taskOpts, WithPeriodicTaskRunOnStart())
+	taskOpts = append(taskOpts, opts...)
+	task := newPeriodicTask(name, interval, check, taskOpts...)
+	return func(srvOpts *serverOptions) {
+		dependencyTask := *task
+		dependencyTask.fn = func(ctx context.Context) error {
+			err := check(ctx)
+			srvOpts.dependencies.set(name, err == nil)
+			return err
+		}
+		srvOpts.periodicTasks = append(srvOpts.periodicTasks, &dependencyTask)
+	}
+}
+
+// SetDependencyHealthy marks a dependency as healthy or unhealthy.
+// While any dependency is unhealthy, the paths guarded by WithLoadShedding are rejected.
+func (server *Server) SetDependencyHealthy(name string, healthy bool) {
+	server.dependencies.set(name, healthy)
+}
+
+// loadSheddingMiddleware returns middleware that rejects the request if the path is guarded and a dependency is unhealthy.
+// If the path is not guarded, nil is returned.
+func loadSheddingMiddleware(cfg *loadSheddingConfig, dependencies *dependencyHealth, path api.Path) middleware.Middleware {
+	if cfg == nil || !slices.Contains(cfg.guardedPaths, path) {
+		return nil
+	}
+	retryAfterSeconds := strconv.Itoa(int(cfg.retryAfter.Seconds()))
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(writer http.ResponseWriter, request *http.Request) {
+			if !dependencies.allHealthy() {
+				logger.Debugf(request.Context(), "Shedding request on path '%s' because a dependency is unhealthy.", path)
+				writer.Header().Set(headers.RetryAfter, retryAfterSeconds)
+				responders.Error(request, writer, &httperrors.ServiceUnavailable{Err: errDependencyUnhealthy})
+				return
+			}
+			next(writer, request)
+		}
+	}
+}
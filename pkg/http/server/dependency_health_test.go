@@ -0,0 +1,131 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/config"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/server"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestDependencyHealth(t *testing.T) {
+	t.Setenv(string(config.HTTPServerTLSModeEnvName), string(config.HTTPServerTLSModeOff))
+
+	okHandler := func(path string) *testHandler {
+		return &testHandler{
+			Path:   path,
+			Method: http.MethodGet,
+			Handler: func(writer http.ResponseWriter, request *http.Request) {
+				writer.WriteHeader(http.StatusOK)
+			},
+		}
+	}
+
+	runServer := func(t *testing.T, options ...server.Option) (*server.Server, string) {
+		t.Helper()
+		waitUntilReady := make(chan bool)
+		var address string
+		allOpts := append(options, server.WithBoundCallback(func(addr *net.TCPAddr) {
+			address = addr.String()
+			close(waitUntilReady)
+		}), server.WithEndpointHandlers(okHandler("/guarded"), okHandler("/open")))
+		srv, err := server.New(allOpts...)
+		assert.NoError(t, err)
+		assert.NotNil(t, srv)
+		t.Cleanup(func() {
+			assert.NoError(t, srv.Shutdown(context.Background()))
+		})
+		go func() {
+			assert.NoError(t, srv.Run())
+		}()
+		<-waitUntilReady
+		return srv, address
+	}
+
+	get := func(t *testing.T, url string) *http.Response {
+		t.Helper()
+		response, err := http.Get(url)
+		assert.NoError(t, err)
+		assert.NoError(t, response.Body.Close())
+		return response
+	}
+
+	t.Run("when the retry after duration is less than a second it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			server.WithLoadShedding(time.Millisecond)
+		}, "the retry after duration must be at least one second")
+	})
+
+	t.Run("when the dependency check function is nil it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			server.WithDependencyCheck("db", time.Second, nil)
+		}, "the dependency check function cannot be nil")
+	})
+
+	t.Run("when the dependency check interval is not positive it should panic without applying the option", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			server.WithDependencyCheck("db", 0, func(ctx context.Context) error {
+				return nil
+			})
+		}, "the periodic task interval must be greater than zero")
+	})
+
+	t.Run("when the dependency check jitter is negative it should panic without applying the option", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			server.WithDependencyCheck("db", time.Second, func(ctx context.Context) error {
+				return nil
+			}, server.WithPeriodicTaskJitter(-time.Second))
+		}, "the periodic task jitter cannot be negative")
+	})
+
+	t.Run("when a dependency is marked unhealthy the guarded routes should return 503 until it recovers", func(t *testing.T) {
+		t.Parallel()
+		srv, address := runServer(t, server.WithLoadShedding(time.Second*5, "/guarded"))
+
+		assert.Equals(t, get(t, "http://"+address+"/guarded").StatusCode, http.StatusOK)
+
+		srv.SetDependencyHealthy("db", false)
+		response := get(t, "http://"+address+"/guarded")
+		assert.Equals(t, response.StatusCode, http.StatusServiceUnavailable)
+		assert.Equals(t, response.Header.Get(headers.RetryAfter), "5")
+		assert.Equals(t, get(t, "http://"+address+"/open").StatusCode, http.StatusOK)
+
+		srv.SetDependencyHealthy("db", true)
+		assert.Equals(t, get(t, "http://"+address+"/guarded").StatusCode, http.StatusOK)
+	})
+
+	t.Run("when load shedding is not configured an unhealthy dependency should not affect the routes", func(t *testing.T) {
+		t.Parallel()
+		srv, address := runServer(t)
+		srv.SetDependencyHealthy("db", false)
+		assert.Equals(t, get(t, "http://"+address+"/guarded").StatusCode, http.StatusOK)
+	})
+
+	t.Run("when a dependency check fails it should shed requests on the guarded routes", func(t *testing.T) {
+		t.Parallel()
+		checked := make(chan struct{})
+		_, address := runServer(t, server.WithLoadShedding(time.Second, "/guarded"), server.WithDependencyCheck("db", time.Hour, func(ctx context.Context) error {
+			defer close(checked)
+			return errors.New("db is down")
+		}))
+		<-checked
+		deadline := time.Now().Add(time.Second * 5)
+		for get(t, "http://"+address+"/guarded").StatusCode != http.StatusServiceUnavailable {
+			if time.Now().After(deadline) {
+				t.Fatal("The guarded route was not shed after the dependency check failed.")
+			}
+			time.Sleep(time.Millisecond)
+		}
+		assert.Equals(t, get(t, "http://"+address+"/open").StatusCode, http.StatusOK)
+	})
+}
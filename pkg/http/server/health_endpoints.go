@@ -0,0 +1,57 @@
+package server
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/TriangleSide/GoBase/pkg/health"
+	"github.com/TriangleSide/GoBase/pkg/http/api"
+)
+
+// healthEndpoint is a GET endpoint that reports the checks of a health.Registry.
+type healthEndpoint struct {
+	path     api.Path
+	registry *health.Registry
+}
+
+// WithLivenessEndpoint serves the JSON report of the checks of the registry on a GET endpoint at the path, like
+// /healthz. The liveness checks should only fail when the process cannot recover by itself and must be restarted.
+// The checks of the registry that have an interval run in the background while the server is running.
+func WithLivenessEndpoint(path api.Path, registry *health.Registry) Option {
+	return withHealthEndpoint(path, registry)
+}
+
+// WithReadinessEndpoint serves the JSON report of the checks of the registry on a GET endpoint at the path, like
+// /readyz. The readiness checks fail when the server cannot serve requests, like when a database is unreachable,
+// so it is taken out of the load balancer until they pass. The checks of the registry that have an interval run
+// in the background while the server is running.
+func WithReadinessEndpoint(path api.Path, registry *health.Registry) Option {
+	return withHealthEndpoint(path, registry)
+}
+
+// withHealthEndpoint adds the endpoint of the registry. If the registry is nil, this function panics.
+func withHealthEndpoint(path api.Path, registry *health.Registry) Option {
+	if registry == nil {
+		panic("the health registry cannot be nil")
+	}
+	return func(srvOpts *serverOptions) {
+		srvOpts.healthEndpoints = append(srvOpts.healthEndpoints, &healthEndpoint{
+			path:     path,
+			registry: registry,
+		})
+	}
+}
+
+// registerHealthEndpoints registers the routes of the health endpoints and returns their distinct registries.
+func registerHealthEndpoints(builder *api.HTTPAPIBuilder, endpoints []*healthEndpoint) []*health.Registry {
+	registries := make([]*health.Registry, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		builder.MustRegister(endpoint.path, http.MethodGet, &api.Handler{
+			Handler: endpoint.registry.Handler(),
+		})
+		if !slices.Contains(registries, endpoint.registry) {
+			registries = append(registries, endpoint.registry)
+		}
+	}
+	return registries
+}
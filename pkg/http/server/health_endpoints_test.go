@@ -0,0 +1,100 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/config"
+	"github.com/TriangleSide/GoBase/pkg/health"
+	"github.com/TriangleSide/GoBase/pkg/http/server"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestHealthEndpoints(t *testing.T) {
+	t.Setenv(string(config.HTTPServerTLSModeEnvName), string(config.HTTPServerTLSModeOff))
+
+	runServer := func(t *testing.T, options ...server.Option) string {
+		t.Helper()
+		waitUntilReady := make(chan bool)
+		var address string
+		allOpts := append(options, server.WithBoundCallback(func(addr *net.TCPAddr) {
+			address = addr.String()
+			close(waitUntilReady)
+		}))
+		srv, err := server.New(allOpts...)
+		assert.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, srv.Shutdown(context.Background()))
+		})
+		go func() {
+			assert.NoError(t, srv.Run())
+		}()
+		<-waitUntilReady
+		return "http://" + address
+	}
+
+	getReport := func(t *testing.T, url string) (int, *health.Report) {
+		t.Helper()
+		response, err := http.Get(url)
+		assert.NoError(t, err)
+		defer func() {
+			assert.NoError(t, response.Body.Close())
+		}()
+		report := &health.Report{}
+		assert.NoError(t, json.NewDecoder(response.Body).Decode(report))
+		return response.StatusCode, report
+	}
+
+	t.Run("when the health endpoints are enabled it should report the checks of their registries", func(t *testing.T) {
+		liveness := health.NewRegistry()
+		liveness.MustRegister("process", func(context.Context) error { return nil })
+		var databaseUp atomic.Bool
+		readiness := health.NewRegistry()
+		readiness.MustRegister("database", func(context.Context) error {
+			if !databaseUp.Load() {
+				return errors.New("connection refused")
+			}
+			return nil
+		})
+		var cacheChecks atomic.Int32
+		readiness.MustRegister("cache", func(context.Context) error {
+			cacheChecks.Add(1)
+			return nil
+		}, health.WithInterval(time.Millisecond*10))
+
+		baseURL := runServer(t,
+			server.WithLivenessEndpoint("/healthz", liveness),
+			server.WithReadinessEndpoint("/readyz", readiness))
+
+		status, report := getReport(t, baseURL+"/healthz")
+		assert.Equals(t, status, http.StatusOK)
+		assert.Equals(t, report.Status, health.StatusHealthy)
+		assert.Equals(t, report.Checks["process"].Status, health.StatusHealthy)
+
+		status, report = getReport(t, baseURL+"/readyz")
+		assert.Equals(t, status, http.StatusServiceUnavailable)
+		assert.Equals(t, report.Checks["database"].Status, health.StatusUnhealthy)
+		assert.Equals(t, report.Checks["database"].Error, "connection refused")
+
+		databaseUp.Store(true)
+		for status != http.StatusOK {
+			time.Sleep(time.Millisecond)
+			status, report = getReport(t, baseURL+"/readyz")
+		}
+		assert.Equals(t, report.Checks["database"].Status, health.StatusHealthy)
+		assert.Equals(t, report.Checks["cache"].Status, health.StatusHealthy)
+		assert.True(t, cacheChecks.Load() > 0)
+	})
+
+	t.Run("when the health registry is nil it should panic", func(t *testing.T) {
+		assert.PanicExact(t, func() {
+			server.WithReadinessEndpoint("/readyz", nil)
+		}, "the health registry cannot be nil")
+	})
+}
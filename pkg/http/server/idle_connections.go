@@ -0,0 +1,53 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"sync"
+)
+
+// idleConnections tracks the connections of the server that are idle between keep-alive requests.
+type idleConnections struct {
+	lock  sync.Mutex
+	conns map[net.Conn]struct{}
+}
+
+// newIdleConnections allocates an idleConnections with no tracked connections.
+func newIdleConnections() *idleConnections {
+	return &idleConnections{
+		conns: make(map[net.Conn]struct{}),
+	}
+}
+
+// track is the http.Server ConnState hook. It records whether a connection is idle.
+func (idle *idleConnections) track(conn net.Conn, state http.ConnState) {
+	idle.lock.Lock()
+	defer idle.lock.Unlock()
+	if state == http.StateIdle {
+		idle.conns[conn] = struct{}{}
+	} else {
+		delete(idle.conns, conn)
+	}
+}
+
+// closeAll closes the connections that are idle and returns how many were closed.
+func (idle *idleConnections) closeAll() int {
+	idle.lock.Lock()
+	defer idle.lock.Unlock()
+	closed := 0
+	for conn := range idle.conns {
+		if conn.Close() == nil {
+			closed++
+		}
+		delete(idle.conns, conn)
+	}
+	return closed
+}
+
+// CloseIdleConnections closes the keep-alive connections that are not serving a request and returns how many were closed.
+// Connections with an active request are not affected and keep serving. The server continues to accept connections,
+// so clients reconnect on their next request. This can be used to rebalance clients across instances or make them
+// resolve the address of the server again without shutting it down.
+func (server *Server) CloseIdleConnections() int {
+	return server.idleConns.closeAll()
+}
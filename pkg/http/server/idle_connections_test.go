@@ -0,0 +1,101 @@
+package server_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/config"
+	"github.com/TriangleSide/GoBase/pkg/http/server"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestCloseIdleConnections(t *testing.T) {
+	t.Setenv(string(config.HTTPServerTLSModeEnvName), string(config.HTTPServerTLSModeOff))
+
+	slowStarted := make(chan struct{})
+	releaseSlow := make(chan struct{})
+
+	waitUntilReady := make(chan bool)
+	var address string
+	srv, err := server.New(server.WithBoundCallback(func(addr *net.TCPAddr) {
+		address = addr.String()
+		close(waitUntilReady)
+	}), server.WithEndpointHandlers(
+		&testHandler{
+			Path:   "/fast",
+			Method: http.MethodGet,
+			Handler: func(writer http.ResponseWriter, request *http.Request) {
+				writer.WriteHeader(http.StatusOK)
+			},
+		},
+		&testHandler{
+			Path:   "/slow",
+			Method: http.MethodGet,
+			Handler: func(writer http.ResponseWriter, request *http.Request) {
+				close(slowStarted)
+				<-releaseSlow
+				writer.WriteHeader(http.StatusOK)
+			},
+		},
+	))
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, srv.Shutdown(context.Background()))
+	})
+	go func() {
+		assert.NoError(t, srv.Run())
+	}()
+	<-waitUntilReady
+
+	sendRequest := func(conn net.Conn, path string) {
+		_, err := fmt.Fprintf(conn, "GET %s HTTP/1.1\r\nHost: %s\r\n\r\n", path, address)
+		assert.NoError(t, err)
+	}
+
+	readResponse := func(reader *bufio.Reader) {
+		response, err := http.ReadResponse(reader, nil)
+		assert.NoError(t, err)
+		assert.Equals(t, response.StatusCode, http.StatusOK)
+		assert.NoError(t, response.Body.Close())
+	}
+
+	idleConn, err := net.Dial("tcp", address)
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		_ = idleConn.Close()
+	})
+	idleReader := bufio.NewReader(idleConn)
+	sendRequest(idleConn, "/fast")
+	readResponse(idleReader)
+
+	activeConn, err := net.Dial("tcp", address)
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		_ = activeConn.Close()
+	})
+	sendRequest(activeConn, "/slow")
+	<-slowStarted
+
+	// The server marks the connection as idle after the response is written, so it may not be idle yet.
+	closed := 0
+	for deadline := time.Now().Add(time.Second * 5); closed == 0 && time.Now().Before(deadline); {
+		closed = srv.CloseIdleConnections()
+		if closed == 0 {
+			time.Sleep(time.Millisecond * 10)
+		}
+	}
+	assert.Equals(t, closed, 1)
+
+	assert.NoError(t, idleConn.SetReadDeadline(time.Now().Add(time.Second*5)))
+	_, err = idleReader.ReadByte()
+	assert.ErrorExact(t, err, io.EOF.Error())
+
+	close(releaseSlow)
+	readResponse(bufio.NewReader(activeConn))
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+const (
+	// InheritedListenerFDEnvName is the environment variable that tells a process started by HandOverListener
+	// which of its file descriptors is the inherited listener.
+	InheritedListenerFDEnvName = "HTTP_SERVER_INHERITED_LISTENER_FD"
+)
+
+// HandOverListener starts the command with a duplicate of the listening socket of the server. A server created
+// with WithInheritedListener in the new process accepts connections on the same socket, so no connection is
+// refused while the new process starts and this one drains. Once the command is started, this server should be
+// shut down. The server must be running. Passing file descriptors to a command is not supported on Windows.
+func (server *Server) HandOverListener(cmd *exec.Cmd) error {
+	listener := server.listener.Load()
+	if listener == nil || server.shutdown.Load() {
+		return errors.New("the server is not listening")
+	}
+	file, err := listener.File()
+	if err != nil {
+		return fmt.Errorf("failed to duplicate the listener (%w)", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	// The extra files of a command are numbered after the standard input, output, and error.
+	fd := 3 + len(cmd.ExtraFiles)
+	cmd.ExtraFiles = append(cmd.ExtraFiles, file)
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%d", InheritedListenerFDEnvName, fd))
+	startErr := cmd.Start()
+	if err := setNonblock(listener); err != nil {
+		return fmt.Errorf("failed to restore the non-blocking mode of the listener (%w)", err)
+	}
+	if startErr != nil {
+		return fmt.Errorf("failed to start the process that inherits the listener (%w)", startErr)
+	}
+	return nil
+}
+
+// inheritedListener returns the listener passed by HandOverListener, or nil if the process did not inherit one.
+// The environment variable is removed so the listener is only used once, and is not passed to other processes.
+func inheritedListener() (*net.TCPListener, error) {
+	value, found := os.LookupEnv(InheritedListenerFDEnvName)
+	if !found {
+		return nil, nil
+	}
+	_ = os.Unsetenv(InheritedListenerFDEnvName)
+
+	fd, err := strconv.Atoi(value)
+	if err != nil || fd < 0 {
+		return nil, fmt.Errorf("the inherited listener file descriptor '%s' is invalid", value)
+	}
+	file := os.NewFile(uintptr(fd), "inherited-listener")
+	defer func() {
+		_ = file.Close()
+	}()
+	listener, err := net.FileListener(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to use the inherited listener (%w)", err)
+	}
+	tcpListener, ok := listener.(*net.TCPListener)
+	if !ok {
+		_ = listener.Close()
+		return nil, errors.New("the inherited listener is not a TCP listener")
+	}
+	return tcpListener, nil
+}
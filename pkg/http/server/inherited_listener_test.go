@@ -0,0 +1,186 @@
+//go:build unix
+
+package server_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/config"
+	"github.com/TriangleSide/GoBase/pkg/http/api"
+	"github.com/TriangleSide/GoBase/pkg/http/server"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+// nameHandler responds with the name of the server that handled the request.
+type nameHandler struct {
+	name string
+}
+
+func (h *nameHandler) AcceptHTTPAPIBuilder(builder *api.HTTPAPIBuilder) {
+	builder.MustRegister("/", http.MethodGet, &api.Handler{
+		Handler: func(writer http.ResponseWriter, _ *http.Request) {
+			_, _ = writer.Write([]byte(h.name))
+		},
+	})
+}
+
+// startNamedServer runs a server with TLS off that responds with its name, and returns the server and its address.
+func startNamedServer(t *testing.T, name string, opts ...server.Option) (*server.Server, string) {
+	t.Helper()
+	bound := make(chan string, 1)
+	opts = append(opts, server.WithConfigProvider(func() (*config.HTTPServer, error) {
+		return &config.HTTPServer{
+			HTTPServerBindIP:         "::1",
+			HTTPServerTLSMode:        config.HTTPServerTLSModeOff,
+			HTTPServerMaxHeaderBytes: 4096,
+		}, nil
+	}), server.WithBoundCallback(func(addr *net.TCPAddr) {
+		bound <- addr.String()
+	}), server.WithEndpointHandlers(&nameHandler{name: name}))
+	srv, err := server.New(opts...)
+	assert.NoError(t, err)
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- srv.Run()
+	}()
+	t.Cleanup(func() {
+		assert.NoError(t, srv.Shutdown(context.Background()))
+		assert.NoError(t, <-runErr)
+	})
+	return srv, <-bound
+}
+
+// respondingServer returns the name of the server that handled a new request to the address.
+func respondingServer(t *testing.T, addr string) string {
+	t.Helper()
+	httpClient := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	response, err := httpClient.Get("http://" + addr)
+	assert.NoError(t, err)
+	body, err := io.ReadAll(response.Body)
+	assert.NoError(t, err)
+	assert.NoError(t, response.Body.Close())
+	return string(body)
+}
+
+// setInheritedListener sets the environment variable of an inherited listener to a duplicate of the file
+// descriptor of the connection. The server owns the duplicate and closes it. The file descriptor is not
+// obtained with Fd, since it would put the socket of the listener in blocking mode.
+func setInheritedListener(t *testing.T, conn syscall.Conn) {
+	t.Helper()
+	rawConn, err := conn.SyscallConn()
+	assert.NoError(t, err)
+	var duplicate int
+	var dupErr error
+	assert.NoError(t, rawConn.Control(func(fd uintptr) {
+		duplicate, dupErr = syscall.Dup(int(fd))
+	}))
+	assert.NoError(t, dupErr)
+	t.Setenv(server.InheritedListenerFDEnvName, strconv.Itoa(duplicate))
+}
+
+func TestInheritedListener(t *testing.T) {
+	t.Run("when a listener is inherited it should keep serving after the previous server shuts down", func(t *testing.T) {
+		listener, err := net.ListenTCP("tcp", &net.TCPAddr{IP: net.ParseIP("::1"), Port: 0})
+		assert.NoError(t, err)
+		setInheritedListener(t, listener)
+
+		previous, err := server.New(server.WithConfigProvider(func() (*config.HTTPServer, error) {
+			return &config.HTTPServer{HTTPServerTLSMode: config.HTTPServerTLSModeOff, HTTPServerMaxHeaderBytes: 4096}, nil
+		}), server.WithListenerProvider(func(string, uint16) (*net.TCPListener, error) {
+			return listener, nil
+		}), server.WithEndpointHandlers(&nameHandler{name: "previous"}))
+		assert.NoError(t, err)
+		previousDone := make(chan error, 1)
+		go func() {
+			previousDone <- previous.Run()
+		}()
+
+		_, addr := startNamedServer(t, "next", server.WithInheritedListener())
+		assert.Equals(t, addr, listener.Addr().String())
+		_, found := os.LookupEnv(server.InheritedListenerFDEnvName)
+		assert.False(t, found)
+
+		assert.NoError(t, previous.Shutdown(context.Background()))
+		assert.NoError(t, <-previousDone)
+		for range 5 {
+			assert.Equals(t, respondingServer(t, addr), "next")
+		}
+	})
+
+	t.Run("when no listener is inherited it should bind the configured address", func(t *testing.T) {
+		_, addr := startNamedServer(t, "bound", server.WithInheritedListener())
+		assert.Equals(t, respondingServer(t, addr), "bound")
+	})
+
+	t.Run("when the inherited listener is invalid it should fail to run", func(t *testing.T) {
+		regularFile, err := os.Create(filepath.Join(t.TempDir(), "file"))
+		assert.NoError(t, err)
+		unixListener, err := net.ListenUnix("unix", &net.UnixAddr{Name: filepath.Join(t.TempDir(), "socket"), Net: "unix"})
+		assert.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, unixListener.Close())
+		})
+
+		testCases := []struct {
+			conn        syscall.Conn
+			value       string
+			expectedErr string
+		}{
+			{value: "not_a_number", expectedErr: "the inherited listener file descriptor 'not_a_number' is invalid"},
+			{value: "-1", expectedErr: "the inherited listener file descriptor '-1' is invalid"},
+			{conn: regularFile, expectedErr: "failed to use the inherited listener"},
+			{conn: unixListener, expectedErr: "the inherited listener is not a TCP listener"},
+		}
+		for _, testCase := range testCases {
+			if testCase.conn != nil {
+				setInheritedListener(t, testCase.conn)
+			} else {
+				t.Setenv(server.InheritedListenerFDEnvName, testCase.value)
+			}
+			srv, err := server.New(server.WithInheritedListener(), server.WithConfigProvider(func() (*config.HTTPServer, error) {
+				return &config.HTTPServer{HTTPServerTLSMode: config.HTTPServerTLSModeOff, HTTPServerMaxHeaderBytes: 4096}, nil
+			}))
+			assert.NoError(t, err)
+			err = srv.Run()
+			assert.ErrorPart(t, err, testCase.expectedErr)
+		}
+		assert.NoError(t, regularFile.Close())
+	})
+
+	t.Run("when the server is not running it should not hand over its listener", func(t *testing.T) {
+		srv, err := server.New(server.WithConfigProvider(func() (*config.HTTPServer, error) {
+			return &config.HTTPServer{HTTPServerTLSMode: config.HTTPServerTLSModeOff, HTTPServerMaxHeaderBytes: 4096}, nil
+		}))
+		assert.NoError(t, err)
+		err = srv.HandOverListener(exec.Command(os.Args[0], "-test.run=^$"))
+		assert.ErrorExact(t, err, "the server is not listening")
+	})
+
+	t.Run("when the listener is handed over it should start the command with the listener", func(t *testing.T) {
+		srv, addr := startNamedServer(t, "previous")
+		cmd := exec.Command(os.Args[0], "-test.run=^$")
+		cmd.ExtraFiles = []*os.File{os.Stdin}
+		assert.NoError(t, srv.HandOverListener(cmd))
+		assert.NoError(t, cmd.Wait())
+		assert.Equals(t, len(cmd.ExtraFiles), 2)
+		assert.Equals(t, cmd.Env[len(cmd.Env)-1], server.InheritedListenerFDEnvName+"=4")
+		assert.True(t, strings.HasPrefix(cmd.ExtraFiles[1].Name(), "tcp:"))
+		assert.Equals(t, respondingServer(t, addr), "previous")
+	})
+
+	t.Run("when the command cannot be started it should return an error", func(t *testing.T) {
+		srv, _ := startNamedServer(t, "previous")
+		err := srv.HandOverListener(exec.Command(filepath.Join(t.TempDir(), "does_not_exist")))
+		assert.ErrorPart(t, err, "failed to start the process that inherits the listener")
+	})
+}
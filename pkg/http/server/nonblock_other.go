@@ -0,0 +1,12 @@
+//go:build !unix
+
+package server
+
+import (
+	"net"
+)
+
+// setNonblock does nothing, since the listener cannot be passed to a command on this platform.
+func setNonblock(*net.TCPListener) error {
+	return nil
+}
@@ -0,0 +1,26 @@
+//go:build unix
+
+package server
+
+import (
+	"net"
+	"syscall"
+)
+
+// setNonblock puts the socket of the listener back in non-blocking mode. Passing the file of a listener to
+// a command puts the socket in blocking mode, which prevents the server from closing the listener while an
+// Accept is waiting, since the socket is shared with the duplicate.
+func setNonblock(listener *net.TCPListener) error {
+	rawConn, err := listener.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var nonblockErr error
+	err = rawConn.Control(func(fd uintptr) {
+		nonblockErr = syscall.SetNonblock(int(fd), true)
+	})
+	if err != nil {
+		return err
+	}
+	return nonblockErr
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/logger"
+)
+
+// periodicTask is a function that is invoked on an interval for as long as the server is running.
+type periodicTask struct {
+	name       string
+	interval   time.Duration
+	jitter     time.Duration
+	runOnStart bool
+	fn         func(ctx context.Context) error
+}
+
+// PeriodicTaskOption is used to configure a periodic task.
+type PeriodicTaskOption func(task *periodicTask)
+
+// WithPeriodicTaskJitter adds a random delay of up to the jitter duration to each interval.
+// This is used to avoid many instances of a service running the same task at the same time.
+func WithPeriodicTaskJitter(jitter time.Duration) PeriodicTaskOption {
+	return func(task *periodicTask) {
+		task.jitter = jitter
+	}
+}
+
+// WithPeriodicTaskRunOnStart runs the task as soon as the server starts rather than waiting for the first interval.
+func WithPeriodicTaskRunOnStart() PeriodicTaskOption {
+	return func(task *periodicTask) {
+		task.runOnStart = true
+	}
+}
+
+// WithPeriodicTask registers a task that is run on an interval while the server is running.
+// The task starts when the server is run and stops when the server is shut down. The context
+// passed to the task is cancelled on shutdown. Errors returned by the task are logged.
+func WithPeriodicTask(name string, interval time.Duration, fn func(ctx context.Context) error, opts ...PeriodicTaskOption) Option {
+	task := newPeriodicTask(name, interval, fn, opts...)
+	return func(srvOpts *serverOptions) {
+		srvOpts.periodicTasks = append(srvOpts.periodicTasks, task)
+	}
+}
+
+// newPeriodicTask allocates a periodicTask and applies the options. It panics if the task is invalid.
+func newPeriodicTask(name string, interval time.Duration, fn func(ctx context.Context) error, opts ...PeriodicTaskOption) *periodicTask {
+	if interval <= 0 {
+		panic("the periodic task interval must be greater than zero")
+	}
+	if fn == nil {
+		panic("the periodic task function cannot be nil")
+	}
+	task := &periodicTask{
+		name:       name,
+		interval:   interval,
+		jitter:     0,
+		runOnStart: false,
+		fn:         fn,
+	}
+	for _, opt := range opts {
+		opt(task)
+	}
+	if task.jitter < 0 {
+		panic("the periodic task jitter cannot be negative")
+	}
+	return task
+}
+
+// run invokes the task on its interval until the context is done.
+func (task *periodicTask) run(ctx context.Context) {
+	ctx = logger.WithField(ctx, "periodicTask", task.name)
+	if task.runOnStart {
+		task.invoke(ctx)
+	}
+	timer := time.NewTimer(task.nextDelay())
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			task.invoke(ctx)
+			timer.Reset(task.nextDelay())
+		}
+	}
+}
+
+// invoke calls the task function and logs the error if there is one.
+func (task *periodicTask) invoke(ctx context.Context) {
+	if ctx.Err() != nil {
+		return
+	}
+	if err := task.fn(ctx); err != nil {
+		logger.Errorf(ctx, "Periodic task %s failed (%s).", task.name, err.Error())
+	}
+}
+
+// nextDelay returns the interval plus a random jitter.
+func (task *periodicTask) nextDelay() time.Duration {
+	if task.jitter == 0 {
+		return task.interval
+	}
+	return task.interval + rand.N(task.jitter+1)
+}
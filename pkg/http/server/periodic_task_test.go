@@ -0,0 +1,103 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/config"
+	"github.com/TriangleSide/GoBase/pkg/http/server"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestPeriodicTask(t *testing.T) {
+	t.Setenv(string(config.HTTPServerTLSModeEnvName), string(config.HTTPServerTLSModeOff))
+
+	runServer := func(t *testing.T, options ...server.Option) *server.Server {
+		t.Helper()
+		waitUntilReady := make(chan bool)
+		allOpts := append(options, server.WithBoundCallback(func(*net.TCPAddr) {
+			close(waitUntilReady)
+		}))
+		srv, err := server.New(allOpts...)
+		assert.NoError(t, err)
+		assert.NotNil(t, srv)
+		go func() {
+			assert.NoError(t, srv.Run())
+		}()
+		<-waitUntilReady
+		return srv
+	}
+
+	t.Run("when a periodic task has an interval of zero it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			server.WithPeriodicTask("task", 0, func(ctx context.Context) error { return nil })
+		}, "the periodic task interval must be greater than zero")
+	})
+
+	t.Run("when a periodic task has a nil function it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			server.WithPeriodicTask("task", time.Second, nil)
+		}, "the periodic task function cannot be nil")
+	})
+
+	t.Run("when a periodic task has a negative jitter it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			server.WithPeriodicTask("task", time.Second, func(ctx context.Context) error { return nil }, server.WithPeriodicTaskJitter(-time.Second))
+		}, "the periodic task jitter cannot be negative")
+	})
+
+	t.Run("when a periodic task is registered it should run at least once and stop on shutdown", func(t *testing.T) {
+		t.Parallel()
+		invocations := atomic.Int32{}
+		ranOnce := make(chan struct{})
+		srv := runServer(t, server.WithPeriodicTask("task", time.Millisecond, func(ctx context.Context) error {
+			if invocations.Add(1) == 1 {
+				close(ranOnce)
+			}
+			return errors.New("task error")
+		}, server.WithPeriodicTaskJitter(time.Millisecond)))
+		<-ranOnce
+		assert.NoError(t, srv.Shutdown(context.Background()))
+		invocationsAtShutdown := invocations.Load()
+		assert.True(t, invocationsAtShutdown >= 1)
+		time.Sleep(time.Millisecond * 20)
+		assert.Equals(t, invocations.Load(), invocationsAtShutdown)
+	})
+
+	t.Run("when a periodic task is set to run on start it should run before the first interval", func(t *testing.T) {
+		t.Parallel()
+		ranOnce := make(chan struct{})
+		srv := runServer(t, server.WithPeriodicTask("task", time.Hour, func(ctx context.Context) error {
+			close(ranOnce)
+			return nil
+		}, server.WithPeriodicTaskRunOnStart()))
+		select {
+		case <-ranOnce:
+		case <-time.After(time.Second * 5):
+			t.Fatal("The periodic task did not run on start.")
+		}
+		assert.NoError(t, srv.Shutdown(context.Background()))
+	})
+
+	t.Run("when a long running periodic task is running it should have its context cancelled on shutdown", func(t *testing.T) {
+		t.Parallel()
+		started := make(chan struct{})
+		cancelled := atomic.Bool{}
+		srv := runServer(t, server.WithPeriodicTask("task", time.Hour, func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			cancelled.Store(true)
+			return ctx.Err()
+		}, server.WithPeriodicTaskRunOnStart()))
+		<-started
+		assert.NoError(t, srv.Shutdown(context.Background()))
+		assert.True(t, cancelled.Load())
+	})
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/TriangleSide/GoBase/pkg/logger"
+)
+
+// responseWriter wraps the http.ResponseWriter of every request handled by the server.
+// It records whether the header was written so subsequent calls to WriteHeader are ignored
+// and a single warning is logged with the route that made the superfluous call.
+type responseWriter struct {
+	http.ResponseWriter
+	request       *http.Request
+	route         string
+	headerWritten bool
+	warned        bool
+}
+
+// newResponseWriter allocates a responseWriter for a request on a route.
+func newResponseWriter(writer http.ResponseWriter, request *http.Request, route string) *responseWriter {
+	return &responseWriter{
+		ResponseWriter: writer,
+		request:        request,
+		route:          route,
+		headerWritten:  false,
+		warned:         false,
+	}
+}
+
+// WriteHeader sends the HTTP response header with the status code if it was not already sent.
+// Informational statuses, like 103 Early Hints, are sent without being recorded since the final status comes after them.
+func (rw *responseWriter) WriteHeader(statusCode int) {
+	if statusCode >= 100 && statusCode < http.StatusOK && !rw.headerWritten {
+		rw.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+	if rw.headerWritten {
+		if !rw.warned {
+			rw.warned = true
+			logger.Warnf(rw.request.Context(), "Superfluous WriteHeader call with status %d on route '%s'. The header was already written.", statusCode, rw.route)
+		}
+		return
+	}
+	rw.headerWritten = true
+	rw.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write writes the data as part of the HTTP reply. The header is implicitly written if it has not been.
+func (rw *responseWriter) Write(data []byte) (int, error) {
+	rw.headerWritten = true
+	return rw.ResponseWriter.Write(data)
+}
+
+// Flush sends any buffered data to the client if the underlying http.ResponseWriter supports it.
+func (rw *responseWriter) Flush() {
+	rw.headerWritten = true
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap returns the underlying http.ResponseWriter. This is used by the http.ResponseController.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+
+	httperrors "github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/logger"
+)
+
+const (
+	// maxRouteSuggestions is the maximum number of routes suggested in the body of a 404 response.
+	maxRouteSuggestions = 3
+)
+
+// WithRouteSuggestions makes the server respond to requests that do not match any route with a JSON body that
+// suggests the registered routes closest to the request path. This is meant to speed up API integration while
+// developing and must not be enabled in production, since it reveals the routes of the server.
+func WithRouteSuggestions() Option {
+	return func(srvOpts *serverOptions) {
+		srvOpts.routeSuggestions = true
+	}
+}
+
+// routeSuggestionsHandler wraps the ServeMux so that requests that do not match a route get route suggestions.
+// Only the not found responses of the ServeMux itself are replaced. Handlers that respond with a 404 are not affected.
+func routeSuggestionsHandler(serveMux *http.ServeMux, routes []string) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if _, pattern := serveMux.Handler(request); pattern != fallbackPattern {
+			serveMux.ServeHTTP(writer, request)
+			return
+		}
+		interceptor := &notFoundInterceptor{ResponseWriter: writer, notFound: false}
+		serveMux.ServeHTTP(interceptor, request)
+		if !interceptor.notFound {
+			return
+		}
+		writer.Header().Set(headers.ContentType, headers.ContentTypeApplicationJson)
+		writer.WriteHeader(http.StatusNotFound)
+		response := map[string]any{
+			httperrors.MessageFieldName(): http.StatusText(http.StatusNotFound),
+			"suggestions":                 suggestRoutes(request.URL.Path, routes),
+		}
+		if err := json.NewEncoder(writer).Encode(response); err != nil {
+			logger.Errorf(request.Context(), "Error encoding the route suggestions (%s).", err)
+		}
+	})
+}
+
+// notFoundInterceptor discards the response of the ServeMux when it is a 404, so it can be replaced.
+type notFoundInterceptor struct {
+	http.ResponseWriter
+	notFound bool
+}
+
+// WriteHeader records a 404 status instead of sending it. Other statuses are sent.
+func (i *notFoundInterceptor) WriteHeader(statusCode int) {
+	if statusCode == http.StatusNotFound {
+		i.notFound = true
+		return
+	}
+	i.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write discards the body of a 404 response.
+func (i *notFoundInterceptor) Write(data []byte) (int, error) {
+	if i.notFound {
+		return len(data), nil
+	}
+	return i.ResponseWriter.Write(data)
+}
+
+// suggestRoutes returns the routes with the paths closest to the request path, ordered by their edit distance.
+func suggestRoutes(requestPath string, routes []string) []string {
+	type candidate struct {
+		route    string
+		distance int
+	}
+	candidates := make([]candidate, 0, len(routes))
+	for _, route := range routes {
+		_, routePath, _ := strings.Cut(route, " ")
+		candidates = append(candidates, candidate{
+			route:    route,
+			distance: editDistance(requestPath, routePath),
+		})
+	}
+	slices.SortFunc(candidates, func(a, b candidate) int {
+		if a.distance != b.distance {
+			return a.distance - b.distance
+		}
+		return strings.Compare(a.route, b.route)
+	})
+	suggestions := make([]string, 0, maxRouteSuggestions)
+	for i := 0; i < len(candidates) && i < maxRouteSuggestions; i++ {
+		suggestions = append(suggestions, candidates[i].route)
+	}
+	return suggestions
+}
+
+// editDistance returns the Levenshtein distance between two strings.
+func editDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			substitutionCost := 1
+			if a[i-1] == b[j-1] {
+				substitutionCost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+substitutionCost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
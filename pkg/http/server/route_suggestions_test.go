@@ -0,0 +1,103 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/config"
+	"github.com/TriangleSide/GoBase/pkg/http/server"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestRouteSuggestions(t *testing.T) {
+	t.Setenv(string(config.HTTPServerTLSModeEnvName), string(config.HTTPServerTLSModeOff))
+
+	handlerFor := func(path string, method string, status int) *testHandler {
+		return &testHandler{
+			Path:   path,
+			Method: method,
+			Handler: func(writer http.ResponseWriter, request *http.Request) {
+				writer.WriteHeader(status)
+			},
+		}
+	}
+
+	runServer := func(t *testing.T, options ...server.Option) string {
+		t.Helper()
+		waitUntilReady := make(chan bool)
+		var address string
+		allOpts := append(options, server.WithBoundCallback(func(addr *net.TCPAddr) {
+			address = addr.String()
+			close(waitUntilReady)
+		}), server.WithEndpointHandlers(
+			handlerFor("/users", http.MethodGet, http.StatusOK),
+			handlerFor("/users/{id}", http.MethodGet, http.StatusOK),
+			handlerFor("/orders", http.MethodPost, http.StatusOK),
+			handlerFor("/missing", http.MethodGet, http.StatusNotFound),
+		))
+		srv, err := server.New(allOpts...)
+		assert.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, srv.Shutdown(context.Background()))
+		})
+		go func() {
+			assert.NoError(t, srv.Run())
+		}()
+		<-waitUntilReady
+		return "http://" + address
+	}
+
+	type suggestionsBody struct {
+		Message     string   `json:"message"`
+		Suggestions []string `json:"suggestions"`
+	}
+
+	t.Run("when route suggestions are enabled and a near-miss path is requested it should suggest the closest routes", func(t *testing.T) {
+		baseURL := runServer(t, server.WithRouteSuggestions())
+		response, err := http.Get(baseURL + "/user")
+		assert.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, response.Body.Close())
+		})
+		assert.Equals(t, response.StatusCode, http.StatusNotFound)
+		body := &suggestionsBody{}
+		assert.NoError(t, json.NewDecoder(response.Body).Decode(body))
+		assert.Equals(t, body.Message, http.StatusText(http.StatusNotFound))
+		assert.Equals(t, body.Suggestions, []string{"GET /users", "POST /orders", "GET /missing"})
+	})
+
+	t.Run("when route suggestions are enabled and a handler responds with a 404 it should not add suggestions", func(t *testing.T) {
+		baseURL := runServer(t, server.WithRouteSuggestions())
+		response, err := http.Get(baseURL + "/missing")
+		assert.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, response.Body.Close())
+		})
+		assert.Equals(t, response.StatusCode, http.StatusNotFound)
+		assert.Equals(t, response.ContentLength, int64(0))
+	})
+
+	t.Run("when route suggestions are enabled and the method is not allowed it should keep the method not allowed response", func(t *testing.T) {
+		baseURL := runServer(t, server.WithRouteSuggestions())
+		response, err := http.Post(baseURL+"/users", "", nil)
+		assert.NoError(t, err)
+		assert.Equals(t, response.StatusCode, http.StatusMethodNotAllowed)
+		assert.NoError(t, response.Body.Close())
+	})
+
+	t.Run("when route suggestions are not enabled it should not reveal the routes", func(t *testing.T) {
+		baseURL := runServer(t)
+		response, err := http.Get(baseURL + "/user")
+		assert.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, response.Body.Close())
+		})
+		assert.Equals(t, response.StatusCode, http.StatusNotFound)
+		body := &suggestionsBody{}
+		assert.NotNil(t, json.NewDecoder(response.Body).Decode(body))
+		assert.Nil(t, body.Suggestions)
+	})
+}
@@ -3,21 +3,44 @@ package server
 import (
 	"context"
 	"crypto/tls"
-	"crypto/x509"
 	"errors"
 	"fmt"
 	"net"
 	"net/http"
 	"net/netip"
-	"os"
+	"reflect"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/TriangleSide/GoBase/pkg/config"
 	"github.com/TriangleSide/GoBase/pkg/config/envprocessor"
+	"github.com/TriangleSide/GoBase/pkg/health"
+	"github.com/TriangleSide/GoBase/pkg/http/acme"
 	"github.com/TriangleSide/GoBase/pkg/http/api"
+	httperrors "github.com/TriangleSide/GoBase/pkg/http/errors"
 	"github.com/TriangleSide/GoBase/pkg/http/middleware"
+	"github.com/TriangleSide/GoBase/pkg/http/middleware/metrics"
+	"github.com/TriangleSide/GoBase/pkg/http/openapi"
+	"github.com/TriangleSide/GoBase/pkg/http/responders"
+	"github.com/TriangleSide/GoBase/pkg/logger"
+	"github.com/TriangleSide/GoBase/pkg/validation"
+)
+
+const (
+	// DefaultMaxURLLength is the maximum length in bytes of a request URI when WithMaxURLLength is not used.
+	DefaultMaxURLLength = 8192
+
+	// acmeRenewalInterval is how often the certificates of the acme TLS mode are checked for renewal.
+	acmeRenewalInterval = 12 * time.Hour
+
+	// acmeHTTPTimeout bounds the requests of the plain HTTP listener of the acme TLS mode.
+	acmeHTTPTimeout = 10 * time.Second
+
+	// fallbackPattern is the ServeMux pattern of the handler for requests that no route matches. Every route
+	// has a method, so it is more specific than this pattern.
+	fallbackPattern = "/"
 )
 
 // serverOptions is configured by the caller with the Option functions.
@@ -27,6 +50,21 @@ type serverOptions struct {
 	boundCallback    func(tcpAddr *net.TCPAddr)
 	commonMiddleware []middleware.Middleware
 	endpointHandlers []api.HTTPEndpointHandler
+	builderOptions   []api.Option
+	periodicTasks    []*periodicTask
+	loadShedding     *loadSheddingConfig
+	dependencies     *dependencyHealth
+	maxURLLength     int
+	routeSuggestions bool
+	onDrainStart     []func(ctx context.Context)
+	onDrainComplete  []func(ctx context.Context, err error)
+	metricsPath      api.Path
+	metrics          *metrics.Metrics
+	healthEndpoints  []*healthEndpoint
+	openAPIPath      api.Path
+	openAPIInfo      *openapi.Info
+	logLevelPath     api.Path
+	inheritListener  bool
 }
 
 // Option is used to configure the HTTP server.
@@ -39,6 +77,29 @@ func WithConfigProvider(provider func() (*config.HTTPServer, error)) Option {
 	}
 }
 
+// WithConfigProviders composes many providers for the config.HTTPServer into one.
+// The providers are invoked in order, and each field of the resulting config is taken from the first provider
+// that sets it to a non-zero value. A provider may return a nil config if it has nothing to contribute.
+// The merged config is validated once all the providers have been invoked.
+func WithConfigProviders(providers ...func() (*config.HTTPServer, error)) Option {
+	return WithConfigProvider(func() (*config.HTTPServer, error) {
+		merged := &config.HTTPServer{}
+		for providerIndex, provider := range providers {
+			cfg, err := provider()
+			if err != nil {
+				return nil, fmt.Errorf("config provider at index %d failed (%w)", providerIndex, err)
+			}
+			if cfg != nil {
+				mergeNonZeroFields(merged, cfg)
+			}
+		}
+		if err := validation.Struct(merged); err != nil {
+			return nil, fmt.Errorf("failed to validate the merged configuration (%w)", err)
+		}
+		return merged, nil
+	})
+}
+
 // WithListenerProvider sets the provider for the tcp.Listener.
 func WithListenerProvider(provider func(bindIP string, bindPort uint16) (*net.TCPListener, error)) Option {
 	return func(srvOpts *serverOptions) {
@@ -69,6 +130,90 @@ func WithEndpointHandlers(endpointHandlers ...api.HTTPEndpointHandler) Option {
 	}
 }
 
+// WithAPIBuilderOptions configures the builder of the routes, like how requests with a method that is not
+// registered for their path are answered.
+func WithAPIBuilderOptions(builderOptions ...api.Option) Option {
+	return func(srvOpts *serverOptions) {
+		srvOpts.builderOptions = append(srvOpts.builderOptions, builderOptions...)
+	}
+}
+
+// WithMaxURLLength sets the maximum length in bytes of the request URI.
+// Longer requests are rejected with an HTTP 414 URI too long before they are routed.
+// The default is DefaultMaxURLLength. If the length is not positive, this function panics.
+func WithMaxURLLength(length int) Option {
+	if length <= 0 {
+		panic("the maximum URL length must be greater than zero")
+	}
+	return func(srvOpts *serverOptions) {
+		srvOpts.maxURLLength = length
+	}
+}
+
+// WithMetricsEndpoint records the requests of every route with the Metrics and serves them on a GET endpoint
+// at the path in the Prometheus text exposition format. The metrics middleware runs before all other middleware.
+func WithMetricsEndpoint(path api.Path, collector *metrics.Metrics) Option {
+	if collector == nil {
+		panic("the metrics cannot be nil")
+	}
+	return func(srvOpts *serverOptions) {
+		srvOpts.metricsPath = path
+		srvOpts.metrics = collector
+	}
+}
+
+// WithOpenAPIEndpoint serves an OpenAPI document of the routes on a GET endpoint at the path. The document is
+// generated from the Documentation of the routes when the server is created. It is served as JSON, or as YAML
+// if the Accept header of the request mentions YAML.
+func WithOpenAPIEndpoint(path api.Path, info openapi.Info) Option {
+	return func(srvOpts *serverOptions) {
+		srvOpts.openAPIPath = path
+		srvOpts.openAPIInfo = &info
+	}
+}
+
+// WithLogLevelEndpoint serves the logger.LevelHandler on GET, PUT and POST endpoints at the path, like
+// /admin/loglevel, so the application and module log levels can be changed while the server is running.
+// The endpoint should be protected by middleware since it changes the logs of the whole process.
+func WithLogLevelEndpoint(path api.Path) Option {
+	return func(srvOpts *serverOptions) {
+		srvOpts.logLevelPath = path
+	}
+}
+
+// WithOnDrainStart registers a hook that is called when the server starts shutting down, before the listener is closed.
+// This can be used to deregister the server from service discovery so that clients stop sending it new requests.
+// The context is cancelled when the grace period of the shutdown expires.
+func WithOnDrainStart(hook func(ctx context.Context)) Option {
+	if hook == nil {
+		panic("the drain start hook cannot be nil")
+	}
+	return func(srvOpts *serverOptions) {
+		srvOpts.onDrainStart = append(srvOpts.onDrainStart, hook)
+	}
+}
+
+// WithOnDrainComplete registers a hook that is called once the server has stopped serving requests during a shutdown.
+// The error is not nil if the in-flight requests did not complete before the grace period or the context expired.
+func WithOnDrainComplete(hook func(ctx context.Context, err error)) Option {
+	if hook == nil {
+		panic("the drain complete hook cannot be nil")
+	}
+	return func(srvOpts *serverOptions) {
+		srvOpts.onDrainComplete = append(srvOpts.onDrainComplete, hook)
+	}
+}
+
+// WithInheritedListener makes the server use the listener passed by the process that started it with
+// HandOverListener, instead of binding a new one. If the process did not inherit a listener, the server
+// binds its configured IP and port as usual. This allows a new binary to take over a listener without
+// refusing connections while the previous process drains.
+func WithInheritedListener() Option {
+	return func(srvOpts *serverOptions) {
+		srvOpts.inheritListener = true
+	}
+}
+
 // Server handles requests via the Hypertext Transfer Protocol (HTTP) and sends back responses.
 // The Server must be allocated using New since the zero value for Server is not valid configuration.
 type Server struct {
@@ -76,8 +221,24 @@ type Server struct {
 	ran              atomic.Bool
 	shutdown         atomic.Bool
 	wg               sync.WaitGroup
+	baseCtx          context.Context
+	cancelBaseCtx    context.CancelFunc
+	boundAddr        atomic.Pointer[string]
+	listener         atomic.Pointer[net.TCPListener]
 	listenerProvider func() (*net.TCPListener, error)
 	boundCallback    func(tcpAddr *net.TCPAddr)
+	periodicTasks    []*periodicTask
+	healthRegistries []*health.Registry
+	dependencies     *dependencyHealth
+	maxURLLength     int
+	idleConns        *idleConnections
+	gracePeriod      time.Duration
+	onDrainStart     []func(ctx context.Context)
+	onDrainComplete  []func(ctx context.Context, err error)
+	tlsReloader      *config.TLSReloader
+	acmeManager      *acme.Manager
+	acmeHTTPServer   *http.Server
+	acmeListener     func() (*net.TCPListener, error)
 }
 
 // New configures an HTTP server with the provided options.
@@ -95,6 +256,8 @@ func New(opts ...Option) (*Server, error) {
 			tcpAddr := net.TCPAddrFromAddrPort(addrPort)
 			return net.ListenTCP(tcpAddr.Network(), tcpAddr)
 		},
+		dependencies: newDependencyHealth(),
+		maxURLLength: DefaultMaxURLLength,
 	}
 
 	for _, opt := range opts {
@@ -106,55 +269,134 @@ func New(opts ...Option) (*Server, error) {
 		return nil, fmt.Errorf("could not load configuration (%w)", err)
 	}
 
-	builder := api.NewHTTPAPIBuilder()
+	builder := api.NewHTTPAPIBuilder(srvOpts.builderOptions...)
+	if srvOpts.metrics != nil {
+		builder.MustRegister(srvOpts.metricsPath, http.MethodGet, &api.Handler{
+			Handler: srvOpts.metrics.Handler(),
+		})
+	}
+	healthRegistries := registerHealthEndpoints(builder, srvOpts.healthEndpoints)
+	if srvOpts.logLevelPath != "" {
+		for _, method := range []api.Method{http.MethodGet, http.MethodPut, http.MethodPost} {
+			builder.MustRegister(srvOpts.logLevelPath, method, &api.Handler{
+				Handler: logger.LevelHandler(),
+			})
+		}
+	}
 	for _, endpointHandler := range srvOpts.endpointHandlers {
 		endpointHandler.AcceptHTTPAPIBuilder(builder)
 	}
+	if srvOpts.openAPIInfo != nil {
+		document, err := openapi.Generate(builder, *srvOpts.openAPIInfo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate the OpenAPI document (%w)", err)
+		}
+		documentHandler, err := openapi.Handler(document)
+		if err != nil {
+			return nil, err
+		}
+		builder.MustRegister(srvOpts.openAPIPath, http.MethodGet, &api.Handler{
+			Handler: documentHandler,
+		})
+	}
 
 	serveMux := http.NewServeMux()
+	routes := make([]string, 0)
+	fallbackChain := middleware.CreateChain(srvOpts.commonMiddleware, builder.FallbackHandler())
+	fallback := func(writer http.ResponseWriter, request *http.Request) {
+		fallbackChain(newResponseWriter(writer, request, fallbackPattern), request)
+	}
+	serveMux.HandleFunc(fallbackPattern, fallback)
 	for apiPath, methodToEndpointHandlerMap := range builder.Handlers() {
 		for method, endpointHandler := range methodToEndpointHandlerMap {
-			endpointHandlerMw := append(srvOpts.commonMiddleware, endpointHandler.Middleware...)
+			endpointHandlerMw := make([]middleware.Middleware, 0, len(srvOpts.commonMiddleware)+len(endpointHandler.Middleware)+4)
+			if srvOpts.metrics != nil {
+				endpointHandlerMw = append(endpointHandlerMw, srvOpts.metrics.Middleware())
+			}
+			if sheddingMw := loadSheddingMiddleware(srvOpts.loadShedding, srvOpts.dependencies, apiPath); sheddingMw != nil {
+				endpointHandlerMw = append(endpointHandlerMw, sheddingMw)
+			}
+			if endpointHandler.Limits != nil {
+				endpointHandlerMw = append(endpointHandlerMw, endpointHandler.Limits.Middleware())
+			}
+			endpointHandlerMw = append(endpointHandlerMw, srvOpts.commonMiddleware...)
+			if len(endpointHandler.AcceptedContentTypes) != 0 {
+				endpointHandlerMw = append(endpointHandlerMw, middleware.RequireContentType(endpointHandler.AcceptedContentTypes...))
+			}
+			endpointHandlerMw = append(endpointHandlerMw, endpointHandler.Middleware...)
 			handlerChain := middleware.CreateChain(endpointHandlerMw, endpointHandler.Handler)
-			serveMux.HandleFunc(fmt.Sprintf("%s %s", method, apiPath), handlerChain)
+			route := fmt.Sprintf("%s %s", method, apiPath.MuxPattern())
+			routes = append(routes, route)
+			serveMux.HandleFunc(route, func(writer http.ResponseWriter, request *http.Request) {
+				// The ServeMux serves HEAD requests with the GET handler when the path has no HEAD route.
+				derivedHead := request.Method == http.MethodHead && method == http.MethodGet
+				if !apiPath.MatchesPatterns(request) || (derivedHead && !builder.DerivesHead()) {
+					fallback(writer, request)
+					return
+				}
+				handlerChain(newResponseWriter(writer, request, route), request)
+			})
 		}
 	}
 
+	protocols, err := serverProtocols(envConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	var tlsConfig *tls.Config
-	switch envConfig.HTTPServerTLSMode {
-	case config.HTTPServerTLSModeOff:
-		tlsConfig = nil
-	case config.HTTPServerTLSModeTLS:
-		serverCert, err := tls.LoadX509KeyPair(envConfig.HTTPServerCert, envConfig.HTTPServerKey)
+	var tlsReloader *config.TLSReloader
+	var acmeManager *acme.Manager
+	periodicTasks := srvOpts.periodicTasks
+	if envConfig.HTTPServerTLSMode == config.HTTPServerTLSModeACME {
+		acmeManager, err = newACMEManager(envConfig)
 		if err != nil {
-			return nil, fmt.Errorf("failed to load the server certificates (%w)", err)
+			return nil, err
 		}
-		tlsConfig = &tls.Config{
-			MinVersion:   tls.VersionTLS13,
-			Certificates: []tls.Certificate{serverCert},
+		tlsConfig = acmeManager.TLSConfig()
+		tlsConfig.NextProtos = append(tlsConfig.NextProtos, "http/1.1")
+		if protocols.HTTP2() {
+			tlsConfig.NextProtos = slices.Insert(tlsConfig.NextProtos, 0, "h2")
 		}
-	case config.HTTPServerTLSModeMutualTLS:
-		if len(envConfig.HTTPServerClientCACerts) == 0 {
-			return nil, errors.New("no client CAs provided")
-		}
-		serverCert, err := tls.LoadX509KeyPair(envConfig.HTTPServerCert, envConfig.HTTPServerKey)
+		periodicTasks = append(slices.Clone(periodicTasks), &periodicTask{
+			name:       "acmeRenewal",
+			interval:   acmeRenewalInterval,
+			runOnStart: true,
+			fn:         acmeManager.RenewExpiring,
+		})
+	} else if envConfig.HTTPServerTLSMode != config.HTTPServerTLSModeOff && envConfig.HTTPServerCertReloadIntervalSeconds > 0 {
+		tlsReloader, err = config.NewTLSReloader(envConfig)
 		if err != nil {
-			return nil, fmt.Errorf("failed to load the server certificates (%w)", err)
+			return nil, err
 		}
-		clientCAs, err := loadMutualTLSClientCAs(envConfig.HTTPServerClientCACerts)
-		if err != nil {
-			return nil, fmt.Errorf("failed to load client CA certificates (%w)", err)
+		tlsConfig = tlsReloader.TLSConfig()
+		tlsConfig.NextProtos = []string{"http/1.1"}
+		if protocols.HTTP2() {
+			tlsConfig.NextProtos = []string{"h2", "http/1.1"}
 		}
-		tlsConfig = &tls.Config{
-			MinVersion:   tls.VersionTLS13,
-			Certificates: []tls.Certificate{serverCert},
-			ClientAuth:   tls.RequireAndVerifyClientCert,
-			ClientCAs:    clientCAs,
+		periodicTasks = append(slices.Clone(periodicTasks), &periodicTask{
+			name:     "certificateReload",
+			interval: time.Second * time.Duration(envConfig.HTTPServerCertReloadIntervalSeconds),
+			fn: func(ctx context.Context) error {
+				reloaded, err := tlsReloader.Reload()
+				if err != nil {
+					return fmt.Errorf("failed to reload the certificates (%w)", err)
+				}
+				if reloaded {
+					logger.Info(ctx, "Reloaded the TLS certificates.")
+				}
+				return nil
+			},
+		})
+	} else {
+		tlsConfig, err = config.BuildTLSConfig(envConfig)
+		if err != nil {
+			return nil, err
 		}
-	default:
-		return nil, fmt.Errorf("invalid TLS mode: %s", envConfig.HTTPServerTLSMode)
 	}
 
+	baseCtx, cancelBaseCtx := context.WithCancel(context.Background())
+
 	srv := &Server{
 		srv: http.Server{
 			Handler:           serveMux,
@@ -164,22 +406,109 @@ func New(opts ...Option) (*Server, error) {
 			ReadHeaderTimeout: time.Second * time.Duration(envConfig.HTTPServerHeaderReadTimeoutSeconds),
 			MaxHeaderBytes:    envConfig.HTTPServerMaxHeaderBytes,
 			TLSConfig:         tlsConfig,
+			Protocols:         protocols,
+			HTTP2: &http.HTTP2Config{
+				MaxConcurrentStreams: envConfig.HTTPServerHTTP2MaxConcurrentStreams,
+			},
 		},
-		ran:      atomic.Bool{},
-		shutdown: atomic.Bool{},
-		wg:       sync.WaitGroup{},
+		ran:           atomic.Bool{},
+		shutdown:      atomic.Bool{},
+		wg:            sync.WaitGroup{},
+		baseCtx:       baseCtx,
+		cancelBaseCtx: cancelBaseCtx,
+		boundAddr:     atomic.Pointer[string]{},
 		listenerProvider: func() (*net.TCPListener, error) {
+			if srvOpts.inheritListener {
+				listener, err := inheritedListener()
+				if listener != nil || err != nil {
+					return listener, err
+				}
+			}
 			return srvOpts.listenerProvider(envConfig.HTTPServerBindIP, envConfig.HTTPServerBindPort)
 		},
-		boundCallback: srvOpts.boundCallback,
+		boundCallback:    srvOpts.boundCallback,
+		periodicTasks:    periodicTasks,
+		healthRegistries: healthRegistries,
+		dependencies:     srvOpts.dependencies,
+		maxURLLength:     srvOpts.maxURLLength,
+		idleConns:        newIdleConnections(),
+		gracePeriod:      time.Second * time.Duration(envConfig.HTTPServerShutdownGracePeriodSeconds),
+		onDrainStart:     srvOpts.onDrainStart,
+		onDrainComplete:  srvOpts.onDrainComplete,
+		tlsReloader:      tlsReloader,
+		acmeManager:      acmeManager,
+	}
+	if acmeManager != nil && envConfig.HTTPServerACMEHTTPBindPort != 0 {
+		srv.acmeHTTPServer = &http.Server{
+			Handler:           acmeManager.HTTPHandler(nil),
+			ReadHeaderTimeout: acmeHTTPTimeout,
+			ReadTimeout:       acmeHTTPTimeout,
+			WriteTimeout:      acmeHTTPTimeout,
+		}
+		srv.acmeListener = func() (*net.TCPListener, error) {
+			return srvOpts.listenerProvider(envConfig.HTTPServerBindIP, envConfig.HTTPServerACMEHTTPBindPort)
+		}
 	}
 
+	var router http.Handler = serveMux
+	if srvOpts.routeSuggestions {
+		router = routeSuggestionsHandler(serveMux, routes)
+	}
+	srv.srv.Handler = srv.normalizeRequest(router)
+	srv.srv.ConnState = srv.idleConns.track
 	srv.ran.Store(false)
 	srv.shutdown.Store(false)
 
 	return srv, nil
 }
 
+// newACMEManager creates the acme.Manager of the acme TLS mode. The http-01 challenge is only solved
+// when the plain HTTP listener is enabled.
+func newACMEManager(cfg *config.HTTPServer) (*acme.Manager, error) {
+	directoryURL := acme.LetsEncryptURL
+	if cfg.HTTPServerACMEStaging {
+		directoryURL = acme.LetsEncryptStagingURL
+	}
+	if cfg.HTTPServerACMEDirectoryURL != "" {
+		directoryURL = cfg.HTTPServerACMEDirectoryURL
+	}
+	opts := []acme.Option{
+		acme.WithDirectoryURL(directoryURL),
+		acme.WithEmail(cfg.HTTPServerACMEEmail),
+	}
+	if cfg.HTTPServerACMEHTTPBindPort != 0 {
+		opts = append(opts, acme.WithHTTPChallenge())
+	}
+	manager, err := acme.NewManager(cfg.HTTPServerACMECacheDir, cfg.HTTPServerACMEDomains, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the ACME manager (%w)", err)
+	}
+	return manager, nil
+}
+
+// serverProtocols returns the protocols the server accepts. HTTP/1 is always accepted. HTTP/2 is accepted
+// over TLS if it is enabled, and over cleartext if h2c is also enabled and the TLS mode is off.
+func serverProtocols(cfg *config.HTTPServer) (*http.Protocols, error) {
+	if cfg.HTTPServerH2CEnabled {
+		if !cfg.HTTPServerHTTP2Enabled {
+			return nil, errors.New("h2c requires HTTP/2 to be enabled")
+		}
+		if cfg.HTTPServerTLSMode != config.HTTPServerTLSModeOff {
+			return nil, errors.New("h2c can only be enabled when the TLS mode is off")
+		}
+	}
+	protocols := &http.Protocols{}
+	protocols.SetHTTP1(true)
+	if cfg.HTTPServerHTTP2Enabled {
+		if cfg.HTTPServerTLSMode == config.HTTPServerTLSModeOff {
+			protocols.SetUnencryptedHTTP2(cfg.HTTPServerH2CEnabled)
+		} else {
+			protocols.SetHTTP2(true)
+		}
+	}
+	return protocols, nil
+}
+
 // Run starts an HTTP server.
 // This function blocks as long as its serving HTTP requests.
 func (server *Server) Run() error {
@@ -194,11 +523,46 @@ func (server *Server) Run() error {
 		return fmt.Errorf("failed to create the network listener (%w)", err)
 	}
 
+	server.listener.Store(listener)
+	tcpAddr := listener.Addr().(*net.TCPAddr)
+	boundAddr := tcpAddr.String()
+	server.boundAddr.Store(&boundAddr)
+
+	if server.acmeHTTPServer != nil {
+		acmeListener, err := server.acmeListener()
+		if err != nil {
+			_ = listener.Close()
+			return fmt.Errorf("failed to create the ACME HTTP listener (%w)", err)
+		}
+		server.wg.Add(1)
+		go func() {
+			defer server.wg.Done()
+			if err := server.acmeHTTPServer.Serve(acmeListener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Errorf(server.baseCtx, "The ACME HTTP listener stopped (%s).", err)
+			}
+		}()
+	}
+
 	if server.boundCallback != nil {
-		tcpAddr := listener.Addr().(*net.TCPAddr)
 		server.boundCallback(tcpAddr)
 	}
 
+	for _, task := range server.periodicTasks {
+		server.wg.Add(1)
+		go func() {
+			defer server.wg.Done()
+			task.run(server.baseCtx)
+		}()
+	}
+
+	for _, registry := range server.healthRegistries {
+		server.wg.Add(1)
+		go func() {
+			defer server.wg.Done()
+			registry.Run(server.baseCtx)
+		}()
+	}
+
 	if server.srv.TLSConfig == nil {
 		err = server.srv.Serve(listener)
 	} else {
@@ -214,26 +578,75 @@ func (server *Server) Run() error {
 
 // Shutdown gracefully shuts down the server and waits for it to finish.
 // This function can be called concurrently, but the first will perform the shutdown action.
+//
+// The shutdown drains the server. The OnDrainStart hooks are called while the listener is still open,
+// then the server stops accepting connections and waits for the in-flight requests to complete. The wait
+// is bounded by the context and by the HTTPServerShutdownGracePeriodSeconds configuration. When the wait
+// is cut short, the remaining connections are closed. Periodic tasks are stopped once the server is no
+// longer accepting requests, and the OnDrainComplete hooks are called last with the result of the drain.
 func (server *Server) Shutdown(ctx context.Context) error {
 	var err error
 	if !server.shutdown.Swap(true) {
-		err = server.srv.Shutdown(ctx)
+		drainCtx := ctx
+		if server.gracePeriod > 0 {
+			var cancel context.CancelFunc
+			drainCtx, cancel = context.WithTimeout(ctx, server.gracePeriod)
+			defer cancel()
+		}
+		for _, hook := range server.onDrainStart {
+			hook(drainCtx)
+		}
+		err = server.srv.Shutdown(drainCtx)
+		if err != nil {
+			_ = server.srv.Close()
+		}
+		if server.acmeHTTPServer != nil {
+			_ = server.acmeHTTPServer.Close()
+		}
+		server.cancelBaseCtx()
+		for _, hook := range server.onDrainComplete {
+			hook(ctx, err)
+		}
 	}
 	server.wg.Wait()
 	return err
 }
 
-// loadMutualTLSClientCAs loads client CA certificates for mutual TLS.
-func loadMutualTLSClientCAs(clientCaCertPaths []string) (*x509.CertPool, error) {
-	clientCAs := x509.NewCertPool()
-	for _, caCertPath := range clientCaCertPaths {
-		caCert, err := os.ReadFile(caCertPath)
-		if err != nil {
-			return nil, fmt.Errorf("could not read client CA certificate on path %s (%w)", caCertPath, err)
+// normalizeRequest wraps the handler to make requests from non-conforming clients consistent before routing.
+//
+// HTTP/1.1 requires the Host header and requests without it are rejected by the http.Server, but HTTP/1.0 does not.
+// When the Host is missing, it is set to the address the server is bound to so features that rely on it behave predictably.
+//
+// HTTP/1.0 connections are closed after each response unless the client sends a "Connection: keep-alive" header,
+// in which case the connection is reused like an HTTP/1.1 connection.
+//
+// Requests with a URI longer than the maximum URL length are rejected before routing so that
+// extremely long URLs do not reach the router, the middleware, or the logs.
+func (server *Server) normalizeRequest(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if len(request.RequestURI) > server.maxURLLength {
+			responders.Error(request, writer, &httperrors.URITooLong{
+				Err: fmt.Errorf("the request URI exceeds the maximum length of %d bytes", server.maxURLLength),
+			})
+			return
+		}
+		if request.Host == "" {
+			if boundAddr := server.boundAddr.Load(); boundAddr != nil {
+				request.Host = *boundAddr
+			}
 		}
-		if ok := clientCAs.AppendCertsFromPEM(caCert); !ok {
-			return nil, fmt.Errorf("failed to append client CA certificate (%s)", caCertPath)
+		handler.ServeHTTP(writer, request)
+	})
+}
+
+// mergeNonZeroFields sets the zero value fields of the destination to the corresponding values of the source.
+func mergeNonZeroFields[T any](destination *T, source *T) {
+	destinationValue := reflect.ValueOf(destination).Elem()
+	sourceValue := reflect.ValueOf(source).Elem()
+	for fieldIndex := 0; fieldIndex < destinationValue.NumField(); fieldIndex++ {
+		destinationField := destinationValue.Field(fieldIndex)
+		if destinationField.IsZero() {
+			destinationField.Set(sourceValue.Field(fieldIndex))
 		}
 	}
-	return clientCAs, nil
 }
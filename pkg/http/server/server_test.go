@@ -1,42 +1,58 @@
 package server_test
 
 import (
+	"bufio"
 	"context"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/json"
 	"encoding/pem"
 	"errors"
 	"io"
 	"math/big"
 	"net"
 	"net/http"
+	"net/http/httptrace"
+	"net/textproto"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/TriangleSide/GoBase/pkg/config"
 	"github.com/TriangleSide/GoBase/pkg/config/envprocessor"
 	"github.com/TriangleSide/GoBase/pkg/http/api"
+	httperrors "github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
 	"github.com/TriangleSide/GoBase/pkg/http/middleware"
+	"github.com/TriangleSide/GoBase/pkg/http/middleware/metrics"
+	"github.com/TriangleSide/GoBase/pkg/http/openapi"
+	"github.com/TriangleSide/GoBase/pkg/http/responders"
 	"github.com/TriangleSide/GoBase/pkg/http/server"
+	"github.com/TriangleSide/GoBase/pkg/http/websocket"
 	"github.com/TriangleSide/GoBase/pkg/test/assert"
 )
 
 type testHandler struct {
-	Path       string
-	Method     string
-	Middleware []middleware.Middleware
-	Handler    http.HandlerFunc
+	Path                 string
+	Method               string
+	Middleware           []middleware.Middleware
+	Handler              http.HandlerFunc
+	AcceptedContentTypes []string
+	Limits               *middleware.Limits
 }
 
 func (t *testHandler) AcceptHTTPAPIBuilder(builder *api.HTTPAPIBuilder) {
 	builder.MustRegister(api.Path(t.Path), api.Method(t.Method), &api.Handler{
-		Middleware: t.Middleware,
-		Handler:    t.Handler,
+		Middleware:           t.Middleware,
+		Handler:              t.Handler,
+		AcceptedContentTypes: t.AcceptedContentTypes,
+		Limits:               t.Limits,
 	})
 }
 
@@ -105,6 +121,67 @@ func TestServer(t *testing.T) {
 		assert.Nil(t, srv)
 	})
 
+	t.Run("when config providers are composed it should merge the fields with the first provider taking precedence", func(t *testing.T) {
+		t.Parallel()
+		configFilePath := filepath.Join(t.TempDir(), "config.json")
+		assert.NoError(t, os.WriteFile(configFilePath, []byte(`{"HTTPServerBindIP":"127.0.0.1","HTTPServerReadTimeoutSeconds":5}`), 0644))
+		fileProvider := func() (*config.HTTPServer, error) {
+			contents, err := os.ReadFile(configFilePath)
+			if err != nil {
+				return nil, err
+			}
+			cfg := &config.HTTPServer{}
+			if err := json.Unmarshal(contents, cfg); err != nil {
+				return nil, err
+			}
+			return cfg, nil
+		}
+		missingProvider := func() (*config.HTTPServer, error) {
+			return nil, nil
+		}
+		envProvider := func() (*config.HTTPServer, error) {
+			return envprocessor.ProcessAndValidate[config.HTTPServer]()
+		}
+		waitUntilReady := make(chan bool)
+		var boundAddr *net.TCPAddr
+		srv, err := server.New(server.WithConfigProviders(fileProvider, missingProvider, envProvider), server.WithBoundCallback(func(addr *net.TCPAddr) {
+			boundAddr = addr
+			close(waitUntilReady)
+		}))
+		assert.NoError(t, err)
+		assert.NotNil(t, srv)
+		t.Cleanup(func() {
+			assert.NoError(t, srv.Shutdown(context.Background()))
+		})
+		go func() {
+			assert.NoError(t, srv.Run())
+		}()
+		<-waitUntilReady
+		assert.Equals(t, boundAddr.IP.String(), "127.0.0.1")
+	})
+
+	t.Run("when a composed config provider fails it should fail to create the server", func(t *testing.T) {
+		t.Parallel()
+		srv, err := server.New(server.WithConfigProviders(func() (*config.HTTPServer, error) {
+			return envprocessor.ProcessAndValidate[config.HTTPServer]()
+		}, func() (*config.HTTPServer, error) {
+			return nil, errors.New("provider error")
+		}))
+		assert.ErrorPart(t, err, "config provider at index 1 failed (provider error)")
+		assert.Nil(t, srv)
+	})
+
+	t.Run("when composed config providers yield an invalid config it should fail to create the server", func(t *testing.T) {
+		t.Parallel()
+		srv, err := server.New(server.WithConfigProviders(func() (*config.HTTPServer, error) {
+			return &config.HTTPServer{HTTPServerBindIP: "::1"}, nil
+		}, func() (*config.HTTPServer, error) {
+			return &config.HTTPServer{HTTPServerTLSMode: config.HTTPServerTLSModeOff}, nil
+		}))
+		assert.ErrorPart(t, err, "failed to validate the merged configuration")
+		assert.Nil(t, srv)
+	})
+
 	t.Run("when a server is started it should fail if the TLS mode is invalid", func(t *testing.T) {
 		t.Parallel()
 		srv, err := server.New(server.WithConfigProvider(func() (*config.HTTPServer, error) {
@@ -276,6 +353,609 @@ func TestServer(t *testing.T) {
 		assert.Equals(t, seq, []string{"0", "1", "2", "3", "4"})
 	})
 
+	t.Run("when the client cancels the request it should cancel the context seen by the handler through the middleware", func(t *testing.T) {
+		t.Parallel()
+		type contextKey struct{}
+		handlerStarted := make(chan struct{})
+		handlerCancelled := make(chan error, 1)
+		serverAddr := startServer(t, server.WithCommonMiddleware(
+			func(next http.HandlerFunc) http.HandlerFunc {
+				return func(writer http.ResponseWriter, request *http.Request) {
+					next(writer, request.WithContext(context.WithValue(request.Context(), contextKey{}, "value")))
+				}
+			},
+		), server.WithEndpointHandlers(&testHandler{
+			Path:   "/slow",
+			Method: http.MethodGet,
+			Handler: func(writer http.ResponseWriter, request *http.Request) {
+				close(handlerStarted)
+				select {
+				case <-request.Context().Done():
+					handlerCancelled <- request.Context().Err()
+				case <-time.After(10 * time.Second):
+					handlerCancelled <- errors.New("the handler was not cancelled")
+				}
+			},
+		}))
+		ctx, cancel := context.WithCancel(context.Background())
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+serverAddr+"/slow", nil)
+		assert.NoError(t, err)
+		go func() {
+			<-handlerStarted
+			cancel()
+		}()
+		response, err := http.DefaultClient.Do(request)
+		assert.Error(t, err)
+		assert.Nil(t, response)
+		assert.ErrorExact(t, <-handlerCancelled, context.Canceled.Error())
+	})
+
+	t.Run("when a websocket route is served it should upgrade through the common middleware", func(t *testing.T) {
+		t.Parallel()
+		serverAddr := startServer(t, server.WithCommonMiddleware(middleware.Compress()), server.WithEndpointHandlers(&testHandler{
+			Path:   "/ws",
+			Method: http.MethodGet,
+			Handler: websocket.Handler(func(conn *websocket.Conn, request *http.Request) {
+				messageType, data, err := conn.ReadMessage()
+				if err == nil {
+					_ = conn.WriteMessage(messageType, data)
+				}
+			}),
+		}))
+		conn, err := net.Dial("tcp", serverAddr)
+		assert.NoError(t, err)
+		t.Cleanup(func() {
+			_ = conn.Close()
+		})
+		_, err = io.WriteString(conn, "GET /ws HTTP/1.1\r\nHost: "+serverAddr+"\r\nAccept-Encoding: gzip\r\n"+
+			"Connection: Upgrade\r\nUpgrade: websocket\r\nSec-WebSocket-Version: 13\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n")
+		assert.NoError(t, err)
+		assert.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second*10)))
+		reader := bufio.NewReader(conn)
+		response, err := http.ReadResponse(reader, nil)
+		assert.NoError(t, err)
+		assert.Equals(t, response.StatusCode, http.StatusSwitchingProtocols)
+		_, err = conn.Write([]byte{0x81, 0x82, 0, 0, 0, 0, 'h', 'i'})
+		assert.NoError(t, err)
+		echoed := make([]byte, 4)
+		_, err = io.ReadFull(reader, echoed)
+		assert.NoError(t, err)
+		assert.Equals(t, echoed, []byte{0x81, 0x02, 'h', 'i'})
+	})
+
+	t.Run("when drain hooks are nil it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicExact(t, func() {
+			server.WithOnDrainStart(nil)
+		}, "the drain start hook cannot be nil")
+		assert.PanicExact(t, func() {
+			server.WithOnDrainComplete(nil)
+		}, "the drain complete hook cannot be nil")
+	})
+
+	runDrainServer := func(t *testing.T, gracePeriodSeconds int, options ...server.Option) (*server.Server, string) {
+		t.Helper()
+		waitUntilReady := make(chan bool)
+		var address string
+		allOpts := append(options, server.WithConfigProvider(func() (*config.HTTPServer, error) {
+			cfg, err := envprocessor.ProcessAndValidate[config.HTTPServer]()
+			if err != nil {
+				return nil, err
+			}
+			cfg.HTTPServerShutdownGracePeriodSeconds = gracePeriodSeconds
+			return cfg, nil
+		}), server.WithBoundCallback(func(addr *net.TCPAddr) {
+			address = addr.String()
+			close(waitUntilReady)
+		}))
+		srv, err := server.New(allOpts...)
+		assert.NoError(t, err)
+		runErr := make(chan error, 1)
+		go func() {
+			runErr <- srv.Run()
+		}()
+		t.Cleanup(func() {
+			assert.NoError(t, <-runErr)
+		})
+		<-waitUntilReady
+		return srv, address
+	}
+
+	t.Run("when the server shuts down it should call the drain hooks around the drain", func(t *testing.T) {
+		t.Parallel()
+		var events []string
+		var address string
+		srv, address := runDrainServer(t, 10, server.WithEndpointHandlers(handler), server.WithOnDrainStart(func(ctx context.Context) {
+			events = append(events, "start")
+			_, hasDeadline := ctx.Deadline()
+			assert.True(t, hasDeadline)
+			assertRootRequestSuccess(t, http.DefaultClient, address, false)
+			events = append(events, "served during drain start")
+		}), server.WithOnDrainComplete(func(ctx context.Context, err error) {
+			assert.NoError(t, err)
+			events = append(events, "complete")
+		}))
+		assert.NoError(t, srv.Shutdown(context.Background()))
+		assert.Equals(t, events, []string{"start", "served during drain start", "complete"})
+		_, err := http.Get("http://" + address)
+		assert.Error(t, err)
+	})
+
+	t.Run("when in-flight requests outlast the grace period it should close their connections", func(t *testing.T) {
+		t.Parallel()
+		handlerStarted := make(chan struct{})
+		releaseHandler := make(chan struct{})
+		t.Cleanup(func() {
+			close(releaseHandler)
+		})
+		var drainErr error
+		srv, address := runDrainServer(t, 1, server.WithEndpointHandlers(&testHandler{
+			Path:   "/slow",
+			Method: http.MethodGet,
+			Handler: func(writer http.ResponseWriter, request *http.Request) {
+				close(handlerStarted)
+				<-releaseHandler
+			},
+		}), server.WithOnDrainComplete(func(ctx context.Context, err error) {
+			drainErr = err
+		}))
+		requestErr := make(chan error, 1)
+		go func() {
+			response, err := http.Get("http://" + address + "/slow")
+			if err == nil {
+				_ = response.Body.Close()
+			}
+			requestErr <- err
+		}()
+		<-handlerStarted
+		start := time.Now()
+		err := srv.Shutdown(context.Background())
+		assert.ErrorExact(t, err, context.DeadlineExceeded.Error())
+		assert.ErrorExact(t, drainErr, context.DeadlineExceeded.Error())
+		assert.True(t, time.Since(start) >= time.Second)
+		assert.Error(t, <-requestErr)
+	})
+
+	t.Run("when routes have accepted content types it should reject the bodies of other types per route", func(t *testing.T) {
+		t.Parallel()
+		endpointMiddlewareCalled := false
+		serverAddr := startServer(t, server.WithEndpointHandlers(&testHandler{
+			Path:   "/json",
+			Method: http.MethodPost,
+			Middleware: []middleware.Middleware{
+				func(next http.HandlerFunc) http.HandlerFunc {
+					return func(writer http.ResponseWriter, request *http.Request) {
+						endpointMiddlewareCalled = true
+						next(writer, request)
+					}
+				},
+			},
+			Handler: func(writer http.ResponseWriter, request *http.Request) {
+				writer.WriteHeader(http.StatusOK)
+			},
+			AcceptedContentTypes: []string{headers.ContentTypeApplicationJson},
+		}, &testHandler{
+			Path:   "/upload",
+			Method: http.MethodPost,
+			Handler: func(writer http.ResponseWriter, request *http.Request) {
+				writer.WriteHeader(http.StatusOK)
+			},
+			AcceptedContentTypes: []string{headers.ContentTypeApplicationJson, "multipart/form-data"},
+		}))
+
+		response, err := http.Post("http://"+serverAddr+"/json", "multipart/form-data; boundary=x", strings.NewReader("body"))
+		assert.NoError(t, err)
+		assert.Equals(t, response.StatusCode, http.StatusUnsupportedMediaType)
+		assert.NoError(t, response.Body.Close())
+		assert.False(t, endpointMiddlewareCalled)
+
+		response, err = http.Post("http://"+serverAddr+"/upload", "multipart/form-data; boundary=x", strings.NewReader("body"))
+		assert.NoError(t, err)
+		assert.Equals(t, response.StatusCode, http.StatusOK)
+		assert.NoError(t, response.Body.Close())
+
+		response, err = http.Post("http://"+serverAddr+"/json", headers.ContentTypeApplicationJson, strings.NewReader("{}"))
+		assert.NoError(t, err)
+		assert.Equals(t, response.StatusCode, http.StatusOK)
+		assert.NoError(t, response.Body.Close())
+		assert.True(t, endpointMiddlewareCalled)
+	})
+
+	t.Run("when a route has limits it should reject large and slow request bodies", func(t *testing.T) {
+		t.Parallel()
+		type echoParams struct {
+			Name string `json:"name"`
+		}
+		serverAddr := startServer(t, server.WithEndpointHandlers(&testHandler{
+			Path:   "/limited",
+			Method: http.MethodPost,
+			Limits: &middleware.Limits{MaxBodyBytes: 32, ReadTimeout: time.Millisecond * 100},
+			Handler: func(writer http.ResponseWriter, request *http.Request) {
+				responders.JSON[echoParams, echoParams](writer, request, func(params *echoParams) (*echoParams, int, error) {
+					return params, http.StatusOK, nil
+				})
+			},
+		}))
+
+		response, err := http.Post("http://"+serverAddr+"/limited", headers.ContentTypeApplicationJson, strings.NewReader(`{"name":"`+strings.Repeat("a", 32)+`"}`))
+		assert.NoError(t, err)
+		assert.Equals(t, response.StatusCode, http.StatusRequestEntityTooLarge)
+		assert.NoError(t, response.Body.Close())
+
+		response, err = http.Post("http://"+serverAddr+"/limited", headers.ContentTypeApplicationJson, strings.NewReader(`{"name":"gopher"}`))
+		assert.NoError(t, err)
+		assert.Equals(t, response.StatusCode, http.StatusOK)
+		assert.NoError(t, response.Body.Close())
+
+		conn, err := net.Dial("tcp", serverAddr)
+		assert.NoError(t, err)
+		t.Cleanup(func() {
+			_ = conn.Close()
+		})
+		_, err = io.WriteString(conn, "POST /limited HTTP/1.1\r\nHost: "+serverAddr+"\r\nContent-Type: application/json\r\nContent-Length: 17\r\n\r\n{\"name\"")
+		assert.NoError(t, err)
+		assert.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second*10)))
+		response, err = http.ReadResponse(bufio.NewReader(conn), nil)
+		assert.NoError(t, err)
+		assert.Equals(t, response.StatusCode, http.StatusRequestTimeout)
+		assert.NoError(t, response.Body.Close())
+	})
+
+	t.Run("when a handler writes the header twice it should only send the first status", func(t *testing.T) {
+		t.Parallel()
+		serverAddr := startServer(t, server.WithEndpointHandlers(&testHandler{
+			Path:   "/double",
+			Method: http.MethodGet,
+			Handler: func(writer http.ResponseWriter, request *http.Request) {
+				writer.WriteHeader(http.StatusCreated)
+				writer.WriteHeader(http.StatusInternalServerError)
+				writer.WriteHeader(http.StatusBadRequest)
+				_, err := io.WriteString(writer, "body")
+				assert.NoError(t, err)
+			},
+		}))
+		response, err := http.Get("http://" + serverAddr + "/double")
+		assert.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, response.Body.Close())
+		})
+		assert.Equals(t, response.StatusCode, http.StatusCreated)
+		body, err := io.ReadAll(response.Body)
+		assert.NoError(t, err)
+		assert.Equals(t, string(body), "body")
+	})
+
+	t.Run("when a handler sends early hints it should send the final status after them", func(t *testing.T) {
+		t.Parallel()
+		serverAddr := startServer(t, server.WithEndpointHandlers(&testHandler{
+			Path:   "/hints",
+			Method: http.MethodGet,
+			Handler: func(writer http.ResponseWriter, request *http.Request) {
+				writer.Header().Set("Link", "</style.css>; rel=preload")
+				writer.WriteHeader(http.StatusEarlyHints)
+				writer.WriteHeader(http.StatusOK)
+				_, err := io.WriteString(writer, "body")
+				assert.NoError(t, err)
+			},
+		}))
+		var informational []int
+		trace := &httptrace.ClientTrace{
+			Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+				informational = append(informational, code)
+				return nil
+			},
+		}
+		request, err := http.NewRequestWithContext(httptrace.WithClientTrace(context.Background(), trace), http.MethodGet, "http://"+serverAddr+"/hints", nil)
+		assert.NoError(t, err)
+		response, err := http.DefaultClient.Do(request)
+		assert.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, response.Body.Close())
+		})
+		assert.Equals(t, informational, []int{http.StatusEarlyHints})
+		assert.Equals(t, response.StatusCode, http.StatusOK)
+		body, err := io.ReadAll(response.Body)
+		assert.NoError(t, err)
+		assert.Equals(t, string(body), "body")
+	})
+
+	t.Run("when a handler writes the body before the header it should keep the implicit status", func(t *testing.T) {
+		t.Parallel()
+		serverAddr := startServer(t, server.WithEndpointHandlers(&testHandler{
+			Path:   "/implicit",
+			Method: http.MethodGet,
+			Handler: func(writer http.ResponseWriter, request *http.Request) {
+				_, err := io.WriteString(writer, "body")
+				assert.NoError(t, err)
+				writer.WriteHeader(http.StatusInternalServerError)
+			},
+		}))
+		response, err := http.Get("http://" + serverAddr + "/implicit")
+		assert.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, response.Body.Close())
+		})
+		assert.Equals(t, response.StatusCode, http.StatusOK)
+	})
+
+	t.Run("when a route has patterned and wildcard parameters it should only run the handler for matching paths", func(t *testing.T) {
+		t.Parallel()
+		type userParams struct {
+			ID int `urlPath:"id" json:"-"`
+		}
+		type fileParams struct {
+			Path string `urlPath:"path" json:"-"`
+		}
+		type pathResponse struct {
+			Value string `json:"value"`
+		}
+		serverAddr := startServer(t, server.WithEndpointHandlers(&testHandler{
+			Path:   "/users/{id:int}",
+			Method: http.MethodGet,
+			Handler: func(writer http.ResponseWriter, request *http.Request) {
+				responders.JSON(writer, request, func(params *userParams) (*pathResponse, int, error) {
+					return &pathResponse{Value: strconv.Itoa(params.ID)}, http.StatusOK, nil
+				})
+			},
+		}, &testHandler{
+			Path:   "/static/{path...}",
+			Method: http.MethodGet,
+			Handler: func(writer http.ResponseWriter, request *http.Request) {
+				responders.JSON(writer, request, func(params *fileParams) (*pathResponse, int, error) {
+					return &pathResponse{Value: params.Path}, http.StatusOK, nil
+				})
+			},
+		}))
+
+		get := func(path string) (int, string) {
+			response, err := http.Get("http://" + serverAddr + path)
+			assert.NoError(t, err)
+			defer func() {
+				assert.NoError(t, response.Body.Close())
+			}()
+			body := &pathResponse{}
+			if response.StatusCode == http.StatusOK {
+				assert.NoError(t, json.NewDecoder(response.Body).Decode(body))
+			}
+			return response.StatusCode, body.Value
+		}
+
+		status, value := get("/users/42")
+		assert.Equals(t, status, http.StatusOK)
+		assert.Equals(t, value, "42")
+		status, _ = get("/users/abc")
+		assert.Equals(t, status, http.StatusNotFound)
+		status, value = get("/static/css/site.css")
+		assert.Equals(t, status, http.StatusOK)
+		assert.Equals(t, value, "css/site.css")
+	})
+
+	t.Run("when a path is requested with a method without a handler it should answer through the common middleware", func(t *testing.T) {
+		t.Parallel()
+		newItemsHandler := func() *testHandler {
+			return &testHandler{
+				Path:   "/items",
+				Method: http.MethodGet,
+				Handler: func(writer http.ResponseWriter, request *http.Request) {
+					_, err := io.WriteString(writer, "items")
+					assert.NoError(t, err)
+				},
+			}
+		}
+		markerMiddleware := func(next http.HandlerFunc) http.HandlerFunc {
+			return func(writer http.ResponseWriter, request *http.Request) {
+				writer.Header().Set("X-Common", "true")
+				next(writer, request)
+			}
+		}
+		request := func(serverAddr string, method string, path string) *http.Response {
+			httpRequest, err := http.NewRequest(method, "http://"+serverAddr+path, nil)
+			assert.NoError(t, err)
+			response, err := http.DefaultClient.Do(httpRequest)
+			assert.NoError(t, err)
+			assert.NoError(t, response.Body.Close())
+			return response
+		}
+
+		serverAddr := startServer(t, server.WithCommonMiddleware(markerMiddleware), server.WithEndpointHandlers(newItemsHandler()))
+		response := request(serverAddr, http.MethodDelete, "/items")
+		assert.Equals(t, response.StatusCode, http.StatusMethodNotAllowed)
+		assert.Equals(t, response.Header.Get(headers.Allow), "GET, HEAD, OPTIONS")
+		assert.Equals(t, response.Header.Get("X-Common"), "true")
+		response = request(serverAddr, http.MethodOptions, "/items")
+		assert.Equals(t, response.StatusCode, http.StatusNoContent)
+		assert.Equals(t, response.Header.Get(headers.Allow), "GET, HEAD, OPTIONS")
+		response = request(serverAddr, http.MethodHead, "/items")
+		assert.Equals(t, response.StatusCode, http.StatusOK)
+		assert.Equals(t, response.ContentLength, int64(len("items")))
+		response = request(serverAddr, http.MethodDelete, "/missing")
+		assert.Equals(t, response.StatusCode, http.StatusNotFound)
+
+		serverAddr = startServer(t, server.WithEndpointHandlers(newItemsHandler()), server.WithAPIBuilderOptions(
+			api.WithoutAutomaticHead(), api.WithoutAutomaticOptions()))
+		response = request(serverAddr, http.MethodHead, "/items")
+		assert.Equals(t, response.StatusCode, http.StatusMethodNotAllowed)
+		assert.Equals(t, response.Header.Get(headers.Allow), "GET")
+		response = request(serverAddr, http.MethodOptions, "/items")
+		assert.Equals(t, response.StatusCode, http.StatusMethodNotAllowed)
+
+		serverAddr = startServer(t, server.WithEndpointHandlers(newItemsHandler()), server.WithAPIBuilderOptions(api.WithoutMethodNotAllowed()))
+		response = request(serverAddr, http.MethodDelete, "/items")
+		assert.Equals(t, response.StatusCode, http.StatusNotFound)
+	})
+
+	t.Run("when the request URI is longer than the maximum URL length it should respond with URI too long", func(t *testing.T) {
+		t.Parallel()
+		serverAddr := startServer(t, server.WithMaxURLLength(64))
+
+		response, err := http.Get("http://" + serverAddr + "/?value=" + strings.Repeat("a", 64))
+		assert.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, response.Body.Close())
+		})
+		assert.Equals(t, response.StatusCode, http.StatusRequestURITooLong)
+		responseBody := &httperrors.Error{}
+		assert.NoError(t, json.NewDecoder(response.Body).Decode(responseBody))
+		assert.Equals(t, responseBody.Message, "the request URI exceeds the maximum length of 64 bytes")
+
+		response, err = http.Get("http://" + serverAddr + "/?value=short")
+		assert.NoError(t, err)
+		assert.Equals(t, response.StatusCode, http.StatusOK)
+		assert.NoError(t, response.Body.Close())
+	})
+
+	t.Run("when the maximum URL length is not positive it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			server.WithMaxURLLength(0)
+		}, "the maximum URL length must be greater than zero")
+	})
+
+	t.Run("when the metrics endpoint is enabled it should serve the recorded requests", func(t *testing.T) {
+		t.Parallel()
+		serverAddr := startServer(t, server.WithMetricsEndpoint("/metrics", metrics.New()))
+
+		response, err := http.Get("http://" + serverAddr + "/")
+		assert.NoError(t, err)
+		assert.Equals(t, response.StatusCode, http.StatusOK)
+		assert.NoError(t, response.Body.Close())
+
+		response, err = http.Get("http://" + serverAddr + "/metrics")
+		assert.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, response.Body.Close())
+		})
+		assert.Equals(t, response.StatusCode, http.StatusOK)
+		assert.Equals(t, response.Header.Get(headers.ContentType), metrics.ContentType)
+		body, err := io.ReadAll(response.Body)
+		assert.NoError(t, err)
+		assert.Contains(t, string(body), "http_requests_total{method=\"GET\",path=\"/\",status=\"200\"} 1\n")
+		assert.Contains(t, string(body), "http_requests_in_flight 1\n")
+	})
+
+	t.Run("when the metrics are nil it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicExact(t, func() {
+			server.WithMetricsEndpoint("/metrics", nil)
+		}, "the metrics cannot be nil")
+	})
+
+	t.Run("when the OpenAPI endpoint is enabled it should serve the document of the routes", func(t *testing.T) {
+		t.Parallel()
+		serverAddr := startServer(t, server.WithOpenAPIEndpoint("/openapi", openapi.Info{Title: "Test", Version: "1.0.0"}))
+
+		response, err := http.Get("http://" + serverAddr + "/openapi")
+		assert.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, response.Body.Close())
+		})
+		assert.Equals(t, response.StatusCode, http.StatusOK)
+		assert.Equals(t, response.Header.Get(headers.ContentType), headers.ContentTypeApplicationJson)
+		document := &openapi.Document{}
+		assert.NoError(t, json.NewDecoder(response.Body).Decode(document))
+		assert.Equals(t, document.Info.Title, "Test")
+		assert.NotNil(t, document.Paths["/"]["get"])
+	})
+
+	t.Run("when the log level endpoint is enabled it should serve the log levels", func(t *testing.T) {
+		t.Parallel()
+		serverAddr := startServer(t, server.WithLogLevelEndpoint("/admin/loglevel"))
+
+		response, err := http.Get("http://" + serverAddr + "/admin/loglevel")
+		assert.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, response.Body.Close())
+		})
+		assert.Equals(t, response.StatusCode, http.StatusOK)
+		assert.Equals(t, response.Header.Get(headers.ContentType), headers.ContentTypeApplicationJson)
+		levels := map[string]any{}
+		assert.NoError(t, json.NewDecoder(response.Body).Decode(&levels))
+		assert.NotNil(t, levels["level"])
+
+		for _, method := range []string{http.MethodPut, http.MethodPost} {
+			request, err := http.NewRequest(method, "http://"+serverAddr+"/admin/loglevel", strings.NewReader("{"))
+			assert.NoError(t, err)
+			updateResponse, err := http.DefaultClient.Do(request)
+			assert.NoError(t, err)
+			assert.NoError(t, updateResponse.Body.Close())
+			assert.Equals(t, updateResponse.StatusCode, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("when HTTP/1.0 requests are made with and without a Host header", func(t *testing.T) {
+		t.Parallel()
+		serverAddr := startServer(t, server.WithEndpointHandlers(&testHandler{
+			Path:   "/host",
+			Method: http.MethodGet,
+			Handler: func(writer http.ResponseWriter, request *http.Request) {
+				writer.WriteHeader(http.StatusOK)
+				_, err := io.WriteString(writer, request.Host)
+				assert.NoError(t, err)
+			},
+		}))
+
+		rawRequest := func(t *testing.T, conn net.Conn, rawRequest string) (*http.Response, string) {
+			t.Helper()
+			_, err := io.WriteString(conn, rawRequest)
+			assert.NoError(t, err)
+			response, err := http.ReadResponse(bufio.NewReader(conn), nil)
+			assert.NoError(t, err)
+			body, err := io.ReadAll(response.Body)
+			assert.NoError(t, err)
+			assert.NoError(t, response.Body.Close())
+			return response, string(body)
+		}
+
+		t.Run("when the Host header is missing it should use the bound address and close the connection", func(t *testing.T) {
+			t.Parallel()
+			conn, err := net.Dial("tcp", serverAddr)
+			assert.NoError(t, err)
+			t.Cleanup(func() {
+				assert.NoError(t, conn.Close())
+			})
+			response, body := rawRequest(t, conn, "GET /host HTTP/1.0\r\n\r\n")
+			assert.Equals(t, response.StatusCode, http.StatusOK)
+			assert.Equals(t, body, serverAddr)
+			assert.True(t, response.Close)
+		})
+
+		t.Run("when the Host header is present it should be kept", func(t *testing.T) {
+			t.Parallel()
+			conn, err := net.Dial("tcp", serverAddr)
+			assert.NoError(t, err)
+			t.Cleanup(func() {
+				assert.NoError(t, conn.Close())
+			})
+			response, body := rawRequest(t, conn, "GET /host HTTP/1.0\r\nHost: example.com\r\n\r\n")
+			assert.Equals(t, response.StatusCode, http.StatusOK)
+			assert.Equals(t, body, "example.com")
+		})
+
+		t.Run("when keep-alive is requested it should reuse the connection", func(t *testing.T) {
+			t.Parallel()
+			conn, err := net.Dial("tcp", serverAddr)
+			assert.NoError(t, err)
+			t.Cleanup(func() {
+				assert.NoError(t, conn.Close())
+			})
+			for i := 0; i < 2; i++ {
+				response, body := rawRequest(t, conn, "GET /host HTTP/1.0\r\nConnection: keep-alive\r\n\r\n")
+				assert.Equals(t, response.StatusCode, http.StatusOK)
+				assert.Equals(t, body, serverAddr)
+				assert.False(t, response.Close)
+			}
+		})
+	})
+
+	t.Run("when a server does not use TLS it should not have TLS info", func(t *testing.T) {
+		t.Parallel()
+		srv, err := server.New()
+		assert.NoError(t, err)
+		tlsInfo, hasTLS := srv.TLSInfo()
+		assert.False(t, hasTLS)
+		assert.Equals(t, tlsInfo, server.TLSInfo{})
+	})
+
 	t.Run("when a server is started without TLS an HTTP client should be able to make requests", func(t *testing.T) {
 		t.Parallel()
 		serverAddr := startServer(t)
@@ -290,6 +970,194 @@ func TestServer(t *testing.T) {
 		assertRootRequestSuccess(t, httpClient, serverAddr, false)
 	})
 
+	t.Run("when h2c is enabled it should serve HTTP/2 cleartext with prior knowledge", func(t *testing.T) {
+		t.Parallel()
+		newH2CClient := func() *http.Client {
+			protocols := &http.Protocols{}
+			protocols.SetUnencryptedHTTP2(true)
+			return &http.Client{Transport: &http.Transport{Protocols: protocols}}
+		}
+
+		serverAddr := startServer(t, server.WithConfigProvider(func() (*config.HTTPServer, error) {
+			cfg, err := envprocessor.ProcessAndValidate[config.HTTPServer]()
+			assert.NoError(t, err)
+			cfg.HTTPServerH2CEnabled = true
+			cfg.HTTPServerHTTP2MaxConcurrentStreams = 10
+			return cfg, nil
+		}))
+		response, err := newH2CClient().Get("http://" + serverAddr)
+		assert.NoError(t, err)
+		assert.Equals(t, response.ProtoMajor, 2)
+		assert.Equals(t, response.StatusCode, http.StatusOK)
+		assert.NoError(t, response.Body.Close())
+
+		http1Response, err := http.Get("http://" + serverAddr)
+		assert.NoError(t, err)
+		assert.Equals(t, http1Response.ProtoMajor, 1)
+		assert.NoError(t, http1Response.Body.Close())
+
+		serverAddr = startServer(t)
+		_, err = newH2CClient().Get("http://" + serverAddr)
+		assert.Error(t, err)
+	})
+
+	t.Run("when h2c is enabled without HTTP/2 or with TLS it should fail to create the server", func(t *testing.T) {
+		t.Parallel()
+		testCases := []struct {
+			modify      func(cfg *config.HTTPServer)
+			expectedErr string
+		}{
+			{modify: func(cfg *config.HTTPServer) { cfg.HTTPServerHTTP2Enabled = false }, expectedErr: "h2c requires HTTP/2 to be enabled"},
+			{modify: func(cfg *config.HTTPServer) { cfg.HTTPServerTLSMode = config.HTTPServerTLSModeTLS }, expectedErr: "h2c can only be enabled when the TLS mode is off"},
+		}
+		for _, testCase := range testCases {
+			srv, err := server.New(server.WithConfigProvider(func() (*config.HTTPServer, error) {
+				cfg, err := envprocessor.ProcessAndValidate[config.HTTPServer]()
+				assert.NoError(t, err)
+				cfg.HTTPServerH2CEnabled = true
+				testCase.modify(cfg)
+				return cfg, nil
+			}))
+			assert.ErrorExact(t, err, testCase.expectedErr)
+			assert.Nil(t, srv)
+		}
+	})
+
+	t.Run("when the TLS mode is acme it should answer http-01 challenges on the plain HTTP listener", func(t *testing.T) {
+		t.Parallel()
+		const acmeHTTPPort = 18080
+		acmeAddr := make(chan string, 1)
+		srv, err := server.New(server.WithConfigProvider(func() (*config.HTTPServer, error) {
+			cfg, err := envprocessor.ProcessAndValidate[config.HTTPServer]()
+			assert.NoError(t, err)
+			cfg.HTTPServerTLSMode = config.HTTPServerTLSModeACME
+			cfg.HTTPServerACMEDomains = []string{"example.com"}
+			cfg.HTTPServerACMECacheDir = t.TempDir()
+			cfg.HTTPServerACMEStaging = true
+			cfg.HTTPServerACMEHTTPBindPort = acmeHTTPPort
+			return cfg, nil
+		}), server.WithListenerProvider(func(bindIP string, bindPort uint16) (*net.TCPListener, error) {
+			listener, err := net.ListenTCP("tcp", &net.TCPAddr{IP: net.ParseIP(bindIP), Port: 0})
+			if err == nil && bindPort == acmeHTTPPort {
+				acmeAddr <- listener.Addr().String()
+			}
+			return listener, err
+		}))
+		assert.NoError(t, err)
+		go func() {
+			assert.NoError(t, srv.Run())
+		}()
+		t.Cleanup(func() {
+			assert.NoError(t, srv.Shutdown(context.Background()))
+		})
+
+		httpClient := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}}
+		address := <-acmeAddr
+		response, err := httpClient.Get("http://" + address + "/path")
+		assert.NoError(t, err)
+		assert.NoError(t, response.Body.Close())
+		assert.Equals(t, response.StatusCode, http.StatusMovedPermanently)
+		assert.Equals(t, response.Header.Get("Location"), "https://"+address[:strings.LastIndex(address, ":")]+"/path")
+
+		response, err = httpClient.Get("http://" + address + "/.well-known/acme-challenge/unknown")
+		assert.NoError(t, err)
+		assert.NoError(t, response.Body.Close())
+		assert.Equals(t, response.StatusCode, http.StatusNotFound)
+	})
+
+	t.Run("when the TLS mode is acme and the cache directory cannot be created it should fail", func(t *testing.T) {
+		t.Parallel()
+		blockingFile := filepath.Join(t.TempDir(), "file")
+		assert.NoError(t, os.WriteFile(blockingFile, []byte("file"), 0600))
+		srv, err := server.New(server.WithConfigProvider(func() (*config.HTTPServer, error) {
+			cfg, err := envprocessor.ProcessAndValidate[config.HTTPServer]()
+			assert.NoError(t, err)
+			cfg.HTTPServerTLSMode = config.HTTPServerTLSModeACME
+			cfg.HTTPServerACMEDomains = []string{"example.com"}
+			cfg.HTTPServerACMECacheDir = filepath.Join(blockingFile, "cache")
+			return cfg, nil
+		}))
+		assert.ErrorPart(t, err, "failed to create the ACME manager (failed to create the ACME cache directory")
+		assert.Nil(t, srv)
+	})
+
+	t.Run("when the certificate files change it should serve the new certificate without restarting", func(t *testing.T) {
+		t.Parallel()
+		tempDir := t.TempDir()
+		certPath := filepath.Join(tempDir, "cert.pem")
+		keyPath := filepath.Join(tempDir, "key.pem")
+		writeCertificate := func(commonName string) {
+			privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+			assert.NoError(t, err)
+			certTemplate := x509.Certificate{
+				SerialNumber:          big.NewInt(time.Now().UnixNano()),
+				Subject:               pkix.Name{CommonName: commonName},
+				NotBefore:             time.Now(),
+				NotAfter:              time.Now().Add(24 * time.Hour),
+				KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+				ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+				BasicConstraintsValid: true,
+			}
+			certBytes, err := x509.CreateCertificate(rand.Reader, &certTemplate, &certTemplate, &privateKey.PublicKey, privateKey)
+			assert.NoError(t, err)
+			assert.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)}), 0600))
+			assert.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certBytes}), 0644))
+		}
+		writeCertificate("first")
+
+		waitUntilReady := make(chan bool)
+		var serverAddr string
+		srv, err := server.New(server.WithEndpointHandlers(handler), server.WithBoundCallback(func(addr *net.TCPAddr) {
+			serverAddr = addr.String()
+			close(waitUntilReady)
+		}), server.WithConfigProvider(func() (*config.HTTPServer, error) {
+			cfg, err := envprocessor.ProcessAndValidate[config.HTTPServer]()
+			assert.NoError(t, err)
+			cfg.HTTPServerTLSMode = config.HTTPServerTLSModeTLS
+			cfg.HTTPServerCert = certPath
+			cfg.HTTPServerKey = keyPath
+			cfg.HTTPServerCertReloadIntervalSeconds = 1
+			return cfg, nil
+		}))
+		assert.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, srv.Shutdown(context.Background()))
+		})
+		go func() {
+			assert.NoError(t, srv.Run())
+		}()
+		<-waitUntilReady
+
+		servedCommonName := func() string {
+			httpClient := &http.Client{
+				Transport: &http.Transport{
+					ForceAttemptHTTP2: true,
+					DisableKeepAlives: true,
+					TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+				},
+			}
+			response, err := httpClient.Get("https://" + serverAddr)
+			assert.NoError(t, err)
+			assert.Equals(t, response.StatusCode, http.StatusOK)
+			assert.Equals(t, response.ProtoMajor, 2)
+			assert.NoError(t, response.Body.Close())
+			return response.TLS.PeerCertificates[0].Subject.CommonName
+		}
+		assert.Equals(t, servedCommonName(), "first")
+
+		writeCertificate("second")
+		deadline := time.Now().Add(time.Second * 10)
+		for servedCommonName() != "second" {
+			assert.True(t, time.Now().Before(deadline))
+			time.Sleep(time.Millisecond * 100)
+		}
+		tlsInfo, hasTLS := srv.TLSInfo()
+		assert.True(t, hasTLS)
+		assert.Equals(t, tlsInfo.Certificates[0].Subject, "CN=second")
+	})
+
 	t.Run("when certificates are generated for TLS and mTLS", func(t *testing.T) {
 		t.Parallel()
 		tempDir := t.TempDir()
@@ -526,6 +1394,25 @@ func TestServer(t *testing.T) {
 			assert.Nil(t, response)
 		})
 
+		t.Run("when a server uses mutual TLS it should describe its TLS configuration", func(t *testing.T) {
+			t.Parallel()
+			srv, err := server.New(server.WithConfigProvider(func() (*config.HTTPServer, error) {
+				cfg := certPathsConfigProvider(t)
+				cfg.HTTPServerTLSMode = config.HTTPServerTLSModeMutualTLS
+				return cfg, nil
+			}))
+			assert.NoError(t, err)
+			tlsInfo, hasTLS := srv.TLSInfo()
+			assert.True(t, hasTLS)
+			assert.Equals(t, tlsInfo.MinVersion, "TLS 1.3")
+			assert.Equals(t, tlsInfo.ClientAuth, "RequireAndVerifyClientCert")
+			assert.True(t, len(tlsInfo.CipherSuites) > 0)
+			assert.Equals(t, len(tlsInfo.Certificates), 1)
+			assert.Equals(t, tlsInfo.Certificates[0].Subject, "O=Server Tests Inc.")
+			assert.Equals(t, tlsInfo.Certificates[0].Issuer, "O=Test CA")
+			assert.Equals(t, tlsInfo.Certificates[0].NotAfter.Unix(), serverCertTemplate.NotAfter.Unix())
+		})
+
 		t.Run("when a server is run with TLS it should succeed if the client is properly configured", func(t *testing.T) {
 			t.Parallel()
 			serverAddress := startServer(t, server.WithConfigProvider(func() (*config.HTTPServer, error) {
@@ -544,6 +1431,35 @@ func TestServer(t *testing.T) {
 			assertRootRequestSuccess(t, httpClient, serverAddress, true)
 		})
 
+		t.Run("when a server is run with TLS it should negotiate HTTP/2 unless it is disabled", func(t *testing.T) {
+			t.Parallel()
+			for _, http2Enabled := range []bool{true, false} {
+				serverAddress := startServer(t, server.WithConfigProvider(func() (*config.HTTPServer, error) {
+					cfg := certPathsConfigProvider(t)
+					cfg.HTTPServerTLSMode = config.HTTPServerTLSModeTLS
+					cfg.HTTPServerHTTP2Enabled = http2Enabled
+					return cfg, nil
+				}))
+				httpClient := &http.Client{
+					Transport: &http.Transport{
+						ForceAttemptHTTP2: true,
+						TLSClientConfig: &tls.Config{
+							RootCAs: caCertPool,
+						},
+					},
+				}
+				response, err := httpClient.Get("https://" + serverAddress)
+				assert.NoError(t, err)
+				assert.Equals(t, response.StatusCode, http.StatusOK)
+				if http2Enabled {
+					assert.Equals(t, response.ProtoMajor, 2)
+				} else {
+					assert.Equals(t, response.ProtoMajor, 1)
+				}
+				assert.NoError(t, response.Body.Close())
+			}
+		})
+
 		t.Run("when a server is run with TLS it should succeed if the client doesn't trust the CA but insecure is set", func(t *testing.T) {
 			t.Parallel()
 			serverAddress := startServer(t, server.WithConfigProvider(func() (*config.HTTPServer, error) {
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"time"
+)
+
+// CertificateInfo describes a certificate loaded by the server. It never contains the private key.
+type CertificateInfo struct {
+	Subject   string
+	Issuer    string
+	DNSNames  []string
+	NotBefore time.Time
+	NotAfter  time.Time
+}
+
+// TLSInfo describes the effective TLS configuration of the server. It is meant for diagnosing handshake failures.
+type TLSInfo struct {
+	MinVersion   string
+	CipherSuites []string
+	ClientAuth   string
+	Certificates []CertificateInfo
+}
+
+// TLSInfo returns the effective TLS configuration of the server.
+// It returns false if the server does not use TLS.
+//
+// When the configuration does not restrict the cipher suites, the cipher suites that Go enables by default are listed.
+// Certificates that cannot be parsed are listed with only their error in the subject.
+func (server *Server) TLSInfo() (TLSInfo, bool) {
+	tlsConfig := server.srv.TLSConfig
+	if tlsConfig == nil {
+		return TLSInfo{}, false
+	}
+
+	cipherSuites := make([]string, 0)
+	if len(tlsConfig.CipherSuites) != 0 {
+		for _, cipherSuite := range tlsConfig.CipherSuites {
+			cipherSuites = append(cipherSuites, tls.CipherSuiteName(cipherSuite))
+		}
+	} else {
+		for _, cipherSuite := range tls.CipherSuites() {
+			cipherSuites = append(cipherSuites, cipherSuite.Name)
+		}
+	}
+
+	servedCertificates := tlsConfig.Certificates
+	if server.tlsReloader != nil {
+		servedCertificates = server.tlsReloader.Certificates()
+	}
+	certificates := make([]CertificateInfo, 0, len(servedCertificates))
+	for _, certificate := range servedCertificates {
+		leaf := certificate.Leaf
+		if leaf == nil && len(certificate.Certificate) != 0 {
+			parsed, err := x509.ParseCertificate(certificate.Certificate[0])
+			if err != nil {
+				certificates = append(certificates, CertificateInfo{Subject: "unparsable certificate (" + err.Error() + ")"})
+				continue
+			}
+			leaf = parsed
+		}
+		if leaf == nil {
+			continue
+		}
+		certificates = append(certificates, CertificateInfo{
+			Subject:   leaf.Subject.String(),
+			Issuer:    leaf.Issuer.String(),
+			DNSNames:  leaf.DNSNames,
+			NotBefore: leaf.NotBefore,
+			NotAfter:  leaf.NotAfter,
+		})
+	}
+
+	return TLSInfo{
+		MinVersion:   tls.VersionName(tlsConfig.MinVersion),
+		CipherSuites: cipherSuites,
+		ClientAuth:   tlsConfig.ClientAuth.String(),
+		Certificates: certificates,
+	}, true
+}
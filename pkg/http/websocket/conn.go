@@ -0,0 +1,334 @@
+package websocket
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// MessageType is the type of the data in a WebSocket message.
+type MessageType byte
+
+const (
+	// TextMessage is a message with UTF-8 encoded text.
+	TextMessage MessageType = 0x1
+
+	// BinaryMessage is a message with binary data.
+	BinaryMessage MessageType = 0x2
+)
+
+// opcodes of the WebSocket frames that are not data messages.
+const (
+	opcodeContinuation byte = 0x0
+	opcodeClose        byte = 0x8
+	opcodePing         byte = 0x9
+	opcodePong         byte = 0xA
+)
+
+// maxControlPayloadBytes is the largest payload a control frame can have.
+const maxControlPayloadBytes = 125
+
+// CloseCode is the status code sent in a close frame to indicate why the connection is closed.
+type CloseCode uint16
+
+const (
+	// CloseNormal indicates that the purpose for which the connection was established has been fulfilled.
+	CloseNormal CloseCode = 1000
+
+	// CloseGoingAway indicates that an endpoint is going away, like a server shutting down.
+	CloseGoingAway CloseCode = 1001
+
+	// CloseProtocolError indicates that an endpoint received a frame that violates the protocol.
+	CloseProtocolError CloseCode = 1002
+
+	// CloseUnsupportedData indicates that an endpoint received a type of data it cannot accept.
+	CloseUnsupportedData CloseCode = 1003
+
+	// CloseNoStatus indicates that a close frame was received without a status code. It is never sent.
+	CloseNoStatus CloseCode = 1005
+
+	// CloseInvalidPayload indicates that an endpoint received data that is not consistent with the message type.
+	CloseInvalidPayload CloseCode = 1007
+
+	// ClosePolicyViolation indicates that an endpoint received a message that violates its policy.
+	ClosePolicyViolation CloseCode = 1008
+
+	// CloseMessageTooBig indicates that an endpoint received a message that is too big to process.
+	CloseMessageTooBig CloseCode = 1009
+
+	// CloseInternalError indicates that the server encountered an unexpected condition.
+	CloseInternalError CloseCode = 1011
+)
+
+// CloseError is returned when reading from a connection that the peer has closed.
+type CloseError struct {
+	Code   CloseCode
+	Reason string
+}
+
+// Error is CloseError implementing the error interface.
+func (e *CloseError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("the websocket connection was closed with the code %d", e.Code)
+	}
+	return fmt.Sprintf("the websocket connection was closed with the code %d (%s)", e.Code, e.Reason)
+}
+
+// Conn is the server side of a WebSocket connection.
+//
+// A Conn supports one concurrent reader and many concurrent writers. Pings from the peer are answered
+// while reading. If keepalive is configured, the Conn sends pings on an interval and the read fails if
+// nothing is received from the peer within the pong timeout.
+type Conn struct {
+	netConn         net.Conn
+	reader          *bufio.Reader
+	writeLock       sync.Mutex
+	cfg             *config
+	closeOnce       sync.Once
+	closed          chan struct{}
+	closeFrameWrote bool
+}
+
+// newConn wraps the hijacked network connection and starts the keepalive if it is configured.
+func newConn(netConn net.Conn, reader *bufio.Reader, cfg *config) *Conn {
+	conn := &Conn{
+		netConn:         netConn,
+		reader:          reader,
+		writeLock:       sync.Mutex{},
+		cfg:             cfg,
+		closeOnce:       sync.Once{},
+		closed:          make(chan struct{}),
+		closeFrameWrote: false,
+	}
+	conn.extendReadDeadline()
+	if cfg.pingInterval > 0 {
+		go conn.keepalive()
+	}
+	return conn
+}
+
+// RemoteAddr returns the network address of the peer.
+func (conn *Conn) RemoteAddr() net.Addr {
+	return conn.netConn.RemoteAddr()
+}
+
+// ReadMessage reads the next data message from the peer. Fragmented messages are reassembled.
+// Control frames are handled while reading. If the peer closes the connection, a *CloseError is returned.
+// If the peer violates the protocol, the connection is closed with the corresponding CloseCode.
+func (conn *Conn) ReadMessage() (MessageType, []byte, error) {
+	var messageType MessageType
+	var message []byte
+	inMessage := false
+
+	for {
+		fin, opcode, payload, err := conn.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+		conn.extendReadDeadline()
+
+		switch opcode {
+		case opcodePing:
+			if err := conn.writeFrame(opcodePong, payload); err != nil {
+				return 0, nil, err
+			}
+		case opcodePong:
+		case opcodeClose:
+			return 0, nil, conn.handleCloseFrame(payload)
+		case opcodeContinuation, byte(TextMessage), byte(BinaryMessage):
+			if (opcode == opcodeContinuation) != inMessage {
+				return 0, nil, conn.fail(CloseProtocolError, "unexpected continuation frame")
+			}
+			if opcode != opcodeContinuation {
+				messageType = MessageType(opcode)
+				inMessage = true
+			}
+			if conn.cfg.maxMessageBytes > 0 && int64(len(message)+len(payload)) > conn.cfg.maxMessageBytes {
+				return 0, nil, conn.fail(CloseMessageTooBig, fmt.Sprintf("the message exceeds %d bytes", conn.cfg.maxMessageBytes))
+			}
+			message = append(message, payload...)
+			if fin {
+				if messageType == TextMessage && !utf8.Valid(message) {
+					return 0, nil, conn.fail(CloseInvalidPayload, "the text message is not valid UTF-8")
+				}
+				if message == nil {
+					message = []byte{}
+				}
+				return messageType, message, nil
+			}
+		default:
+			return 0, nil, conn.fail(CloseProtocolError, fmt.Sprintf("unknown opcode %d", opcode))
+		}
+	}
+}
+
+// WriteMessage sends a data message to the peer in a single frame.
+func (conn *Conn) WriteMessage(messageType MessageType, data []byte) error {
+	if messageType != TextMessage && messageType != BinaryMessage {
+		return fmt.Errorf("the message type %d is invalid", messageType)
+	}
+	return conn.writeFrame(byte(messageType), data)
+}
+
+// Close sends a close frame with the code and reason to the peer, then closes the network connection.
+// It does not wait for the peer to acknowledge the close. Calling Close more than once has no effect.
+func (conn *Conn) Close(code CloseCode, reason string) error {
+	var err error
+	conn.closeOnce.Do(func() {
+		close(conn.closed)
+		payload := make([]byte, 2, 2+len(reason))
+		binary.BigEndian.PutUint16(payload, uint16(code))
+		payload = append(payload, reason...)
+		if len(payload) > maxControlPayloadBytes {
+			payload = payload[:maxControlPayloadBytes]
+		}
+		writeErr := conn.writeFrame(opcodeClose, payload)
+		closeErr := conn.netConn.Close()
+		err = errors.Join(writeErr, closeErr)
+	})
+	return err
+}
+
+// handleCloseFrame echoes the close frame of the peer and returns the CloseError it describes.
+func (conn *Conn) handleCloseFrame(payload []byte) error {
+	closeErr := &CloseError{Code: CloseNoStatus, Reason: ""}
+	if len(payload) == 1 {
+		return conn.fail(CloseProtocolError, "the close frame has an invalid payload")
+	}
+	if len(payload) >= 2 {
+		closeErr.Code = CloseCode(binary.BigEndian.Uint16(payload))
+		closeErr.Reason = string(payload[2:])
+		if !utf8.ValidString(closeErr.Reason) {
+			return conn.fail(CloseInvalidPayload, "the close reason is not valid UTF-8")
+		}
+	}
+	echoCode := closeErr.Code
+	if echoCode == CloseNoStatus {
+		echoCode = CloseNormal
+	}
+	_ = conn.Close(echoCode, "")
+	return closeErr
+}
+
+// fail closes the connection because the peer violated the protocol and returns the error.
+func (conn *Conn) fail(code CloseCode, reason string) error {
+	_ = conn.Close(code, reason)
+	return fmt.Errorf("websocket protocol error (%s)", reason)
+}
+
+// readFrame reads a single frame from the peer and unmasks its payload.
+func (conn *Conn) readFrame() (bool, byte, []byte, error) {
+	var header [2]byte
+	if _, err := io.ReadFull(conn.reader, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+	fin := header[0]&0x80 != 0
+	opcode := header[0] & 0x0F
+	if header[0]&0x70 != 0 {
+		return false, 0, nil, conn.fail(CloseProtocolError, "reserved bits are set")
+	}
+	if header[1]&0x80 == 0 {
+		return false, 0, nil, conn.fail(CloseProtocolError, "frames from the client must be masked")
+	}
+
+	payloadLength := uint64(header[1] & 0x7F)
+	switch payloadLength {
+	case 126:
+		var extended [2]byte
+		if _, err := io.ReadFull(conn.reader, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+		payloadLength = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err := io.ReadFull(conn.reader, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+		payloadLength = binary.BigEndian.Uint64(extended[:])
+	}
+
+	if opcode >= opcodeClose && (!fin || payloadLength > maxControlPayloadBytes) {
+		return false, 0, nil, conn.fail(CloseProtocolError, "control frames must not be fragmented or exceed 125 bytes")
+	}
+	if conn.cfg.maxMessageBytes > 0 && payloadLength > uint64(conn.cfg.maxMessageBytes) {
+		return false, 0, nil, conn.fail(CloseMessageTooBig, fmt.Sprintf("the message exceeds %d bytes", conn.cfg.maxMessageBytes))
+	}
+
+	var maskKey [4]byte
+	if _, err := io.ReadFull(conn.reader, maskKey[:]); err != nil {
+		return false, 0, nil, err
+	}
+	payload := make([]byte, payloadLength)
+	if _, err := io.ReadFull(conn.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= maskKey[i%4]
+	}
+
+	return fin, opcode, payload, nil
+}
+
+// writeFrame writes a single unmasked and unfragmented frame to the peer.
+func (conn *Conn) writeFrame(opcode byte, payload []byte) error {
+	conn.writeLock.Lock()
+	defer conn.writeLock.Unlock()
+
+	if conn.closeFrameWrote {
+		return net.ErrClosed
+	}
+	if opcode == opcodeClose {
+		conn.closeFrameWrote = true
+	}
+
+	frame := make([]byte, 0, 10+len(payload))
+	frame = append(frame, 0x80|opcode)
+	switch {
+	case len(payload) <= 125:
+		frame = append(frame, byte(len(payload)))
+	case len(payload) <= 0xFFFF:
+		frame = append(frame, 126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(len(payload)))
+	default:
+		frame = append(frame, 127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(len(payload)))
+	}
+	frame = append(frame, payload...)
+
+	if conn.cfg.writeTimeout > 0 {
+		if err := conn.netConn.SetWriteDeadline(time.Now().Add(conn.cfg.writeTimeout)); err != nil {
+			return err
+		}
+	}
+	_, err := conn.netConn.Write(frame)
+	return err
+}
+
+// extendReadDeadline gives the peer until the pong timeout to send its next frame when keepalive is configured.
+func (conn *Conn) extendReadDeadline() {
+	if conn.cfg.pingInterval > 0 {
+		_ = conn.netConn.SetReadDeadline(time.Now().Add(conn.cfg.pingInterval + conn.cfg.pongTimeout))
+	}
+}
+
+// keepalive sends a ping on every interval until the connection is closed.
+func (conn *Conn) keepalive() {
+	ticker := time.NewTicker(conn.cfg.pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-conn.closed:
+			return
+		case <-ticker.C:
+			if err := conn.writeFrame(opcodePing, nil); err != nil {
+				return
+			}
+		}
+	}
+}
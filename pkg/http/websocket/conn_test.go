@@ -0,0 +1,200 @@
+package websocket_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/http/websocket"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestConn(t *testing.T) {
+	t.Parallel()
+
+	// echoServer echoes messages and sends the error that ended the read loop on the channel.
+	echoServer := func(t *testing.T, opts ...websocket.Option) (*testClient, <-chan error) {
+		t.Helper()
+		readErr := make(chan error, 1)
+		handler := websocket.Handler(func(conn *websocket.Conn, request *http.Request) {
+			for {
+				messageType, data, err := conn.ReadMessage()
+				if err != nil {
+					readErr <- err
+					return
+				}
+				if err := conn.WriteMessage(messageType, data); err != nil {
+					readErr <- err
+					return
+				}
+			}
+		}, opts...)
+		client, response := dial(t, startServer(t, handler), nil)
+		assert.Equals(t, response.StatusCode, http.StatusSwitchingProtocols)
+		return client, readErr
+	}
+
+	closePayload := func(code websocket.CloseCode, reason string) []byte {
+		return append(binary.BigEndian.AppendUint16(nil, uint16(code)), reason...)
+	}
+
+	t.Run("when messages of every size are sent it should echo them", func(t *testing.T) {
+		t.Parallel()
+		client, _ := echoServer(t)
+		for _, size := range []int{0, 125, 126, 0xFFFF, 0x10000} {
+			payload := bytes.Repeat([]byte{'a'}, size)
+			client.writeFrame(true, 0x2, payload)
+			frame := client.readFrame()
+			assert.True(t, frame.fin)
+			assert.Equals(t, frame.opcode, byte(0x2))
+			assert.Equals(t, len(frame.payload), size)
+		}
+	})
+
+	t.Run("when a message is fragmented with a ping in between it should answer the ping and reassemble the message", func(t *testing.T) {
+		t.Parallel()
+		client, _ := echoServer(t)
+		client.writeFrame(false, 0x1, []byte("hello "))
+		client.writeFrame(true, 0x9, []byte("are you there"))
+		client.writeFrame(true, 0x0, []byte("world"))
+		pong := client.readFrame()
+		assert.Equals(t, pong.opcode, byte(0xA))
+		assert.Equals(t, string(pong.payload), "are you there")
+		message := client.readFrame()
+		assert.Equals(t, message.opcode, byte(0x1))
+		assert.Equals(t, string(message.payload), "hello world")
+	})
+
+	t.Run("when the peer closes the connection it should echo the close and return a close error", func(t *testing.T) {
+		t.Parallel()
+		client, readErr := echoServer(t)
+		client.writeFrame(true, 0x8, closePayload(websocket.CloseGoingAway, "bye"))
+		assert.Equals(t, client.readCloseCode(), websocket.CloseGoingAway)
+		err := <-readErr
+		var closeErr *websocket.CloseError
+		assert.True(t, errors.As(err, &closeErr))
+		assert.Equals(t, closeErr.Code, websocket.CloseGoingAway)
+		assert.Equals(t, closeErr.Reason, "bye")
+		assert.ErrorExact(t, err, "the websocket connection was closed with the code 1001 (bye)")
+	})
+
+	t.Run("when the peer closes the connection without a code it should close normally", func(t *testing.T) {
+		t.Parallel()
+		client, readErr := echoServer(t)
+		client.writeFrame(true, 0x8, nil)
+		assert.Equals(t, client.readCloseCode(), websocket.CloseNormal)
+		assert.ErrorExact(t, <-readErr, "the websocket connection was closed with the code 1005")
+	})
+
+	t.Run("when the peer violates the protocol it should close the connection with the matching code", func(t *testing.T) {
+		t.Parallel()
+		testCases := []struct {
+			send         func(client *testClient)
+			expectedCode websocket.CloseCode
+			expectedErr  string
+		}{
+			{
+				send:         func(client *testClient) { client.writeFrame(true, 0x0, []byte("orphan")) },
+				expectedCode: websocket.CloseProtocolError,
+				expectedErr:  "unexpected continuation frame",
+			},
+			{
+				send: func(client *testClient) {
+					client.writeFrame(false, 0x1, []byte("first"))
+					client.writeFrame(true, 0x1, []byte("second"))
+				},
+				expectedCode: websocket.CloseProtocolError,
+				expectedErr:  "unexpected continuation frame",
+			},
+			{
+				send:         func(client *testClient) { client.writeFrame(true, 0x3, nil) },
+				expectedCode: websocket.CloseProtocolError,
+				expectedErr:  "unknown opcode 3",
+			},
+			{
+				send:         func(client *testClient) { client.writeFrame(false, 0x9, nil) },
+				expectedCode: websocket.CloseProtocolError,
+				expectedErr:  "control frames must not be fragmented or exceed 125 bytes",
+			},
+			{
+				send: func(client *testClient) {
+					_, err := client.conn.Write([]byte{0x81, 0x01, 'a'})
+					assert.NoError(t, err)
+				},
+				expectedCode: websocket.CloseProtocolError,
+				expectedErr:  "frames from the client must be masked",
+			},
+			{
+				send:         func(client *testClient) { client.writeFrame(true, 0x1, []byte{0xff, 0xfe}) },
+				expectedCode: websocket.CloseInvalidPayload,
+				expectedErr:  "the text message is not valid UTF-8",
+			},
+			{
+				send:         func(client *testClient) { client.writeFrame(true, 0x8, []byte{0x03}) },
+				expectedCode: websocket.CloseProtocolError,
+				expectedErr:  "the close frame has an invalid payload",
+			},
+		}
+		for _, testCase := range testCases {
+			client, readErr := echoServer(t)
+			testCase.send(client)
+			assert.Equals(t, client.readCloseCode(), testCase.expectedCode)
+			assert.ErrorPart(t, <-readErr, testCase.expectedErr)
+		}
+	})
+
+	t.Run("when a message exceeds the maximum size it should close the connection as too big", func(t *testing.T) {
+		t.Parallel()
+		client, readErr := echoServer(t, websocket.WithMaxMessageBytes(8))
+		client.writeFrame(false, 0x2, []byte("12345"))
+		client.writeFrame(true, 0x0, []byte("67890"))
+		assert.Equals(t, client.readCloseCode(), websocket.CloseMessageTooBig)
+		assert.ErrorPart(t, <-readErr, "the message exceeds 8 bytes")
+
+		client, readErr = echoServer(t, websocket.WithMaxMessageBytes(8))
+		client.writeFrame(true, 0x2, []byte("123456789"))
+		assert.Equals(t, client.readCloseCode(), websocket.CloseMessageTooBig)
+		assert.ErrorPart(t, <-readErr, "the message exceeds 8 bytes")
+	})
+
+	t.Run("when keepalive is configured it should ping the peer and fail reads when the peer is silent", func(t *testing.T) {
+		t.Parallel()
+		client, readErr := echoServer(t, websocket.WithKeepalive(time.Millisecond*50, time.Millisecond*50))
+		ping := client.readFrame()
+		assert.Equals(t, ping.opcode, byte(0x9))
+		client.writeFrame(true, 0xA, nil)
+		select {
+		case err := <-readErr:
+			assert.ErrorPart(t, err, "timeout")
+		case <-time.After(time.Second * 5):
+			t.Fatal("the read did not time out")
+		}
+	})
+
+	t.Run("when an invalid message type is written it should return an error", func(t *testing.T) {
+		t.Parallel()
+		writeErr := make(chan error, 1)
+		handler := websocket.Handler(func(conn *websocket.Conn, request *http.Request) {
+			writeErr <- conn.WriteMessage(websocket.MessageType(0x9), nil)
+		})
+		client, _ := dial(t, startServer(t, handler), nil)
+		assert.ErrorExact(t, <-writeErr, "the message type 9 is invalid")
+		assert.Equals(t, client.readCloseCode(), websocket.CloseNormal)
+	})
+
+	t.Run("when the connection is closed it should fail to write", func(t *testing.T) {
+		t.Parallel()
+		writeErr := make(chan error, 1)
+		handler := websocket.Handler(func(conn *websocket.Conn, request *http.Request) {
+			assert.NoError(t, conn.Close(websocket.CloseGoingAway, "shutting down"))
+			assert.NoError(t, conn.Close(websocket.CloseNormal, ""))
+			writeErr <- conn.WriteMessage(websocket.TextMessage, []byte("late"))
+		})
+		client, _ := dial(t, startServer(t, handler), nil)
+		assert.Equals(t, client.readCloseCode(), websocket.CloseGoingAway)
+		assert.Error(t, <-writeErr)
+	})
+}
@@ -0,0 +1,225 @@
+package websocket
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	httperrors "github.com/TriangleSide/GoBase/pkg/http/errors"
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/responders"
+	"github.com/TriangleSide/GoBase/pkg/logger"
+	"github.com/TriangleSide/GoBase/pkg/validation"
+)
+
+// acceptGUID is appended to the Sec-WebSocket-Key to compute the Sec-WebSocket-Accept (RFC 6455, section 1.3).
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// config is configured by the Option functions.
+type config struct {
+	pingInterval    time.Duration
+	pongTimeout     time.Duration
+	writeTimeout    time.Duration
+	maxMessageBytes int64
+	checkOrigin     func(request *http.Request) bool
+}
+
+// Option is used to configure a WebSocket connection.
+type Option func(cfg *config)
+
+// newConfig allocates a config with its default values and applies the options.
+func newConfig(opts ...Option) *config {
+	cfg := &config{
+		pingInterval:    0,
+		pongTimeout:     0,
+		writeTimeout:    time.Second * 10,
+		maxMessageBytes: 1 << 20,
+		checkOrigin:     sameOrigin,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// WithKeepalive makes the server ping the peer on the interval. If nothing, including a pong, is received
+// from the peer within the interval plus the pong timeout, reading from the connection fails.
+// Keepalive is disabled by default.
+func WithKeepalive(pingInterval time.Duration, pongTimeout time.Duration) Option {
+	if pingInterval <= 0 || pongTimeout <= 0 {
+		panic("the ping interval and pong timeout must be greater than zero")
+	}
+	return func(cfg *config) {
+		cfg.pingInterval = pingInterval
+		cfg.pongTimeout = pongTimeout
+	}
+}
+
+// WithWriteTimeout sets how long writing a frame can take. The default is 10 seconds. Zero disables the timeout.
+func WithWriteTimeout(timeout time.Duration) Option {
+	return func(cfg *config) {
+		cfg.writeTimeout = timeout
+	}
+}
+
+// WithMaxMessageBytes sets the largest message that can be read. Larger messages close the connection
+// with CloseMessageTooBig. The default is 1 MiB. Zero means no limit.
+func WithMaxMessageBytes(maxMessageBytes int64) Option {
+	return func(cfg *config) {
+		cfg.maxMessageBytes = maxMessageBytes
+	}
+}
+
+// WithOriginCheck sets the function that decides if the Origin of the request is allowed.
+// By default, requests with an Origin header are only allowed if its host matches the host of the request.
+func WithOriginCheck(checkOrigin func(request *http.Request) bool) Option {
+	return func(cfg *config) {
+		cfg.checkOrigin = checkOrigin
+	}
+}
+
+// sameOrigin allows requests without an Origin header, or with an Origin whose host matches the request host.
+func sameOrigin(request *http.Request) bool {
+	origin := request.Header.Get(headers.Origin)
+	if origin == "" {
+		return true
+	}
+	originURL, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(originURL.Host, request.Host)
+}
+
+// Upgrade completes the WebSocket opening handshake and takes over the connection of the request.
+// If the request is not a valid WebSocket handshake, an error response is written and an error is returned.
+// The deadlines of the HTTP server no longer apply to the connection once it is upgraded.
+func Upgrade(writer http.ResponseWriter, request *http.Request, opts ...Option) (*Conn, error) {
+	cfg := newConfig(opts...)
+
+	if err := validateHandshake(request, cfg); err != nil {
+		if request.Header.Get(headers.SecWebSocketVersion) != "13" {
+			writer.Header().Set(headers.SecWebSocketVersion, "13")
+		}
+		responders.Error(request, writer, err)
+		return nil, err
+	}
+
+	netConn, bufferedReadWriter, err := http.NewResponseController(writer).Hijack()
+	if err != nil {
+		err = fmt.Errorf("failed to take over the connection (%w)", err)
+		responders.Error(request, writer, err)
+		return nil, err
+	}
+	if err := netConn.SetDeadline(time.Time{}); err != nil {
+		_ = netConn.Close()
+		return nil, fmt.Errorf("failed to clear the connection deadlines (%w)", err)
+	}
+
+	response := "HTTP/1.1 101 Switching Protocols\r\n" +
+		headers.Upgrade + ": websocket\r\n" +
+		headers.Connection + ": Upgrade\r\n" +
+		headers.SecWebSocketAccept + ": " + acceptKey(request.Header.Get(headers.SecWebSocketKey)) + "\r\n\r\n"
+	if _, err := bufferedReadWriter.WriteString(response); err != nil {
+		_ = netConn.Close()
+		return nil, fmt.Errorf("failed to write the handshake response (%w)", err)
+	}
+	if err := bufferedReadWriter.Flush(); err != nil {
+		_ = netConn.Close()
+		return nil, fmt.Errorf("failed to write the handshake response (%w)", err)
+	}
+
+	return newConn(netConn, bufferedReadWriter.Reader, cfg), nil
+}
+
+// validateHandshake returns an error if the request is not a valid WebSocket opening handshake.
+func validateHandshake(request *http.Request, cfg *config) error {
+	if request.Method != http.MethodGet {
+		return &httperrors.BadRequest{Err: errors.New("the websocket handshake must be a GET request")}
+	}
+	if !headerHasToken(request.Header, headers.Connection, "upgrade") || !headerHasToken(request.Header, headers.Upgrade, "websocket") {
+		return &httperrors.BadRequest{Err: errors.New("the request is not a websocket upgrade")}
+	}
+	if version := request.Header.Get(headers.SecWebSocketVersion); version != "13" {
+		return &httperrors.BadRequest{Err: fmt.Errorf("the websocket version '%s' is not supported", version)}
+	}
+	key, err := base64.StdEncoding.DecodeString(request.Header.Get(headers.SecWebSocketKey))
+	if err != nil || len(key) != 16 {
+		return &httperrors.BadRequest{Err: errors.New("the websocket key is invalid")}
+	}
+	if cfg.checkOrigin != nil && !cfg.checkOrigin(request) {
+		return &httperrors.Forbidden{Err: fmt.Errorf("the origin '%s' is not allowed", request.Header.Get(headers.Origin))}
+	}
+	return nil
+}
+
+// headerHasToken returns true if one of the comma separated values of the header equals the token, ignoring case.
+func headerHasToken(header http.Header, name string, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// acceptKey computes the Sec-WebSocket-Accept value for the Sec-WebSocket-Key of the client.
+func acceptKey(key string) string {
+	hash := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// Handler returns an http.HandlerFunc that upgrades the request and calls the handler with the connection.
+// The connection is closed with CloseNormal when the handler returns, if the handler has not closed it.
+func Handler(handler func(conn *Conn, request *http.Request), opts ...Option) http.HandlerFunc {
+	if handler == nil {
+		panic("the websocket handler cannot be nil")
+	}
+	return func(writer http.ResponseWriter, request *http.Request) {
+		conn, err := Upgrade(writer, request, opts...)
+		if err != nil {
+			logger.Debugf(request.Context(), "The websocket upgrade failed (%s).", err)
+			return
+		}
+		defer func() {
+			_ = conn.Close(CloseNormal, "")
+		}()
+		handler(conn, request)
+	}
+}
+
+// ReadJSON reads the next message and decodes it as JSON. Structs are validated after they are decoded.
+func ReadJSON[T any](conn *Conn) (*T, error) {
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		return nil, err
+	}
+	value := new(T)
+	if err := json.Unmarshal(data, value); err != nil {
+		return nil, fmt.Errorf("failed to decode the json message (%w)", err)
+	}
+	if reflect.TypeFor[T]().Kind() == reflect.Struct {
+		if err := validation.Struct(value); err != nil {
+			return nil, fmt.Errorf("validation failed for the message (%w)", err)
+		}
+	}
+	return value, nil
+}
+
+// WriteJSON encodes the value as JSON and sends it in a text message.
+func WriteJSON(conn *Conn, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode the json message (%w)", err)
+	}
+	return conn.WriteMessage(TextMessage, data)
+}
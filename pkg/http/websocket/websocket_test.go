@@ -0,0 +1,267 @@
+package websocket_test
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/websocket"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+// testClient is a minimal WebSocket client that sends masked frames and reads raw frames.
+type testClient struct {
+	t      *testing.T
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// testFrame is a frame read by the testClient.
+type testFrame struct {
+	fin     bool
+	opcode  byte
+	payload []byte
+}
+
+// startServer runs the handler on a test server and returns its address.
+func startServer(t *testing.T, handler http.HandlerFunc) string {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "http://")
+}
+
+// dial performs the opening handshake with the extra headers and returns the handshake response.
+func dial(t *testing.T, address string, extraHeaders map[string]string) (*testClient, *http.Response) {
+	t.Helper()
+	conn, err := net.Dial("tcp", address)
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	handshake := "GET / HTTP/1.1\r\nHost: " + address + "\r\n" +
+		"Connection: keep-alive, Upgrade\r\nUpgrade: websocket\r\n" +
+		"Sec-WebSocket-Version: 13\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n"
+	for name, value := range extraHeaders {
+		handshake += name + ": " + value + "\r\n"
+	}
+	_, err = io.WriteString(conn, handshake+"\r\n")
+	assert.NoError(t, err)
+	assert.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second*10)))
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, nil)
+	assert.NoError(t, err)
+	return &testClient{t: t, conn: conn, reader: reader}, response
+}
+
+// writeFrame sends a masked frame.
+func (client *testClient) writeFrame(fin bool, opcode byte, payload []byte) {
+	client.t.Helper()
+	first := opcode
+	if fin {
+		first |= 0x80
+	}
+	frame := []byte{first}
+	switch {
+	case len(payload) <= 125:
+		frame = append(frame, 0x80|byte(len(payload)))
+	case len(payload) <= 0xFFFF:
+		frame = append(frame, 0x80|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(len(payload)))
+	default:
+		frame = append(frame, 0x80|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(len(payload)))
+	}
+	mask := []byte{0x12, 0x34, 0x56, 0x78}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	_, err := client.conn.Write(frame)
+	assert.NoError(client.t, err)
+}
+
+// readFrame reads an unmasked frame from the server.
+func (client *testClient) readFrame() testFrame {
+	client.t.Helper()
+	var header [2]byte
+	_, err := io.ReadFull(client.reader, header[:])
+	assert.NoError(client.t, err)
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		var extended [2]byte
+		_, err = io.ReadFull(client.reader, extended[:])
+		assert.NoError(client.t, err)
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		_, err = io.ReadFull(client.reader, extended[:])
+		assert.NoError(client.t, err)
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+	payload := make([]byte, length)
+	_, err = io.ReadFull(client.reader, payload)
+	assert.NoError(client.t, err)
+	return testFrame{fin: header[0]&0x80 != 0, opcode: header[0] & 0x0F, payload: payload}
+}
+
+// readCloseCode reads a close frame and returns its code.
+func (client *testClient) readCloseCode() websocket.CloseCode {
+	client.t.Helper()
+	frame := client.readFrame()
+	assert.Equals(client.t, frame.opcode, byte(0x8))
+	return websocket.CloseCode(binary.BigEndian.Uint16(frame.payload))
+}
+
+func TestUpgrade(t *testing.T) {
+	t.Parallel()
+
+	echo := websocket.Handler(func(conn *websocket.Conn, request *http.Request) {
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err := conn.WriteMessage(messageType, data); err != nil {
+				return
+			}
+		}
+	})
+
+	t.Run("when the handler is nil it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicExact(t, func() {
+			websocket.Handler(nil)
+		}, "the websocket handler cannot be nil")
+	})
+
+	t.Run("when the keepalive is invalid it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicExact(t, func() {
+			websocket.WithKeepalive(0, time.Second)
+		}, "the ping interval and pong timeout must be greater than zero")
+	})
+
+	t.Run("when the handshake is valid it should switch protocols with the accept key", func(t *testing.T) {
+		t.Parallel()
+		_, response := dial(t, startServer(t, echo), nil)
+		assert.Equals(t, response.StatusCode, http.StatusSwitchingProtocols)
+		assert.Equals(t, response.Header.Get(headers.Upgrade), "websocket")
+		assert.Equals(t, response.Header.Get(headers.SecWebSocketAccept), "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=")
+	})
+
+	t.Run("when the request is not a websocket handshake it should respond with a bad request", func(t *testing.T) {
+		t.Parallel()
+		recorder := httptest.NewRecorder()
+		echo(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equals(t, recorder.Code, http.StatusBadRequest)
+		assert.Contains(t, recorder.Body.String(), "the request is not a websocket upgrade")
+	})
+
+	t.Run("when the handshake is invalid it should respond with a bad request", func(t *testing.T) {
+		t.Parallel()
+		testCases := []struct {
+			method      string
+			version     string
+			key         string
+			expectedErr string
+		}{
+			{method: http.MethodPost, version: "13", key: "dGhlIHNhbXBsZSBub25jZQ==", expectedErr: "the websocket handshake must be a GET request"},
+			{method: http.MethodGet, version: "8", key: "dGhlIHNhbXBsZSBub25jZQ==", expectedErr: "the websocket version '8' is not supported"},
+			{method: http.MethodGet, version: "13", key: "c2hvcnQ=", expectedErr: "the websocket key is invalid"},
+			{method: http.MethodGet, version: "13", key: "not base64", expectedErr: "the websocket key is invalid"},
+		}
+		for _, testCase := range testCases {
+			request := httptest.NewRequest(testCase.method, "/", nil)
+			request.Header.Set(headers.Connection, "Upgrade")
+			request.Header.Set(headers.Upgrade, "websocket")
+			request.Header.Set(headers.SecWebSocketVersion, testCase.version)
+			request.Header.Set(headers.SecWebSocketKey, testCase.key)
+			recorder := httptest.NewRecorder()
+			echo(recorder, request)
+			assert.Equals(t, recorder.Code, http.StatusBadRequest)
+			assert.Contains(t, recorder.Body.String(), testCase.expectedErr)
+			if testCase.version != "13" {
+				assert.Equals(t, recorder.Header().Get(headers.SecWebSocketVersion), "13")
+			}
+		}
+	})
+
+	t.Run("when the origin does not match the host it should respond with forbidden", func(t *testing.T) {
+		t.Parallel()
+		_, response := dial(t, startServer(t, echo), map[string]string{"Origin": "https://evil.example.com"})
+		assert.Equals(t, response.StatusCode, http.StatusForbidden)
+	})
+
+	t.Run("when the origin matches the host it should switch protocols", func(t *testing.T) {
+		t.Parallel()
+		address := startServer(t, echo)
+		_, response := dial(t, address, map[string]string{"Origin": "http://" + address})
+		assert.Equals(t, response.StatusCode, http.StatusSwitchingProtocols)
+	})
+
+	t.Run("when a custom origin check allows the origin it should switch protocols", func(t *testing.T) {
+		t.Parallel()
+		handler := websocket.Handler(func(conn *websocket.Conn, request *http.Request) {}, websocket.WithOriginCheck(func(request *http.Request) bool {
+			return request.Header.Get(headers.Origin) == "https://app.example.com"
+		}))
+		_, response := dial(t, startServer(t, handler), map[string]string{"Origin": "https://app.example.com"})
+		assert.Equals(t, response.StatusCode, http.StatusSwitchingProtocols)
+	})
+
+	t.Run("when the handler returns it should close the connection normally", func(t *testing.T) {
+		t.Parallel()
+		handler := websocket.Handler(func(conn *websocket.Conn, request *http.Request) {})
+		client, _ := dial(t, startServer(t, handler), nil)
+		assert.Equals(t, client.readCloseCode(), websocket.CloseNormal)
+	})
+
+	t.Run("when typed messages are exchanged it should decode, validate, and encode them", func(t *testing.T) {
+		t.Parallel()
+		type request struct {
+			Name string `json:"name" validate:"required"`
+		}
+		type response struct {
+			Greeting string `json:"greeting"`
+		}
+		handlerErr := make(chan error, 2)
+		handler := websocket.Handler(func(conn *websocket.Conn, _ *http.Request) {
+			message, err := websocket.ReadJSON[request](conn)
+			if err != nil {
+				handlerErr <- err
+				return
+			}
+			handlerErr <- websocket.WriteJSON(conn, &response{Greeting: "hello " + message.Name})
+			_, err = websocket.ReadJSON[request](conn)
+			handlerErr <- err
+		})
+		client, _ := dial(t, startServer(t, handler), nil)
+		client.writeFrame(true, 0x1, []byte(`{"name":"gopher"}`))
+		assert.NoError(t, <-handlerErr)
+		frame := client.readFrame()
+		assert.Equals(t, frame.opcode, byte(0x1))
+		assert.Equals(t, string(frame.payload), `{"greeting":"hello gopher"}`)
+		client.writeFrame(true, 0x1, []byte(`{}`))
+		assert.ErrorPart(t, <-handlerErr, "validation failed for the message")
+	})
+
+	t.Run("when a message is not valid JSON it should return an error", func(t *testing.T) {
+		t.Parallel()
+		handlerErr := make(chan error, 1)
+		handler := websocket.Handler(func(conn *websocket.Conn, _ *http.Request) {
+			_, err := websocket.ReadJSON[map[string]string](conn)
+			handlerErr <- err
+		})
+		client, _ := dial(t, startServer(t, handler), nil)
+		client.writeFrame(true, 0x1, []byte(`{`))
+		assert.ErrorPart(t, <-handlerErr, "failed to decode the json message")
+	})
+}
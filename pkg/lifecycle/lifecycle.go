@@ -0,0 +1,206 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/logger"
+)
+
+const (
+	// DefaultShutdownTimeout is the time the services have to shut down when WithShutdownTimeout is not used.
+	DefaultShutdownTimeout = 30 * time.Second
+)
+
+// Service is a long-running component, like the server.Server, that is run and shut down by the Manager.
+//
+// Run blocks until the service stops. It returns nil if the service stopped because Shutdown was called, and
+// an error if it failed. Shutdown stops the service gracefully, and should return once Run has returned or the
+// context is done.
+type Service interface {
+	Run() error
+	Shutdown(ctx context.Context) error
+}
+
+// managerOptions is configured by the caller with the Option functions.
+type managerOptions struct {
+	shutdownTimeout time.Duration
+	signals         []os.Signal
+}
+
+// Option is used to configure the Manager.
+type Option func(managerOpts *managerOptions)
+
+// WithShutdownTimeout sets the deadline for all the services to shut down. The default is DefaultShutdownTimeout.
+func WithShutdownTimeout(timeout time.Duration) Option {
+	if timeout <= 0 {
+		panic("the shutdown timeout must be greater than zero")
+	}
+	return func(managerOpts *managerOptions) {
+		managerOpts.shutdownTimeout = timeout
+	}
+}
+
+// WithSignals sets the signals that shut down the services. The default is SIGINT and SIGTERM.
+// If no signals are provided, the services are only shut down by the context of Run or by a failure.
+func WithSignals(signals ...os.Signal) Option {
+	return func(managerOpts *managerOptions) {
+		managerOpts.signals = signals
+	}
+}
+
+// namedService is a Service registered in the Manager.
+type namedService struct {
+	name    string
+	service Service
+}
+
+// Manager runs services together and shuts them all down when one of them fails, when the process receives a
+// shutdown signal, or when the context of Run is done. The services are shut down in reverse registration order,
+// so a service can depend on the services registered before it, like an HTTP server on a metrics server.
+type Manager struct {
+	opts     *managerOptions
+	services []*namedService
+}
+
+// New allocates a Manager without services.
+func New(opts ...Option) *Manager {
+	managerOpts := &managerOptions{
+		shutdownTimeout: DefaultShutdownTimeout,
+		signals:         []os.Signal{os.Interrupt, syscall.SIGTERM},
+	}
+	for _, opt := range opts {
+		opt(managerOpts)
+	}
+	return &Manager{
+		opts:     managerOpts,
+		services: make([]*namedService, 0),
+	}
+}
+
+// MustAdd registers a named service. The services must be added before Run.
+// If the name is empty or already registered, or the service is nil, this function panics.
+func (manager *Manager) MustAdd(name string, service Service) {
+	if name == "" {
+		panic("the service name cannot be empty")
+	}
+	if service == nil {
+		panic("the service cannot be nil")
+	}
+	for _, registered := range manager.services {
+		if registered.name == name {
+			panic(fmt.Sprintf("the service '%s' is already registered", name))
+		}
+	}
+	manager.services = append(manager.services, &namedService{
+		name:    name,
+		service: service,
+	})
+}
+
+// Run runs the services until the first one stops, a shutdown signal is received, or the context is done.
+// Then every service is shut down in reverse registration order, within the shutdown timeout. The returned error
+// joins the failure that stopped the services, if any, with the errors of the shutdowns.
+func (manager *Manager) Run(ctx context.Context) error {
+	if len(manager.opts.signals) != 0 {
+		var stop context.CancelFunc
+		ctx, stop = signal.NotifyContext(ctx, manager.opts.signals...)
+		defer stop()
+	}
+
+	type stopped struct {
+		name string
+		err  error
+	}
+	stoppedChan := make(chan stopped, len(manager.services))
+	var running sync.WaitGroup
+	for _, registered := range manager.services {
+		running.Add(1)
+		go func() {
+			defer running.Done()
+			stoppedChan <- stopped{name: registered.name, err: registered.service.Run()}
+		}()
+	}
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		logger.Infof(ctx, "Shutting down the services (%s).", context.Cause(ctx))
+	case first := <-stoppedChan:
+		if first.err != nil {
+			runErr = fmt.Errorf("the service '%s' failed (%w)", first.name, first.err)
+		} else {
+			runErr = fmt.Errorf("the service '%s' stopped unexpectedly", first.name)
+		}
+		logger.Errorf(ctx, "Shutting down the services because %s.", runErr)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), manager.opts.shutdownTimeout)
+	defer cancel()
+	shutdownErrs := []error{runErr}
+	for _, registered := range slices.Backward(manager.services) {
+		if err := registered.service.Shutdown(shutdownCtx); err != nil {
+			shutdownErrs = append(shutdownErrs, fmt.Errorf("failed to shut down the service '%s' (%w)", registered.name, err))
+		}
+	}
+
+	allStopped := make(chan struct{})
+	go func() {
+		running.Wait()
+		close(allStopped)
+	}()
+	select {
+	case <-allStopped:
+	case <-shutdownCtx.Done():
+		shutdownErrs = append(shutdownErrs, errors.New("the services did not stop before the shutdown timeout"))
+	}
+	return errors.Join(shutdownErrs...)
+}
+
+// daemon is a Service that runs a function until its context is canceled.
+type daemon struct {
+	run      func(ctx context.Context) error
+	ctx      context.Context
+	cancel   context.CancelFunc
+	finished chan struct{}
+}
+
+// Daemon returns a Service for a function that runs until its context is canceled, like a UDP listener loop.
+// Shutdown cancels the context of the function and waits for it to return. The function should return nil when
+// its context is canceled.
+func Daemon(run func(ctx context.Context) error) Service {
+	if run == nil {
+		panic("the daemon function cannot be nil")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &daemon{
+		run:      run,
+		ctx:      ctx,
+		cancel:   cancel,
+		finished: make(chan struct{}),
+	}
+}
+
+// Run invokes the function of the daemon and blocks until it returns.
+func (d *daemon) Run() error {
+	defer close(d.finished)
+	return d.run(d.ctx)
+}
+
+// Shutdown cancels the context of the daemon and waits for its function to return or for the context to be done.
+func (d *daemon) Shutdown(ctx context.Context) error {
+	d.cancel()
+	select {
+	case <-d.finished:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("the daemon did not stop in time (%w)", ctx.Err())
+	}
+}
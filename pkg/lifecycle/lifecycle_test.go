@@ -0,0 +1,183 @@
+package lifecycle_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/http/server"
+	"github.com/TriangleSide/GoBase/pkg/lifecycle"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+// The HTTP server can be run by the Manager.
+var _ lifecycle.Service = (*server.Server)(nil)
+
+// fakeService runs until it is shut down or fails with runErr.
+type fakeService struct {
+	name        string
+	runErr      error
+	shutdownErr error
+	ignoreStop  bool
+	order       *shutdownOrder
+	stop        chan struct{}
+	stopOnce    sync.Once
+}
+
+// shutdownOrder records the names of the services in the order they are shut down.
+type shutdownOrder struct {
+	mu    sync.Mutex
+	names []string
+}
+
+func newFakeService(name string, order *shutdownOrder) *fakeService {
+	return &fakeService{
+		name:  name,
+		order: order,
+		stop:  make(chan struct{}),
+	}
+}
+
+func (service *fakeService) Run() error {
+	if service.runErr != nil {
+		return service.runErr
+	}
+	<-service.stop
+	return nil
+}
+
+func (service *fakeService) Shutdown(context.Context) error {
+	service.order.mu.Lock()
+	service.order.names = append(service.order.names, service.name)
+	service.order.mu.Unlock()
+	if !service.ignoreStop {
+		service.stopOnce.Do(func() {
+			close(service.stop)
+		})
+	}
+	return service.shutdownErr
+}
+
+func TestManager(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when the context is done it should shut down the services in reverse registration order", func(t *testing.T) {
+		t.Parallel()
+		order := &shutdownOrder{}
+		manager := lifecycle.New(lifecycle.WithSignals())
+		manager.MustAdd("metrics", newFakeService("metrics", order))
+		manager.MustAdd("http", newFakeService("http", order))
+		manager.MustAdd("udp", newFakeService("udp", order))
+		ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+		defer cancel()
+		assert.NoError(t, manager.Run(ctx))
+		assert.Equals(t, order.names, []string{"udp", "http", "metrics"})
+	})
+
+	t.Run("when a service fails it should shut down every service and return the failure", func(t *testing.T) {
+		t.Parallel()
+		order := &shutdownOrder{}
+		failing := newFakeService("http", order)
+		failing.runErr = errors.New("address in use")
+		manager := lifecycle.New(lifecycle.WithSignals())
+		manager.MustAdd("metrics", newFakeService("metrics", order))
+		manager.MustAdd("http", failing)
+		err := manager.Run(t.Context())
+		assert.ErrorPart(t, err, "the service 'http' failed (address in use)")
+		assert.Equals(t, order.names, []string{"http", "metrics"})
+	})
+
+	t.Run("when a service stops without an error it should shut down every service", func(t *testing.T) {
+		t.Parallel()
+		manager := lifecycle.New(lifecycle.WithSignals())
+		manager.MustAdd("worker", lifecycle.Daemon(func(context.Context) error {
+			return nil
+		}))
+		manager.MustAdd("http", newFakeService("http", &shutdownOrder{}))
+		assert.ErrorPart(t, manager.Run(t.Context()), "the service 'worker' stopped unexpectedly")
+	})
+
+	t.Run("when a shutdown fails it should return the error and still shut down the other services", func(t *testing.T) {
+		t.Parallel()
+		order := &shutdownOrder{}
+		failing := newFakeService("http", order)
+		failing.shutdownErr = errors.New("connections still open")
+		manager := lifecycle.New(lifecycle.WithSignals())
+		manager.MustAdd("metrics", newFakeService("metrics", order))
+		manager.MustAdd("http", failing)
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		err := manager.Run(ctx)
+		assert.ErrorPart(t, err, "failed to shut down the service 'http' (connections still open)")
+		assert.Equals(t, order.names, []string{"http", "metrics"})
+	})
+
+	t.Run("when a service does not stop before the deadline it should return an error", func(t *testing.T) {
+		t.Parallel()
+		stuck := newFakeService("stuck", &shutdownOrder{})
+		stuck.ignoreStop = true
+		manager := lifecycle.New(lifecycle.WithSignals(), lifecycle.WithShutdownTimeout(20*time.Millisecond))
+		manager.MustAdd("stuck", stuck)
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		assert.ErrorPart(t, manager.Run(ctx), "the services did not stop before the shutdown timeout")
+		close(stuck.stop)
+	})
+
+	t.Run("when a daemon is shut down it should cancel its context and wait for it", func(t *testing.T) {
+		t.Parallel()
+		stopped := false
+		daemon := lifecycle.Daemon(func(ctx context.Context) error {
+			<-ctx.Done()
+			time.Sleep(10 * time.Millisecond)
+			stopped = true
+			return nil
+		})
+		runErr := make(chan error, 1)
+		go func() {
+			runErr <- daemon.Run()
+		}()
+		assert.NoError(t, daemon.Shutdown(t.Context()))
+		assert.True(t, stopped)
+		assert.NoError(t, <-runErr)
+	})
+
+	t.Run("when a daemon does not stop in time its shutdown should return an error", func(t *testing.T) {
+		t.Parallel()
+		release := make(chan struct{})
+		daemon := lifecycle.Daemon(func(context.Context) error {
+			<-release
+			return nil
+		})
+		go func() {
+			_ = daemon.Run()
+		}()
+		ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+		defer cancel()
+		assert.ErrorPart(t, daemon.Shutdown(ctx), "the daemon did not stop in time")
+		close(release)
+	})
+
+	t.Run("when the options or services are invalid it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicExact(t, func() {
+			lifecycle.WithShutdownTimeout(0)
+		}, "the shutdown timeout must be greater than zero")
+		assert.PanicExact(t, func() {
+			lifecycle.Daemon(nil)
+		}, "the daemon function cannot be nil")
+		manager := lifecycle.New()
+		assert.PanicExact(t, func() {
+			manager.MustAdd("", newFakeService("", &shutdownOrder{}))
+		}, "the service name cannot be empty")
+		assert.PanicExact(t, func() {
+			manager.MustAdd("http", nil)
+		}, "the service cannot be nil")
+		manager.MustAdd("http", newFakeService("http", &shutdownOrder{}))
+		assert.PanicExact(t, func() {
+			manager.MustAdd("http", newFakeService("http", &shutdownOrder{}))
+		}, "the service 'http' is already registered")
+	})
+}
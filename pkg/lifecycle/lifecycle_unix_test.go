@@ -0,0 +1,32 @@
+//go:build unix
+
+package lifecycle_test
+
+import (
+	"context"
+	"syscall"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/lifecycle"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestManagerSignals(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when a shutdown signal is received it should shut down the services", func(t *testing.T) {
+		t.Parallel()
+		manager := lifecycle.New(lifecycle.WithSignals(syscall.SIGUSR1))
+		started := make(chan struct{})
+		manager.MustAdd("worker", lifecycle.Daemon(func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			return nil
+		}))
+		go func() {
+			<-started
+			assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+		}()
+		assert.NoError(t, manager.Run(t.Context()))
+	})
+}
@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Backend writes the log entries. The level of an entry is checked before the Backend is called,
+// so the Backend only has to write it. The fields are the ones added to the context with WithField and WithFields.
+type Backend interface {
+	Write(ctx context.Context, level LogLevel, fields map[string]any, msg string)
+}
+
+// writerBackend is the default Backend. It formats the entries with the formatter set by SetFormatter
+// and writes them to the output set by SetOutput.
+type writerBackend struct{}
+
+// Write is the implementation of the Backend interface.
+func (writerBackend) Write(_ context.Context, _ LogLevel, fields map[string]any, msg string) {
+	appLogger.Println(appLogFormatter(fields, msg))
+}
+
+// appBackend holds the Backend of the application.
+var appBackend atomic.Pointer[Backend]
+
+// SetBackend replaces the Backend that writes the log entries, like a Backend created with NewSlogBackend.
+// A nil Backend restores the default, which writes the entries formatted by SetFormatter to the output of SetOutput.
+func SetBackend(backend Backend) {
+	if backend == nil {
+		appBackend.Store(nil)
+		return
+	}
+	appBackend.Store(&backend)
+}
+
+// GetBackend returns the Backend that writes the log entries.
+func GetBackend() Backend {
+	backend := appBackend.Load()
+	if backend == nil {
+		return writerBackend{}
+	}
+	return *backend
+}
+
+// write sends the entry to the Backend with the fields of the context.
+func write(ctx context.Context, level LogLevel, msg string) {
+	GetBackend().Write(ctx, level, contextFields(ctx), msg)
+}
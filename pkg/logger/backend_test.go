@@ -0,0 +1,125 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/logger"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+type recordedEntry struct {
+	level  logger.LogLevel
+	fields map[string]any
+	msg    string
+}
+
+type recordingBackend struct {
+	lock    sync.Mutex
+	entries []recordedEntry
+}
+
+func (b *recordingBackend) Write(_ context.Context, level logger.LogLevel, fields map[string]any, msg string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.entries = append(b.entries, recordedEntry{level: level, fields: fields, msg: msg})
+}
+
+func (b *recordingBackend) recorded() []recordedEntry {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return slices.Clone(b.entries)
+}
+
+func TestBackend(t *testing.T) {
+	t.Cleanup(func() {
+		logger.SetBackend(nil)
+		logger.SetOutput(os.Stdout)
+		logger.SetLevel(logger.LevelInfo)
+	})
+
+	t.Run("when a backend is set it should receive the entries with their level and fields", func(t *testing.T) {
+		backend := &recordingBackend{}
+		logger.SetBackend(backend)
+		t.Cleanup(func() {
+			logger.SetBackend(nil)
+		})
+		logger.SetLevel(logger.LevelInfo)
+		ctx := logger.WithField(context.Background(), "key", "value")
+		logger.Warnf(ctx, "warn %d", 1)
+		logger.Debug(ctx, "not logged")
+		assert.PanicPart(t, func() {
+			logger.Panic(ctx, "panic")
+		}, "key=value panic")
+		assert.Equals(t, backend.entries, []recordedEntry{
+			{level: logger.LevelWarn, fields: map[string]any{"key": "value"}, msg: "warn 1"},
+			{level: logger.LevelError, fields: map[string]any{"key": "value"}, msg: "panic"},
+		})
+		assert.Equals(t, logger.GetBackend(), logger.Backend(backend))
+	})
+
+	t.Run("when the backend is reset it should write to the output", func(t *testing.T) {
+		logger.SetBackend(&recordingBackend{})
+		logger.SetBackend(nil)
+		var output bytes.Buffer
+		logger.SetOutput(&output)
+		logger.Error(context.Background(), "message")
+		assert.Contains(t, output.String(), "message")
+	})
+}
+
+func TestSlogBackend(t *testing.T) {
+	t.Cleanup(func() {
+		logger.SetBackend(nil)
+		logger.SetLevel(logger.LevelInfo)
+	})
+
+	t.Run("when the slog logger is nil it should panic", func(t *testing.T) {
+		assert.PanicPart(t, func() {
+			logger.NewSlogBackend(nil)
+		}, "the slog logger cannot be nil")
+	})
+
+	t.Run("when entries are logged it should write them with the slog levels and the fields as attributes", func(t *testing.T) {
+		var output bytes.Buffer
+		handler := slog.NewTextHandler(&output, &slog.HandlerOptions{
+			Level: logger.SlogLevelTrace,
+			ReplaceAttr: func(_ []string, attr slog.Attr) slog.Attr {
+				if attr.Key == slog.TimeKey {
+					return slog.Attr{}
+				}
+				return attr
+			},
+		})
+		logger.SetBackend(logger.NewSlogBackend(slog.New(handler)))
+		logger.SetLevel(logger.LevelTrace)
+		ctx := logger.WithFields(context.Background(), map[string]any{"b": 2, "a": "one"})
+		logger.Error(ctx, "error")
+		logger.Warn(ctx, "warn")
+		logger.Info(ctx, "info")
+		logger.Debug(ctx, "debug")
+		var nilCtx context.Context
+		logger.Trace(nilCtx, "trace")
+		assert.Equals(t, strings.Split(strings.TrimSpace(output.String()), "\n"), []string{
+			"level=ERROR msg=error a=one b=2",
+			"level=WARN msg=warn a=one b=2",
+			"level=INFO msg=info a=one b=2",
+			"level=DEBUG msg=debug a=one b=2",
+			"level=DEBUG-4 msg=trace",
+		})
+	})
+
+	t.Run("when the levels are converted it should map them to the slog levels", func(t *testing.T) {
+		assert.Equals(t, logger.SlogLevel(logger.LevelError), slog.LevelError)
+		assert.Equals(t, logger.SlogLevel(logger.LevelWarn), slog.LevelWarn)
+		assert.Equals(t, logger.SlogLevel(logger.LevelInfo), slog.LevelInfo)
+		assert.Equals(t, logger.SlogLevel(logger.LevelDebug), slog.LevelDebug)
+		assert.Equals(t, logger.SlogLevel(logger.LevelTrace), logger.SlogLevelTrace)
+	})
+}
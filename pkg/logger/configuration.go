@@ -1,14 +1,21 @@
 package logger
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	"github.com/TriangleSide/GoBase/pkg/config"
 	"github.com/TriangleSide/GoBase/pkg/config/envprocessor"
 )
 
+// appLogFile is the log file opened by MustConfigure. It is closed when MustConfigure replaces the output.
+var appLogFile atomic.Pointer[RotatingFile]
+
 // loggerConfig is configured by the ConfigOption functions.
 type loggerConfig struct {
 	configProvider func() (*config.Logger, error)
@@ -25,7 +32,7 @@ func WithConfigProvider(provider func() (*config.Logger, error)) ConfigOption {
 	}
 }
 
-// WithOutputProvider sets the logger output.
+// WithOutputProvider sets the logger output. It replaces the log file of the config.Logger.
 func WithOutputProvider(provider func() (io.Writer, error)) ConfigOption {
 	return func(c *loggerConfig) {
 		c.outputProvider = provider
@@ -38,9 +45,7 @@ func MustConfigure(opts ...ConfigOption) {
 		configProvider: func() (*config.Logger, error) {
 			return envprocessor.ProcessAndValidate[config.Logger]()
 		},
-		outputProvider: func() (io.Writer, error) {
-			return os.Stdout, nil
-		},
+		outputProvider: nil,
 	}
 
 	for _, opt := range opts {
@@ -58,9 +63,57 @@ func MustConfigure(opts ...ConfigOption) {
 	}
 	SetLevel(level)
 
-	output, err := cfg.outputProvider()
+	moduleLevels, err := ParseModuleLevels(envConf.LogModuleLevels)
 	if err != nil {
-		panic(fmt.Sprintf("Failed to get logger output (%s).", err.Error()))
+		panic(fmt.Sprintf("Failed to parse the module log levels (%s).", err.Error()))
+	}
+	SetModuleLevels(moduleLevels)
+	SetSlowOperationThreshold(time.Millisecond * time.Duration(envConf.LogSlowOperationThresholdMilliseconds))
+
+	var output io.Writer = os.Stdout
+	var logFile *RotatingFile
+	if cfg.outputProvider != nil {
+		output, err = cfg.outputProvider()
+		if err != nil {
+			panic(fmt.Sprintf("Failed to get logger output (%s).", err.Error()))
+		}
+	} else if envConf.LogFilePath != "" {
+		logFile, err = NewRotatingFile(envConf.LogFilePath, fileOptions(envConf)...)
+		if err != nil {
+			panic(fmt.Sprintf("Failed to open the log file (%s).", err.Error()))
+		}
+		output = logFile
 	}
 	SetOutput(output)
+
+	previousLogFile := appLogFile.Swap(logFile)
+	if previousLogFile != nil {
+		if err := previousLogFile.Close(); err != nil {
+			Errorf(context.Background(), "Failed to close the previous log file (%s).", err.Error())
+		}
+	}
+}
+
+// fileOptions returns the options of the RotatingFile of the config.
+func fileOptions(envConf *config.Logger) []FileOption {
+	opts := make([]FileOption, 0)
+	if envConf.LogFileMaxSizeMegabytes > 0 {
+		opts = append(opts, WithMaxSize(int64(envConf.LogFileMaxSizeMegabytes)*1024*1024))
+	}
+	if envConf.LogFileRotationIntervalMinutes > 0 {
+		opts = append(opts, WithRotationInterval(time.Minute*time.Duration(envConf.LogFileRotationIntervalMinutes)))
+	}
+	if envConf.LogFileMaxAgeDays > 0 {
+		opts = append(opts, WithMaxAge(24*time.Hour*time.Duration(envConf.LogFileMaxAgeDays)))
+	}
+	if envConf.LogFileMaxBackups > 0 {
+		opts = append(opts, WithMaxBackups(envConf.LogFileMaxBackups))
+	}
+	if envConf.LogFileCompress {
+		opts = append(opts, WithCompression())
+	}
+	if envConf.LogFileReopenOnSighup {
+		opts = append(opts, WithReopenOnSignal(syscall.SIGHUP))
+	}
+	return opts
 }
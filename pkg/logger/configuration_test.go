@@ -6,7 +6,9 @@ import (
 	"errors"
 	"io"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/TriangleSide/GoBase/pkg/config"
 	"github.com/TriangleSide/GoBase/pkg/test/assert"
@@ -16,6 +18,8 @@ func TestMustConfigure(t *testing.T) {
 	t.Cleanup(func() {
 		SetOutput(os.Stdout)
 		SetLevel(LevelInfo)
+		SetModuleLevels(nil)
+		SetSlowOperationThreshold(0)
 	})
 
 	t.Run("when the config provider succeeds it sets the logger level", func(t *testing.T) {
@@ -24,7 +28,17 @@ func TestMustConfigure(t *testing.T) {
 				LogLevel: "debug",
 			}, nil
 		}))
-		assert.Equals(t, appLogLevel, LevelDebug)
+		assert.Equals(t, GetLevel(), LevelDebug)
+	})
+
+	t.Run("when the config has a slow operation threshold it sets the threshold", func(t *testing.T) {
+		MustConfigure(WithConfigProvider(func() (*config.Logger, error) {
+			return &config.Logger{
+				LogLevel:                              "info",
+				LogSlowOperationThresholdMilliseconds: 150,
+			}, nil
+		}))
+		assert.Equals(t, GetSlowOperationThreshold(), time.Millisecond*150)
 	})
 
 	t.Run("when the level is incorrect it should panic", func(t *testing.T) {
@@ -37,6 +51,71 @@ func TestMustConfigure(t *testing.T) {
 		}, "Failed to parse the log level (invalid log level: incorrect).")
 	})
 
+	t.Run("when the config has module levels it sets the module levels", func(t *testing.T) {
+		MustConfigure(WithConfigProvider(func() (*config.Logger, error) {
+			return &config.Logger{
+				LogLevel:        "info",
+				LogModuleLevels: "http=debug, cache=warn",
+			}, nil
+		}))
+		assert.Equals(t, GetModuleLevels(), map[string]LogLevel{"http": LevelDebug, "cache": LevelWarn})
+	})
+
+	t.Run("when the module levels are incorrect it should panic", func(t *testing.T) {
+		assert.PanicExact(t, func() {
+			MustConfigure(WithConfigProvider(func() (*config.Logger, error) {
+				return &config.Logger{
+					LogLevel:        "info",
+					LogModuleLevels: "http",
+				}, nil
+			}))
+		}, "Failed to parse the module log levels (the module level 'http' must be formatted as module=level).")
+	})
+
+	t.Run("when the config has a log file it should write the logs to it", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		MustConfigure(WithConfigProvider(func() (*config.Logger, error) {
+			return &config.Logger{
+				LogLevel:                       "info",
+				LogFilePath:                    path,
+				LogFileMaxSizeMegabytes:        1,
+				LogFileRotationIntervalMinutes: 60,
+				LogFileMaxAgeDays:              7,
+				LogFileMaxBackups:              3,
+				LogFileCompress:                true,
+				LogFileReopenOnSighup:          true,
+			}, nil
+		}))
+		logFile := appLogFile.Load()
+		assert.NotNil(t, logFile)
+		assert.Equals(t, logFile.cfg.maxSize, int64(1024*1024))
+		assert.Equals(t, logFile.cfg.rotationInterval, time.Hour)
+		assert.Equals(t, logFile.cfg.maxAge, 7*24*time.Hour)
+		assert.Equals(t, logFile.cfg.maxBackups, 3)
+		assert.True(t, logFile.cfg.compress)
+		assert.Equals(t, len(logFile.cfg.reopenSignals), 1)
+		Error(context.Background(), "file message")
+
+		MustConfigure()
+		assert.Nil(t, appLogFile.Load())
+		content, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Contains(t, string(content), "file message")
+		_, err = logFile.Write([]byte("closed"))
+		assert.ErrorExact(t, err, ErrFileClosed.Error())
+	})
+
+	t.Run("when the log file cannot be opened it should panic", func(t *testing.T) {
+		assert.PanicPart(t, func() {
+			MustConfigure(WithConfigProvider(func() (*config.Logger, error) {
+				return &config.Logger{
+					LogLevel:    "info",
+					LogFilePath: t.TempDir(),
+				}, nil
+			}))
+		}, "Failed to open the log file (failed to open the log file")
+	})
+
 	t.Run("when the config provider fails it should panic", func(t *testing.T) {
 		assert.PanicExact(t, func() {
 			MustConfigure(WithConfigProvider(func() (*config.Logger, error) {
@@ -64,7 +143,11 @@ func TestMustConfigure(t *testing.T) {
 
 	t.Run("when the defaults are used it should set the defaults", func(t *testing.T) {
 		SetLevel(LevelTrace)
+		SetModuleLevel("http", LevelDebug)
+		SetSlowOperationThreshold(time.Second)
 		MustConfigure()
-		assert.Equals(t, appLogLevel, LevelInfo)
+		assert.Equals(t, GetLevel(), LevelInfo)
+		assert.Equals(t, GetModuleLevels(), map[string]LogLevel{})
+		assert.Equals(t, GetSlowOperationThreshold(), time.Duration(0))
 	})
 }
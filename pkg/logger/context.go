@@ -11,6 +11,16 @@ const (
 	contextKey contextKeyType = "__logCtx"
 )
 
+const (
+	// TraceIDLogField is the field that holds the W3C trace ID of the logs. The ECS and OTLP backends
+	// use it to correlate the logs with the traces.
+	TraceIDLogField = "trace_id"
+
+	// SpanIDLogField is the field that holds the W3C span ID of the logs. The ECS and OTLP backends
+	// use it to correlate the logs with the traces.
+	SpanIDLogField = "span_id"
+)
+
 func WithField(ctx context.Context, key string, value any) context.Context {
 	fieldNotCast := ctx.Value(contextKey)
 	var newFields map[string]any
@@ -43,3 +53,11 @@ func WithFields(ctx context.Context, fieldsToAdd map[string]any) context.Context
 	}
 	return context.WithValue(ctx, contextKey, newFields)
 }
+
+// Fields returns a copy of the fields added to the context with WithField and WithFields.
+func Fields(ctx context.Context) map[string]any {
+	fields := contextFields(ctx)
+	copied := make(map[string]any, len(fields))
+	maps.Copy(copied, fields)
+	return copied
+}
@@ -120,3 +120,17 @@ func TestLoggerContext(t *testing.T) {
 		}, "The logger context fields are not the correct type.")
 	})
 }
+
+func TestFields(t *testing.T) {
+	t.Run("when the context has no fields it should return an empty map", func(t *testing.T) {
+		assert.Equals(t, Fields(context.Background()), map[string]any{})
+	})
+
+	t.Run("when the context has fields it should return a copy of them", func(t *testing.T) {
+		ctx := WithFields(context.Background(), map[string]any{"key": "value"})
+		fields := Fields(ctx)
+		assert.Equals(t, fields, map[string]any{"key": "value"})
+		fields["key"] = "changed"
+		assert.Equals(t, Fields(ctx), map[string]any{"key": "value"})
+	})
+}
@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// ECSVersion is the version of the Elastic Common Schema of the entries written by the ECS backend.
+	ECSVersion = "8.11.0"
+)
+
+// ecsBackend is a Backend that writes the entries as JSON documents of the Elastic Common Schema.
+type ecsBackend struct {
+	lock        sync.Mutex
+	out         io.Writer
+	serviceName string
+}
+
+// NewECSBackend creates a Backend that writes each entry to the writer as a line of JSON in the format of the
+// Elastic Common Schema, so the logs can be ingested without a parser. The TraceIDLogField and SpanIDLogField
+// are written as trace.id and span.id to correlate the logs with the traces, and the other fields are written
+// as they are. The service name is written as service.name if it is not empty. If the writer is nil,
+// this function panics.
+func NewECSBackend(out io.Writer, serviceName string) Backend {
+	if out == nil {
+		panic("the ECS output cannot be nil")
+	}
+	return &ecsBackend{
+		out:         out,
+		serviceName: serviceName,
+	}
+}
+
+// Write is the implementation of the Backend interface.
+func (b *ecsBackend) Write(_ context.Context, level LogLevel, fields map[string]any, msg string) {
+	document := map[string]any{
+		"@timestamp":  time.Now().UTC().Format(time.RFC3339Nano),
+		"log.level":   strings.ToLower(level.String()),
+		"message":     msg,
+		"ecs.version": ECSVersion,
+	}
+	if b.serviceName != "" {
+		document["service.name"] = b.serviceName
+	}
+	for key, value := range fields {
+		switch key {
+		case TraceIDLogField:
+			document["trace.id"] = value
+		case SpanIDLogField:
+			document["span.id"] = value
+		default:
+			if _, reserved := document[key]; !reserved {
+				document[key] = jsonFieldValue(value)
+			}
+		}
+	}
+
+	line, err := json.Marshal(document)
+	if err != nil {
+		for key, value := range document {
+			document[key] = fmt.Sprint(value)
+		}
+		line, _ = json.Marshal(document)
+	}
+	line = append(line, '\n')
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if _, err := b.out.Write(line); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write the ECS log entry (%s).\n", err)
+	}
+}
+
+// jsonFieldValue converts the value of a field so it is meaningful in JSON. Errors are written as their message
+// instead of an empty object.
+func jsonFieldValue(value any) any {
+	if err, isError := value.(error); isError {
+		return err.Error()
+	}
+	return value
+}
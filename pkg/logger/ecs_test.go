@@ -0,0 +1,67 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/logger"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestECSBackend(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when the output is nil it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicExact(t, func() {
+			logger.NewECSBackend(nil, "service")
+		}, "the ECS output cannot be nil")
+	})
+
+	t.Run("when an entry is written it should write an ECS document", func(t *testing.T) {
+		t.Parallel()
+		var output bytes.Buffer
+		backend := logger.NewECSBackend(&output, "service")
+		backend.Write(context.Background(), logger.LevelWarn, map[string]any{
+			logger.TraceIDLogField: "4bf92f3577b34da6a3ce929d0e0e4736",
+			logger.SpanIDLogField:  "00f067aa0ba902b7",
+			"user_id":              "user",
+			"error":                errors.New("failure"),
+			"message":              "overridden",
+		}, "warning")
+		assert.True(t, strings.HasSuffix(output.String(), "}\n"))
+
+		document := map[string]any{}
+		assert.NoError(t, json.Unmarshal(output.Bytes(), &document))
+		timestamp, err := time.Parse(time.RFC3339Nano, document["@timestamp"].(string))
+		assert.NoError(t, err)
+		assert.True(t, time.Since(timestamp) < time.Minute)
+		delete(document, "@timestamp")
+		assert.Equals(t, document, map[string]any{
+			"log.level":    "warn",
+			"message":      "warning",
+			"ecs.version":  logger.ECSVersion,
+			"service.name": "service",
+			"trace.id":     "4bf92f3577b34da6a3ce929d0e0e4736",
+			"span.id":      "00f067aa0ba902b7",
+			"user_id":      "user",
+			"error":        "failure",
+		})
+	})
+
+	t.Run("when a field cannot be encoded it should write it as a string", func(t *testing.T) {
+		t.Parallel()
+		var output bytes.Buffer
+		backend := logger.NewECSBackend(&output, "")
+		backend.Write(context.Background(), logger.LevelInfo, map[string]any{"channel": make(chan int)}, "info")
+		document := map[string]any{}
+		assert.NoError(t, json.Unmarshal(output.Bytes(), &document))
+		assert.True(t, strings.HasPrefix(document["channel"].(string), "0x"))
+		assert.Nil(t, document["service.name"])
+	})
+}
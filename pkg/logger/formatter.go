@@ -32,16 +32,21 @@ func SetFormatter(f logFormatterType) {
 
 // formatLog formats the log message using the fields in the context and the provided message.
 func formatLog(ctx context.Context, msg string) string {
+	return appLogFormatter(contextFields(ctx), msg)
+}
+
+// contextFields returns the fields added to the context with WithField and WithFields.
+func contextFields(ctx context.Context) map[string]any {
 	if ctx == nil {
-		return appLogFormatter(nil, msg)
+		return nil
 	}
 	fieldsNotCast := ctx.Value(contextKey)
 	if fieldsNotCast == nil {
-		return appLogFormatter(nil, msg)
+		return nil
 	}
 	fields, fieldsCastOk := fieldsNotCast.(map[string]any)
 	if !fieldsCastOk {
 		panic("The logger context fields is not the correct type.")
 	}
-	return appLogFormatter(fields, msg)
+	return fields
 }
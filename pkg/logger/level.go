@@ -3,6 +3,7 @@ package logger
 import (
 	"fmt"
 	"strings"
+	"sync/atomic"
 )
 
 // LogLevel represents the various log levels.
@@ -16,17 +17,21 @@ const (
 	LevelTrace
 )
 
-// appLogLevel is the configured log level for the application.
-var appLogLevel = LevelInfo
+// appLogLevel is the configured log level for the application. It can be changed while the application logs.
+var appLogLevel atomic.Int32
 
-// SetLevel sets the application log level.
+func init() {
+	appLogLevel.Store(int32(LevelInfo))
+}
+
+// SetLevel sets the application log level. It is safe to call while the application logs.
 func SetLevel(level LogLevel) {
-	appLogLevel = level
+	appLogLevel.Store(int32(level))
 }
 
 // GetLevel returns the applications log level.
 func GetLevel() LogLevel {
-	return appLogLevel
+	return LogLevel(appLogLevel.Load())
 }
 
 // String converts a LogLevel to its string representation.
@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+)
+
+// levelState is the body of the requests and responses of the LevelHandler.
+type levelState struct {
+	Level   *LogLevel           `json:"level,omitempty"`
+	Modules map[string]LogLevel `json:"modules"`
+}
+
+// LevelHandler returns an HTTP handler that changes the log levels at runtime, to be served on an admin endpoint.
+//
+// A GET responds with the application log level and the module log levels as JSON, like
+// {"level":"INFO","modules":{"http":"DEBUG"}}. A PUT or POST with the same JSON document sets the application log
+// level if it has one, and replaces the module log levels if it has modules. It responds with the new levels.
+func LevelHandler() http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		switch request.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			update := &levelState{}
+			if err := json.NewDecoder(request.Body).Decode(update); err != nil {
+				http.Error(writer, fmt.Sprintf("failed to decode the log levels (%s)", err), http.StatusBadRequest)
+				return
+			}
+			if update.Level != nil {
+				SetLevel(*update.Level)
+			}
+			if update.Modules != nil {
+				SetModuleLevels(update.Modules)
+			}
+			Infof(request.Context(), "The log levels were changed to %s with modules %v.", GetLevel(), GetModuleLevels())
+		default:
+			writer.Header().Set(headers.Allow, "GET, PUT, POST")
+			http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		level := GetLevel()
+		writer.Header().Set(headers.ContentType, headers.ContentTypeApplicationJson)
+		writer.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(writer).Encode(&levelState{Level: &level, Modules: GetModuleLevels()}); err != nil {
+			Errorf(request.Context(), "Failed to write the log levels (%s).", err)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package logger_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/logger"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestLevelHandler(t *testing.T) {
+	t.Cleanup(func() {
+		logger.SetOutput(os.Stdout)
+		logger.SetLevel(logger.LevelInfo)
+		logger.SetModuleLevels(nil)
+	})
+
+	serve := func(method string, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		logger.LevelHandler()(recorder, httptest.NewRequest(method, "/loglevel", strings.NewReader(body)))
+		return recorder
+	}
+
+	t.Run("when the levels are fetched it should return them as JSON", func(t *testing.T) {
+		logger.SetLevel(logger.LevelWarn)
+		logger.SetModuleLevels(map[string]logger.LogLevel{"http": logger.LevelDebug})
+		recorder := serve(http.MethodGet, "")
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.Equals(t, recorder.Header().Get(headers.ContentType), headers.ContentTypeApplicationJson)
+		assert.Equals(t, recorder.Body.String(), "{\"level\":\"WARN\",\"modules\":{\"http\":\"DEBUG\"}}\n")
+	})
+
+	t.Run("when the levels are put it should change them", func(t *testing.T) {
+		logger.SetLevel(logger.LevelInfo)
+		logger.SetModuleLevels(nil)
+		recorder := serve(http.MethodPut, "{\"level\":\"debug\",\"modules\":{\"cache\":\"trace\"}}")
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.Equals(t, recorder.Body.String(), "{\"level\":\"DEBUG\",\"modules\":{\"cache\":\"TRACE\"}}\n")
+		assert.Equals(t, logger.GetLevel(), logger.LevelDebug)
+		assert.Equals(t, logger.GetModuleLevels(), map[string]logger.LogLevel{"cache": logger.LevelTrace})
+	})
+
+	t.Run("when only the level is put it should keep the module levels", func(t *testing.T) {
+		logger.SetModuleLevels(map[string]logger.LogLevel{"http": logger.LevelDebug})
+		recorder := serve(http.MethodPut, "{\"level\":\"error\"}")
+		assert.Equals(t, recorder.Code, http.StatusOK)
+		assert.Equals(t, logger.GetLevel(), logger.LevelError)
+		assert.Equals(t, logger.GetModuleLevels(), map[string]logger.LogLevel{"http": logger.LevelDebug})
+	})
+
+	t.Run("when the body is invalid it should respond with a bad request", func(t *testing.T) {
+		logger.SetLevel(logger.LevelInfo)
+		recorder := serve(http.MethodPut, "{\"level\":\"verbose\"}")
+		assert.Equals(t, recorder.Code, http.StatusBadRequest)
+		assert.Contains(t, recorder.Body.String(), "invalid log level: verbose")
+		assert.Equals(t, logger.GetLevel(), logger.LevelInfo)
+	})
+
+	t.Run("when the method is not supported it should respond with method not allowed", func(t *testing.T) {
+		recorder := serve(http.MethodDelete, "")
+		assert.Equals(t, recorder.Code, http.StatusMethodNotAllowed)
+		assert.Equals(t, recorder.Header().Get(headers.Allow), "GET, PUT, POST")
+	})
+}
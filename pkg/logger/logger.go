@@ -18,116 +18,128 @@ func SetOutput(out io.Writer) {
 
 type LogFn func() []any
 
+// logPanic writes the message at the error level and panics with the formatted line of the message.
+func logPanic(ctx context.Context, msg string) {
+	write(ctx, LevelError, msg)
+	panic(fmt.Sprintln(formatLog(ctx, msg)))
+}
+
+// logFatal writes the message at the error level and exits the process.
+func logFatal(ctx context.Context, msg string) {
+	write(ctx, LevelError, msg)
+	os.Exit(1)
+}
+
 func Panic(ctx context.Context, args ...any) {
-	appLogger.Panicln(formatLog(ctx, fmt.Sprint(args...)))
+	logPanic(ctx, fmt.Sprint(args...))
 }
 
 func Panicf(ctx context.Context, format string, args ...any) {
-	appLogger.Panicln(formatLog(ctx, fmt.Sprintf(format, args...)))
+	logPanic(ctx, fmt.Sprintf(format, args...))
 }
 
 func PanicFn(ctx context.Context, fn LogFn) {
-	appLogger.Panicln(formatLog(ctx, fmt.Sprint(fn()...)))
+	logPanic(ctx, fmt.Sprint(fn()...))
 }
 
 func Fatal(ctx context.Context, args ...any) {
-	appLogger.Fatalln(formatLog(ctx, fmt.Sprint(args...)))
+	logFatal(ctx, fmt.Sprint(args...))
 }
 
 func Fatalf(ctx context.Context, format string, args ...any) {
-	appLogger.Fatalln(formatLog(ctx, fmt.Sprintf(format, args...)))
+	logFatal(ctx, fmt.Sprintf(format, args...))
 }
 
 func FatalFn(ctx context.Context, fn LogFn) {
-	appLogger.Fatalln(formatLog(ctx, fmt.Sprint(fn()...)))
+	logFatal(ctx, fmt.Sprint(fn()...))
 }
 
 func Error(ctx context.Context, args ...any) {
-	if appLogLevel >= LevelError {
-		appLogger.Println(formatLog(ctx, fmt.Sprint(args...)))
+	if enabled(ctx, LevelError) {
+		write(ctx, LevelError, fmt.Sprint(args...))
 	}
 }
 
 func Errorf(ctx context.Context, format string, args ...any) {
-	if appLogLevel >= LevelError {
-		appLogger.Println(formatLog(ctx, fmt.Sprintf(format, args...)))
+	if enabled(ctx, LevelError) {
+		write(ctx, LevelError, fmt.Sprintf(format, args...))
 	}
 }
 
 func ErrorFn(ctx context.Context, fn LogFn) {
-	if appLogLevel >= LevelError {
-		appLogger.Println(formatLog(ctx, fmt.Sprint(fn()...)))
+	if enabled(ctx, LevelError) {
+		write(ctx, LevelError, fmt.Sprint(fn()...))
 	}
 }
 
 func Warn(ctx context.Context, args ...any) {
-	if appLogLevel >= LevelWarn {
-		appLogger.Println(formatLog(ctx, fmt.Sprint(args...)))
+	if enabled(ctx, LevelWarn) {
+		write(ctx, LevelWarn, fmt.Sprint(args...))
 	}
 }
 
 func Warnf(ctx context.Context, format string, args ...any) {
-	if appLogLevel >= LevelWarn {
-		appLogger.Println(formatLog(ctx, fmt.Sprintf(format, args...)))
+	if enabled(ctx, LevelWarn) {
+		write(ctx, LevelWarn, fmt.Sprintf(format, args...))
 	}
 }
 
 func WarnFn(ctx context.Context, fn LogFn) {
-	if appLogLevel >= LevelWarn {
-		appLogger.Println(formatLog(ctx, fmt.Sprint(fn()...)))
+	if enabled(ctx, LevelWarn) {
+		write(ctx, LevelWarn, fmt.Sprint(fn()...))
 	}
 }
 
 func Info(ctx context.Context, args ...any) {
-	if appLogLevel >= LevelInfo {
-		appLogger.Println(formatLog(ctx, fmt.Sprint(args...)))
+	if enabled(ctx, LevelInfo) {
+		write(ctx, LevelInfo, fmt.Sprint(args...))
 	}
 }
 
 func Infof(ctx context.Context, format string, args ...any) {
-	if appLogLevel >= LevelInfo {
-		appLogger.Println(formatLog(ctx, fmt.Sprintf(format, args...)))
+	if enabled(ctx, LevelInfo) {
+		write(ctx, LevelInfo, fmt.Sprintf(format, args...))
 	}
 }
 
 func InfoFn(ctx context.Context, fn LogFn) {
-	if appLogLevel >= LevelInfo {
-		appLogger.Println(formatLog(ctx, fmt.Sprint(fn()...)))
+	if enabled(ctx, LevelInfo) {
+		write(ctx, LevelInfo, fmt.Sprint(fn()...))
 	}
 }
 
 func Debug(ctx context.Context, args ...any) {
-	if appLogLevel >= LevelDebug {
-		appLogger.Println(formatLog(ctx, fmt.Sprint(args...)))
+	if enabled(ctx, LevelDebug) {
+		write(ctx, LevelDebug, fmt.Sprint(args...))
 	}
 }
 
 func Debugf(ctx context.Context, format string, args ...any) {
-	if appLogLevel >= LevelDebug {
-		appLogger.Println(formatLog(ctx, fmt.Sprintf(format, args...)))
+	if enabled(ctx, LevelDebug) {
+		write(ctx, LevelDebug, fmt.Sprintf(format, args...))
 	}
 }
 
 func DebugFn(ctx context.Context, fn LogFn) {
-	if appLogLevel >= LevelDebug {
-		appLogger.Println(formatLog(ctx, fmt.Sprint(fn()...)))
+	if enabled(ctx, LevelDebug) {
+		write(ctx, LevelDebug, fmt.Sprint(fn()...))
 	}
 }
 
 func Trace(ctx context.Context, args ...any) {
-	if appLogLevel >= LevelTrace {
-		appLogger.Println(formatLog(ctx, fmt.Sprint(args...)))
+	if enabled(ctx, LevelTrace) {
+		write(ctx, LevelTrace, fmt.Sprint(args...))
 	}
 }
 
 func Tracef(ctx context.Context, format string, args ...any) {
-	if appLogLevel >= LevelTrace {
-		appLogger.Println(formatLog(ctx, fmt.Sprintf(format, args...)))
+	if enabled(ctx, LevelTrace) {
+		write(ctx, LevelTrace, fmt.Sprintf(format, args...))
 	}
 }
 
 func TraceFn(ctx context.Context, fn LogFn) {
-	if appLogLevel >= LevelTrace {
-		appLogger.Println(formatLog(ctx, fmt.Sprint(fn()...)))
+	if enabled(ctx, LevelTrace) {
+		write(ctx, LevelTrace, fmt.Sprint(fn()...))
 	}
 }
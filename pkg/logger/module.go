@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// ModuleLogField is the logger field that holds the module set with WithModule.
+	ModuleLogField = "module"
+)
+
+// moduleContextKeyType is the type of the context key of the module.
+type moduleContextKeyType string
+
+const (
+	// moduleContextKey is the context key of the module.
+	moduleContextKey moduleContextKeyType = "__logModule"
+)
+
+var (
+	// moduleLevelsLock guards moduleLevels.
+	moduleLevelsLock sync.RWMutex
+
+	// moduleLevels are the log levels that override the application log level for the modules.
+	moduleLevels = make(map[string]LogLevel)
+
+	// hasModuleLevels is true if moduleLevels is not empty. It avoids the lock when there are no overrides.
+	hasModuleLevels atomic.Bool
+)
+
+// WithModule names the module of the logs of the context, like "http" or "cache". The logs of the context use the
+// level of the module if it has one, and include the module in their fields.
+func WithModule(ctx context.Context, module string) context.Context {
+	ctx = context.WithValue(ctx, moduleContextKey, module)
+	return WithField(ctx, ModuleLogField, module)
+}
+
+// SetModuleLevel overrides the log level of the module. It is safe to call while the application logs.
+func SetModuleLevel(module string, level LogLevel) {
+	moduleLevelsLock.Lock()
+	defer moduleLevelsLock.Unlock()
+	moduleLevels[module] = level
+	hasModuleLevels.Store(true)
+}
+
+// RemoveModuleLevel removes the override of the log level of the module, so it uses the application log level.
+func RemoveModuleLevel(module string) {
+	moduleLevelsLock.Lock()
+	defer moduleLevelsLock.Unlock()
+	delete(moduleLevels, module)
+	hasModuleLevels.Store(len(moduleLevels) != 0)
+}
+
+// SetModuleLevels replaces all the overrides of the module log levels.
+func SetModuleLevels(levels map[string]LogLevel) {
+	moduleLevelsLock.Lock()
+	defer moduleLevelsLock.Unlock()
+	moduleLevels = make(map[string]LogLevel, len(levels))
+	maps.Copy(moduleLevels, levels)
+	hasModuleLevels.Store(len(moduleLevels) != 0)
+}
+
+// GetModuleLevels returns a copy of the overrides of the module log levels.
+func GetModuleLevels() map[string]LogLevel {
+	moduleLevelsLock.RLock()
+	defer moduleLevelsLock.RUnlock()
+	levels := make(map[string]LogLevel, len(moduleLevels))
+	maps.Copy(levels, moduleLevels)
+	return levels
+}
+
+// ParseModuleLevels parses comma separated module=level pairs, like "http=debug,cache=warn".
+// An empty string has no pairs.
+func ParseModuleLevels(value string) (map[string]LogLevel, error) {
+	levels := make(map[string]LogLevel)
+	if strings.TrimSpace(value) == "" {
+		return levels, nil
+	}
+	for _, pair := range strings.Split(value, ",") {
+		module, levelName, found := strings.Cut(pair, "=")
+		module = strings.TrimSpace(module)
+		if !found || module == "" {
+			return nil, fmt.Errorf("the module level '%s' must be formatted as module=level", strings.TrimSpace(pair))
+		}
+		level, err := ParseLevel(strings.TrimSpace(levelName))
+		if err != nil {
+			return nil, fmt.Errorf("the level of the module '%s' is invalid (%w)", module, err)
+		}
+		levels[module] = level
+	}
+	return levels, nil
+}
+
+// enabled returns true if the logs at the level are written for the context. The level of the module of the context
+// is used if it has one, otherwise the application log level is used.
+func enabled(ctx context.Context, level LogLevel) bool {
+	if hasModuleLevels.Load() && ctx != nil {
+		if module, hasModule := ctx.Value(moduleContextKey).(string); hasModule {
+			moduleLevelsLock.RLock()
+			moduleLevel, hasLevel := moduleLevels[module]
+			moduleLevelsLock.RUnlock()
+			if hasLevel {
+				return moduleLevel >= level
+			}
+		}
+	}
+	return GetLevel() >= level
+}
@@ -0,0 +1,91 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/logger"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestModuleLevels(t *testing.T) {
+	t.Cleanup(func() {
+		logger.SetOutput(os.Stdout)
+		logger.SetLevel(logger.LevelInfo)
+		logger.SetModuleLevels(nil)
+	})
+
+	var buffer bytes.Buffer
+	logger.SetOutput(&buffer)
+
+	t.Run("when a module has a level it should use it instead of the application level", func(t *testing.T) {
+		buffer.Reset()
+		logger.SetLevel(logger.LevelWarn)
+		logger.SetModuleLevels(map[string]logger.LogLevel{"http": logger.LevelDebug, "cache": logger.LevelError})
+		logger.Debug(logger.WithModule(context.Background(), "http"), "http debug")
+		logger.Warn(logger.WithModule(context.Background(), "cache"), "cache warn")
+		logger.Warn(logger.WithModule(context.Background(), "other"), "other warn")
+		logger.Info(context.Background(), "no module info")
+		assert.Contains(t, buffer.String(), "http debug")
+		assert.False(t, bytes.Contains(buffer.Bytes(), []byte("cache warn")))
+		assert.Contains(t, buffer.String(), "other warn")
+		assert.False(t, bytes.Contains(buffer.Bytes(), []byte("no module info")))
+	})
+
+	t.Run("when a module is set on the context it should be in the fields", func(t *testing.T) {
+		ctx := logger.WithModule(context.Background(), "http")
+		assert.Equals(t, logger.Fields(ctx)[logger.ModuleLogField], any("http"))
+	})
+
+	t.Run("when the level of a module is removed it should use the application level", func(t *testing.T) {
+		buffer.Reset()
+		logger.SetLevel(logger.LevelInfo)
+		logger.SetModuleLevels(nil)
+		logger.SetModuleLevel("http", logger.LevelTrace)
+		assert.Equals(t, logger.GetModuleLevels(), map[string]logger.LogLevel{"http": logger.LevelTrace})
+		logger.RemoveModuleLevel("http")
+		logger.Debug(logger.WithModule(context.Background(), "http"), "http debug")
+		assert.Equals(t, buffer.String(), "")
+		assert.Equals(t, logger.GetModuleLevels(), map[string]logger.LogLevel{})
+	})
+
+	t.Run("when the module levels are copied it should not change the module levels", func(t *testing.T) {
+		levels := map[string]logger.LogLevel{"http": logger.LevelDebug}
+		logger.SetModuleLevels(levels)
+		levels["http"] = logger.LevelError
+		logger.GetModuleLevels()["http"] = logger.LevelError
+		assert.Equals(t, logger.GetModuleLevels(), map[string]logger.LogLevel{"http": logger.LevelDebug})
+	})
+}
+
+func TestParseModuleLevels(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when the string is empty it should have no levels", func(t *testing.T) {
+		t.Parallel()
+		levels, err := logger.ParseModuleLevels(" ")
+		assert.NoError(t, err)
+		assert.Equals(t, levels, map[string]logger.LogLevel{})
+	})
+
+	t.Run("when the pairs are valid it should parse them", func(t *testing.T) {
+		t.Parallel()
+		levels, err := logger.ParseModuleLevels("http=debug, cache = WARN")
+		assert.NoError(t, err)
+		assert.Equals(t, levels, map[string]logger.LogLevel{"http": logger.LevelDebug, "cache": logger.LevelWarn})
+	})
+
+	t.Run("when a pair has no module it should return an error", func(t *testing.T) {
+		t.Parallel()
+		_, err := logger.ParseModuleLevels("=debug")
+		assert.ErrorExact(t, err, "the module level '=debug' must be formatted as module=level")
+	})
+
+	t.Run("when a level is invalid it should return an error", func(t *testing.T) {
+		t.Parallel()
+		_, err := logger.ParseModuleLevels("http=verbose")
+		assert.ErrorExact(t, err, "the level of the module 'http' is invalid (invalid log level: verbose)")
+	})
+}
@@ -0,0 +1,388 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+)
+
+const (
+	// DefaultOTLPBatchSize is the number of entries that are exported together by default.
+	DefaultOTLPBatchSize = 512
+
+	// DefaultOTLPQueueSize is the number of entries that can wait to be exported by default.
+	DefaultOTLPQueueSize = 2048
+
+	// DefaultOTLPFlushInterval is the interval at which the entries are exported by default.
+	DefaultOTLPFlushInterval = 5 * time.Second
+
+	// otlpScopeName is the instrumentation scope of the exported entries.
+	otlpScopeName = "github.com/TriangleSide/GoBase/pkg/logger"
+)
+
+var (
+	// ErrOTLPExporterShutdown is returned when flushing an OTLPExporter that is shut down.
+	ErrOTLPExporterShutdown = errors.New("the OTLP exporter is shut down")
+)
+
+// otlpConfig is configured by the OTLPOption functions.
+type otlpConfig struct {
+	batchSize     int
+	queueSize     int
+	flushInterval time.Duration
+	serviceName   string
+	headers       map[string]string
+	client        *http.Client
+}
+
+// OTLPOption is used to configure the OTLPExporter.
+type OTLPOption func(cfg *otlpConfig)
+
+// WithOTLPBatchSize sets the number of entries that are exported together. The default is DefaultOTLPBatchSize.
+// If the size is not positive, this function panics.
+func WithOTLPBatchSize(batchSize int) OTLPOption {
+	if batchSize <= 0 {
+		panic(fmt.Sprintf("the OTLP batch size %d must be greater than zero", batchSize))
+	}
+	return func(cfg *otlpConfig) {
+		cfg.batchSize = batchSize
+	}
+}
+
+// WithOTLPQueueSize sets the number of entries that can wait to be exported. The entries written when the queue
+// is full are dropped, so the logs never wait on the collector. The default is DefaultOTLPQueueSize.
+// If the size is not positive, this function panics.
+func WithOTLPQueueSize(queueSize int) OTLPOption {
+	if queueSize <= 0 {
+		panic(fmt.Sprintf("the OTLP queue size %d must be greater than zero", queueSize))
+	}
+	return func(cfg *otlpConfig) {
+		cfg.queueSize = queueSize
+	}
+}
+
+// WithOTLPFlushInterval sets the interval at which the entries are exported if the batch is not full.
+// The default is DefaultOTLPFlushInterval. If the interval is not positive, this function panics.
+func WithOTLPFlushInterval(interval time.Duration) OTLPOption {
+	if interval <= 0 {
+		panic(fmt.Sprintf("the OTLP flush interval %s must be greater than zero", interval))
+	}
+	return func(cfg *otlpConfig) {
+		cfg.flushInterval = interval
+	}
+}
+
+// WithOTLPServiceName sets the service.name attribute of the resource of the exported entries.
+func WithOTLPServiceName(serviceName string) OTLPOption {
+	return func(cfg *otlpConfig) {
+		cfg.serviceName = serviceName
+	}
+}
+
+// WithOTLPHeaders sets headers on the export requests, like the credentials of the collector.
+func WithOTLPHeaders(requestHeaders map[string]string) OTLPOption {
+	return func(cfg *otlpConfig) {
+		cfg.headers = maps.Clone(requestHeaders)
+	}
+}
+
+// WithOTLPClient sets the HTTP client of the export requests. The default is a client with a ten second timeout.
+// If the client is nil, this function panics.
+func WithOTLPClient(client *http.Client) OTLPOption {
+	if client == nil {
+		panic("the OTLP client cannot be nil")
+	}
+	return func(cfg *otlpConfig) {
+		cfg.client = client
+	}
+}
+
+// otlpRecord is an entry waiting to be exported.
+type otlpRecord struct {
+	time   time.Time
+	level  LogLevel
+	fields map[string]any
+	msg    string
+}
+
+// OTLPExporter is a Backend that exports the entries in batches to an OpenTelemetry collector with OTLP/HTTP,
+// using the JSON encoding. The TraceIDLogField and SpanIDLogField are exported as the trace and span IDs of the
+// records to correlate the logs with the traces, and the other fields are exported as attributes.
+//
+// The entries are exported by a goroutine when a batch is full or at the flush interval. Shutdown exports the
+// remaining entries and stops the goroutine. Export failures are written to stderr, since the logs may be
+// written to this exporter.
+type OTLPExporter struct {
+	endpoint string
+	cfg      *otlpConfig
+
+	lock    sync.Mutex
+	records []otlpRecord
+	dropped int
+
+	exportLock   sync.Mutex
+	flush        chan struct{}
+	shutdownOnce sync.Once
+	stop         chan struct{}
+	done         chan struct{}
+}
+
+// NewOTLPExporter creates an OTLPExporter that sends the entries to the endpoint, like
+// http://localhost:4318/v1/logs. If the endpoint is empty, this function panics.
+func NewOTLPExporter(endpoint string, opts ...OTLPOption) *OTLPExporter {
+	if endpoint == "" {
+		panic("the OTLP endpoint cannot be empty")
+	}
+
+	cfg := &otlpConfig{
+		batchSize:     DefaultOTLPBatchSize,
+		queueSize:     DefaultOTLPQueueSize,
+		flushInterval: DefaultOTLPFlushInterval,
+		serviceName:   "",
+		headers:       nil,
+		client:        &http.Client{Timeout: 10 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	exporter := &OTLPExporter{
+		endpoint: endpoint,
+		cfg:      cfg,
+		records:  make([]otlpRecord, 0, cfg.batchSize),
+		flush:    make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go exporter.run()
+	return exporter
+}
+
+// Write is the implementation of the Backend interface.
+func (e *OTLPExporter) Write(_ context.Context, level LogLevel, fields map[string]any, msg string) {
+	record := otlpRecord{time: time.Now(), level: level, fields: fields, msg: msg}
+
+	e.lock.Lock()
+	if len(e.records) >= e.cfg.queueSize {
+		e.dropped++
+		e.lock.Unlock()
+		return
+	}
+	e.records = append(e.records, record)
+	batchFull := len(e.records) >= e.cfg.batchSize
+	e.lock.Unlock()
+
+	if batchFull {
+		select {
+		case e.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Flush exports the entries that are waiting to be exported.
+func (e *OTLPExporter) Flush(ctx context.Context) error {
+	select {
+	case <-e.done:
+		return ErrOTLPExporterShutdown
+	default:
+		return e.export(ctx)
+	}
+}
+
+// Shutdown stops exporting at the flush interval and exports the entries that are waiting to be exported.
+// It is safe to call Shutdown many times.
+func (e *OTLPExporter) Shutdown(ctx context.Context) error {
+	var err error
+	e.shutdownOnce.Do(func() {
+		close(e.stop)
+		<-e.done
+		err = e.export(ctx)
+	})
+	return err
+}
+
+// run exports the entries at each flush interval, or when a batch is full, until the exporter is shut down.
+func (e *OTLPExporter) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(e.cfg.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.stop:
+			return
+		case <-ticker.C:
+		case <-e.flush:
+		}
+		if err := e.export(context.Background()); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to export the logs to %s (%s).\n", e.endpoint, err)
+		}
+	}
+}
+
+// export sends the waiting entries in batches. The entries of a batch that fails to be sent are dropped.
+func (e *OTLPExporter) export(ctx context.Context) error {
+	e.exportLock.Lock()
+	defer e.exportLock.Unlock()
+
+	e.lock.Lock()
+	records := e.records
+	dropped := e.dropped
+	e.records = make([]otlpRecord, 0, e.cfg.batchSize)
+	e.dropped = 0
+	e.lock.Unlock()
+
+	if dropped != 0 {
+		records = append(records, otlpRecord{
+			time:   time.Now(),
+			level:  LevelWarn,
+			fields: map[string]any{DroppedLogField: dropped},
+			msg:    "Log entries were dropped because the OTLP export queue was full.",
+		})
+	}
+
+	var errs []error
+	for batch := range slices.Chunk(records, e.cfg.batchSize) {
+		if err := e.send(ctx, batch); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// send posts the batch of entries to the endpoint.
+func (e *OTLPExporter) send(ctx context.Context, batch []otlpRecord) error {
+	body, err := json.Marshal(e.request(batch))
+	if err != nil {
+		return fmt.Errorf("failed to encode the OTLP request (%w)", err)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create the OTLP request (%w)", err)
+	}
+	request.Header.Set(headers.ContentType, headers.ContentTypeApplicationJson)
+	for key, value := range e.cfg.headers {
+		request.Header.Set(key, value)
+	}
+	response, err := e.cfg.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to send the OTLP request (%w)", err)
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, response.Body)
+		_ = response.Body.Close()
+	}()
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("the OTLP collector responded with the status %d", response.StatusCode)
+	}
+	return nil
+}
+
+// request creates the body of an OTLP/HTTP logs request in the JSON encoding.
+func (e *OTLPExporter) request(batch []otlpRecord) map[string]any {
+	resourceAttributes := make([]map[string]any, 0, 1)
+	if e.cfg.serviceName != "" {
+		resourceAttributes = append(resourceAttributes, otlpAttribute("service.name", e.cfg.serviceName))
+	}
+	logRecords := make([]map[string]any, 0, len(batch))
+	for _, record := range batch {
+		logRecords = append(logRecords, otlpLogRecord(record))
+	}
+	return map[string]any{
+		"resourceLogs": []map[string]any{{
+			"resource": map[string]any{
+				"attributes": resourceAttributes,
+			},
+			"scopeLogs": []map[string]any{{
+				"scope": map[string]any{
+					"name": otlpScopeName,
+				},
+				"logRecords": logRecords,
+			}},
+		}},
+	}
+}
+
+// otlpLogRecord converts an entry to an OTLP log record.
+func otlpLogRecord(record otlpRecord) map[string]any {
+	timestamp := strconv.FormatInt(record.time.UnixNano(), 10)
+	logRecord := map[string]any{
+		"timeUnixNano":         timestamp,
+		"observedTimeUnixNano": timestamp,
+		"severityNumber":       OTLPSeverityNumber(record.level),
+		"severityText":         record.level.String(),
+		"body":                 map[string]any{"stringValue": record.msg},
+	}
+	keys := slices.Sorted(maps.Keys(record.fields))
+	attributes := make([]map[string]any, 0, len(keys))
+	for _, key := range keys {
+		value := record.fields[key]
+		switch key {
+		case TraceIDLogField:
+			logRecord["traceId"] = fmt.Sprint(value)
+		case SpanIDLogField:
+			logRecord["spanId"] = fmt.Sprint(value)
+		default:
+			attributes = append(attributes, otlpAttribute(key, value))
+		}
+	}
+	logRecord["attributes"] = attributes
+	return logRecord
+}
+
+// otlpAttribute converts a field to an OTLP attribute.
+func otlpAttribute(key string, value any) map[string]any {
+	var anyValue map[string]any
+	switch typed := value.(type) {
+	case string:
+		anyValue = map[string]any{"stringValue": typed}
+	case bool:
+		anyValue = map[string]any{"boolValue": typed}
+	case int:
+		anyValue = map[string]any{"intValue": strconv.FormatInt(int64(typed), 10)}
+	case int32:
+		anyValue = map[string]any{"intValue": strconv.FormatInt(int64(typed), 10)}
+	case int64:
+		anyValue = map[string]any{"intValue": strconv.FormatInt(typed, 10)}
+	case uint32:
+		anyValue = map[string]any{"intValue": strconv.FormatUint(uint64(typed), 10)}
+	case float64:
+		if math.IsNaN(typed) || math.IsInf(typed, 0) {
+			anyValue = map[string]any{"stringValue": fmt.Sprint(typed)}
+		} else {
+			anyValue = map[string]any{"doubleValue": typed}
+		}
+	default:
+		anyValue = map[string]any{"stringValue": fmt.Sprint(value)}
+	}
+	return map[string]any{"key": key, "value": anyValue}
+}
+
+// OTLPSeverityNumber converts a LogLevel to its OpenTelemetry severity number.
+func OTLPSeverityNumber(level LogLevel) int {
+	switch level {
+	case LevelError:
+		return 17
+	case LevelWarn:
+		return 13
+	case LevelInfo:
+		return 9
+	case LevelDebug:
+		return 5
+	default:
+		return 1
+	}
+}
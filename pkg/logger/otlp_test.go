@@ -0,0 +1,189 @@
+package logger_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/logger"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+// otlpCollector records the log records of the OTLP requests it receives.
+type otlpCollector struct {
+	lock      sync.Mutex
+	requests  []map[string]any
+	records   []map[string]any
+	status    int
+	lastToken string
+}
+
+func newOTLPCollector(t *testing.T) (*otlpCollector, string) {
+	t.Helper()
+	collector := &otlpCollector{status: http.StatusOK}
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equals(t, request.Method, http.MethodPost)
+		assert.Equals(t, request.Header.Get(headers.ContentType), headers.ContentTypeApplicationJson)
+		body := map[string]any{}
+		assert.NoError(t, json.NewDecoder(request.Body).Decode(&body))
+		collector.lock.Lock()
+		defer collector.lock.Unlock()
+		collector.requests = append(collector.requests, body)
+		collector.lastToken = request.Header.Get("Authorization")
+		resourceLogs := body["resourceLogs"].([]any)[0].(map[string]any)
+		scopeLogs := resourceLogs["scopeLogs"].([]any)[0].(map[string]any)
+		for _, record := range scopeLogs["logRecords"].([]any) {
+			collector.records = append(collector.records, record.(map[string]any))
+		}
+		writer.WriteHeader(collector.status)
+	}))
+	t.Cleanup(server.Close)
+	return collector, server.URL + "/v1/logs"
+}
+
+func (c *otlpCollector) recordCount() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.records)
+}
+
+func TestOTLPExporter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when the options are invalid it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicExact(t, func() {
+			logger.NewOTLPExporter("")
+		}, "the OTLP endpoint cannot be empty")
+		assert.PanicExact(t, func() {
+			logger.WithOTLPBatchSize(0)
+		}, "the OTLP batch size 0 must be greater than zero")
+		assert.PanicExact(t, func() {
+			logger.WithOTLPQueueSize(0)
+		}, "the OTLP queue size 0 must be greater than zero")
+		assert.PanicExact(t, func() {
+			logger.WithOTLPFlushInterval(0)
+		}, "the OTLP flush interval 0s must be greater than zero")
+		assert.PanicExact(t, func() {
+			logger.WithOTLPClient(nil)
+		}, "the OTLP client cannot be nil")
+	})
+
+	t.Run("when the exporter is shut down it should export the entries as OTLP records", func(t *testing.T) {
+		t.Parallel()
+		collector, endpoint := newOTLPCollector(t)
+		exporter := logger.NewOTLPExporter(endpoint,
+			logger.WithOTLPServiceName("service"),
+			logger.WithOTLPHeaders(map[string]string{"Authorization": "Bearer token"}),
+			logger.WithOTLPFlushInterval(time.Hour))
+		exporter.Write(context.Background(), logger.LevelError, map[string]any{
+			logger.TraceIDLogField: "4bf92f3577b34da6a3ce929d0e0e4736",
+			logger.SpanIDLogField:  "00f067aa0ba902b7",
+			"attempt":              3,
+			"ratio":                0.5,
+			"retry":                true,
+			"user_id":              "user",
+			"duration":             time.Second,
+		}, "failed")
+		assert.NoError(t, exporter.Shutdown(context.Background()))
+		assert.NoError(t, exporter.Shutdown(context.Background()))
+		assert.ErrorExact(t, exporter.Flush(context.Background()), logger.ErrOTLPExporterShutdown.Error())
+
+		assert.Equals(t, len(collector.requests), 1)
+		assert.Equals(t, collector.lastToken, "Bearer token")
+		resourceLogs := collector.requests[0]["resourceLogs"].([]any)[0].(map[string]any)
+		assert.Equals(t, resourceLogs["resource"], any(map[string]any{
+			"attributes": []any{map[string]any{"key": "service.name", "value": map[string]any{"stringValue": "service"}}},
+		}))
+		record := collector.records[0]
+		assert.NotNil(t, record["timeUnixNano"])
+		delete(record, "timeUnixNano")
+		delete(record, "observedTimeUnixNano")
+		assert.Equals(t, record, map[string]any{
+			"severityNumber": float64(17),
+			"severityText":   "ERROR",
+			"body":           map[string]any{"stringValue": "failed"},
+			"traceId":        "4bf92f3577b34da6a3ce929d0e0e4736",
+			"spanId":         "00f067aa0ba902b7",
+			"attributes": []any{
+				map[string]any{"key": "attempt", "value": map[string]any{"intValue": "3"}},
+				map[string]any{"key": "duration", "value": map[string]any{"stringValue": "1s"}},
+				map[string]any{"key": "ratio", "value": map[string]any{"doubleValue": 0.5}},
+				map[string]any{"key": "retry", "value": map[string]any{"boolValue": true}},
+				map[string]any{"key": "user_id", "value": map[string]any{"stringValue": "user"}},
+			},
+		})
+	})
+
+	t.Run("when a batch is full it should export it without waiting for the interval", func(t *testing.T) {
+		t.Parallel()
+		collector, endpoint := newOTLPCollector(t)
+		exporter := logger.NewOTLPExporter(endpoint, logger.WithOTLPBatchSize(2), logger.WithOTLPFlushInterval(time.Hour))
+		t.Cleanup(func() {
+			assert.NoError(t, exporter.Shutdown(context.Background()))
+		})
+		exporter.Write(context.Background(), logger.LevelInfo, nil, "first")
+		exporter.Write(context.Background(), logger.LevelInfo, nil, "second")
+		for collector.recordCount() != 2 {
+			time.Sleep(time.Millisecond)
+		}
+	})
+
+	t.Run("when the flush interval passes it should export the entries", func(t *testing.T) {
+		t.Parallel()
+		collector, endpoint := newOTLPCollector(t)
+		exporter := logger.NewOTLPExporter(endpoint, logger.WithOTLPFlushInterval(time.Millisecond))
+		t.Cleanup(func() {
+			assert.NoError(t, exporter.Shutdown(context.Background()))
+		})
+		exporter.Write(context.Background(), logger.LevelDebug, nil, "debug")
+		for collector.recordCount() != 1 {
+			time.Sleep(time.Millisecond)
+		}
+	})
+
+	t.Run("when the queue is full it should drop the entries and report them", func(t *testing.T) {
+		t.Parallel()
+		collector, endpoint := newOTLPCollector(t)
+		exporter := logger.NewOTLPExporter(endpoint, logger.WithOTLPQueueSize(1), logger.WithOTLPFlushInterval(time.Hour))
+		for i := 0; i < 3; i++ {
+			exporter.Write(context.Background(), logger.LevelInfo, nil, "entry")
+		}
+		assert.NoError(t, exporter.Flush(context.Background()))
+		assert.NoError(t, exporter.Shutdown(context.Background()))
+		assert.Equals(t, len(collector.records), 2)
+		assert.Equals(t, collector.records[1]["attributes"], any([]any{
+			map[string]any{"key": logger.DroppedLogField, "value": map[string]any{"intValue": "2"}},
+		}))
+	})
+
+	t.Run("when the collector fails it should return an error", func(t *testing.T) {
+		t.Parallel()
+		collector, endpoint := newOTLPCollector(t)
+		collector.status = http.StatusServiceUnavailable
+		exporter := logger.NewOTLPExporter(endpoint, logger.WithOTLPFlushInterval(time.Hour))
+		exporter.Write(context.Background(), logger.LevelInfo, nil, "entry")
+		assert.ErrorExact(t, exporter.Shutdown(context.Background()), "the OTLP collector responded with the status 503")
+	})
+
+	t.Run("when the collector is unreachable it should return an error", func(t *testing.T) {
+		t.Parallel()
+		exporter := logger.NewOTLPExporter("http://127.0.0.1:1/v1/logs", logger.WithOTLPFlushInterval(time.Hour))
+		exporter.Write(context.Background(), logger.LevelInfo, nil, "entry")
+		assert.ErrorPart(t, exporter.Shutdown(context.Background()), "failed to send the OTLP request")
+	})
+
+	t.Run("when converting the levels to severity numbers", func(t *testing.T) {
+		t.Parallel()
+		assert.Equals(t, logger.OTLPSeverityNumber(logger.LevelError), 17)
+		assert.Equals(t, logger.OTLPSeverityNumber(logger.LevelWarn), 13)
+		assert.Equals(t, logger.OTLPSeverityNumber(logger.LevelInfo), 9)
+		assert.Equals(t, logger.OTLPSeverityNumber(logger.LevelDebug), 5)
+		assert.Equals(t, logger.OTLPSeverityNumber(logger.LevelTrace), 1)
+	})
+}
@@ -0,0 +1,441 @@
+package logger
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// backupTimeFormat is the format of the time in the names of the rotated files.
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+
+	// compressedSuffix is the suffix of the rotated files that are compressed.
+	compressedSuffix = ".gz"
+)
+
+var (
+	// ErrFileClosed is returned when writing to a RotatingFile that is closed, or whose file could not be opened
+	// again after a rotation or a reopen.
+	ErrFileClosed = errors.New("the log file is closed")
+)
+
+// fileConfig is configured by the FileOption functions.
+type fileConfig struct {
+	maxSize          int64
+	rotationInterval time.Duration
+	maxAge           time.Duration
+	maxBackups       int
+	compress         bool
+	reopenSignals    []os.Signal
+}
+
+// FileOption is used to configure the RotatingFile.
+type FileOption func(cfg *fileConfig)
+
+// WithMaxSize rotates the file before a write would make it larger than the size in bytes.
+// If the size is not positive, this function panics.
+func WithMaxSize(maxSize int64) FileOption {
+	if maxSize <= 0 {
+		panic(fmt.Sprintf("the max file size %d must be greater than zero", maxSize))
+	}
+	return func(cfg *fileConfig) {
+		cfg.maxSize = maxSize
+	}
+}
+
+// WithRotationInterval rotates the file on the first write after it has been open for the interval.
+// If the interval is not positive, this function panics.
+func WithRotationInterval(interval time.Duration) FileOption {
+	if interval <= 0 {
+		panic(fmt.Sprintf("the rotation interval %s must be greater than zero", interval))
+	}
+	return func(cfg *fileConfig) {
+		cfg.rotationInterval = interval
+	}
+}
+
+// WithMaxAge removes the rotated files that are older than the age. If the age is not positive, this function panics.
+func WithMaxAge(maxAge time.Duration) FileOption {
+	if maxAge <= 0 {
+		panic(fmt.Sprintf("the max age %s must be greater than zero", maxAge))
+	}
+	return func(cfg *fileConfig) {
+		cfg.maxAge = maxAge
+	}
+}
+
+// WithMaxBackups keeps the most recent rotated files and removes the others.
+// If the count is not positive, this function panics.
+func WithMaxBackups(maxBackups int) FileOption {
+	if maxBackups <= 0 {
+		panic(fmt.Sprintf("the max backups %d must be greater than zero", maxBackups))
+	}
+	return func(cfg *fileConfig) {
+		cfg.maxBackups = maxBackups
+	}
+}
+
+// WithCompression compresses the rotated files with gzip.
+func WithCompression() FileOption {
+	return func(cfg *fileConfig) {
+		cfg.compress = true
+	}
+}
+
+// WithReopenOnSignal reopens the file when the process receives one of the signals, like SIGHUP. This lets tools
+// like logrotate move the file and have the logs written to a new one at the same path.
+// If there are no signals, this function panics.
+func WithReopenOnSignal(signals ...os.Signal) FileOption {
+	if len(signals) == 0 {
+		panic("the reopen signals cannot be empty")
+	}
+	return func(cfg *fileConfig) {
+		cfg.reopenSignals = signals
+	}
+}
+
+// RotatingFile is an io.Writer that writes to a file and rotates it by size or time. A rotated file is renamed
+// with the time of the rotation, like app-2006-01-02T15-04-05.000.log for the file app.log, and the new logs are
+// written to a new file at the path. A counter is added to the name, like app-2006-01-02T15-04-05.000-1.log,
+// when the file is rotated many times in the same millisecond. The rotated files can be compressed and removed by age or count, which is
+// done in the background after a rotation.
+type RotatingFile struct {
+	path string
+	cfg  *fileConfig
+
+	lock     sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+
+	cleanupLock sync.Mutex
+	cleanups    sync.WaitGroup
+
+	signals   chan os.Signal
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// NewRotatingFile opens the file at the path for appending, creating it and its directory if needed.
+func NewRotatingFile(path string, opts ...FileOption) (*RotatingFile, error) {
+	cfg := &fileConfig{
+		maxSize:          0,
+		rotationInterval: 0,
+		maxAge:           0,
+		maxBackups:       0,
+		compress:         false,
+		reopenSignals:    nil,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	rotatingFile := &RotatingFile{
+		path: path,
+		cfg:  cfg,
+		done: make(chan struct{}),
+	}
+	if err := rotatingFile.open(); err != nil {
+		return nil, err
+	}
+	if len(cfg.reopenSignals) != 0 {
+		rotatingFile.signals = make(chan os.Signal, 1)
+		signal.Notify(rotatingFile.signals, cfg.reopenSignals...)
+		go rotatingFile.reopenOnSignal()
+	}
+	return rotatingFile, nil
+}
+
+// Write is the implementation of the io.Writer interface. The file is rotated before the write if it is due.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.file == nil {
+		return 0, ErrFileClosed
+	}
+	if f.rotationDue(int64(len(p))) {
+		if err := f.rotateLocked(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Rotate renames the file with the time of the rotation and opens a new file at the path.
+func (f *RotatingFile) Rotate() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.file == nil {
+		return ErrFileClosed
+	}
+	return f.rotateLocked()
+}
+
+// Reopen closes the file and opens the file at the path, which is a new file if the previous one was moved.
+func (f *RotatingFile) Reopen() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.file == nil {
+		return ErrFileClosed
+	}
+	err := f.file.Close()
+	f.file = nil
+	if err != nil {
+		return fmt.Errorf("failed to close the log file (%w)", err)
+	}
+	return f.open()
+}
+
+// Close closes the file and waits for the cleanup of the rotated files. It is safe to call Close many times.
+func (f *RotatingFile) Close() error {
+	var err error
+	f.closeOnce.Do(func() {
+		if f.signals != nil {
+			signal.Stop(f.signals)
+			close(f.done)
+		}
+		f.lock.Lock()
+		if f.file != nil {
+			err = f.file.Close()
+			f.file = nil
+		}
+		f.lock.Unlock()
+		f.cleanups.Wait()
+	})
+	return err
+}
+
+// rotationDue returns true if the file must be rotated before writing the number of bytes.
+func (f *RotatingFile) rotationDue(writeSize int64) bool {
+	if f.cfg.maxSize > 0 && f.size > 0 && f.size+writeSize > f.cfg.maxSize {
+		return true
+	}
+	return f.cfg.rotationInterval > 0 && time.Since(f.openedAt) >= f.cfg.rotationInterval
+}
+
+// open opens the file at the path for appending.
+func (f *RotatingFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create the directory of the log file (%w)", err)
+	}
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open the log file (%w)", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return errors.Join(fmt.Errorf("failed to stat the log file (%w)", err), file.Close())
+	}
+	f.file = file
+	f.size = info.Size()
+	f.openedAt = time.Now()
+	return nil
+}
+
+// rotateLocked renames the file, opens a new one and cleans up the rotated files in the background. If the new
+// file cannot be opened, the RotatingFile is left without a file and the writes fail with ErrFileClosed.
+func (f *RotatingFile) rotateLocked() error {
+	err := f.file.Close()
+	f.file = nil
+	if err != nil {
+		return fmt.Errorf("failed to close the log file (%w)", err)
+	}
+	backupPath := f.backupPath(time.Now())
+	if err := os.Rename(f.path, backupPath); err != nil {
+		return errors.Join(fmt.Errorf("failed to rename the log file (%w)", err), f.open())
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	f.cleanups.Add(1)
+	go func() {
+		defer f.cleanups.Done()
+		f.cleanup(backupPath)
+	}()
+	return nil
+}
+
+// reopenOnSignal reopens the file each time a reopen signal is received, until the file is closed.
+func (f *RotatingFile) reopenOnSignal() {
+	for {
+		select {
+		case <-f.done:
+			return
+		case <-f.signals:
+			if err := f.Reopen(); err != nil && !errors.Is(err, ErrFileClosed) {
+				fmt.Fprintf(os.Stderr, "Failed to reopen the log file %s (%s).\n", f.path, err)
+			}
+		}
+	}
+}
+
+// backupNameParts returns the prefix and the extension of the names of the rotated files.
+func (f *RotatingFile) backupNameParts() (string, string) {
+	extension := filepath.Ext(f.path)
+	return strings.TrimSuffix(filepath.Base(f.path), extension) + "-", extension
+}
+
+// backupPath returns the path of the file rotated at the time. A counter is added to the name if a rotated
+// file, compressed or not, already has it, so a rotation never replaces an earlier one.
+func (f *RotatingFile) backupPath(rotatedAt time.Time) string {
+	prefix, extension := f.backupNameParts()
+	name := prefix + rotatedAt.UTC().Format(backupTimeFormat)
+	backupPath := filepath.Join(filepath.Dir(f.path), name+extension)
+	for counter := 1; backupExists(backupPath); counter++ {
+		backupPath = filepath.Join(filepath.Dir(f.path), name+"-"+strconv.Itoa(counter)+extension)
+	}
+	return backupPath
+}
+
+// backupExists returns true if a rotated file has the path, or the path once compressed.
+func backupExists(backupPath string) bool {
+	for _, candidate := range []string{backupPath, backupPath + compressedSuffix} {
+		if _, err := os.Lstat(candidate); !errors.Is(err, os.ErrNotExist) {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanup compresses the rotated file and removes the rotated files that exceed the max age or max backups.
+// The errors are written to stderr since the logger may be writing to this file.
+func (f *RotatingFile) cleanup(backupPath string) {
+	f.cleanupLock.Lock()
+	defer f.cleanupLock.Unlock()
+
+	if f.cfg.compress {
+		if err := compressFile(backupPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to compress the log file %s (%s).\n", backupPath, err)
+		}
+	}
+	if err := f.removeOldBackups(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to remove the old log files of %s (%s).\n", f.path, err)
+	}
+}
+
+// rotatedFile is a file rotated from the RotatingFile.
+type rotatedFile struct {
+	path      string
+	rotatedAt time.Time
+	counter   int
+}
+
+// removeOldBackups removes the rotated files that exceed the max age or max backups.
+func (f *RotatingFile) removeOldBackups() error {
+	if f.cfg.maxAge <= 0 && f.cfg.maxBackups <= 0 {
+		return nil
+	}
+	backups, err := f.rotatedFiles()
+	if err != nil {
+		return err
+	}
+	slices.SortFunc(backups, func(a, b rotatedFile) int {
+		if order := b.rotatedAt.Compare(a.rotatedAt); order != 0 {
+			return order
+		}
+		return b.counter - a.counter
+	})
+	var errs []error
+	for i, backup := range backups {
+		tooMany := f.cfg.maxBackups > 0 && i >= f.cfg.maxBackups
+		tooOld := f.cfg.maxAge > 0 && time.Since(backup.rotatedAt) > f.cfg.maxAge
+		if tooMany || tooOld {
+			if err := os.Remove(backup.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// rotatedFiles lists the files rotated from the RotatingFile.
+func (f *RotatingFile) rotatedFiles() ([]rotatedFile, error) {
+	dir := filepath.Dir(f.path)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the log directory (%w)", err)
+	}
+	prefix, extension := f.backupNameParts()
+	backups := make([]rotatedFile, 0)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), compressedSuffix)
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, extension) {
+			continue
+		}
+		timestamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), extension)
+		rotatedAt, counter, ok := parseBackupTimestamp(timestamp)
+		if !ok {
+			continue
+		}
+		backups = append(backups, rotatedFile{path: filepath.Join(dir, entry.Name()), rotatedAt: rotatedAt, counter: counter})
+	}
+	return backups, nil
+}
+
+// parseBackupTimestamp parses the time of the rotation and the counter in the name of a rotated file.
+// The counter is zero if the name does not have one.
+func parseBackupTimestamp(timestamp string) (time.Time, int, bool) {
+	if len(timestamp) < len(backupTimeFormat) {
+		return time.Time{}, 0, false
+	}
+	rotatedAt, err := time.Parse(backupTimeFormat, timestamp[:len(backupTimeFormat)])
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+	counterPart := timestamp[len(backupTimeFormat):]
+	if counterPart == "" {
+		return rotatedAt, 0, true
+	}
+	counterText, hasCounter := strings.CutPrefix(counterPart, "-")
+	counter, err := strconv.Atoi(counterText)
+	if !hasCounter || err != nil || counter <= 0 {
+		return time.Time{}, 0, false
+	}
+	return rotatedAt, counter, true
+}
+
+// compressFile writes the file compressed with gzip next to it and removes it.
+func compressFile(path string) error {
+	compressedPath := path + compressedSuffix
+	if err := writeCompressed(path, compressedPath); err != nil {
+		return errors.Join(err, os.Remove(compressedPath))
+	}
+	return os.Remove(path)
+}
+
+// writeCompressed writes the source file compressed with gzip to the destination file.
+func writeCompressed(sourcePath string, destinationPath string) (returnErr error) {
+	source, err := os.Open(sourcePath)
+	if err != nil {
+		return fmt.Errorf("failed to open the file (%w)", err)
+	}
+	defer func() {
+		returnErr = errors.Join(returnErr, source.Close())
+	}()
+	destination, err := os.OpenFile(destinationPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create the compressed file (%w)", err)
+	}
+	gzipWriter := gzip.NewWriter(destination)
+	_, copyErr := io.Copy(gzipWriter, source)
+	if err := errors.Join(copyErr, gzipWriter.Close(), destination.Close()); err != nil {
+		return fmt.Errorf("failed to compress the file (%w)", err)
+	}
+	return nil
+}
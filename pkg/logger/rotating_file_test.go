@@ -0,0 +1,249 @@
+package logger_test
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/logger"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+// rotatedFileNames returns the sorted names of the rotated files of app.log in the directory.
+func rotatedFileNames(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	names := make([]string, 0)
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), "app-") {
+			names = append(names, entry.Name())
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// readFile returns the content of the file, decompressing it if it is compressed.
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	file, err := os.Open(path)
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, file.Close())
+	})
+	var reader io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gzipReader, err := gzip.NewReader(file)
+		assert.NoError(t, err)
+		reader = gzipReader
+	}
+	content, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	return string(content)
+}
+
+func TestRotatingFile(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when the options are invalid it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicExact(t, func() {
+			logger.WithMaxSize(0)
+		}, "the max file size 0 must be greater than zero")
+		assert.PanicExact(t, func() {
+			logger.WithRotationInterval(0)
+		}, "the rotation interval 0s must be greater than zero")
+		assert.PanicExact(t, func() {
+			logger.WithMaxAge(0)
+		}, "the max age 0s must be greater than zero")
+		assert.PanicExact(t, func() {
+			logger.WithMaxBackups(0)
+		}, "the max backups 0 must be greater than zero")
+		assert.PanicExact(t, func() {
+			logger.WithReopenOnSignal()
+		}, "the reopen signals cannot be empty")
+	})
+
+	t.Run("when the directory does not exist it should create it", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "logs", "app.log")
+		file, err := logger.NewRotatingFile(path)
+		assert.NoError(t, err)
+		_, err = file.Write([]byte("line\n"))
+		assert.NoError(t, err)
+		assert.NoError(t, file.Close())
+		assert.NoError(t, file.Close())
+		assert.Equals(t, readFile(t, path), "line\n")
+		_, err = file.Write([]byte("closed\n"))
+		assert.ErrorExact(t, err, logger.ErrFileClosed.Error())
+	})
+
+	t.Run("when the path cannot be opened it should return an error", func(t *testing.T) {
+		t.Parallel()
+		file, err := logger.NewRotatingFile(t.TempDir())
+		assert.ErrorPart(t, err, "failed to open the log file")
+		assert.Nil(t, file)
+	})
+
+	t.Run("when a write exceeds the max size it should rotate the file", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		path := filepath.Join(dir, "app.log")
+		file, err := logger.NewRotatingFile(path, logger.WithMaxSize(10))
+		assert.NoError(t, err)
+		_, err = file.Write([]byte("123456\n"))
+		assert.NoError(t, err)
+		_, err = file.Write([]byte("abcdef\n"))
+		assert.NoError(t, err)
+		assert.NoError(t, file.Close())
+		assert.Equals(t, readFile(t, path), "abcdef\n")
+		rotated := rotatedFileNames(t, dir)
+		assert.Equals(t, len(rotated), 1)
+		assert.True(t, strings.HasSuffix(rotated[0], ".log"))
+		assert.Equals(t, readFile(t, filepath.Join(dir, rotated[0])), "123456\n")
+	})
+
+	t.Run("when the rotation interval has passed it should rotate the file", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		file, err := logger.NewRotatingFile(filepath.Join(dir, "app.log"), logger.WithRotationInterval(time.Millisecond))
+		assert.NoError(t, err)
+		_, err = file.Write([]byte("first\n"))
+		assert.NoError(t, err)
+		time.Sleep(5 * time.Millisecond)
+		_, err = file.Write([]byte("second\n"))
+		assert.NoError(t, err)
+		assert.NoError(t, file.Close())
+		assert.True(t, len(rotatedFileNames(t, dir)) >= 1)
+	})
+
+	t.Run("when compression is enabled it should compress the rotated files", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		file, err := logger.NewRotatingFile(filepath.Join(dir, "app.log"), logger.WithCompression())
+		assert.NoError(t, err)
+		_, err = file.Write([]byte("compressed\n"))
+		assert.NoError(t, err)
+		assert.NoError(t, file.Rotate())
+		assert.NoError(t, file.Close())
+		rotated := rotatedFileNames(t, dir)
+		assert.Equals(t, len(rotated), 1)
+		assert.True(t, strings.HasSuffix(rotated[0], ".log.gz"))
+		assert.Equals(t, readFile(t, filepath.Join(dir, rotated[0])), "compressed\n")
+	})
+
+	t.Run("when there are more backups than the max it should remove the oldest", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		file, err := logger.NewRotatingFile(filepath.Join(dir, "app.log"), logger.WithMaxBackups(2))
+		assert.NoError(t, err)
+		for _, line := range []string{"1\n", "2\n", "3\n"} {
+			_, err = file.Write([]byte(line))
+			assert.NoError(t, err)
+			assert.NoError(t, file.Rotate())
+			time.Sleep(2 * time.Millisecond)
+		}
+		assert.NoError(t, file.Close())
+		rotated := rotatedFileNames(t, dir)
+		assert.Equals(t, len(rotated), 2)
+		assert.Equals(t, readFile(t, filepath.Join(dir, rotated[0])), "2\n")
+		assert.Equals(t, readFile(t, filepath.Join(dir, rotated[1])), "3\n")
+	})
+
+	t.Run("when the file is rotated many times in the same millisecond it should keep every backup", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		file, err := logger.NewRotatingFile(filepath.Join(dir, "app.log"), logger.WithMaxBackups(10))
+		assert.NoError(t, err)
+		lines := []string{"1\n", "2\n", "3\n", "4\n", "5\n"}
+		for _, line := range lines {
+			_, err = file.Write([]byte(line))
+			assert.NoError(t, err)
+			assert.NoError(t, file.Rotate())
+		}
+		assert.NoError(t, file.Close())
+		rotated := rotatedFileNames(t, dir)
+		assert.Equals(t, len(rotated), len(lines))
+		contents := make([]string, 0, len(rotated))
+		for _, name := range rotated {
+			contents = append(contents, readFile(t, filepath.Join(dir, name)))
+		}
+		slices.Sort(contents)
+		assert.Equals(t, contents, lines)
+	})
+
+	t.Run("when backups share a time it should remove the ones with the lowest counter first", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		for _, name := range []string{"app-2000-01-01T00-00-00.000.log", "app-2000-01-01T00-00-00.000-1.log", "app-2000-01-01T00-00-00.000-2.log"} {
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644))
+		}
+		file, err := logger.NewRotatingFile(filepath.Join(dir, "app.log"), logger.WithMaxBackups(3))
+		assert.NoError(t, err)
+		assert.NoError(t, file.Rotate())
+		assert.NoError(t, file.Close())
+		rotated := rotatedFileNames(t, dir)
+		assert.Equals(t, len(rotated), 3)
+		assert.SliceContains(t, rotated, "app-2000-01-01T00-00-00.000-1.log")
+		assert.SliceContains(t, rotated, "app-2000-01-01T00-00-00.000-2.log")
+	})
+
+	t.Run("when backups are older than the max age it should remove them", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		oldBackup := filepath.Join(dir, "app-2000-01-01T00-00-00.000.log.gz")
+		unrelated := filepath.Join(dir, "app-notes.log")
+		for _, path := range []string{oldBackup, unrelated} {
+			assert.NoError(t, os.WriteFile(path, []byte("old\n"), 0o644))
+		}
+		file, err := logger.NewRotatingFile(filepath.Join(dir, "app.log"), logger.WithMaxAge(time.Hour))
+		assert.NoError(t, err)
+		assert.NoError(t, file.Rotate())
+		assert.NoError(t, file.Close())
+		_, err = os.Stat(oldBackup)
+		assert.True(t, os.IsNotExist(err))
+		_, err = os.Stat(unrelated)
+		assert.NoError(t, err)
+		assert.Equals(t, len(rotatedFileNames(t, dir)), 2)
+	})
+
+	t.Run("when the file is moved and reopened it should write to a new file", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		path := filepath.Join(dir, "app.log")
+		file, err := logger.NewRotatingFile(path)
+		assert.NoError(t, err)
+		_, err = file.Write([]byte("before\n"))
+		assert.NoError(t, err)
+		assert.NoError(t, os.Rename(path, filepath.Join(dir, "moved.log")))
+		assert.NoError(t, file.Reopen())
+		_, err = file.Write([]byte("after\n"))
+		assert.NoError(t, err)
+		assert.NoError(t, file.Close())
+		assert.Equals(t, readFile(t, path), "after\n")
+		assert.Equals(t, readFile(t, filepath.Join(dir, "moved.log")), "before\n")
+		assert.ErrorExact(t, file.Reopen(), logger.ErrFileClosed.Error())
+		assert.ErrorExact(t, file.Rotate(), logger.ErrFileClosed.Error())
+	})
+
+	t.Run("when the file cannot be opened again it should fail the writes with the closed error", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		path := filepath.Join(dir, "app.log")
+		file, err := logger.NewRotatingFile(path)
+		assert.NoError(t, err)
+		assert.NoError(t, os.Rename(path, filepath.Join(dir, "moved.log")))
+		assert.NoError(t, os.Mkdir(path, 0o755))
+		assert.ErrorPart(t, file.Reopen(), "failed to open the log file")
+		_, err = file.Write([]byte("lost\n"))
+		assert.ErrorExact(t, err, logger.ErrFileClosed.Error())
+		assert.ErrorExact(t, file.Rotate(), logger.ErrFileClosed.Error())
+		assert.NoError(t, file.Close())
+	})
+}
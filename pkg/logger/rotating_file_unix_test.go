@@ -0,0 +1,37 @@
+//go:build unix
+
+package logger_test
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/logger"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestRotatingFileSignals(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when the reopen signal is received it should reopen the file", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		path := filepath.Join(dir, "app.log")
+		file, err := logger.NewRotatingFile(path, logger.WithReopenOnSignal(syscall.SIGUSR1))
+		assert.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, file.Close())
+		})
+		assert.NoError(t, os.Rename(path, filepath.Join(dir, "moved.log")))
+		assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+		for {
+			if _, err := os.Stat(path); err == nil {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+	})
+}
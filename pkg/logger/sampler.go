@@ -0,0 +1,263 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultSampleWindow is the window of the Sampler in which identical entries are collapsed.
+	DefaultSampleWindow = time.Second
+
+	// RepeatedLogField is the field of the entry written by the Sampler at the end of a window. It is the number
+	// of identical entries that were collapsed in the window after the first one was written.
+	RepeatedLogField = "repeated"
+
+	// DroppedLogField is the field of the entry written by the Sampler once the byte rate allows it again.
+	// It is the number of entries that were dropped because the byte rate was exceeded.
+	DroppedLogField = "dropped"
+)
+
+// samplerConfig is configured by the SamplerOption functions.
+type samplerConfig struct {
+	window            time.Duration
+	maxBytesPerSecond int
+}
+
+// SamplerOption is used to configure the Sampler.
+type SamplerOption func(cfg *samplerConfig)
+
+// WithSampleWindow sets the window in which identical entries are collapsed. The default is DefaultSampleWindow.
+// If the window is not positive, this function panics.
+func WithSampleWindow(window time.Duration) SamplerOption {
+	if window <= 0 {
+		panic(fmt.Sprintf("the sample window %s must be greater than zero", window))
+	}
+	return func(cfg *samplerConfig) {
+		cfg.window = window
+	}
+}
+
+// WithMaxBytesPerSecond caps the rate at which the Sampler writes entries. The size of an entry is the length of
+// its message and fields. It allows bursts of up to a second of bytes, and the entries above the cap are dropped.
+// There is no cap by default. If the cap is not positive, this function panics.
+func WithMaxBytesPerSecond(maxBytesPerSecond int) SamplerOption {
+	if maxBytesPerSecond <= 0 {
+		panic(fmt.Sprintf("the max bytes per second %d must be greater than zero", maxBytesPerSecond))
+	}
+	return func(cfg *samplerConfig) {
+		cfg.maxBytesPerSecond = maxBytesPerSecond
+	}
+}
+
+// sampleKey identifies identical entries.
+type sampleKey struct {
+	level LogLevel
+	msg   string
+}
+
+// sampledEntry is the state of the window of identical entries.
+type sampledEntry struct {
+	ctx       context.Context
+	fields    map[string]any
+	windowEnd time.Time
+	repeated  int
+}
+
+// pendingEntry is an entry to write to the next Backend once the lock of the Sampler is released.
+type pendingEntry struct {
+	ctx    context.Context
+	level  LogLevel
+	fields map[string]any
+	msg    string
+}
+
+// Sampler is a Backend that prevents log storms from saturating the I/O. The first of the identical entries,
+// those with the same level and message, is written and the others are collapsed until the end of the window.
+// At the end of the window, an entry with the RepeatedLogField is written if any were collapsed. The byte rate
+// of the written entries can also be capped with WithMaxBytesPerSecond.
+//
+// The Sampler runs a goroutine that writes the collapsed entries of the windows that ended, which is stopped
+// by Close.
+type Sampler struct {
+	next              Backend
+	window            time.Duration
+	maxBytesPerSecond float64
+
+	lock       sync.Mutex
+	entries    map[sampleKey]*sampledEntry
+	tokens     float64
+	lastRefill time.Time
+	dropped    int
+
+	closeOnce sync.Once
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// NewSampler creates a Sampler that writes the entries to the next Backend, like the one returned by GetBackend.
+// If the next Backend is nil, this function panics.
+func NewSampler(next Backend, opts ...SamplerOption) *Sampler {
+	if next == nil {
+		panic("the next backend cannot be nil")
+	}
+
+	cfg := &samplerConfig{
+		window:            DefaultSampleWindow,
+		maxBytesPerSecond: 0,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	sampler := &Sampler{
+		next:              next,
+		window:            cfg.window,
+		maxBytesPerSecond: float64(cfg.maxBytesPerSecond),
+		entries:           make(map[sampleKey]*sampledEntry),
+		tokens:            float64(cfg.maxBytesPerSecond),
+		lastRefill:        time.Now(),
+		stop:              make(chan struct{}),
+		done:              make(chan struct{}),
+	}
+	go sampler.run()
+	return sampler
+}
+
+// Write is the implementation of the Backend interface.
+func (s *Sampler) Write(ctx context.Context, level LogLevel, fields map[string]any, msg string) {
+	now := time.Now()
+	key := sampleKey{level: level, msg: msg}
+	pending := make([]pendingEntry, 0, 2)
+
+	s.lock.Lock()
+	if entry, found := s.entries[key]; found {
+		if now.Before(entry.windowEnd) {
+			entry.repeated++
+			s.lock.Unlock()
+			return
+		}
+		pending = s.appendRepeatedLocked(pending, key, entry)
+	}
+	s.entries[key] = &sampledEntry{
+		ctx:       ctx,
+		fields:    fields,
+		windowEnd: now.Add(s.window),
+		repeated:  0,
+	}
+	pending = append(pending, pendingEntry{ctx: ctx, level: level, fields: fields, msg: msg})
+	pending = s.limitLocked(pending, now)
+	s.lock.Unlock()
+
+	s.writeEntries(pending)
+}
+
+// Flush writes the collapsed entries of the windows that have ended.
+func (s *Sampler) Flush() {
+	s.flush(false)
+}
+
+// Close stops the goroutine of the Sampler and writes the collapsed entries of all the windows, even those that
+// have not ended. It is safe to call Close many times.
+func (s *Sampler) Close() {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+		<-s.done
+		s.flush(true)
+	})
+}
+
+// run flushes the Sampler at each window until it is closed.
+func (s *Sampler) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.window)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.Flush()
+		}
+	}
+}
+
+// flush forgets the windows that have ended, or all the windows if all is true, and writes their collapsed entries.
+func (s *Sampler) flush(all bool) {
+	now := time.Now()
+	pending := make([]pendingEntry, 0)
+
+	s.lock.Lock()
+	for key, entry := range s.entries {
+		if all || !now.Before(entry.windowEnd) {
+			pending = s.appendRepeatedLocked(pending, key, entry)
+		}
+	}
+	pending = s.limitLocked(pending, now)
+	s.lock.Unlock()
+
+	s.writeEntries(pending)
+}
+
+// appendRepeatedLocked forgets the window of the key and appends its RepeatedLogField entry if it collapsed entries.
+func (s *Sampler) appendRepeatedLocked(pending []pendingEntry, key sampleKey, entry *sampledEntry) []pendingEntry {
+	delete(s.entries, key)
+	if entry.repeated == 0 {
+		return pending
+	}
+	fields := make(map[string]any, len(entry.fields)+1)
+	maps.Copy(fields, entry.fields)
+	fields[RepeatedLogField] = entry.repeated
+	return append(pending, pendingEntry{ctx: entry.ctx, level: key.level, fields: fields, msg: key.msg})
+}
+
+// limitLocked removes the entries that exceed the byte rate. If entries were dropped and the rate allows entries
+// again, an entry with the DroppedLogField is written before them.
+func (s *Sampler) limitLocked(pending []pendingEntry, now time.Time) []pendingEntry {
+	if s.maxBytesPerSecond == 0 || len(pending) == 0 {
+		return pending
+	}
+
+	s.tokens = min(s.maxBytesPerSecond, s.tokens+now.Sub(s.lastRefill).Seconds()*s.maxBytesPerSecond)
+	s.lastRefill = now
+
+	allowed := make([]pendingEntry, 0, len(pending)+1)
+	for _, entry := range pending {
+		size := min(s.maxBytesPerSecond, float64(entrySize(entry.fields, entry.msg)))
+		if s.tokens < size {
+			s.dropped++
+			continue
+		}
+		s.tokens -= size
+		if s.dropped != 0 {
+			allowed = append(allowed, pendingEntry{
+				ctx:    entry.ctx,
+				level:  LevelWarn,
+				fields: map[string]any{DroppedLogField: s.dropped},
+				msg:    "Log entries were dropped because the maximum bytes per second was exceeded.",
+			})
+			s.dropped = 0
+		}
+		allowed = append(allowed, entry)
+	}
+	return allowed
+}
+
+// writeEntries writes the entries to the next Backend.
+func (s *Sampler) writeEntries(pending []pendingEntry) {
+	for _, entry := range pending {
+		s.next.Write(entry.ctx, entry.level, entry.fields, entry.msg)
+	}
+}
+
+// entrySize estimates the number of bytes of an entry.
+func entrySize(fields map[string]any, msg string) int {
+	size := len(msg)
+	for key, value := range fields {
+		size += len(key) + len(fmt.Sprint(value)) + 2
+	}
+	return size
+}
@@ -0,0 +1,101 @@
+package logger_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/logger"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestSampler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when the options are invalid it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicExact(t, func() {
+			logger.WithSampleWindow(0)
+		}, "the sample window 0s must be greater than zero")
+		assert.PanicExact(t, func() {
+			logger.WithMaxBytesPerSecond(0)
+		}, "the max bytes per second 0 must be greater than zero")
+		assert.PanicExact(t, func() {
+			logger.NewSampler(nil)
+		}, "the next backend cannot be nil")
+	})
+
+	t.Run("when identical entries are written in a window it should collapse them", func(t *testing.T) {
+		t.Parallel()
+		backend := &recordingBackend{}
+		sampler := logger.NewSampler(backend, logger.WithSampleWindow(time.Hour))
+		for i := 0; i < 5; i++ {
+			sampler.Write(context.Background(), logger.LevelError, map[string]any{"key": "value"}, "failed")
+		}
+		sampler.Write(context.Background(), logger.LevelWarn, nil, "failed")
+		sampler.Write(context.Background(), logger.LevelError, nil, "other")
+		sampler.Close()
+		sampler.Close()
+		entries := backend.recorded()
+		assert.Equals(t, entries[:3], []recordedEntry{
+			{level: logger.LevelError, fields: map[string]any{"key": "value"}, msg: "failed"},
+			{level: logger.LevelWarn, fields: nil, msg: "failed"},
+			{level: logger.LevelError, fields: nil, msg: "other"},
+		})
+		assert.Equals(t, entries[3:], []recordedEntry{
+			{level: logger.LevelError, fields: map[string]any{"key": "value", logger.RepeatedLogField: 4}, msg: "failed"},
+		})
+	})
+
+	t.Run("when a window ends it should write the number of collapsed entries", func(t *testing.T) {
+		t.Parallel()
+		backend := &recordingBackend{}
+		sampler := logger.NewSampler(backend, logger.WithSampleWindow(10*time.Millisecond))
+		t.Cleanup(sampler.Close)
+		sampler.Write(context.Background(), logger.LevelInfo, nil, "message")
+		sampler.Write(context.Background(), logger.LevelInfo, nil, "message")
+		for len(backend.recorded()) != 2 {
+			time.Sleep(time.Millisecond)
+		}
+		sampler.Write(context.Background(), logger.LevelInfo, nil, "message")
+		assert.Equals(t, backend.recorded(), []recordedEntry{
+			{level: logger.LevelInfo, fields: nil, msg: "message"},
+			{level: logger.LevelInfo, fields: map[string]any{logger.RepeatedLogField: 1}, msg: "message"},
+			{level: logger.LevelInfo, fields: nil, msg: "message"},
+		})
+	})
+
+	t.Run("when an entry is written after its window it should write the collapsed entries before it", func(t *testing.T) {
+		t.Parallel()
+		backend := &recordingBackend{}
+		sampler := logger.NewSampler(backend, logger.WithSampleWindow(time.Millisecond))
+		sampler.Close()
+		sampler.Write(context.Background(), logger.LevelInfo, nil, "message")
+		sampler.Write(context.Background(), logger.LevelInfo, nil, "message")
+		time.Sleep(5 * time.Millisecond)
+		sampler.Write(context.Background(), logger.LevelInfo, nil, "message")
+		assert.Equals(t, backend.recorded(), []recordedEntry{
+			{level: logger.LevelInfo, fields: nil, msg: "message"},
+			{level: logger.LevelInfo, fields: map[string]any{logger.RepeatedLogField: 1}, msg: "message"},
+			{level: logger.LevelInfo, fields: nil, msg: "message"},
+		})
+	})
+
+	t.Run("when the byte rate is exceeded it should drop the entries and report them", func(t *testing.T) {
+		t.Parallel()
+		backend := &recordingBackend{}
+		sampler := logger.NewSampler(backend, logger.WithSampleWindow(time.Hour), logger.WithMaxBytesPerSecond(1000))
+		t.Cleanup(sampler.Close)
+		sampler.Write(context.Background(), logger.LevelInfo, nil, string(make([]byte, 600)))
+		sampler.Write(context.Background(), logger.LevelInfo, nil, string(make([]byte, 500)))
+		sampler.Write(context.Background(), logger.LevelInfo, nil, string(make([]byte, 550)))
+		assert.Equals(t, len(backend.recorded()), 1)
+		time.Sleep(100 * time.Millisecond)
+		sampler.Write(context.Background(), logger.LevelInfo, map[string]any{"key": "value"}, "small")
+		entries := backend.recorded()
+		assert.Equals(t, entries[1:], []recordedEntry{
+			{level: logger.LevelWarn, fields: map[string]any{logger.DroppedLogField: 2}, msg: "Log entries were dropped because the maximum bytes per second was exceeded."},
+			{level: logger.LevelInfo, fields: map[string]any{"key": "value"}, msg: "small"},
+		})
+	})
+}
@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+)
+
+const (
+	// SlogLevelTrace is the slog level of the entries logged at LevelTrace. It is below slog.LevelDebug.
+	SlogLevelTrace = slog.LevelDebug - 4
+)
+
+// slogBackend is a Backend that writes the entries to a slog.Logger.
+type slogBackend struct {
+	logger *slog.Logger
+}
+
+// NewSlogBackend creates a Backend that writes the entries to the slog.Logger, with the fields as attributes.
+// If the logger is nil, this function panics.
+func NewSlogBackend(logger *slog.Logger) Backend {
+	if logger == nil {
+		panic("the slog logger cannot be nil")
+	}
+	return &slogBackend{
+		logger: logger,
+	}
+}
+
+// Write is the implementation of the Backend interface.
+func (b *slogBackend) Write(ctx context.Context, level LogLevel, fields map[string]any, msg string) {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	attrs := make([]slog.Attr, 0, len(keys))
+	for _, key := range keys {
+		attrs = append(attrs, slog.Any(key, fields[key]))
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	b.logger.LogAttrs(ctx, SlogLevel(level), msg, attrs...)
+}
+
+// SlogLevel converts a LogLevel to its slog level.
+func SlogLevel(level LogLevel) slog.Level {
+	switch level {
+	case LevelError:
+		return slog.LevelError
+	case LevelWarn:
+		return slog.LevelWarn
+	case LevelInfo:
+		return slog.LevelInfo
+	case LevelDebug:
+		return slog.LevelDebug
+	default:
+		return SlogLevelTrace
+	}
+}
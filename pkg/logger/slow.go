@@ -0,0 +1,20 @@
+package logger
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// slowOperationThreshold is how long an instrumented operation can take before it is logged as slow.
+var slowOperationThreshold atomic.Int64
+
+// SetSlowOperationThreshold sets how long an instrumented operation can take before it is logged as slow.
+// A threshold of zero, which is the default, disables the slow operation logs.
+func SetSlowOperationThreshold(threshold time.Duration) {
+	slowOperationThreshold.Store(int64(threshold))
+}
+
+// GetSlowOperationThreshold returns how long an instrumented operation can take before it is logged as slow.
+func GetSlowOperationThreshold() time.Duration {
+	return time.Duration(slowOperationThreshold.Load())
+}
@@ -0,0 +1,18 @@
+package logger_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/logger"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func TestSlowOperationThreshold(t *testing.T) {
+	t.Cleanup(func() {
+		logger.SetSlowOperationThreshold(0)
+	})
+	assert.Equals(t, logger.GetSlowOperationThreshold(), time.Duration(0))
+	logger.SetSlowOperationThreshold(time.Millisecond * 250)
+	assert.Equals(t, logger.GetSlowOperationThreshold(), time.Millisecond*250)
+}
@@ -513,6 +513,292 @@ func TestAssertFunctions(t *testing.T) {
 					"within a margin of 0.",
 				},
 			},
+			{
+				name: "Len positive case - slice of 3",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.Len(tr, []int{1, 2, 3}, 3, opts...)
+				},
+				expectLogs: []string{},
+			},
+			{
+				name: "Len positive case - string",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.Len(tr, "abc", 3, opts...)
+				},
+				expectLogs: []string{},
+			},
+			{
+				name: "Len positive case - map",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.Len(tr, map[string]int{"a": 1}, 1, opts...)
+				},
+				expectLogs: []string{},
+			},
+			{
+				name: "Len negative case - slice of 3 and 2",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.Len(tr, []int{1, 2, 3}, 2, opts...)
+				},
+				expectLogs: []string{
+					"Expected [1 2 3] to have a length of 2 but it has a length of 3.",
+				},
+			},
+			{
+				name: "Len negative case - int",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.Len(tr, 1, 1, opts...)
+				},
+				expectLogs: []string{
+					"Unknown type for the length check.",
+				},
+			},
+			{
+				name: "Len negative case - nil",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.Len(tr, nil, 0, opts...)
+				},
+				expectLogs: []string{
+					"Unknown type for the length check.",
+				},
+			},
+			{
+				name: "Empty positive case - nil",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.Empty(tr, nil, opts...)
+				},
+				expectLogs: []string{},
+			},
+			{
+				name: "Empty positive case - empty slice",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.Empty(tr, []int{}, opts...)
+				},
+				expectLogs: []string{},
+			},
+			{
+				name: "Empty positive case - empty string",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.Empty(tr, "", opts...)
+				},
+				expectLogs: []string{},
+			},
+			{
+				name: "Empty positive case - zero struct",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.Empty(tr, struct{ Value int }{}, opts...)
+				},
+				expectLogs: []string{},
+			},
+			{
+				name: "Empty negative case - map with a key",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.Empty(tr, map[string]int{"a": 1}, opts...)
+				},
+				expectLogs: []string{
+					"Expecting map[a:1] to be empty.",
+				},
+			},
+			{
+				name: "Empty negative case - non-zero int",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.Empty(tr, 1, opts...)
+				},
+				expectLogs: []string{
+					"Expecting 1 to be empty.",
+				},
+			},
+			{
+				name: "NotEmpty positive case - slice",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.NotEmpty(tr, []int{1}, opts...)
+				},
+				expectLogs: []string{},
+			},
+			{
+				name: "NotEmpty positive case - non-zero int",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.NotEmpty(tr, 1, opts...)
+				},
+				expectLogs: []string{},
+			},
+			{
+				name: "NotEmpty negative case - nil slice",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.NotEmpty(tr, []int(nil), opts...)
+				},
+				expectLogs: []string{
+					"Expecting the value to not be empty.",
+				},
+			},
+			{
+				name: "NotEmpty negative case - empty string",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.NotEmpty(tr, "", opts...)
+				},
+				expectLogs: []string{
+					"Expecting the value to not be empty.",
+				},
+			},
+			{
+				name: "SliceContains positive case - int",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.SliceContains(tr, []int{1, 2, 3}, 2, opts...)
+				},
+				expectLogs: []string{},
+			},
+			{
+				name: "SliceContains positive case - slice element",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.SliceContains(tr, [][]int{{1}, {2, 3}}, []int{2, 3}, opts...)
+				},
+				expectLogs: []string{},
+			},
+			{
+				name: "SliceContains negative case - missing int",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.SliceContains(tr, []int{1, 2, 3}, 4, opts...)
+				},
+				expectLogs: []string{
+					"Expecting [1 2 3] to contain 4.",
+				},
+			},
+			{
+				name: "SliceContains negative case - nil slice",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.SliceContains(tr, nil, "a", opts...)
+				},
+				expectLogs: []string{
+					"Expecting [] to contain a.",
+				},
+			},
+			{
+				name: "MapContainsKey positive case",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.MapContainsKey(tr, map[string]int{"a": 1}, "a", opts...)
+				},
+				expectLogs: []string{},
+			},
+			{
+				name: "MapContainsKey negative case",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.MapContainsKey(tr, map[string]int{"a": 1}, "b", opts...)
+				},
+				expectLogs: []string{
+					"Expecting map[a:1] to contain the key b.",
+				},
+			},
+			{
+				name: "Subset positive case",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.Subset(tr, []int{1, 2, 3}, []int{3, 1}, opts...)
+				},
+				expectLogs: []string{},
+			},
+			{
+				name: "Subset positive case - empty subset",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.Subset(tr, []int{1, 2, 3}, nil, opts...)
+				},
+				expectLogs: []string{},
+			},
+			{
+				name: "Subset negative case",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.Subset(tr, []int{1, 2, 3}, []int{1, 4}, opts...)
+				},
+				expectLogs: []string{
+					"Expecting [1 4] to be a subset of [1 2 3] but 4 is missing.",
+				},
+			},
+			{
+				name: "ElementsMatch positive case - different order",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.ElementsMatch(tr, []int{1, 2, 2, 3}, []int{2, 3, 1, 2}, opts...)
+				},
+				expectLogs: []string{},
+			},
+			{
+				name: "ElementsMatch positive case - empty and nil",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.ElementsMatch(tr, []string{}, nil, opts...)
+				},
+				expectLogs: []string{},
+			},
+			{
+				name: "ElementsMatch negative case - different counts",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.ElementsMatch(tr, []int{1, 1, 2}, []int{1, 2, 2}, opts...)
+				},
+				expectLogs: []string{
+					"Expected [1 1 2] to have the same elements as [1 2 2].",
+				},
+			},
+			{
+				name: "ElementsMatch negative case - different lengths",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.ElementsMatch(tr, []int{1, 2}, []int{1, 2, 3}, opts...)
+				},
+				expectLogs: []string{
+					"Expected [1 2] to have the same elements as [1 2 3].",
+				},
+			},
+			{
+				name: "HasPrefix positive case",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.HasPrefix(tr, "test string", "test", opts...)
+				},
+				expectLogs: []string{},
+			},
+			{
+				name: "HasPrefix negative case",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.HasPrefix(tr, "test string", "string", opts...)
+				},
+				expectLogs: []string{
+					"Expecting 'test string' to have the prefix 'string'.",
+				},
+			},
+			{
+				name: "HasSuffix positive case",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.HasSuffix(tr, "test string", "string", opts...)
+				},
+				expectLogs: []string{},
+			},
+			{
+				name: "HasSuffix negative case",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.HasSuffix(tr, "test string", "test", opts...)
+				},
+				expectLogs: []string{
+					"Expecting 'test string' to have the suffix 'test'.",
+				},
+			},
+			{
+				name: "MatchRegex positive case",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.MatchRegex(tr, "request 123", `\d+$`, opts...)
+				},
+				expectLogs: []string{},
+			},
+			{
+				name: "MatchRegex negative case",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.MatchRegex(tr, "request", `^\d+$`, opts...)
+				},
+				expectLogs: []string{
+					"Expecting 'request' to match the regular expression '^\\d+$'.",
+				},
+			},
+			{
+				name: "MatchRegex negative case - invalid expression",
+				callback: func(tr *testRecorder, opts ...assert.Option) {
+					assert.MatchRegex(tr, "request", "(", opts...)
+				},
+				expectLogs: []string{
+					"Invalid regular expression '(' (error parsing regexp: missing closing ): `(`).",
+				},
+			},
 		}
 		for _, testCase := range testCases {
 			t.Run(testCase.name, func(t *testing.T) {
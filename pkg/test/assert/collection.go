@@ -0,0 +1,125 @@
+package assert
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// length returns the length of an array, channel, map, slice or string, and false for other types.
+func length(value any) (int, bool) {
+	if value == nil {
+		return 0, false
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Array, reflect.Chan, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len(), true
+	default:
+		return 0, false
+	}
+}
+
+// isEmpty checks if a value is nil, has a length of zero, or is the zero value of its type.
+func isEmpty(value any) bool {
+	if isNil(value) {
+		return true
+	}
+	if valueLen, hasLen := length(value); hasLen {
+		return valueLen == 0
+	}
+	return reflect.ValueOf(value).IsZero()
+}
+
+// Len checks if an array, channel, map, slice or string has a length.
+func Len(t Testing, value any, expectedLen int, options ...Option) {
+	tCtx := newTestContext(t, options...)
+	tCtx.Helper()
+	valueLen, hasLen := length(value)
+	if !hasLen {
+		tCtx.fail("Unknown type for the length check.")
+		return
+	}
+	if valueLen != expectedLen {
+		tCtx.fail(fmt.Sprintf("Expected %+v to have a length of %d but it has a length of %d.", value, expectedLen, valueLen))
+	}
+}
+
+// Empty checks if a value is nil, has a length of zero, or is the zero value of its type.
+func Empty(t Testing, value any, options ...Option) {
+	tCtx := newTestContext(t, options...)
+	tCtx.Helper()
+	if !isEmpty(value) {
+		tCtx.fail(fmt.Sprintf("Expecting %+v to be empty.", value))
+	}
+}
+
+// NotEmpty checks if a value is not nil, does not have a length of zero, and is not the zero value of its type.
+func NotEmpty(t Testing, value any, options ...Option) {
+	tCtx := newTestContext(t, options...)
+	tCtx.Helper()
+	if isEmpty(value) {
+		tCtx.fail("Expecting the value to not be empty.")
+	}
+}
+
+// SliceContains checks if a slice has an element equal to another.
+func SliceContains[T any](t Testing, slice []T, element T, options ...Option) {
+	tCtx := newTestContext(t, options...)
+	tCtx.Helper()
+	if !slices.ContainsFunc(slice, func(candidate T) bool { return reflect.DeepEqual(candidate, element) }) {
+		tCtx.fail(fmt.Sprintf("Expecting %+v to contain %+v.", slice, element))
+	}
+}
+
+// MapContainsKey checks if a map has a key.
+func MapContainsKey[K comparable, V any](t Testing, m map[K]V, key K, options ...Option) {
+	tCtx := newTestContext(t, options...)
+	tCtx.Helper()
+	if _, found := m[key]; !found {
+		tCtx.fail(fmt.Sprintf("Expecting %+v to contain the key %+v.", m, key))
+	}
+}
+
+// Subset checks if every element of the subset is equal to an element of the slice.
+func Subset[T any](t Testing, slice []T, subset []T, options ...Option) {
+	tCtx := newTestContext(t, options...)
+	tCtx.Helper()
+	for _, element := range subset {
+		if !slices.ContainsFunc(slice, func(candidate T) bool { return reflect.DeepEqual(candidate, element) }) {
+			tCtx.fail(fmt.Sprintf("Expecting %+v to be a subset of %+v but %+v is missing.", subset, slice, element))
+			return
+		}
+	}
+}
+
+// elementsMatch checks if two slices have equal elements, the same number of times, regardless of their order.
+func elementsMatch[T any](actual []T, expected []T) bool {
+	if len(actual) != len(expected) {
+		return false
+	}
+	matched := make([]bool, len(expected))
+	for _, element := range actual {
+		found := false
+		for i, candidate := range expected {
+			if !matched[i] && reflect.DeepEqual(candidate, element) {
+				matched[i] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// ElementsMatch checks if two slices have equal elements, the same number of times, regardless of their order.
+func ElementsMatch[T any](t Testing, actual []T, expected []T, options ...Option) {
+	tCtx := newTestContext(t, options...)
+	tCtx.Helper()
+	if !elementsMatch(actual, expected) {
+		tCtx.fail(fmt.Sprintf("Expected %+v to have the same elements as %+v.", actual, expected))
+	}
+}
@@ -0,0 +1,40 @@
+package assert
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// HasPrefix checks if a string starts with a prefix.
+func HasPrefix(t Testing, value string, prefix string, options ...Option) {
+	tCtx := newTestContext(t, options...)
+	tCtx.Helper()
+	if !strings.HasPrefix(value, prefix) {
+		tCtx.fail(fmt.Sprintf("Expecting '%s' to have the prefix '%s'.", value, prefix))
+	}
+}
+
+// HasSuffix checks if a string ends with a suffix.
+func HasSuffix(t Testing, value string, suffix string, options ...Option) {
+	tCtx := newTestContext(t, options...)
+	tCtx.Helper()
+	if !strings.HasSuffix(value, suffix) {
+		tCtx.fail(fmt.Sprintf("Expecting '%s' to have the suffix '%s'.", value, suffix))
+	}
+}
+
+// MatchRegex checks if a string matches a regular expression. The expression is not anchored, so it matches
+// if any part of the string matches.
+func MatchRegex(t Testing, value string, pattern string, options ...Option) {
+	tCtx := newTestContext(t, options...)
+	tCtx.Helper()
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		tCtx.fail(fmt.Sprintf("Invalid regular expression '%s' (%s).", pattern, err.Error()))
+		return
+	}
+	if !regex.MatchString(value) {
+		tCtx.fail(fmt.Sprintf("Expecting '%s' to match the regular expression '%s'.", value, pattern))
+	}
+}
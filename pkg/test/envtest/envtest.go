@@ -0,0 +1,72 @@
+package envtest
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Testing matches the functions on the testing.T struct that are needed by this package.
+type Testing interface {
+	Helper()
+	Setenv(key string, value string)
+	Fatal(...any)
+}
+
+// Snapshot captures the current environment variables and returns a function that restores them.
+// Variables set after the snapshot are removed and variables that were modified or removed are reset.
+// The variables that did not change are left untouched.
+func Snapshot() func() {
+	snapshot := environ()
+	return func() {
+		for name := range environ() {
+			if _, found := snapshot[name]; !found {
+				if err := os.Unsetenv(name); err != nil {
+					panic(fmt.Sprintf("failed to remove the environment variable %s (%s)", name, err.Error()))
+				}
+			}
+		}
+		for name, value := range snapshot {
+			if current, found := os.LookupEnv(name); found && current == value {
+				continue
+			}
+			if err := os.Setenv(name, value); err != nil {
+				panic(fmt.Sprintf("failed to restore the environment variable %s (%s)", name, err.Error()))
+			}
+		}
+	}
+}
+
+// environ returns the environment variables by name.
+func environ() map[string]string {
+	entries := os.Environ()
+	variables := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		name, value, _ := strings.Cut(entry, "=")
+		variables[name] = value
+	}
+	return variables
+}
+
+// Set sets the environment variables for the duration of the test with t.Setenv.
+// The variables are restored to their state prior to the call when the test and its subtests complete.
+// Like t.Setenv, this panics if it is used in parallel tests or tests with parallel ancestors.
+func Set(t Testing, envs map[string]string) {
+	t.Helper()
+	for name, value := range envs {
+		t.Setenv(name, value)
+	}
+}
+
+// Unset removes the environment variables for the duration of the test.
+// The variables are restored to their state prior to the call when the test and its subtests complete.
+// Like t.Setenv, this panics if it is used in parallel tests or tests with parallel ancestors.
+func Unset(t Testing, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		t.Setenv(name, "")
+		if err := os.Unsetenv(name); err != nil {
+			t.Fatal(fmt.Sprintf("Failed to unset the environment variable %s (%s).", name, err.Error()))
+		}
+	}
+}
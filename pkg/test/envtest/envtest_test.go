@@ -0,0 +1,89 @@
+package envtest_test
+
+import (
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+	"github.com/TriangleSide/GoBase/pkg/test/envtest"
+)
+
+func TestEnvTest(t *testing.T) {
+	const (
+		existingName  = "ENVTEST_EXISTING"
+		existingValue = "existing"
+		addedName     = "ENVTEST_ADDED"
+	)
+
+	t.Run("when the snapshot is restored it should fully restore the environment", func(t *testing.T) {
+		t.Setenv(existingName, existingValue)
+		before := os.Environ()
+		restore := envtest.Snapshot()
+
+		assert.NoError(t, os.Setenv(addedName, "added"))
+		assert.NoError(t, os.Setenv(existingName, "modified"))
+		assert.NoError(t, os.Unsetenv("PATH"))
+
+		restore()
+
+		after := os.Environ()
+		slices.Sort(before)
+		slices.Sort(after)
+		assert.Equals(t, after, before)
+		_, addedFound := os.LookupEnv(addedName)
+		assert.False(t, addedFound)
+		assert.Equals(t, os.Getenv(existingName), existingValue)
+	})
+
+	t.Run("when variables are set for a test they should be restored when the test completes", func(t *testing.T) {
+		t.Setenv(existingName, existingValue)
+		t.Run("set", func(t *testing.T) {
+			envtest.Set(t, map[string]string{
+				existingName: "modified",
+				addedName:    "added",
+			})
+			assert.Equals(t, os.Getenv(existingName), "modified")
+			assert.Equals(t, os.Getenv(addedName), "added")
+		})
+		assert.Equals(t, os.Getenv(existingName), existingValue)
+		_, addedFound := os.LookupEnv(addedName)
+		assert.False(t, addedFound)
+	})
+
+	t.Run("when variables are unset for a test they should be restored when the test completes", func(t *testing.T) {
+		t.Setenv(existingName, existingValue)
+		t.Run("unset", func(t *testing.T) {
+			envtest.Unset(t, existingName)
+			_, found := os.LookupEnv(existingName)
+			assert.False(t, found)
+		})
+		assert.Equals(t, os.Getenv(existingName), existingValue)
+	})
+
+	t.Run("when the snapshot is restored it should leave the unchanged variables untouched", func(t *testing.T) {
+		t.Setenv(existingName, existingValue)
+		restore := envtest.Snapshot()
+		assert.NoError(t, os.Setenv(addedName, "added"))
+		restore()
+		assert.Equals(t, os.Getenv(existingName), existingValue)
+		_, addedFound := os.LookupEnv(addedName)
+		assert.False(t, addedFound)
+	})
+}
+
+func TestEnvTestParallel(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when variables are set in a parallel test it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			envtest.Set(t, map[string]string{"ENVTEST_ADDED": "added"})
+		}, "t.Setenv")
+		assert.PanicPart(t, func() {
+			envtest.Unset(t, "ENVTEST_EXISTING")
+		}, "t.Setenv")
+		_, addedFound := os.LookupEnv("ENVTEST_ADDED")
+		assert.False(t, addedFound)
+	})
+}
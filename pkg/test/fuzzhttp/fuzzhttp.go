@@ -0,0 +1,483 @@
+package fuzzhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"iter"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/TriangleSide/GoBase/pkg/http/headers"
+	"github.com/TriangleSide/GoBase/pkg/http/parameters"
+	"github.com/TriangleSide/GoBase/pkg/utils/fields"
+)
+
+const (
+	// defaultIterations is the amount of inputs generated when WithIterations is not used.
+	defaultIterations = 100
+
+	// defaultMaxStringLength is the maximum length of generated strings without a max or len validation rule.
+	defaultMaxStringLength = 16
+
+	// defaultNumberSpread is the range of generated numbers without a min and max validation rule.
+	defaultNumberSpread = 1000
+
+	// maxShrinkAttempts caps the amount of times the handler is invoked while shrinking a failing input.
+	maxShrinkAttempts = 1000
+
+	// stringAlphabet contains the characters used for generated strings.
+	// They are safe to use in headers, query parameters, and path parameters.
+	stringAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+)
+
+// Testing matches the functions on the testing.T struct that are needed by this package.
+type Testing interface {
+	Helper()
+	Fatalf(format string, args ...any)
+}
+
+// config is configured by the Option functions.
+type config struct {
+	iterations int
+	seed       uint64
+	method     string
+	target     string
+}
+
+// Option is used to configure a fuzzing run.
+type Option func(cfg *config)
+
+// WithIterations sets the amount of randomized inputs sent to the handler.
+func WithIterations(iterations int) Option {
+	return func(cfg *config) {
+		cfg.iterations = iterations
+	}
+}
+
+// WithSeed sets the seed of the random generator. It is used to reproduce a failure.
+func WithSeed(seed uint64) Option {
+	return func(cfg *config) {
+		cfg.seed = seed
+	}
+}
+
+// WithMethod sets the HTTP method of the generated requests. The default is POST.
+func WithMethod(method string) Option {
+	return func(cfg *config) {
+		cfg.method = method
+	}
+}
+
+// WithTarget sets the request target of the generated requests. The default is /.
+func WithTarget(target string) Option {
+	return func(cfg *config) {
+		cfg.target = target
+	}
+}
+
+// Run generates randomized instances of the parameter struct T and sends them to the handler.
+// The values respect the field types and the common validate rules (required, oneof, len, min, max, gt, gte, lt, lte).
+// Fields are encoded into the request according to their parameter tags (urlQuery, httpHeader, httpCookie, urlPath and json).
+// The handler must never panic and must always respond with a valid HTTP status. When it fails,
+// the input is shrunk to a smaller failing input and the test fails with it and the seed used.
+func Run[T any](t Testing, handler http.Handler, opts ...Option) {
+	t.Helper()
+
+	cfg := &config{
+		iterations: defaultIterations,
+		seed:       rand.Uint64(),
+		method:     http.MethodPost,
+		target:     "/",
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if reflect.TypeFor[T]().Kind() != reflect.Struct {
+		panic("the generic must be a struct")
+	}
+	if cfg.iterations <= 0 {
+		panic("the iterations must be greater than zero")
+	}
+
+	random := rand.New(rand.NewPCG(cfg.seed, cfg.seed))
+	for iteration := 0; iteration < cfg.iterations; iteration++ {
+		params := new(T)
+		for fieldName, fieldMetadata := range sortedFields[T]() {
+			fieldValue := fieldByName(params, fieldName, fieldMetadata)
+			generateValue(random, fieldValue, parseRules(fieldMetadata.Tags["validate"]))
+		}
+		if failure := serve(cfg, handler, params); failure != "" {
+			shrunk, shrunkFailure := shrink(cfg, handler, params, failure)
+			t.Fatalf("the handler failed with the seed %d on the input %s (%s)", cfg.seed, describe(shrunk), shrunkFailure)
+			return
+		}
+	}
+}
+
+// rules are the validation rules that constrain the generated values.
+// For strings, the min and max are the bounds of the length.
+type rules struct {
+	required bool
+	oneOf    []string
+	min      *float64
+	max      *float64
+}
+
+// parseRules extracts the rules of a validate tag that are used when generating values.
+// Rules that are not understood are ignored.
+func parseRules(validateTag string) rules {
+	parsed := rules{}
+	setMin := func(value float64) {
+		if parsed.min == nil || value > *parsed.min {
+			parsed.min = &value
+		}
+	}
+	setMax := func(value float64) {
+		if parsed.max == nil || value < *parsed.max {
+			parsed.max = &value
+		}
+	}
+	for _, rule := range strings.Split(validateTag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		if name == "dive" {
+			break
+		}
+		switch name {
+		case "required":
+			parsed.required = true
+			continue
+		case "oneof":
+			parsed.oneOf = strings.Fields(param)
+			continue
+		}
+		value, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			continue
+		}
+		switch name {
+		case "len":
+			setMin(value)
+			setMax(value)
+		case "min", "gte":
+			setMin(value)
+		case "gt":
+			setMin(math.Nextafter(value, math.Inf(1)))
+		case "max", "lte":
+			setMax(value)
+		case "lt":
+			setMax(math.Nextafter(value, math.Inf(-1)))
+		}
+	}
+	return parsed
+}
+
+// bounds returns the inclusive range of a generated number. The range is clamped to the lower and upper limits.
+func (r rules) bounds(lowerLimit float64, upperLimit float64) (float64, float64) {
+	low, high := -float64(defaultNumberSpread), float64(defaultNumberSpread)
+	switch {
+	case r.min != nil && r.max != nil:
+		low, high = *r.min, *r.max
+	case r.min != nil:
+		low, high = *r.min, *r.min+defaultNumberSpread
+	case r.max != nil:
+		low, high = *r.max-defaultNumberSpread, *r.max
+	}
+	return math.Max(low, lowerLimit), math.Min(high, upperLimit)
+}
+
+// sortedFields iterates over the exported fields of the struct ordered by field name.
+// The order must be stable so that a seed always generates the same inputs.
+func sortedFields[T any]() iter.Seq2[string, *fields.FieldMetadata] {
+	metadata := fields.StructMetadata[T]()
+	fieldNames := metadata.Keys()
+	slices.Sort(fieldNames)
+	return func(yield func(string, *fields.FieldMetadata) bool) {
+		for _, fieldName := range fieldNames {
+			if !token.IsExported(fieldName) {
+				continue
+			}
+			if !yield(fieldName, metadata.Get(fieldName)) {
+				return
+			}
+		}
+	}
+}
+
+// fieldByName returns the settable value of a field. This accounts for fields in embedded anonymous structs.
+func fieldByName[T any](params *T, fieldName string, fieldMetadata *fields.FieldMetadata) reflect.Value {
+	structValue := reflect.ValueOf(params).Elem()
+	for _, anonymousName := range fieldMetadata.Anonymous {
+		structValue = structValue.FieldByName(anonymousName)
+	}
+	return structValue.FieldByName(fieldName)
+}
+
+// generateValue sets a random value that satisfies the rules into the field.
+// Fields of kinds that are not supported are left with their zero value.
+func generateValue(random *rand.Rand, fieldValue reflect.Value, fieldRules rules) {
+	if fieldValue.Kind() == reflect.Ptr {
+		if !fieldRules.required && random.IntN(4) == 0 {
+			fieldValue.SetZero()
+			return
+		}
+		allocated := reflect.New(fieldValue.Type().Elem())
+		generateValue(random, allocated.Elem(), fieldRules)
+		fieldValue.Set(allocated)
+		return
+	}
+
+	if len(fieldRules.oneOf) > 0 && fieldValue.Kind() != reflect.Bool {
+		if err := setFromString(fieldValue, fieldRules.oneOf[random.IntN(len(fieldRules.oneOf))]); err == nil {
+			return
+		}
+	}
+
+	switch fieldValue.Kind() {
+	case reflect.String:
+		minLength, maxLength := 0, defaultMaxStringLength
+		if fieldRules.min != nil {
+			minLength = int(math.Ceil(*fieldRules.min))
+			maxLength = minLength + defaultMaxStringLength
+		}
+		if fieldRules.max != nil {
+			maxLength = int(math.Floor(*fieldRules.max))
+		}
+		if fieldRules.required && minLength == 0 {
+			minLength = 1
+		}
+		length := minLength
+		if maxLength > minLength {
+			length += random.IntN(maxLength - minLength + 1)
+		}
+		generated := make([]byte, length)
+		for i := range generated {
+			generated[i] = stringAlphabet[random.IntN(len(stringAlphabet))]
+		}
+		fieldValue.SetString(string(generated))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		bits := fieldValue.Type().Bits()
+		low, high := fieldRules.bounds(-math.Exp2(float64(bits-1)), math.Exp2(float64(bits-1))-1)
+		generated := int64(math.Ceil(low)) + random.Int64N(int64(math.Floor(high))-int64(math.Ceil(low))+1)
+		if generated == 0 && fieldRules.required {
+			generated = int64(math.Floor(high))
+		}
+		fieldValue.SetInt(generated)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		low, high := fieldRules.bounds(0, math.Exp2(float64(fieldValue.Type().Bits()))-1)
+		generated := uint64(math.Ceil(low)) + random.Uint64N(uint64(math.Floor(high))-uint64(math.Ceil(low))+1)
+		if generated == 0 && fieldRules.required {
+			generated = uint64(math.Floor(high))
+		}
+		fieldValue.SetUint(generated)
+	case reflect.Float32, reflect.Float64:
+		low, high := fieldRules.bounds(-math.MaxFloat32, math.MaxFloat32)
+		generated := low + random.Float64()*(high-low)
+		if generated == 0 && fieldRules.required {
+			generated = high
+		}
+		fieldValue.SetFloat(generated)
+	case reflect.Bool:
+		fieldValue.SetBool(fieldRules.required || random.IntN(2) == 0)
+	default:
+	}
+}
+
+// setFromString parses the string into the field. It is used for the values of the oneof rule.
+func setFromString(fieldValue reflect.Value, value string) error {
+	switch fieldValue.Kind() {
+	case reflect.String:
+		fieldValue.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(value, 10, fieldValue.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fieldValue.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(value, 10, fieldValue.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fieldValue.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, fieldValue.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fieldValue.SetFloat(parsed)
+	default:
+		return fmt.Errorf("the kind %s does not support the oneof rule", fieldValue.Kind())
+	}
+	return nil
+}
+
+// newRequest encodes the parameters into a request according to their tags.
+func newRequest[T any](cfg *config, params *T) *http.Request {
+	body := make(map[string]any)
+	query := url.Values{}
+	requestHeaders := http.Header{}
+	pathValues := make(map[string]string)
+	var cookies []*http.Cookie
+
+	for fieldName, fieldMetadata := range sortedFields[T]() {
+		fieldValue := fieldByName(params, fieldName, fieldMetadata)
+		if jsonTag, hasJSONTag := fieldMetadata.Tag(string(parameters.JSONTag)); hasJSONTag && jsonTag.Name != "" && jsonTag.Value != "-" {
+			body[jsonTag.Name] = fieldValue.Interface()
+		}
+		if fieldValue.Kind() == reflect.Ptr {
+			if fieldValue.IsNil() {
+				continue
+			}
+			fieldValue = fieldValue.Elem()
+		}
+		encoded := fmt.Sprint(fieldValue.Interface())
+		if name, hasTag := fieldMetadata.Tags[string(parameters.QueryTag)]; hasTag {
+			query.Set(name, encoded)
+		}
+		if name, hasTag := fieldMetadata.Tags[string(parameters.HeaderTag)]; hasTag {
+			requestHeaders.Set(name, encoded)
+		}
+		if name, hasTag := fieldMetadata.Tags[string(parameters.PathTag)]; hasTag {
+			pathValues[name] = encoded
+		}
+		if name, hasTag := fieldMetadata.Tags[string(parameters.CookieTag)]; hasTag {
+			cookies = append(cookies, &http.Cookie{Name: name, Value: encoded})
+		}
+	}
+
+	encodedBody, err := json.Marshal(body)
+	if err != nil {
+		panic(fmt.Sprintf("failed to encode the request body (%s)", err.Error()))
+	}
+	request := httptest.NewRequest(cfg.method, cfg.target, bytes.NewReader(encodedBody))
+	request.URL.RawQuery = query.Encode()
+	for name, values := range requestHeaders {
+		request.Header[name] = values
+	}
+	request.Header.Set(headers.ContentType, headers.ContentTypeApplicationJson)
+	for name, value := range pathValues {
+		request.SetPathValue(name, value)
+	}
+	for _, cookie := range cookies {
+		request.AddCookie(cookie)
+	}
+	return request
+}
+
+// serve sends the parameters to the handler. It returns a description of the failure, or an empty string if it succeeded.
+func serve[T any](cfg *config, handler http.Handler, params *T) (failure string) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			failure = fmt.Sprintf("panic: %v", recovered)
+		}
+	}()
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, newRequest(cfg, params))
+	if recorder.Code < 100 || recorder.Code > 599 {
+		return fmt.Sprintf("invalid status %d", recorder.Code)
+	}
+	return ""
+}
+
+// shrink reduces the fields of a failing input one at a time while the handler still fails.
+// It returns the smallest failing input found and its failure.
+func shrink[T any](cfg *config, handler http.Handler, params *T, failure string) (*T, string) {
+	attempts := 0
+	for progress := true; progress && attempts < maxShrinkAttempts; {
+		progress = false
+		for fieldName, fieldMetadata := range sortedFields[T]() {
+			for _, candidate := range shrinkCandidates(fieldByName(params, fieldName, fieldMetadata)) {
+				attempts++
+				candidateParams := new(T)
+				*candidateParams = *params
+				fieldByName(candidateParams, fieldName, fieldMetadata).Set(candidate)
+				if candidateFailure := serve(cfg, handler, candidateParams); candidateFailure != "" {
+					params, failure = candidateParams, candidateFailure
+					progress = true
+					break
+				}
+			}
+		}
+	}
+	return params, failure
+}
+
+// shrinkCandidates returns values that are simpler than the field value, ordered from the simplest.
+func shrinkCandidates(fieldValue reflect.Value) []reflect.Value {
+	fieldType := fieldValue.Type()
+	candidates := make([]reflect.Value, 0)
+	add := func(candidate any) {
+		converted := reflect.ValueOf(candidate).Convert(fieldType)
+		if !converted.Equal(fieldValue) {
+			candidates = append(candidates, converted)
+		}
+	}
+
+	switch fieldValue.Kind() {
+	case reflect.Ptr:
+		if fieldValue.IsNil() {
+			return candidates
+		}
+		candidates = append(candidates, reflect.Zero(fieldType))
+		for _, elemCandidate := range shrinkCandidates(fieldValue.Elem()) {
+			allocated := reflect.New(fieldType.Elem())
+			allocated.Elem().Set(elemCandidate)
+			candidates = append(candidates, allocated)
+		}
+	case reflect.String:
+		value := fieldValue.String()
+		add("")
+		add(value[:len(value)/2])
+		if len(value) > 0 {
+			add(value[1:])
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value := fieldValue.Int()
+		add(int64(0))
+		add(value / 2)
+		if value > 0 {
+			add(value - 1)
+		} else if value < 0 {
+			add(value + 1)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value := fieldValue.Uint()
+		add(uint64(0))
+		add(value / 2)
+		if value > 0 {
+			add(value - 1)
+		}
+	case reflect.Float32, reflect.Float64:
+		value := fieldValue.Float()
+		add(float64(0))
+		add(math.Trunc(value))
+		add(value / 2)
+	case reflect.Bool:
+		add(false)
+	default:
+	}
+	return candidates
+}
+
+// describe formats the parameters for the failure message.
+func describe[T any](params *T) string {
+	described := make(map[string]any)
+	for fieldName, fieldMetadata := range sortedFields[T]() {
+		described[fieldName] = fieldByName(params, fieldName, fieldMetadata).Interface()
+	}
+	encoded, err := json.Marshal(described)
+	if err != nil {
+		return fmt.Sprintf("%+v", *params)
+	}
+	return string(encoded)
+}
@@ -0,0 +1,111 @@
+package fuzzhttp_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/http/parameters"
+	"github.com/TriangleSide/GoBase/pkg/http/responders"
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+	"github.com/TriangleSide/GoBase/pkg/test/fuzzhttp"
+)
+
+// recordingT records the failure of a fuzzing run instead of failing the test.
+type recordingT struct {
+	failure string
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Fatalf(format string, args ...any) {
+	r.failure = fmt.Sprintf(format, args...)
+}
+
+type embeddedParams struct {
+	Trace string `httpHeader:"x-trace" json:"-" validate:"required,len=8"`
+}
+
+type sampleParams struct {
+	embeddedParams
+	ID       int      `urlPath:"id" json:"-" validate:"gt=0,lte=1000"`
+	Mode     string   `urlQuery:"mode" json:"-" validate:"required,oneof=fast slow"`
+	Name     string   `json:"name" validate:"required,min=2,max=10"`
+	Ratio    float64  `json:"ratio" validate:"gte=0,lt=1"`
+	Count    *uint8   `json:"count,omitempty" validate:"omitempty,max=5"`
+	Enabled  bool     `json:"enabled"`
+	Ignored  []string `json:"ignored"`
+	internal string
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when the generic is not a struct it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			fuzzhttp.Run[string](&recordingT{}, http.NotFoundHandler())
+		}, "the generic must be a struct")
+	})
+
+	t.Run("when the iterations are not positive it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			fuzzhttp.Run[sampleParams](&recordingT{}, http.NotFoundHandler(), fuzzhttp.WithIterations(0))
+		}, "the iterations must be greater than zero")
+	})
+
+	t.Run("when the generated parameters are decoded they should satisfy the validation rules", func(t *testing.T) {
+		t.Parallel()
+		recorder := &recordingT{}
+		fuzzhttp.Run[sampleParams](recorder, http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if _, err := parameters.Decode[sampleParams](request); err != nil {
+				panic(err)
+			}
+			writer.WriteHeader(http.StatusOK)
+		}), fuzzhttp.WithIterations(500))
+		assert.Equals(t, recorder.failure, "")
+	})
+
+	t.Run("when a responder handles the parameters it should never fail", func(t *testing.T) {
+		t.Parallel()
+		recorder := &recordingT{}
+		fuzzhttp.Run[sampleParams](recorder, http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			responders.Status[sampleParams](writer, request, func(params *sampleParams) (int, error) {
+				return http.StatusNoContent, nil
+			})
+		}))
+		assert.Equals(t, recorder.failure, "")
+	})
+
+	t.Run("when the handler panics it should fail with a shrunk input and the seed", func(t *testing.T) {
+		t.Parallel()
+		recorder := &recordingT{}
+		fuzzhttp.Run[sampleParams](recorder, http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			params, err := parameters.Decode[sampleParams](request)
+			if err == nil && params.ID > 500 {
+				panic("the id is too large")
+			}
+			writer.WriteHeader(http.StatusOK)
+		}), fuzzhttp.WithSeed(42))
+		assert.True(t, strings.Contains(recorder.failure, "the seed 42"))
+		assert.True(t, strings.Contains(recorder.failure, `"ID":501`))
+		assert.True(t, strings.Contains(recorder.failure, "panic: the id is too large"))
+	})
+
+	t.Run("when the same seed is used it should generate the same failure", func(t *testing.T) {
+		t.Parallel()
+		handler := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if request.URL.Query().Get("mode") == "slow" {
+				panic("slow mode")
+			}
+		})
+		first := &recordingT{}
+		fuzzhttp.Run[sampleParams](first, handler, fuzzhttp.WithSeed(7))
+		second := &recordingT{}
+		fuzzhttp.Run[sampleParams](second, handler, fuzzhttp.WithSeed(7))
+		assert.True(t, first.failure != "")
+		assert.Equals(t, first.failure, second.failure)
+	})
+}
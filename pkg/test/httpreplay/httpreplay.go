@@ -0,0 +1,218 @@
+package httpreplay
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// Mode determines whether the Recorder records real HTTP interactions or replays them from a cassette.
+type Mode int
+
+const (
+	// ModeRecord sends requests to the real transport and records the interactions.
+	ModeRecord Mode = iota
+
+	// ModeReplay serves responses from the interactions in the cassette without any network calls.
+	ModeReplay
+)
+
+// RecordedRequest is the part of an HTTP request that is stored in a cassette.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// RecordedResponse is the part of an HTTP response that is stored in a cassette.
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+}
+
+// Interaction is a request and the response it received.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// Cassette is the file format of the recorded interactions.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// config is configured by the Option functions.
+type config struct {
+	transport http.RoundTripper
+}
+
+// Option is used to configure the Recorder.
+type Option func(cfg *config)
+
+// WithTransport sets the transport used to make the real HTTP requests in record mode.
+// It defaults to http.DefaultTransport.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(cfg *config) {
+		cfg.transport = transport
+	}
+}
+
+// Recorder is an http.RoundTripper that records HTTP interactions to a cassette file or replays them.
+// Interactions are keyed by the request method, URL and body. When many interactions have the same key,
+// they are replayed in the order they were recorded.
+type Recorder struct {
+	mode         Mode
+	cassettePath string
+	transport    http.RoundTripper
+	mu           sync.Mutex
+	cassette     *Cassette
+	replayQueues map[string][]*Interaction
+}
+
+// New allocates a Recorder for the cassette file. In replay mode, the cassette file is loaded.
+func New(cassettePath string, mode Mode, opts ...Option) (*Recorder, error) {
+	cfg := &config{
+		transport: http.DefaultTransport,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	recorder := &Recorder{
+		mode:         mode,
+		cassettePath: cassettePath,
+		transport:    cfg.transport,
+		mu:           sync.Mutex{},
+		cassette:     &Cassette{Interactions: make([]*Interaction, 0)},
+		replayQueues: make(map[string][]*Interaction),
+	}
+
+	switch mode {
+	case ModeRecord:
+	case ModeReplay:
+		contents, err := os.ReadFile(cassettePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the cassette (%w)", err)
+		}
+		if err := json.Unmarshal(contents, recorder.cassette); err != nil {
+			return nil, fmt.Errorf("failed to decode the cassette (%w)", err)
+		}
+		for _, interaction := range recorder.cassette.Interactions {
+			key := interactionKey(interaction.Request.Method, interaction.Request.URL, interaction.Request.Body)
+			recorder.replayQueues[key] = append(recorder.replayQueues[key], interaction)
+		}
+	default:
+		return nil, fmt.Errorf("invalid mode %d", mode)
+	}
+
+	return recorder, nil
+}
+
+// RoundTrip is the Recorder implementing the http.RoundTripper interface.
+func (r *Recorder) RoundTrip(request *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	if request.Body != nil {
+		var err error
+		requestBody, err = io.ReadAll(request.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the request body (%w)", err)
+		}
+		if err := request.Body.Close(); err != nil {
+			return nil, fmt.Errorf("failed to close the request body (%w)", err)
+		}
+	}
+	key := interactionKey(request.Method, request.URL.String(), requestBody)
+
+	if r.mode == ModeReplay {
+		r.mu.Lock()
+		queue := r.replayQueues[key]
+		if len(queue) == 0 {
+			r.mu.Unlock()
+			return nil, fmt.Errorf("no recorded interaction for %s %s", request.Method, request.URL.String())
+		}
+		interaction := queue[0]
+		r.replayQueues[key] = queue[1:]
+		r.mu.Unlock()
+		return newResponse(request, &interaction.Response), nil
+	}
+
+	outgoing := request.Clone(request.Context())
+	outgoing.Body = io.NopCloser(bytes.NewReader(requestBody))
+	response, err := r.transport.RoundTrip(outgoing)
+	if err != nil {
+		return nil, err
+	}
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the response body (%w)", err)
+	}
+	if err := response.Body.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close the response body (%w)", err)
+	}
+
+	interaction := &Interaction{
+		Request: RecordedRequest{
+			Method: request.Method,
+			URL:    request.URL.String(),
+			Header: request.Header.Clone(),
+			Body:   requestBody,
+		},
+		Response: RecordedResponse{
+			StatusCode: response.StatusCode,
+			Header:     response.Header.Clone(),
+			Body:       responseBody,
+		},
+	}
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+
+	return newResponse(request, &interaction.Response), nil
+}
+
+// Save writes the recorded interactions to the cassette file. It can only be called in record mode.
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return errors.New("the cassette can only be saved in record mode")
+	}
+	r.mu.Lock()
+	contents, err := json.MarshalIndent(r.cassette, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode the cassette (%w)", err)
+	}
+	if err := os.WriteFile(r.cassettePath, contents, 0644); err != nil {
+		return fmt.Errorf("failed to write the cassette (%w)", err)
+	}
+	return nil
+}
+
+// interactionKey returns the key used to match a request to a recorded interaction.
+func interactionKey(method string, url string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	return method + " " + url + " " + hex.EncodeToString(bodyHash[:])
+}
+
+// newResponse creates an http.Response from a RecordedResponse.
+func newResponse(request *http.Request, recorded *RecordedResponse) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       request,
+	}
+}
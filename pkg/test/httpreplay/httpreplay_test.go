@@ -0,0 +1,116 @@
+package httpreplay_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+	"github.com/TriangleSide/GoBase/pkg/test/httpreplay"
+)
+
+func TestHTTPReplay(t *testing.T) {
+	t.Parallel()
+
+	doRequest := func(t *testing.T, client *http.Client, method string, url string, body string) (int, string) {
+		t.Helper()
+		request, err := http.NewRequest(method, url, strings.NewReader(body))
+		assert.NoError(t, err)
+		response, err := client.Do(request)
+		assert.NoError(t, err)
+		responseBody, err := io.ReadAll(response.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, response.Body.Close())
+		return response.StatusCode, string(responseBody)
+	}
+
+	t.Run("when interactions are recorded they should be replayed deterministically without the server", func(t *testing.T) {
+		t.Parallel()
+		requestCount := atomic.Int32{}
+		server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			count := requestCount.Add(1)
+			body, err := io.ReadAll(request.Body)
+			assert.NoError(t, err)
+			writer.Header().Set("X-Count", strconv.Itoa(int(count)))
+			writer.WriteHeader(http.StatusCreated)
+			_, err = io.WriteString(writer, request.Method+":"+string(body)+":"+strconv.Itoa(int(count)))
+			assert.NoError(t, err)
+		}))
+		cassettePath := filepath.Join(t.TempDir(), "cassette.json")
+
+		recorder, err := httpreplay.New(cassettePath, httpreplay.ModeRecord)
+		assert.NoError(t, err)
+		recordClient := &http.Client{Transport: recorder}
+		status, body := doRequest(t, recordClient, http.MethodPost, server.URL+"/items", "first")
+		assert.Equals(t, status, http.StatusCreated)
+		assert.Equals(t, body, "POST:first:1")
+		_, body = doRequest(t, recordClient, http.MethodPost, server.URL+"/items", "second")
+		assert.Equals(t, body, "POST:second:2")
+		_, body = doRequest(t, recordClient, http.MethodPost, server.URL+"/items", "first")
+		assert.Equals(t, body, "POST:first:3")
+		assert.NoError(t, recorder.Save())
+		server.Close()
+
+		for i := 0; i < 2; i++ {
+			replayer, err := httpreplay.New(cassettePath, httpreplay.ModeReplay)
+			assert.NoError(t, err)
+			replayClient := &http.Client{Transport: replayer}
+			_, body = doRequest(t, replayClient, http.MethodPost, server.URL+"/items", "second")
+			assert.Equals(t, body, "POST:second:2")
+			status, body = doRequest(t, replayClient, http.MethodPost, server.URL+"/items", "first")
+			assert.Equals(t, status, http.StatusCreated)
+			assert.Equals(t, body, "POST:first:1")
+			_, body = doRequest(t, replayClient, http.MethodPost, server.URL+"/items", "first")
+			assert.Equals(t, body, "POST:first:3")
+		}
+		assert.Equals(t, requestCount.Load(), int32(3))
+	})
+
+	t.Run("when a request was not recorded it should fail to replay", func(t *testing.T) {
+		t.Parallel()
+		cassettePath := filepath.Join(t.TempDir(), "cassette.json")
+		assert.NoError(t, os.WriteFile(cassettePath, []byte(`{"interactions":[]}`), 0644))
+		replayer, err := httpreplay.New(cassettePath, httpreplay.ModeReplay)
+		assert.NoError(t, err)
+		_, err = (&http.Client{Transport: replayer}).Get("http://localhost/missing")
+		assert.ErrorPart(t, err, "no recorded interaction for GET http://localhost/missing")
+	})
+
+	t.Run("when the cassette does not exist it should fail to replay", func(t *testing.T) {
+		t.Parallel()
+		replayer, err := httpreplay.New(filepath.Join(t.TempDir(), "missing.json"), httpreplay.ModeReplay)
+		assert.ErrorPart(t, err, "failed to read the cassette")
+		assert.Nil(t, replayer)
+	})
+
+	t.Run("when the cassette is malformed it should fail to replay", func(t *testing.T) {
+		t.Parallel()
+		cassettePath := filepath.Join(t.TempDir(), "cassette.json")
+		assert.NoError(t, os.WriteFile(cassettePath, []byte(`{`), 0644))
+		replayer, err := httpreplay.New(cassettePath, httpreplay.ModeReplay)
+		assert.ErrorPart(t, err, "failed to decode the cassette")
+		assert.Nil(t, replayer)
+	})
+
+	t.Run("when the mode is invalid it should fail", func(t *testing.T) {
+		t.Parallel()
+		replayer, err := httpreplay.New("cassette.json", httpreplay.Mode(100))
+		assert.ErrorExact(t, err, "invalid mode 100")
+		assert.Nil(t, replayer)
+	})
+
+	t.Run("when the cassette is saved in replay mode it should fail", func(t *testing.T) {
+		t.Parallel()
+		cassettePath := filepath.Join(t.TempDir(), "cassette.json")
+		assert.NoError(t, os.WriteFile(cassettePath, []byte(`{"interactions":[]}`), 0644))
+		replayer, err := httpreplay.New(cassettePath, httpreplay.ModeReplay)
+		assert.NoError(t, err)
+		assert.ErrorExact(t, replayer.Save(), "the cassette can only be saved in record mode")
+	})
+}
@@ -0,0 +1,165 @@
+package assign
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/TriangleSide/GoBase/pkg/utils/fields"
+)
+
+// pathSegment is a part of a field path. It is either the name of a struct field,
+// or the index or key between brackets.
+type pathSegment struct {
+	name      string
+	key       string
+	isBracket bool
+}
+
+// String returns the segment as it is written in a field path.
+func (s pathSegment) String() string {
+	if s.isBracket {
+		return "[" + s.key + "]"
+	}
+	return s.name
+}
+
+// FieldPath sets the value at a field path of a struct to a provided value encoded as a string. A field path
+// is made of field names separated by dots, where slices and arrays are indexed, and maps are keyed, between brackets.
+//
+//	assign.FieldPath(&conf, "Servers[2].Host", "localhost")
+//	assign.FieldPath(&conf, "Labels[app]", "api")
+//
+// The value is decoded with the same rules as StructField, using the tags of the last struct field of the path.
+// Nil pointers and maps are allocated along the path, and slices are grown to fit the index.
+// Map keys are decoded like values of their type. Since paths usually come from user input, such as command line
+// overrides, an invalid path returns an error instead of panicking.
+func FieldPath[T any](obj *T, fieldPath string, stringEncodedValue string) error {
+	structPtr := reflect.ValueOf(obj)
+	if structPtr.Kind() != reflect.Ptr || structPtr.Elem().Kind() != reflect.Struct {
+		panic("obj must be a pointer to a struct")
+	}
+	segments, err := parseFieldPath(fieldPath)
+	if err != nil {
+		return fmt.Errorf("invalid field path '%s' (%w)", fieldPath, err)
+	}
+	return assignPath(structPtr.Elem(), segments, nil, stringEncodedValue)
+}
+
+// parseFieldPath splits a field path, such as Servers[2].Host, into its segments.
+func parseFieldPath(fieldPath string) ([]pathSegment, error) {
+	segments := make([]pathSegment, 0)
+	for i := 0; i < len(fieldPath); {
+		if fieldPath[i] == '[' {
+			end := strings.IndexByte(fieldPath[i:], ']')
+			if end < 0 {
+				return nil, errors.New("unterminated bracket")
+			}
+			segments = append(segments, pathSegment{key: fieldPath[i+1 : i+end], isBracket: true})
+			i += end + 1
+		} else {
+			end := strings.IndexAny(fieldPath[i:], ".[")
+			if end < 0 {
+				end = len(fieldPath) - i
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("expecting a field name at position %d", i)
+			}
+			segments = append(segments, pathSegment{name: fieldPath[i : i+end]})
+			i += end
+		}
+
+		if i < len(fieldPath) && fieldPath[i] == '.' {
+			i++
+			if i == len(fieldPath) {
+				return nil, errors.New("expecting a field name after the last dot")
+			}
+		} else if i < len(fieldPath) && fieldPath[i] != '[' {
+			return nil, fmt.Errorf("unexpected character '%c' at position %d", fieldPath[i], i)
+		}
+	}
+	if len(segments) == 0 {
+		return nil, errors.New("the path is empty")
+	}
+	if segments[0].isBracket {
+		return nil, errors.New("the path must start with a field name")
+	}
+	return segments, nil
+}
+
+// assignPath follows the segments from the settable target and sets the decoded value at the end of the path.
+// The fieldMetadata is the metadata of the last struct field on the path.
+func assignPath(target reflect.Value, segments []pathSegment, fieldMetadata *fields.FieldMetadata, stringEncodedValue string) error {
+	if len(segments) == 0 {
+		return setDecodedValue(target, fieldMetadata, stringEncodedValue)
+	}
+
+	for target.Kind() == reflect.Ptr {
+		if target.IsNil() {
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+		target = target.Elem()
+	}
+
+	segment := segments[0]
+	switch target.Kind() {
+	case reflect.Struct:
+		if segment.isBracket {
+			return fmt.Errorf("the struct %s cannot be indexed with %s", target.Type(), segment)
+		}
+		nextMetadata, found := fields.StructMetadataFromType(target.Type()).Fetch(segment.name)
+		if !found {
+			return fmt.Errorf("no field '%s' in struct '%s'", segment.name, target.Type())
+		}
+		field, _ := lookupStructField(target.Addr(), segment.name)
+		if !field.CanSet() {
+			return fmt.Errorf("the field '%s' of struct '%s' cannot be set", segment.name, target.Type())
+		}
+		return assignPath(field, segments[1:], nextMetadata, stringEncodedValue)
+	case reflect.Slice, reflect.Array:
+		if !segment.isBracket {
+			return fmt.Errorf("expecting an index for the %s instead of the field '%s'", target.Type(), segment.name)
+		}
+		index, err := strconv.Atoi(segment.key)
+		if err != nil || index < 0 {
+			return fmt.Errorf("invalid index '%s' for the %s", segment.key, target.Type())
+		}
+		if index >= target.Len() {
+			if target.Kind() == reflect.Array {
+				return fmt.Errorf("the index %d is out of range for the %s", index, target.Type())
+			}
+			grown := reflect.MakeSlice(target.Type(), index+1, index+1)
+			reflect.Copy(grown, target)
+			target.Set(grown)
+		}
+		if err := assignPath(target.Index(index), segments[1:], fieldMetadata, stringEncodedValue); err != nil {
+			return fmt.Errorf("index %d (%w)", index, err)
+		}
+		return nil
+	case reflect.Map:
+		if !segment.isBracket {
+			return fmt.Errorf("expecting a key for the %s instead of the field '%s'", target.Type(), segment.name)
+		}
+		keyPtr := reflect.New(target.Type().Key())
+		if err := decodeValue(keyPtr, &fields.FieldMetadata{}, segment.key); err != nil {
+			return fmt.Errorf("invalid key '%s' for the %s (%w)", segment.key, target.Type(), err)
+		}
+		if target.IsNil() {
+			target.Set(reflect.MakeMap(target.Type()))
+		}
+		// Map elements are not addressable, so the element is copied, assigned, then stored back in the map.
+		element := reflect.New(target.Type().Elem()).Elem()
+		if existing := target.MapIndex(keyPtr.Elem()); existing.IsValid() {
+			element.Set(existing)
+		}
+		if err := assignPath(element, segments[1:], fieldMetadata, stringEncodedValue); err != nil {
+			return fmt.Errorf("key %s (%w)", segment.key, err)
+		}
+		target.SetMapIndex(keyPtr.Elem(), element)
+		return nil
+	default:
+		return fmt.Errorf("the type %s cannot be traversed with %s", target.Type(), segment)
+	}
+}
@@ -0,0 +1,149 @@
+package assign_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+	"github.com/TriangleSide/GoBase/pkg/utils/assign"
+)
+
+type fieldPathServer struct {
+	Host    string
+	Port    int
+	Timeout time.Duration
+}
+
+type fieldPathEmbedded struct {
+	Region string
+}
+
+type fieldPathStruct struct {
+	fieldPathEmbedded
+	Name       string
+	Servers    []fieldPathServer
+	ServerPtrs []*fieldPathServer
+	Labels     map[string]string
+	Limits     map[string]int64 `unit:"bytes"`
+	Ports      map[int]string
+	Nested     *fieldPathStruct
+	Matrix     [][]int
+	Pair       [2]string
+	ByName     map[string]fieldPathServer
+	unexported string
+}
+
+func TestFieldPath(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when the path is a field name it should set the field", func(t *testing.T) {
+		t.Parallel()
+		values := &fieldPathStruct{}
+		assert.NoError(t, assign.FieldPath(values, "Name", "test"))
+		assert.Equals(t, values.Name, "test")
+		assert.NoError(t, assign.FieldPath(values, "Region", "us-east"))
+		assert.Equals(t, values.Region, "us-east")
+	})
+
+	t.Run("when the path indexes a slice it should grow the slice and set the element", func(t *testing.T) {
+		t.Parallel()
+		values := &fieldPathStruct{Servers: []fieldPathServer{{Host: "first"}}}
+		assert.NoError(t, assign.FieldPath(values, "Servers[2].Host", "third"))
+		assert.NoError(t, assign.FieldPath(values, "Servers[2].Timeout", "5s"))
+		assert.NoError(t, assign.FieldPath(values, "Servers[0].Port", "80"))
+		assert.Equals(t, values.Servers, []fieldPathServer{
+			{Host: "first", Port: 80},
+			{},
+			{Host: "third", Timeout: 5 * time.Second},
+		})
+	})
+
+	t.Run("when the path goes through nil pointers it should allocate them", func(t *testing.T) {
+		t.Parallel()
+		values := &fieldPathStruct{}
+		assert.NoError(t, assign.FieldPath(values, "ServerPtrs[1].Host", "host"))
+		assert.Equals(t, len(values.ServerPtrs), 2)
+		assert.Nil(t, values.ServerPtrs[0])
+		assert.Equals(t, values.ServerPtrs[1].Host, "host")
+		assert.NoError(t, assign.FieldPath(values, "Nested.Nested.Labels[app]", "api"))
+		assert.Equals(t, values.Nested.Nested.Labels, map[string]string{"app": "api"})
+	})
+
+	t.Run("when the path keys a map it should allocate the map and set the key", func(t *testing.T) {
+		t.Parallel()
+		values := &fieldPathStruct{}
+		assert.NoError(t, assign.FieldPath(values, "Labels[app]", "api"))
+		assert.NoError(t, assign.FieldPath(values, "Labels[app.kubernetes.io/name]", "gobase"))
+		assert.Equals(t, values.Labels, map[string]string{"app": "api", "app.kubernetes.io/name": "gobase"})
+		assert.NoError(t, assign.FieldPath(values, "Ports[8080]", "http"))
+		assert.Equals(t, values.Ports, map[int]string{8080: "http"})
+	})
+
+	t.Run("when the path goes through a map of structs it should keep the other fields of the element", func(t *testing.T) {
+		t.Parallel()
+		values := &fieldPathStruct{ByName: map[string]fieldPathServer{"main": {Host: "host"}}}
+		assert.NoError(t, assign.FieldPath(values, "ByName[main].Port", "443"))
+		assert.Equals(t, values.ByName["main"], fieldPathServer{Host: "host", Port: 443})
+	})
+
+	t.Run("when the path has many indexes it should set the nested element", func(t *testing.T) {
+		t.Parallel()
+		values := &fieldPathStruct{}
+		assert.NoError(t, assign.FieldPath(values, "Matrix[1][2]", "7"))
+		assert.Equals(t, values.Matrix, [][]int{nil, {0, 0, 7}})
+		assert.NoError(t, assign.FieldPath(values, "Pair[1]", "second"))
+		assert.Equals(t, values.Pair, [2]string{"", "second"})
+	})
+
+	t.Run("when the last struct field has tags they should be used to decode the value", func(t *testing.T) {
+		t.Parallel()
+		values := &fieldPathStruct{}
+		assert.NoError(t, assign.FieldPath(values, "Limits[body]", "1KiB"))
+		assert.Equals(t, values.Limits["body"], int64(1024))
+	})
+
+	t.Run("when the value can't be decoded it should return an error with its location", func(t *testing.T) {
+		t.Parallel()
+		values := &fieldPathStruct{}
+		err := assign.FieldPath(values, "Servers[1].Port", "not a port")
+		assert.ErrorPart(t, err, "index 1 (int parsing error")
+	})
+
+	t.Run("when the obj is not a pointer to a struct it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			_ = assign.FieldPath(new(int), "Name", "test")
+		}, "obj must be a pointer to a struct")
+	})
+
+	t.Run("when the path is invalid it should return an error", func(t *testing.T) {
+		t.Parallel()
+		subTests := []struct {
+			path      string
+			errorPart string
+		}{
+			{"", "the path is empty"},
+			{"[0]", "the path must start with a field name"},
+			{"Servers[0", "unterminated bracket"},
+			{"Servers..Host", "expecting a field name at position 8"},
+			{".Name", "expecting a field name at position 0"},
+			{"Name.", "expecting a field name after the last dot"},
+			{"Servers[0]Host", "unexpected character 'H' at position 10"},
+			{"Unknown", "no field 'Unknown' in struct"},
+			{"unexported", "the field 'unexported' of struct"},
+			{"Name[0]", "the type string cannot be traversed with [0]"},
+			{"Nested[0]", "cannot be indexed with [0]"},
+			{"Servers.Host", "expecting an index for the []assign_test.fieldPathServer instead of the field 'Host'"},
+			{"Servers[-1]", "invalid index '-1'"},
+			{"Servers[one]", "invalid index 'one'"},
+			{"Pair[2]", "the index 2 is out of range for the [2]string"},
+			{"Labels.app", "expecting a key for the map[string]string instead of the field 'app'"},
+			{"Ports[http]", "invalid key 'http' for the map[int]string"},
+			{"ByName[main].Unknown", "key main (no field 'Unknown'"},
+		}
+		for _, subTest := range subTests {
+			values := &fieldPathStruct{}
+			assert.ErrorPart(t, assign.FieldPath(values, subTest.path, "value"), subTest.errorPart)
+		}
+	})
+}
@@ -4,108 +4,346 @@ import (
 	"encoding"
 	"encoding/json"
 	"fmt"
+	"math"
+	"net/url"
 	"reflect"
 	"strconv"
+	"strings"
+	"time"
+	"unicode"
 
+	"github.com/TriangleSide/GoBase/pkg/utils/enum"
 	"github.com/TriangleSide/GoBase/pkg/utils/fields"
 )
 
+// TimezoneTag is a struct field tag that specifies the IANA location a time.Time field is normalized into.
+//
+//	type MyStruct struct {
+//	    From time.Time `timezone:"UTC"`
+//	}
+const TimezoneTag = "timezone"
+
+// UnitTag is a struct field tag that specifies the unit of an integer field. With UnitBytes, the field accepts
+// humanized byte sizes.
+//
+//	type MyStruct struct {
+//	    MaxBodySize int64 `unit:"bytes"`
+//	}
+const UnitTag = "unit"
+
+// UnitBytes is the UnitTag value of byte sizes. A byte size is a number followed by an optional unit. The units
+// B, KB, MB, GB, TB and PB are powers of 1000, and the units KiB, MiB, GiB, TiB and PiB are powers of 1024.
+// The units are case-insensitive, and the number can have a fraction if the size is a whole number of bytes.
+const UnitBytes = "bytes"
+
+var (
+	// timeType is the reflected type of time.Time.
+	timeType = reflect.TypeOf(time.Time{})
+
+	// durationType is the reflected type of time.Duration.
+	durationType = reflect.TypeOf(time.Duration(0))
+
+	// urlType is the reflected type of url.URL.
+	urlType = reflect.TypeOf(url.URL{})
+
+	// byteSizeUnits maps the lower-case byte size units to their number of bytes.
+	byteSizeUnits = map[string]float64{
+		"":    1,
+		"b":   1,
+		"kb":  1e3,
+		"mb":  1e6,
+		"gb":  1e9,
+		"tb":  1e12,
+		"pb":  1e15,
+		"kib": 1 << 10,
+		"mib": 1 << 20,
+		"gib": 1 << 30,
+		"tib": 1 << 40,
+		"pib": 1 << 50,
+	}
+)
+
 // StructField sets a struct field specified by its name to a provided value encoded as a string.
 // The function handles various data types including basic types (string, int, etc.),
 // complex types (structs, slices, maps) and types implementing the encoding.TextUnmarshaler interface.
 // The conversion from string to the appropriate type is performed based on the field's underlying type.
+// String based types registered with enum.MustRegister are parsed into their canonical value.
 // JSON format is expected for complex types. This function supports setting both direct values and pointers to the values.
+//
+// Times are parsed with RFC3339 and keep the offset of the input. If the field has a TimezoneTag, the time
+// is normalized into that location, and inputs without an offset are interpreted as local to that location.
+// Durations are parsed with time.ParseDuration, such as 30s, or as a number of nanoseconds.
+// URLs are parsed with url.Parse. Integer fields with the UnitTag set to UnitBytes accept byte sizes, such as 512MiB.
 func StructField[T any](obj *T, fieldName string, stringEncodedValue string) error {
-	structValue := reflect.ValueOf(obj)
+	return structField(reflect.ValueOf(obj), fieldName, stringEncodedValue)
+}
+
+// ReflectedStructField is the same as StructField, but for cases where the type of the struct is only known at runtime.
+// The structPtr must be the reflected value of a pointer to a struct.
+func ReflectedStructField(structPtr reflect.Value, fieldName string, stringEncodedValue string) error {
+	return structField(structPtr, fieldName, stringEncodedValue)
+}
+
+// structField sets the field of the struct that structPtr points to. See StructField.
+func structField(structPtr reflect.Value, fieldName string, stringEncodedValue string) error {
+	structFieldValue, fieldMetadata := lookupStructField(structPtr, fieldName)
+	return setDecodedValue(structFieldValue, fieldMetadata, stringEncodedValue)
+}
+
+// setDecodedValue decodes the string encoded value and sets it into the settable target.
+// If the target is a pointer, it is set to a newly allocated value.
+func setDecodedValue(target reflect.Value, fieldMetadata *fields.FieldMetadata, stringEncodedValue string) error {
+	// Get the target type. This is needed to determine how to set the value.
+	originalFieldType := target.Type()
+	var fieldType reflect.Type
+	if originalFieldType.Kind() == reflect.Ptr {
+		fieldType = originalFieldType.Elem()
+	} else {
+		fieldType = originalFieldType
+	}
+
+	// fieldPtr is an allocated ptr to the raw type of the field to set the encoded value into.
+	fieldPtr := reflect.New(fieldType)
+	if err := decodeValue(fieldPtr, fieldMetadata, stringEncodedValue); err != nil {
+		return err
+	}
+
+	// If the target is a ptr, set the ptr to the newly allocated value in fieldPtr.
+	// If the target it not a ptr, copy the contents of fieldPtr into it.
+	if originalFieldType.Kind() == reflect.Ptr {
+		target.Set(fieldPtr)
+	} else {
+		target.Set(fieldPtr.Elem())
+	}
+
+	return nil
+}
+
+// StructFieldValues sets a slice struct field specified by its name to a list of values encoded as strings.
+// Each value is decoded into an element of the slice with the same rules as StructField.
+// The field can be a slice, a pointer to a slice, and the elements of the slice can be pointers.
+func StructFieldValues[T any](obj *T, fieldName string, stringEncodedValues []string) error {
+	structFieldValue, fieldMetadata := lookupStructField(reflect.ValueOf(obj), fieldName)
+
+	originalFieldType := structFieldValue.Type()
+	sliceType := originalFieldType
+	if sliceType.Kind() == reflect.Ptr {
+		sliceType = sliceType.Elem()
+	}
+	if sliceType.Kind() != reflect.Slice {
+		return fmt.Errorf("the field type %s is not a slice", originalFieldType)
+	}
+
+	elementType := sliceType.Elem()
+	elementIsPtr := elementType.Kind() == reflect.Ptr
+	if elementIsPtr {
+		elementType = elementType.Elem()
+	}
+
+	slice := reflect.MakeSlice(sliceType, len(stringEncodedValues), len(stringEncodedValues))
+	for i, stringEncodedValue := range stringEncodedValues {
+		elementPtr := reflect.New(elementType)
+		if err := decodeValue(elementPtr, fieldMetadata, stringEncodedValue); err != nil {
+			return fmt.Errorf("element %d (%w)", i, err)
+		}
+		if elementIsPtr {
+			slice.Index(i).Set(elementPtr)
+		} else {
+			slice.Index(i).Set(elementPtr.Elem())
+		}
+	}
+
+	if originalFieldType.Kind() == reflect.Ptr {
+		slicePtr := reflect.New(sliceType)
+		slicePtr.Elem().Set(slice)
+		structFieldValue.Set(slicePtr)
+	} else {
+		structFieldValue.Set(slice)
+	}
+
+	return nil
+}
+
+// lookupStructField returns the value of the struct field specified by its name and its metadata.
+// The structValue must be a pointer to a struct. This accounts for fields in embedded anonymous structs.
+func lookupStructField(structValue reflect.Value, fieldName string) (reflect.Value, *fields.FieldMetadata) {
 	if structValue.Kind() != reflect.Ptr || structValue.Elem().Kind() != reflect.Struct {
 		panic("obj must be a pointer to a struct")
 	}
 
 	// Get the field metadata for all the structs fields.
-	fieldsToMetadata := fields.StructMetadata[T]()
+	fieldsToMetadata := fields.StructMetadataFromType(structValue.Elem().Type())
 	fieldMetadata, foundFieldMetadata := fieldsToMetadata.Fetch(fieldName)
 	if !foundFieldMetadata {
 		panic(fmt.Sprintf("no field '%s' in struct '%s'", fieldName, structValue.Type().String()))
 	}
 
-	// Get the value of the specified field in the struct.
-	// This accounts for fields in embedded anonymous structs.
-	var structFieldValue reflect.Value
 	if len(fieldMetadata.Anonymous) != 0 {
 		anonValue := structValue.Elem()
 		for _, anonymousName := range fieldMetadata.Anonymous {
 			anonValue = anonValue.FieldByName(anonymousName)
 		}
-		structFieldValue = anonValue.FieldByName(fieldName)
-	} else {
-		structFieldValue = structValue.Elem().FieldByName(fieldName)
+		return anonValue.FieldByName(fieldName), fieldMetadata
 	}
+	return structValue.Elem().FieldByName(fieldName), fieldMetadata
+}
 
-	// Get the struct field type. This is needed to determine how to set the value.
-	originalFieldType := structFieldValue.Type()
-	var fieldType reflect.Type
-	if originalFieldType.Kind() == reflect.Ptr {
-		fieldType = originalFieldType.Elem()
-	} else {
-		fieldType = originalFieldType
+// decodeValue decodes the string encoded value into the allocated value that valuePtr points to.
+// The tags of the field metadata customize the decoding, like the TimezoneTag.
+func decodeValue(valuePtr reflect.Value, fieldMetadata *fields.FieldMetadata, stringEncodedValue string) error {
+	valueType := valuePtr.Type().Elem()
+
+	// Switch on how to set the value.
+	if timezone, hasTimezone := fieldMetadata.Tags[TimezoneTag]; hasTimezone && valueType == timeType {
+		// If the value is a time with a timezone, the time is parsed and normalized into the location.
+		parsed, err := parseTimeInLocation(stringEncodedValue, timezone)
+		if err != nil {
+			return err
+		}
+		valuePtr.Elem().Set(reflect.ValueOf(parsed))
+		return nil
 	}
 
-	// fieldPtr is an allocated ptr to the raw type of the field to set the encoded value into.
-	fieldPtr := reflect.New(fieldType)
+	if valueType == durationType {
+		// If the value is a duration, it is parsed like 30s, or as a number of nanoseconds.
+		parsed, err := time.ParseDuration(stringEncodedValue)
+		if err != nil {
+			nanoseconds, intErr := strconv.ParseInt(stringEncodedValue, 10, 64)
+			if intErr != nil {
+				return fmt.Errorf("duration parsing error (%s)", err.Error())
+			}
+			parsed = time.Duration(nanoseconds)
+		}
+		valuePtr.Elem().SetInt(int64(parsed))
+		return nil
+	}
 
-	// Switch on how to set the value.
-	if reflect.PointerTo(fieldType).Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()) {
-		// If the field type implements encoding.TextUnmarshaler, the interface is used parse the value.
-		unmarshaler := fieldPtr.Interface().(encoding.TextUnmarshaler)
+	if valueType == urlType {
+		parsed, err := url.Parse(stringEncodedValue)
+		if err != nil {
+			return fmt.Errorf("url parsing error (%s)", err.Error())
+		}
+		valuePtr.Elem().Set(reflect.ValueOf(*parsed))
+		return nil
+	}
+
+	if unit, hasUnit := fieldMetadata.Tags[UnitTag]; hasUnit && unit == UnitBytes {
+		return decodeByteSize(valuePtr, stringEncodedValue)
+	}
+
+	if valuePtr.Type().Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()) {
+		// If the value type implements encoding.TextUnmarshaler, the interface is used parse the value.
+		unmarshaler := valuePtr.Interface().(encoding.TextUnmarshaler)
 		if err := unmarshaler.UnmarshalText([]byte(stringEncodedValue)); err != nil {
 			return fmt.Errorf("text unmarshall error (%s)", err.Error())
 		}
-	} else {
-		// If the field type is basic, the value is set directly.
-		// If the field type is map, slice, or struct, it is assumed that the value is a json object.
-		switch fieldType.Kind() {
-		case reflect.Map, reflect.Slice, reflect.Struct:
-			if err := json.Unmarshal([]byte(stringEncodedValue), fieldPtr.Interface()); err != nil {
-				return fmt.Errorf("json unmarshal error (%s)", err.Error())
-			}
-		case reflect.String:
-			fieldPtr.Elem().SetString(stringEncodedValue)
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			parsed, err := strconv.ParseInt(stringEncodedValue, 10, fieldType.Bits())
-			if err != nil {
-				return fmt.Errorf("int parsing error (%s)", err.Error())
-			}
-			fieldPtr.Elem().SetInt(parsed)
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			parsed, err := strconv.ParseUint(stringEncodedValue, 10, fieldType.Bits())
-			if err != nil {
-				return fmt.Errorf("unsigned int parsing error (%s)", err.Error())
-			}
-			fieldPtr.Elem().SetUint(parsed)
-		case reflect.Float32, reflect.Float64:
-			parsed, err := strconv.ParseFloat(stringEncodedValue, fieldType.Bits())
-			if err != nil {
-				return fmt.Errorf("float parsing error (%s)", err.Error())
-			}
-			fieldPtr.Elem().SetFloat(parsed)
-		case reflect.Bool:
-			parsed, err := strconv.ParseBool(stringEncodedValue)
+		return nil
+	}
+
+	// If the value type is basic, the value is set directly.
+	// If the value type is map, slice, or struct, it is assumed that the value is a json object.
+	switch valueType.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Struct:
+		if err := json.Unmarshal([]byte(stringEncodedValue), valuePtr.Interface()); err != nil {
+			return fmt.Errorf("json unmarshal error (%s)", err.Error())
+		}
+	case reflect.String:
+		if enumParser, isEnum := enum.ParserFor(valueType); isEnum {
+			parsed, err := enumParser(stringEncodedValue)
 			if err != nil {
-				return fmt.Errorf("bool parsing error (%s)", err.Error())
+				return fmt.Errorf("enum parsing error (%s)", err.Error())
 			}
-			fieldPtr.Elem().SetBool(parsed)
-		default:
-			return fmt.Errorf("unsupported field type: %s", fieldType)
+			valuePtr.Elem().SetString(parsed)
+		} else {
+			valuePtr.Elem().SetString(stringEncodedValue)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(stringEncodedValue, 10, valueType.Bits())
+		if err != nil {
+			return fmt.Errorf("int parsing error (%s)", err.Error())
+		}
+		valuePtr.Elem().SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(stringEncodedValue, 10, valueType.Bits())
+		if err != nil {
+			return fmt.Errorf("unsigned int parsing error (%s)", err.Error())
 		}
+		valuePtr.Elem().SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(stringEncodedValue, valueType.Bits())
+		if err != nil {
+			return fmt.Errorf("float parsing error (%s)", err.Error())
+		}
+		valuePtr.Elem().SetFloat(parsed)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(stringEncodedValue)
+		if err != nil {
+			return fmt.Errorf("bool parsing error (%s)", err.Error())
+		}
+		valuePtr.Elem().SetBool(parsed)
+	default:
+		return fmt.Errorf("unsupported field type: %s", valueType)
 	}
 
-	// If the field is a ptr, set the ptr to the newly allocated value in fieldPtr.
-	// If the field it not a ptr, copy the contents of fieldPtr into it.
-	if originalFieldType.Kind() == reflect.Ptr {
-		structFieldValue.Set(fieldPtr)
-	} else {
-		structFieldValue.Set(fieldPtr.Elem())
+	return nil
+}
+
+// decodeByteSize parses a byte size, such as 512MiB, into the integer that valuePtr points to.
+func decodeByteSize(valuePtr reflect.Value, stringEncodedValue string) error {
+	valueType := valuePtr.Type().Elem()
+	trimmed := strings.TrimSpace(stringEncodedValue)
+	unitIndex := strings.IndexFunc(trimmed, func(r rune) bool {
+		return !unicode.IsDigit(r) && r != '.'
+	})
+	if unitIndex < 0 {
+		unitIndex = len(trimmed)
 	}
+	number, unit := trimmed[:unitIndex], strings.ToLower(strings.TrimSpace(trimmed[unitIndex:]))
 
+	multiplier, knownUnit := byteSizeUnits[unit]
+	if !knownUnit {
+		return fmt.Errorf("byte size parsing error (unknown unit '%s')", unit)
+	}
+	parsed, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return fmt.Errorf("byte size parsing error (%s)", err.Error())
+	}
+	size := parsed * multiplier
+	if size != math.Trunc(size) {
+		return fmt.Errorf("byte size parsing error (%s is not a whole number of bytes)", stringEncodedValue)
+	}
+
+	switch valueType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if size > math.MaxInt64 || valuePtr.Elem().OverflowInt(int64(size)) {
+			return fmt.Errorf("byte size parsing error (%s overflows %s)", stringEncodedValue, valueType)
+		}
+		valuePtr.Elem().SetInt(int64(size))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if size > math.MaxUint64 || valuePtr.Elem().OverflowUint(uint64(size)) {
+			return fmt.Errorf("byte size parsing error (%s overflows %s)", stringEncodedValue, valueType)
+		}
+		valuePtr.Elem().SetUint(uint64(size))
+	default:
+		return fmt.Errorf("byte sizes cannot be assigned to the type %s", valueType)
+	}
 	return nil
 }
+
+// parseTimeInLocation parses an RFC3339 time and normalizes it into the named location.
+// If the value has no offset, it is interpreted as a time in the location.
+func parseTimeInLocation(stringEncodedValue string, timezone string) (time.Time, error) {
+	location, err := time.LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("timezone loading error (%s)", err.Error())
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, stringEncodedValue)
+	if err != nil {
+		var errWithoutOffset error
+		parsed, errWithoutOffset = time.ParseInLocation("2006-01-02T15:04:05.999999999", stringEncodedValue, location)
+		if errWithoutOffset != nil {
+			return time.Time{}, fmt.Errorf("time parsing error (%s)", err.Error())
+		}
+	}
+	return parsed.In(location), nil
+}
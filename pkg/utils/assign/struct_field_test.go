@@ -1,9 +1,12 @@
 package assign_test
 
 import (
+	"net/url"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
+	_ "time/tzdata"
 
 	"github.com/TriangleSide/GoBase/pkg/test/assert"
 	"github.com/TriangleSide/GoBase/pkg/utils/assign"
@@ -64,6 +67,19 @@ func TestAssign(t *testing.T) {
 		UnmarshallPtrValue *unmarshallTestStruct
 		TimePtrValue       *time.Time
 
+		UTCTimeValue    time.Time  `timezone:"UTC"`
+		TorontoTimePtr  *time.Time `timezone:"America/Toronto"`
+		InvalidZoneTime time.Time  `timezone:"Not/AZone"`
+
+		DurationValue    time.Duration
+		DurationPtrValue *time.Duration
+		URLValue         url.URL
+		URLPtrValue      *url.URL
+		ByteSizeValue    int64   `unit:"bytes"`
+		ByteSizePtrValue *uint32 `unit:"bytes"`
+		ByteSizeInt8     int8    `unit:"bytes"`
+		ByteSizeString   string  `unit:"bytes"`
+
 		ListStringValue []string
 		ListIntValue    []int
 		ListFloatValue  []float64
@@ -105,6 +121,125 @@ func TestAssign(t *testing.T) {
 		assert.Equals(t, expectedTime, *values.TimePtrValue)
 	})
 
+	t.Run("when a time with an offset is set it should preserve the offset", func(t *testing.T) {
+		t.Parallel()
+		values := &testStruct{}
+		assert.NoError(t, assign.StructField(values, "TimeValue", "2024-01-01T00:00:00+02:00"))
+		_, offset := values.TimeValue.Zone()
+		assert.Equals(t, offset, 2*60*60)
+		assert.True(t, values.TimeValue.Equal(time.Date(2023, 12, 31, 22, 0, 0, 0, time.UTC)))
+	})
+
+	t.Run("when a time field has a timezone tag it should normalize the time into the location", func(t *testing.T) {
+		t.Parallel()
+		toronto, err := time.LoadLocation("America/Toronto")
+		assert.NoError(t, err)
+		subTests := []struct {
+			fieldName string
+			value     string
+			expected  time.Time
+		}{
+			{"UTCTimeValue", "2024-01-01T00:00:00+02:00", time.Date(2023, 12, 31, 22, 0, 0, 0, time.UTC)},
+			{"UTCTimeValue", "2024-01-01T00:00:00.5Z", time.Date(2024, 1, 1, 0, 0, 0, 500000000, time.UTC)},
+			{"UTCTimeValue", "2024-01-01T00:00:00", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+			{"TorontoTimePtr", "2024-01-01T12:00:00Z", time.Date(2024, 1, 1, 7, 0, 0, 0, toronto)},
+			{"TorontoTimePtr", "2024-07-01T12:00:00", time.Date(2024, 7, 1, 12, 0, 0, 0, toronto)},
+		}
+		for _, subTest := range subTests {
+			values := &testStruct{}
+			assert.NoError(t, assign.StructField(values, subTest.fieldName, subTest.value))
+			var parsed time.Time
+			if subTest.fieldName == "TorontoTimePtr" {
+				parsed = *values.TorontoTimePtr
+			} else {
+				parsed = values.UTCTimeValue
+			}
+			assert.True(t, parsed.Equal(subTest.expected))
+			assert.Equals(t, parsed.Location().String(), subTest.expected.Location().String())
+		}
+	})
+
+	t.Run("when a time with a timezone tag can't be parsed it should return an error", func(t *testing.T) {
+		t.Parallel()
+		values := &testStruct{}
+		assert.ErrorPart(t, assign.StructField(values, "UTCTimeValue", "not a time"), "time parsing error")
+		assert.ErrorPart(t, assign.StructField(values, "InvalidZoneTime", "2024-01-01T00:00:00Z"), "timezone loading error")
+	})
+
+	t.Run("when a duration is set it should parse it as a duration or as nanoseconds", func(t *testing.T) {
+		t.Parallel()
+		values := &testStruct{}
+		assert.NoError(t, assign.StructField(values, "DurationValue", "1m30s"))
+		assert.Equals(t, values.DurationValue, 90*time.Second)
+		assert.NoError(t, assign.StructField(values, "DurationPtrValue", "250ms"))
+		assert.Equals(t, *values.DurationPtrValue, 250*time.Millisecond)
+		assert.NoError(t, assign.StructField(values, "DurationValue", "1000"))
+		assert.Equals(t, values.DurationValue, time.Microsecond)
+		assert.ErrorPart(t, assign.StructField(values, "DurationValue", "thirty seconds"), "duration parsing error")
+	})
+
+	t.Run("when a url is set it should parse it", func(t *testing.T) {
+		t.Parallel()
+		values := &testStruct{}
+		assert.NoError(t, assign.StructField(values, "URLValue", "https://user@example.com:8443/path?q=1"))
+		assert.Equals(t, values.URLValue.Scheme, "https")
+		assert.Equals(t, values.URLValue.Host, "example.com:8443")
+		assert.Equals(t, values.URLValue.Path, "/path")
+		assert.Equals(t, values.URLValue.User.Username(), "user")
+		assert.NoError(t, assign.StructField(values, "URLPtrValue", "postgres://localhost/db"))
+		assert.Equals(t, values.URLPtrValue.String(), "postgres://localhost/db")
+		assert.ErrorPart(t, assign.StructField(values, "URLPtrValue", "http://[::1"), "url parsing error")
+	})
+
+	t.Run("when a byte size is set on a field with the bytes unit it should parse it", func(t *testing.T) {
+		t.Parallel()
+		subTests := []struct {
+			value    string
+			expected int64
+		}{
+			{"512", 512},
+			{"512B", 512},
+			{"1KB", 1000},
+			{"1kib", 1024},
+			{"512MiB", 512 << 20},
+			{"1.5GiB", 3 << 29},
+			{"2 GB", 2e9},
+			{"1TiB", 1 << 40},
+			{"1PB", 1e15},
+		}
+		for _, subTest := range subTests {
+			values := &testStruct{}
+			assert.NoError(t, assign.StructField(values, "ByteSizeValue", subTest.value))
+			assert.Equals(t, values.ByteSizeValue, subTest.expected)
+		}
+		values := &testStruct{}
+		assert.NoError(t, assign.StructField(values, "ByteSizePtrValue", "4KiB"))
+		assert.Equals(t, *values.ByteSizePtrValue, uint32(4096))
+	})
+
+	t.Run("when an invalid byte size is set it should return an error", func(t *testing.T) {
+		t.Parallel()
+		subTests := []struct {
+			fieldName string
+			value     string
+			errorPart string
+		}{
+			{"ByteSizeValue", "12XB", "unknown unit 'xb'"},
+			{"ByteSizeValue", "MiB", "byte size parsing error"},
+			{"ByteSizeValue", "1.1.1KB", "byte size parsing error"},
+			{"ByteSizeValue", "-1KB", "unknown unit '-1kb'"},
+			{"ByteSizeValue", "1.5B", "not a whole number of bytes"},
+			{"ByteSizeValue", "100000PiB", "overflows int64"},
+			{"ByteSizePtrValue", "8GiB", "overflows uint32"},
+			{"ByteSizeInt8", "1KB", "overflows int8"},
+			{"ByteSizeString", "1KB", "byte sizes cannot be assigned to the type string"},
+		}
+		for _, subTest := range subTests {
+			values := &testStruct{}
+			assert.ErrorPart(t, assign.StructField(values, subTest.fieldName, subTest.value), subTest.errorPart)
+		}
+	})
+
 	t.Run("when normal value assignments are done it should assign values correctly", func(t *testing.T) {
 		t.Parallel()
 		subTests := []struct {
@@ -189,3 +324,98 @@ func TestAssign(t *testing.T) {
 		}
 	})
 }
+
+func TestAssignValues(t *testing.T) {
+	t.Parallel()
+
+	type testElement struct {
+		Value string `json:"value"`
+	}
+
+	type testStruct struct {
+		Strings      []string
+		Ints         *[]int
+		IntPtrs      []*int
+		Elements     []testElement
+		Unmarshalers []unmarshallTestStruct
+		Times        []time.Time `timezone:"UTC"`
+		NotSlice     string
+	}
+
+	t.Run("when values are assigned to slice fields it should decode each element", func(t *testing.T) {
+		t.Parallel()
+		values := &testStruct{}
+		assert.NoError(t, assign.StructFieldValues(values, "Strings", []string{"a", "b"}))
+		assert.NoError(t, assign.StructFieldValues(values, "Ints", []string{"1", "2"}))
+		assert.NoError(t, assign.StructFieldValues(values, "IntPtrs", []string{"3"}))
+		assert.NoError(t, assign.StructFieldValues(values, "Elements", []string{`{"value":"x"}`}))
+		assert.NoError(t, assign.StructFieldValues(values, "Unmarshalers", []string{"text"}))
+		assert.NoError(t, assign.StructFieldValues(values, "Times", []string{"2024-01-01T05:00:00+05:00"}))
+		assert.Equals(t, values.Strings, []string{"a", "b"})
+		assert.Equals(t, *values.Ints, []int{1, 2})
+		assert.Equals(t, *values.IntPtrs[0], 3)
+		assert.Equals(t, values.Elements, []testElement{{Value: "x"}})
+		assert.Equals(t, values.Unmarshalers[0].Value, "text")
+		assert.Equals(t, values.Times[0], time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	})
+
+	t.Run("when no values are assigned it should set an empty slice", func(t *testing.T) {
+		t.Parallel()
+		values := &testStruct{Strings: []string{"old"}}
+		assert.NoError(t, assign.StructFieldValues(values, "Strings", []string{}))
+		assert.Equals(t, values.Strings, []string{})
+	})
+
+	t.Run("when an element can't be parsed it should return an error with its index", func(t *testing.T) {
+		t.Parallel()
+		err := assign.StructFieldValues(&testStruct{}, "Ints", []string{"1", "two"})
+		assert.ErrorPart(t, err, "element 1 (int parsing error")
+	})
+
+	t.Run("when the field is not a slice it should return an error", func(t *testing.T) {
+		t.Parallel()
+		err := assign.StructFieldValues(&testStruct{}, "NotSlice", []string{"a"})
+		assert.ErrorPart(t, err, "the field type string is not a slice")
+	})
+
+	t.Run("when the field does not exist it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			_ = assign.StructFieldValues(&testStruct{}, "Missing", []string{"a"})
+		}, "no field 'Missing'")
+	})
+}
+
+func TestReflectedStructField(t *testing.T) {
+	t.Parallel()
+
+	type embedded struct {
+		Embedded int
+	}
+	type testStruct struct {
+		embedded
+		Value *string
+	}
+
+	t.Run("when a field is assigned through the reflected value it should set the field", func(t *testing.T) {
+		t.Parallel()
+		obj := &testStruct{}
+		assert.NoError(t, assign.ReflectedStructField(reflect.ValueOf(obj), "Value", "value"))
+		assert.NoError(t, assign.ReflectedStructField(reflect.ValueOf(obj), "Embedded", "1"))
+		assert.Equals(t, *obj.Value, "value")
+		assert.Equals(t, obj.Embedded, 1)
+	})
+
+	t.Run("when the value can't be parsed it should return an error", func(t *testing.T) {
+		t.Parallel()
+		err := assign.ReflectedStructField(reflect.ValueOf(&testStruct{}), "Embedded", "one")
+		assert.ErrorPart(t, err, "int parsing error")
+	})
+
+	t.Run("when the reflected value is not a pointer to a struct it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			_ = assign.ReflectedStructField(reflect.ValueOf(testStruct{}), "Value", "value")
+		}, "obj must be a pointer to a struct")
+	})
+}
@@ -0,0 +1,34 @@
+package ctxkey
+
+import (
+	"context"
+)
+
+// Key is a typed key for storing and retrieving values from a context.Context.
+// Each call to New returns a distinct key, so values cannot collide even if the names are the same.
+type Key[T any] struct {
+	name string
+}
+
+// New allocates a Key for values of type T. The name is used for debugging purposes.
+func New[T any](name string) *Key[T] {
+	return &Key[T]{
+		name: name,
+	}
+}
+
+// String returns the name of the key.
+func (k *Key[T]) String() string {
+	return k.name
+}
+
+// WithValue returns a copy of the context that holds the value for the key.
+func (k *Key[T]) WithValue(ctx context.Context, value T) context.Context {
+	return context.WithValue(ctx, k, value)
+}
+
+// Value returns the value of the key in the context and whether it was found.
+func (k *Key[T]) Value(ctx context.Context) (T, bool) {
+	value, found := ctx.Value(k).(T)
+	return value, found
+}
@@ -0,0 +1,49 @@
+package ctxkey_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+	"github.com/TriangleSide/GoBase/pkg/utils/ctxkey"
+)
+
+func TestContextKey(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when a value is set on a context it should be returned by the key", func(t *testing.T) {
+		t.Parallel()
+		key := ctxkey.New[int]("key")
+		ctx := key.WithValue(context.Background(), 123)
+		value, found := key.Value(ctx)
+		assert.True(t, found)
+		assert.Equals(t, value, 123)
+	})
+
+	t.Run("when a value is not set on a context it should return the zero value", func(t *testing.T) {
+		t.Parallel()
+		key := ctxkey.New[*int]("key")
+		value, found := key.Value(context.Background())
+		assert.False(t, found)
+		assert.Nil(t, value)
+	})
+
+	t.Run("when two keys have the same name they should not collide", func(t *testing.T) {
+		t.Parallel()
+		first := ctxkey.New[string]("key")
+		second := ctxkey.New[string]("key")
+		ctx := first.WithValue(context.Background(), "first")
+		ctx = second.WithValue(ctx, "second")
+		firstValue, firstFound := first.Value(ctx)
+		assert.True(t, firstFound)
+		assert.Equals(t, firstValue, "first")
+		secondValue, secondFound := second.Value(ctx)
+		assert.True(t, secondFound)
+		assert.Equals(t, secondValue, "second")
+	})
+
+	t.Run("when the key is formatted as a string it should return its name", func(t *testing.T) {
+		t.Parallel()
+		assert.Equals(t, ctxkey.New[string]("name").String(), "name")
+	})
+}
@@ -0,0 +1,110 @@
+package enum
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+var (
+	// typeToParser holds the parse functions of the registered enum types.
+	typeToParser = sync.Map{}
+)
+
+// config is configured by the Option functions.
+type config struct {
+	caseInsensitive bool
+}
+
+// Option is used to configure an Enum.
+type Option func(cfg *config)
+
+// WithCaseInsensitive makes the Enum match values regardless of their case.
+// For example, TLS, tls and Tls would all be parsed into the canonical value tls.
+func WithCaseInsensitive() Option {
+	return func(cfg *config) {
+		cfg.caseInsensitive = true
+	}
+}
+
+// Enum is the set of valid values for a string based type.
+type Enum[T ~string] struct {
+	values          []T
+	caseInsensitive bool
+}
+
+// New allocates an Enum with its canonical values.
+// If values are empty or not unique, this function panics.
+func New[T ~string](values []T, opts ...Option) *Enum[T] {
+	cfg := &config{
+		caseInsensitive: false,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	if len(values) == 0 {
+		panic("an enum must have at least one value")
+	}
+	seen := make(map[string]bool, len(values))
+	for _, value := range values {
+		key := string(value)
+		if cfg.caseInsensitive {
+			key = strings.ToLower(key)
+		}
+		if seen[key] {
+			panic(fmt.Sprintf("the enum value '%s' is not unique", value))
+		}
+		seen[key] = true
+	}
+
+	return &Enum[T]{
+		values:          slices.Clone(values),
+		caseInsensitive: cfg.caseInsensitive,
+	}
+}
+
+// Values returns a copy of the canonical values of the Enum.
+func (e *Enum[T]) Values() []T {
+	return slices.Clone(e.values)
+}
+
+// Parse returns the canonical value that matches the string.
+// An error listing the valid options is returned if there is no match.
+func (e *Enum[T]) Parse(value string) (T, error) {
+	for _, candidate := range e.values {
+		if string(candidate) == value || (e.caseInsensitive && strings.EqualFold(string(candidate), value)) {
+			return candidate, nil
+		}
+	}
+	validOptions := make([]string, 0, len(e.values))
+	for _, candidate := range e.values {
+		validOptions = append(validOptions, string(candidate))
+	}
+	return "", fmt.Errorf("invalid value '%s', the valid options are: %s", value, strings.Join(validOptions, ", "))
+}
+
+// MustRegister associates the Enum with its type so that utilities that decode strings
+// into struct fields, such as the HTTP parameter decoder, use it to parse the value.
+// If the type is already registered, this function panics.
+func MustRegister[T ~string](e *Enum[T]) {
+	reflectType := reflect.TypeFor[T]()
+	parser := func(value string) (string, error) {
+		parsed, err := e.Parse(value)
+		return string(parsed), err
+	}
+	if _, alreadyRegistered := typeToParser.LoadOrStore(reflectType, parser); alreadyRegistered {
+		panic(fmt.Sprintf("the enum type '%s' has already been registered", reflectType.String()))
+	}
+}
+
+// ParserFor returns the parse function of a registered enum type.
+func ParserFor(reflectType reflect.Type) (func(value string) (string, error), bool) {
+	parserNotCast, found := typeToParser.Load(reflectType)
+	if !found {
+		return nil, false
+	}
+	return parserNotCast.(func(value string) (string, error)), true
+}
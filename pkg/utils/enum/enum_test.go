@@ -0,0 +1,93 @@
+package enum_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+	"github.com/TriangleSide/GoBase/pkg/utils/enum"
+)
+
+type testMode string
+
+type testRegisteredMode string
+
+type testUnregisteredMode string
+
+func TestEnum(t *testing.T) {
+	t.Parallel()
+
+	t.Run("when an enum is created without values it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			enum.New[testMode](nil)
+		}, "an enum must have at least one value")
+	})
+
+	t.Run("when an enum is created with duplicate values it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			enum.New([]testMode{"a", "a"})
+		}, "the enum value 'a' is not unique")
+	})
+
+	t.Run("when a case insensitive enum is created with values that only differ by case it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			enum.New([]testMode{"a", "A"}, enum.WithCaseInsensitive())
+		}, "the enum value 'A' is not unique")
+	})
+
+	t.Run("when a case sensitive enum parses a value with a different case it should fail", func(t *testing.T) {
+		t.Parallel()
+		e := enum.New([]testMode{"off", "tls"})
+		value, err := e.Parse("TLS")
+		assert.ErrorExact(t, err, "invalid value 'TLS', the valid options are: off, tls")
+		assert.Equals(t, value, testMode(""))
+	})
+
+	t.Run("when a case insensitive enum parses mixed case values it should return the canonical value", func(t *testing.T) {
+		t.Parallel()
+		e := enum.New([]testMode{"off", "tls", "mutual_tls"}, enum.WithCaseInsensitive())
+		for _, input := range []string{"TLS", "tls", "Tls", "tLs"} {
+			value, err := e.Parse(input)
+			assert.NoError(t, err)
+			assert.Equals(t, value, testMode("tls"))
+		}
+	})
+
+	t.Run("when a case insensitive enum parses an unknown value it should list the valid options", func(t *testing.T) {
+		t.Parallel()
+		e := enum.New([]testMode{"off", "tls"}, enum.WithCaseInsensitive())
+		_, err := e.Parse("ssl")
+		assert.ErrorExact(t, err, "invalid value 'ssl', the valid options are: off, tls")
+	})
+
+	t.Run("when the values are returned it should be a copy", func(t *testing.T) {
+		t.Parallel()
+		e := enum.New([]testMode{"off", "tls"})
+		values := e.Values()
+		values[0] = "changed"
+		assert.Equals(t, e.Values(), []testMode{"off", "tls"})
+	})
+
+	t.Run("when an enum is registered it should have a parser for its type", func(t *testing.T) {
+		t.Parallel()
+		enum.MustRegister(enum.New([]testRegisteredMode{"a", "b"}, enum.WithCaseInsensitive()))
+		parser, found := enum.ParserFor(reflect.TypeFor[testRegisteredMode]())
+		assert.True(t, found)
+		value, err := parser("B")
+		assert.NoError(t, err)
+		assert.Equals(t, value, "b")
+		assert.PanicPart(t, func() {
+			enum.MustRegister(enum.New([]testRegisteredMode{"c"}))
+		}, "has already been registered")
+	})
+
+	t.Run("when an enum is not registered it should not have a parser", func(t *testing.T) {
+		t.Parallel()
+		parser, found := enum.ParserFor(reflect.TypeFor[testUnregisteredMode]())
+		assert.False(t, found)
+		assert.Nil(t, parser)
+	})
+}
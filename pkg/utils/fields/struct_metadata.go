@@ -3,8 +3,9 @@ package fields
 import (
 	"errors"
 	"fmt"
+	"iter"
 	"reflect"
-	"regexp"
+	"slices"
 	"time"
 
 	"github.com/TriangleSide/GoBase/pkg/datastructures/cache"
@@ -12,36 +13,104 @@ import (
 )
 
 var (
-	// tagMatchRegex matches all tag entries on a struct field.
-	tagMatchRegex = regexp.MustCompile(`(\w+):"([^"]*)"`)
-
 	// typeToMetadataCache is used to cache the result of the StructMetadata function.
-	typeToMetadataCache = cache.New[reflect.Type, *readonlymap.ReadOnlyMap[string, *FieldMetadata]]()
+	typeToMetadataCache = cache.NewSharded[reflect.Type, *readonlymap.ReadOnlyMap[string, *FieldMetadata]](cache.DefaultShardCount)
 )
 
 // FieldMetadata is the metadata extracted from struct fields.
 type FieldMetadata struct {
-	Type      reflect.Type
-	Tags      map[string]string
+	// Name is the name of the field.
+	Name string
+
+	// Type is the type of the field.
+	Type reflect.Type
+
+	// Tags maps the keys of the field's tags to their values.
+	Tags map[string]string
+
+	// Anonymous is the chain of embedded anonymous structs that contain the field.
 	Anonymous []string
+
+	// Index is the index path of the field for reflect.Value.FieldByIndex. It goes through the embedded structs.
+	Index []int
+
+	// Order is the position of the field in declaration order, where the fields of an embedded struct
+	// take the place of the embedded struct.
+	Order int
+
+	// Exported is true if the field is exported.
+	Exported bool
+
+	// JSONName is the name of the field in JSON. It is empty if the field is skipped with the tag value "-".
+	JSONName string
+
+	// parsedTags maps the keys of the field's tags to their parsed values.
+	parsedTags map[string]*Tag
+}
+
+// Tag returns the parsed value of a tag, with its name and options. It returns false if the field doesn't have the tag.
+// The parsed tags are built once with the metadata, so they can be fetched repeatedly.
+func (m *FieldMetadata) Tag(tag string) (*Tag, bool) {
+	parsed, hasTag := m.parsedTags[tag]
+	return parsed, hasTag
+}
+
+// TagName returns the name of the field for a tag whose value is a name followed by comma separated options,
+// like json:"name,omitempty". If the tag has no name, the field name is used. It returns false if the field is
+// skipped with the tag value "-". Like encoding/json, the tag value "-," names the field "-".
+func (m *FieldMetadata) TagName(tag string) (string, bool) {
+	parsed, hasTag := m.Tag(tag)
+	if !hasTag || parsed.Name == "" {
+		return m.Name, true
+	}
+	if parsed.Value == "-" {
+		return "", false
+	}
+	return parsed.Name, true
 }
 
 // StructMetadata returns a map of a structs field names to their respective metadata.
 func StructMetadata[T any]() *readonlymap.ReadOnlyMap[string, *FieldMetadata] {
-	reflectType := reflect.TypeOf(*new(T))
+	return StructMetadataFromType(reflect.TypeOf(*new(T)))
+}
+
+// StructMetadataFromType returns a map of a structs field names to their respective metadata.
+// It is the same as StructMetadata, but for cases where the type is only known at runtime.
+func StructMetadataFromType(reflectType reflect.Type) *readonlymap.ReadOnlyMap[string, *FieldMetadata] {
 	fieldsToMetadata, _ := typeToMetadataCache.GetOrSet(reflectType, func(reflectType reflect.Type) (*readonlymap.ReadOnlyMap[string, *FieldMetadata], *time.Duration, error) {
 		fieldsToMetadata := make(map[string]*FieldMetadata)
-		processType(reflectType, fieldsToMetadata, make([]string, 0))
+		order := 0
+		processType(reflectType, fieldsToMetadata, make([]string, 0), make([]int, 0), &order)
 		readOnlyMap := readonlymap.NewBuilder[string, *FieldMetadata]().SetMap(fieldsToMetadata).Build()
 		return readOnlyMap, nil, nil
 	})
 	return fieldsToMetadata
 }
 
+// InDeclarationOrder iterates over the metadata of a struct in the order the fields are declared.
+// The fields of an embedded struct take the place of the embedded struct.
+func InDeclarationOrder(fieldsToMetadata *readonlymap.ReadOnlyMap[string, *FieldMetadata]) iter.Seq2[string, *FieldMetadata] {
+	ordered := make([]*FieldMetadata, 0, fieldsToMetadata.Size())
+	for _, fieldMetadata := range fieldsToMetadata.Iterator() {
+		ordered = append(ordered, fieldMetadata)
+	}
+	slices.SortFunc(ordered, func(a *FieldMetadata, b *FieldMetadata) int {
+		return a.Order - b.Order
+	})
+	return func(yield func(string, *FieldMetadata) bool) {
+		for _, fieldMetadata := range ordered {
+			if !yield(fieldMetadata.Name, fieldMetadata) {
+				return
+			}
+		}
+	}
+}
+
 // processType takes a struct type, lists all of its fields, and builds the metadata for it.
-// If the struct contains an embedded anonymous struct, it appends its name to the anonymous name chain.
+// If the struct contains an embedded anonymous struct, it appends its name to the anonymous name chain
+// and its index to the index path. The order counts the fields in declaration order.
 // If a field name is not unique, a panic occurs. This includes field names of the anonymous structs.
-func processType(reflectType reflect.Type, fieldsToMetadata map[string]*FieldMetadata, anonymousChain []string) {
+func processType(reflectType reflect.Type, fieldsToMetadata map[string]*FieldMetadata, anonymousChain []string, indexPath []int, order *int) {
 	if reflectType.Kind() == reflect.Ptr {
 		reflectType = reflectType.Elem()
 	}
@@ -56,9 +125,13 @@ func processType(reflectType reflect.Type, fieldsToMetadata map[string]*FieldMet
 		anonymousChainCopy := make([]string, len(anonymousChain))
 		copy(anonymousChainCopy, anonymousChain)
 
+		fieldIndexPath := make([]int, len(indexPath), len(indexPath)+1)
+		copy(fieldIndexPath, indexPath)
+		fieldIndexPath = append(fieldIndexPath, fieldIndex)
+
 		if field.Anonymous {
 			anonymousChainCopy = append(anonymousChainCopy, field.Name)
-			processType(field.Type, fieldsToMetadata, anonymousChainCopy)
+			processType(field.Type, fieldsToMetadata, anonymousChainCopy, fieldIndexPath, order)
 			continue
 		}
 
@@ -67,18 +140,20 @@ func processType(reflectType reflect.Type, fieldsToMetadata map[string]*FieldMet
 		}
 
 		metadata := &FieldMetadata{}
+		metadata.Name = field.Name
 		metadata.Type = field.Type
-		metadata.Tags = make(map[string]string)
 		metadata.Anonymous = anonymousChainCopy
-
-		if len(string(field.Tag)) != 0 {
-			matches := tagMatchRegex.FindAllStringSubmatch(string(field.Tag), -1)
-			for _, match := range matches {
-				tagKey := match[1]
-				tagValue := match[2]
-				metadata.Tags[tagKey] = tagValue
-			}
+		metadata.Index = fieldIndexPath
+		metadata.Order = *order
+		metadata.Exported = field.IsExported()
+		*order++
+
+		metadata.Tags = parseStructTag(field.Tag)
+		metadata.parsedTags = make(map[string]*Tag, len(metadata.Tags))
+		for tagKey, tagValue := range metadata.Tags {
+			metadata.parsedTags[tagKey] = parseTag(tagValue)
 		}
+		metadata.JSONName, _ = metadata.TagName("json")
 
 		fieldsToMetadata[field.Name] = metadata
 	}
@@ -23,6 +23,15 @@ func TestStructMetadata(t *testing.T) {
 		assert.Equals(t, metadata.Size(), 1)
 	})
 
+	t.Run("when the metadata is fetched from a reflect type it should be the same as the generic", func(t *testing.T) {
+		type testStruct struct {
+			Value string `tag:"value"`
+		}
+		fromType := fields.StructMetadataFromType(reflect.TypeFor[testStruct]())
+		assert.Equals(t, fromType, fields.StructMetadata[testStruct]())
+		assert.Equals(t, fromType.Get("Value").Tags["tag"], "value")
+	})
+
 	t.Run("when the struct is empty it should return an empty map", func(t *testing.T) {
 		metadata := fields.StructMetadata[struct{}]()
 		assert.Equals(t, metadata.Size(), 0)
@@ -136,6 +145,174 @@ func TestStructMetadata(t *testing.T) {
 		assert.Equals(t, len(outerField.Anonymous), 0)
 	})
 
+	t.Run("when a struct has embedded structs it should set the index paths, order and export status of the fields", func(t *testing.T) {
+		type deepStruct struct {
+			DeepField string
+		}
+
+		type embeddedStruct struct {
+			deepStruct
+			EmbeddedField string
+		}
+
+		type outerStruct struct {
+			FirstField string
+			*embeddedStruct
+			lastField string
+		}
+
+		metadata := fields.StructMetadata[outerStruct]()
+		firstField := metadata.Get("FirstField")
+		assert.Equals(t, firstField.Name, "FirstField")
+		assert.Equals(t, firstField.Index, []int{0})
+		assert.Equals(t, firstField.Order, 0)
+		assert.True(t, firstField.Exported)
+
+		deepField := metadata.Get("DeepField")
+		assert.Equals(t, deepField.Name, "DeepField")
+		assert.Equals(t, deepField.Index, []int{1, 0, 0})
+		assert.Equals(t, deepField.Order, 1)
+		assert.True(t, deepField.Exported)
+
+		embeddedField := metadata.Get("EmbeddedField")
+		assert.Equals(t, embeddedField.Index, []int{1, 1})
+		assert.Equals(t, embeddedField.Order, 2)
+
+		lastField := metadata.Get("lastField")
+		assert.Equals(t, lastField.Index, []int{2})
+		assert.Equals(t, lastField.Order, 3)
+		assert.False(t, lastField.Exported)
+
+		instance := outerStruct{embeddedStruct: &embeddedStruct{EmbeddedField: "embedded"}}
+		assert.Equals(t, reflect.ValueOf(instance).FieldByIndex(embeddedField.Index).Interface(), "embedded")
+	})
+
+	t.Run("when the metadata is iterated in declaration order it should yield the fields in the order they are declared", func(t *testing.T) {
+		type embeddedStruct struct {
+			B string
+			A string
+		}
+
+		type outerStruct struct {
+			Z string
+			embeddedStruct
+			Y string
+			X string
+		}
+
+		names := make([]string, 0)
+		for fieldName, fieldMetadata := range fields.InDeclarationOrder(fields.StructMetadata[outerStruct]()) {
+			assert.Equals(t, fieldName, fieldMetadata.Name)
+			names = append(names, fieldName)
+		}
+		assert.Equals(t, names, []string{"Z", "B", "A", "Y", "X"})
+
+		count := 0
+		for range fields.InDeclarationOrder(fields.StructMetadata[outerStruct]()) {
+			count++
+			break
+		}
+		assert.Equals(t, count, 1)
+	})
+
+	t.Run("when a field has tags with names it should resolve the names of the field", func(t *testing.T) {
+		type testStruct struct {
+			Named     string `json:"named,omitempty" urlQuery:"q" httpHeader:"X-Named"`
+			Unnamed   string `json:",omitempty" urlQuery:""`
+			Skipped   string `json:"-" httpHeader:"-"`
+			Untagged  string
+			DashNamed string `json:"-,"`
+		}
+
+		metadata := fields.StructMetadata[testStruct]()
+		named := metadata.Get("Named")
+		assert.Equals(t, named.JSONName, "named")
+		queryName, hasQueryName := named.TagName("urlQuery")
+		assert.True(t, hasQueryName)
+		assert.Equals(t, queryName, "q")
+		headerName, hasHeaderName := named.TagName("httpHeader")
+		assert.True(t, hasHeaderName)
+		assert.Equals(t, headerName, "X-Named")
+
+		unnamed := metadata.Get("Unnamed")
+		assert.Equals(t, unnamed.JSONName, "Unnamed")
+		queryName, hasQueryName = unnamed.TagName("urlQuery")
+		assert.True(t, hasQueryName)
+		assert.Equals(t, queryName, "Unnamed")
+
+		skipped := metadata.Get("Skipped")
+		assert.Equals(t, skipped.JSONName, "")
+		_, hasHeaderName = skipped.TagName("httpHeader")
+		assert.False(t, hasHeaderName)
+
+		assert.Equals(t, metadata.Get("Untagged").JSONName, "Untagged")
+		assert.Equals(t, metadata.Get("DashNamed").JSONName, "-")
+	})
+
+	t.Run("when a tag has escaped quotes and keys with symbols it should parse them like reflect.StructTag", func(t *testing.T) {
+		type testStruct struct {
+			Value string `quoted:"a\"b" x-key:"dash" empty:"" spaced:"a b"   unicode:"\u00e9"`
+		}
+		valueField := fields.StructMetadata[testStruct]().Get("Value")
+		assert.Equals(t, valueField.Tags, map[string]string{
+			"quoted":  `a"b`,
+			"x-key":   "dash",
+			"empty":   "",
+			"spaced":  "a b",
+			"unicode": "é",
+		})
+		field, _ := reflect.TypeFor[testStruct]().FieldByName("Value")
+		for key, value := range valueField.Tags {
+			assert.Equals(t, field.Tag.Get(key), value)
+		}
+	})
+
+	t.Run("when a tag is malformed it should keep the pairs before the malformed pair", func(t *testing.T) {
+		for tag, expected := range map[string]map[string]string{
+			`a:"1" b`:             {"a": "1"},
+			`a:"1" b:2`:           {"a": "1"},
+			`a:"1" b:"2`:          {"a": "1"},
+			`a:"1" :"2"`:          {"a": "1"},
+			`a:"1" b:"\q"`:        {"a": "1"},
+			`a:"1"b:"2"`:          {"a": "1", "b": "2"},
+			` a:"1" `:             {"a": "1"},
+			`a:"1" "b":"2"`:       {"a": "1"},
+			`a:"1" b:"2" c:`:      {"a": "1", "b": "2"},
+			"a:\"1\" b\x7f:\"2\"": {"a": "1"},
+		} {
+			structType := reflect.StructOf([]reflect.StructField{{Name: "Value", Type: reflect.TypeFor[string](), Tag: reflect.StructTag(tag)}})
+			assert.Equals(t, fields.StructMetadataFromType(structType).Get("Value").Tags, expected)
+		}
+	})
+
+	t.Run("when a tag has options it should expose its name and options", func(t *testing.T) {
+		type testStruct struct {
+			Value string `json:"value,omitempty,string" validate:"required" empty:""`
+		}
+		valueField := fields.StructMetadata[testStruct]().Get("Value")
+
+		jsonTag, hasJSONTag := valueField.Tag("json")
+		assert.True(t, hasJSONTag)
+		assert.Equals(t, jsonTag, &fields.Tag{Value: "value,omitempty,string", Name: "value", Options: []string{"omitempty", "string"}})
+		assert.True(t, jsonTag.HasOption("omitempty"))
+		assert.False(t, jsonTag.HasOption("value"))
+
+		validateTag, hasValidateTag := valueField.Tag("validate")
+		assert.True(t, hasValidateTag)
+		assert.Equals(t, validateTag.Name, "required")
+		assert.Equals(t, len(validateTag.Options), 0)
+
+		emptyTag, hasEmptyTag := valueField.Tag("empty")
+		assert.True(t, hasEmptyTag)
+		assert.Equals(t, emptyTag.Name, "")
+
+		_, hasMissingTag := valueField.Tag("missing")
+		assert.False(t, hasMissingTag)
+
+		cachedTag, _ := fields.StructMetadata[testStruct]().Get("Value").Tag("json")
+		assert.True(t, cachedTag == jsonTag)
+	})
+
 	t.Run("when a struct and a nested struct both have fields with the same name it should panic", func(t *testing.T) {
 		type embeddedStruct struct {
 			Field string
@@ -0,0 +1,87 @@
+package fields
+
+import (
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Tag is the parsed value of a struct field tag whose value is a name followed by comma separated options,
+// like json:"name,omitempty".
+type Tag struct {
+	// Value is the complete value of the tag.
+	Value string
+
+	// Name is the part of the value before the first comma.
+	Name string
+
+	// Options are the parts of the value after the first comma.
+	Options []string
+}
+
+// HasOption returns true if the tag has the option, like omitempty.
+func (t *Tag) HasOption(option string) bool {
+	return slices.Contains(t.Options, option)
+}
+
+// parseTag splits the value of a tag into its name and options.
+func parseTag(value string) *Tag {
+	parts := strings.Split(value, ",")
+	return &Tag{
+		Value:   value,
+		Name:    parts[0],
+		Options: parts[1:],
+	}
+}
+
+// parseStructTag parses all the key and value pairs of a struct tag. It follows the conventions of
+// reflect.StructTag, so the values are unquoted like Go strings and can contain escaped quotes.
+// Parsing stops at the first malformed pair, like reflect.StructTag.Lookup does.
+func parseStructTag(tag reflect.StructTag) map[string]string {
+	tags := make(map[string]string)
+	remaining := string(tag)
+	for remaining != "" {
+		// Skip the leading spaces.
+		i := 0
+		for i < len(remaining) && remaining[i] == ' ' {
+			i++
+		}
+		remaining = remaining[i:]
+		if remaining == "" {
+			break
+		}
+
+		// A key is a non-empty string of non-control characters other than space, quote, and colon.
+		i = 0
+		for i < len(remaining) && remaining[i] > ' ' && remaining[i] != ':' && remaining[i] != '"' && remaining[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(remaining) || remaining[i] != ':' || remaining[i+1] != '"' {
+			break
+		}
+		key := remaining[:i]
+		remaining = remaining[i+1:]
+
+		// The value is a quoted string that ends at the first unescaped quote.
+		i = 1
+		for i < len(remaining) && remaining[i] != '"' {
+			if remaining[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(remaining) {
+			break
+		}
+		quotedValue := remaining[:i+1]
+		remaining = remaining[i+1:]
+
+		value, err := strconv.Unquote(quotedValue)
+		if err != nil {
+			break
+		}
+		tags[key] = value
+	}
+	return tags
+}
@@ -0,0 +1,163 @@
+package structs
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Cloner is implemented by types that need a custom copy, such as types that hold resources that can't be shared.
+// Clone must return a value of the same type as its receiver, or a pointer to it. It must not call the Clone
+// function on its receiver, since that would call Cloner again.
+type Cloner interface {
+	Clone() any
+}
+
+// cloneKey identifies a reference that was already copied. The type is part of the key because a struct
+// and its first field have the same address.
+type cloneKey struct {
+	pointer uintptr
+	length  int
+	typ     reflect.Type
+}
+
+// cloner keeps track of the references that were copied so that cycles and shared references are preserved.
+type cloner struct {
+	copies map[cloneKey]reflect.Value
+}
+
+// Clone returns a deep copy of a struct. Its pointers, slices, maps and interfaces are copied recursively.
+//
+// Reference cycles and shared references are detected and preserved in the copy, so a pointer that is reachable
+// more than once is only copied once. Types that implement Cloner are copied with it. Unexported fields, except the
+// fields of embedded structs, are copied shallowly, as are channels and functions.
+func Clone[T any](instance *T) *T {
+	if instance == nil {
+		panic("the instance cannot be nil")
+	}
+	instanceValue := reflect.ValueOf(instance)
+	if instanceValue.Elem().Kind() != reflect.Struct {
+		panic("the generic must be a struct")
+	}
+	c := &cloner{
+		copies: make(map[cloneKey]reflect.Value),
+	}
+	return c.clone(instanceValue).Interface().(*T)
+}
+
+// clone returns a deep copy of the value.
+func (c *cloner) clone(value reflect.Value) reflect.Value {
+	if copied, isCloner := c.cloneWithCloner(value); isCloner {
+		return copied
+	}
+
+	switch value.Kind() {
+	case reflect.Ptr:
+		if value.IsNil() {
+			return reflect.Zero(value.Type())
+		}
+		key := cloneKey{pointer: value.Pointer(), typ: value.Type()}
+		if copied, alreadyCopied := c.copies[key]; alreadyCopied {
+			return copied
+		}
+		copied := reflect.New(value.Type().Elem())
+		c.copies[key] = copied
+		copied.Elem().Set(c.clone(value.Elem()))
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(value.Type()).Elem()
+		copied.Set(value)
+		c.cloneStructFields(copied, value)
+		return copied
+	case reflect.Slice:
+		if value.IsNil() {
+			return reflect.Zero(value.Type())
+		}
+		key := cloneKey{pointer: value.Pointer(), length: value.Len(), typ: value.Type()}
+		if copied, alreadyCopied := c.copies[key]; alreadyCopied {
+			return copied
+		}
+		copied := reflect.MakeSlice(value.Type(), value.Len(), value.Cap())
+		c.copies[key] = copied
+		for index := 0; index < value.Len(); index++ {
+			copied.Index(index).Set(c.clone(value.Index(index)))
+		}
+		return copied
+	case reflect.Array:
+		copied := reflect.New(value.Type()).Elem()
+		for index := 0; index < value.Len(); index++ {
+			copied.Index(index).Set(c.clone(value.Index(index)))
+		}
+		return copied
+	case reflect.Map:
+		if value.IsNil() {
+			return reflect.Zero(value.Type())
+		}
+		key := cloneKey{pointer: value.Pointer(), typ: value.Type()}
+		if copied, alreadyCopied := c.copies[key]; alreadyCopied {
+			return copied
+		}
+		copied := reflect.MakeMapWithSize(value.Type(), value.Len())
+		c.copies[key] = copied
+		iter := value.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(c.clone(iter.Key()), c.clone(iter.Value()))
+		}
+		return copied
+	case reflect.Interface:
+		if value.IsNil() {
+			return reflect.Zero(value.Type())
+		}
+		copied := reflect.New(value.Type()).Elem()
+		copied.Set(c.clone(value.Elem()))
+		return copied
+	default:
+		return value
+	}
+}
+
+// cloneStructFields replaces the exported fields of the copied struct with deep copies of the fields of the value.
+// The copied struct must already be a shallow copy of the value.
+func (c *cloner) cloneStructFields(copied reflect.Value, value reflect.Value) {
+	structType := value.Type()
+	for fieldIndex := 0; fieldIndex < structType.NumField(); fieldIndex++ {
+		field := structType.Field(fieldIndex)
+		if field.IsExported() {
+			copied.Field(fieldIndex).Set(c.clone(value.Field(fieldIndex)))
+		} else if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			c.cloneStructFields(copied.Field(fieldIndex), value.Field(fieldIndex))
+		}
+	}
+}
+
+// cloneWithCloner copies the value with its Cloner implementation. It returns false if the value doesn't implement it.
+func (c *cloner) cloneWithCloner(value reflect.Value) (reflect.Value, bool) {
+	clonerType := reflect.TypeFor[Cloner]()
+	var implementation Cloner
+	switch {
+	case value.Kind() == reflect.Interface || (value.Kind() == reflect.Ptr && value.IsNil()):
+		// Interfaces are copied with the Cloner of the value they hold.
+		return reflect.Value{}, false
+	case value.Type().Implements(clonerType):
+		implementation = value.Interface().(Cloner)
+	case value.Kind() != reflect.Ptr && reflect.PointerTo(value.Type()).Implements(clonerType):
+		valuePtr := reflect.New(value.Type())
+		valuePtr.Elem().Set(value)
+		implementation = valuePtr.Interface().(Cloner)
+	default:
+		return reflect.Value{}, false
+	}
+
+	copied := reflect.ValueOf(implementation.Clone())
+	switch {
+	case copied.IsValid() && copied.Type() == value.Type():
+		return copied, true
+	case copied.IsValid() && copied.Type() == reflect.PointerTo(value.Type()) && !copied.IsNil():
+		return copied.Elem(), true
+	case copied.IsValid() && value.Kind() == reflect.Ptr && copied.Type() == value.Type().Elem():
+		copiedPtr := reflect.New(copied.Type())
+		copiedPtr.Elem().Set(copied)
+		return copiedPtr, true
+	default:
+		panic(fmt.Sprintf("the Clone method of %s must return a %s", value.Type(), value.Type()))
+	}
+}
@@ -0,0 +1,195 @@
+package structs_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+	"github.com/TriangleSide/GoBase/pkg/utils/ptr"
+	"github.com/TriangleSide/GoBase/pkg/utils/structs"
+)
+
+type cloneCounter struct {
+	Value  int
+	Clones int
+}
+
+func (c *cloneCounter) Clone() any {
+	return &cloneCounter{Value: c.Value, Clones: c.Clones + 1}
+}
+
+type cloneValueCounter struct {
+	Value int
+}
+
+func (c cloneValueCounter) Clone() any {
+	return cloneValueCounter{Value: c.Value * 10}
+}
+
+type cloneInvalid struct{}
+
+func (c cloneInvalid) Clone() any {
+	return "invalid"
+}
+
+type cloneNode struct {
+	Name     string
+	Next     *cloneNode
+	Children []*cloneNode
+	Values   map[string]any
+}
+
+func TestClone(t *testing.T) {
+	t.Parallel()
+
+	type nested struct {
+		Values []int
+	}
+
+	type embedded struct {
+		Embedded []string
+	}
+
+	type testStruct struct {
+		embedded
+		Name       string
+		Pointer    *int
+		Nested     nested
+		NestedPtr  *nested
+		List       []nested
+		Array      [2]*int
+		Map        map[string]*nested
+		Any        any
+		Time       time.Time
+		Func       func() int
+		Channel    chan int
+		NilPointer *int
+		NilMap     map[string]int
+		unexported *int
+	}
+
+	t.Run("when a struct is cloned it should deep copy its references", func(t *testing.T) {
+		t.Parallel()
+		channel := make(chan int)
+		original := &testStruct{
+			embedded:   embedded{Embedded: []string{"a"}},
+			Name:       "name",
+			Pointer:    ptr.Of(1),
+			Nested:     nested{Values: []int{1, 2}},
+			NestedPtr:  &nested{Values: []int{3}},
+			List:       []nested{{Values: []int{4}}},
+			Array:      [2]*int{ptr.Of(5), nil},
+			Map:        map[string]*nested{"key": {Values: []int{6}}},
+			Any:        &nested{Values: []int{7}},
+			Time:       time.Unix(0, 0).UTC(),
+			Func:       func() int { return 8 },
+			Channel:    channel,
+			unexported: ptr.Of(9),
+		}
+		cloned := structs.Clone(original)
+
+		assert.Equals(t, cloned.Name, original.Name)
+		assert.Equals(t, cloned.Embedded, original.Embedded)
+		assert.Equals(t, cloned.Nested, original.Nested)
+		assert.Equals(t, cloned.NestedPtr, original.NestedPtr)
+		assert.Equals(t, cloned.List, original.List)
+		assert.Equals(t, cloned.Map, original.Map)
+		assert.Equals(t, cloned.Any, original.Any)
+		assert.Equals(t, cloned.Time, original.Time)
+		assert.Equals(t, cloned.Func(), 8)
+		assert.True(t, cloned.Channel == channel)
+		assert.True(t, cloned.unexported == original.unexported)
+		assert.Nil(t, cloned.NilPointer)
+		assert.Nil(t, cloned.NilMap)
+
+		*original.Pointer = 10
+		original.Embedded[0] = "changed"
+		original.Nested.Values[0] = 10
+		original.NestedPtr.Values[0] = 10
+		original.List[0].Values[0] = 10
+		*original.Array[0] = 10
+		original.Map["key"].Values[0] = 10
+		original.Any.(*nested).Values[0] = 10
+		assert.Equals(t, *cloned.Pointer, 1)
+		assert.Equals(t, cloned.Embedded, []string{"a"})
+		assert.Equals(t, cloned.Nested.Values, []int{1, 2})
+		assert.Equals(t, cloned.NestedPtr.Values, []int{3})
+		assert.Equals(t, cloned.List[0].Values, []int{4})
+		assert.Equals(t, *cloned.Array[0], 5)
+		assert.Equals(t, cloned.Map["key"].Values, []int{6})
+		assert.Equals(t, cloned.Any.(*nested).Values, []int{7})
+	})
+
+	t.Run("when a struct has reference cycles it should preserve them in the clone", func(t *testing.T) {
+		t.Parallel()
+		root := &cloneNode{Name: "root", Values: map[string]any{}}
+		child := &cloneNode{Name: "child", Next: root}
+		root.Next = root
+		root.Children = []*cloneNode{child, child}
+		root.Values["self"] = root.Values
+		root.Values["root"] = root
+
+		cloned := structs.Clone(root)
+		assert.True(t, cloned != root)
+		assert.True(t, cloned.Next == cloned)
+		assert.True(t, cloned.Children[0] != child)
+		assert.True(t, cloned.Children[0] == cloned.Children[1])
+		assert.True(t, cloned.Children[0].Next == cloned)
+		assert.True(t, cloned.Values["root"].(*cloneNode) == cloned)
+		cloned.Values["added"] = true
+		_, clonedSelfHasAdded := cloned.Values["self"].(map[string]any)["added"]
+		assert.True(t, clonedSelfHasAdded)
+		_, originalHasAdded := root.Values["added"]
+		assert.False(t, originalHasAdded)
+	})
+
+	t.Run("when a type implements Cloner it should be copied with it", func(t *testing.T) {
+		t.Parallel()
+		type withCloners struct {
+			Pointer *cloneCounter
+			Value   cloneCounter
+			ByValue cloneValueCounter
+			Any     any
+		}
+		original := &withCloners{
+			Pointer: &cloneCounter{Value: 1},
+			Value:   cloneCounter{Value: 2},
+			ByValue: cloneValueCounter{Value: 3},
+			Any:     cloneValueCounter{Value: 4},
+		}
+		cloned := structs.Clone(original)
+		assert.Equals(t, cloned.Pointer, &cloneCounter{Value: 1, Clones: 1})
+		assert.Equals(t, cloned.Value, cloneCounter{Value: 2, Clones: 1})
+		assert.Equals(t, cloned.ByValue, cloneValueCounter{Value: 30})
+		assert.Equals(t, cloned.Any, any(cloneValueCounter{Value: 40}))
+
+		counter := structs.Clone(&cloneCounter{Value: 5})
+		assert.Equals(t, counter, &cloneCounter{Value: 5, Clones: 1})
+		valueCounter := structs.Clone(&cloneValueCounter{Value: 6})
+		assert.Equals(t, valueCounter, &cloneValueCounter{Value: 60})
+	})
+
+	t.Run("when a Cloner returns another type it should panic", func(t *testing.T) {
+		t.Parallel()
+		type withInvalid struct {
+			Invalid cloneInvalid
+		}
+		assert.PanicPart(t, func() {
+			structs.Clone(&withInvalid{})
+		}, "the Clone method of structs_test.cloneInvalid must return a structs_test.cloneInvalid")
+	})
+
+	t.Run("when the instance is nil it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			structs.Clone[testStruct](nil)
+		}, "the instance cannot be nil")
+	})
+
+	t.Run("when the generic is not a struct it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			structs.Clone(ptr.Of("value"))
+		}, "the generic must be a struct")
+	})
+}
@@ -0,0 +1,182 @@
+package structs
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+
+	"github.com/TriangleSide/GoBase/pkg/utils/fields"
+)
+
+// DiffTag is a struct field tag that excludes a field from Diff when its value is "-".
+//
+//	type MyStruct struct {
+//	    UpdatedAt time.Time `diff:"-"`
+//	}
+const DiffTag = "diff"
+
+// Change is a difference between two structs at a field path.
+type Change struct {
+	// Path is the field names joined by dots, with the slice indexes and map keys between brackets,
+	// such as Servers[1].Host or Labels[app]. It has the same format as assign.FieldPath.
+	Path string
+
+	// Old is the value in the first struct. It is nil if the value doesn't exist, like a slice element past its length.
+	Old any
+
+	// New is the value in the second struct. It is nil if the value doesn't exist.
+	New any
+}
+
+// diffKey identifies a pair of pointers that were already compared.
+type diffKey struct {
+	a   uintptr
+	b   uintptr
+	typ reflect.Type
+}
+
+// differ collects the changes between two structs. It keeps track of the compared pointers to stop at reference cycles.
+type differ struct {
+	compared map[diffKey]bool
+	changes  []Change
+}
+
+// Diff compares two structs and returns the changes between them, such as for audit logs or to announce the
+// fields of a configuration that were reloaded.
+//
+// Nested structs and pointers to structs are compared field by field, unless they implement json.Marshaler or
+// encoding.TextMarshaler, like time.Time, in which case they are compared as a whole. Slices are compared element
+// by element, and maps entry by entry. Unexported fields and fields with the DiffTag set to "-" are ignored.
+// Pointers that form a reference cycle are compared once. The changes are ordered by field name, slice index,
+// and map key.
+func Diff[T any](a *T, b *T) []Change {
+	if a == nil || b == nil {
+		panic("the structs to compare cannot be nil")
+	}
+	aValue := reflect.ValueOf(a).Elem()
+	if aValue.Kind() != reflect.Struct {
+		panic("the generic must be a struct")
+	}
+	d := &differ{
+		compared: make(map[diffKey]bool),
+		changes:  make([]Change, 0),
+	}
+	d.diffStruct(aValue, reflect.ValueOf(b).Elem(), "")
+	return d.changes
+}
+
+// diffStruct adds the changes between the fields of two values of the same struct type.
+func (d *differ) diffStruct(a reflect.Value, b reflect.Value, path string) {
+	metadataMap := fields.StructMetadataFromType(a.Type())
+	fieldNames := make([]string, 0, metadataMap.Size())
+	for fieldName, fieldMetadata := range metadataMap.Iterator() {
+		if fieldMetadata.Tags[DiffTag] != "-" {
+			fieldNames = append(fieldNames, fieldName)
+		}
+	}
+	slices.Sort(fieldNames)
+
+	for _, fieldName := range fieldNames {
+		fieldMetadata, _ := metadataMap.Fetch(fieldName)
+		aField := fieldValueOrZero(a, fieldName, fieldMetadata)
+		bField := fieldValueOrZero(b, fieldName, fieldMetadata)
+		if !aField.CanInterface() || !bField.CanInterface() {
+			continue
+		}
+		fieldPath := fieldName
+		if path != "" {
+			fieldPath = path + "." + fieldName
+		}
+		d.diffValue(aField, bField, fieldPath)
+	}
+}
+
+// fieldValueOrZero gets the value of a field. If the field is in a nil embedded struct pointer, its zero value is used.
+func fieldValueOrZero(structValue reflect.Value, fieldName string, fieldMetadata *fields.FieldMetadata) reflect.Value {
+	fieldValue, ok := fieldValueFromMetadata(structValue, fieldName, fieldMetadata)
+	if !ok {
+		return reflect.Zero(fieldMetadata.Type)
+	}
+	return fieldValue
+}
+
+// diffValue adds the changes between two values of the same type.
+func (d *differ) diffValue(a reflect.Value, b reflect.Value, path string) {
+	switch a.Kind() {
+	case reflect.Ptr:
+		if a.IsNil() || b.IsNil() || !isMergeableStruct(a.Type().Elem()) {
+			d.addChangeIfDifferent(a, b, path)
+			return
+		}
+		key := diffKey{a: a.Pointer(), b: b.Pointer(), typ: a.Type()}
+		if a.Pointer() != b.Pointer() && !d.compared[key] {
+			d.compared[key] = true
+			d.diffStruct(a.Elem(), b.Elem(), path)
+		}
+	case reflect.Struct:
+		if isMergeableStruct(a.Type()) {
+			d.diffStruct(a, b, path)
+		} else {
+			d.addChangeIfDifferent(a, b, path)
+		}
+	case reflect.Slice, reflect.Array:
+		if a.Kind() == reflect.Slice && (a.IsNil() || b.IsNil()) {
+			d.addChangeIfDifferent(a, b, path)
+			return
+		}
+		for index := 0; index < max(a.Len(), b.Len()); index++ {
+			elementPath := fmt.Sprintf("%s[%d]", path, index)
+			switch {
+			case index >= a.Len():
+				d.changes = append(d.changes, Change{Path: elementPath, Old: nil, New: b.Index(index).Interface()})
+			case index >= b.Len():
+				d.changes = append(d.changes, Change{Path: elementPath, Old: a.Index(index).Interface(), New: nil})
+			default:
+				d.diffValue(a.Index(index), b.Index(index), elementPath)
+			}
+		}
+	case reflect.Map:
+		if a.IsNil() || b.IsNil() {
+			d.addChangeIfDifferent(a, b, path)
+			return
+		}
+		for _, key := range sortedMapKeys(a, b) {
+			entryPath := fmt.Sprintf("%s[%v]", path, key.Interface())
+			aEntry, bEntry := a.MapIndex(key), b.MapIndex(key)
+			switch {
+			case !aEntry.IsValid():
+				d.changes = append(d.changes, Change{Path: entryPath, Old: nil, New: bEntry.Interface()})
+			case !bEntry.IsValid():
+				d.changes = append(d.changes, Change{Path: entryPath, Old: aEntry.Interface(), New: nil})
+			default:
+				d.diffValue(aEntry, bEntry, entryPath)
+			}
+		}
+	default:
+		d.addChangeIfDifferent(a, b, path)
+	}
+}
+
+// addChangeIfDifferent adds a change with the values if they are not deeply equal.
+func (d *differ) addChangeIfDifferent(a reflect.Value, b reflect.Value, path string) {
+	aInterface, bInterface := a.Interface(), b.Interface()
+	if !reflect.DeepEqual(aInterface, bInterface) {
+		d.changes = append(d.changes, Change{Path: path, Old: aInterface, New: bInterface})
+	}
+}
+
+// sortedMapKeys returns the keys of both maps without duplicates, sorted by their formatted values.
+func sortedMapKeys(a reflect.Value, b reflect.Value) []reflect.Value {
+	keys := make([]reflect.Value, 0, a.Len()+b.Len())
+	keys = append(keys, a.MapKeys()...)
+	for _, key := range b.MapKeys() {
+		if !a.MapIndex(key).IsValid() {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+	})
+	return keys
+}
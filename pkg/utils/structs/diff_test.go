@@ -0,0 +1,129 @@
+package structs_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+	"github.com/TriangleSide/GoBase/pkg/utils/ptr"
+	"github.com/TriangleSide/GoBase/pkg/utils/structs"
+)
+
+type DiffEmbeddedPtr struct {
+	Zone string
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	type server struct {
+		Host string
+		Port int
+	}
+
+	type embedded struct {
+		Region string
+	}
+
+	type testStruct struct {
+		embedded
+		*DiffEmbeddedPtr
+		Name       string
+		Retries    *int
+		Database   server
+		Primary    *server
+		Started    time.Time
+		Servers    []server
+		Tags       []string
+		Pair       [2]int
+		Labels     map[string]string
+		Any        any
+		UpdatedAt  time.Time `diff:"-"`
+		unexported string
+	}
+
+	t.Run("when the structs are equal it should return no changes", func(t *testing.T) {
+		t.Parallel()
+		a := &testStruct{Name: "name", Primary: &server{Host: "host"}, Servers: []server{{Port: 1}}, Labels: map[string]string{"a": "b"}}
+		b := &testStruct{Name: "name", Primary: &server{Host: "host"}, Servers: []server{{Port: 1}}, Labels: map[string]string{"a": "b"}}
+		assert.Equals(t, structs.Diff(a, b), []structs.Change{})
+	})
+
+	t.Run("when the structs are different it should return the changed field paths in order", func(t *testing.T) {
+		t.Parallel()
+		a := &testStruct{
+			embedded:   embedded{Region: "us"},
+			Name:       "old",
+			Retries:    ptr.Of(1),
+			Database:   server{Host: "host", Port: 1},
+			Primary:    &server{Host: "primary", Port: 1},
+			Started:    time.Unix(1, 0).UTC(),
+			Servers:    []server{{Host: "a"}, {Host: "b"}},
+			Tags:       []string{"a"},
+			Pair:       [2]int{1, 2},
+			Labels:     map[string]string{"app": "api", "old": "value"},
+			Any:        1,
+			UpdatedAt:  time.Unix(1, 0),
+			unexported: "old",
+		}
+		b := &testStruct{
+			embedded:        embedded{Region: "eu"},
+			DiffEmbeddedPtr: &DiffEmbeddedPtr{Zone: "zone"},
+			Name:            "new",
+			Retries:         ptr.Of(2),
+			Database:        server{Host: "host", Port: 2},
+			Started:         time.Unix(2, 0).UTC(),
+			Servers:         []server{{Host: "a"}, {Host: "c"}, {Host: "d"}},
+			Tags:            nil,
+			Pair:            [2]int{1, 3},
+			Labels:          map[string]string{"app": "web", "new": "value"},
+			Any:             "1",
+			UpdatedAt:       time.Unix(2, 0),
+			unexported:      "new",
+		}
+		assert.Equals(t, structs.Diff(a, b), []structs.Change{
+			{Path: "Any", Old: 1, New: "1"},
+			{Path: "Database.Port", Old: 1, New: 2},
+			{Path: "Labels[app]", Old: "api", New: "web"},
+			{Path: "Labels[new]", Old: nil, New: "value"},
+			{Path: "Labels[old]", Old: "value", New: nil},
+			{Path: "Name", Old: "old", New: "new"},
+			{Path: "Pair[1]", Old: 2, New: 3},
+			{Path: "Primary", Old: &server{Host: "primary", Port: 1}, New: (*server)(nil)},
+			{Path: "Region", Old: "us", New: "eu"},
+			{Path: "Retries", Old: ptr.Of(1), New: ptr.Of(2)},
+			{Path: "Servers[1].Host", Old: "b", New: "c"},
+			{Path: "Servers[2]", Old: nil, New: server{Host: "d"}},
+			{Path: "Started", Old: time.Unix(1, 0).UTC(), New: time.Unix(2, 0).UTC()},
+			{Path: "Tags", Old: []string{"a"}, New: []string(nil)},
+			{Path: "Zone", Old: "", New: "zone"},
+		})
+	})
+
+	t.Run("when the structs have reference cycles it should compare each pointer once", func(t *testing.T) {
+		t.Parallel()
+		type node struct {
+			Name string
+			Next *node
+		}
+		a := &node{Name: "a"}
+		a.Next = &node{Name: "b", Next: a}
+		b := &node{Name: "a"}
+		b.Next = &node{Name: "c", Next: b}
+		assert.Equals(t, structs.Diff(a, b), []structs.Change{{Path: "Next.Name", Old: "b", New: "c"}})
+	})
+
+	t.Run("when a struct is nil it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			structs.Diff(&testStruct{}, nil)
+		}, "the structs to compare cannot be nil")
+	})
+
+	t.Run("when the generic is not a struct it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			structs.Diff(ptr.Of(1), ptr.Of(2))
+		}, "the generic must be a struct")
+	})
+}
@@ -0,0 +1,160 @@
+package structs
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/TriangleSide/GoBase/pkg/utils/fields"
+)
+
+// FromMap sets the fields of a struct from a map of their names to their values. It is the reverse of ToMap.
+//
+// The keys of the map are matched to the fields with the tag in the same way as ToMap. Fields whose keys are not in
+// the map keep their values, so the map can patch an existing instance. Keys that don't match a field are ignored.
+// The fields of embedded anonymous structs can be flattened into the map or nested under the key of the embedded
+// struct, and nil embedded struct pointers are allocated when one of their fields is set.
+//
+// Values that can be assigned to a field are set as they are. Maps are set into nested structs with the same rules.
+// Other values, like the float64 numbers and slices of a decoded JSON document, are converted with JSON encoding.
+func FromMap[T any](values map[string]any, instance *T, tag string) error {
+	if instance == nil {
+		panic("the instance cannot be nil")
+	}
+	structValue := reflect.ValueOf(instance).Elem()
+	if structValue.Kind() != reflect.Struct {
+		panic("the generic must be a struct")
+	}
+	return mapToStruct(values, structValue, tag)
+}
+
+// mapToStruct sets the values of the map into the fields of the addressable struct value.
+func mapToStruct(values map[string]any, structValue reflect.Value, tag string) error {
+	keyToFieldName := make(map[string]string)
+	metadataMap := fields.StructMetadataFromType(structValue.Type())
+	for fieldName, fieldMetadata := range metadataMap.Iterator() {
+		if key, hasKey := fieldMetadata.TagName(tag); hasKey {
+			keyToFieldName[key] = fieldName
+		}
+	}
+
+	keyToEmbeddedIndex := make(map[string]int)
+	structType := structValue.Type()
+	for fieldIndex := 0; fieldIndex < structType.NumField(); fieldIndex++ {
+		field := structType.Field(fieldIndex)
+		if !field.Anonymous {
+			continue
+		}
+		key, skip := keyFromTag(field.Name, field.Tag.Get(tag))
+		if !skip {
+			keyToEmbeddedIndex[key] = fieldIndex
+		}
+	}
+
+	for key, value := range values {
+		if fieldName, isField := keyToFieldName[key]; isField {
+			fieldMetadata, _ := metadataMap.Fetch(fieldName)
+			fieldValue, err := settableField(structValue, fieldName, fieldMetadata)
+			if err != nil {
+				return err
+			}
+			if err := setValue(fieldValue, value, tag); err != nil {
+				return fmt.Errorf("failed to set the field %s (%w)", fieldName, err)
+			}
+			continue
+		}
+
+		if fieldIndex, isEmbedded := keyToEmbeddedIndex[key]; isEmbedded {
+			nestedValues, isMap := value.(map[string]any)
+			if !isMap {
+				continue
+			}
+			embeddedValue, err := allocateEmbedded(structValue.Field(fieldIndex), structType.Field(fieldIndex).Name)
+			if err != nil {
+				return err
+			}
+			if err := mapToStruct(nestedValues, embeddedValue, tag); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// settableField returns the field of the struct value, allocating the nil embedded struct pointers that contain it.
+func settableField(structValue reflect.Value, fieldName string, fieldMetadata *fields.FieldMetadata) (reflect.Value, error) {
+	current := structValue
+	for _, anonymousName := range fieldMetadata.Anonymous {
+		embeddedValue, err := allocateEmbedded(current.FieldByName(anonymousName), anonymousName)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		current = embeddedValue
+	}
+	fieldValue := current.FieldByName(fieldName)
+	if !fieldValue.CanSet() {
+		return reflect.Value{}, fmt.Errorf("the field %s cannot be set", fieldName)
+	}
+	return fieldValue, nil
+}
+
+// allocateEmbedded returns the struct of an embedded field. If the field is a nil pointer, the struct is allocated.
+func allocateEmbedded(embeddedValue reflect.Value, embeddedName string) (reflect.Value, error) {
+	if embeddedValue.Kind() != reflect.Ptr {
+		return embeddedValue, nil
+	}
+	if embeddedValue.IsNil() {
+		if !embeddedValue.CanSet() {
+			return reflect.Value{}, fmt.Errorf("the embedded struct %s cannot be allocated", embeddedName)
+		}
+		embeddedValue.Set(reflect.New(embeddedValue.Type().Elem()))
+	}
+	return embeddedValue.Elem(), nil
+}
+
+// setValue sets the value into the addressable target.
+func setValue(target reflect.Value, value any, tag string) error {
+	if value == nil {
+		target.Set(reflect.Zero(target.Type()))
+		return nil
+	}
+
+	reflectedValue := reflect.ValueOf(value)
+	if reflectedValue.Type().AssignableTo(target.Type()) {
+		target.Set(reflectedValue)
+		return nil
+	}
+
+	if target.Kind() == reflect.Ptr {
+		elemPtr := reflect.New(target.Type().Elem())
+		if !target.IsNil() {
+			elemPtr.Elem().Set(target.Elem())
+		}
+		if err := setValue(elemPtr.Elem(), value, tag); err != nil {
+			return err
+		}
+		target.Set(elemPtr)
+		return nil
+	}
+
+	if nestedValues, isMap := value.(map[string]any); isMap && target.Kind() == reflect.Struct && !implementsUnmarshaler(target.Type()) {
+		return mapToStruct(nestedValues, target, tag)
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode the value (%w)", err)
+	}
+	if err := json.Unmarshal(encoded, target.Addr().Interface()); err != nil {
+		return fmt.Errorf("failed to convert the value (%w)", err)
+	}
+	return nil
+}
+
+// implementsUnmarshaler checks if the type has its own deserialization.
+func implementsUnmarshaler(reflectType reflect.Type) bool {
+	ptrType := reflect.PointerTo(reflectType)
+	return ptrType.Implements(reflect.TypeFor[json.Unmarshaler]()) || ptrType.Implements(reflect.TypeFor[encoding.TextUnmarshaler]())
+}
@@ -0,0 +1,179 @@
+package structs_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+	"github.com/TriangleSide/GoBase/pkg/utils/ptr"
+	"github.com/TriangleSide/GoBase/pkg/utils/structs"
+)
+
+type FromMapEmbeddedPtr struct {
+	EmbeddedPtrField string `json:"embeddedPtr"`
+}
+
+type fromMapUnexportedEmbeddedPtr struct {
+	Hidden string `json:"hidden"`
+}
+
+func TestFromMap(t *testing.T) {
+	t.Parallel()
+
+	type nestedStruct struct {
+		Value int    `json:"value"`
+		Other string `json:"other"`
+	}
+
+	type embeddedStruct struct {
+		EmbeddedField string `json:"embedded" query:"emb"`
+	}
+
+	type testStruct struct {
+		embeddedStruct
+		*FromMapEmbeddedPtr
+		Name       string `json:"name,omitempty" query:"n"`
+		NoTag      int
+		Skipped    string            `json:"-"`
+		Pointer    *int              `json:"pointer"`
+		Nested     nestedStruct      `json:"nested"`
+		NestedPtr  *nestedStruct     `json:"nestedPtr"`
+		Time       time.Time         `json:"time"`
+		List       []string          `json:"list"`
+		Structs    []nestedStruct    `json:"structs"`
+		Labels     map[string]string `json:"labels"`
+		unexported string
+	}
+
+	t.Run("when a map from ToMap is set into a struct it should be equal to the original", func(t *testing.T) {
+		t.Parallel()
+		original := &testStruct{
+			embeddedStruct:     embeddedStruct{EmbeddedField: "embedded"},
+			FromMapEmbeddedPtr: &FromMapEmbeddedPtr{EmbeddedPtrField: "embeddedPtr"},
+			Name:               "name",
+			NoTag:              1,
+			Pointer:            ptr.Of(2),
+			Nested:             nestedStruct{Value: 3},
+			NestedPtr:          &nestedStruct{Value: 4},
+			Time:               time.Unix(0, 0).UTC(),
+			List:               []string{"a"},
+			Structs:            []nestedStruct{{Value: 5}},
+			Labels:             map[string]string{"app": "api"},
+		}
+		for _, opts := range [][]structs.ToMapOption{nil, {structs.WithNestedEmbedded()}} {
+			instance := &testStruct{}
+			assert.NoError(t, structs.FromMap(structs.ToMap(original, "json", opts...), instance, "json"))
+			assert.Equals(t, instance, original)
+		}
+	})
+
+	t.Run("when a decoded JSON document is set into a struct it should convert the values", func(t *testing.T) {
+		t.Parallel()
+		var values map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(`{
+			"embedded": "embedded",
+			"NoTag": 1,
+			"pointer": 2,
+			"nested": {"value": 3},
+			"nestedPtr": {"value": 4},
+			"time": "1970-01-01T00:00:00Z",
+			"list": ["a", "b"],
+			"structs": [{"value": 5}],
+			"labels": {"app": "api"},
+			"unknown": true
+		}`), &values))
+		instance := &testStruct{}
+		assert.NoError(t, structs.FromMap(values, instance, "json"))
+		assert.Equals(t, instance, &testStruct{
+			embeddedStruct: embeddedStruct{EmbeddedField: "embedded"},
+			NoTag:          1,
+			Pointer:        ptr.Of(2),
+			Nested:         nestedStruct{Value: 3},
+			NestedPtr:      &nestedStruct{Value: 4},
+			Time:           time.Unix(0, 0).UTC(),
+			List:           []string{"a", "b"},
+			Structs:        []nestedStruct{{Value: 5}},
+			Labels:         map[string]string{"app": "api"},
+		})
+	})
+
+	t.Run("when a map patches an instance it should only change the fields in the map", func(t *testing.T) {
+		t.Parallel()
+		instance := &testStruct{
+			Name:      "name",
+			NoTag:     1,
+			Pointer:   ptr.Of(2),
+			Nested:    nestedStruct{Value: 3, Other: "other"},
+			NestedPtr: &nestedStruct{Value: 4, Other: "other"},
+		}
+		assert.NoError(t, structs.FromMap(map[string]any{
+			"name":      "patched",
+			"pointer":   nil,
+			"nested":    map[string]any{"value": 30},
+			"nestedPtr": map[string]any{"other": "patched"},
+		}, instance, "json"))
+		assert.Equals(t, instance, &testStruct{
+			Name:      "patched",
+			NoTag:     1,
+			Nested:    nestedStruct{Value: 30, Other: "other"},
+			NestedPtr: &nestedStruct{Value: 4, Other: "patched"},
+		})
+	})
+
+	t.Run("when a map is set with another tag it should use that tag for the names", func(t *testing.T) {
+		t.Parallel()
+		instance := &testStruct{}
+		assert.NoError(t, structs.FromMap(map[string]any{"emb": "embedded", "n": "name", "Skipped": "skipped", "name": "ignored"}, instance, "query"))
+		assert.Equals(t, instance.EmbeddedField, "embedded")
+		assert.Equals(t, instance.Name, "name")
+		assert.Equals(t, instance.Skipped, "skipped")
+	})
+
+	t.Run("when a skipped or unexported field is in the map it should be ignored", func(t *testing.T) {
+		t.Parallel()
+		instance := &testStruct{}
+		assert.NoError(t, structs.FromMap(map[string]any{"Skipped": "skipped", "-": "skipped"}, instance, "json"))
+		assert.Equals(t, instance.Skipped, "")
+		assert.ErrorPart(t, structs.FromMap(map[string]any{"unexported": "value"}, instance, "json"), "the field unexported cannot be set")
+	})
+
+	t.Run("when a value can't be converted it should return an error", func(t *testing.T) {
+		t.Parallel()
+		subTests := []struct {
+			values    map[string]any
+			errorPart string
+		}{
+			{map[string]any{"NoTag": "one"}, "failed to set the field NoTag (failed to convert the value"},
+			{map[string]any{"NoTag": 1.5}, "failed to set the field NoTag (failed to convert the value"},
+			{map[string]any{"nested": map[string]any{"value": "one"}}, "failed to set the field Nested (failed to set the field Value"},
+			{map[string]any{"list": make(chan int)}, "failed to encode the value"},
+		}
+		for _, subTest := range subTests {
+			assert.ErrorPart(t, structs.FromMap(subTest.values, &testStruct{}, "json"), subTest.errorPart)
+		}
+	})
+
+	t.Run("when an unexported embedded struct pointer is nil it should return an error", func(t *testing.T) {
+		t.Parallel()
+		type unexportedEmbedded struct {
+			*fromMapUnexportedEmbeddedPtr
+		}
+		err := structs.FromMap(map[string]any{"hidden": "value"}, &unexportedEmbedded{}, "json")
+		assert.ErrorPart(t, err, "the embedded struct fromMapUnexportedEmbeddedPtr cannot be allocated")
+	})
+
+	t.Run("when the instance is nil it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			_ = structs.FromMap[testStruct](map[string]any{}, nil, "json")
+		}, "the instance cannot be nil")
+	})
+
+	t.Run("when the generic is not a struct it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			_ = structs.FromMap(map[string]any{}, ptr.Of("value"), "json")
+		}, "the generic must be a struct")
+	})
+}
@@ -0,0 +1,323 @@
+package structs
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/TriangleSide/GoBase/pkg/utils/fields"
+)
+
+// MergeTag is a struct field tag that overrides how Merge combines a slice or map field. Its value is replace,
+// append, or key=<FieldName> to merge the elements by the value of one of their fields.
+//
+//	type MyStruct struct {
+//	    Servers []Server `merge:"key=Name"`
+//	    Tags    []string `merge:"append"`
+//	}
+const MergeTag = "merge"
+
+// SliceStrategy is how Merge combines the slices of dst and src.
+type SliceStrategy string
+
+const (
+	// SliceReplace replaces the slice of dst with the slice of src.
+	SliceReplace SliceStrategy = "replace"
+
+	// SliceAppend appends the elements of the slice of src to the slice of dst.
+	SliceAppend SliceStrategy = "append"
+
+	// SliceMergeByKey merges the struct elements of the slices that have the same value in their key field.
+	// The elements of src that don't match an element of dst are appended.
+	SliceMergeByKey SliceStrategy = "key"
+)
+
+// MapStrategy is how Merge combines the maps of dst and src.
+type MapStrategy string
+
+const (
+	// MapMerge sets the entries of the map of src into the map of dst. Entries with struct values that exist in both
+	// maps are merged.
+	MapMerge MapStrategy = "merge"
+
+	// MapReplace replaces the map of dst with the map of src.
+	MapReplace MapStrategy = "replace"
+)
+
+// mergeConfig is configured by the MergeOption functions.
+type mergeConfig struct {
+	sliceStrategy SliceStrategy
+	mergeKey      string
+	mapStrategy   MapStrategy
+	presentFields map[string]bool
+}
+
+// MergeOption is used to configure the Merge function.
+type MergeOption func(cfg *mergeConfig)
+
+// WithSliceStrategy sets how the slices without a MergeTag are merged. The default is SliceReplace.
+// SliceMergeByKey requires the key field to be set with WithMergeKey.
+func WithSliceStrategy(strategy SliceStrategy) MergeOption {
+	return func(cfg *mergeConfig) {
+		cfg.sliceStrategy = strategy
+	}
+}
+
+// WithMergeKey sets the name of the field that identifies the elements of the slices merged with SliceMergeByKey.
+func WithMergeKey(fieldName string) MergeOption {
+	return func(cfg *mergeConfig) {
+		cfg.mergeKey = fieldName
+	}
+}
+
+// WithMapStrategy sets how the maps without a MergeTag are merged. The default is MapMerge.
+func WithMapStrategy(strategy MapStrategy) MergeOption {
+	return func(cfg *mergeConfig) {
+		cfg.mapStrategy = strategy
+	}
+}
+
+// WithPresentFields replaces the fields of dst at the paths with the fields of src, even when they are zero.
+// The paths are the field names joined by dots, such as Database.Port. This is used for PATCH requests, where
+// a field that is explicitly set to its zero value must be applied.
+func WithPresentFields(fieldPaths ...string) MergeOption {
+	return func(cfg *mergeConfig) {
+		for _, fieldPath := range fieldPaths {
+			cfg.presentFields[fieldPath] = true
+		}
+	}
+}
+
+// Merge deep merges the fields of src into dst. A field of src is merged if it is not zero, so a config can be
+// layered over another one. Pointers are present when they are not nil, even if they point to a zero value.
+//
+// Nested structs and pointers to structs are merged field by field, unless they implement json.Marshaler or
+// encoding.TextMarshaler, like time.Time, in which case they are replaced. Slices are combined according to the
+// SliceStrategy, and maps according to the MapStrategy. The MergeTag overrides the strategy of a field.
+// Unexported fields are skipped. Nil pointers and maps of dst are allocated, so dst never shares them with src.
+func Merge[T any](dst *T, src *T, opts ...MergeOption) {
+	cfg := &mergeConfig{
+		sliceStrategy: SliceReplace,
+		mergeKey:      "",
+		mapStrategy:   MapMerge,
+		presentFields: make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if dst == nil || src == nil {
+		panic("the dst and src cannot be nil")
+	}
+	dstValue := reflect.ValueOf(dst).Elem()
+	if dstValue.Kind() != reflect.Struct {
+		panic("the generic must be a struct")
+	}
+	mergeStruct(dstValue, reflect.ValueOf(src).Elem(), "", cfg)
+}
+
+// mergeStruct merges the fields of the src struct value into the addressable dst struct value.
+func mergeStruct(dst reflect.Value, src reflect.Value, path string, cfg *mergeConfig) {
+	for fieldName, fieldMetadata := range fields.StructMetadataFromType(src.Type()).Iterator() {
+		srcField, ok := fieldValueFromMetadata(src, fieldName, fieldMetadata)
+		if !ok || !srcField.CanInterface() {
+			continue
+		}
+
+		fieldPath := fieldName
+		if path != "" {
+			fieldPath = path + "." + fieldName
+		}
+
+		isPresent := cfg.presentFields[fieldPath]
+		if !isPresent && srcField.IsZero() && !hasPresentChild(cfg, fieldPath) {
+			continue
+		}
+		dstField, err := settableField(dst, fieldName, fieldMetadata)
+		if err != nil {
+			continue
+		}
+		if isPresent && srcField.IsZero() {
+			dstField.Set(reflect.Zero(dstField.Type()))
+			continue
+		}
+		mergeValue(dstField, srcField, fieldPath, fieldMetadata.Tags[MergeTag], cfg)
+	}
+}
+
+// mergeValue merges the src value into the settable dst value. The tag is the value of the MergeTag of the field.
+func mergeValue(dst reflect.Value, src reflect.Value, path string, tag string, cfg *mergeConfig) {
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			return
+		}
+		if isMergeableStruct(src.Type().Elem()) {
+			if dst.IsNil() {
+				dst.Set(reflect.New(dst.Type().Elem()))
+			}
+			mergeStruct(dst.Elem(), src.Elem(), path, cfg)
+			return
+		}
+		copied := reflect.New(src.Type().Elem())
+		copied.Elem().Set(src.Elem())
+		dst.Set(copied)
+	case reflect.Struct:
+		if isMergeableStruct(src.Type()) {
+			mergeStruct(dst, src, path, cfg)
+		} else if !src.IsZero() {
+			dst.Set(src)
+		}
+	case reflect.Slice:
+		if !src.IsNil() {
+			mergeSlice(dst, src, path, tag, cfg)
+		}
+	case reflect.Map:
+		if !src.IsNil() {
+			mergeMap(dst, src, path, tag, cfg)
+		}
+	default:
+		if !src.IsZero() {
+			dst.Set(src)
+		}
+	}
+}
+
+// mergeSlice combines the src slice into the dst slice with the strategy of the tag, or of the config.
+func mergeSlice(dst reflect.Value, src reflect.Value, path string, tag string, cfg *mergeConfig) {
+	strategy, mergeKey := cfg.sliceStrategy, cfg.mergeKey
+	if tag != "" {
+		strategy, mergeKey = parseMergeTag(tag, path)
+	}
+
+	switch strategy {
+	case SliceReplace:
+		dst.Set(reflect.AppendSlice(reflect.MakeSlice(src.Type(), 0, src.Len()), src))
+	case SliceAppend:
+		combined := reflect.MakeSlice(dst.Type(), 0, dst.Len()+src.Len())
+		dst.Set(reflect.AppendSlice(reflect.AppendSlice(combined, dst), src))
+	case SliceMergeByKey:
+		mergeSliceByKey(dst, src, path, mergeKey, cfg)
+	default:
+		panic(fmt.Sprintf("invalid slice merge strategy '%s' for the field %s", strategy, path))
+	}
+}
+
+// mergeSliceByKey merges the elements of the slices that have the same value in the key field.
+func mergeSliceByKey(dst reflect.Value, src reflect.Value, path string, mergeKey string, cfg *mergeConfig) {
+	elementType := src.Type().Elem()
+	structType := elementType
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("the elements of the field %s must be structs to be merged by key", path))
+	}
+	if _, hasKey := fields.StructMetadataFromType(structType).Fetch(mergeKey); !hasKey {
+		panic(fmt.Sprintf("the merge key '%s' of the field %s is not a field of %s", mergeKey, path, structType))
+	}
+
+	merged := reflect.AppendSlice(reflect.MakeSlice(dst.Type(), 0, dst.Len()+src.Len()), dst)
+	for srcIndex := 0; srcIndex < src.Len(); srcIndex++ {
+		srcElement := src.Index(srcIndex)
+		srcKey, hasSrcKey := elementKey(srcElement, mergeKey)
+		matchIndex := -1
+		for dstIndex := 0; hasSrcKey && dstIndex < merged.Len(); dstIndex++ {
+			if dstKey, hasDstKey := elementKey(merged.Index(dstIndex), mergeKey); hasDstKey && dstKey.Equal(srcKey) {
+				matchIndex = dstIndex
+				break
+			}
+		}
+		if matchIndex < 0 {
+			appended := reflect.New(elementType).Elem()
+			mergeValue(appended, srcElement, fmt.Sprintf("%s[%d]", path, srcIndex), "", cfg)
+			merged = reflect.Append(merged, appended)
+			continue
+		}
+		mergeValue(merged.Index(matchIndex), srcElement, fmt.Sprintf("%s[%d]", path, matchIndex), "", cfg)
+	}
+	dst.Set(merged)
+}
+
+// elementKey returns the value of the key field of a struct element. It returns false if the element is a nil pointer.
+func elementKey(element reflect.Value, mergeKey string) (reflect.Value, bool) {
+	if element.Kind() == reflect.Ptr {
+		if element.IsNil() {
+			return reflect.Value{}, false
+		}
+		element = element.Elem()
+	}
+	return element.FieldByName(mergeKey), true
+}
+
+// mergeMap combines the src map into the dst map with the strategy of the tag, or of the config.
+func mergeMap(dst reflect.Value, src reflect.Value, path string, tag string, cfg *mergeConfig) {
+	strategy := cfg.mapStrategy
+	if tag != "" {
+		sliceStrategy, _ := parseMergeTag(tag, path)
+		switch sliceStrategy {
+		case SliceReplace:
+			strategy = MapReplace
+		default:
+			panic(fmt.Sprintf("the merge tag '%s' of the field %s cannot be used on a map", tag, path))
+		}
+	}
+
+	switch strategy {
+	case MapReplace:
+		dst.Set(reflect.MakeMapWithSize(src.Type(), src.Len()))
+		fallthrough
+	case MapMerge:
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMapWithSize(src.Type(), src.Len()))
+		}
+		iter := src.MapRange()
+		for iter.Next() {
+			srcElement := iter.Value()
+			dstElement := dst.MapIndex(iter.Key())
+			if !dstElement.IsValid() || strategy == MapReplace {
+				dst.SetMapIndex(iter.Key(), srcElement)
+				continue
+			}
+			// Map elements are not addressable, so the element is copied, merged, then stored back in the map.
+			merged := reflect.New(dstElement.Type()).Elem()
+			merged.Set(dstElement)
+			mergeValue(merged, srcElement, fmt.Sprintf("%s[%v]", path, iter.Key()), "", cfg)
+			dst.SetMapIndex(iter.Key(), merged)
+		}
+	default:
+		panic(fmt.Sprintf("invalid map merge strategy '%s' for the field %s", strategy, path))
+	}
+}
+
+// parseMergeTag parses the value of a MergeTag into its slice strategy and merge key.
+func parseMergeTag(tag string, path string) (SliceStrategy, string) {
+	switch {
+	case tag == string(SliceReplace):
+		return SliceReplace, ""
+	case tag == string(SliceAppend):
+		return SliceAppend, ""
+	case strings.HasPrefix(tag, string(SliceMergeByKey)+"="):
+		mergeKey := strings.TrimPrefix(tag, string(SliceMergeByKey)+"=")
+		if mergeKey == "" {
+			panic(fmt.Sprintf("the merge tag of the field %s has an empty key", path))
+		}
+		return SliceMergeByKey, mergeKey
+	default:
+		panic(fmt.Sprintf("invalid merge tag '%s' for the field %s", tag, path))
+	}
+}
+
+// hasPresentChild returns true if a present field is nested in the field at the path.
+func hasPresentChild(cfg *mergeConfig, fieldPath string) bool {
+	for presentField := range cfg.presentFields {
+		if strings.HasPrefix(presentField, fieldPath+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// isMergeableStruct returns true if the type is a struct that is merged field by field.
+func isMergeableStruct(reflectType reflect.Type) bool {
+	return reflectType.Kind() == reflect.Struct && !implementsMarshaler(reflectType)
+}
@@ -0,0 +1,220 @@
+package structs_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+	"github.com/TriangleSide/GoBase/pkg/utils/ptr"
+	"github.com/TriangleSide/GoBase/pkg/utils/structs"
+)
+
+func TestMerge(t *testing.T) {
+	t.Parallel()
+
+	type server struct {
+		Name string
+		Host string
+		Port int
+	}
+
+	type database struct {
+		Host    string
+		Port    int
+		Timeout *time.Duration
+	}
+
+	type embedded struct {
+		Region string
+	}
+
+	type config struct {
+		embedded
+		Name       string
+		Enabled    bool
+		Retries    *int
+		Database   database
+		DatabasePt *database
+		Started    time.Time
+		Tags       []string
+		Servers    []server
+		ServerPtrs []*server `merge:"key=Name"`
+		Appended   []string  `merge:"append"`
+		Labels     map[string]string
+		Databases  map[string]database
+		Replaced   map[string]string `merge:"replace"`
+		unexported string
+	}
+
+	t.Run("when src has non-zero fields it should override the fields of dst", func(t *testing.T) {
+		t.Parallel()
+		dst := &config{
+			embedded: embedded{Region: "us"},
+			Name:     "dst",
+			Enabled:  true,
+			Database: database{Host: "dst-host", Port: 5432},
+			Started:  time.Unix(1, 0).UTC(),
+		}
+		src := &config{
+			Name:       "src",
+			Database:   database{Port: 6543, Timeout: ptr.Of(time.Second)},
+			DatabasePt: &database{Host: "src-host"},
+			Started:    time.Unix(2, 0).UTC(),
+			unexported: "unexported",
+		}
+		structs.Merge(dst, src)
+		assert.Equals(t, dst, &config{
+			embedded:   embedded{Region: "us"},
+			Name:       "src",
+			Enabled:    true,
+			Database:   database{Host: "dst-host", Port: 6543, Timeout: ptr.Of(time.Second)},
+			DatabasePt: &database{Host: "src-host"},
+			Started:    time.Unix(2, 0).UTC(),
+		})
+		assert.True(t, dst.DatabasePt != src.DatabasePt)
+		assert.True(t, dst.Database.Timeout != src.Database.Timeout)
+	})
+
+	t.Run("when a src pointer points to a zero value it should be merged", func(t *testing.T) {
+		t.Parallel()
+		dst := &config{Retries: ptr.Of(3)}
+		structs.Merge(dst, &config{Retries: ptr.Of(0)})
+		assert.Equals(t, *dst.Retries, 0)
+		structs.Merge(dst, &config{})
+		assert.Equals(t, *dst.Retries, 0)
+	})
+
+	t.Run("when fields are present they should be merged even if they are zero", func(t *testing.T) {
+		t.Parallel()
+		dst := &config{Name: "dst", Enabled: true, Database: database{Host: "host", Port: 5432}, Tags: []string{"a"}}
+		structs.Merge(dst, &config{}, structs.WithPresentFields("Enabled", "Database.Port", "Tags"))
+		assert.Equals(t, dst, &config{Name: "dst", Database: database{Host: "host"}})
+	})
+
+	t.Run("when slices are merged it should use the strategy of the options or the tag", func(t *testing.T) {
+		t.Parallel()
+		dst := &config{
+			Tags:     []string{"a"},
+			Appended: []string{"a"},
+			Servers:  []server{{Name: "one", Host: "host-1"}, {Name: "two", Host: "host-2"}},
+		}
+		src := &config{
+			Tags:     []string{"b"},
+			Appended: []string{"b"},
+			Servers:  []server{{Name: "two", Port: 2}, {Name: "three", Host: "host-3"}},
+		}
+		structs.Merge(dst, src)
+		assert.Equals(t, dst.Tags, []string{"b"})
+		assert.Equals(t, dst.Appended, []string{"a", "b"})
+		assert.Equals(t, dst.Servers, []server{{Name: "two", Port: 2}, {Name: "three", Host: "host-3"}})
+
+		dst = &config{Tags: []string{"a"}, Appended: []string{"a"}}
+		structs.Merge(dst, &config{Tags: []string{"b"}, Appended: []string{"b"}}, structs.WithSliceStrategy(structs.SliceAppend))
+		assert.Equals(t, dst.Tags, []string{"a", "b"})
+		assert.Equals(t, dst.Appended, []string{"a", "b"})
+
+		dst = &config{Servers: []server{{Name: "one", Host: "host-1"}, {Name: "two", Host: "host-2"}}}
+		structs.Merge(dst, &config{Servers: src.Servers}, structs.WithSliceStrategy(structs.SliceMergeByKey), structs.WithMergeKey("Name"))
+		assert.Equals(t, dst.Servers, []server{{Name: "one", Host: "host-1"}, {Name: "two", Host: "host-2", Port: 2}, {Name: "three", Host: "host-3"}})
+	})
+
+	t.Run("when a slice of pointers is merged by key it should merge the matching elements", func(t *testing.T) {
+		t.Parallel()
+		dst := &config{ServerPtrs: []*server{nil, {Name: "one", Host: "host-1"}}}
+		src := &config{ServerPtrs: []*server{{Name: "one", Port: 1}, nil, {Name: "two"}}}
+		structs.Merge(dst, src)
+		assert.Equals(t, dst.ServerPtrs, []*server{nil, {Name: "one", Host: "host-1", Port: 1}, nil, {Name: "two"}})
+		assert.True(t, dst.ServerPtrs[3] != src.ServerPtrs[2])
+	})
+
+	t.Run("when maps are merged it should use the strategy of the options or the tag", func(t *testing.T) {
+		t.Parallel()
+		dst := &config{
+			Labels:    map[string]string{"a": "1", "b": "2"},
+			Databases: map[string]database{"main": {Host: "host", Port: 1}},
+			Replaced:  map[string]string{"a": "1"},
+		}
+		src := &config{
+			Labels:    map[string]string{"b": "3", "c": "4"},
+			Databases: map[string]database{"main": {Port: 2}, "replica": {Host: "replica"}},
+			Replaced:  map[string]string{"b": "2"},
+		}
+		structs.Merge(dst, src)
+		assert.Equals(t, dst.Labels, map[string]string{"a": "1", "b": "3", "c": "4"})
+		assert.Equals(t, dst.Databases, map[string]database{"main": {Host: "host", Port: 2}, "replica": {Host: "replica"}})
+		assert.Equals(t, dst.Replaced, map[string]string{"b": "2"})
+
+		dst = &config{Labels: map[string]string{"a": "1"}}
+		structs.Merge(dst, src, structs.WithMapStrategy(structs.MapReplace))
+		assert.Equals(t, dst.Labels, map[string]string{"b": "3", "c": "4"})
+	})
+
+	t.Run("when dst has nil maps it should allocate them instead of sharing the maps of src", func(t *testing.T) {
+		t.Parallel()
+		dst := &config{}
+		src := &config{Labels: map[string]string{"a": "1"}}
+		structs.Merge(dst, src)
+		dst.Labels["b"] = "2"
+		assert.Equals(t, src.Labels, map[string]string{"a": "1"})
+	})
+
+	t.Run("when the merge configuration is invalid it should panic", func(t *testing.T) {
+		t.Parallel()
+		type invalidTag struct {
+			Values []string `merge:"unknown"`
+		}
+		assert.PanicPart(t, func() {
+			structs.Merge(&invalidTag{}, &invalidTag{Values: []string{"a"}})
+		}, "invalid merge tag 'unknown' for the field Values")
+
+		type emptyKey struct {
+			Values []server `merge:"key="`
+		}
+		assert.PanicPart(t, func() {
+			structs.Merge(&emptyKey{}, &emptyKey{Values: []server{{}}})
+		}, "the merge tag of the field Values has an empty key")
+
+		type unknownKey struct {
+			Values []server `merge:"key=ID"`
+		}
+		assert.PanicPart(t, func() {
+			structs.Merge(&unknownKey{}, &unknownKey{Values: []server{{}}})
+		}, "the merge key 'ID' of the field Values is not a field of structs_test.server")
+
+		type notStructs struct {
+			Values []string `merge:"key=ID"`
+		}
+		assert.PanicPart(t, func() {
+			structs.Merge(&notStructs{}, &notStructs{Values: []string{"a"}})
+		}, "the elements of the field Values must be structs to be merged by key")
+
+		type appendMap struct {
+			Values map[string]string `merge:"append"`
+		}
+		assert.PanicPart(t, func() {
+			structs.Merge(&appendMap{}, &appendMap{Values: map[string]string{"a": "b"}})
+		}, "the merge tag 'append' of the field Values cannot be used on a map")
+
+		assert.PanicPart(t, func() {
+			structs.Merge(&config{}, &config{Tags: []string{"a"}}, structs.WithSliceStrategy("unknown"))
+		}, "invalid slice merge strategy 'unknown' for the field Tags")
+
+		assert.PanicPart(t, func() {
+			structs.Merge(&config{}, &config{Labels: map[string]string{"a": "b"}}, structs.WithMapStrategy("unknown"))
+		}, "invalid map merge strategy 'unknown' for the field Labels")
+	})
+
+	t.Run("when dst or src is nil it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			structs.Merge(nil, &config{})
+		}, "the dst and src cannot be nil")
+	})
+
+	t.Run("when the generic is not a struct it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			structs.Merge(ptr.Of("dst"), ptr.Of("src"))
+		}, "the generic must be a struct")
+	})
+}
@@ -0,0 +1,169 @@
+package structs
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/TriangleSide/GoBase/pkg/utils/fields"
+)
+
+// toMapConfig is configured by the ToMapOption functions.
+type toMapConfig struct {
+	omitZero       bool
+	nestedEmbedded bool
+}
+
+// ToMapOption is used to configure the ToMap function.
+type ToMapOption func(cfg *toMapConfig)
+
+// WithOmitZero omits the fields that have a zero value from the map.
+func WithOmitZero() ToMapOption {
+	return func(cfg *toMapConfig) {
+		cfg.omitZero = true
+	}
+}
+
+// WithNestedEmbedded stores the fields of embedded anonymous structs in a nested map instead of flattening them.
+// The nested map is keyed by the tag of the embedded struct, or by its type name if it doesn't have the tag.
+func WithNestedEmbedded() ToMapOption {
+	return func(cfg *toMapConfig) {
+		cfg.nestedEmbedded = true
+	}
+}
+
+// ToMap converts a struct into a map of its field names to their values.
+//
+// The name of a field is taken from the tag. For example, if the tag is json, the following field
+// is stored under the key "name". If the field doesn't have the tag, its field name is used.
+// Fields with the tag value "-" and unexported fields are skipped.
+//
+//	type MyStruct struct {
+//	    Name string `json:"name,omitempty"`
+//	}
+//
+// Embedded anonymous structs are flattened into the map, unless WithNestedEmbedded is used. Pointers are dereferenced, and nil pointers are stored as nil.
+// Nested structs are converted into maps unless they implement json.Marshaler or encoding.TextMarshaler, like time.Time.
+func ToMap[T any](instance *T, tag string, opts ...ToMapOption) map[string]any {
+	cfg := &toMapConfig{
+		omitZero:       false,
+		nestedEmbedded: false,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if instance == nil {
+		panic("the instance cannot be nil")
+	}
+	structValue := reflect.ValueOf(instance).Elem()
+	if structValue.Kind() != reflect.Struct {
+		panic("the generic must be a struct")
+	}
+	return structToMap(structValue, tag, cfg)
+}
+
+// structToMap converts the struct value into a map.
+func structToMap(structValue reflect.Value, tag string, cfg *toMapConfig) map[string]any {
+	result := make(map[string]any)
+	for fieldName, fieldMetadata := range fields.StructMetadataFromType(structValue.Type()).Iterator() {
+		if cfg.nestedEmbedded && len(fieldMetadata.Anonymous) != 0 {
+			continue
+		}
+		fieldValue, ok := fieldValueFromMetadata(structValue, fieldName, fieldMetadata)
+		if !ok || !fieldValue.CanInterface() {
+			continue
+		}
+
+		key, hasKey := fieldMetadata.TagName(tag)
+		if !hasKey {
+			continue
+		}
+
+		if cfg.omitZero && fieldValue.IsZero() {
+			continue
+		}
+		result[key] = convertValue(fieldValue, tag, cfg)
+	}
+	if cfg.nestedEmbedded {
+		addNestedEmbedded(structValue, tag, cfg, result)
+	}
+	return result
+}
+
+// addNestedEmbedded adds the embedded anonymous structs of the struct value into the result as nested maps.
+func addNestedEmbedded(structValue reflect.Value, tag string, cfg *toMapConfig, result map[string]any) {
+	structType := structValue.Type()
+	for fieldIndex := 0; fieldIndex < structType.NumField(); fieldIndex++ {
+		field := structType.Field(fieldIndex)
+		if !field.Anonymous {
+			continue
+		}
+		key, skip := keyFromTag(field.Name, field.Tag.Get(tag))
+		if skip {
+			continue
+		}
+		embeddedValue := structValue.Field(fieldIndex)
+		if cfg.omitZero && embeddedValue.IsZero() {
+			continue
+		}
+		if embeddedValue.Kind() == reflect.Ptr {
+			if embeddedValue.IsNil() {
+				result[key] = nil
+				continue
+			}
+			embeddedValue = embeddedValue.Elem()
+		}
+		if embeddedValue.Kind() == reflect.Struct {
+			result[key] = structToMap(embeddedValue, tag, cfg)
+		}
+	}
+}
+
+// keyFromTag returns the map key of a field from its tag value. The field name is used if the tag has no name.
+// It returns true if the field is skipped with the tag value "-". This matches FieldMetadata.TagName.
+func keyFromTag(fieldName string, tagValue string) (string, bool) {
+	if tagValue == "-" {
+		return "", true
+	}
+	tagName, _, _ := strings.Cut(tagValue, ",")
+	if tagName != "" {
+		return tagName, false
+	}
+	return fieldName, false
+}
+
+// fieldValueFromMetadata gets the value of a field. It returns false if the field is in a nil embedded struct pointer.
+func fieldValueFromMetadata(structValue reflect.Value, fieldName string, fieldMetadata *fields.FieldMetadata) (reflect.Value, bool) {
+	current := structValue
+	for _, anonymousName := range fieldMetadata.Anonymous {
+		current = current.FieldByName(anonymousName)
+		if current.Kind() == reflect.Ptr {
+			if current.IsNil() {
+				return reflect.Value{}, false
+			}
+			current = current.Elem()
+		}
+	}
+	return current.FieldByName(fieldName), true
+}
+
+// convertValue returns the value to store in the map.
+func convertValue(value reflect.Value, tag string, cfg *toMapConfig) any {
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() == reflect.Struct && !implementsMarshaler(value.Type()) {
+		return structToMap(value, tag, cfg)
+	}
+	return value.Interface()
+}
+
+// implementsMarshaler checks if the type has its own serialization.
+func implementsMarshaler(reflectType reflect.Type) bool {
+	ptrType := reflect.PointerTo(reflectType)
+	return ptrType.Implements(reflect.TypeFor[json.Marshaler]()) || ptrType.Implements(reflect.TypeFor[encoding.TextMarshaler]())
+}
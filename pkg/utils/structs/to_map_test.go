@@ -0,0 +1,117 @@
+package structs_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+	"github.com/TriangleSide/GoBase/pkg/utils/ptr"
+	"github.com/TriangleSide/GoBase/pkg/utils/structs"
+)
+
+func TestToMap(t *testing.T) {
+	t.Parallel()
+
+	type nestedStruct struct {
+		Value int `json:"value"`
+	}
+
+	type embeddedStruct struct {
+		EmbeddedField string `json:"embedded" query:"emb"`
+	}
+
+	type embeddedPtrStruct struct {
+		EmbeddedPtrField string `json:"embeddedPtr"`
+	}
+
+	type testStruct struct {
+		embeddedStruct
+		*embeddedPtrStruct
+		Name       string `json:"name,omitempty" query:"n"`
+		NoTag      int
+		Skipped    string        `json:"-"`
+		Pointer    *int          `json:"pointer"`
+		NilPointer *int          `json:"nilPointer"`
+		Nested     nestedStruct  `json:"nested"`
+		NestedPtr  *nestedStruct `json:"nestedPtr"`
+		Time       time.Time     `json:"time"`
+		List       []string      `json:"list"`
+		unexported string
+	}
+
+	instance := &testStruct{
+		embeddedStruct: embeddedStruct{EmbeddedField: "embedded"},
+		Name:           "name",
+		NoTag:          1,
+		Skipped:        "skipped",
+		Pointer:        ptr.Of(2),
+		Nested:         nestedStruct{Value: 3},
+		NestedPtr:      &nestedStruct{Value: 4},
+		Time:           time.Unix(0, 0).UTC(),
+		List:           []string{"a"},
+		unexported:     "unexported",
+	}
+
+	t.Run("when a struct is converted with the json tag it should use the tag names and flatten embedded fields", func(t *testing.T) {
+		t.Parallel()
+		result := structs.ToMap(instance, "json")
+		assert.Equals(t, result, map[string]any{
+			"embedded":   "embedded",
+			"name":       "name",
+			"NoTag":      1,
+			"pointer":    2,
+			"nilPointer": nil,
+			"nested":     map[string]any{"value": 3},
+			"nestedPtr":  map[string]any{"value": 4},
+			"time":       time.Unix(0, 0).UTC(),
+			"list":       []string{"a"},
+		})
+	})
+
+	t.Run("when a struct is converted with another tag it should use that tag for the names", func(t *testing.T) {
+		t.Parallel()
+		result := structs.ToMap(instance, "query")
+		assert.Equals(t, result["emb"], "embedded")
+		assert.Equals(t, result["n"], "name")
+		assert.Equals(t, result["Skipped"], "skipped")
+		assert.Equals(t, result["Nested"], map[string]any{"Value": 3})
+	})
+
+	t.Run("when zero values are omitted they should not be in the map", func(t *testing.T) {
+		t.Parallel()
+		result := structs.ToMap(&testStruct{Name: "name", embeddedPtrStruct: &embeddedPtrStruct{}}, "json", structs.WithOmitZero())
+		assert.Equals(t, result, map[string]any{"name": "name"})
+	})
+
+	t.Run("when an embedded struct pointer is set its fields should be in the map", func(t *testing.T) {
+		t.Parallel()
+		result := structs.ToMap(&testStruct{embeddedPtrStruct: &embeddedPtrStruct{EmbeddedPtrField: "value"}}, "json")
+		assert.Equals(t, result["embeddedPtr"], "value")
+	})
+
+	t.Run("when embedded structs are nested they should be maps under their type names", func(t *testing.T) {
+		t.Parallel()
+		result := structs.ToMap(instance, "json", structs.WithNestedEmbedded())
+		assert.Equals(t, result["embeddedStruct"], map[string]any{"embedded": "embedded"})
+		assert.Equals(t, result["embeddedPtrStruct"], nil)
+		_, hasFlattened := result["embedded"]
+		assert.False(t, hasFlattened)
+
+		result = structs.ToMap(&testStruct{embeddedPtrStruct: &embeddedPtrStruct{EmbeddedPtrField: "value"}}, "json", structs.WithNestedEmbedded(), structs.WithOmitZero())
+		assert.Equals(t, result, map[string]any{"embeddedPtrStruct": map[string]any{"embeddedPtr": "value"}})
+	})
+
+	t.Run("when the instance is nil it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			structs.ToMap[testStruct](nil, "json")
+		}, "the instance cannot be nil")
+	})
+
+	t.Run("when the generic is not a struct it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicPart(t, func() {
+			structs.ToMap(ptr.Of("value"), "json")
+		}, "the generic must be a struct")
+	})
+}
@@ -0,0 +1,140 @@
+package validation
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultLocale is the locale of the built-in English validation failure messages.
+	DefaultLocale = "en"
+)
+
+var (
+	// locales maps the lower-case name of a locale to its message catalog.
+	locales = make(map[string]map[string]string)
+
+	// localesMutex guards the locales.
+	localesMutex sync.RWMutex
+)
+
+// RegisterLocale registers the message catalog of a locale, such as fr or pt-BR. The keys of the catalog are
+// validator tags, such as required or gte, and the values are the message templates of the tags.
+// The templates can reference the following placeholders:
+//   - {field} is the name of the field.
+//   - {tag} is the validator tag.
+//   - {param} is the parameter of the tag, such as 3 in min=3.
+//   - {value} is the offending value.
+//   - {error} is the error returned by a validator registered with RegisterValidator.
+//
+// Tags that are missing from the catalog use the built-in English message. Registering the DefaultLocale replaces
+// the built-in English messages of the tags in its catalog. If it is called more than once for a locale, a panic occurs.
+func RegisterLocale(locale string, messages map[string]string) {
+	if strings.TrimSpace(locale) == "" {
+		panic("The locale cannot be empty.")
+	}
+	if messages == nil {
+		panic(fmt.Sprintf("Locale '%s' has a nil message catalog.", locale))
+	}
+	key := strings.ToLower(locale)
+	localesMutex.Lock()
+	defer localesMutex.Unlock()
+	if _, found := locales[key]; found {
+		panic(fmt.Sprintf("Locale '%s' is already registered.", locale))
+	}
+	locales[key] = maps.Clone(messages)
+}
+
+// languageRange is a language range of an Accept-Language header with its quality.
+type languageRange struct {
+	tag     string
+	quality float64
+}
+
+// parseAcceptLanguage parses the language ranges of an Accept-Language header, sorted by descending quality.
+// Ranges with the same quality keep the order of the header. Invalid or missing quality values are treated as 1.
+func parseAcceptLanguage(acceptLanguage string) []languageRange {
+	ranges := make([]languageRange, 0)
+	for part := range strings.SplitSeq(acceptLanguage, ",") {
+		params := strings.Split(part, ";")
+		tag := strings.ToLower(strings.TrimSpace(params[0]))
+		if tag == "" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range params[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if !strings.EqualFold(strings.TrimSpace(key), "q") {
+				continue
+			}
+			if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil && parsed >= 0 && parsed <= 1 {
+				quality = parsed
+			}
+		}
+		ranges = append(ranges, languageRange{tag: tag, quality: quality})
+	}
+	slices.SortStableFunc(ranges, func(a, b languageRange) int {
+		switch {
+		case a.quality > b.quality:
+			return -1
+		case a.quality < b.quality:
+			return 1
+		default:
+			return 0
+		}
+	})
+	return ranges
+}
+
+// MatchLocale returns the registered locale that best matches an Accept-Language header, such as "fr-CA, en;q=0.8".
+// A language range matches a locale with the same name, or with the same primary language, so fr-CA matches fr.
+// The ranges are tried in order of quality, and the DefaultLocale is returned when none of them match.
+func MatchLocale(acceptLanguage string) string {
+	localesMutex.RLock()
+	defer localesMutex.RUnlock()
+	for _, languageRange := range parseAcceptLanguage(acceptLanguage) {
+		if languageRange.quality == 0 {
+			continue
+		}
+		if languageRange.tag == "*" {
+			return DefaultLocale
+		}
+		if _, found := locales[languageRange.tag]; found {
+			return languageRange.tag
+		}
+		primary, _, _ := strings.Cut(languageRange.tag, "-")
+		if _, found := locales[primary]; found {
+			return primary
+		}
+		if primary == DefaultLocale {
+			return DefaultLocale
+		}
+	}
+	return DefaultLocale
+}
+
+// localeMessages returns the message catalog of a locale, or nil if it is not registered.
+func localeMessages(locale string) map[string]string {
+	localesMutex.RLock()
+	defer localesMutex.RUnlock()
+	return locales[strings.ToLower(locale)]
+}
+
+// interpolate replaces the placeholders of a message template.
+func interpolate(template string, field string, tag string, param string, value any, validatorErr error) string {
+	errMessage := ""
+	if validatorErr != nil {
+		errMessage = validatorErr.Error()
+	}
+	return strings.NewReplacer(
+		"{field}", field,
+		"{tag}", tag,
+		"{param}", param,
+		"{value}", fmt.Sprintf("%v", value),
+		"{error}", errMessage,
+	).Replace(template)
+}
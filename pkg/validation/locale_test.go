@@ -0,0 +1,121 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/TriangleSide/GoBase/pkg/test/assert"
+)
+
+func init() {
+	RegisterLocale("fr", map[string]string{
+		"required": "le champ '{field}' est obligatoire",
+		"gte":      "le champ '{field}' doit être supérieur ou égal à {param}, mais il vaut {value}",
+	})
+	RegisterLocale("pt-BR", map[string]string{
+		"required": "o campo '{field}' é obrigatório",
+	})
+	RegisterValidator("locale_even_test", func(value any, _ string) error {
+		if number, ok := value.(int); ok && number%2 == 0 {
+			return nil
+		}
+		return errors.New("not even")
+	})
+	RegisterLocale("de", map[string]string{
+		"locale_even_test": "das Feld '{field}' ist ungültig ({error})",
+	})
+}
+
+func TestLocale(t *testing.T) {
+	t.Parallel()
+
+	type testStruct struct {
+		Name  string `json:"name" validate:"required"`
+		Count int    `json:"count" validate:"gte=3"`
+		Even  int    `json:"even" validate:"locale_even_test"`
+	}
+
+	t.Run("when a locale is used it should translate the messages and interpolate the parameters", func(t *testing.T) {
+		t.Parallel()
+		err := Struct(&testStruct{Count: 1, Even: 2}, WithLocale("fr"), WithFieldNameTag("json"))
+		assert.ErrorExact(t, err, "le champ 'name' est obligatoire; "+
+			"le champ 'count' doit être supérieur ou égal à 3, mais il vaut 1")
+		var validationErr *Error
+		assert.True(t, errors.As(err, &validationErr))
+		assert.Equals(t, validationErr.Fields()[0].Message, "le champ 'name' est obligatoire")
+	})
+
+	t.Run("when a tag is missing from the locale it should use the English message", func(t *testing.T) {
+		t.Parallel()
+		assert.ErrorExact(t, Struct(&testStruct{Count: 1, Even: 2}, WithLocale("pt-BR")),
+			"o campo 'Name' é obrigatório; "+
+				"validation failed on field 'Count' with validator 'gte' and parameter(s) '3'")
+	})
+
+	t.Run("when a registered validator fails it should interpolate its error", func(t *testing.T) {
+		t.Parallel()
+		assert.ErrorExact(t, Struct(&testStruct{Name: "a", Count: 3, Even: 1}, WithLocale("de")),
+			"das Feld 'Even' ist ungültig (not even)")
+	})
+
+	t.Run("when the locale is unknown or the default it should use the English messages", func(t *testing.T) {
+		t.Parallel()
+		const expected = "validation failed on field 'Name' with validator 'required'"
+		assert.ErrorExact(t, Struct(&testStruct{Count: 3, Even: 2}, WithLocale("xx")), expected)
+		assert.ErrorExact(t, Struct(&testStruct{Count: 3, Even: 2}, WithLocale(DefaultLocale)), expected)
+		assert.ErrorExact(t, Struct(&testStruct{Count: 3, Even: 2}), expected)
+	})
+
+	t.Run("when a variable is validated with a locale it should translate the message", func(t *testing.T) {
+		t.Parallel()
+		assert.ErrorExact(t, Var(1, "gte=2", WithLocale("FR")),
+			"le champ '' doit être supérieur ou égal à 2, mais il vaut 1")
+	})
+
+	t.Run("when matching an Accept-Language header it should return the best registered locale", func(t *testing.T) {
+		t.Parallel()
+		for acceptLanguage, expected := range map[string]string{
+			"":                         DefaultLocale,
+			"fr":                       "fr",
+			"FR-ca":                    "fr",
+			"pt-BR":                    "pt-br",
+			"pt":                       DefaultLocale,
+			"es, fr;q=0.5":             "fr",
+			"fr;q=0.5, de;q=0.8":       "de",
+			"en-US, fr;q=0.9":          DefaultLocale,
+			"fr;q=0, de;q=invalid":     "de",
+			"es, *;q=0.5, fr;q=0.1":    DefaultLocale,
+			" , ;q=1, fr ; q=0.3 ":     "fr",
+			"xx-yy, zz":                DefaultLocale,
+			"de;q=0.5, fr;q=0.5, es":   "de",
+			"de;q=0.5, fr;q=0.5;a=b,x": "de",
+		} {
+			assert.Equals(t, MatchLocale(acceptLanguage), expected)
+		}
+	})
+
+	t.Run("when a locale is registered twice it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicExact(t, func() {
+			RegisterLocale("FR", map[string]string{})
+		}, "Locale 'FR' is already registered.")
+	})
+
+	t.Run("when a locale is registered with an empty name or a nil catalog it should panic", func(t *testing.T) {
+		t.Parallel()
+		assert.PanicExact(t, func() {
+			RegisterLocale(" ", map[string]string{})
+		}, "The locale cannot be empty.")
+		assert.PanicExact(t, func() {
+			RegisterLocale("nil_catalog_test", nil)
+		}, "Locale 'nil_catalog_test' has a nil message catalog.")
+	})
+
+	t.Run("when the registered catalog is modified it should not change the messages", func(t *testing.T) {
+		t.Parallel()
+		catalog := map[string]string{"required": "requis"}
+		RegisterLocale("catalog_copy_test", catalog)
+		catalog["required"] = "changed"
+		assert.ErrorExact(t, Var("", "required", WithLocale("catalog_copy_test")), "requis")
+	})
+}
@@ -1,23 +1,130 @@
 package validation
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"go/token"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
+
+	"github.com/TriangleSide/GoBase/pkg/utils/ctxkey"
 )
 
 var (
 	validate                      = validator.New(validator.WithRequiredStructEnabled(), validator.WithPrivateFieldValidation())
 	customValidationErrorMessages = make(map[string]func(err validator.FieldError) string)
+	registeredValidators          = make(map[string]func(value any, param string) error)
+
+	// validatorFailuresKey is the context key of the failures of the registered validators during a validation.
+	validatorFailuresKey = ctxkey.New[*validatorFailures]("validatorFailures")
 )
 
+// Error is returned when a value violates its validation rules.
+// It allows validation failures to be distinguished from other kinds of errors.
+// The details of each field that failed its validation rules are available with Fields.
+type Error struct {
+	message string
+	fields  []FieldError
+	cause   error
+}
+
+// FieldError describes a field that failed one of its validation rules.
+type FieldError struct {
+	// Field is the name of the field. It is empty when a variable is validated with Var.
+	Field string
+
+	// Tag is the validator that failed, such as required or gte.
+	Tag string
+
+	// Value is the offending value of the field. It is nil for fields that are not exported.
+	Value any
+
+	// Message describes the validation failure.
+	Message string
+}
+
+// Error is Error implementing the error interface.
+func (e *Error) Error() string {
+	return e.message
+}
+
+// Fields returns the fields that failed their validation rules.
+// It is empty when the failure comes from the Validate function of a Validatable struct.
+func (e *Error) Fields() []FieldError {
+	return e.fields
+}
+
+// Unwrap returns the error returned by the Validate function of a Validatable struct, if any.
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Validatable is implemented by structs with invariants between their fields.
+// Validate is called by Struct once all the field validation rules pass.
+type Validatable interface {
+	Validate() error
+}
+
+// config is configured by the Option functions.
+type config struct {
+	fieldNameTag  string
+	slowThreshold time.Duration
+	reportSlow    func(typeName string, elapsed time.Duration)
+	locale        string
+}
+
+// newConfig applies the options to the default configuration.
+func newConfig(opts []Option) *config {
+	cfg := &config{
+		fieldNameTag:  "",
+		slowThreshold: 0,
+		reportSlow:    nil,
+		locale:        DefaultLocale,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// Option is used to configure how a struct is validated.
+type Option func(cfg *config)
+
+// WithFieldNameTag makes the error messages reference fields by the name in their tag instead of their Go name.
+// For example, with the json tag, a field named ID with the tag json:"id" is referenced as 'id'.
+// Fields without the tag, or with a name of "-", keep their Go name.
+func WithFieldNameTag(tag string) Option {
+	return func(cfg *config) {
+		cfg.fieldNameTag = tag
+	}
+}
+
+// WithSlowValidationReporter calls the reporter with the name of the validated type and the time it took
+// when the validation takes longer than the threshold. A threshold of zero disables the reporter.
+func WithSlowValidationReporter(threshold time.Duration, reporter func(typeName string, elapsed time.Duration)) Option {
+	return func(cfg *config) {
+		cfg.slowThreshold = threshold
+		cfg.reportSlow = reporter
+	}
+}
+
+// WithLocale translates the validation failure messages with the message catalog of a locale registered with
+// RegisterLocale. MatchLocale can be used to find the locale of an Accept-Language header.
+// Unknown locales use the built-in English messages.
+func WithLocale(locale string) Option {
+	return func(cfg *config) {
+		cfg.locale = locale
+	}
+}
+
 // RegisterValidation registers a custom validator and error message generator for a tag.
 // If it is called more than once for a tag, a panic occurs.
 func RegisterValidation(tag string, validationFunc validator.Func, validationErrorMsg func(err validator.FieldError) string) {
-	if _, ok := customValidationErrorMessages[tag]; ok {
+	if isRegistered(tag) {
 		panic(fmt.Sprintf("Tag '%s' already has a registered validation function.", tag))
 	}
 	if validationErrorMsg == nil {
@@ -29,8 +136,97 @@ func RegisterValidation(tag string, validationFunc validator.Func, validationErr
 	}
 }
 
+// RegisterValidator registers a validator for a tag that is usable in the validate tag of any struct field.
+// The function receives the value of the field and the parameter of the tag, for example 'abc' in `validate:"name=abc"`.
+// The value is nil for fields that are not exported.
+// It returns nil when the value is valid, otherwise the error is appended to the validation failure message.
+// If it is called more than once for a tag, a panic occurs.
+func RegisterValidator(name string, fn func(value any, param string) error) {
+	if isRegistered(name) {
+		panic(fmt.Sprintf("Tag '%s' already has a registered validation function.", name))
+	}
+	if fn == nil {
+		panic(fmt.Sprintf("Tag '%s' has a nil validator function.", name))
+	}
+	registeredValidators[name] = fn
+	if err := validate.RegisterValidationCtx(name, func(ctx context.Context, fl validator.FieldLevel) bool {
+		err := fn(fieldValue(fl.Field()), fl.Param())
+		if err != nil {
+			if failures, found := validatorFailuresKey.Value(ctx); found {
+				failures.record(fl.StructFieldName(), fl.GetTag(), fl.Param(), err)
+			}
+		}
+		return err == nil
+	}, true); err != nil {
+		panic(fmt.Sprintf("Failed to register the validation function for the tag '%s'.", name))
+	}
+}
+
+// isRegistered returns true if a custom validation is registered for the tag.
+func isRegistered(tag string) bool {
+	_, isCustomMessage := customValidationErrorMessages[tag]
+	_, isValidator := registeredValidators[tag]
+	return isCustomMessage || isValidator
+}
+
+// fieldValue returns the value of a field, or nil if it is not exported.
+func fieldValue(value reflect.Value) any {
+	if !value.IsValid() || !value.CanInterface() {
+		return nil
+	}
+	return value.Interface()
+}
+
+// validatorFailure is the error of a registered validator that failed on a field.
+type validatorFailure struct {
+	field string
+	tag   string
+	param string
+	err   error
+}
+
+// validatorFailures records the errors of the registered validators as they fail, so the validators do not run
+// again to build the failure messages.
+type validatorFailures struct {
+	failures []validatorFailure
+	next     int
+}
+
+// record appends the error of a registered validator that failed on the field.
+func (f *validatorFailures) record(field string, tag string, param string, err error) {
+	f.failures = append(f.failures, validatorFailure{field: field, tag: tag, param: param, err: err})
+}
+
+// take returns the error of the next failure of the validator with the tag and parameter on the field. The failures
+// are taken in the order the validation reports them, and the failures in between are skipped, like those of a
+// validator that failed in a group of tags separated by '|' that passed. It returns nil if there is no such failure.
+func (f *validatorFailures) take(field string, tag string, param string) error {
+	if f == nil {
+		return nil
+	}
+	for i := f.next; i < len(f.failures); i++ {
+		failure := f.failures[i]
+		if failure.field == field && failure.tag == tag && failure.param == param {
+			f.next = i + 1
+			return failure.err
+		}
+	}
+	return nil
+}
+
 // Struct returns an error if one or many of the struct members violate validation rules.
-func Struct[T any](val T) error {
+func Struct[T any](val T, opts ...Option) error {
+	cfg := newConfig(opts)
+
+	if cfg.slowThreshold > 0 && cfg.reportSlow != nil {
+		start := time.Now()
+		defer func() {
+			if elapsed := time.Since(start); elapsed > cfg.slowThreshold {
+				cfg.reportSlow(reflect.TypeOf(val).String(), elapsed)
+			}
+		}()
+	}
+
 	v := reflect.ValueOf(val)
 	if v.Kind() == reflect.Ptr && v.IsNil() {
 		return errors.New("struct validation on nil value")
@@ -38,34 +234,129 @@ func Struct[T any](val T) error {
 	if v.Kind() != reflect.Struct && !(v.Kind() == reflect.Ptr && v.Elem().Kind() == reflect.Struct) {
 		panic("Type must be a struct or a pointer to a struct.")
 	}
-	if err := validate.Struct(val); err != nil {
-		return formatErrorMessage(err)
+	failures := &validatorFailures{}
+	if err := validate.StructCtx(validatorFailuresKey.WithValue(context.Background(), failures), val); err != nil {
+		fieldName := func(fieldError validator.FieldError) string {
+			return fieldError.Field()
+		}
+		if cfg.fieldNameTag != "" {
+			fieldName = func(fieldError validator.FieldError) string {
+				return taggedFieldName(v.Type(), fieldError, cfg.fieldNameTag)
+			}
+		}
+		return formatErrorMessage(err, fieldName, localeMessages(cfg.locale), failures)
+	}
+	return validateStructLevel(v)
+}
+
+// validateStructLevel calls the Validate function of the struct if it implements the Validatable interface.
+// Structs passed by value are copied into a pointer so that Validate can be implemented on a pointer receiver.
+func validateStructLevel(v reflect.Value) error {
+	validatable, ok := v.Interface().(Validatable)
+	if !ok && v.Kind() == reflect.Struct {
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		validatable, ok = ptr.Interface().(Validatable)
+	}
+	if !ok {
+		return nil
+	}
+	if err := validatable.Validate(); err != nil {
+		var validationErr *Error
+		if errors.As(err, &validationErr) {
+			return err
+		}
+		return &Error{
+			message: fmt.Sprintf("struct validation failed (%s)", err.Error()),
+			cause:   err,
+		}
 	}
 	return nil
 }
 
 // Var validates a single variable using tag style validation that would be set on a struct field.
-func Var[T any](val T, tag string) error {
-	if err := validate.Var(val, tag); err != nil {
-		return formatErrorMessage(err)
+// Only the WithLocale option applies to variables.
+func Var[T any](val T, tag string, opts ...Option) error {
+	cfg := newConfig(opts)
+	failures := &validatorFailures{}
+	if err := validate.VarCtx(validatorFailuresKey.WithValue(context.Background(), failures), val, tag); err != nil {
+		return formatErrorMessage(err, func(fieldError validator.FieldError) string {
+			return fieldError.Field()
+		}, localeMessages(cfg.locale), failures)
 	}
 	return nil
 }
 
-// formatErrorMessage takes a validation error and formats it.
-func formatErrorMessage(err error) error {
+// taggedFieldName follows the struct namespace of the field error from the root type to find the name of the field in the tag.
+// If the field cannot be found, or it has no name in the tag, its Go name is returned.
+func taggedFieldName(rootType reflect.Type, fieldError validator.FieldError, tag string) string {
+	namespace := strings.Split(fieldError.StructNamespace(), ".")
+	currentType := rootType
+	var field reflect.StructField
+	for _, segment := range namespace[1:] {
+		for currentType.Kind() == reflect.Ptr || currentType.Kind() == reflect.Slice || currentType.Kind() == reflect.Array || currentType.Kind() == reflect.Map {
+			currentType = currentType.Elem()
+		}
+		if currentType.Kind() != reflect.Struct {
+			return fieldError.Field()
+		}
+		fieldName, _, _ := strings.Cut(segment, "[")
+		var found bool
+		field, found = currentType.FieldByName(fieldName)
+		if !found {
+			return fieldError.Field()
+		}
+		currentType = field.Type
+	}
+	taggedName, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+	if taggedName == "" || taggedName == "-" {
+		return fieldError.Field()
+	}
+	if _, index, hasIndex := strings.Cut(fieldError.Field(), "["); hasIndex {
+		return taggedName + "[" + index
+	}
+	return taggedName
+}
+
+// isExportedField returns true if the field of the error is exported. Variables validated with Var are exported.
+func isExportedField(fieldError validator.FieldError) bool {
+	name, _, _ := strings.Cut(fieldError.StructField(), "[")
+	return name == "" || token.IsExported(name)
+}
+
+// formatErrorMessage takes a validation error and formats it into an Error.
+// The fieldName function returns the name used to reference the field in the message.
+// The messages are translated with the templates of the catalog, and tags without a template use the English message.
+// The errors of the registered validators are taken from the failures recorded during the validation.
+func formatErrorMessage(err error, fieldName func(fieldError validator.FieldError) string, catalog map[string]string, failures *validatorFailures) error {
 	var validationErrs validator.ValidationErrors
 	if errors.As(err, &validationErrs) {
-		errorList := make([]string, 0)
+		errorList := make([]string, 0, len(validationErrs))
+		fieldErrors := make([]FieldError, 0, len(validationErrs))
 		for _, fieldError := range validationErrs {
+			var field string
+			if fieldError.Field() != "" {
+				field = fieldName(fieldError)
+			}
+			var validatorErr error
+			if _, isValidator := registeredValidators[fieldError.Tag()]; isValidator {
+				validatorErr = failures.take(fieldError.StructField(), fieldError.Tag(), fieldError.Param())
+			}
+			value := fieldError.Value()
+			if !isExportedField(fieldError) {
+				value = nil
+			}
+			var message string
 			if customErrorMsg, isCustomTag := customValidationErrorMessages[fieldError.Tag()]; isCustomTag {
-				errorList = append(errorList, customErrorMsg(fieldError))
+				message = customErrorMsg(fieldError)
+			} else if template, isTranslated := catalog[fieldError.Tag()]; isTranslated {
+				message = interpolate(template, field, fieldError.Tag(), fieldError.Param(), value, validatorErr)
 			} else {
 				sb := strings.Builder{}
 				sb.WriteString("validation failed")
-				if fieldError.Field() != "" {
+				if field != "" {
 					sb.WriteString(" on field '")
-					sb.WriteString(fieldError.Field())
+					sb.WriteString(field)
 					sb.WriteString("'")
 				}
 				sb.WriteString(" with validator '")
@@ -76,10 +367,24 @@ func formatErrorMessage(err error) error {
 					sb.WriteString(fieldError.Param())
 					sb.WriteString("'")
 				}
-				errorList = append(errorList, sb.String())
+				if validatorErr != nil {
+					sb.WriteString(": ")
+					sb.WriteString(validatorErr.Error())
+				}
+				message = sb.String()
 			}
+			errorList = append(errorList, message)
+			fieldErrors = append(fieldErrors, FieldError{
+				Field:   field,
+				Tag:     fieldError.Tag(),
+				Value:   value,
+				Message: message,
+			})
+		}
+		return &Error{
+			message: strings.Join(errorList, "; "),
+			fields:  fieldErrors,
 		}
-		return errors.New(strings.Join(errorList, "; "))
 	}
 	return err
 }
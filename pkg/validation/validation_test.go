@@ -2,13 +2,36 @@ package validation
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 
 	"github.com/TriangleSide/GoBase/pkg/test/assert"
 )
 
+var errDateRange = errors.New("start must be before end")
+
+type dateRange struct {
+	Start int `validate:"gte=0"`
+	End   int `validate:"gte=0"`
+}
+
+func (r *dateRange) Validate() error {
+	if r.Start >= r.End {
+		return errDateRange
+	}
+	return nil
+}
+
+type validatableWithError struct{}
+
+func (validatableWithError) Validate() error {
+	return &Error{message: "custom validation error"}
+}
+
 func TestValidation(t *testing.T) {
 	t.Parallel()
 
@@ -94,6 +117,38 @@ func TestValidation(t *testing.T) {
 		assert.ErrorPart(t, err, "validation failed on field 'StrValue' with validator 'required'")
 	})
 
+	t.Run("when validations fail it should describe each field in the error", func(t *testing.T) {
+		t.Parallel()
+		RegisterValidation("field_errors_custom_test", func(fl validator.FieldLevel) bool {
+			return false
+		}, func(err validator.FieldError) string {
+			return "custom message"
+		})
+		type testStruct struct {
+			Count  int    `json:"count" validate:"gte=0"`
+			Name   string `json:"name" validate:"required"`
+			Custom string `validate:"field_errors_custom_test"`
+		}
+		err := Struct(&testStruct{Count: -1, Custom: "value"}, WithFieldNameTag("json"))
+		var validationErr *Error
+		assert.True(t, errors.As(err, &validationErr))
+		assert.Equals(t, validationErr.Fields(), []FieldError{
+			{Field: "count", Tag: "gte", Value: -1, Message: "validation failed on field 'count' with validator 'gte' and parameter(s) '0'"},
+			{Field: "name", Tag: "required", Value: "", Message: "validation failed on field 'name' with validator 'required'"},
+			{Field: "Custom", Tag: "field_errors_custom_test", Value: "value", Message: "custom message"},
+		})
+
+		err = Var(0, "gt=0")
+		assert.True(t, errors.As(err, &validationErr))
+		assert.Equals(t, validationErr.Fields(), []FieldError{
+			{Field: "", Tag: "gt", Value: 0, Message: "validation failed with validator 'gt' and parameter(s) '0'"},
+		})
+
+		err = Struct(&dateRange{Start: 2, End: 1})
+		assert.True(t, errors.As(err, &validationErr))
+		assert.Equals(t, len(validationErr.Fields()), 0)
+	})
+
 	t.Run("when a variable satisfies the required tag it should succeed", func(t *testing.T) {
 		t.Parallel()
 		myInt := 1
@@ -126,8 +181,244 @@ func TestValidation(t *testing.T) {
 		})
 	})
 
+	t.Run("when a registered validator is used it should include its error in the failure message", func(t *testing.T) {
+		t.Parallel()
+		RegisterValidator("prefixed_validator_test", func(value any, param string) error {
+			str, ok := value.(string)
+			if !ok {
+				return errors.New("value must be a string")
+			}
+			if !strings.HasPrefix(str, param) {
+				return fmt.Errorf("value '%s' must start with '%s'", str, param)
+			}
+			return nil
+		})
+		type testStruct struct {
+			Name  string `json:"name" validate:"prefixed_validator_test=abc"`
+			Other string `validate:"prefixed_validator_test=def"`
+		}
+		assert.NoError(t, Struct(&testStruct{Name: "abc1", Other: "def1"}))
+		assert.ErrorExact(t, Struct(&testStruct{Name: "xyz", Other: "def"}, WithFieldNameTag("json")),
+			"validation failed on field 'name' with validator 'prefixed_validator_test' and parameter(s) 'abc': "+
+				"value 'xyz' must start with 'abc'")
+		assert.ErrorExact(t, Struct(&testStruct{Name: "abc", Other: "abc"}),
+			"validation failed on field 'Other' with validator 'prefixed_validator_test' and parameter(s) 'def': "+
+				"value 'abc' must start with 'def'")
+		assert.NoError(t, Var("abc", "prefixed_validator_test=a"))
+		assert.ErrorExact(t, Var(1, "prefixed_validator_test=a"),
+			"validation failed with validator 'prefixed_validator_test' and parameter(s) 'a': value must be a string")
+	})
+
+	t.Run("when a registered validator is used on an unexported field it should not receive or report its value", func(t *testing.T) {
+		t.Parallel()
+		var received []any
+		RegisterValidator("unexported_validator_test", func(value any, _ string) error {
+			received = append(received, value)
+			return errors.New("always fails")
+		})
+		type testStruct struct {
+			internal string `validate:"unexported_validator_test"`
+		}
+		err := Struct(&testStruct{internal: "secret"})
+		assert.ErrorExact(t, err, "validation failed on field 'internal' with validator 'unexported_validator_test': always fails")
+		var validationErr *Error
+		assert.True(t, errors.As(err, &validationErr))
+		assert.Equals(t, validationErr.Fields()[0].Value, nil)
+		assert.Equals(t, received, []any{nil})
+	})
+
+	t.Run("when a registered validator fails it should run once per field", func(t *testing.T) {
+		t.Parallel()
+		calls := 0
+		RegisterValidator("counted_validator_test", func(value any, _ string) error {
+			calls++
+			return fmt.Errorf("value %v is invalid", value)
+		})
+		type testStruct struct {
+			First  int `validate:"counted_validator_test"`
+			Second int `validate:"counted_validator_test"`
+		}
+		assert.ErrorExact(t, Struct(&testStruct{First: 1, Second: 2}),
+			"validation failed on field 'First' with validator 'counted_validator_test': value 1 is invalid; "+
+				"validation failed on field 'Second' with validator 'counted_validator_test': value 2 is invalid")
+		assert.Equals(t, calls, 2)
+	})
+
+	t.Run("when a registered validator fails in a group of tags that passes it should not use its error for another field", func(t *testing.T) {
+		t.Parallel()
+		RegisterValidator("grouped_validator_test", func(value any, _ string) error {
+			return fmt.Errorf("value %v is invalid", value)
+		})
+		type testStruct struct {
+			First  int `validate:"grouped_validator_test|gt=0"`
+			Second int `validate:"grouped_validator_test"`
+		}
+		assert.ErrorExact(t, Struct(&testStruct{First: 1, Second: 2}),
+			"validation failed on field 'Second' with validator 'grouped_validator_test': value 2 is invalid")
+	})
+
+	t.Run("when a registered validator is used on a pointer field it should receive the pointed to value", func(t *testing.T) {
+		t.Parallel()
+		RegisterValidator("pointer_validator_test", func(value any, _ string) error {
+			if value == 1 {
+				return nil
+			}
+			return errors.New("value must be one")
+		})
+		one := 1
+		two := 2
+		type testStruct struct {
+			Value *int `validate:"omitempty,pointer_validator_test"`
+		}
+		assert.NoError(t, Struct(&testStruct{Value: &one}))
+		assert.NoError(t, Struct(&testStruct{}))
+		assert.ErrorPart(t, Struct(&testStruct{Value: &two}), "value must be one")
+	})
+
+	t.Run("when a validator is registered with a name that is already used it should panic", func(t *testing.T) {
+		RegisterValidator("duplicate_validator_test", func(any, string) error { return nil })
+		assert.PanicExact(t, func() {
+			RegisterValidator("duplicate_validator_test", func(any, string) error { return nil })
+		}, "Tag 'duplicate_validator_test' already has a registered validation function.")
+		assert.PanicExact(t, func() {
+			RegisterValidation("duplicate_validator_test",
+				func(fl validator.FieldLevel) bool { return true },
+				func(err validator.FieldError) string { return "" })
+		}, "Tag 'duplicate_validator_test' already has a registered validation function.")
+	})
+
+	t.Run("when a validator is registered with a nil function it should panic", func(t *testing.T) {
+		assert.PanicExact(t, func() {
+			RegisterValidator("nil_validator_test", nil)
+		}, "Tag 'nil_validator_test' has a nil validator function.")
+	})
+
+	t.Run("when cross-field rules are used it should validate the fields against each other", func(t *testing.T) {
+		t.Parallel()
+		type request struct {
+			Mode       string `json:"mode" validate:"oneof=email phone"`
+			Email      string `json:"email" validate:"required_if=Mode email"`
+			Phone      string `json:"phone" validate:"required_without=Email"`
+			Password   string `json:"password" validate:"required"`
+			Confirm    string `json:"confirm" validate:"eqfield=Password"`
+			MinReplica int    `json:"minReplica" validate:"gte=0"`
+			MaxReplica int    `json:"maxReplica" validate:"gtfield=MinReplica"`
+		}
+		assert.NoError(t, Struct(&request{
+			Mode:       "email",
+			Email:      "a@b.c",
+			Password:   "secret",
+			Confirm:    "secret",
+			MinReplica: 1,
+			MaxReplica: 2,
+		}))
+		assert.NoError(t, Struct(&request{
+			Mode:       "phone",
+			Phone:      "555",
+			Password:   "secret",
+			Confirm:    "secret",
+			MaxReplica: 1,
+		}))
+		assert.ErrorExact(t, Struct(&request{
+			Mode:       "email",
+			Password:   "secret",
+			Confirm:    "other",
+			MinReplica: 2,
+			MaxReplica: 2,
+		}, WithFieldNameTag("json")),
+			"validation failed on field 'email' with validator 'required_if' and parameter(s) 'Mode email'; "+
+				"validation failed on field 'phone' with validator 'required_without' and parameter(s) 'Email'; "+
+				"validation failed on field 'confirm' with validator 'eqfield' and parameter(s) 'Password'; "+
+				"validation failed on field 'maxReplica' with validator 'gtfield' and parameter(s) 'MinReplica'")
+	})
+
+	t.Run("when a struct implements Validatable it should be called after the field rules pass", func(t *testing.T) {
+		t.Parallel()
+		assert.NoError(t, Struct(&dateRange{Start: 1, End: 2}))
+		assert.NoError(t, Struct(dateRange{Start: 1, End: 2}))
+
+		err := Struct(&dateRange{Start: 2, End: 1})
+		assert.ErrorExact(t, err, "struct validation failed (start must be before end)")
+		assert.True(t, errors.Is(err, errDateRange))
+		var validationErr *Error
+		assert.True(t, errors.As(err, &validationErr))
+
+		assert.ErrorExact(t, Struct(dateRange{Start: 2, End: 1}), "struct validation failed (start must be before end)")
+		assert.ErrorExact(t, Struct(&dateRange{Start: -1, End: -2}),
+			"validation failed on field 'Start' with validator 'gte' and parameter(s) '0'; "+
+				"validation failed on field 'End' with validator 'gte' and parameter(s) '0'")
+	})
+
+	t.Run("when Validate returns a validation error it should be returned as is", func(t *testing.T) {
+		t.Parallel()
+		assert.ErrorExact(t, Struct(validatableWithError{}), "custom validation error")
+	})
+
 	t.Run("when the error formatter is passed an error it doesn't recognize it should simply return the error", func(t *testing.T) {
 		t.Parallel()
-		assert.ErrorExact(t, formatErrorMessage(errors.New("test error")), "test error")
+		assert.ErrorExact(t, formatErrorMessage(errors.New("test error"), nil, nil, nil), "test error")
+	})
+
+	t.Run("when a field name tag is used it should reference the fields by their tag name", func(t *testing.T) {
+		t.Parallel()
+		type embedded struct {
+			Code string `json:"code" validate:"required"`
+		}
+		type item struct {
+			Name string `json:"name" validate:"required"`
+		}
+		type request struct {
+			embedded
+			ID       int     `json:"id" validate:"gt=0"`
+			Untagged int     `validate:"gt=0"`
+			Ignored  int     `json:"-" validate:"gt=0"`
+			Nested   *item   `json:"nested,omitempty" validate:"required"`
+			Items    []*item `json:"items" validate:"dive"`
+		}
+		err := Struct(&request{Nested: &item{}, Items: []*item{{Name: "a"}, {}}}, WithFieldNameTag("json"))
+		assert.ErrorExact(t, err, "validation failed on field 'code' with validator 'required'; "+
+			"validation failed on field 'id' with validator 'gt' and parameter(s) '0'; "+
+			"validation failed on field 'Untagged' with validator 'gt' and parameter(s) '0'; "+
+			"validation failed on field 'Ignored' with validator 'gt' and parameter(s) '0'; "+
+			"validation failed on field 'name' with validator 'required'; "+
+			"validation failed on field 'name' with validator 'required'")
+		var validationErr *Error
+		assert.True(t, errors.As(err, &validationErr))
+	})
+
+	t.Run("when a field name tag is not used it should reference the fields by their Go name", func(t *testing.T) {
+		t.Parallel()
+		type request struct {
+			ID int `json:"id" validate:"gt=0"`
+		}
+		assert.ErrorExact(t, Struct(request{}), "validation failed on field 'ID' with validator 'gt' and parameter(s) '0'")
+	})
+
+	t.Run("when a validation exceeds the slow threshold it should report the type and duration", func(t *testing.T) {
+		t.Parallel()
+		RegisterValidation("slow_validation_test", func(fl validator.FieldLevel) bool {
+			time.Sleep(time.Millisecond * 20)
+			return true
+		}, func(err validator.FieldError) string {
+			return ""
+		})
+		type slowStruct struct {
+			Value int `validate:"slow_validation_test"`
+		}
+		var reportedType string
+		var reportedElapsed time.Duration
+		reporter := func(typeName string, elapsed time.Duration) {
+			reportedType = typeName
+			reportedElapsed = elapsed
+		}
+		assert.NoError(t, Struct(&slowStruct{}, WithSlowValidationReporter(time.Millisecond, reporter)))
+		assert.Equals(t, reportedType, "*validation.slowStruct")
+		assert.True(t, reportedElapsed >= time.Millisecond*20)
+
+		reportedType = ""
+		assert.NoError(t, Struct(&slowStruct{}, WithSlowValidationReporter(time.Hour, reporter)))
+		assert.Equals(t, reportedType, "")
+		assert.NoError(t, Struct(&slowStruct{}, WithSlowValidationReporter(0, reporter)))
+		assert.Equals(t, reportedType, "")
 	})
 }